@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const activityMaxPerPage = 1000
+
+func (a *API) registerActivityRoutes(r *mux.Router) {
+	r.HandleFunc("/boards/{boardID}/activity", a.sessionRequired(a.handleGetBoardActivity)).Methods("GET")
+	r.HandleFunc("/cards/{cardID}/activity", a.sessionRequired(a.handleGetCardActivity)).Methods("GET")
+}
+
+func (a *API) handleGetBoardActivity(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/activity getBoardActivity
+	//
+	// Returns the activity of the cards of a board, newest first
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: before
+	//   in: query
+	//   description: Only return changes made before this timestamp, use nextBefore of the previous page to paginate
+	//   required: false
+	//   type: integer
+	// - name: before_id
+	//   in: query
+	//   description: Also return the changes made at the before timestamp to blocks with a lesser ID, use nextBeforeId of the previous page to paginate
+	//   required: false
+	//   type: string
+	// - name: per_page
+	//   in: query
+	//   description: Number of block revisions to read per page, a revision can produce several entries (default=100)
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ActivityFeed"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+	a.handleGetActivity(w, r, boardID, "")
+}
+
+func (a *API) handleGetCardActivity(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /cards/{cardID}/activity getCardActivity
+	//
+	// Returns the activity of a card and its content, newest first
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: cardID
+	//   in: path
+	//   description: Card ID
+	//   required: true
+	//   type: string
+	// - name: before
+	//   in: query
+	//   description: Only return changes made before this timestamp, use nextBefore of the previous page to paginate
+	//   required: false
+	//   type: integer
+	// - name: before_id
+	//   in: query
+	//   description: Also return the changes made at the before timestamp to blocks with a lesser ID, use nextBeforeId of the previous page to paginate
+	//   required: false
+	//   type: string
+	// - name: per_page
+	//   in: query
+	//   description: Number of block revisions to read per page, a revision can produce several entries (default=100)
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ActivityFeed"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	cardID := mux.Vars(r)["cardID"]
+
+	card, err := a.app.GetCardByID(cardID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.handleGetActivity(w, r, card.BoardID, card.ID)
+}
+
+func (a *API) handleGetActivity(w http.ResponseWriter, r *http.Request, boardID, cardID string) {
+	userID := getUserID(r)
+
+	query := r.URL.Query()
+	strBefore := query.Get("before")
+	beforeID := query.Get("before_id")
+	strPerPage := query.Get("per_page")
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board activity"))
+		return
+	}
+
+	var before int64
+	if strBefore != "" {
+		var err error
+		before, err = strconv.ParseInt(strBefore, 10, 64)
+		if err != nil {
+			message := fmt.Sprintf("invalid `before` parameter: %s", err)
+			a.errorResponse(w, r, model.NewErrBadRequest(message))
+			return
+		}
+	}
+
+	if strPerPage == "" {
+		strPerPage = defaultPerPage
+	}
+	perPage, err := strconv.Atoi(strPerPage)
+	if err != nil || perPage <= 0 || perPage > activityMaxPerPage {
+		message := fmt.Sprintf("invalid `per_page` parameter, must be between 1 and %d", activityMaxPerPage)
+		a.errorResponse(w, r, model.NewErrBadRequest(message))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getActivity", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("cardID", cardID)
+	auditRec.AddMeta("before", before)
+	auditRec.AddMeta("before_id", beforeID)
+	auditRec.AddMeta("per_page", perPage)
+
+	feed, err := a.app.GetActivity(boardID, model.QueryActivityOptions{
+		CardID:         cardID,
+		BeforeUpdateAt: before,
+		BeforeID:       beforeID,
+		PerPage:        perPage,
+	})
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("GetActivity",
+		mlog.String("boardID", boardID),
+		mlog.String("cardID", cardID),
+		mlog.Int("entries", len(feed.Entries)),
+	)
+
+	data, err := json.Marshal(feed)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	// response
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
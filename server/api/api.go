@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/focalboard/server/app"
@@ -93,9 +95,17 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 	a.registerContentBlocksRoutes(apiv2)
 	a.registerStatisticsRoutes(apiv2)
 	a.registerComplianceRoutes(apiv2)
+	a.registerAutomationsRoutes(apiv2)
+	a.registerActivityRoutes(apiv2)
+	a.registerCommentsRoutes(apiv2)
+	a.registerInboxRoutes(apiv2)
+	a.registerPresenceRoutes(apiv2)
+	a.registerStorageRoutes(apiv2)
+	a.registerUploadPolicyRoutes(apiv2)
 
 	// V3 routes
 	a.registerCardsRoutes(apiv2)
+	a.registerCardCSVRoutes(apiv2)
 
 	// System routes are outside the /api/v2 path
 	a.registerSystemRoutes(r)
@@ -204,6 +214,22 @@ func (a *API) errorResponse(w http.ResponseWriter, r *http.Request, err error) {
 		errorResponse.ErrorCode = http.StatusForbidden
 	case model.IsErrNotFound(err):
 		errorResponse.ErrorCode = http.StatusNotFound
+	case model.IsErrWIPLimitExceeded(err):
+		errorResponse.ErrorCode = http.StatusConflict
+	case model.IsErrStorageQuotaExceeded(err):
+		var sqe *model.ErrStorageQuotaExceeded
+		errors.As(err, &sqe)
+		errorResponse.ErrorCode = http.StatusInsufficientStorage
+		errorResponse.QuotaExceeded = &sqe.Breach
+	case model.IsErrUploadRejected(err):
+		errorResponse.ErrorCode = http.StatusUnsupportedMediaType
+	case model.IsErrVersionConflict(err):
+		var vc *model.ErrVersionConflict
+		errors.As(err, &vc)
+		errorResponse.ErrorCode = http.StatusConflict
+		errorResponse.CurrentUpdateAt = vc.CurrentUpdateAt
+		errorResponse.Current = vc.Current
+		setETagHeader(w, vc.CurrentUpdateAt)
 	case model.IsErrRequestEntityTooLarge(err):
 		errorResponse.ErrorCode = http.StatusRequestEntityTooLarge
 	case model.IsErrNotImplemented(err):
@@ -245,6 +271,29 @@ func jsonBytesResponse(w http.ResponseWriter, code int, json []byte) { //nolint:
 	_, _ = w.Write(json)
 }
 
+// setETagHeader sets the ETag of a response to the update time of the returned entity,
+// which clients can send back in the If-Match header of a patch.
+func setETagHeader(w http.ResponseWriter, updateAt int64) {
+	setResponseHeader(w, "ETag", strconv.Quote(strconv.FormatInt(updateAt, 10)))
+}
+
+// setExpectedUpdateAtFromHeader sets the expected update time of a patch from the If-Match
+// header of the request, if present. The header takes precedence over the patch payload.
+func setExpectedUpdateAtFromHeader(r *http.Request, expectedUpdateAt *int64) error {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil
+	}
+
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	updateAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || updateAt <= 0 {
+		return model.NewErrBadRequest("invalid If-Match header")
+	}
+	*expectedUpdateAt = updateAt
+	return nil
+}
+
 func setResponseHeader(w http.ResponseWriter, key string, value string) { //nolint:unparam
 	header := w.Header()
 	if header == nil {
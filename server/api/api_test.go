@@ -47,6 +47,9 @@ func TestErrorResponse(t *testing.T) {
 		{"sql.ErrNoRows", sql.ErrNoRows, http.StatusNotFound, "rows"},
 		{"ErrNotFound", model.ErrCategoryDeleted, http.StatusNotFound, "category is deleted"},
 
+		// conflict
+		{"ErrVersionConflict", model.NewErrVersionConflict("block", "block-id", 42, nil), http.StatusConflict, `"currentUpdateAt":42`},
+
 		// request entity too large
 		{"ErrRequestEntityTooLarge", model.ErrRequestEntityTooLarge, http.StatusRequestEntityTooLarge, "entity too large"},
 
@@ -75,3 +78,37 @@ func TestErrorResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestSetExpectedUpdateAtFromHeader(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		IfMatch  string
+		Expected int64
+		IsError  bool
+	}{
+		{"no header keeps the payload value", "", 7, false},
+		{"wildcard keeps the payload value", "*", 7, false},
+		{"quoted etag", `"1234"`, 1234, false},
+		{"weak etag", `W/"1234"`, 1234, false},
+		{"not a number", `"abc"`, 7, true},
+		{"not positive", `"0"`, 7, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/test", nil)
+			if tc.IfMatch != "" {
+				r.Header.Set("If-Match", tc.IfMatch)
+			}
+
+			expectedUpdateAt := int64(7)
+			err := setExpectedUpdateAtFromHeader(r, &expectedUpdateAt)
+			if tc.IsError {
+				require.True(t, model.IsErrBadRequest(err))
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.Expected, expectedUpdateAt)
+		})
+	}
+}
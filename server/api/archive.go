@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -22,6 +23,12 @@ func (a *API) registerAchivesRoutes(r *mux.Router) {
 	r.HandleFunc("/boards/{boardID}/archive/export", a.sessionRequired(a.handleArchiveExportBoard)).Methods("GET")
 	r.HandleFunc("/teams/{teamID}/archive/import", a.sessionRequired(a.handleArchiveImport)).Methods("POST")
 	r.HandleFunc("/teams/{teamID}/archive/export", a.sessionRequired(a.handleArchiveExportTeam)).Methods("GET")
+	r.HandleFunc("/teams/{teamID}/import/{tool}", a.sessionRequired(a.handleExternalImport)).Methods("POST")
+	r.HandleFunc("/teams/{teamID}/archive/exports", a.sessionRequired(a.handleCreateExportJob)).Methods("POST")
+	r.HandleFunc("/teams/{teamID}/archive/exports", a.sessionRequired(a.handleGetExportJobs)).Methods("GET")
+	r.HandleFunc("/teams/{teamID}/archive/exports/{exportID}", a.sessionRequired(a.handleGetExportJob)).Methods("GET")
+	r.HandleFunc("/teams/{teamID}/archive/exports/{exportID}", a.sessionRequired(a.handleDeleteExportJob)).Methods("DELETE")
+	r.HandleFunc("/teams/{teamID}/archive/exports/{exportID}/download", a.sessionRequired(a.handleDownloadExport)).Methods("GET")
 }
 
 func (a *API) handleArchiveExportBoard(w http.ResponseWriter, r *http.Request) {
@@ -97,7 +104,8 @@ func (a *API) handleArchiveExportBoard(w http.ResponseWriter, r *http.Request) {
 func (a *API) handleArchiveImport(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation POST /teams/{teamID}/archive/import archiveImport
 	//
-	// Import an archive of boards.
+	// Import an archive of boards. Each board is imported entirely or not at all, and the
+	// problems found, with their line in the archive, are listed in the response.
 	//
 	// ---
 	// produces:
@@ -115,11 +123,23 @@ func (a *API) handleArchiveImport(w http.ResponseWriter, r *http.Request) {
 	//   description: archive file to import
 	//   required: true
 	//   type: file
+	// - name: dry_run
+	//   in: query
+	//   description: Only validate the archive and report what would be imported
+	//   required: false
+	//   type: boolean
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ArchiveImportReport"
+	//   '400':
+	//     description: unreadable archive, or no board imported because of errors, in which case the
+	//       report is returned
+	//     schema:
+	//       "$ref": "#/definitions/ArchiveImportReport"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -154,17 +174,22 @@ func (a *API) handleArchiveImport(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
+	dryRun := r.URL.Query().Get("dry_run") == True
+
 	auditRec := a.makeAuditRecord(r, "import", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("filename", handle.Filename)
 	auditRec.AddMeta("size", handle.Size)
+	auditRec.AddMeta("dryRun", dryRun)
 
 	opt := model.ImportArchiveOptions{
 		TeamID:     teamID,
 		ModifiedBy: userID,
+		DryRun:     dryRun,
 	}
 
-	if err := a.app.ImportArchive(file, opt); err != nil {
+	report, err := a.app.ImportArchive(file, opt)
+	if err != nil {
 		a.logger.Debug("Error importing archive",
 			mlog.String("team_id", teamID),
 			mlog.Err(err),
@@ -173,7 +198,131 @@ func (a *API) handleArchiveImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonStringResponse(w, http.StatusOK, "{}")
+	data, err := json.Marshal(report)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	// clients that don't read the report must not take an import that
+	// imported nothing for a success
+	if !dryRun && report.Failed() {
+		a.logger.Debug("Error importing archive",
+			mlog.String("team_id", teamID),
+			mlog.Int("errors", len(report.Errors)),
+		)
+		jsonBytesResponse(w, http.StatusBadRequest, data)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("boardCount", len(report.Boards))
+	auditRec.AddMeta("errorCount", len(report.Errors))
+	auditRec.Success()
+}
+
+func (a *API) handleExternalImport(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /teams/{teamID}/import/{tool} externalImport
+	//
+	// Imports the export of another tool as new boards. People of the export are set in person
+	// properties if a member of the team has the same username or email, and the fields that have
+	// no equivalent on boards are reported.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// consumes:
+	// - multipart/form-data
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: tool
+	//   in: path
+	//   description: Tool the export is from, one of trello (JSON), jira (XML) or asana (JSON)
+	//   required: true
+	//   type: string
+	// - name: file
+	//   in: formData
+	//   description: export of the tool, as downloaded from it
+	//   required: true
+	//   type: file
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ExternalImportResult"
+	//   '400':
+	//     description: unsupported tool or invalid export
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	teamID := vars["teamID"]
+	tool := vars["tool"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to create board"))
+		return
+	}
+
+	isGuest, err := a.userIsGuest(userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	if isGuest {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to create board"))
+		return
+	}
+
+	file, handle, err := r.FormFile(UploadFormFileKey)
+	if err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+	defer file.Close()
+
+	auditRec := a.makeAuditRecord(r, "externalImport", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("tool", tool)
+	auditRec.AddMeta("filename", handle.Filename)
+	auditRec.AddMeta("size", handle.Size)
+
+	opt := model.ImportArchiveOptions{
+		TeamID:     teamID,
+		ModifiedBy: userID,
+	}
+
+	result, err := a.app.ImportExternal(tool, file, opt)
+	if err != nil {
+		a.logger.Debug("Error importing external export",
+			mlog.String("team_id", teamID),
+			mlog.String("tool", tool),
+			mlog.Err(err),
+		)
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("boardCount", len(result.Boards))
+	auditRec.AddMeta("cardCount", result.CardCount)
 	auditRec.Success()
 }
 
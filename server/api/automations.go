@@ -0,0 +1,396 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const defaultAutomationExecutionsLimit = 100
+
+func (a *API) registerAutomationsRoutes(r *mux.Router) {
+	// Automation APIs
+	r.HandleFunc("/boards/{boardID}/automations", a.sessionRequired(a.handleGetAutomationRules)).Methods("GET")
+	r.HandleFunc("/boards/{boardID}/automations", a.sessionRequired(a.handleCreateAutomationRule)).Methods("POST")
+	r.HandleFunc("/boards/{boardID}/automations/executions", a.sessionRequired(a.handleGetAutomationExecutions)).Methods("GET")
+	r.HandleFunc("/boards/{boardID}/automations/{ruleID}", a.sessionRequired(a.handlePatchAutomationRule)).Methods("PATCH")
+	r.HandleFunc("/boards/{boardID}/automations/{ruleID}", a.sessionRequired(a.handleDeleteAutomationRule)).Methods("DELETE")
+}
+
+func (a *API) handleGetAutomationRules(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/automations getAutomationRules
+	//
+	// Returns the automation rules of a board.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/AutomationRule"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	boardID := mux.Vars(r)["boardID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board automations"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getAutomationRules", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	rules, err := a.app.GetAutomationRulesForBoard(boardID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("GetAutomationRules",
+		mlog.String("boardID", boardID),
+		mlog.Int("count", len(rules)),
+	)
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleCreateAutomationRule(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /boards/{boardID}/automations createAutomationRule
+	//
+	// Creates an automation rule for a board. The rule's actions are performed on behalf of the last user that created or modified it.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the automation rule to create
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AutomationRule"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       $ref: '#/definitions/AutomationRule'
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	boardID := mux.Vars(r)["boardID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to create automation rule"))
+		return
+	}
+
+	rule, err := model.AutomationRuleFromJSON(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if rule.BoardID != "" && rule.BoardID != boardID {
+		a.errorResponse(w, r, model.ErrBoardIDMismatch)
+		return
+	}
+	rule.BoardID = boardID
+
+	auditRec := a.makeAuditRecord(r, "createAutomationRule", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	newRule, err := a.app.CreateAutomationRule(rule, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("CreateAutomationRule",
+		mlog.String("boardID", boardID),
+		mlog.String("ruleID", newRule.ID),
+		mlog.String("userID", userID),
+	)
+
+	data, err := json.Marshal(newRule)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("ruleID", newRule.ID)
+	auditRec.Success()
+}
+
+func (a *API) handlePatchAutomationRule(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PATCH /boards/{boardID}/automations/{ruleID} patchAutomationRule
+	//
+	// Partially updates an automation rule. The rule's actions are then performed on behalf of the user updating it.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: ruleID
+	//   in: path
+	//   description: Automation rule ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: automation rule patch to apply
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AutomationRulePatch"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       $ref: '#/definitions/AutomationRule'
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	ruleID := vars["ruleID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to modify automation rule"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var patch *model.AutomationRulePatch
+	if err = json.Unmarshal(requestBody, &patch); err != nil || patch == nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid automation rule patch"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "patchAutomationRule", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("ruleID", ruleID)
+
+	rule, err := a.app.PatchAutomationRule(boardID, ruleID, patch, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("PatchAutomationRule",
+		mlog.String("boardID", boardID),
+		mlog.String("ruleID", ruleID),
+		mlog.String("userID", userID),
+	)
+
+	data, err := json.Marshal(rule)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteAutomationRule(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /boards/{boardID}/automations/{ruleID} deleteAutomationRule
+	//
+	// Deletes an automation rule.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: ruleID
+	//   in: path
+	//   description: Automation rule ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	ruleID := vars["ruleID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to delete automation rule"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteAutomationRule", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("ruleID", ruleID)
+
+	if err := a.app.DeleteAutomationRule(boardID, ruleID, userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("DeleteAutomationRule",
+		mlog.String("boardID", boardID),
+		mlog.String("ruleID", ruleID),
+		mlog.String("userID", userID),
+	)
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleGetAutomationExecutions(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/automations/executions getAutomationExecutions
+	//
+	// Returns the most recent automation rule executions of a board, newest first.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: rule_id
+	//   in: query
+	//   description: Only return executions of this rule
+	//   required: false
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: Number of executions to return (default=100)
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/AutomationExecution"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	boardID := mux.Vars(r)["boardID"]
+	query := r.URL.Query()
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board automations"))
+		return
+	}
+
+	limit := uint64(defaultAutomationExecutionsLimit)
+	if strLimit := query.Get("limit"); strLimit != "" {
+		var err error
+		limit, err = strconv.ParseUint(strLimit, 10, 64)
+		if err != nil {
+			message := fmt.Sprintf("invalid `limit` parameter: %s", err)
+			a.errorResponse(w, r, model.NewErrBadRequest(message))
+			return
+		}
+	}
+
+	opts := model.QueryAutomationExecutionsOptions{
+		BoardID: boardID,
+		RuleID:  query.Get("rule_id"),
+		Limit:   limit,
+	}
+
+	auditRec := a.makeAuditRecord(r, "getAutomationExecutions", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("ruleID", opts.RuleID)
+
+	executions, err := a.app.GetAutomationExecutions(opts)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(executions)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
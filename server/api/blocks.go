@@ -49,6 +49,11 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 	//   description: Type of blocks to return, omit to specify all types
 	//   required: false
 	//   type: string
+	// - name: include_archived
+	//   in: query
+	//   description: Include archived cards (default=false)
+	//   required: false
+	//   type: boolean
 	// security:
 	// - BearerAuth: []
 	// responses:
@@ -70,6 +75,7 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 	blockType := query.Get("type")
 	all := query.Get("all")
 	blockID := query.Get("block_id")
+	includeArchived := query.Get("include_archived") == "true"
 	boardID := mux.Vars(r)["boardID"]
 
 	userID := getUserID(r)
@@ -120,6 +126,7 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("blockType", blockType)
 	auditRec.AddMeta("all", all)
 	auditRec.AddMeta("blockID", blockID)
+	auditRec.AddMeta("includeArchived", includeArchived)
 
 	var blocks []*model.Block
 	var block *model.Block
@@ -151,6 +158,10 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if !includeArchived && blockID == "" {
+		blocks = model.ExcludeArchivedCards(blocks)
+	}
+
 	a.logger.Debug("GetBlocks",
 		mlog.String("boardID", boardID),
 		mlog.String("parentID", parentID),
@@ -512,6 +523,11 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 	//   description: Disables notifications (for bulk patching)
 	//   required: false
 	//   type: bool
+	// - name: If-Match
+	//   in: header
+	//   description: ETag of the block version the patch is based on
+	//   required: false
+	//   type: string
 	// - name: Body
 	//   in: body
 	//   description: block patch to apply
@@ -525,6 +541,10 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 	//     description: success
 	//   '404':
 	//     description: block not found
+	//   '409':
+	//     description: the block was modified since the expected version
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -567,17 +587,26 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = setExpectedUpdateAtFromHeader(r, &patch.ExpectedUpdateAt); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	auditRec := a.makeAuditRecord(r, "patchBlock", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("boardID", boardID)
 	auditRec.AddMeta("blockID", blockID)
 
-	if _, err = a.app.PatchBlockAndNotify(blockID, patch, userID, disableNotify); err != nil {
+	patchedBlock, err := a.app.PatchBlockAndNotify(blockID, patch, userID, disableNotify)
+	if err != nil {
 		a.errorResponse(w, r, err)
 		return
 	}
 
 	a.logger.Debug("PATCH Block", mlog.String("boardID", boardID), mlog.String("blockID", blockID))
+	if patchedBlock != nil {
+		setETagHeader(w, patchedBlock.UpdateAt)
+	}
 	jsonStringResponse(w, http.StatusOK, "{}")
 
 	auditRec.Success()
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/focalboard/server/model"
@@ -20,6 +21,8 @@ func (a *API) registerBoardsRoutes(r *mux.Router) {
 	r.HandleFunc("/boards/{boardID}", a.sessionRequired(a.handleDeleteBoard)).Methods("DELETE")
 	r.HandleFunc("/boards/{boardID}/duplicate", a.sessionRequired(a.handleDuplicateBoard)).Methods("POST")
 	r.HandleFunc("/boards/{boardID}/undelete", a.sessionRequired(a.handleUndeleteBoard)).Methods("POST")
+	r.HandleFunc("/boards/{boardID}/snapshot", a.sessionRequired(a.handleGetBoardSnapshot)).Methods("GET")
+	r.HandleFunc("/boards/{boardID}/restore", a.sessionRequired(a.handleRestoreBoard)).Methods("POST")
 	r.HandleFunc("/boards/{boardID}/metadata", a.sessionRequired(a.handleGetBoardMetadata)).Methods("GET")
 }
 
@@ -278,6 +281,7 @@ func (a *API) handleGetBoard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// response
+	setETagHeader(w, board.UpdateAt)
 	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.Success()
@@ -297,6 +301,11 @@ func (a *API) handlePatchBoard(w http.ResponseWriter, r *http.Request) {
 	//   description: Board ID
 	//   required: true
 	//   type: string
+	// - name: If-Match
+	//   in: header
+	//   description: ETag of the board version the patch is based on
+	//   required: false
+	//   type: string
 	// - name: Body
 	//   in: body
 	//   description: board patch to apply
@@ -312,6 +321,10 @@ func (a *API) handlePatchBoard(w http.ResponseWriter, r *http.Request) {
 	//       $ref: '#/definitions/Board'
 	//   '404':
 	//     description: board not found
+	//   '409':
+	//     description: the board was modified since the expected version
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -337,6 +350,11 @@ func (a *API) handlePatchBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = setExpectedUpdateAtFromHeader(r, &patch.ExpectedUpdateAt); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	if err = patch.IsValid(); err != nil {
 		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
 		return
@@ -384,6 +402,7 @@ func (a *API) handlePatchBoard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// response
+	setETagHeader(w, updatedBoard.UpdateAt)
 	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.Success()
@@ -603,6 +622,184 @@ func (a *API) handleUndeleteBoard(w http.ResponseWriter, r *http.Request) {
 	auditRec.Success()
 }
 
+func (a *API) handleGetBoardSnapshot(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/snapshot getBoardSnapshot
+	//
+	// Returns a board and its blocks as they were at a point in time
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: at
+	//   in: query
+	//   description: The timestamp of the snapshot in milliseconds since the current epoch
+	//   required: true
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/BoardSnapshot"
+	//   '404':
+	//     description: the board didn't exist at the requested time
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	boardID := mux.Vars(r)["boardID"]
+
+	at, err := strconv.ParseInt(r.URL.Query().Get("at"), 10, 64)
+	if err != nil || at <= 0 {
+		a.errorResponse(w, r, model.NewErrBadRequest("a valid at timestamp is required"))
+		return
+	}
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionRestoreBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board history"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getBoardSnapshot", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("at", at)
+
+	snapshot, err := a.app.GetBoardSnapshot(boardID, at)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("GetBoardSnapshot",
+		mlog.String("boardID", boardID),
+		mlog.Int("at", at),
+	)
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	// response
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleRestoreBoard(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /boards/{boardID}/restore restoreBoard
+	//
+	// Restores a board, its schema, cards, content and views to their state at a point in
+	// time, either in place or as a new board. With dryRun set, the changes are only reported.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the restore options
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/BoardRestoreOptions"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/BoardRestoreResult"
+	//   '404':
+	//     description: the board didn't exist at the requested time
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	boardID := mux.Vars(r)["boardID"]
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var opts model.BoardRestoreOptions
+	if err = json.Unmarshal(requestBody, &opts); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = opts.IsValid(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionRestoreBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to restore board"))
+		return
+	}
+
+	if opts.AsNewBoard {
+		isGuest, err := a.userIsGuest(userID)
+		if err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+		if isGuest {
+			a.errorResponse(w, r, model.NewErrPermission("access denied to create board"))
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "restoreBoard", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("at", opts.At)
+	auditRec.AddMeta("asNewBoard", opts.AsNewBoard)
+	auditRec.AddMeta("dryRun", opts.DryRun)
+
+	result, err := a.app.RestoreBoard(boardID, opts, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("RestoreBoard",
+		mlog.String("boardID", boardID),
+		mlog.String("restoredBoardID", result.Board.ID),
+		mlog.Int("at", opts.At),
+	)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	// response
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
 func (a *API) handleGetBoardMetadata(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation GET /boards/{boardID}/metadata getBoardMetadata
 	//
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	csvColumnsFormKey = "columns"
+)
+
+func (a *API) registerCardCSVRoutes(r *mux.Router) {
+	// Card CSV APIs
+	r.HandleFunc("/boards/{boardID}/cards/csv", a.sessionRequired(a.handleExportCardsCSV)).Methods("GET")
+	r.HandleFunc("/boards/{boardID}/cards/csv", a.sessionRequired(a.handleImportCardsCSV)).Methods("POST")
+}
+
+func (a *API) handleExportCardsCSV(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/cards/csv exportCardsCSV
+	//
+	// Exports the cards of a board, or of one of its views, to a CSV file with one column per card property.
+	//
+	// ---
+	// produces:
+	// - text/csv
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: view_id
+	//   in: query
+	//   description: ID of a view of the board, to only export the cards and properties it shows
+	//   required: false
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     content:
+	//       text/csv:
+	//         type: string
+	//         format: binary
+	//   '404':
+	//     description: board or view not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+	viewID := r.URL.Query().Get("view_id")
+	userID := getUserID(r)
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "exportCardsCSV", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("viewID", viewID)
+
+	// the file is written to a buffer first, so errors can still be
+	// returned as an error response
+	var buf bytes.Buffer
+	if err := a.app.ExportBoardCSV(&buf, boardID, viewID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("cards-%s.csv", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+
+	auditRec.Success()
+}
+
+func (a *API) handleImportCardsCSV(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /boards/{boardID}/cards/csv importCardsCSV
+	//
+	// Creates a card for each row of a CSV file. Columns are imported to the properties with the same name
+	// unless mapped otherwise, and new properties are created for the other columns. Creating properties
+	// or select options requires the permission to manage the board properties.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// consumes:
+	// - multipart/form-data
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: file
+	//   in: formData
+	//   description: CSV file to import, with a header row
+	//   required: true
+	//   type: file
+	// - name: columns
+	//   in: formData
+	//   description: JSON array of CardCSVColumn overriding how columns are mapped to properties
+	//   required: false
+	//   type: string
+	// - name: dry_run
+	//   in: query
+	//   description: Only report how the file would be imported, with a preview of the first cards
+	//   required: false
+	//   type: boolean
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/CardCSVImportResult"
+	//   '400':
+	//     description: invalid file or column mapping
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+	userID := getUserID(r)
+	dryRun := r.URL.Query().Get("dry_run") == True
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to create cards"))
+		return
+	}
+
+	if a.app.GetConfig().MaxFileSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, a.app.GetConfig().MaxFileSize)
+	}
+
+	file, handle, err := r.FormFile(UploadFormFileKey)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "http: request body too large") {
+			a.errorResponse(w, r, model.ErrRequestEntityTooLarge)
+			return
+		}
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+	defer file.Close()
+
+	opt := model.CardCSVImportOptions{
+		BoardID:    boardID,
+		ModifiedBy: userID,
+		DryRun:     dryRun,
+	}
+	if columns := r.FormValue(csvColumnsFormKey); columns != "" {
+		if err = json.Unmarshal([]byte(columns), &opt.Columns); err != nil {
+			a.errorResponse(w, r, model.NewErrBadRequest("invalid columns, must be a JSON array of column mappings"))
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "importCardsCSV", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("filename", handle.Filename)
+	auditRec.AddMeta("size", handle.Size)
+	auditRec.AddMeta("dryRun", dryRun)
+
+	result, err := a.app.ImportCardsCSV(file, opt)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("ImportCardsCSV",
+		mlog.String("boardID", boardID),
+		mlog.String("userID", userID),
+		mlog.Bool("dryRun", dryRun),
+		mlog.Int("count", result.CardCount),
+		mlog.Int("warnings", len(result.Warnings)),
+	)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("cardCount", result.CardCount)
+	auditRec.AddMeta("newProperties", len(result.NewProperties))
+	auditRec.Success()
+}
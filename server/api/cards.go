@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/focalboard/server/model"
@@ -25,6 +26,10 @@ func (a *API) registerCardsRoutes(r *mux.Router) {
 	r.HandleFunc("/boards/{boardID}/cards", a.sessionRequired(a.handleGetCards)).Methods("GET")
 	r.HandleFunc("/cards/{cardID}", a.sessionRequired(a.handlePatchCard)).Methods("PATCH")
 	r.HandleFunc("/cards/{cardID}", a.sessionRequired(a.handleGetCard)).Methods("GET")
+	r.HandleFunc("/cards/{cardID}/move", a.sessionRequired(a.handleMoveCard)).Methods("POST")
+	r.HandleFunc("/boards/{boardID}/cards/archive", a.sessionRequired(a.handleArchiveCards)).Methods("POST")
+	r.HandleFunc("/boards/{boardID}/cards/unarchive", a.sessionRequired(a.handleUnarchiveCards)).Methods("POST")
+	r.HandleFunc("/boards/{boardID}/cards/bulk", a.sessionRequired(a.handleBulkCards)).Methods("POST")
 }
 
 func (a *API) handleCreateCard(w http.ResponseWriter, r *http.Request) {
@@ -151,6 +156,11 @@ func (a *API) handleGetCards(w http.ResponseWriter, r *http.Request) {
 	//   description: Number of cards to return per page(default=100)
 	//   required: false
 	//   type: integer
+	// - name: include_archived
+	//   in: query
+	//   description: Include archived cards (default=false)
+	//   required: false
+	//   type: boolean
 	// security:
 	// - BearerAuth: []
 	// responses:
@@ -170,6 +180,7 @@ func (a *API) handleGetCards(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	strPage := query.Get("page")
 	strPerPage := query.Get("per_page")
+	includeArchived := query.Get("include_archived") == "true"
 
 	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to fetch cards"))
@@ -200,8 +211,9 @@ func (a *API) handleGetCards(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("boardID", boardID)
 	auditRec.AddMeta("page", page)
 	auditRec.AddMeta("per_page", perPage)
+	auditRec.AddMeta("include_archived", includeArchived)
 
-	cards, err := a.app.GetCardsForBoard(boardID, page, perPage)
+	cards, err := a.app.GetCardsForBoard(boardID, page, perPage, includeArchived)
 	if err != nil {
 		a.errorResponse(w, r, err)
 		return
@@ -241,6 +253,11 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 	//   description: Card ID
 	//   required: true
 	//   type: string
+	// - name: If-Match
+	//   in: header
+	//   description: ETag of the card version the patch is based on
+	//   required: false
+	//   type: string
 	// - name: Body
 	//   in: body
 	//   description: the card patch
@@ -259,6 +276,10 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 	//     description: success
 	//     schema:
 	//       $ref: '#/definitions/Card'
+	//   '409':
+	//     description: the card was modified since the expected version, or would exceed the hard WIP limit of a column
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -294,6 +315,11 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = setExpectedUpdateAtFromHeader(r, &patch.ExpectedUpdateAt); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	auditRec := a.makeAuditRecord(r, "patchCard", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("boardID", card.BoardID)
@@ -319,6 +345,7 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// response
+	setETagHeader(w, cardPatched.UpdateAt)
 	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.Success()
@@ -383,7 +410,333 @@ func (a *API) handleGetCard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// response
+	setETagHeader(w, card.UpdateAt)
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleMoveCard(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /cards/{cardID}/move moveCard
+	//
+	// Moves a card, with its contents and comments, to another board. The card keeps its ID,
+	// and its properties are mapped to the destination board by name or by the provided mappings.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: cardID
+	//   in: path
+	//   description: Card ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the destination board and property mappings
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CardMoveOptions"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       $ref: '#/definitions/Card'
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	cardID := mux.Vars(r)["cardID"]
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var opts *model.CardMoveOptions
+	if err = json.Unmarshal(requestBody, &opts); err != nil || opts == nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid card move options"))
+		return
+	}
+	if opts.DestinationBoardID == "" {
+		a.errorResponse(w, r, model.NewErrBadRequest("destinationBoardId is required"))
+		return
+	}
+
+	card, err := a.app.GetCardByID(cardID)
+	if err != nil {
+		message := fmt.Sprintf("could not fetch card %s: %s", cardID, err)
+		a.errorResponse(w, r, model.NewErrBadRequest(message))
+		return
+	}
+
+	if !a.permissions.HasPermissionToBoard(userID, card.BoardID, model.PermissionManageBoardCards) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to move card from board"))
+		return
+	}
+	if !a.permissions.HasPermissionToBoard(userID, opts.DestinationBoardID, model.PermissionManageBoardCards) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to move card to board"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "moveCard", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("cardID", card.ID)
+	auditRec.AddMeta("sourceBoardID", card.BoardID)
+	auditRec.AddMeta("destinationBoardID", opts.DestinationBoardID)
+
+	movedCard, err := a.app.MoveCard(card.ID, *opts, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("MoveCard",
+		mlog.String("sourceBoardID", card.BoardID),
+		mlog.String("destinationBoardID", movedCard.BoardID),
+		mlog.String("cardID", movedCard.ID),
+		mlog.String("userID", userID),
+	)
+
+	data, err := json.Marshal(movedCard)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleArchiveCards(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /boards/{boardID}/cards/archive archiveCards
+	//
+	// Archives the cards of a board that match the filter. Archived cards are hidden by
+	// default but are kept until they are unarchived.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the filter selecting the cards to archive
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CardFilter"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, returns the archived cards
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Card"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	a.handleSetCardsArchived(w, r, true)
+}
+
+func (a *API) handleUnarchiveCards(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /boards/{boardID}/cards/unarchive unarchiveCards
+	//
+	// Restores the archived cards of a board that match the filter.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the filter selecting the cards to unarchive
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CardFilter"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, returns the unarchived cards
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Card"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	a.handleSetCardsArchived(w, r, false)
+}
+
+func (a *API) handleSetCardsArchived(w http.ResponseWriter, r *http.Request, archive bool) {
+	userID := getUserID(r)
+	boardID := mux.Vars(r)["boardID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to archive cards"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var filter *model.CardFilter
+	if err = json.Unmarshal(requestBody, &filter); err != nil || filter == nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid card archive filter"))
+		return
+	}
+
+	event := "archiveCards"
+	if !archive {
+		event = "unarchiveCards"
+	}
+	auditRec := a.makeAuditRecord(r, event, audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	var cards []*model.Card
+	if archive {
+		cards, err = a.app.ArchiveCards(boardID, *filter, userID)
+	} else {
+		cards, err = a.app.UnarchiveCards(boardID, *filter, userID)
+	}
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug(event,
+		mlog.String("boardID", boardID),
+		mlog.String("userID", userID),
+		mlog.Int("count", len(cards)),
+	)
+
+	data, err := json.Marshal(cards)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("cardCount", len(cards))
+	auditRec.Success()
+}
+
+func (a *API) handleBulkCards(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /boards/{boardID}/cards/bulk bulkCards
+	//
+	// Applies a set of operations to the cards of a board selected by a filter, in a single
+	// transaction. Cards that can't be changed are reported as failures.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the filter, the operations and whether this is a dry run
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CardBulkRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/CardBulkResult"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	boardID := mux.Vars(r)["boardID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to modify cards"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var req *model.CardBulkRequest
+	if err = json.Unmarshal(requestBody, &req); err != nil || req == nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid bulk card request"))
+		return
+	}
+
+	operations := make([]string, 0, len(req.Operations))
+	for _, op := range req.Operations {
+		if op.Type == model.CardBulkMove && !a.permissions.HasPermissionToBoard(userID, op.BoardID, model.PermissionManageBoardCards) {
+			a.errorResponse(w, r, model.NewErrPermission("access denied to move cards to board"))
+			return
+		}
+		operations = append(operations, string(op.Type))
+	}
+
+	auditRec := a.makeAuditRecord(r, "bulkCards", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("operations", strings.Join(operations, ","))
+	auditRec.AddMeta("dryRun", req.DryRun)
+
+	result, err := a.app.ApplyCardBulkOperations(boardID, req, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("BulkCards",
+		mlog.String("boardID", boardID),
+		mlog.String("userID", userID),
+		mlog.Bool("dryRun", req.DryRun),
+		mlog.Int("count", len(result.CardIDs)),
+		mlog.Int("failures", len(result.Failures)),
+	)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	jsonBytesResponse(w, http.StatusOK, data)
 
+	auditRec.AddMeta("cardCount", len(result.CardIDs))
+	auditRec.AddMeta("failureCount", len(result.Failures))
 	auditRec.Success()
 }
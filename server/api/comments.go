@@ -0,0 +1,460 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) registerCommentsRoutes(r *mux.Router) {
+	r.HandleFunc("/boards/{boardID}/comments/{commentID}", a.sessionRequired(a.handleEditComment)).Methods("PATCH")
+	r.HandleFunc("/boards/{boardID}/comments/{commentID}/revisions", a.sessionRequired(a.handleGetCommentRevisions)).Methods("GET")
+	r.HandleFunc("/boards/{boardID}/comments/{commentID}/reactions", a.sessionRequired(a.handleGetCommentReactions)).Methods("GET")
+	r.HandleFunc("/boards/{boardID}/comments/{commentID}/reactions", a.sessionRequired(a.handleAddCommentReaction)).Methods("POST")
+	r.HandleFunc("/boards/{boardID}/comments/{commentID}/reactions/{emoji}", a.sessionRequired(a.handleRemoveCommentReaction)).Methods("DELETE")
+	r.HandleFunc("/cards/{cardID}/comments/reactions", a.sessionRequired(a.handleGetCardCommentReactions)).Methods("GET")
+}
+
+func (a *API) handleEditComment(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PATCH /boards/{boardID}/comments/{commentID} editComment
+	//
+	// Changes the text of a comment. Only the author of a comment can edit it
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: commentID
+	//   in: path
+	//   description: Comment ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the new text of the comment
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CommentEdit"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Block"
+	//   '404':
+	//     description: comment not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	commentID := vars["commentID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionCommentBoardCards) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to edit card comments"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var edit *model.CommentEdit
+	if err = json.Unmarshal(requestBody, &edit); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+	if edit == nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid comment edit"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "editComment", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("commentID", commentID)
+
+	comment, err := a.app.EditComment(boardID, commentID, edit.Title, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("EditComment", mlog.String("boardID", boardID), mlog.String("commentID", commentID))
+
+	data, err := json.Marshal(comment)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleGetCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/comments/{commentID}/revisions getCommentRevisions
+	//
+	// Returns the versions of the text of a comment, newest first
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: commentID
+	//   in: path
+	//   description: Comment ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Block"
+	//   '404':
+	//     description: comment not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	commentID := vars["commentID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getCommentRevisions", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("commentID", commentID)
+
+	revisions, err := a.app.GetCommentRevisions(boardID, commentID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(revisions)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleGetCommentReactions(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/comments/{commentID}/reactions getCommentReactions
+	//
+	// Returns the reactions to a comment
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: commentID
+	//   in: path
+	//   description: Comment ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/CommentReaction"
+	//   '404':
+	//     description: comment not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	commentID := vars["commentID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
+		return
+	}
+
+	reactions, err := a.app.GetCommentReactions(boardID, commentID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(reactions)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleAddCommentReaction(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /boards/{boardID}/comments/{commentID}/reactions addCommentReaction
+	//
+	// Adds an emoji reaction of the current user to a comment
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: commentID
+	//   in: path
+	//   description: Comment ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the reaction to add, only the emoji is used
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CommentReaction"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/CommentReaction"
+	//   '404':
+	//     description: comment not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	commentID := vars["commentID"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionCommentBoardCards) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to react to card comments"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var reaction *model.CommentReaction
+	if err = json.Unmarshal(requestBody, &reaction); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+	if reaction == nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid comment reaction"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "addCommentReaction", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("commentID", commentID)
+	auditRec.AddMeta("emoji", reaction.Emoji)
+
+	reaction, err = a.app.AddCommentReaction(boardID, commentID, userID, reaction.Emoji)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("AddCommentReaction",
+		mlog.String("boardID", boardID),
+		mlog.String("commentID", commentID),
+		mlog.String("emoji", reaction.Emoji),
+	)
+
+	data, err := json.Marshal(reaction)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleRemoveCommentReaction(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /boards/{boardID}/comments/{commentID}/reactions/{emoji} removeCommentReaction
+	//
+	// Removes an emoji reaction of the current user from a comment
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: commentID
+	//   in: path
+	//   description: Comment ID
+	//   required: true
+	//   type: string
+	// - name: emoji
+	//   in: path
+	//   description: Name of the emoji
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: comment or reaction not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	commentID := vars["commentID"]
+	emoji := vars["emoji"]
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionCommentBoardCards) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to react to card comments"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "removeCommentReaction", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("commentID", commentID)
+	auditRec.AddMeta("emoji", emoji)
+
+	if err := a.app.RemoveCommentReaction(boardID, commentID, userID, emoji); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("RemoveCommentReaction",
+		mlog.String("boardID", boardID),
+		mlog.String("commentID", commentID),
+		mlog.String("emoji", emoji),
+	)
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	auditRec.Success()
+}
+
+func (a *API) handleGetCardCommentReactions(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /cards/{cardID}/comments/reactions getCardCommentReactions
+	//
+	// Returns the reactions to all the comments of a card
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: cardID
+	//   in: path
+	//   description: Card ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/CommentReaction"
+	//   '404':
+	//     description: card not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	cardID := mux.Vars(r)["cardID"]
+
+	card, err := a.app.GetCardByID(cardID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	if !a.permissions.HasPermissionToBoard(userID, card.BoardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to card"))
+		return
+	}
+
+	reactions, err := a.app.GetCardCommentReactions(card.BoardID, card.ID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(reactions)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
@@ -0,0 +1,353 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) handleCreateExportJob(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /teams/{teamID}/archive/exports createExportJob
+	//
+	// Starts an export of all the boards of a team the user can see to an archive. The archive is
+	// written in the background, and can be downloaded once the export is done, until it expires.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the export options
+	//   required: false
+	//   schema:
+	//     "$ref": "#/definitions/ExportJobOptions"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ExportJob"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if a.MattermostAuth {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted in plugin mode"))
+		return
+	}
+
+	userID := getUserID(r)
+	teamID := mux.Vars(r)["teamID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var opts model.ExportJobOptions
+	if len(requestBody) > 0 {
+		if err = json.Unmarshal(requestBody, &opts); err != nil {
+			a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "createExportJob", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("TeamID", teamID)
+	auditRec.AddMeta("excludeFiles", opts.ExcludeFiles)
+	auditRec.AddMeta("excludeHistory", opts.ExcludeHistory)
+
+	isGuest, err := a.userIsGuest(userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	boards, err := a.app.GetBoardsForUserAndTeam(userID, teamID, !isGuest)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	ids := []string{}
+	for _, board := range boards {
+		ids = append(ids, board.ID)
+	}
+
+	job, err := a.app.CreateExportJob(teamID, userID, ids, opts)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("CreateExportJob", mlog.String("teamID", teamID), mlog.String("jobID", job.ID), mlog.Int("boards", len(ids)))
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("jobID", job.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleGetExportJobs(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /teams/{teamID}/archive/exports getExportJobs
+	//
+	// Returns the exports of a team requested by the user, the most recent first.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/ExportJob"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	teamID := mux.Vars(r)["teamID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
+		return
+	}
+
+	jobs, err := a.app.GetExportJobsForUser(teamID, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(jobs)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleGetExportJob(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /teams/{teamID}/archive/exports/{exportID} getExportJob
+	//
+	// Returns the status and progress of an export requested by the user.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: exportID
+	//   in: path
+	//   description: Export ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ExportJob"
+	//   '404':
+	//     description: export not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	teamID := vars["teamID"]
+	exportID := vars["exportID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
+		return
+	}
+
+	job, err := a.app.GetExportJob(teamID, exportID, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleDownloadExport(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /teams/{teamID}/archive/exports/{exportID}/download downloadExport
+	//
+	// Downloads the archive of an export requested by the user, once it is done.
+	//
+	// ---
+	// produces:
+	// - application/octet-stream
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: exportID
+	//   in: path
+	//   description: Export ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     content:
+	//       application-octet-stream:
+	//         type: string
+	//         format: binary
+	//   '400':
+	//     description: export not done
+	//   '404':
+	//     description: export not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	teamID := vars["teamID"]
+	exportID := vars["exportID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "downloadExport", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("TeamID", teamID)
+	auditRec.AddMeta("jobID", exportID)
+
+	job, reader, err := a.app.GetExportArchive(teamID, exportID, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	defer reader.Close()
+
+	createAt := time.UnixMilli(job.CreateAt)
+	filename := fmt.Sprintf("archive-%s%s", createAt.Format("2006-01-02"), archiveExtension)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+	w.Header().Set("Content-Transfer-Encoding", "binary")
+
+	http.ServeContent(w, r, filename, time.UnixMilli(job.UpdateAt), reader)
+
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteExportJob(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /teams/{teamID}/archive/exports/{exportID} deleteExportJob
+	//
+	// Deletes a finished export requested by the user, along with its archive.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: exportID
+	//   in: path
+	//   description: Export ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: export not finished
+	//   '404':
+	//     description: export not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+	teamID := vars["teamID"]
+	exportID := vars["exportID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteExportJob", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("TeamID", teamID)
+	auditRec.AddMeta("jobID", exportID)
+
+	if err := a.app.DeleteExportJob(teamID, exportID, userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
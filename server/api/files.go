@@ -77,7 +77,9 @@ func (a *API) registerFilesRoutes(r *mux.Router) {
 	// Files API
 	r.HandleFunc("/files/teams/{teamID}/{boardID}/{filename}", a.attachSession(a.handleServeFile, false)).Methods("GET")
 	r.HandleFunc("/files/teams/{teamID}/{boardID}/{filename}/info", a.attachSession(a.getFileInfo, false)).Methods("GET")
+	r.HandleFunc("/files/teams/{teamID}/{boardID}/{filename}/signed_url", a.sessionRequired(a.handleSignFileURL)).Methods("POST")
 	r.HandleFunc("/teams/{teamID}/{boardID}/files", a.sessionRequired(a.handleUploadFile)).Methods("POST")
+	r.HandleFunc("/admin/files/gc", a.sessionRequired(a.handleCollectOrphanedFiles)).Methods("POST")
 }
 
 func (a *API) handleServeFile(w http.ResponseWriter, r *http.Request) {
@@ -107,11 +109,34 @@ func (a *API) handleServeFile(w http.ResponseWriter, r *http.Request) {
 	//   description: name of the file
 	//   required: true
 	//   type: string
+	// - name: size
+	//   in: query
+	//   description: Rendition of an image to return, thumb or preview. Files without renditions are returned as uploaded
+	//   required: false
+	//   type: string
+	// - name: expires
+	//   in: query
+	//   description: Expiry of a signed URL, in milliseconds since the current epoch
+	//   required: false
+	//   type: integer
+	// - name: signature
+	//   in: query
+	//   description: Signature of a signed URL, which grants access to the file without a session
+	//   required: false
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
+	//   '206':
+	//     description: partial content, for range requests
+	//   '302':
+	//     description: redirect to the files storage, for signed URLs when enabled
+	//   '400':
+	//     description: invalid size
+	//   '401':
+	//     description: invalid or expired signature
 	//   '404':
 	//     description: file not found
 	//   default:
@@ -120,19 +145,36 @@ func (a *API) handleServeFile(w http.ResponseWriter, r *http.Request) {
 	//       "$ref": "#/definitions/ErrorResponse"
 
 	vars := mux.Vars(r)
+	teamID := vars["teamID"]
 	boardID := vars["boardID"]
 	filename := vars["filename"]
 	userID := getUserID(r)
+	query := r.URL.Query()
 
-	hasValidReadToken := a.hasValidReadTokenForBoard(r, boardID)
-	if userID == "" && !hasValidReadToken {
-		a.errorResponse(w, r, model.NewErrUnauthorized("access denied to board"))
+	rendition := model.FileRendition(query.Get("size"))
+	if !rendition.IsValid() {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid size, must be thumb or preview"))
 		return
 	}
 
-	if !hasValidReadToken && !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
-		a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
-		return
+	signature := query.Get(app.FileURLSignatureParam)
+	isSigned := signature != ""
+	if isSigned {
+		if err := a.app.CheckFileURLSignature(teamID, boardID, filename, query.Get(app.FileURLExpiresParam), signature); err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+	} else {
+		hasValidReadToken := a.hasValidReadTokenForBoard(r, boardID)
+		if userID == "" && !hasValidReadToken {
+			a.errorResponse(w, r, model.NewErrUnauthorized("access denied to board"))
+			return
+		}
+
+		if !hasValidReadToken && !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+			a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
+			return
+		}
 	}
 
 	board, err := a.app.GetBoard(boardID)
@@ -146,8 +188,23 @@ func (a *API) handleServeFile(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("boardID", boardID)
 	auditRec.AddMeta("teamID", board.TeamID)
 	auditRec.AddMeta("filename", filename)
+	auditRec.AddMeta("size", string(rendition))
+	auditRec.AddMeta("signed", isSigned)
 
-	fileInfo, fileReader, err := a.app.GetFile(board.TeamID, boardID, filename)
+	if isSigned && rendition == model.FileRenditionOriginal {
+		presignedURL, err := a.app.GetFilePresignedURL(board.TeamID, boardID, filename)
+		if err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+		if presignedURL != "" {
+			http.Redirect(w, r, presignedURL, http.StatusFound)
+			auditRec.Success()
+			return
+		}
+	}
+
+	fileInfo, fileReader, err := a.app.GetFileRendition(board.TeamID, boardID, filename, rendition)
 	if err != nil && !model.IsErrNotFound(err) {
 		a.errorResponse(w, r, err)
 		return
@@ -178,11 +235,113 @@ func (a *API) handleServeFile(w http.ResponseWriter, r *http.Request) {
 
 	mimeType := ""
 	var fileSize int64
+	lastModification := time.Now()
 	if fileInfo != nil {
 		mimeType = fileInfo.MimeType
 		fileSize = fileInfo.Size
+		// uploaded files never change, which lets clients resume
+		// downloads with range requests
+		if fileInfo.CreateAt > 0 {
+			lastModification = time.UnixMilli(fileInfo.CreateAt)
+		}
+		if hash, ok := model.FileBlobHash(fileInfo.Path); ok {
+			w.Header().Set("ETag", `"`+hash+`"`)
+		}
+	}
+	writeFileResponse(filename, mimeType, fileSize, lastModification, "", fileReader, false, w, r)
+	auditRec.Success()
+}
+
+func (a *API) handleSignFileURL(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /files/teams/{teamID}/{boardID}/{filename}/signed_url signFileURL
+	//
+	// Returns a short-lived URL to download an uploaded file without a session
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: filename
+	//   in: path
+	//   description: name of the file
+	//   required: true
+	//   type: string
+	// - name: expires_in
+	//   in: query
+	//   description: Number of seconds the URL is valid for, capped to the lifetime of signed URLs of the server
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/FileSignedURL"
+	//   '501':
+	//     description: the server has no secret to sign URLs with
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	filename := vars["filename"]
+	userID := getUserID(r)
+
+	var expiresIn time.Duration
+	if expiresInStr := r.URL.Query().Get("expires_in"); expiresInStr != "" {
+		seconds, err := strconv.Atoi(expiresInStr)
+		if err != nil || seconds <= 0 {
+			a.errorResponse(w, r, model.NewErrBadRequest("invalid expires_in, must be a positive number of seconds"))
+			return
+		}
+		expiresIn = time.Duration(seconds) * time.Second
+	}
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
+		return
+	}
+
+	board, err := a.app.GetBoard(boardID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
 	}
-	writeFileResponse(filename, mimeType, fileSize, time.Now(), "", fileReader, false, w, r)
+
+	auditRec := a.makeAuditRecord(r, "signFileURL", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("teamID", board.TeamID)
+	auditRec.AddMeta("filename", filename)
+
+	signedURL, err := a.app.SignFileURL(board.TeamID, boardID, filename, expiresIn)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(signedURL)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("expiresAt", signedURL.ExpiresAt)
 	auditRec.Success()
 }
 
@@ -393,7 +552,7 @@ func (a *API) handleUploadFile(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("teamID", board.TeamID)
 	auditRec.AddMeta("filename", handle.Filename)
 
-	fileID, err := a.app.SaveFile(file, board.TeamID, boardID, handle.Filename, board.IsTemplate)
+	fileID, err := a.app.SaveFile(file, board.TeamID, boardID, userID, handle.Filename, board.IsTemplate)
 	if err != nil {
 		a.errorResponse(w, r, err)
 		return
@@ -414,3 +573,60 @@ func (a *API) handleUploadFile(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("fileID", fileID)
 	auditRec.Success()
 }
+
+func (a *API) handleCollectOrphanedFiles(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /admin/files/gc collectOrphanedFiles
+	//
+	// Removes the files that are no longer attached to any block, or only reports them. Requires the manage system permission.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: dry_run
+	//   in: query
+	//   description: Only report the orphaned files without removing them
+	//   required: false
+	//   type: boolean
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/FileGCReport"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	dryRun := r.URL.Query().Get("dry_run") == True
+
+	if !a.permissions.HasPermissionTo(userID, mmModel.PermissionManageSystem) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to file garbage collection"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "collectOrphanedFiles", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("dryRun", dryRun)
+
+	report, err := a.app.CollectOrphanedFiles(dryRun)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("orphans", len(report.Orphans))
+	auditRec.AddMeta("removed", report.RemovedCount)
+	auditRec.Success()
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const inboxMaxPerPage = 200
+
+func (a *API) registerInboxRoutes(r *mux.Router) {
+	r.HandleFunc("/teams/{teamID}/inbox", a.sessionRequired(a.handleGetInbox)).Methods("GET")
+	r.HandleFunc("/teams/{teamID}/inbox/read", a.sessionRequired(a.handleMarkInboxRead)).Methods("POST")
+}
+
+func (a *API) handleGetInbox(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /teams/{teamID}/inbox getInbox
+	//
+	// Returns the inbox items of the current user in a team, newest first
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: unread_only
+	//   in: query
+	//   description: Only return the unread items
+	//   required: false
+	//   type: boolean
+	// - name: before
+	//   in: query
+	//   description: Only return items created before this timestamp, use the createAt of the last item of the previous page to paginate
+	//   required: false
+	//   type: integer
+	// - name: per_page
+	//   in: query
+	//   description: Number of items per page (default=100)
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Inbox"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	teamID := mux.Vars(r)["teamID"]
+
+	query := r.URL.Query()
+	unreadOnly := query.Get("unread_only") == True
+	strBefore := query.Get("before")
+	strPerPage := query.Get("per_page")
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
+		return
+	}
+
+	var before int64
+	if strBefore != "" {
+		var err error
+		before, err = strconv.ParseInt(strBefore, 10, 64)
+		if err != nil {
+			message := fmt.Sprintf("invalid `before` parameter: %s", err)
+			a.errorResponse(w, r, model.NewErrBadRequest(message))
+			return
+		}
+	}
+
+	if strPerPage == "" {
+		strPerPage = defaultPerPage
+	}
+	perPage, err := strconv.Atoi(strPerPage)
+	if err != nil || perPage <= 0 || perPage > inboxMaxPerPage {
+		message := fmt.Sprintf("invalid `per_page` parameter, must be between 1 and %d", inboxMaxPerPage)
+		a.errorResponse(w, r, model.NewErrBadRequest(message))
+		return
+	}
+
+	inbox, err := a.app.GetInbox(userID, teamID, unreadOnly, before, perPage)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("GetInbox",
+		mlog.String("teamID", teamID),
+		mlog.String("userID", userID),
+		mlog.Int("items", len(inbox.Items)),
+	)
+
+	data, err := json.Marshal(inbox)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleMarkInboxRead(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /teams/{teamID}/inbox/read markInboxRead
+	//
+	// Marks inbox items of the current user in a team as read
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the items to mark as read, all the items of the team are marked if the list is empty
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/InboxReadRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, returns the new unread count
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	teamID := mux.Vars(r)["teamID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var readRequest model.InboxReadRequest
+	if len(requestBody) != 0 {
+		if err = json.Unmarshal(requestBody, &readRequest); err != nil {
+			a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "markInboxRead", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+	auditRec.AddMeta("itemCount", len(readRequest.IDs))
+
+	unreadCount, err := a.app.MarkInboxItemsRead(userID, teamID, readRequest.IDs)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(map[string]int{"unreadCount": unreadCount})
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) registerPresenceRoutes(r *mux.Router) {
+	r.HandleFunc("/boards/{boardID}/presence", a.sessionRequired(a.handleGetBoardPresence)).Methods("GET")
+}
+
+func (a *API) handleGetBoardPresence(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/presence getBoardPresence
+	//
+	// Returns the users currently looking at a board
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: card_id
+	//   in: query
+	//   description: Only return the users that have this card open
+	//   required: false
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/BoardPresence"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	boardID := mux.Vars(r)["boardID"]
+	cardID := r.URL.Query().Get("card_id")
+
+	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
+		return
+	}
+
+	presences := a.app.GetBoardPresence(boardID, cardID)
+
+	a.logger.Debug("GetBoardPresence",
+		mlog.String("boardID", boardID),
+		mlog.Int("presenceCount", len(presences)),
+	)
+
+	data, err := json.Marshal(presences)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) registerStorageRoutes(r *mux.Router) {
+	r.HandleFunc("/teams/{teamID}/storage", a.sessionRequired(a.handleGetStorageUsage)).Methods("GET")
+	r.HandleFunc("/teams/{teamID}/storage/quota", a.sessionRequired(a.handleSetTeamStorageQuota)).Methods("PUT")
+	r.HandleFunc("/teams/{teamID}/storage/quota", a.sessionRequired(a.handleDeleteTeamStorageQuota)).Methods("DELETE")
+	r.HandleFunc("/boards/{boardID}/storage/quota", a.sessionRequired(a.handleSetBoardStorageQuota)).Methods("PUT")
+	r.HandleFunc("/boards/{boardID}/storage/quota", a.sessionRequired(a.handleDeleteBoardStorageQuota)).Methods("DELETE")
+}
+
+func (a *API) handleGetStorageUsage(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /teams/{teamID}/storage getStorageUsage
+	//
+	// Returns the size of the files stored in a team, by board and by user, with their quotas
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/StorageUsage"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	teamID := mux.Vars(r)["teamID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionManageTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team storage usage"))
+		return
+	}
+
+	storageUsage, err := a.app.GetStorageUsage(teamID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("GetStorageUsage",
+		mlog.String("teamID", teamID),
+		mlog.Int("totalSize", storageUsage.TotalSize),
+	)
+
+	data, err := json.Marshal(storageUsage)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleSetTeamStorageQuota(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /teams/{teamID}/storage/quota setTeamStorageQuota
+	//
+	// Sets the storage quota of a team, overriding the default one. Requires the manage system permission.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the quota, zero for unlimited
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/StorageQuotaPatch"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/StorageQuota"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	a.setStorageQuota(w, r)
+}
+
+func (a *API) handleSetBoardStorageQuota(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /boards/{boardID}/storage/quota setBoardStorageQuota
+	//
+	// Sets the storage quota of a board, overriding the default one. Requires the manage team permission.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the quota, zero for unlimited
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/StorageQuotaPatch"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/StorageQuota"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	a.setStorageQuota(w, r)
+}
+
+// setStorageQuota overrides the team or board storage quota of a request.
+func (a *API) setStorageQuota(w http.ResponseWriter, r *http.Request) {
+	quota, err := a.getStorageQuotaTarget(r)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var patch model.StorageQuotaPatch
+	if err = json.Unmarshal(requestBody, &patch); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+	quota.MaxSize = patch.MaxSize
+
+	auditRec := a.makeAuditRecord(r, "setStorageQuota", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("scope", quota.Scope)
+	auditRec.AddMeta("scopeID", quota.ScopeID)
+	auditRec.AddMeta("maxSize", quota.MaxSize)
+
+	if err = a.app.SetStorageQuota(quota); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(quota)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteTeamStorageQuota(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /teams/{teamID}/storage/quota deleteTeamStorageQuota
+	//
+	// Removes the storage quota of a team, which goes back to the default one. Requires the manage system permission.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	a.deleteStorageQuota(w, r)
+}
+
+func (a *API) handleDeleteBoardStorageQuota(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /boards/{boardID}/storage/quota deleteBoardStorageQuota
+	//
+	// Removes the storage quota of a board, which goes back to the default one. Requires the manage team permission.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	a.deleteStorageQuota(w, r)
+}
+
+// deleteStorageQuota removes the team or board storage quota of a request.
+func (a *API) deleteStorageQuota(w http.ResponseWriter, r *http.Request) {
+	quota, err := a.getStorageQuotaTarget(r)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteStorageQuota", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("scope", quota.Scope)
+	auditRec.AddMeta("scopeID", quota.ScopeID)
+
+	if err = a.app.DeleteStorageQuota(quota.Scope, quota.ScopeID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	auditRec.Success()
+}
+
+// getStorageQuotaTarget returns the team or board quota a request is
+// for, if the user is allowed to change it. Team quotas are set by
+// system admins, board quotas by the admins of their team.
+func (a *API) getStorageQuotaTarget(r *http.Request) (*model.StorageQuota, error) {
+	userID := getUserID(r)
+	vars := mux.Vars(r)
+
+	if boardID, ok := vars["boardID"]; ok {
+		board, err := a.app.GetBoard(boardID)
+		if err != nil {
+			return nil, err
+		}
+		if !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionManageTeam) {
+			return nil, model.NewErrPermission("access denied to board storage quota")
+		}
+		return &model.StorageQuota{
+			Scope:   model.StorageQuotaScopeBoard,
+			ScopeID: board.ID,
+			TeamID:  board.TeamID,
+		}, nil
+	}
+
+	teamID := vars["teamID"]
+	if !a.permissions.HasPermissionTo(userID, mm_model.PermissionManageSystem) {
+		return nil, model.NewErrPermission("access denied to team storage quota")
+	}
+	return &model.StorageQuota{
+		Scope:   model.StorageQuotaScopeTeam,
+		ScopeID: teamID,
+		TeamID:  teamID,
+	}, nil
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) registerUploadPolicyRoutes(r *mux.Router) {
+	r.HandleFunc("/teams/{teamID}/upload_policy", a.sessionRequired(a.handleGetUploadPolicy)).Methods("GET")
+	r.HandleFunc("/teams/{teamID}/upload_policy", a.sessionRequired(a.handleSaveUploadPolicy)).Methods("PUT")
+	r.HandleFunc("/teams/{teamID}/upload_policy", a.sessionRequired(a.handleDeleteUploadPolicy)).Methods("DELETE")
+}
+
+func (a *API) handleGetUploadPolicy(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /teams/{teamID}/upload_policy getUploadPolicy
+	//
+	// Returns the types of files that can be uploaded in a team
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/UploadPolicy"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	teamID := mux.Vars(r)["teamID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team upload policy"))
+		return
+	}
+
+	policy, err := a.app.GetUploadPolicy(teamID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("GetUploadPolicy", mlog.String("teamID", teamID))
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleSaveUploadPolicy(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /teams/{teamID}/upload_policy saveUploadPolicy
+	//
+	// Sets the types of files that can be uploaded in a team, overriding the default ones. Requires the manage team permission.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the upload policy
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/UploadPolicy"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/UploadPolicy"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	teamID := mux.Vars(r)["teamID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionManageTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team upload policy"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var policy model.UploadPolicy
+	if err = json.Unmarshal(requestBody, &policy); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+	policy.TeamID = teamID
+
+	auditRec := a.makeAuditRecord(r, "saveUploadPolicy", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+	auditRec.AddMeta("allowedMimeTypes", policy.AllowedMimeTypes)
+	auditRec.AddMeta("deniedMimeTypes", policy.DeniedMimeTypes)
+	auditRec.AddMeta("allowedExtensions", policy.AllowedExtensions)
+	auditRec.AddMeta("deniedExtensions", policy.DeniedExtensions)
+
+	if err = a.app.SaveUploadPolicy(&policy); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteUploadPolicy(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /teams/{teamID}/upload_policy deleteUploadPolicy
+	//
+	// Removes the upload policy of a team, which goes back to the default one. Requires the manage team permission.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := getUserID(r)
+	teamID := mux.Vars(r)["teamID"]
+
+	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionManageTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team upload policy"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteUploadPolicy", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+
+	if err := a.app.DeleteUploadPolicy(teamID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	auditRec.Success()
+}
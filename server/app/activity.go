@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify/notifysubscriptions"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// GetActivity returns a page of the activity of a board, or of one of its cards, built
+// by diffing each block revision in the history with the revision preceding it.
+func (a *App) GetActivity(boardID string, opts model.QueryActivityOptions) (*model.ActivityFeed, error) {
+	board, err := a.store.GetBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := model.ParsePropertySchema(board)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse property schema for board %s: %w", boardID, err)
+	}
+
+	// revisions are sorted by timestamp and block id, so the next page starts after
+	// the last revision of this one even when several revisions share its timestamp
+	revisions, err := a.store.GetBlockHistoryDescendants(boardID, model.QueryBlockHistoryOptions{
+		CardID:         opts.CardID,
+		BeforeUpdateAt: opts.BeforeUpdateAt,
+		BeforeID:       opts.BeforeID,
+		Limit:          uint64(opts.PerPage + 1),
+		Descending:     true,
+		SortByUpdateAt: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &model.ActivityFeed{Entries: []*model.ActivityEntry{}}
+	if len(revisions) > opts.PerPage {
+		revisions = revisions[:opts.PerPage]
+		feed.HasMore = true
+		feed.NextBefore = revisions[opts.PerPage-1].UpdateAt
+		feed.NextBeforeID = revisions[opts.PerPage-1].ID
+	}
+
+	// revisions are walked oldest first, so the previous revision of a block is
+	// only fetched from the store for its oldest revision in the page
+	entries := make([][]*model.ActivityEntry, len(revisions))
+	latest := map[string]*model.Block{}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		revision := revisions[i]
+		previous, ok := latest[revision.ID]
+		if !ok {
+			history, err := a.store.GetBlockHistory(revision.ID, model.QueryBlockHistoryOptions{
+				BeforeUpdateAt: revision.UpdateAt,
+				Limit:          1,
+				Descending:     true,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not get block history for block %s: %w", revision.ID, err)
+			}
+			if len(history) != 0 {
+				previous = history[0]
+			}
+		}
+
+		entries[i] = activityEntriesForRevision(previous, revision, schema, a.store, a.logger)
+		latest[revision.ID] = revision
+	}
+
+	for _, revisionEntries := range entries {
+		feed.Entries = append(feed.Entries, revisionEntries...)
+	}
+	return feed, nil
+}
+
+// activityEntriesForRevision returns the activity entries describing the changes between
+// a block revision and the one preceding it, which is nil if the block was just created.
+func activityEntriesForRevision(oldBlock, newBlock *model.Block, schema model.PropSchema, resolver model.PropValueResolver, logger mlog.LoggerIFace) []*model.ActivityEntry {
+	cardID := newBlock.ParentID
+	if newBlock.Type == model.TypeCard {
+		cardID = newBlock.ID
+	}
+	newEntry := func(activityType model.ActivityType, oldValue, newValue string) *model.ActivityEntry {
+		return &model.ActivityEntry{
+			Type:      activityType,
+			BoardID:   newBlock.BoardID,
+			CardID:    cardID,
+			BlockID:   newBlock.ID,
+			BlockType: newBlock.Type,
+			UserID:    newBlock.ModifiedBy,
+			At:        newBlock.UpdateAt,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+		}
+	}
+
+	created := oldBlock == nil || oldBlock.DeleteAt != 0
+	deleted := newBlock.DeleteAt != 0
+
+	switch newBlock.Type {
+	case model.TypeBoard, model.TypeView:
+		return nil
+
+	case model.TypeCard:
+		switch {
+		case deleted:
+			return []*model.ActivityEntry{newEntry(model.ActivityCardDeleted, newBlock.Title, "")}
+		case created:
+			return []*model.ActivityEntry{newEntry(model.ActivityCardCreated, "", newBlock.Title)}
+		}
+
+		entries := []*model.ActivityEntry{}
+		if oldBlock.BoardID != newBlock.BoardID {
+			// the properties of a moved card are mapped to another schema, so
+			// they are not compared
+			return append(entries, newEntry(model.ActivityCardMoved, oldBlock.BoardID, newBlock.BoardID))
+		}
+		if oldBlock.Title != newBlock.Title {
+			entries = append(entries, newEntry(model.ActivityTitleChanged, oldBlock.Title, newBlock.Title))
+		}
+		if wasArchived, isArchived := model.IsArchivedCard(oldBlock), model.IsArchivedCard(newBlock); wasArchived != isArchived {
+			if isArchived {
+				entries = append(entries, newEntry(model.ActivityCardArchived, "", ""))
+			} else {
+				entries = append(entries, newEntry(model.ActivityCardUnarchived, "", ""))
+			}
+		}
+		for _, propDiff := range notifysubscriptions.GeneratePropDiffs(oldBlock, newBlock, schema, resolver, logger) {
+			entry := newEntry(model.ActivityPropertyChanged, propDiff.OldValue, propDiff.NewValue)
+			entry.PropertyID = propDiff.ID
+			entry.PropertyName = propDiff.Name
+			entries = append(entries, entry)
+		}
+		return entries
+
+	case model.TypeComment:
+		switch {
+		case deleted:
+			return []*model.ActivityEntry{newEntry(model.ActivityCommentDeleted, newBlock.Title, "")}
+		case created:
+			return []*model.ActivityEntry{newEntry(model.ActivityCommentAdded, "", newBlock.Title)}
+		case oldBlock.Title != newBlock.Title:
+			return []*model.ActivityEntry{newEntry(model.ActivityCommentEdited, oldBlock.Title, newBlock.Title)}
+		}
+
+	case model.TypeAttachment:
+		switch {
+		case deleted:
+			return []*model.ActivityEntry{newEntry(model.ActivityAttachmentRemoved, newBlock.Title, "")}
+		case created:
+			return []*model.ActivityEntry{newEntry(model.ActivityAttachmentAdded, "", newBlock.Title)}
+		}
+
+	default:
+		switch {
+		case deleted:
+			return []*model.ActivityEntry{newEntry(model.ActivityContentDeleted, newBlock.Title, "")}
+		case created:
+			return []*model.ActivityEntry{newEntry(model.ActivityContentAdded, "", newBlock.Title)}
+		case oldBlock.Title != newBlock.Title || !reflect.DeepEqual(oldBlock.Fields, newBlock.Fields):
+			return []*model.ActivityEntry{newEntry(model.ActivityContentChanged, oldBlock.Title, newBlock.Title)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func TestActivityEntriesForRevision(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(t)
+	schema, err := model.ParsePropertySchema(makeTestBoard("board-id", ""))
+	require.NoError(t, err)
+
+	revision := func(block *model.Block, title string, updateAt int64) *model.Block {
+		b := *block
+		b.Title = title
+		b.UpdateAt = updateAt
+		b.ModifiedBy = "user-id"
+		return &b
+	}
+	card := makeTestCard("card-id", "todo")
+	comment := &model.Block{ID: "comment-id", BoardID: "board-id", ParentID: "card-id", Type: model.TypeComment}
+
+	t.Run("card created", func(t *testing.T) {
+		entries := activityEntriesForRevision(nil, revision(card, "Card", 1000), schema, nil, logger)
+		require.Len(t, entries, 1)
+		require.Equal(t, &model.ActivityEntry{
+			Type:      model.ActivityCardCreated,
+			BoardID:   "board-id",
+			CardID:    "card-id",
+			BlockID:   "card-id",
+			BlockType: model.TypeCard,
+			UserID:    "user-id",
+			At:        1000,
+			NewValue:  "Card",
+		}, entries[0])
+	})
+
+	t.Run("card title and property changed", func(t *testing.T) {
+		newCard := revision(makeTestCard("card-id", "doing"), "New title", 2000)
+		entries := activityEntriesForRevision(revision(card, "Card", 1000), newCard, schema, nil, logger)
+		require.Len(t, entries, 2)
+		require.Equal(t, model.ActivityTitleChanged, entries[0].Type)
+		require.Equal(t, "Card", entries[0].OldValue)
+		require.Equal(t, "New title", entries[0].NewValue)
+		require.Equal(t, model.ActivityPropertyChanged, entries[1].Type)
+		require.Equal(t, "status", entries[1].PropertyID)
+		require.Equal(t, "Status", entries[1].PropertyName)
+		require.Equal(t, "TO DO", entries[1].OldValue)
+		require.Equal(t, "DOING", entries[1].NewValue)
+	})
+
+	t.Run("card archived", func(t *testing.T) {
+		archived := revision(makeTestCard("card-id", "todo"), "Card", 2000)
+		archived.Fields[model.CardArchivedAtField] = float64(2000)
+		entries := activityEntriesForRevision(revision(card, "Card", 1000), archived, schema, nil, logger)
+		require.Len(t, entries, 1)
+		require.Equal(t, model.ActivityCardArchived, entries[0].Type)
+	})
+
+	t.Run("card moved", func(t *testing.T) {
+		moved := revision(card, "Card", 2000)
+		moved.BoardID = "other-board-id"
+		entries := activityEntriesForRevision(revision(card, "Card", 1000), moved, schema, nil, logger)
+		require.Len(t, entries, 1)
+		require.Equal(t, model.ActivityCardMoved, entries[0].Type)
+		require.Equal(t, "board-id", entries[0].OldValue)
+		require.Equal(t, "other-board-id", entries[0].NewValue)
+	})
+
+	t.Run("comment lifecycle", func(t *testing.T) {
+		added := revision(comment, "Hello", 1000)
+		edited := revision(comment, "Hello world", 2000)
+		deleted := revision(comment, "Hello world", 3000)
+		deleted.DeleteAt = 3000
+
+		entries := activityEntriesForRevision(nil, added, schema, nil, logger)
+		require.Equal(t, model.ActivityCommentAdded, entries[0].Type)
+		require.Equal(t, "card-id", entries[0].CardID)
+
+		entries = activityEntriesForRevision(added, edited, schema, nil, logger)
+		require.Equal(t, model.ActivityCommentEdited, entries[0].Type)
+		require.Equal(t, "Hello", entries[0].OldValue)
+
+		entries = activityEntriesForRevision(edited, deleted, schema, nil, logger)
+		require.Equal(t, model.ActivityCommentDeleted, entries[0].Type)
+		require.Equal(t, "Hello world", entries[0].OldValue)
+	})
+
+	t.Run("unchanged revisions and views produce no entries", func(t *testing.T) {
+		require.Empty(t, activityEntriesForRevision(revision(card, "Card", 1000), revision(card, "Card", 2000), schema, nil, logger))
+
+		view := &model.Block{ID: "view-id", BoardID: "board-id", Type: model.TypeView}
+		require.Empty(t, activityEntriesForRevision(nil, view, schema, nil, logger))
+	})
+}
+
+func TestGetActivity(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	board := makeTestBoard("board-id", "")
+	created := makeTestCard("card-id", "todo")
+	created.Title = "Card"
+	created.UpdateAt = 1000
+	renamed := makeTestCard("card-id", "todo")
+	renamed.Title = "Renamed"
+	renamed.UpdateAt = 2000
+	comment := &model.Block{ID: "comment-id", BoardID: "board-id", ParentID: "card-id", Type: model.TypeComment, Title: "Hi", UpdateAt: 2000}
+	moved := makeTestCard("card-id", "doing")
+	moved.Title = "Renamed"
+	moved.UpdateAt = 3000
+
+	t.Run("returns the entries newest first", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlockHistoryDescendants("board-id", model.QueryBlockHistoryOptions{
+			CardID:         "card-id",
+			Limit:          101,
+			Descending:     true,
+			SortByUpdateAt: true,
+		}).Return([]*model.Block{comment, renamed, created}, nil)
+		th.Store.EXPECT().GetBlockHistory("comment-id", gomock.Any()).Return([]*model.Block{}, nil)
+		th.Store.EXPECT().GetBlockHistory("card-id", gomock.Any()).Return([]*model.Block{}, nil)
+
+		feed, err := th.App.GetActivity("board-id", model.QueryActivityOptions{CardID: "card-id", PerPage: 100})
+		require.NoError(t, err)
+		require.False(t, feed.HasMore)
+		require.Len(t, feed.Entries, 3)
+		require.Equal(t, model.ActivityCommentAdded, feed.Entries[0].Type)
+		require.Equal(t, model.ActivityTitleChanged, feed.Entries[1].Type)
+		require.Equal(t, model.ActivityCardCreated, feed.Entries[2].Type)
+	})
+
+	t.Run("pages continue after the last revision made at the same time", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlockHistoryDescendants("board-id", model.QueryBlockHistoryOptions{
+			Limit:          3,
+			Descending:     true,
+			SortByUpdateAt: true,
+		}).Return([]*model.Block{moved, comment, renamed}, nil)
+		th.Store.EXPECT().GetBlockHistory("comment-id", gomock.Any()).Return([]*model.Block{}, nil)
+		th.Store.EXPECT().GetBlockHistory("card-id", model.QueryBlockHistoryOptions{
+			BeforeUpdateAt: 3000,
+			Limit:          1,
+			Descending:     true,
+		}).Return([]*model.Block{renamed}, nil)
+
+		feed, err := th.App.GetActivity("board-id", model.QueryActivityOptions{PerPage: 2})
+		require.NoError(t, err)
+		require.True(t, feed.HasMore)
+		require.Equal(t, int64(2000), feed.NextBefore)
+		require.Equal(t, "comment-id", feed.NextBeforeID)
+		require.Len(t, feed.Entries, 2)
+		require.Equal(t, model.ActivityPropertyChanged, feed.Entries[0].Type)
+		require.Equal(t, model.ActivityCommentAdded, feed.Entries[1].Type)
+
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlockHistoryDescendants("board-id", model.QueryBlockHistoryOptions{
+			BeforeUpdateAt: 2000,
+			BeforeID:       "comment-id",
+			Limit:          3,
+			Descending:     true,
+			SortByUpdateAt: true,
+		}).Return([]*model.Block{renamed, created}, nil)
+		th.Store.EXPECT().GetBlockHistory("card-id", model.QueryBlockHistoryOptions{
+			BeforeUpdateAt: 1000,
+			Limit:          1,
+			Descending:     true,
+		}).Return([]*model.Block{}, nil)
+
+		feed, err = th.App.GetActivity("board-id", model.QueryActivityOptions{PerPage: 2, BeforeUpdateAt: 2000, BeforeID: "comment-id"})
+		require.NoError(t, err)
+		require.False(t, feed.HasMore)
+		require.Len(t, feed.Entries, 2)
+		require.Equal(t, model.ActivityTitleChanged, feed.Entries[0].Type)
+		require.Equal(t, model.ActivityCardCreated, feed.Entries[1].Type)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/services/notify"
 	"github.com/mattermost/focalboard/server/services/permissions"
+	"github.com/mattermost/focalboard/server/services/scanner"
 	"github.com/mattermost/focalboard/server/services/store"
 	"github.com/mattermost/focalboard/server/services/webhook"
 	"github.com/mattermost/focalboard/server/utils"
@@ -28,6 +29,18 @@ const (
 	blockChangeNotifierQueueSize       = 1000
 	blockChangeNotifierPoolSize        = 10
 	blockChangeNotifierShutdownTimeout = time.Second * 10
+
+	uploadScanQueueSize       = 1000
+	uploadScanPoolSize        = 2
+	uploadScanShutdownTimeout = time.Second * 10
+
+	exportQueueSize       = 100
+	exportPoolSize        = 1
+	exportShutdownTimeout = time.Second * 10
+
+	automationQueueSize       = 1000
+	automationPoolSize        = 4
+	automationShutdownTimeout = time.Second * 10
 )
 
 type servicesAPI interface {
@@ -43,6 +56,8 @@ type fileBackend interface {
 	MoveFile(oldPath, newPath string) error
 	WriteFile(fr io.Reader, path string) (int64, error)
 	RemoveFile(path string) error
+	FileModTime(path string) (time.Time, error)
+	ListDirectoryRecursively(path string) ([]string, error)
 }
 
 type Services struct {
@@ -54,6 +69,7 @@ type Services struct {
 	Notifications    *notify.Service
 	Logger           mlog.LoggerIFace
 	Permissions      permissions.PermissionsService
+	Scanner          scanner.Scanner
 	SkipTemplateInit bool
 	ServicesAPI      servicesAPI
 }
@@ -70,11 +86,20 @@ type App struct {
 	logger              mlog.LoggerIFace
 	permissions         permissions.PermissionsService
 	blockChangeNotifier *utils.CallbackQueue
+	scanner             scanner.Scanner
+	uploadScanQueue     *utils.CallbackQueue
+	exportQueue         *utils.CallbackQueue
+	automationQueue     *utils.CallbackQueue
 	servicesAPI         servicesAPI
+	automationGuard     *automationGuard
 
 	cardLimitMux sync.RWMutex
 	cardLimit    int
 
+	// the exports queued or running on this server
+	exportJobsMux    sync.Mutex
+	activeExportJobs map[string]bool
+
 	// in‑memory storage for temporary Telegram verification codes
 	telegramVerificationMux   sync.RWMutex
 	telegramVerificationCodes map[string]telegramVerification
@@ -106,7 +131,13 @@ func New(config *config.Configuration, wsAdapter ws.Adapter, services Services)
 		logger:                    services.Logger,
 		permissions:               services.Permissions,
 		blockChangeNotifier:       utils.NewCallbackQueue("blockChangeNotifier", blockChangeNotifierQueueSize, blockChangeNotifierPoolSize, services.Logger),
+		scanner:                   services.Scanner,
+		uploadScanQueue:           utils.NewCallbackQueue("uploadScan", uploadScanQueueSize, uploadScanPoolSize, services.Logger),
+		exportQueue:               utils.NewCallbackQueue("export", exportQueueSize, exportPoolSize, services.Logger),
+		automationQueue:           utils.NewCallbackQueue("automation", automationQueueSize, automationPoolSize, services.Logger),
 		servicesAPI:               services.ServicesAPI,
+		automationGuard:           newAutomationGuard(),
+		activeExportJobs:          make(map[string]bool),
 		telegramVerificationCodes: make(map[string]telegramVerification),
 	}
 	app.initialize(services.SkipTemplateInit)
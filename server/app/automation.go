@@ -0,0 +1,528 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// maxAutomationChainDepth is the number of rules that may fire in a row because
+	// of changes made by other rules before the chain is cut off.
+	maxAutomationChainDepth = 3
+
+	// automationChainTTL is how long a pending automation change is remembered
+	// while waiting for its block change event.
+	automationChainTTL = int64(60 * 1000)
+
+	automationDueDateCheckedAtKey = "automation_due_date_checked_at"
+)
+
+var errAutomationNotificationsDisabled = errors.New("no notification channel is configured")
+
+// automationChain describes the sequence of rules that led to a block change.
+type automationChain struct {
+	depth    int
+	rules    map[string]bool
+	pending  int
+	expireAt int64
+}
+
+func (c automationChain) next(ruleID string) automationChain {
+	rules := make(map[string]bool, len(c.rules)+1)
+	for id := range c.rules {
+		rules[id] = true
+	}
+	rules[ruleID] = true
+	return automationChain{depth: c.depth + 1, rules: rules}
+}
+
+// automationGuard remembers which cards are about to be changed by automation rules,
+// so that the resulting block change events can be recognized as part of a chain.
+// This is what protects against rules triggering each other forever.
+type automationGuard struct {
+	mux    sync.Mutex
+	chains map[string]*automationChain
+}
+
+func newAutomationGuard() *automationGuard {
+	return &automationGuard{
+		chains: make(map[string]*automationChain),
+	}
+}
+
+// expect records that a rule is about to change the specified card.
+func (g *automationGuard) expect(cardID string, chain automationChain) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	now := utils.GetMillis()
+	existing, ok := g.chains[cardID]
+	if !ok || existing.expireAt < now {
+		chain.pending = 0
+		existing = &chain
+		g.chains[cardID] = existing
+	} else {
+		if chain.depth > existing.depth {
+			existing.depth = chain.depth
+		}
+		for id := range chain.rules {
+			existing.rules[id] = true
+		}
+	}
+	existing.pending++
+	existing.expireAt = now + automationChainTTL
+}
+
+// enter returns the chain a block change event for the specified card belongs to.
+// Changes that were not made by an automation rule start a new, empty chain.
+func (g *automationGuard) enter(cardID string) automationChain {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	existing, ok := g.chains[cardID]
+	if !ok {
+		return automationChain{}
+	}
+	if existing.expireAt < utils.GetMillis() {
+		delete(g.chains, cardID)
+		return automationChain{}
+	}
+
+	existing.pending--
+	if existing.pending <= 0 {
+		delete(g.chains, cardID)
+	}
+	return *existing
+}
+
+func (a *App) GetAutomationRulesForBoard(boardID string) ([]*model.AutomationRule, error) {
+	return a.store.GetAutomationRulesForBoard(boardID)
+}
+
+func (a *App) GetAutomationRule(boardID, ruleID string) (*model.AutomationRule, error) {
+	rule, err := a.store.GetAutomationRule(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.BoardID != boardID {
+		return nil, model.NewErrNotFound("automation rule ID=" + ruleID)
+	}
+	return rule, nil
+}
+
+func (a *App) CreateAutomationRule(rule *model.AutomationRule, userID string) (*model.AutomationRule, error) {
+	rule.ID = ""
+	rule.CreatedBy = userID
+	rule.ModifiedBy = userID
+	rule.CreateAt = 0
+	rule.UpdateAt = 0
+	rule.DeleteAt = 0
+	rule.Populate()
+
+	if err := rule.IsValid(); err != nil {
+		return nil, model.NewErrBadRequest(err.Error())
+	}
+
+	return a.store.CreateAutomationRule(rule)
+}
+
+func (a *App) PatchAutomationRule(boardID, ruleID string, patch *model.AutomationRulePatch, userID string) (*model.AutomationRule, error) {
+	rule, err := a.GetAutomationRule(boardID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule = patch.Patch(rule)
+	rule.ModifiedBy = userID
+	rule.UpdateAt = utils.GetMillis()
+
+	if err = rule.IsValid(); err != nil {
+		return nil, model.NewErrBadRequest(err.Error())
+	}
+
+	if err = a.store.UpdateAutomationRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (a *App) DeleteAutomationRule(boardID, ruleID, userID string) error {
+	if _, err := a.GetAutomationRule(boardID, ruleID); err != nil {
+		return err
+	}
+	return a.store.DeleteAutomationRule(ruleID, userID)
+}
+
+func (a *App) GetAutomationExecutions(opts model.QueryAutomationExecutionsOptions) ([]*model.AutomationExecution, error) {
+	return a.store.GetAutomationExecutions(opts)
+}
+
+// enqueueAutomations queues the evaluation of the automation rules for a changed block.
+// Block changes are notified both from the blockChangeNotifier queue and from the
+// request that made them, so rules get a queue of their own to always run asynchronously.
+func (a *App) enqueueAutomations(action notify.Action, block *model.Block, oldBlock *model.Block) {
+	if !a.config.EnableAutomations || block == nil {
+		return
+	}
+	a.automationQueue.Enqueue(func() error {
+		a.runAutomations(action, block, oldBlock)
+		return nil
+	})
+}
+
+// runAutomations evaluates the automation rules of the board a changed block belongs to.
+func (a *App) runAutomations(action notify.Action, block *model.Block, oldBlock *model.Block) {
+	if !a.config.EnableAutomations || block == nil {
+		return
+	}
+
+	var card *model.Block
+	var triggers []model.AutomationTriggerType
+
+	switch {
+	case action == notify.Add && block.Type == model.TypeCard:
+		card = block
+		triggers = append(triggers, model.AutomationTriggerCardCreated)
+	case action == notify.Add && block.Type == model.TypeComment:
+		parent, err := a.store.GetBlock(block.ParentID)
+		if err != nil || parent.Type != model.TypeCard {
+			return
+		}
+		card = parent
+		triggers = append(triggers, model.AutomationTriggerCommentAdded)
+	case action == notify.Update && block.Type == model.TypeCard && oldBlock != nil:
+		card = block
+		triggers = append(triggers, model.AutomationTriggerPropertyChanged)
+		if oldBlock.BoardID != block.BoardID {
+			triggers = append(triggers, model.AutomationTriggerCardMoved)
+		}
+	default:
+		return
+	}
+
+	chain := a.automationGuard.enter(card.ID)
+
+	rules, err := a.store.GetAutomationRulesForBoard(card.BoardID)
+	if err != nil {
+		a.logger.Error("Cannot fetch automation rules for board", mlog.String("board_id", card.BoardID), mlog.Err(err))
+		return
+	}
+
+	for _, rule := range rules {
+		if rule.Enabled && automationRuleMatches(rule, triggers, card, oldBlock) {
+			a.executeAutomationRule(rule, card, chain)
+		}
+	}
+}
+
+func automationRuleMatches(rule *model.AutomationRule, triggers []model.AutomationTriggerType, card *model.Block, oldCard *model.Block) bool {
+	for _, trigger := range triggers {
+		if rule.Trigger.Type != trigger {
+			continue
+		}
+		if trigger != model.AutomationTriggerPropertyChanged {
+			return true
+		}
+
+		newValue := getCardPropertyValue(card, rule.Trigger.PropertyID)
+		if reflect.DeepEqual(newValue, getCardPropertyValue(oldCard, rule.Trigger.PropertyID)) {
+			return false
+		}
+		return rule.Trigger.Value == "" || propertyValueContains(newValue, rule.Trigger.Value)
+	}
+	return false
+}
+
+func getCardProperties(card *model.Block) map[string]interface{} {
+	if card == nil {
+		return map[string]interface{}{}
+	}
+	props, ok := card.Fields["properties"].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return props
+}
+
+func getCardPropertyValue(card *model.Block, propertyID string) interface{} {
+	return getCardProperties(card)[propertyID]
+}
+
+// propertyValueContains returns true if a property value is, or for multi value
+// properties contains, the specified value.
+func propertyValueContains(value interface{}, expected string) bool {
+	switch v := value.(type) {
+	case string:
+		return v == expected
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// executeAutomationRule applies the actions of a rule to a card and logs the execution.
+func (a *App) executeAutomationRule(rule *model.AutomationRule, card *model.Block, chain automationChain) {
+	execution := &model.AutomationExecution{
+		RuleID:      rule.ID,
+		BoardID:     rule.BoardID,
+		CardID:      card.ID,
+		TriggerType: rule.Trigger.Type,
+		Status:      model.AutomationExecutionSuccess,
+		Depth:       chain.depth,
+	}
+
+	switch {
+	case chain.depth >= maxAutomationChainDepth:
+		execution.Status = model.AutomationExecutionSkipped
+		execution.Message = fmt.Sprintf("automation chain reached the maximum depth of %d", maxAutomationChainDepth)
+	case chain.rules[rule.ID]:
+		execution.Status = model.AutomationExecutionSkipped
+		execution.Message = "rule already ran earlier in this automation chain"
+	default:
+		if err := a.applyAutomationActions(rule, card, chain.next(rule.ID)); err != nil {
+			execution.Status = model.AutomationExecutionFailed
+			execution.Message = err.Error()
+		}
+	}
+
+	a.logger.Debug("Automation rule executed",
+		mlog.String("rule_id", rule.ID),
+		mlog.String("board_id", rule.BoardID),
+		mlog.String("card_id", card.ID),
+		mlog.String("trigger", string(rule.Trigger.Type)),
+		mlog.String("status", string(execution.Status)),
+		mlog.String("message", execution.Message),
+		mlog.Int("depth", chain.depth),
+	)
+
+	if err := a.store.InsertAutomationExecution(execution); err != nil {
+		a.logger.Error("Cannot log automation execution", mlog.String("rule_id", rule.ID), mlog.Err(err))
+	}
+}
+
+func (a *App) applyAutomationActions(rule *model.AutomationRule, card *model.Block, chain automationChain) error {
+	for i, action := range rule.Actions {
+		var err error
+		switch action.Type {
+		case model.AutomationActionSetProperty:
+			err = a.automationSetProperty(rule, card.ID, action, chain)
+		case model.AutomationActionAssignPerson:
+			err = a.automationAssignPerson(rule, card.ID, action, chain)
+		case model.AutomationActionAddComment:
+			err = a.automationAddComment(rule, card, action, chain)
+		case model.AutomationActionNotify:
+			err = a.automationNotify(rule, card, action)
+		case model.AutomationActionMoveCard:
+			err = a.automationMoveCard(rule, card.ID, action, chain)
+		case model.AutomationActionWebhook:
+			err = a.webhook.PostJSON(action.URL, automationWebhookPayload{
+				RuleID:  rule.ID,
+				BoardID: rule.BoardID,
+				Trigger: rule.Trigger.Type,
+				Card:    card,
+			})
+		default:
+			err = model.NewErrNotImplemented("unsupported automation action " + string(action.Type))
+		}
+		if err != nil {
+			return fmt.Errorf("action %d (%s): %w", i, action.Type, err)
+		}
+	}
+	return nil
+}
+
+type automationWebhookPayload struct {
+	RuleID  string                      `json:"ruleId"`
+	BoardID string                      `json:"boardId"`
+	Trigger model.AutomationTriggerType `json:"trigger"`
+	Card    *model.Block                `json:"card"`
+}
+
+func (a *App) patchCardPropertyForAutomation(rule *model.AutomationRule, cardID string, chain automationChain, update func(props map[string]interface{})) error {
+	// the card is fetched again so that earlier actions of the rule are not overwritten.
+	card, err := a.store.GetBlock(cardID)
+	if err != nil {
+		return err
+	}
+
+	props := make(map[string]interface{})
+	for k, v := range getCardProperties(card) {
+		props[k] = v
+	}
+	update(props)
+
+	a.automationGuard.expect(card.ID, chain)
+	_, err = a.PatchBlock(card.ID, &model.BlockPatch{
+		UpdatedFields: map[string]interface{}{"properties": props},
+	}, rule.ModifiedBy)
+	return err
+}
+
+func (a *App) automationSetProperty(rule *model.AutomationRule, cardID string, action model.AutomationAction, chain automationChain) error {
+	return a.patchCardPropertyForAutomation(rule, cardID, chain, func(props map[string]interface{}) {
+		if action.Value == nil {
+			delete(props, action.PropertyID)
+			return
+		}
+		props[action.PropertyID] = action.Value
+	})
+}
+
+func (a *App) automationAssignPerson(rule *model.AutomationRule, cardID string, action model.AutomationAction, chain automationChain) error {
+	board, err := a.store.GetBoard(rule.BoardID)
+	if err != nil {
+		return err
+	}
+	schema, err := model.ParsePropertySchema(board)
+	if err != nil {
+		return err
+	}
+	propDef, ok := schema[action.PropertyID]
+	if !ok {
+		return model.NewErrNotFound("card property ID=" + action.PropertyID)
+	}
+
+	return a.patchCardPropertyForAutomation(rule, cardID, chain, func(props map[string]interface{}) {
+		if propDef.Type != "multiPerson" {
+			props[action.PropertyID] = action.UserIDs[0]
+			return
+		}
+
+		assigned := []interface{}{}
+		if current, ok := props[action.PropertyID].([]interface{}); ok {
+			assigned = append(assigned, current...)
+		}
+		for _, userID := range action.UserIDs {
+			if !propertyValueContains(assigned, userID) {
+				assigned = append(assigned, userID)
+			}
+		}
+		props[action.PropertyID] = assigned
+	})
+}
+
+func (a *App) automationAddComment(rule *model.AutomationRule, card *model.Block, action model.AutomationAction, chain automationChain) error {
+	now := utils.GetMillis()
+	comment := &model.Block{
+		ID:         utils.NewID(utils.IDTypeBlock),
+		ParentID:   card.ID,
+		BoardID:    card.BoardID,
+		CreatedBy:  rule.ModifiedBy,
+		ModifiedBy: rule.ModifiedBy,
+		Schema:     1,
+		Type:       model.TypeComment,
+		Title:      action.Text,
+		Fields:     map[string]interface{}{},
+		CreateAt:   now,
+		UpdateAt:   now,
+	}
+
+	a.automationGuard.expect(card.ID, chain)
+	return a.InsertBlock(comment, rule.ModifiedBy)
+}
+
+func (a *App) automationMoveCard(rule *model.AutomationRule, cardID string, action model.AutomationAction, chain automationChain) error {
+	if !a.permissions.HasPermissionToBoard(rule.ModifiedBy, action.BoardID, model.PermissionManageBoardCards) {
+		return model.NewErrPermission("rule modifier cannot manage cards in board " + action.BoardID)
+	}
+
+	a.automationGuard.expect(cardID, chain)
+	_, err := a.MoveCard(cardID, model.CardMoveOptions{DestinationBoardID: action.BoardID}, rule.ModifiedBy)
+	return err
+}
+
+func (a *App) automationNotify(rule *model.AutomationRule, card *model.Block, action model.AutomationAction) error {
+	if a.config == nil || !a.config.Telegram.Enabled || a.config.Telegram.BotWebhookURL == "" {
+		return errAutomationNotificationsDisabled
+	}
+
+	board, err := a.store.GetBoard(rule.BoardID)
+	if err != nil {
+		return err
+	}
+
+	telegram := notify.NewTelegramService(a.config.Telegram.BotWebhookURL)
+	message := fmt.Sprintf("⚙️ %s\n\n📝 *%s*\n📋 Board: %s", action.Text, card.Title, board.Title)
+
+	for _, userID := range action.UserIDs {
+		// the rule may name users that are not, or no longer, members of the board
+		if !a.permissions.HasPermissionToBoard(userID, rule.BoardID, model.PermissionViewBoard) {
+			continue
+		}
+		user, err := a.store.GetUserByID(userID)
+		if err != nil {
+			return err
+		}
+		if user.TelegramChatID == "" || user.TelegramNotificationsEnabled == 0 {
+			continue
+		}
+		if err := telegram.SendMessage(user.TelegramChatID, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunDueDateAutomations fires the dueDatePassed rules of all boards for cards
+// whose due date passed since the previous run.
+func (a *App) RunDueDateAutomations() {
+	// the run is claimed so a single server of the cluster fires the rules
+	since, now, claimed := a.claimScheduledRunSince(automationDueDateCheckedAtKey, 0)
+	if !claimed || since == 0 {
+		// the first run only records the point in time to start from.
+		return
+	}
+
+	rules, err := a.store.GetEnabledAutomationRulesByTrigger(model.AutomationTriggerDueDatePassed)
+	if err != nil {
+		a.logger.Error("Cannot fetch due date automation rules", mlog.Err(err))
+		return
+	}
+
+	for _, rule := range rules {
+		cards, err := a.store.GetBlocksWithType(rule.BoardID, model.TypeCard)
+		if err != nil {
+			a.logger.Error("Cannot fetch cards for due date automation", mlog.String("board_id", rule.BoardID), mlog.Err(err))
+			continue
+		}
+		for _, card := range cards {
+			due, ok := getCardDueDate(card, rule.Trigger.PropertyID)
+			if !ok || due <= since || due > now {
+				continue
+			}
+			a.executeAutomationRule(rule, card, automationChain{})
+		}
+	}
+}
+
+// getCardDueDate returns the end of the date range stored in a date property,
+// or its start if the property is a single date.
+func getCardDueDate(card *model.Block, propertyID string) (int64, bool) {
+	value, ok := getCardPropertyValue(card, propertyID).(string)
+	if !ok || value == "" {
+		return 0, false
+	}
+
+	var date map[string]int64
+	if err := json.Unmarshal([]byte(value), &date); err != nil {
+		return 0, false
+	}
+	if to, ok := date["to"]; ok {
+		return to, true
+	}
+	from, ok := date["from"]
+	return from, ok
+}
@@ -0,0 +1,294 @@
+package app
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAutomationRule(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	boardID := utils.NewID(utils.IDTypeBoard)
+	userID := utils.NewID(utils.IDTypeUser)
+
+	t.Run("success scenario", func(t *testing.T) {
+		rule := &model.AutomationRule{
+			ID:      "should-be-replaced",
+			BoardID: boardID,
+			Enabled: true,
+			Trigger: model.AutomationTrigger{Type: model.AutomationTriggerCardCreated},
+			Actions: []model.AutomationAction{{Type: model.AutomationActionAddComment, Text: "welcome"}},
+		}
+
+		th.Store.EXPECT().CreateAutomationRule(gomock.Any()).DoAndReturn(
+			func(r *model.AutomationRule) (*model.AutomationRule, error) { return r, nil },
+		)
+
+		newRule, err := th.App.CreateAutomationRule(rule, userID)
+		require.NoError(t, err)
+		require.NotEqual(t, "should-be-replaced", newRule.ID)
+		require.Equal(t, userID, newRule.CreatedBy)
+		require.Equal(t, userID, newRule.ModifiedBy)
+		require.NotZero(t, newRule.CreateAt)
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		rule := &model.AutomationRule{
+			BoardID: boardID,
+			Trigger: model.AutomationTrigger{Type: model.AutomationTriggerPropertyChanged},
+			Actions: []model.AutomationAction{{Type: model.AutomationActionAddComment, Text: "changed"}},
+		}
+
+		newRule, err := th.App.CreateAutomationRule(rule, userID)
+		require.True(t, model.IsErrBadRequest(err))
+		require.Nil(t, newRule)
+	})
+}
+
+func TestPatchAutomationRule(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	boardID := utils.NewID(utils.IDTypeBoard)
+	userID := utils.NewID(utils.IDTypeUser)
+
+	makeRule := func() *model.AutomationRule {
+		return &model.AutomationRule{
+			ID:      "rule-1",
+			BoardID: boardID,
+			Trigger: model.AutomationTrigger{Type: model.AutomationTriggerCardCreated},
+			Actions: []model.AutomationAction{{Type: model.AutomationActionAddComment, Text: "welcome"}},
+		}
+	}
+
+	t.Run("success scenario", func(t *testing.T) {
+		enabled := true
+		th.Store.EXPECT().GetAutomationRule("rule-1").Return(makeRule(), nil)
+		th.Store.EXPECT().UpdateAutomationRule(gomock.Any()).Return(nil)
+
+		rule, err := th.App.PatchAutomationRule(boardID, "rule-1", &model.AutomationRulePatch{Enabled: &enabled}, userID)
+		require.NoError(t, err)
+		require.True(t, rule.Enabled)
+		require.Equal(t, userID, rule.ModifiedBy)
+	})
+
+	t.Run("rule of another board", func(t *testing.T) {
+		th.Store.EXPECT().GetAutomationRule("rule-1").Return(makeRule(), nil)
+
+		rule, err := th.App.PatchAutomationRule("other-board", "rule-1", &model.AutomationRulePatch{}, userID)
+		require.True(t, model.IsErrNotFound(err))
+		require.Nil(t, rule)
+	})
+
+	t.Run("invalid patch", func(t *testing.T) {
+		actions := []model.AutomationAction{}
+		th.Store.EXPECT().GetAutomationRule("rule-1").Return(makeRule(), nil)
+
+		rule, err := th.App.PatchAutomationRule(boardID, "rule-1", &model.AutomationRulePatch{Actions: &actions}, userID)
+		require.True(t, model.IsErrBadRequest(err))
+		require.Nil(t, rule)
+	})
+}
+
+func TestAutomationRuleMatches(t *testing.T) {
+	makeCard := func(status interface{}) *model.Block {
+		props := map[string]interface{}{}
+		if status != nil {
+			props["status"] = status
+		}
+		return &model.Block{
+			ID:     "card-1",
+			Type:   model.TypeCard,
+			Fields: map[string]interface{}{"properties": props},
+		}
+	}
+
+	rule := &model.AutomationRule{
+		Trigger: model.AutomationTrigger{
+			Type:       model.AutomationTriggerPropertyChanged,
+			PropertyID: "status",
+			Value:      "done",
+		},
+	}
+	triggers := []model.AutomationTriggerType{model.AutomationTriggerPropertyChanged}
+
+	testCases := []struct {
+		name     string
+		oldValue interface{}
+		newValue interface{}
+		expected bool
+	}{
+		{"changed to value", "todo", "done", true},
+		{"set to value", nil, "done", true},
+		{"changed to other value", "todo", "doing", false},
+		{"unchanged", "done", "done", false},
+		{"multi value containing value", []interface{}{"a"}, []interface{}{"a", "done"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches := automationRuleMatches(rule, triggers, makeCard(tc.newValue), makeCard(tc.oldValue))
+			assert.Equal(t, tc.expected, matches)
+		})
+	}
+
+	t.Run("other trigger", func(t *testing.T) {
+		matches := automationRuleMatches(rule, []model.AutomationTriggerType{model.AutomationTriggerCardCreated}, makeCard("done"), nil)
+		assert.False(t, matches)
+	})
+}
+
+func TestAutomationGuard(t *testing.T) {
+	guard := newAutomationGuard()
+
+	chain := guard.enter("card-1")
+	require.Equal(t, 0, chain.depth)
+
+	guard.expect("card-1", chain.next("rule-1"))
+	guard.expect("card-1", chain.next("rule-1"))
+
+	for i := 0; i < 2; i++ {
+		chain = guard.enter("card-1")
+		require.Equal(t, 1, chain.depth)
+		require.True(t, chain.rules["rule-1"])
+	}
+
+	// once all expected changes are seen, the next change starts a new chain.
+	chain = guard.enter("card-1")
+	require.Equal(t, 0, chain.depth)
+	require.Empty(t, chain.rules)
+}
+
+func TestExecuteAutomationRuleSkipped(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	rule := &model.AutomationRule{
+		ID:      "rule-1",
+		BoardID: "board-1",
+		Trigger: model.AutomationTrigger{Type: model.AutomationTriggerCardCreated},
+		Actions: []model.AutomationAction{{Type: model.AutomationActionAddComment, Text: "welcome"}},
+	}
+	card := &model.Block{ID: "card-1", BoardID: "board-1", Type: model.TypeCard}
+
+	t.Run("rule already in chain", func(t *testing.T) {
+		chain := automationChain{}.next("rule-1")
+
+		th.Store.EXPECT().InsertAutomationExecution(gomock.Any()).DoAndReturn(
+			func(execution *model.AutomationExecution) error {
+				require.Equal(t, model.AutomationExecutionSkipped, execution.Status)
+				require.Equal(t, 1, execution.Depth)
+				return nil
+			},
+		)
+
+		th.App.executeAutomationRule(rule, card, chain)
+	})
+
+	t.Run("maximum depth reached", func(t *testing.T) {
+		chain := automationChain{}
+		for i := 0; i < maxAutomationChainDepth; i++ {
+			chain = chain.next(utils.NewID(utils.IDTypeNone))
+		}
+
+		th.Store.EXPECT().InsertAutomationExecution(gomock.Any()).DoAndReturn(
+			func(execution *model.AutomationExecution) error {
+				require.Equal(t, model.AutomationExecutionSkipped, execution.Status)
+				require.Equal(t, maxAutomationChainDepth, execution.Depth)
+				return nil
+			},
+		)
+
+		th.App.executeAutomationRule(rule, card, chain)
+	})
+}
+
+func TestEnqueueAutomations(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	comment := &model.Block{ID: "comment-1", ParentID: "card-1", BoardID: "board-1", Type: model.TypeComment}
+
+	t.Run("automations disabled", func(t *testing.T) {
+		th.App.config.EnableAutomations = false
+		th.App.enqueueAutomations(notify.Add, comment, nil)
+	})
+
+	t.Run("rules are evaluated on the automation queue", func(t *testing.T) {
+		th.App.config.EnableAutomations = true
+		defer func() { th.App.config.EnableAutomations = false }()
+
+		release := make(chan struct{})
+		evaluated := make(chan struct{})
+		th.Store.EXPECT().GetBlock("card-1").DoAndReturn(func(blockID string) (*model.Block, error) {
+			<-release
+			defer close(evaluated)
+			return &model.Block{ID: "card-1", Type: model.TypeText}, nil
+		})
+
+		// the caller does not wait for the rules to be evaluated.
+		th.App.enqueueAutomations(notify.Add, comment, nil)
+		close(release)
+
+		select {
+		case <-evaluated:
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "automation rules were not evaluated")
+		}
+	})
+}
+
+func TestGetCardDueDate(t *testing.T) {
+	makeCard := func(value string) *model.Block {
+		return &model.Block{
+			Fields: map[string]interface{}{
+				"properties": map[string]interface{}{"due": value},
+			},
+		}
+	}
+
+	due, ok := getCardDueDate(makeCard(`{"from":1000}`), "due")
+	require.True(t, ok)
+	require.Equal(t, int64(1000), due)
+
+	due, ok = getCardDueDate(makeCard(`{"from":1000,"to":2000}`), "due")
+	require.True(t, ok)
+	require.Equal(t, int64(2000), due)
+
+	_, ok = getCardDueDate(makeCard(""), "due")
+	require.False(t, ok)
+
+	_, ok = getCardDueDate(makeCard("not a date"), "due")
+	require.False(t, ok)
+}
+
+func TestRunDueDateAutomations(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("a run claimed by another server fires no rule", func(t *testing.T) {
+		lastRun := strconv.FormatInt(utils.GetMillis()-1000, 10)
+		th.Store.EXPECT().GetSystemSetting(automationDueDateCheckedAtKey).Return(lastRun, nil)
+		th.Store.EXPECT().CompareAndSetSystemSetting(automationDueDateCheckedAtKey, lastRun, gomock.Any()).Return(false, nil)
+		th.Store.EXPECT().GetEnabledAutomationRulesByTrigger(gomock.Any()).Times(0)
+
+		th.App.RunDueDateAutomations()
+	})
+
+	t.Run("a first run only records the point in time", func(t *testing.T) {
+		th.Store.EXPECT().GetSystemSetting(automationDueDateCheckedAtKey).Return("", nil)
+		th.Store.EXPECT().CompareAndSetSystemSetting(automationDueDateCheckedAtKey, "", gomock.Any()).Return(true, nil)
+		th.Store.EXPECT().GetEnabledAutomationRulesByTrigger(gomock.Any()).Times(0)
+
+		th.App.RunDueDateAutomations()
+	})
+}
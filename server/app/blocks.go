@@ -72,6 +72,15 @@ func (a *App) PatchBlockAndNotify(blockID string, blockPatch *model.BlockPatch,
 		return nil, err
 	}
 
+	blockPatch, err = prepareCommentPatch(oldBlock, blockPatch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.checkWIPLimits(board, []*model.Block{oldBlock}, []*model.BlockPatch{blockPatch}, modifiedByID); err != nil {
+		return nil, err
+	}
+
 	err = a.store.PatchBlock(blockID, blockPatch, modifiedByID)
 	if err != nil {
 		return nil, err
@@ -108,6 +117,21 @@ func (a *App) PatchBlocksAndNotify(teamID string, blockPatches *model.BlockPatch
 		return err
 	}
 
+	for i, oldBlock := range oldBlocks {
+		if i >= len(blockPatches.BlockPatches) {
+			break
+		}
+		blockPatch, err := prepareCommentPatch(oldBlock, &blockPatches.BlockPatches[i])
+		if err != nil {
+			return err
+		}
+		blockPatches.BlockPatches[i] = *blockPatch
+	}
+
+	if err := a.checkWIPLimitsForPatchBatch(oldBlocks, blockPatches, modifiedByID); err != nil {
+		return err
+	}
+
 	if err := a.store.PatchBlocks(blockPatches, modifiedByID); err != nil {
 		return err
 	}
@@ -140,6 +164,10 @@ func (a *App) InsertBlockAndNotify(block *model.Block, modifiedByID string, disa
 		return bErr
 	}
 
+	if err := a.validateCommentReplies([]*model.Block{block}); err != nil {
+		return err
+	}
+
 	err := a.store.InsertBlock(block, modifiedByID)
 	if err == nil {
 		a.blockChangeNotifier.Enqueue(func() error {
@@ -178,6 +206,10 @@ func (a *App) InsertBlocksAndNotify(blocks []*model.Block, modifiedByID string,
 		return nil, err
 	}
 
+	if err := a.validateCommentReplies(blocks); err != nil {
+		return nil, err
+	}
+
 	needsNotify := make([]*model.Block, 0, len(blocks))
 	for i := range blocks {
 		err := a.store.InsertBlock(blocks[i], modifiedByID)
@@ -307,6 +339,12 @@ func (a *App) GetBlocksForBoard(boardID string) ([]*model.Block, error) {
 }
 
 func (a *App) notifyBlockChanged(action notify.Action, block *model.Block, oldBlock *model.Block, modifiedByID string) {
+	// automation rules are evaluated for every change that is not made by the system user,
+	// including changes made by other rules.
+	if modifiedByID != model.SystemUserID {
+		a.enqueueAutomations(action, block, oldBlock)
+	}
+
 	// don't notify if notifications service disabled, or block change is generated via system user.
 	if a.notifications == nil || modifiedByID == model.SystemUserID {
 		return
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// GetBoardSnapshot returns a board and its blocks as they were at the given time.
+func (a *App) GetBoardSnapshot(boardID string, at int64) (*model.BoardSnapshot, error) {
+	return a.store.GetBoardSnapshot(boardID, at)
+}
+
+// RestoreBoard restores a board to its state at the given time. In place, the
+// reverted board and blocks are written as new revisions, so the restore can
+// itself be undone. The access settings of the board are not restored.
+func (a *App) RestoreBoard(boardID string, opts model.BoardRestoreOptions, userID string) (*model.BoardRestoreResult, error) {
+	if err := opts.IsValid(); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := a.store.GetBoardSnapshot(boardID, opts.At)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.AsNewBoard {
+		return a.restoreBoardAsNew(snapshot, opts, userID)
+	}
+	return a.restoreBoardInPlace(snapshot, opts.DryRun, userID)
+}
+
+func (a *App) restoreBoardInPlace(snapshot *model.BoardSnapshot, dryRun bool, userID string) (*model.BoardRestoreResult, error) {
+	board, err := a.store.GetBoard(snapshot.Board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentBlocks, err := a.store.GetBlocksForBoard(board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentByID := map[string]*model.Block{}
+	for _, block := range currentBlocks {
+		currentByID[block.ID] = block
+	}
+
+	// blocks missing from the board may have been moved to another board since,
+	// in which case they are left where they are
+	missingIDs := []string{}
+	for _, block := range snapshot.Blocks {
+		if _, ok := currentByID[block.ID]; !ok {
+			missingIDs = append(missingIDs, block.ID)
+		}
+	}
+	elsewhere := map[string]bool{}
+	if len(missingIDs) != 0 {
+		blocks, err := a.store.GetBlocksByIDs(missingIDs)
+		if err != nil && !model.IsErrNotFound(err) {
+			return nil, err
+		}
+		for _, block := range blocks {
+			elsewhere[block.ID] = true
+		}
+	}
+
+	changes, result := planBoardRestore(board, currentBlocks, snapshot, elsewhere)
+	if dryRun {
+		result.DryRun = true
+		result.Board = snapshot.Board
+		return result, nil
+	}
+
+	restoredBoard, err := a.store.RestoreBoard(changes, userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot restore board %s: %w", board.ID, err)
+	}
+	result.Board = restoredBoard
+
+	a.blockChangeNotifier.Enqueue(func() error {
+		a.wsAdapter.BroadcastBoardChange(restoredBoard.TeamID, restoredBoard)
+
+		now := utils.GetMillis()
+		blocks := append([]*model.Block{}, changes.Blocks...)
+		for _, blockID := range changes.DeletedBlockIDs {
+			blocks = append(blocks, &model.Block{ID: blockID, BoardID: restoredBoard.ID, UpdateAt: now, DeleteAt: now})
+		}
+		if len(blocks) != 0 {
+			a.wsAdapter.BroadcastBlocksChange(restoredBoard.TeamID, restoredBoard.ID, blocks)
+		}
+		return nil
+	})
+
+	return result, nil
+}
+
+// planBoardRestore compares the current board and blocks with a snapshot and
+// returns the changes that revert the board to it.
+func planBoardRestore(board *model.Board, currentBlocks []*model.Block, snapshot *model.BoardSnapshot, elsewhere map[string]bool) (*model.BoardRestoreChanges, *model.BoardRestoreResult) {
+	restoredBoard := *board
+	restoredBoard.Title = snapshot.Board.Title
+	restoredBoard.Description = snapshot.Board.Description
+	restoredBoard.Icon = snapshot.Board.Icon
+	restoredBoard.ShowDescription = snapshot.Board.ShowDescription
+	restoredBoard.Properties = snapshot.Board.Properties
+	restoredBoard.CardProperties = snapshot.Board.CardProperties
+
+	changes := &model.BoardRestoreChanges{
+		Board:           &restoredBoard,
+		Blocks:          []*model.Block{},
+		DeletedBlockIDs: []string{},
+	}
+	result := &model.BoardRestoreResult{
+		CreatedBlockIDs: []string{},
+		UpdatedBlockIDs: []string{},
+		DeletedBlockIDs: []string{},
+		SkippedBlockIDs: []string{},
+	}
+
+	inSnapshot := map[string]bool{}
+	for _, block := range snapshot.Blocks {
+		inSnapshot[block.ID] = true
+	}
+
+	currentByID := map[string]*model.Block{}
+	deleted := map[string]bool{}
+	for _, block := range currentBlocks {
+		currentByID[block.ID] = block
+		if !inSnapshot[block.ID] {
+			deleted[block.ID] = true
+			changes.DeletedBlockIDs = append(changes.DeletedBlockIDs, block.ID)
+		}
+	}
+	result.DeletedBlockIDs = append(result.DeletedBlockIDs, changes.DeletedBlockIDs...)
+
+	for _, block := range snapshot.Blocks {
+		current, ok := currentByID[block.ID]
+		switch {
+		case !ok && elsewhere[block.ID]:
+			result.SkippedBlockIDs = append(result.SkippedBlockIDs, block.ID)
+		case !ok:
+			changes.Blocks = append(changes.Blocks, block)
+			result.CreatedBlockIDs = append(result.CreatedBlockIDs, block.ID)
+		// deleting a block deletes its children too, so unchanged children of a
+		// deleted block are written back
+		case current.UpdateAt != block.UpdateAt || deleted[current.ParentID]:
+			changes.Blocks = append(changes.Blocks, block)
+			result.UpdatedBlockIDs = append(result.UpdatedBlockIDs, block.ID)
+		}
+	}
+
+	return changes, result
+}
+
+func (a *App) restoreBoardAsNew(snapshot *model.BoardSnapshot, opts model.BoardRestoreOptions, userID string) (*model.BoardRestoreResult, error) {
+	sourceBoardID := snapshot.Board.ID
+	board := snapshot.Board
+	board.ChannelID = ""
+
+	// the new board is created in the team, which needs the same permission
+	// as creating a board of its type there
+	if board.Type == model.BoardTypeOpen {
+		if !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionCreatePublicChannel) {
+			return nil, model.NewErrPermission("access denied to create public boards")
+		}
+	} else {
+		if !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionCreatePrivateChannel) {
+			return nil, model.NewErrPermission("access denied to create private boards")
+		}
+	}
+	if opts.Title != "" {
+		board.Title = opts.Title
+	}
+
+	result := &model.BoardRestoreResult{
+		DryRun:          opts.DryRun,
+		Board:           board,
+		CreatedBlockIDs: []string{},
+		UpdatedBlockIDs: []string{},
+		DeletedBlockIDs: []string{},
+		SkippedBlockIDs: []string{},
+	}
+
+	if opts.DryRun {
+		for _, block := range snapshot.Blocks {
+			result.CreatedBlockIDs = append(result.CreatedBlockIDs, block.ID)
+		}
+		return result, nil
+	}
+
+	if len(snapshot.Blocks) == 0 {
+		board.ID = utils.NewID(utils.IDTypeBoard)
+		newBoard, err := a.CreateBoard(board, userID, true)
+		if err != nil {
+			return nil, fmt.Errorf("cannot restore board %s as a new board: %w", sourceBoardID, err)
+		}
+		result.Board = newBoard
+		return result, nil
+	}
+
+	bab, err := model.GenerateBoardsAndBlocksIDs(&model.BoardsAndBlocks{
+		Boards: []*model.Board{board},
+		Blocks: snapshot.Blocks,
+	}, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	newBab, err := a.CreateBoardsAndBlocks(bab, userID, true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot restore board %s as a new board: %w", sourceBoardID, err)
+	}
+
+	result.Board = newBab.Boards[0]
+	for _, block := range newBab.Blocks {
+		result.CreatedBlockIDs = append(result.CreatedBlockIDs, block.ID)
+	}
+	return result, nil
+}
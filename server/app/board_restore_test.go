@@ -0,0 +1,114 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanBoardRestore(t *testing.T) {
+	board := &model.Board{ID: "board-id", TeamID: "team-id", Title: "New title", Type: model.BoardTypePrivate}
+	snapshot := &model.BoardSnapshot{
+		At:    1000,
+		Board: &model.Board{ID: "board-id", TeamID: "team-id", Title: "Old title", Type: model.BoardTypeOpen},
+		Blocks: []*model.Block{
+			{ID: "unchanged", BoardID: "board-id", UpdateAt: 100},
+			{ID: "changed", BoardID: "board-id", UpdateAt: 100},
+			{ID: "removed", BoardID: "board-id", UpdateAt: 100},
+			{ID: "moved", BoardID: "board-id", UpdateAt: 100},
+			{ID: "child", BoardID: "board-id", ParentID: "new-parent", UpdateAt: 100},
+		},
+	}
+	current := []*model.Block{
+		{ID: "unchanged", BoardID: "board-id", UpdateAt: 100},
+		{ID: "changed", BoardID: "board-id", UpdateAt: 2000},
+		{ID: "added", BoardID: "board-id", UpdateAt: 2000},
+		{ID: "new-parent", BoardID: "board-id", UpdateAt: 2000},
+		{ID: "child", BoardID: "board-id", ParentID: "new-parent", UpdateAt: 100},
+	}
+
+	changes, result := planBoardRestore(board, current, snapshot, map[string]bool{"moved": true})
+
+	require.Equal(t, "Old title", changes.Board.Title)
+	require.Equal(t, model.BoardTypePrivate, changes.Board.Type, "access settings are not restored")
+	require.Equal(t, "New title", board.Title, "the current board is not modified")
+
+	require.ElementsMatch(t, []string{"added", "new-parent"}, changes.DeletedBlockIDs)
+	require.ElementsMatch(t, []string{"added", "new-parent"}, result.DeletedBlockIDs)
+	require.Equal(t, []string{"removed"}, result.CreatedBlockIDs)
+	require.Equal(t, []string{"changed", "child"}, result.UpdatedBlockIDs)
+	require.Equal(t, []string{"moved"}, result.SkippedBlockIDs)
+
+	restored := []string{}
+	for _, block := range changes.Blocks {
+		restored = append(restored, block.ID)
+	}
+	require.Equal(t, []string{"changed", "removed", "child"}, restored)
+}
+
+func TestRestoreBoard(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	board := &model.Board{ID: "board-id", TeamID: "team-id", Title: "New title"}
+	snapshot := &model.BoardSnapshot{
+		At:     1000,
+		Board:  &model.Board{ID: "board-id", TeamID: "team-id", Title: "Old title"},
+		Blocks: []*model.Block{{ID: "card-1", BoardID: "board-id", Type: model.TypeCard, UpdateAt: 100}},
+	}
+	current := []*model.Block{
+		{ID: "card-1", BoardID: "board-id", Type: model.TypeCard, UpdateAt: 2000},
+		{ID: "card-2", BoardID: "board-id", Type: model.TypeCard, UpdateAt: 2000},
+	}
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := th.App.RestoreBoard("board-id", model.BoardRestoreOptions{}, "user-id")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("dry run reports the changes without applying them", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardSnapshot("board-id", int64(1000)).Return(snapshot, nil)
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlocksForBoard("board-id").Return(current, nil)
+
+		result, err := th.App.RestoreBoard("board-id", model.BoardRestoreOptions{At: 1000, DryRun: true}, "user-id")
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Equal(t, "Old title", result.Board.Title)
+		require.Equal(t, []string{"card-1"}, result.UpdatedBlockIDs)
+		require.Equal(t, []string{"card-2"}, result.DeletedBlockIDs)
+	})
+
+	t.Run("restores in place", func(t *testing.T) {
+		restoredBoard := *board
+		restoredBoard.Title = "Old title"
+
+		th.Store.EXPECT().GetBoardSnapshot("board-id", int64(1000)).Return(snapshot, nil)
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlocksForBoard("board-id").Return(current, nil)
+		th.Store.EXPECT().RestoreBoard(gomock.Any(), "user-id").DoAndReturn(
+			func(changes *model.BoardRestoreChanges, userID string) (*model.Board, error) {
+				require.Equal(t, "Old title", changes.Board.Title)
+				require.Equal(t, snapshot.Blocks, changes.Blocks)
+				require.Equal(t, []string{"card-2"}, changes.DeletedBlockIDs)
+				return &restoredBoard, nil
+			})
+		th.Store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{}, nil).AnyTimes()
+
+		result, err := th.App.RestoreBoard("board-id", model.BoardRestoreOptions{At: 1000}, "user-id")
+		require.NoError(t, err)
+		require.False(t, result.DryRun)
+		require.Equal(t, &restoredBoard, result.Board)
+	})
+
+	t.Run("restoring as a new board requires the permission to create boards", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardSnapshot("board-id", int64(1000)).Return(snapshot, nil)
+		th.API.EXPECT().HasPermissionToTeam("user-id", "team-id", model.PermissionCreatePrivateChannel).Return(false)
+
+		_, err := th.App.RestoreBoard("board-id", model.BoardRestoreOptions{At: 1000, AsNewBoard: true, DryRun: true}, "user-id")
+		var perr *model.ErrPermission
+		require.ErrorAs(t, err, &perr)
+	})
+}
@@ -63,12 +63,18 @@ func (a *App) GetBoardMetadata(boardID string) (*model.Board, *model.BoardMetada
 		return nil, nil, err
 	}
 
+	wipLimitBreaches, err := a.GetWIPLimitBreaches(board)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	boardMetadata := model.BoardMetadata{
 		BoardID:                 boardID,
 		DescendantFirstUpdateAt: earliestTime,
 		DescendantLastUpdateAt:  latestTime,
 		CreatedBy:               board.CreatedBy,
 		LastModifiedBy:          lastModifiedBy,
+		WIPLimitBreaches:        wipLimitBreaches,
 	}
 	return board, &boardMetadata, nil
 }
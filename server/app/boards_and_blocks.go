@@ -22,15 +22,31 @@ func (a *App) CreateBoardsAndBlocks(bab *model.BoardsAndBlocks, userID string, a
 		return nil, err
 	}
 
+	a.notifyBoardsAndBlocksCreated(newBab, members, userID)
+
+	for _, board := range newBab.Boards {
+		if !board.IsTemplate {
+			if err := a.addBoardsToDefaultCategory(userID, board.TeamID, []*model.Board{board}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return newBab, nil
+}
+
+// notifyBoardsAndBlocksCreated broadcasts the boards, blocks and members
+// created together, and notifies the changes of the blocks.
+func (a *App) notifyBoardsAndBlocksCreated(bab *model.BoardsAndBlocks, members []*model.BoardMember, userID string) {
 	// all new boards should belong to the same team
-	teamID := newBab.Boards[0].TeamID
+	teamID := bab.Boards[0].TeamID
 
 	// This can be synchronous because this action is not common
-	for _, board := range newBab.Boards {
+	for _, board := range bab.Boards {
 		a.wsAdapter.BroadcastBoardChange(teamID, board)
 	}
 
-	for _, block := range newBab.Blocks {
+	for _, block := range bab.Blocks {
 		b := block
 		a.wsAdapter.BroadcastBlockChange(teamID, b)
 		a.metrics.IncrementBlocksInserted(1)
@@ -38,21 +54,9 @@ func (a *App) CreateBoardsAndBlocks(bab *model.BoardsAndBlocks, userID string, a
 		a.notifyBlockChanged(notify.Add, b, nil, userID)
 	}
 
-	if addMember {
-		for _, member := range members {
-			a.wsAdapter.BroadcastMemberChange(teamID, member.BoardID, member)
-		}
-	}
-
-	for _, board := range newBab.Boards {
-		if !board.IsTemplate {
-			if err := a.addBoardsToDefaultCategory(userID, board.TeamID, []*model.Board{board}); err != nil {
-				return nil, err
-			}
-		}
+	for _, member := range members {
+		a.wsAdapter.BroadcastMemberChange(teamID, member.BoardID, member)
 	}
-
-	return newBab, nil
 }
 
 func (a *App) PatchBoardsAndBlocks(pbab *model.PatchBoardsAndBlocks, userID string) (*model.BoardsAndBlocks, error) {
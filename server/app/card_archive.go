@@ -0,0 +1,81 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// ArchiveCards archives the cards of a board selected by the filter. Archived cards are
+// hidden from the board by default but, unlike deleted cards, are kept indefinitely.
+func (a *App) ArchiveCards(boardID string, filter model.CardFilter, userID string) ([]*model.Card, error) {
+	return a.setCardsArchived(boardID, filter, true, userID)
+}
+
+// UnarchiveCards restores the archived cards of a board selected by the filter.
+func (a *App) UnarchiveCards(boardID string, filter model.CardFilter, userID string) ([]*model.Card, error) {
+	return a.setCardsArchived(boardID, filter, false, userID)
+}
+
+func (a *App) setCardsArchived(boardID string, filter model.CardFilter, archive bool, userID string) ([]*model.Card, error) {
+	if err := filter.IsValid(); err != nil {
+		return nil, err
+	}
+
+	board, err := a.store.GetBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := a.store.GetBlocksWithType(boardID, model.TypeCard)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.GetMillis()
+	patches := &model.BlockPatchBatch{}
+	for _, block := range blocks {
+		if model.IsArchivedCard(block) == archive || !filter.Matches(block) {
+			continue
+		}
+
+		patch := model.BlockPatch{}
+		if archive {
+			patch.UpdatedFields = map[string]interface{}{model.CardArchivedAtField: now}
+		} else {
+			patch.DeletedFields = []string{model.CardArchivedAtField}
+		}
+		patches.BlockIDs = append(patches.BlockIDs, block.ID)
+		patches.BlockPatches = append(patches.BlockPatches, patch)
+	}
+
+	if len(patches.BlockIDs) == 0 {
+		return []*model.Card{}, nil
+	}
+
+	if err = a.PatchBlocks(board.TeamID, patches, userID); err != nil {
+		return nil, err
+	}
+
+	a.logger.Debug("Cards archive state changed",
+		mlog.String("board_id", boardID),
+		mlog.Bool("archived", archive),
+		mlog.Int("card_count", len(patches.BlockIDs)),
+	)
+
+	updated, err := a.store.GetBlocksByIDs(patches.BlockIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]*model.Card, 0, len(updated))
+	for _, block := range updated {
+		card, err := model.Block2Card(block)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveCards(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	board := &model.Board{ID: "board-id", TeamID: "team-id"}
+	done := &model.Block{
+		ID:       "card-1",
+		BoardID:  "board-id",
+		Type:     model.TypeCard,
+		UpdateAt: 1000,
+		Fields:   map[string]interface{}{"properties": map[string]interface{}{"status": "done"}},
+	}
+	recentDone := &model.Block{
+		ID:       "card-2",
+		BoardID:  "board-id",
+		Type:     model.TypeCard,
+		UpdateAt: 5000,
+		Fields:   map[string]interface{}{"properties": map[string]interface{}{"status": "done"}},
+	}
+	todo := &model.Block{
+		ID:       "card-3",
+		BoardID:  "board-id",
+		Type:     model.TypeCard,
+		UpdateAt: 1000,
+		Fields:   map[string]interface{}{"properties": map[string]interface{}{"status": "todo"}},
+	}
+
+	t.Run("invalid filter", func(t *testing.T) {
+		_, err := th.App.ArchiveCards("board-id", model.CardFilter{}, "user-id")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("archives the matching cards", func(t *testing.T) {
+		archived := *done
+		archived.Fields = map[string]interface{}{model.CardArchivedAtField: float64(2000)}
+
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return([]*model.Block{done, recentDone, todo}, nil)
+		th.Store.EXPECT().GetBlocksByIDs([]string{"card-1"}).Return([]*model.Block{done}, nil)
+		th.Store.EXPECT().PatchBlocks(gomock.Any(), "user-id").DoAndReturn(
+			func(patches *model.BlockPatchBatch, userID string) error {
+				require.Equal(t, []string{"card-1"}, patches.BlockIDs)
+				require.Contains(t, patches.BlockPatches[0].UpdatedFields, model.CardArchivedAtField)
+				return nil
+			})
+		th.Store.EXPECT().GetBlocksByIDs([]string{"card-1"}).Return([]*model.Block{&archived}, nil)
+		th.Store.EXPECT().GetBlock("card-1").Return(&archived, nil).AnyTimes()
+		th.Store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{}, nil).AnyTimes()
+
+		filter := model.CardFilter{PropertyID: "status", Value: "done", UpdatedBefore: 2000}
+		cards, err := th.App.ArchiveCards("board-id", filter, "user-id")
+		require.NoError(t, err)
+		require.Len(t, cards, 1)
+		require.Equal(t, int64(2000), cards[0].ArchivedAt)
+	})
+
+	t.Run("cards already in the requested state are skipped", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return([]*model.Block{done, todo}, nil)
+
+		cards, err := th.App.UnarchiveCards("board-id", model.CardFilter{CardIDs: []string{"card-1", "card-3"}}, "user-id")
+		require.NoError(t, err)
+		require.Empty(t, cards)
+	})
+}
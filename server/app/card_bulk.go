@@ -0,0 +1,447 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// cardBulkPlan holds the state of a bulk card request while the changes of each card
+// are computed.
+type cardBulkPlan struct {
+	req        *model.CardBulkRequest
+	board      *model.Board
+	schema     model.PropSchema
+	boardCards []*model.Block
+	now        int64
+
+	// set if the request moves the cards to another board.
+	destBoard      *model.Board
+	destCards      []*model.Block
+	mapper         *cardPropertyMapper
+	hasFiles       bool
+	arrivals       []*model.Block
+	arrivalPatches []*model.BlockPatch
+
+	// the cards patched so far, for the WIP limit checks.
+	patchedCards []*model.Block
+	patches      []*model.BlockPatch
+
+	changes  *model.CardBulkChanges
+	result   *model.CardBulkResult
+	oldCards map[string]*model.Block
+}
+
+// ApplyCardBulkOperations applies the operations of a bulk request to the cards of a board
+// selected by its filter. The changes of all the cards are applied in a single transaction.
+// Cards that can't be changed, for instance because of a hard WIP limit, are reported as
+// failures and don't prevent the other cards from being changed.
+func (a *App) ApplyCardBulkOperations(boardID string, req *model.CardBulkRequest, userID string) (*model.CardBulkResult, error) {
+	if err := req.IsValid(); err != nil {
+		return nil, err
+	}
+
+	plan, err := a.newCardBulkPlan(boardID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, card := range plan.boardCards {
+		if !req.Filter.Matches(card) {
+			continue
+		}
+		if err := a.planCardBulkChange(plan, card, userID); err != nil {
+			if !model.IsErrWIPLimitExceeded(err) {
+				return nil, err
+			}
+			plan.result.Failures = append(plan.result.Failures, model.CardBulkFailure{CardID: card.ID, Error: err.Error()})
+			continue
+		}
+		plan.result.CardIDs = append(plan.result.CardIDs, card.ID)
+		plan.oldCards[card.ID] = card
+	}
+
+	if req.DryRun || len(plan.result.CardIDs) == 0 {
+		return plan.result, nil
+	}
+
+	// file paths may depend on the board, so the files of the moved cards are
+	// moved to the destination board, and moved back if the cards can't be.
+	revertFiles := func() {}
+	if plan.hasFiles {
+		if revertFiles, err = a.moveCardFiles(plan.board, plan.destBoard, plan.changes.MovedBlocks); err != nil {
+			return nil, fmt.Errorf("cannot move the files of the cards to board %s: %w", plan.destBoard.ID, err)
+		}
+	}
+
+	created, err := a.store.ApplyCardBulkChanges(plan.changes, userID)
+	if err != nil {
+		revertFiles()
+		return nil, fmt.Errorf("cannot apply bulk changes to board %s: %w", boardID, err)
+	}
+
+	if len(created) != 0 {
+		failures, err := a.copyDuplicatedCardFiles(boardID, userID, created)
+		if err != nil {
+			return nil, err
+		}
+		plan.result.Failures = append(plan.result.Failures, failures...)
+		for _, block := range created {
+			if block.Type == model.TypeCard {
+				plan.result.CreatedCardIDs = append(plan.result.CreatedCardIDs, block.ID)
+			}
+		}
+	}
+
+	a.logger.Debug("Card bulk operations applied",
+		mlog.String("board_id", boardID),
+		mlog.Int("card_count", len(plan.result.CardIDs)),
+		mlog.Int("failure_count", len(plan.result.Failures)),
+	)
+
+	a.notifyCardBulkChanges(plan, created, userID)
+
+	return plan.result, nil
+}
+
+func (a *App) newCardBulkPlan(boardID string, req *model.CardBulkRequest) (*cardBulkPlan, error) {
+	board, err := a.store.GetBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := model.ParsePropertySchema(board)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &cardBulkPlan{
+		req:     req,
+		board:   board,
+		schema:  schema,
+		now:     utils.GetMillis(),
+		changes: &model.CardBulkChanges{BoardID: boardID},
+		result: &model.CardBulkResult{
+			DryRun:         req.DryRun,
+			CardIDs:        []string{},
+			CreatedCardIDs: []string{},
+			Failures:       []model.CardBulkFailure{},
+		},
+		oldCards: map[string]*model.Block{},
+	}
+
+	for _, op := range req.Operations {
+		if err := a.validateCardBulkOperation(plan, op); err != nil {
+			return nil, err
+		}
+	}
+
+	if plan.boardCards, err = a.store.GetBlocksWithType(boardID, model.TypeCard); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// copyDuplicatedCardFiles copies the files of the cards created by a duplicate, so they
+// don't share them with the original cards. The cards are already created, so the ones
+// whose files can't all be copied are returned as failures and keep referencing the
+// files of the original cards.
+func (a *App) copyDuplicatedCardFiles(boardID, userID string, created []*model.Block) ([]model.CardBulkFailure, error) {
+	var cardIDs []string
+	cardBlocks := map[string][]*model.Block{}
+	for _, block := range created {
+		cardID := block.ParentID
+		if block.Type == model.TypeCard {
+			cardID = block.ID
+			cardIDs = append(cardIDs, cardID)
+		}
+		cardBlocks[cardID] = append(cardBlocks[cardID], block)
+	}
+
+	failures := []model.CardBulkFailure{}
+	for _, cardID := range cardIDs {
+		blocks := cardBlocks[cardID]
+		newFileNames, copyErr := a.CopyCardFiles(boardID, userID, blocks, false)
+		if err := a.updateCopiedFileIDs(blocks, newFileNames, userID); err != nil {
+			return nil, fmt.Errorf("could not patch file IDs of duplicated card %s: %w", cardID, err)
+		}
+
+		if copyErr == nil {
+			for _, block := range blocks {
+				if fileID := cardFileID(block); fileID != "" {
+					if _, copied := newFileNames[fileID]; !copied {
+						copyErr = fmt.Errorf("file %s could not be copied", fileID)
+						break
+					}
+				}
+			}
+		}
+		if copyErr != nil {
+			a.logger.Error("Could not copy the files of a duplicated card", mlog.String("card_id", cardID), mlog.Err(copyErr))
+			failures = append(failures, model.CardBulkFailure{
+				CardID: cardID,
+				Error:  fmt.Sprintf("the card was duplicated but its files could not be copied: %s", copyErr),
+			})
+		}
+	}
+	return failures, nil
+}
+
+// validateCardBulkOperation checks an operation against the board it applies to.
+func (a *App) validateCardBulkOperation(plan *cardBulkPlan, op model.CardBulkOperation) error {
+	switch op.Type {
+	case model.CardBulkSetProperty, model.CardBulkClearProperty:
+		propDef, ok := plan.schema[op.PropertyID]
+		if !ok {
+			return model.NewErrBadRequest(fmt.Sprintf("property %s does not exist in board %s", op.PropertyID, plan.board.ID))
+		}
+		if op.Type == model.CardBulkSetProperty && !isValidPropertyValue(propDef, op.Value) {
+			return model.NewErrBadRequest(fmt.Sprintf("invalid value for property %s", op.PropertyID))
+		}
+
+	case model.CardBulkAddPerson, model.CardBulkRemovePerson:
+		propDef, ok := plan.schema[op.PropertyID]
+		if !ok || (propDef.Type != "person" && propDef.Type != "multiPerson") {
+			return model.NewErrBadRequest(fmt.Sprintf("property %s is not a person property", op.PropertyID))
+		}
+		if op.Type == model.CardBulkAddPerson && plan.board.Type != model.BoardTypeOpen {
+			if _, err := a.store.GetMemberForBoard(plan.board.ID, op.UserID); err != nil {
+				if model.IsErrNotFound(err) {
+					return model.NewErrBadRequest(fmt.Sprintf("user %s is not a member of board %s", op.UserID, plan.board.ID))
+				}
+				return err
+			}
+		}
+
+	case model.CardBulkMove:
+		if op.BoardID == plan.board.ID {
+			return model.NewErrBadRequest(fmt.Sprintf("cards already belong to board %s", op.BoardID))
+		}
+		destBoard, err := a.store.GetBoard(op.BoardID)
+		if err != nil {
+			return err
+		}
+		if plan.mapper, err = a.newCardPropertyMapper(plan.board, destBoard, model.CardMoveOptions{DestinationBoardID: destBoard.ID}); err != nil {
+			return err
+		}
+		if plan.destCards, err = a.store.GetBlocksWithType(destBoard.ID, model.TypeCard); err != nil {
+			return err
+		}
+		plan.destBoard = destBoard
+	}
+	return nil
+}
+
+// isValidPropertyValue returns true if the value can be set to a property, which
+// for select properties means it must be one of their options.
+func isValidPropertyValue(propDef model.PropDef, value interface{}) bool {
+	switch propDef.Type {
+	case "select":
+		optionID, ok := value.(string)
+		_, exists := propDef.Options[optionID]
+		return ok && exists
+	case "multiSelect":
+		optionIDs, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range optionIDs {
+			optionID, _ := v.(string)
+			if _, exists := propDef.Options[optionID]; !exists {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// planCardBulkChange adds the changes for a card to the plan.
+func (a *App) planCardBulkChange(plan *cardBulkPlan, card *model.Block, userID string) error {
+	switch plan.req.Operations[0].Type {
+	case model.CardBulkDelete:
+		plan.changes.DeletedCardIDs = append(plan.changes.DeletedCardIDs, card.ID)
+		return nil
+	case model.CardBulkDuplicate:
+		plan.changes.DuplicatedCardIDs = append(plan.changes.DuplicatedCardIDs, card.ID)
+		return nil
+	}
+
+	props := make(map[string]interface{})
+	for k, v := range getCardProperties(card) {
+		props[k] = v
+	}
+	archive := false
+	for _, op := range plan.req.Operations {
+		if op.Type == model.CardBulkArchive {
+			archive = !model.IsArchivedCard(card)
+			continue
+		}
+		applyCardBulkPropertyOperation(plan.schema[op.PropertyID], props, op)
+	}
+
+	if plan.destBoard != nil {
+		return a.planCardBulkMove(plan, card, props, archive, userID)
+	}
+
+	patch := &model.BlockPatch{UpdatedFields: map[string]interface{}{"properties": props}}
+	if archive {
+		patch.UpdatedFields[model.CardArchivedAtField] = plan.now
+	}
+
+	cards := append(plan.patchedCards, card)
+	patches := append(plan.patches, patch)
+	if err := a.checkWIPLimitsWithBoardCards(plan.board, plan.boardCards, cards, patches, userID); err != nil {
+		return err
+	}
+	plan.patchedCards, plan.patches = cards, patches
+
+	plan.changes.Patches.BlockIDs = append(plan.changes.Patches.BlockIDs, card.ID)
+	plan.changes.Patches.BlockPatches = append(plan.changes.Patches.BlockPatches, *patch)
+	return nil
+}
+
+func (a *App) planCardBulkMove(plan *cardBulkPlan, card *model.Block, props map[string]interface{}, archive bool, userID string) error {
+	mapped := plan.mapper.mapProperties(props)
+
+	// for the destination board the cards are new arrivals in the columns they map to.
+	arrival := &model.BlockPatch{UpdatedFields: map[string]interface{}{"properties": mapped}}
+	arrivals := append(plan.arrivals, &model.Block{Type: model.TypeCard})
+	arrivalPatches := append(plan.arrivalPatches, arrival)
+	if err := a.checkWIPLimitsWithBoardCards(plan.destBoard, plan.destCards, arrivals, arrivalPatches, userID); err != nil {
+		return err
+	}
+
+	blocks, err := a.store.GetSubTree2(plan.board.ID, card.ID, model.QuerySubtreeOptions{})
+	if err != nil {
+		return err
+	}
+	if relocateCardBlocks(blocks, card.ID, plan.destBoard.ID, mapped) {
+		plan.hasFiles = true
+	}
+	if archive {
+		for _, block := range blocks {
+			if block.ID == card.ID {
+				block.Fields[model.CardArchivedAtField] = plan.now
+			}
+		}
+	}
+
+	plan.arrivals, plan.arrivalPatches = arrivals, arrivalPatches
+	plan.changes.MovedBlocks = append(plan.changes.MovedBlocks, blocks...)
+	return nil
+}
+
+// applyCardBulkPropertyOperation applies a property operation to the properties of a card.
+func applyCardBulkPropertyOperation(propDef model.PropDef, props map[string]interface{}, op model.CardBulkOperation) {
+	switch op.Type {
+	case model.CardBulkSetProperty:
+		props[op.PropertyID] = op.Value
+
+	case model.CardBulkClearProperty:
+		delete(props, op.PropertyID)
+
+	case model.CardBulkAddPerson:
+		if propDef.Type != "multiPerson" {
+			props[op.PropertyID] = op.UserID
+			return
+		}
+		assigned := []interface{}{}
+		if current, ok := props[op.PropertyID].([]interface{}); ok {
+			assigned = append(assigned, current...)
+		}
+		if !propertyValueContains(assigned, op.UserID) {
+			assigned = append(assigned, op.UserID)
+		}
+		props[op.PropertyID] = assigned
+
+	case model.CardBulkRemovePerson:
+		if propDef.Type != "multiPerson" {
+			if props[op.PropertyID] == op.UserID {
+				delete(props, op.PropertyID)
+			}
+			return
+		}
+		current, _ := props[op.PropertyID].([]interface{})
+		remaining := []interface{}{}
+		for _, v := range current {
+			if v != op.UserID {
+				remaining = append(remaining, v)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(props, op.PropertyID)
+			return
+		}
+		props[op.PropertyID] = remaining
+	}
+}
+
+// notifyCardBulkChanges broadcasts the changes of a bulk request as a single websocket
+// message per board, and sends the notifications of each changed card.
+func (a *App) notifyCardBulkChanges(plan *cardBulkPlan, created []*model.Block, userID string) {
+	changes := plan.changes
+	board := plan.board
+	destBoard := plan.destBoard
+
+	a.blockChangeNotifier.Enqueue(func() error {
+		var updated []*model.Block
+		if len(changes.Patches.BlockIDs) != 0 {
+			var err error
+			if updated, err = a.store.GetBlocksByIDs(changes.Patches.BlockIDs); err != nil {
+				return err
+			}
+		}
+
+		now := utils.GetMillis()
+		boardBlocks := append([]*model.Block{}, updated...)
+		boardBlocks = append(boardBlocks, created...)
+		removedIDs := append([]string{}, changes.DeletedCardIDs...)
+		for _, block := range changes.MovedBlocks {
+			removedIDs = append(removedIDs, block.ID)
+		}
+		for _, blockID := range removedIDs {
+			boardBlocks = append(boardBlocks, &model.Block{ID: blockID, BoardID: board.ID, UpdateAt: now, DeleteAt: now})
+		}
+
+		if len(boardBlocks) != 0 {
+			a.wsAdapter.BroadcastBlocksChange(board.TeamID, board.ID, boardBlocks)
+		}
+		if len(changes.MovedBlocks) != 0 {
+			a.wsAdapter.BroadcastBlocksChange(destBoard.TeamID, destBoard.ID, changes.MovedBlocks)
+		}
+
+		for _, block := range boardBlocks {
+			if block.DeleteAt == 0 {
+				a.webhook.NotifyUpdate(block)
+			}
+		}
+		for _, block := range changes.MovedBlocks {
+			a.webhook.NotifyUpdate(block)
+		}
+
+		a.metrics.IncrementBlocksPatched(len(updated) + len(changes.MovedBlocks))
+		a.metrics.IncrementBlocksInserted(len(created))
+		a.metrics.IncrementBlocksDeleted(len(changes.DeletedCardIDs))
+
+		for _, block := range updated {
+			a.notifyBlockChanged(notify.Update, block, plan.oldCards[block.ID], userID)
+		}
+		for _, block := range changes.MovedBlocks {
+			if oldCard, ok := plan.oldCards[block.ID]; ok {
+				a.notifyBlockChanged(notify.Update, block, oldCard, userID)
+			}
+		}
+		for _, cardID := range changes.DeletedCardIDs {
+			a.notifyBlockChanged(notify.Delete, plan.oldCards[cardID], plan.oldCards[cardID], userID)
+		}
+		for _, block := range created {
+			if block.Type == model.TypeCard {
+				a.notifyBlockChanged(notify.Add, block, nil, userID)
+			}
+		}
+		return nil
+	})
+}
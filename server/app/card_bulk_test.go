@@ -0,0 +1,198 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/permissions/localpermissions"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore/mocks"
+)
+
+func TestApplyCardBulkOperations(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.permissions = localpermissions.New(th.Store, th.logger)
+
+	board := makeTestBoard("board-id", "")
+	setTestWIPLimit(board, model.WIPLimitHard)
+	boardCards := []*model.Block{
+		makeTestCard("card-1", "doing"),
+		makeTestCard("card-2", "todo"),
+		makeTestCard("card-3", "todo"),
+		makeTestCard("card-4", "todo"),
+	}
+	toDoing := &model.CardBulkRequest{
+		Filter:     model.CardFilter{PropertyID: "status", Value: "todo"},
+		Operations: []model.CardBulkOperation{{Type: model.CardBulkSetProperty, PropertyID: "status", Value: "doing"}},
+	}
+
+	t.Run("unknown property", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+
+		req := &model.CardBulkRequest{
+			Filter:     model.CardFilter{CardIDs: []string{"card-1"}},
+			Operations: []model.CardBulkOperation{{Type: model.CardBulkClearProperty, PropertyID: "unknown"}},
+		}
+		_, err := th.App.ApplyCardBulkOperations("board-id", req, "user-id")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("dry run reports the cards over the WIP limit as failures", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(boardCards, nil)
+		th.Store.EXPECT().GetMemberForBoard("board-id", "user-id").Return(&model.BoardMember{SchemeEditor: true}, nil).Times(2)
+		th.Store.EXPECT().ApplyCardBulkChanges(gomock.Any(), gomock.Any()).Times(0)
+
+		req := *toDoing
+		req.DryRun = true
+		result, err := th.App.ApplyCardBulkOperations("board-id", &req, "user-id")
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Equal(t, []string{"card-2"}, result.CardIDs)
+		require.Len(t, result.Failures, 2)
+		require.Equal(t, "card-3", result.Failures[0].CardID)
+		require.Equal(t, "card-4", result.Failures[1].CardID)
+	})
+
+	t.Run("applies the changes in a single store call", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(boardCards, nil)
+		th.Store.EXPECT().GetMemberForBoard("board-id", "user-id").Return(&model.BoardMember{SchemeEditor: true}, nil).Times(2)
+		th.Store.EXPECT().ApplyCardBulkChanges(gomock.Any(), "user-id").DoAndReturn(
+			func(changes *model.CardBulkChanges, userID string) ([]*model.Block, error) {
+				require.Equal(t, "board-id", changes.BoardID)
+				require.Equal(t, []string{"card-2"}, changes.Patches.BlockIDs)
+				props := changes.Patches.BlockPatches[0].UpdatedFields["properties"].(map[string]interface{})
+				require.Equal(t, "doing", props["status"])
+				return []*model.Block{}, nil
+			})
+		th.Store.EXPECT().GetBlocksByIDs([]string{"card-2"}).Return([]*model.Block{makeTestCard("card-2", "doing")}, nil).AnyTimes()
+		th.Store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{}, nil).AnyTimes()
+
+		result, err := th.App.ApplyCardBulkOperations("board-id", toDoing, "user-id")
+		require.NoError(t, err)
+		require.False(t, result.DryRun)
+		require.Equal(t, []string{"card-2"}, result.CardIDs)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(boardCards, nil)
+		th.Store.EXPECT().ApplyCardBulkChanges(gomock.Any(), "user-id").DoAndReturn(
+			func(changes *model.CardBulkChanges, userID string) ([]*model.Block, error) {
+				require.Equal(t, []string{"card-1"}, changes.DeletedCardIDs)
+				require.Empty(t, changes.Patches.BlockIDs)
+				return []*model.Block{}, nil
+			})
+		th.Store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{}, nil).AnyTimes()
+
+		req := &model.CardBulkRequest{
+			Filter:     model.CardFilter{PropertyID: "status", Value: "doing"},
+			Operations: []model.CardBulkOperation{{Type: model.CardBulkDelete}},
+		}
+		result, err := th.App.ApplyCardBulkOperations("board-id", req, "user-id")
+		require.NoError(t, err)
+		require.Equal(t, []string{"card-1"}, result.CardIDs)
+	})
+}
+
+func TestApplyCardBulkOperationsFiles(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	source := makeTestBoard("source-board", "a-")
+	dest := makeTestBoard("dest-board", "b-")
+	card := &model.Block{
+		ID:       "card-id",
+		BoardID:  "source-board",
+		ParentID: "source-board",
+		Type:     model.TypeCard,
+		Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"a-status": "a-todo"},
+		},
+	}
+	image := &model.Block{
+		ID:       "image-id",
+		BoardID:  "source-board",
+		ParentID: "card-id",
+		Type:     model.TypeImage,
+		Fields:   map[string]interface{}{"fileId": "7fileID.png"},
+	}
+	sourcePath := filepath.Join("team-id", "source-board", "7fileID.png")
+	destPath := filepath.Join("team-id", "dest-board", "7fileID.png")
+
+	t.Run("duplicated cards whose files cannot be copied are reported as failures", func(t *testing.T) {
+		newCard := &model.Block{ID: "new-card-id", BoardID: "source-board", ParentID: "source-board", Type: model.TypeCard}
+		newImage := &model.Block{
+			ID:       "new-image-id",
+			BoardID:  "source-board",
+			ParentID: "new-card-id",
+			Type:     model.TypeImage,
+			Fields:   map[string]interface{}{"fileId": "7fileID.png"},
+		}
+		th.Store.EXPECT().GetBoard("source-board").Return(source, nil).Times(2)
+		th.Store.EXPECT().GetBlocksWithType("source-board", model.TypeCard).Return([]*model.Block{card}, nil)
+		th.Store.EXPECT().ApplyCardBulkChanges(gomock.Any(), "user-id").Return([]*model.Block{newCard, newImage}, nil)
+		th.Store.EXPECT().GetFileInfo("fileID").Return(nil, model.NewErrNotFound("file info ID=fileID"))
+		th.Store.EXPECT().PatchBlocks(gomock.Any(), gomock.Any()).Times(0)
+		th.Store.EXPECT().GetMembersForBoard(gomock.Any()).Return([]*model.BoardMember{}, nil).AnyTimes()
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("CopyFile", sourcePath, mock.Anything).Return(errDummy)
+
+		req := &model.CardBulkRequest{
+			Filter:     model.CardFilter{CardIDs: []string{"card-id"}},
+			Operations: []model.CardBulkOperation{{Type: model.CardBulkDuplicate}},
+		}
+		result, err := th.App.ApplyCardBulkOperations("source-board", req, "user-id")
+		require.NoError(t, err)
+		require.Equal(t, []string{"new-card-id"}, result.CreatedCardIDs)
+		require.Len(t, result.Failures, 1)
+		require.Equal(t, "new-card-id", result.Failures[0].CardID)
+	})
+
+	t.Run("files are moved back if the cards cannot be moved", func(t *testing.T) {
+		cardCopy := *card
+		imageCopy := *image
+		th.Store.EXPECT().GetBoard("source-board").Return(source, nil)
+		th.Store.EXPECT().GetBoard("dest-board").Return(dest, nil)
+		th.Store.EXPECT().GetBlocksWithType("dest-board", model.TypeCard).Return([]*model.Block{}, nil)
+		th.Store.EXPECT().GetBlocksWithType("source-board", model.TypeCard).Return([]*model.Block{card}, nil)
+		th.Store.EXPECT().GetSubTree2("source-board", "card-id", gomock.Any()).Return([]*model.Block{&cardCopy, &imageCopy}, nil)
+		th.Store.EXPECT().GetFileInfo("fileID").Return(nil, model.NewErrNotFound("file info ID=fileID"))
+		th.Store.EXPECT().ApplyCardBulkChanges(gomock.Any(), "user-id").Return(nil, errDummy)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("MoveFile", sourcePath, destPath).Return(nil)
+		mockedFileBackend.On("MoveFile", destPath, sourcePath).Return(nil)
+
+		req := &model.CardBulkRequest{
+			Filter:     model.CardFilter{CardIDs: []string{"card-id"}},
+			Operations: []model.CardBulkOperation{{Type: model.CardBulkMove, BoardID: "dest-board"}},
+		}
+		_, err := th.App.ApplyCardBulkOperations("source-board", req, "user-id")
+		require.ErrorIs(t, err, errDummy)
+		mockedFileBackend.AssertExpectations(t)
+	})
+}
+
+func TestApplyCardBulkPropertyOperation(t *testing.T) {
+	multiPerson := model.PropDef{ID: "assignees", Type: "multiPerson"}
+	props := map[string]interface{}{"assignees": []interface{}{"user-1"}}
+
+	applyCardBulkPropertyOperation(multiPerson, props, model.CardBulkOperation{Type: model.CardBulkAddPerson, PropertyID: "assignees", UserID: "user-2"})
+	require.Equal(t, []interface{}{"user-1", "user-2"}, props["assignees"])
+
+	applyCardBulkPropertyOperation(multiPerson, props, model.CardBulkOperation{Type: model.CardBulkRemovePerson, PropertyID: "assignees", UserID: "user-1"})
+	require.Equal(t, []interface{}{"user-2"}, props["assignees"])
+
+	applyCardBulkPropertyOperation(multiPerson, props, model.CardBulkOperation{Type: model.CardBulkRemovePerson, PropertyID: "assignees", UserID: "user-2"})
+	require.NotContains(t, props, "assignees")
+}
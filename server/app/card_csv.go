@@ -0,0 +1,672 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	cardCSVTitleHeader  = "Name"
+	cardCSVMaxCards     = 10000
+	cardCSVPreviewCards = 10
+	cardCSVDateFormat   = "January 02, 2006"
+	cardCSVDateRangeSep = " -> "
+
+	// cardCSVFormulaPrefixes are the first characters of the cells that
+	// spreadsheets evaluate as formulas
+	cardCSVFormulaPrefixes = "=+-@\t\r"
+)
+
+// cardCSVPropertyTypes are the types of the properties a CSV column can be
+// imported to. The other types are computed from the cards.
+var cardCSVPropertyTypes = map[string]bool{
+	"text":        true,
+	"number":      true,
+	"email":       true,
+	"phone":       true,
+	"url":         true,
+	"checkbox":    true,
+	"select":      true,
+	"multiSelect": true,
+	"date":        true,
+	"person":      true,
+	"multiPerson": true,
+}
+
+// cardCSVDateLayouts are the date formats accepted by CSV imports, the
+// first one being the one of CSV exports.
+var cardCSVDateLayouts = []string{
+	cardCSVDateFormat,
+	"2006-01-02",
+	time.RFC3339,
+	"01/02/2006",
+	"Jan 2, 2006",
+}
+
+// ExportBoardCSV writes the cards of a board to a CSV file, with the title
+// and one column per card property. If a view is set, only the cards and
+// properties it shows are exported, in the order of the view.
+func (a *App) ExportBoardCSV(w io.Writer, boardID, viewID string) error {
+	board, err := a.store.GetBoard(boardID)
+	if err != nil {
+		return err
+	}
+	schema, err := model.ParsePropertySchema(board)
+	if err != nil {
+		return err
+	}
+
+	cards, err := a.store.GetBlocksWithType(boardID, model.TypeCard)
+	if err != nil {
+		return err
+	}
+	cards = model.ExcludeArchivedCards(cards)
+
+	propDefs := make([]model.PropDef, 0, len(schema))
+	for _, propDef := range schema {
+		propDefs = append(propDefs, propDef)
+	}
+	sort.Slice(propDefs, func(i, j int) bool { return propDefs[i].Index < propDefs[j].Index })
+
+	if viewID != "" {
+		if propDefs, cards, err = a.applyCSVExportView(boardID, viewID, schema, propDefs, cards); err != nil {
+			return err
+		}
+	} else {
+		sort.SliceStable(cards, func(i, j int) bool { return cards[i].CreateAt < cards[j].CreateAt })
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{cardCSVTitleHeader}
+	for _, propDef := range propDefs {
+		header = append(header, escapeCSVCell(propDef.Name))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, card := range cards {
+		if isTemplate, _ := card.Fields["isTemplate"].(bool); isTemplate {
+			continue
+		}
+		props, _ := card.Fields["properties"].(map[string]interface{})
+
+		row := []string{escapeCSVCell(card.Title)}
+		for _, propDef := range propDefs {
+			row = append(row, escapeCSVCell(a.cardCSVValue(propDef, card, props[propDef.ID])))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// applyCSVExportView returns the properties and cards a view shows.
+func (a *App) applyCSVExportView(boardID, viewID string, schema model.PropSchema, propDefs []model.PropDef, cards []*model.Block) ([]model.PropDef, []*model.Block, error) {
+	view, err := a.store.GetBlock(viewID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if view.Type != model.TypeView || view.BoardID != boardID {
+		return nil, nil, model.NewErrNotFound("view ID=" + viewID)
+	}
+
+	visible := map[string]bool{}
+	visibleIDs, _ := view.Fields["visiblePropertyIds"].([]interface{})
+	for _, id := range visibleIDs {
+		if s, ok := id.(string); ok {
+			visible[s] = true
+		}
+	}
+	// calendar views show the date they display cards by even when it's
+	// not one of the visible properties
+	if viewType, _ := view.Fields["viewType"].(string); viewType == "calendar" {
+		if dateID, ok := view.Fields["dateDisplayPropertyId"].(string); ok && dateID != "" {
+			visible[dateID] = true
+		}
+	}
+	visiblePropDefs := []model.PropDef{}
+	for _, propDef := range propDefs {
+		if visible[propDef.ID] {
+			visiblePropDefs = append(visiblePropDefs, propDef)
+		}
+	}
+
+	filter := model.ParseViewFilterGroup(view.Fields["filter"])
+	shown := []*model.Block{}
+	for _, card := range cards {
+		if filter.Matches(card, schema) {
+			shown = append(shown, card)
+		}
+	}
+
+	// cards follow the manual order of the view, the others come after
+	// it, oldest first
+	order := map[string]int{}
+	cardOrder, _ := view.Fields["cardOrder"].([]interface{})
+	for i, id := range cardOrder {
+		if s, ok := id.(string); ok {
+			order[s] = i
+		}
+	}
+	sort.SliceStable(shown, func(i, j int) bool {
+		oi, iOrdered := order[shown[i].ID]
+		oj, jOrdered := order[shown[j].ID]
+		switch {
+		case iOrdered && jOrdered:
+			return oi < oj
+		case iOrdered != jOrdered:
+			return iOrdered
+		}
+		return shown[i].CreateAt < shown[j].CreateAt
+	})
+
+	return visiblePropDefs, shown, nil
+}
+
+// cardCSVValue renders the value of a property of a card for a CSV file.
+func (a *App) cardCSVValue(propDef model.PropDef, card *model.Block, value interface{}) string {
+	switch propDef.Type {
+	case "createdBy":
+		value = card.CreatedBy
+		propDef.Type = "person"
+	case "updatedBy":
+		value = card.ModifiedBy
+		propDef.Type = "person"
+	case "createdTime":
+		return utils.GetTimeForMillis(card.CreateAt).Format(cardCSVDateFormat)
+	case "updatedTime":
+		return utils.GetTimeForMillis(card.UpdateAt).Format(cardCSVDateFormat)
+	}
+	if value == nil || value == "" {
+		return ""
+	}
+
+	s, err := propDef.GetValue(value, a.store)
+	if err != nil {
+		a.logger.Debug("Cannot render the value of a card property for a CSV export",
+			mlog.String("card_id", card.ID),
+			mlog.String("property_id", propDef.ID),
+			mlog.Err(err),
+		)
+		return fmt.Sprintf("%v", value)
+	}
+	return s
+}
+
+// cardCSVImport holds the state of a CSV import while the rows are read.
+type cardCSVImport struct {
+	opt     model.CardCSVImportOptions
+	board   *model.Board
+	schema  model.PropSchema
+	columns []model.CardCSVColumn
+	result  *model.CardCSVImportResult
+
+	// the properties created by the import, and the existing ones it
+	// adds options to.
+	newPropIDs     map[string]bool
+	changedPropIDs map[string]bool
+	users          map[string]string
+	colorIndex     int
+}
+
+// ImportCardsCSV creates a card per row of a CSV file. Columns are mapped
+// to the properties of the board with the same name unless the options
+// map them otherwise, and new properties are created for the others.
+// Options of select properties are created for unknown values, and
+// person columns are resolved by username or email. Values that can't
+// be imported are reported as warnings. A dry run reports what would be
+// imported without changing the board.
+func (a *App) ImportCardsCSV(r io.Reader, opt model.CardCSVImportOptions) (*model.CardCSVImportResult, error) {
+	board, err := a.store.GetBoard(opt.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := model.ParsePropertySchema(board)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, model.NewErrBadRequest("the CSV file is empty")
+	}
+	if err != nil {
+		return nil, model.NewErrBadRequest("invalid CSV file: " + err.Error())
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = unescapeCSVCell(header[i])
+	}
+
+	imp := &cardCSVImport{
+		opt:    opt,
+		board:  board,
+		schema: schema,
+		result: &model.CardCSVImportResult{
+			DryRun:        opt.DryRun,
+			Columns:       []model.CardCSVColumn{},
+			NewProperties: []string{},
+			NewOptions:    map[string][]string{},
+			Preview:       []*model.Card{},
+			Warnings:      []model.CardCSVWarning{},
+		},
+		newPropIDs:     map[string]bool{},
+		changedPropIDs: map[string]bool{},
+		users:          map[string]string{},
+	}
+	if err := imp.mapColumns(header); err != nil {
+		return nil, err
+	}
+
+	cards := []*model.Card{}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, model.NewErrBadRequest(fmt.Sprintf("invalid CSV file at line %d: %s", line, err.Error()))
+		}
+		if len(cards) == cardCSVMaxCards {
+			return nil, model.NewErrBadRequest(fmt.Sprintf("too many cards, the maximum is %d", cardCSVMaxCards))
+		}
+		cards = append(cards, a.cardFromCSVRecord(imp, record, line))
+	}
+	imp.result.CardCount = len(cards)
+
+	if opt.DryRun {
+		for i := 0; i < len(cards) && i < cardCSVPreviewCards; i++ {
+			imp.result.Preview = append(imp.result.Preview, cards[i])
+		}
+		return imp.result, nil
+	}
+	if len(cards) == 0 {
+		return imp.result, nil
+	}
+
+	blocks := make([]*model.Block, 0, len(cards))
+	wipCards := make([]*model.Block, 0, len(cards))
+	wipPatches := make([]*model.BlockPatch, 0, len(cards))
+	for _, card := range cards {
+		card.PopulateWithBoardID(board.ID)
+		card.CreatedBy = opt.ModifiedBy
+		card.ModifiedBy = opt.ModifiedBy
+		blocks = append(blocks, model.Card2Block(card))
+
+		wipCards = append(wipCards, &model.Block{Type: model.TypeCard})
+		wipPatches = append(wipPatches, &model.BlockPatch{UpdatedFields: map[string]interface{}{"properties": card.Properties}})
+	}
+	if err := a.checkWIPLimits(board, wipCards, wipPatches, opt.ModifiedBy); err != nil {
+		return nil, err
+	}
+
+	if patch := imp.boardPatch(); patch != nil {
+		if !a.permissions.HasPermissionToBoard(opt.ModifiedBy, board.ID, model.PermissionManageBoardProperties) {
+			return nil, model.NewErrPermission("access denied to create the properties and options of the imported columns")
+		}
+		if _, err := a.PatchBoard(patch, board.ID, opt.ModifiedBy); err != nil {
+			return nil, fmt.Errorf("cannot add the imported properties to board %s: %w", board.ID, err)
+		}
+	}
+
+	// notifications are disabled, as for the other bulk data inserts
+	if _, err := a.InsertBlocksAndNotify(blocks, opt.ModifiedBy, true); err != nil {
+		return nil, fmt.Errorf("cannot import cards to board %s: %w", board.ID, err)
+	}
+
+	a.logger.Debug("Cards imported from CSV",
+		mlog.String("board_id", board.ID),
+		mlog.Int("card_count", len(cards)),
+		mlog.Int("warning_count", len(imp.result.Warnings)),
+	)
+	return imp.result, nil
+}
+
+// mapColumns maps each column of the header to the title or a property.
+func (imp *cardCSVImport) mapColumns(header []string) error {
+	hasTitle := false
+	for _, name := range header {
+		column, ok := imp.opt.ColumnFor(name)
+		column.Name = name
+		if !ok {
+			column = imp.defaultColumn(name, hasTitle)
+		}
+
+		switch {
+		case column.Skip:
+		case column.PropertyID == model.CardCSVTitleColumn:
+			hasTitle = true
+		case column.PropertyID != "":
+			propDef, ok := imp.schema[column.PropertyID]
+			if !ok {
+				return model.NewErrBadRequest(fmt.Sprintf("column %s is mapped to property %s, which doesn't exist", name, column.PropertyID))
+			}
+			column.Type = propDef.Type
+			if !cardCSVPropertyTypes[propDef.Type] {
+				imp.warn(1, name, fmt.Sprintf("properties of type %s are computed and can't be imported", propDef.Type))
+				column.Skip = true
+			}
+		default:
+			if column.Type == "" {
+				column.Type = "text"
+			}
+			if !cardCSVPropertyTypes[column.Type] {
+				return model.NewErrBadRequest(fmt.Sprintf("invalid property type %s for column %s", column.Type, name))
+			}
+			column.PropertyID = utils.NewID(utils.IDTypeNone)
+			imp.schema[column.PropertyID] = model.PropDef{
+				ID:      column.PropertyID,
+				Index:   len(imp.schema),
+				Name:    name,
+				Type:    column.Type,
+				Options: map[string]model.PropDefOption{},
+			}
+			imp.newPropIDs[column.PropertyID] = true
+			imp.result.NewProperties = append(imp.result.NewProperties, name)
+		}
+		imp.columns = append(imp.columns, column)
+		imp.result.Columns = append(imp.result.Columns, column)
+	}
+	return nil
+}
+
+// defaultColumn maps a column without a mapping in the options to the
+// title, or to the property with the same name.
+func (imp *cardCSVImport) defaultColumn(name string, hasTitle bool) model.CardCSVColumn {
+	trimmed := strings.TrimSpace(name)
+	if !hasTitle && (strings.EqualFold(trimmed, cardCSVTitleHeader) || strings.EqualFold(trimmed, model.CardCSVTitleColumn)) {
+		return model.CardCSVColumn{Name: name, PropertyID: model.CardCSVTitleColumn}
+	}
+	for _, propDef := range imp.schema {
+		if strings.EqualFold(propDef.Name, trimmed) {
+			return model.CardCSVColumn{Name: name, PropertyID: propDef.ID}
+		}
+	}
+	return model.CardCSVColumn{Name: name}
+}
+
+func (imp *cardCSVImport) warn(line int, column, message string) {
+	imp.result.Warnings = append(imp.result.Warnings, model.CardCSVWarning{Line: line, Column: column, Error: message})
+}
+
+// cardFromCSVRecord returns the card of a row of the file.
+func (a *App) cardFromCSVRecord(imp *cardCSVImport, record []string, line int) *model.Card {
+	card := &model.Card{
+		BoardID:    imp.board.ID,
+		Properties: map[string]any{},
+	}
+
+	for i, column := range imp.columns {
+		if column.Skip || i >= len(record) {
+			continue
+		}
+		s := unescapeCSVCell(strings.TrimSpace(record[i]))
+		if column.PropertyID == model.CardCSVTitleColumn {
+			card.Title = s
+			continue
+		}
+		if s == "" {
+			continue
+		}
+
+		value, err := a.cardCSVPropertyValue(imp, imp.schema[column.PropertyID], s)
+		if err != nil {
+			imp.warn(line, column.Name, err.Error())
+			continue
+		}
+		if value != nil {
+			card.Properties[column.PropertyID] = value
+		}
+	}
+	return card
+}
+
+// cardCSVPropertyValue parses a value of a CSV file into the value of a
+// card property.
+func (a *App) cardCSVPropertyValue(imp *cardCSVImport, propDef model.PropDef, s string) (interface{}, error) {
+	switch propDef.Type {
+	case "number":
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, fmt.Errorf("%s is not a number", s)
+		}
+		return s, nil
+
+	case "checkbox":
+		switch strings.ToLower(s) {
+		case "true", "yes", "y", "1", "x":
+			return "true", nil
+		case "false", "no", "n", "0":
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s is not a checkbox value", s)
+
+	case "select":
+		return imp.optionID(propDef.ID, s), nil
+
+	case "multiSelect":
+		optionIDs := []interface{}{}
+		for _, item := range splitCSVList(s) {
+			optionIDs = append(optionIDs, imp.optionID(propDef.ID, item))
+		}
+		return optionIDs, nil
+
+	case "person":
+		return a.resolveCSVUser(imp, s)
+
+	case "multiPerson":
+		userIDs := []interface{}{}
+		for _, item := range splitCSVList(s) {
+			userID, err := a.resolveCSVUser(imp, item)
+			if err != nil {
+				return nil, err
+			}
+			userIDs = append(userIDs, userID)
+		}
+		return userIDs, nil
+
+	case "date":
+		return parseCSVDate(s)
+	}
+	return s, nil
+}
+
+// optionID returns the id of the option of a select property with a
+// value, creating the option if there is none. Values are compared
+// ignoring case, as exports render them in upper case.
+func (imp *cardCSVImport) optionID(propID, value string) string {
+	propDef := imp.schema[propID]
+	for _, opt := range propDef.Options {
+		if strings.EqualFold(opt.Value, value) {
+			return opt.ID
+		}
+	}
+
+	opt := model.PropDefOption{
+		ID:    utils.NewID(utils.IDTypeNone),
+		Index: len(propDef.Options),
+		Color: model.PropOptionColor(imp.colorIndex),
+		Value: value,
+	}
+	imp.colorIndex++
+	propDef.Options[opt.ID] = opt
+	if !imp.newPropIDs[propID] {
+		imp.changedPropIDs[propID] = true
+	}
+	imp.result.NewOptions[propDef.Name] = append(imp.result.NewOptions[propDef.Name], value)
+	return opt.ID
+}
+
+// resolveCSVUser returns the id of the member of the team of the board
+// with a username or email.
+func (a *App) resolveCSVUser(imp *cardCSVImport, s string) (string, error) {
+	name := strings.TrimPrefix(s, "@")
+	if userID, ok := imp.users[name]; ok {
+		return userID, nil
+	}
+
+	var user *model.User
+	var err error
+	if strings.Contains(name, "@") {
+		user, err = a.findTeamUser(imp.board.TeamID, "", name)
+	} else {
+		user, err = a.findTeamUser(imp.board.TeamID, name, "")
+	}
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", fmt.Errorf("no user with username or email %s", s)
+	}
+
+	imp.users[name] = user.ID
+	return user.ID, nil
+}
+
+// boardPatch returns the patch adding the properties and options created
+// by the import to the board, nil if there are none.
+func (imp *cardCSVImport) boardPatch() *model.BoardPatch {
+	if len(imp.newPropIDs) == 0 && len(imp.changedPropIDs) == 0 {
+		return nil
+	}
+
+	patch := &model.BoardPatch{}
+	// existing properties keep the fields the schema doesn't parse
+	for _, prop := range imp.board.CardProperties {
+		id, _ := prop["id"].(string)
+		if !imp.changedPropIDs[id] {
+			continue
+		}
+		updated := map[string]interface{}{}
+		for k, v := range prop {
+			updated[k] = v
+		}
+		updated["options"] = mergeCSVOptions(prop["options"], imp.schema[id])
+		patch.UpdatedCardProperties = append(patch.UpdatedCardProperties, updated)
+	}
+
+	for _, column := range imp.columns {
+		if !imp.newPropIDs[column.PropertyID] {
+			continue
+		}
+		propDef := imp.schema[column.PropertyID]
+		patch.UpdatedCardProperties = append(patch.UpdatedCardProperties, map[string]interface{}{
+			"id":      propDef.ID,
+			"name":    propDef.Name,
+			"type":    propDef.Type,
+			"options": mergeCSVOptions(nil, propDef),
+		})
+	}
+	return patch
+}
+
+// mergeCSVOptions appends the options created by an import to the
+// options of a property.
+func mergeCSVOptions(existing interface{}, propDef model.PropDef) []interface{} {
+	options, _ := existing.([]interface{})
+	merged := append([]interface{}{}, options...)
+
+	known := map[string]bool{}
+	for _, opt := range options {
+		if m, ok := opt.(map[string]interface{}); ok {
+			id, _ := m["id"].(string)
+			known[id] = true
+		}
+	}
+
+	added := []model.PropDefOption{}
+	for _, opt := range propDef.Options {
+		if !known[opt.ID] {
+			added = append(added, opt)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Index < added[j].Index })
+	for _, opt := range added {
+		merged = append(merged, map[string]interface{}{
+			"id":    opt.ID,
+			"value": opt.Value,
+			"color": opt.Color,
+		})
+	}
+	return merged
+}
+
+// escapeCSVCell prefixes the cells that spreadsheets would evaluate as
+// formulas with a quote, so that they are shown as text.
+func escapeCSVCell(s string) string {
+	if s != "" && strings.ContainsRune(cardCSVFormulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// unescapeCSVCell removes the quote escapeCSVCell adds to a cell.
+func unescapeCSVCell(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(cardCSVFormulaPrefixes, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+func splitCSVList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseCSVDate parses a date, or a range of dates as exported, into the
+// JSON value of a date property. Dates are set at noon UTC so they show
+// as the same day in most time zones.
+func parseCSVDate(s string) (string, error) {
+	parts := strings.SplitN(s, strings.TrimSpace(cardCSVDateRangeSep), 2)
+	value := map[string]int64{}
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		var date time.Time
+		var err error
+		for _, layout := range cardCSVDateLayouts {
+			if date, err = time.Parse(layout, part); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return "", fmt.Errorf("%s is not a date", s)
+		}
+
+		millis := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, time.UTC).UnixMilli()
+		if i == 0 {
+			value["from"] = millis
+		} else {
+			value["to"] = millis
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,251 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/permissions/localpermissions"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportBoardCSV(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	cards := []*model.Block{
+		{
+			ID:       "card-2",
+			BoardID:  "board-id",
+			Type:     model.TypeCard,
+			Title:    "Second, with comma",
+			CreateAt: 2,
+			Fields:   map[string]interface{}{"properties": map[string]interface{}{"status": "done", "estimate": "3"}},
+		},
+		{
+			ID:       "card-1",
+			BoardID:  "board-id",
+			Type:     model.TypeCard,
+			Title:    "First",
+			CreateAt: 1,
+			Fields:   map[string]interface{}{"properties": map[string]interface{}{"status": "todo", "owner": "user-1"}},
+		},
+	}
+
+	t.Run("exports every property of the board", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(cards, nil)
+		th.Store.EXPECT().GetUserByID("user-1").Return(&model.User{ID: "user-1", Username: "alice"}, nil)
+
+		var buf bytes.Buffer
+		require.NoError(t, th.App.ExportBoardCSV(&buf, "board-id", ""))
+		require.Equal(t, "Name,Status,Owner,Estimate\nFirst,TO DO,alice,\n\"Second, with comma\",DONE,,3\n", buf.String())
+	})
+
+	t.Run("exports the cards and properties shown by a view", func(t *testing.T) {
+		view := &model.Block{
+			ID:      "view-id",
+			BoardID: "board-id",
+			Type:    model.TypeView,
+			Fields: map[string]interface{}{
+				"visiblePropertyIds": []interface{}{"estimate", "status"},
+				"filter": map[string]interface{}{
+					"operation": "and",
+					"filters": []interface{}{
+						map[string]interface{}{"propertyId": "status", "condition": "includes", "values": []interface{}{"done", "todo"}},
+					},
+				},
+				"cardOrder": []interface{}{"card-2"},
+			},
+		}
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(cards, nil)
+		th.Store.EXPECT().GetBlock("view-id").Return(view, nil)
+
+		var buf bytes.Buffer
+		require.NoError(t, th.App.ExportBoardCSV(&buf, "board-id", "view-id"))
+		require.Equal(t, "Name,Status,Estimate\n\"Second, with comma\",DONE,3\nFirst,TO DO,\n", buf.String())
+	})
+
+	t.Run("escapes the cells evaluated as formulas", func(t *testing.T) {
+		formulaCards := []*model.Block{
+			{
+				ID:       "card-1",
+				BoardID:  "board-id",
+				Type:     model.TypeCard,
+				Title:    "=HYPERLINK(\"http://example.com\")",
+				CreateAt: 1,
+				Fields:   map[string]interface{}{"properties": map[string]interface{}{"estimate": "-3"}},
+			},
+			{
+				ID:       "card-2",
+				BoardID:  "board-id",
+				Type:     model.TypeCard,
+				Title:    "@SUM(A1)",
+				CreateAt: 2,
+				Fields:   map[string]interface{}{"properties": map[string]interface{}{"estimate": "+1"}},
+			},
+		}
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(formulaCards, nil)
+
+		var buf bytes.Buffer
+		require.NoError(t, th.App.ExportBoardCSV(&buf, "board-id", ""))
+		require.Equal(t, "Name,Status,Owner,Estimate\n\"'=HYPERLINK(\"\"http://example.com\"\")\",,,'-3\n'@SUM(A1),,,'+1\n", buf.String())
+	})
+
+	t.Run("rejects a view of another board", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(cards, nil)
+		th.Store.EXPECT().GetBlock("view-id").Return(&model.Block{ID: "view-id", BoardID: "other-board-id", Type: model.TypeView}, nil)
+
+		var buf bytes.Buffer
+		err := th.App.ExportBoardCSV(&buf, "board-id", "view-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
+
+func TestImportCardsCSV(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.permissions = localpermissions.New(th.Store, th.logger)
+
+	file := "Name,Status,Owner,Estimate,Due\n" +
+		"First,to do,@alice,3,2022-01-14\n" +
+		"Second,Blocked,bob@example.com,soon,\n" +
+		"Third,blocked,nobody,,\n"
+
+	expectUsers := func() {
+		th.Store.EXPECT().GetUserByUsername("alice").Return(&model.User{ID: "user-1", Username: "alice"}, nil)
+		th.Store.EXPECT().GetUserByEmail("bob@example.com").Return(&model.User{ID: "user-2", Username: "bob"}, nil)
+		th.Store.EXPECT().GetUserByUsername("nobody").Return(nil, model.NewErrNotFound("user"))
+	}
+
+	t.Run("dry run previews the import", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+		expectUsers()
+		th.Store.EXPECT().PatchBoard(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		th.Store.EXPECT().InsertBlock(gomock.Any(), gomock.Any()).Times(0)
+
+		opt := model.CardCSVImportOptions{BoardID: "board-id", ModifiedBy: "user-id", DryRun: true}
+		result, err := th.App.ImportCardsCSV(strings.NewReader(file), opt)
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Equal(t, 3, result.CardCount)
+		require.Equal(t, []string{"Due"}, result.NewProperties)
+		require.Equal(t, map[string][]string{"Status": {"Blocked"}}, result.NewOptions)
+
+		require.Len(t, result.Columns, 5)
+		require.Equal(t, model.CardCSVTitleColumn, result.Columns[0].PropertyID)
+		require.Equal(t, "status", result.Columns[1].PropertyID)
+		require.Equal(t, "text", result.Columns[4].Type)
+
+		require.Len(t, result.Preview, 3)
+		require.Equal(t, "First", result.Preview[0].Title)
+		require.Equal(t, "todo", result.Preview[0].Properties["status"])
+		require.Equal(t, "user-1", result.Preview[0].Properties["owner"])
+		require.Equal(t, "3", result.Preview[0].Properties["estimate"])
+		require.Equal(t, result.Preview[1].Properties["status"], result.Preview[2].Properties["status"])
+
+		require.Len(t, result.Warnings, 2)
+		require.Equal(t, model.CardCSVWarning{Line: 3, Column: "Estimate", Error: "soon is not a number"}, result.Warnings[0])
+		require.Equal(t, 4, result.Warnings[1].Line)
+		require.Equal(t, "Owner", result.Warnings[1].Column)
+	})
+
+	t.Run("maps columns as requested", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+		th.Store.EXPECT().GetUserByUsername("alice").Return(&model.User{ID: "user-1", Username: "alice"}, nil)
+
+		opt := model.CardCSVImportOptions{
+			BoardID:    "board-id",
+			ModifiedBy: "user-id",
+			DryRun:     true,
+			Columns: []model.CardCSVColumn{
+				{Name: "Status", Skip: true},
+				{Name: "Estimate", Skip: true},
+				{Name: "Due", Type: "date"},
+			},
+		}
+		result, err := th.App.ImportCardsCSV(strings.NewReader("Name,Status,Owner,Estimate,Due\nFirst,to do,alice,3,\"January 14, 2022\"\n"), opt)
+		require.NoError(t, err)
+		require.Empty(t, result.NewOptions)
+		require.Empty(t, result.Warnings)
+		require.Len(t, result.Preview, 1)
+		require.NotContains(t, result.Preview[0].Properties, "status")
+		require.Contains(t, result.Preview[0].Properties, result.Columns[4].PropertyID)
+		require.Equal(t, `{"from":1642161600000}`, result.Preview[0].Properties[result.Columns[4].PropertyID])
+
+		opt.Columns = []model.CardCSVColumn{{Name: "Status", PropertyID: "unknown"}}
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+		_, err = th.App.ImportCardsCSV(strings.NewReader(file), opt)
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("creates the properties, options and cards", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil).Times(2)
+		expectUsers()
+		th.Store.EXPECT().GetMemberForBoard("board-id", "user-id").Return(&model.BoardMember{SchemeEditor: true}, nil)
+		th.Store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{}, nil).AnyTimes()
+		th.Store.EXPECT().PatchBoard("board-id", gomock.Any(), "user-id").DoAndReturn(
+			func(boardID string, patch *model.BoardPatch, userID string) (*model.Board, error) {
+				require.Len(t, patch.UpdatedCardProperties, 2)
+				status := patch.UpdatedCardProperties[0]
+				require.Equal(t, "status", status["id"])
+				require.Len(t, status["options"], 4)
+				require.Equal(t, "Due", patch.UpdatedCardProperties[1]["name"])
+				return makeTestBoard("board-id", ""), nil
+			})
+		inserted := []*model.Block{}
+		th.Store.EXPECT().InsertBlock(gomock.Any(), "user-id").DoAndReturn(
+			func(block *model.Block, userID string) error {
+				inserted = append(inserted, block)
+				return nil
+			}).Times(3)
+
+		opt := model.CardCSVImportOptions{BoardID: "board-id", ModifiedBy: "user-id"}
+		result, err := th.App.ImportCardsCSV(strings.NewReader(file), opt)
+		require.NoError(t, err)
+		require.False(t, result.DryRun)
+		require.Equal(t, 3, result.CardCount)
+		require.Empty(t, result.Preview)
+
+		require.Len(t, inserted, 3)
+		require.Equal(t, "First", inserted[0].Title)
+		require.Equal(t, "user-id", inserted[0].CreatedBy)
+		require.EqualValues(t, model.TypeCard, inserted[0].Type)
+	})
+
+	t.Run("requires the permission to manage properties to create them", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+		expectUsers()
+		th.Store.EXPECT().GetMemberForBoard("board-id", "user-id").Return(&model.BoardMember{SchemeEditor: false, SchemeCommenter: true}, nil)
+		th.Store.EXPECT().PatchBoard(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		opt := model.CardCSVImportOptions{BoardID: "board-id", ModifiedBy: "user-id"}
+		_, err := th.App.ImportCardsCSV(strings.NewReader(file), opt)
+		require.True(t, model.IsErrForbidden(err))
+	})
+
+	t.Run("reads the cells escaped by exports", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+
+		opt := model.CardCSVImportOptions{BoardID: "board-id", ModifiedBy: "user-id", DryRun: true}
+		result, err := th.App.ImportCardsCSV(strings.NewReader("Name,Estimate\n'=1+1,'-3\n'quoted,\n"), opt)
+		require.NoError(t, err)
+		require.Empty(t, result.Warnings)
+		require.Len(t, result.Preview, 2)
+		require.Equal(t, "=1+1", result.Preview[0].Title)
+		require.Equal(t, "-3", result.Preview[0].Properties["estimate"])
+		require.Equal(t, "'quoted", result.Preview[1].Title)
+	})
+
+	t.Run("rejects an empty file", func(t *testing.T) {
+		th.Store.EXPECT().GetBoard("board-id").Return(makeTestBoard("board-id", ""), nil)
+
+		_, err := th.App.ImportCardsCSV(strings.NewReader(""), model.CardCSVImportOptions{BoardID: "board-id"})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+}
@@ -0,0 +1,367 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// datedUploadsRoot is the directory of the uploads stored by date, see utils.GetBaseFilePath.
+const datedUploadsRoot = "boards"
+
+// MoveCard moves a card, together with its content blocks and comments, to another board.
+// The card keeps its ID, so its history, comment authorship and subscriptions are preserved.
+// Card properties are mapped onto the destination board's schema as described by opts.
+func (a *App) MoveCard(cardID string, opts model.CardMoveOptions, userID string) (*model.Card, error) {
+	oldCard, err := a.store.GetBlock(cardID)
+	if err != nil {
+		return nil, err
+	}
+	if oldCard.Type != model.TypeCard {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("block %s is not a card", cardID))
+	}
+	if oldCard.BoardID == opts.DestinationBoardID {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("card %s already belongs to board %s", cardID, opts.DestinationBoardID))
+	}
+
+	sourceBoard, err := a.store.GetBoard(oldCard.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	destBoard, err := a.store.GetBoard(opts.DestinationBoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper, err := a.newCardPropertyMapper(sourceBoard, destBoard, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := a.store.GetSubTree2(sourceBoard.ID, cardID, model.QuerySubtreeOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	props := mapper.mapProperties(getCardProperties(oldCard))
+
+	// for the destination board the card is a new arrival in the columns it maps to.
+	arrival := &model.BlockPatch{UpdatedFields: map[string]interface{}{"properties": props}}
+	if err = a.checkWIPLimits(destBoard, []*model.Block{{Type: model.TypeCard}}, []*model.BlockPatch{arrival}, userID); err != nil {
+		return nil, err
+	}
+
+	hasFiles := relocateCardBlocks(blocks, cardID, destBoard.ID, props)
+
+	revertFiles := func() {}
+	if hasFiles {
+		if revertFiles, err = a.moveCardFiles(sourceBoard, destBoard, blocks); err != nil {
+			return nil, fmt.Errorf("cannot move the files of card %s to board %s: %w", cardID, destBoard.ID, err)
+		}
+	}
+
+	if err = a.store.MoveBlocks(blocks, sourceBoard.ID, userID); err != nil {
+		revertFiles()
+		return nil, fmt.Errorf("cannot move card %s to board %s: %w", cardID, destBoard.ID, err)
+	}
+
+	var card *model.Block
+	for _, block := range blocks {
+		if block.ID == cardID {
+			card = block
+		}
+	}
+	if card == nil {
+		return nil, model.NewErrNotFound("card ID=" + cardID)
+	}
+
+	a.logger.Debug("Card moved",
+		mlog.String("card_id", cardID),
+		mlog.String("source_board_id", sourceBoard.ID),
+		mlog.String("destination_board_id", destBoard.ID),
+		mlog.Int("block_count", len(blocks)),
+	)
+
+	a.blockChangeNotifier.Enqueue(func() error {
+		for _, block := range blocks {
+			a.wsAdapter.BroadcastBlockDelete(sourceBoard.TeamID, block.ID, sourceBoard.ID)
+		}
+		for _, block := range blocks {
+			a.wsAdapter.BroadcastBlockChange(destBoard.TeamID, block)
+			a.webhook.NotifyUpdate(block)
+		}
+		a.metrics.IncrementBlocksPatched(len(blocks))
+		a.notifyBlockChanged(notify.Update, card, oldCard, userID)
+		return nil
+	})
+
+	return model.Block2Card(card)
+}
+
+// relocateCardBlocks sets the destination board on the blocks of a card subtree and the
+// mapped properties on the card. It returns true if any of the blocks holds a file.
+func relocateCardBlocks(blocks []*model.Block, cardID, destBoardID string, props map[string]interface{}) bool {
+	hasFiles := false
+	for _, block := range blocks {
+		block.BoardID = destBoardID
+		if block.ID == cardID {
+			if block.Fields == nil {
+				block.Fields = map[string]interface{}{}
+			}
+			block.ParentID = destBoardID
+			block.Fields["properties"] = props
+		}
+		if block.Type == model.TypeImage || block.Type == model.TypeAttachment {
+			hasFiles = true
+		}
+	}
+	return hasFiles
+}
+
+// moveCardFiles moves the files of a card that are stored by board to the
+// destination board, before the blocks of the card are moved. Other files keep
+// their path, and are moved to the storage usage of the destination board along
+// with the blocks, unless the destination is a template: the files of templates
+// are stored by board so that data retention does not remove them. It returns a
+// function that moves the files back if the blocks can't be moved.
+func (a *App) moveCardFiles(sourceBoard, destBoard *model.Board, blocks []*model.Block) (func(), error) {
+	var movedPaths [][2]string
+	var movedInfos []*mm_model.FileInfo
+
+	revert := func() {
+		for _, fileInfo := range movedInfos {
+			if err := a.store.UpdateFileInfoPath(fileInfo); err != nil {
+				a.logger.Error("moveCardFiles: cannot restore file info path", mlog.String("file_id", fileInfo.Id), mlog.Err(err))
+			}
+		}
+		for i := len(movedPaths) - 1; i >= 0; i-- {
+			if err := a.filesBackend.MoveFile(movedPaths[i][1], movedPaths[i][0]); err != nil {
+				a.logger.Error("moveCardFiles: cannot move file back", mlog.String("path", movedPaths[i][1]), mlog.Err(err))
+			}
+		}
+	}
+
+	moveFile := func(oldPath, newPath string) error {
+		if err := a.filesBackend.MoveFile(oldPath, newPath); err != nil {
+			return err
+		}
+		movedPaths = append(movedPaths, [2]string{oldPath, newPath})
+		return nil
+	}
+
+	for _, block := range blocks {
+		fileID := cardFileID(block)
+		if fileID == "" {
+			continue
+		}
+
+		fileInfo, sourcePath, err := a.GetFilePath(sourceBoard.TeamID, sourceBoard.ID, fileID)
+		if err != nil {
+			revert()
+			return nil, err
+		}
+
+		if fileInfo == nil || fileInfo.Path == "" || fileInfo.Path == emptyString {
+			// files without a path are found by their board.
+			if err = moveFile(sourcePath, filepath.Join(destBoard.TeamID, destBoard.ID, fileID)); err != nil {
+				revert()
+				return nil, err
+			}
+			continue
+		}
+
+		// data retention removes the dated uploads, but not the blobs.
+		_, isBlob := model.FileBlobHash(fileInfo.Path)
+		if !destBoard.IsTemplate || isBlob || !strings.HasPrefix(fileInfo.Path, datedUploadsRoot+"/") {
+			continue
+		}
+
+		movedInfo := *fileInfo
+		movedInfo.Path = getDestinationFilePath(true, destBoard.TeamID, destBoard.ID, filepath.Base(fileInfo.Path))
+		if err = moveFile(fileInfo.Path, movedInfo.Path); err != nil {
+			revert()
+			return nil, err
+		}
+		if movedInfo.HasPreviewImage {
+			movedInfo.ThumbnailPath = renditionPath(movedInfo.Path, model.FileRenditionThumb)
+			movedInfo.PreviewPath = renditionPath(movedInfo.Path, model.FileRenditionPreview)
+			for _, paths := range [][2]string{{fileInfo.ThumbnailPath, movedInfo.ThumbnailPath}, {fileInfo.PreviewPath, movedInfo.PreviewPath}} {
+				if err = moveFile(paths[0], paths[1]); err != nil {
+					revert()
+					return nil, err
+				}
+			}
+		}
+		if err = a.store.UpdateFileInfoPath(&movedInfo); err != nil {
+			revert()
+			return nil, err
+		}
+		movedInfos = append(movedInfos, fileInfo)
+	}
+
+	return revert, nil
+}
+
+// cardPropertyMapper translates card property values from the schema of one board to another.
+type cardPropertyMapper struct {
+	sourceSchema  model.PropSchema
+	destSchema    model.PropSchema
+	properties    map[string]string // source property ID -> destination property ID
+	optionMapping map[string]string
+	destMembers   map[string]bool // nil if any user can be assigned on the destination board
+}
+
+func (a *App) newCardPropertyMapper(sourceBoard, destBoard *model.Board, opts model.CardMoveOptions) (*cardPropertyMapper, error) {
+	sourceSchema, err := model.ParsePropertySchema(sourceBoard)
+	if err != nil {
+		return nil, err
+	}
+	destSchema, err := model.ParsePropertySchema(destBoard)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := &cardPropertyMapper{
+		sourceSchema:  sourceSchema,
+		destSchema:    destSchema,
+		properties:    map[string]string{},
+		optionMapping: opts.OptionMapping,
+	}
+
+	hasPersonProps := false
+	for sourceID, sourceDef := range sourceSchema {
+		destID, explicit := opts.PropertyMapping[sourceID]
+		if !explicit {
+			destID = findPropertyByName(destSchema, sourceDef)
+		}
+		if destID == "" {
+			continue
+		}
+
+		destDef, ok := destSchema[destID]
+		if !ok {
+			return nil, model.NewErrBadRequest(fmt.Sprintf("property %s does not exist in board %s", destID, destBoard.ID))
+		}
+		if destDef.Type != sourceDef.Type {
+			return nil, model.NewErrBadRequest(fmt.Sprintf("cannot map property %s of type %s to property %s of type %s",
+				sourceID, sourceDef.Type, destID, destDef.Type))
+		}
+
+		mapper.properties[sourceID] = destID
+		if destDef.Type == "person" || destDef.Type == "multiPerson" {
+			hasPersonProps = true
+		}
+	}
+
+	if hasPersonProps && destBoard.Type != model.BoardTypeOpen {
+		members, err := a.store.GetMembersForBoard(destBoard.ID)
+		if err != nil {
+			return nil, err
+		}
+		mapper.destMembers = make(map[string]bool, len(members))
+		for _, member := range members {
+			mapper.destMembers[member.UserID] = true
+		}
+	}
+
+	return mapper, nil
+}
+
+// findPropertyByName returns the ID of the property of a schema with the same name
+// and type as the specified property, or an empty string if there is none.
+func findPropertyByName(schema model.PropSchema, def model.PropDef) string {
+	for id, candidate := range schema {
+		if candidate.Type == def.Type && strings.EqualFold(strings.TrimSpace(candidate.Name), strings.TrimSpace(def.Name)) {
+			return id
+		}
+	}
+	return ""
+}
+
+// mapProperties returns the card properties translated to the destination schema.
+// Values that cannot be mapped are dropped.
+func (m *cardPropertyMapper) mapProperties(props map[string]interface{}) map[string]interface{} {
+	mapped := map[string]interface{}{}
+	for sourceID, value := range props {
+		destID, ok := m.properties[sourceID]
+		if !ok {
+			continue
+		}
+		if destValue, ok := m.mapValue(m.sourceSchema[sourceID], m.destSchema[destID], value); ok {
+			mapped[destID] = destValue
+		}
+	}
+	return mapped
+}
+
+func (m *cardPropertyMapper) mapValue(sourceDef, destDef model.PropDef, value interface{}) (interface{}, bool) {
+	switch destDef.Type {
+	case "select":
+		optionID, _ := value.(string)
+		destOptionID := m.mapOption(sourceDef, destDef, optionID)
+		return destOptionID, destOptionID != ""
+
+	case "multiSelect":
+		return mapValues(value, func(optionID string) string {
+			return m.mapOption(sourceDef, destDef, optionID)
+		})
+
+	case "person":
+		userID, _ := value.(string)
+		return userID, userID != "" && m.canAssign(userID)
+
+	case "multiPerson":
+		return mapValues(value, func(userID string) string {
+			if m.canAssign(userID) {
+				return userID
+			}
+			return ""
+		})
+	}
+	return value, true
+}
+
+// mapValues maps each of the values of a multi value property, dropping the empty results.
+func mapValues(value interface{}, mapOne func(string) string) (interface{}, bool) {
+	values, _ := value.([]interface{})
+	mapped := []interface{}{}
+	for _, v := range values {
+		s, _ := v.(string)
+		if result := mapOne(s); result != "" {
+			mapped = append(mapped, result)
+		}
+	}
+	return mapped, len(mapped) != 0
+}
+
+// mapOption returns the destination option ID for a source option ID, using the explicit
+// option mapping first and then matching option values.
+func (m *cardPropertyMapper) mapOption(sourceDef, destDef model.PropDef, optionID string) string {
+	if destID, ok := m.optionMapping[optionID]; ok {
+		if _, exists := destDef.Options[destID]; exists {
+			return destID
+		}
+		return ""
+	}
+
+	sourceOption, ok := sourceDef.Options[optionID]
+	if !ok {
+		return ""
+	}
+	for destID, destOption := range destDef.Options {
+		if strings.EqualFold(strings.TrimSpace(destOption.Value), strings.TrimSpace(sourceOption.Value)) {
+			return destID
+		}
+	}
+	return ""
+}
+
+func (m *cardPropertyMapper) canAssign(userID string) bool {
+	return m.destMembers == nil || m.destMembers[userID]
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore/mocks"
+)
+
+func TestCardPropertyMapper(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	source := makeTestBoard("source-board", "a-")
+	dest := makeTestBoard("dest-board", "b-")
+	props := map[string]interface{}{
+		"a-status": "a-done",
+		"a-owner":  "user-1",
+		"unknown":  "value",
+	}
+
+	t.Run("properties and options are matched by name", func(t *testing.T) {
+		mapper, err := th.App.newCardPropertyMapper(source, dest, model.CardMoveOptions{})
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{
+			"b-status": "b-done",
+			"b-owner":  "user-1",
+		}, mapper.mapProperties(props))
+	})
+
+	t.Run("explicit option mapping", func(t *testing.T) {
+		mapper, err := th.App.newCardPropertyMapper(source, dest, model.CardMoveOptions{
+			OptionMapping: map[string]string{"a-done": "b-todo"},
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "b-todo", mapper.mapProperties(props)["b-status"])
+	})
+
+	t.Run("mapping to a property of another type fails", func(t *testing.T) {
+		_, err := th.App.newCardPropertyMapper(source, dest, model.CardMoveOptions{
+			PropertyMapping: map[string]string{"a-status": "b-owner"},
+		})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("people that are not members of a private destination board are dropped", func(t *testing.T) {
+		private := makeTestBoard("dest-board", "b-")
+		private.Type = model.BoardTypePrivate
+		th.Store.EXPECT().GetMembersForBoard("dest-board").Return([]*model.BoardMember{{UserID: "user-2"}}, nil)
+
+		mapper, err := th.App.newCardPropertyMapper(source, private, model.CardMoveOptions{})
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{"b-status": "b-done"}, mapper.mapProperties(props))
+	})
+}
+
+func TestMoveCard(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	source := makeTestBoard("source-board", "a-")
+	dest := makeTestBoard("dest-board", "b-")
+
+	card := &model.Block{
+		ID:       "card-id",
+		BoardID:  "source-board",
+		ParentID: "source-board",
+		Type:     model.TypeCard,
+		Title:    "Card",
+		Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"a-status": "a-todo"},
+		},
+	}
+	comment := &model.Block{
+		ID:       "comment-id",
+		BoardID:  "source-board",
+		ParentID: "card-id",
+		Type:     model.TypeComment,
+	}
+
+	t.Run("card already on the destination board", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("card-id").Return(card, nil)
+
+		_, err := th.App.MoveCard("card-id", model.CardMoveOptions{DestinationBoardID: "source-board"}, "user-id")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("moves the card and its children", func(t *testing.T) {
+		cardCopy := *card
+		commentCopy := *comment
+		th.Store.EXPECT().GetBlock("card-id").Return(card, nil)
+		th.Store.EXPECT().GetBoard("source-board").Return(source, nil)
+		th.Store.EXPECT().GetBoard("dest-board").Return(dest, nil)
+		th.Store.EXPECT().GetSubTree2("source-board", "card-id", gomock.Any()).Return([]*model.Block{&cardCopy, &commentCopy}, nil)
+		th.Store.EXPECT().GetMembersForBoard(gomock.Any()).Return([]*model.BoardMember{}, nil).AnyTimes()
+		th.Store.EXPECT().MoveBlocks(gomock.Any(), "source-board", "user-id").DoAndReturn(
+			func(blocks []*model.Block, sourceBoardID, userID string) error {
+				require.Len(t, blocks, 2)
+				for _, block := range blocks {
+					require.Equal(t, "dest-board", block.BoardID)
+				}
+				require.Equal(t, "dest-board", blocks[0].ParentID)
+				require.Equal(t, "card-id", blocks[1].ParentID)
+				return nil
+			})
+
+		moved, err := th.App.MoveCard("card-id", model.CardMoveOptions{DestinationBoardID: "dest-board"}, "user-id")
+		require.NoError(t, err)
+		require.Equal(t, "card-id", moved.ID)
+		require.Equal(t, "dest-board", moved.BoardID)
+		require.Equal(t, map[string]any{"b-status": "b-todo"}, moved.Properties)
+	})
+
+	image := &model.Block{
+		ID:       "image-id",
+		BoardID:  "source-board",
+		ParentID: "card-id",
+		Type:     model.TypeImage,
+		Fields:   map[string]interface{}{"fileId": "7fileID.png"},
+	}
+	sourcePath := filepath.Join("team-id", "source-board", "7fileID.png")
+	destPath := filepath.Join("team-id", "dest-board", "7fileID.png")
+
+	t.Run("files stored by board are moved with the card", func(t *testing.T) {
+		cardCopy := *card
+		imageCopy := *image
+		th.Store.EXPECT().GetBlock("card-id").Return(card, nil)
+		th.Store.EXPECT().GetBoard("source-board").Return(source, nil)
+		th.Store.EXPECT().GetBoard("dest-board").Return(dest, nil)
+		th.Store.EXPECT().GetSubTree2("source-board", "card-id", gomock.Any()).Return([]*model.Block{&cardCopy, &imageCopy}, nil)
+		th.Store.EXPECT().GetMembersForBoard(gomock.Any()).Return([]*model.BoardMember{}, nil).AnyTimes()
+		th.Store.EXPECT().GetFileInfo("fileID").Return(nil, model.NewErrNotFound("file info ID=fileID"))
+		th.Store.EXPECT().MoveBlocks(gomock.Any(), "source-board", "user-id").DoAndReturn(
+			func(blocks []*model.Block, sourceBoardID, userID string) error {
+				require.Equal(t, "7fileID.png", blocks[1].Fields["fileId"])
+				return nil
+			})
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("MoveFile", sourcePath, destPath).Return(nil)
+
+		_, err := th.App.MoveCard("card-id", model.CardMoveOptions{DestinationBoardID: "dest-board"}, "user-id")
+		require.NoError(t, err)
+		mockedFileBackend.AssertExpectations(t)
+	})
+
+	t.Run("the card is not moved if a file cannot be moved", func(t *testing.T) {
+		cardCopy := *card
+		imageCopy := *image
+		th.Store.EXPECT().GetBlock("card-id").Return(card, nil)
+		th.Store.EXPECT().GetBoard("source-board").Return(source, nil)
+		th.Store.EXPECT().GetBoard("dest-board").Return(dest, nil)
+		th.Store.EXPECT().GetSubTree2("source-board", "card-id", gomock.Any()).Return([]*model.Block{&cardCopy, &imageCopy}, nil)
+		th.Store.EXPECT().GetMembersForBoard(gomock.Any()).Return([]*model.BoardMember{}, nil).AnyTimes()
+		th.Store.EXPECT().GetFileInfo("fileID").Return(nil, model.NewErrNotFound("file info ID=fileID"))
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("MoveFile", sourcePath, destPath).Return(errDummy)
+
+		_, err := th.App.MoveCard("card-id", model.CardMoveOptions{DestinationBoardID: "dest-board"}, "user-id")
+		require.ErrorIs(t, err, errDummy)
+	})
+
+	t.Run("files are moved back if the card cannot be moved", func(t *testing.T) {
+		cardCopy := *card
+		imageCopy := *image
+		th.Store.EXPECT().GetBlock("card-id").Return(card, nil)
+		th.Store.EXPECT().GetBoard("source-board").Return(source, nil)
+		th.Store.EXPECT().GetBoard("dest-board").Return(dest, nil)
+		th.Store.EXPECT().GetSubTree2("source-board", "card-id", gomock.Any()).Return([]*model.Block{&cardCopy, &imageCopy}, nil)
+		th.Store.EXPECT().GetMembersForBoard(gomock.Any()).Return([]*model.BoardMember{}, nil).AnyTimes()
+		th.Store.EXPECT().GetFileInfo("fileID").Return(nil, model.NewErrNotFound("file info ID=fileID"))
+		th.Store.EXPECT().MoveBlocks(gomock.Any(), "source-board", "user-id").Return(errDummy)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("MoveFile", sourcePath, destPath).Return(nil)
+		mockedFileBackend.On("MoveFile", destPath, sourcePath).Return(nil)
+
+		_, err := th.App.MoveCard("card-id", model.CardMoveOptions{DestinationBoardID: "dest-board"}, "user-id")
+		require.ErrorIs(t, err, errDummy)
+		mockedFileBackend.AssertExpectations(t)
+	})
+}
@@ -37,12 +37,13 @@ func (a *App) CreateCard(card *model.Card, boardID string, userID string, disabl
 	return newCard, nil
 }
 
-func (a *App) GetCardsForBoard(boardID string, page int, perPage int) ([]*model.Card, error) {
+func (a *App) GetCardsForBoard(boardID string, page int, perPage int, includeArchived bool) ([]*model.Card, error) {
 	opts := model.QueryBlocksOptions{
-		BoardID:   boardID,
-		BlockType: model.TypeCard,
-		Page:      page,
-		PerPage:   perPage,
+		BoardID:         boardID,
+		BlockType:       model.TypeCard,
+		Page:            page,
+		PerPage:         perPage,
+		ExcludeArchived: !includeArchived,
 	}
 
 	blocks, err := a.store.GetBlocks(opts)
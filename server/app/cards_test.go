@@ -84,26 +84,28 @@ func TestGetCards(t *testing.T) {
 
 	t.Run("success scenario", func(t *testing.T) {
 		opts := model.QueryBlocksOptions{
-			BoardID:   board.ID,
-			BlockType: model.TypeCard,
+			BoardID:         board.ID,
+			BlockType:       model.TypeCard,
+			ExcludeArchived: true,
 		}
 
 		th.Store.EXPECT().GetBlocks(opts).Return(blocks, nil)
 
-		cards, err := th.App.GetCardsForBoard(board.ID, 0, 0)
+		cards, err := th.App.GetCardsForBoard(board.ID, 0, 0, false)
 		require.NoError(t, err)
 		assert.Len(t, cards, cardCount)
 	})
 
 	t.Run("error scenario", func(t *testing.T) {
 		opts := model.QueryBlocksOptions{
-			BoardID:   board.ID,
-			BlockType: model.TypeCard,
+			BoardID:         board.ID,
+			BlockType:       model.TypeCard,
+			ExcludeArchived: true,
 		}
 
 		th.Store.EXPECT().GetBlocks(opts).Return(nil, blockError{"error"})
 
-		cards, err := th.App.GetCardsForBoard(board.ID, 0, 0)
+		cards, err := th.App.GetCardsForBoard(board.ID, 0, 0, false)
 		require.Error(t, err)
 		require.Nil(t, cards)
 	})
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// getComment returns a comment of a board, or a not found error if the block
+// doesn't exist, is not a comment or belongs to another board.
+func (a *App) getComment(boardID, commentID string) (*model.Block, error) {
+	comment, err := a.store.GetBlock(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Type != model.TypeComment || comment.BoardID != boardID {
+		return nil, model.NewErrNotFound("comment ID=" + commentID + " on BoardID=" + boardID)
+	}
+	return comment, nil
+}
+
+// validateCommentReplies checks that the comments of a set of blocks that reply to another
+// comment reference an existing top level comment of the same card. The replied comment
+// can be part of the same set.
+func (a *App) validateCommentReplies(blocks []*model.Block) error {
+	var byID map[string]*model.Block
+	for _, block := range blocks {
+		parentCommentID := model.GetCommentParentID(block)
+		if parentCommentID == "" {
+			continue
+		}
+
+		if byID == nil {
+			byID = make(map[string]*model.Block, len(blocks))
+			for _, b := range blocks {
+				byID[b.ID] = b
+			}
+		}
+
+		parent, ok := byID[parentCommentID]
+		if !ok {
+			var err error
+			parent, err = a.store.GetBlock(parentCommentID)
+			if model.IsErrNotFound(err) {
+				return model.NewErrBadRequest(fmt.Sprintf("comment %s replies to a comment that doesn't exist", block.ID))
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if parent.Type != model.TypeComment || parent.BoardID != block.BoardID || parent.ParentID != block.ParentID {
+			return model.NewErrBadRequest(fmt.Sprintf("comment %s must reply to a comment of the same card", block.ID))
+		}
+		if model.GetCommentParentID(parent) != "" {
+			return model.NewErrBadRequest(fmt.Sprintf("comment %s cannot reply to a reply", block.ID))
+		}
+	}
+	return nil
+}
+
+// prepareCommentPatch returns the patch to apply to a comment. The comment a reply belongs
+// to can't be changed, and patches that change the text of a comment mark it as edited.
+func prepareCommentPatch(comment *model.Block, patch *model.BlockPatch) (*model.BlockPatch, error) {
+	if comment.Type != model.TypeComment {
+		return patch, nil
+	}
+
+	if _, ok := patch.UpdatedFields[model.CommentParentIDField]; ok {
+		return nil, model.NewErrBadRequest("the comment a reply belongs to cannot be changed")
+	}
+	for _, field := range patch.DeletedFields {
+		if field == model.CommentParentIDField {
+			return nil, model.NewErrBadRequest("the comment a reply belongs to cannot be changed")
+		}
+	}
+
+	if patch.Title == nil || *patch.Title == comment.Title {
+		return patch, nil
+	}
+
+	commentPatch := *patch
+	commentPatch.UpdatedFields = make(map[string]interface{}, len(patch.UpdatedFields)+1)
+	for key, value := range patch.UpdatedFields {
+		commentPatch.UpdatedFields[key] = value
+	}
+	commentPatch.UpdatedFields[model.CommentEditedAtField] = utils.GetMillis()
+	return &commentPatch, nil
+}
+
+// EditComment changes the text of a comment. Only the author of a comment can edit it.
+func (a *App) EditComment(boardID, commentID, title, userID string) (*model.Block, error) {
+	comment, err := a.getComment(boardID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.CreatedBy != userID {
+		return nil, model.NewErrPermission("only the author of a comment can edit it")
+	}
+
+	return a.PatchBlock(commentID, &model.BlockPatch{Title: &title}, userID)
+}
+
+// GetCommentRevisions returns the previous versions of the text of a comment, newest first.
+func (a *App) GetCommentRevisions(boardID, commentID string) ([]*model.Block, error) {
+	if _, err := a.getComment(boardID, commentID); err != nil {
+		return nil, err
+	}
+
+	history, err := a.store.GetBlockHistory(commentID, model.QueryBlockHistoryOptions{Descending: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// only the revisions that changed the text are kept
+	revisions := []*model.Block{}
+	for i, revision := range history {
+		if revision.DeleteAt != 0 {
+			continue
+		}
+		if i+1 < len(history) && history[i+1].DeleteAt == 0 && history[i+1].Title == revision.Title {
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// AddCommentReaction adds the emoji reaction of a user to a comment. Adding a reaction
+// that already exists is not an error.
+func (a *App) AddCommentReaction(boardID, commentID, userID, emoji string) (*model.CommentReaction, error) {
+	if !model.IsValidEmojiName(emoji) {
+		return nil, model.NewErrBadRequest("invalid emoji name: " + emoji)
+	}
+
+	comment, err := a.getComment(boardID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := a.store.GetBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	reaction, err := a.store.AddCommentReaction(&model.CommentReaction{
+		CommentID: comment.ID,
+		BoardID:   comment.BoardID,
+		UserID:    userID,
+		Emoji:     emoji,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.blockChangeNotifier.Enqueue(func() error {
+		a.wsAdapter.BroadcastCommentReactionChange(board.TeamID, reaction, false)
+		return nil
+	})
+	return reaction, nil
+}
+
+// RemoveCommentReaction removes the emoji reaction of a user from a comment.
+func (a *App) RemoveCommentReaction(boardID, commentID, userID, emoji string) error {
+	comment, err := a.getComment(boardID, commentID)
+	if err != nil {
+		return err
+	}
+
+	board, err := a.store.GetBoard(boardID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.store.DeleteCommentReaction(comment.ID, userID, emoji); err != nil {
+		return err
+	}
+
+	reaction := &model.CommentReaction{
+		CommentID: comment.ID,
+		BoardID:   comment.BoardID,
+		UserID:    userID,
+		Emoji:     emoji,
+	}
+	a.blockChangeNotifier.Enqueue(func() error {
+		a.wsAdapter.BroadcastCommentReactionChange(board.TeamID, reaction, true)
+		return nil
+	})
+	return nil
+}
+
+// GetCommentReactions returns the reactions to a comment.
+func (a *App) GetCommentReactions(boardID, commentID string) ([]*model.CommentReaction, error) {
+	if _, err := a.getComment(boardID, commentID); err != nil {
+		return nil, err
+	}
+	return a.store.GetCommentReactions([]string{commentID})
+}
+
+// GetCardCommentReactions returns the reactions to all the comments of a card.
+func (a *App) GetCardCommentReactions(boardID, cardID string) ([]*model.CommentReaction, error) {
+	comments, err := a.store.GetBlocksWithParentAndType(boardID, cardID, string(model.TypeComment))
+	if err != nil {
+		return nil, err
+	}
+
+	commentIDs := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		commentIDs = append(commentIDs, comment.ID)
+	}
+	return a.store.GetCommentReactions(commentIDs)
+}
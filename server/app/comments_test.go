@@ -0,0 +1,202 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func makeComment(id, cardID, parentCommentID string) *model.Block {
+	comment := &model.Block{
+		ID:        id,
+		BoardID:   "board-id",
+		ParentID:  cardID,
+		Type:      model.TypeComment,
+		Title:     "Comment",
+		CreatedBy: "author-id",
+		Fields:    map[string]interface{}{},
+	}
+	if parentCommentID != "" {
+		comment.Fields[model.CommentParentIDField] = parentCommentID
+	}
+	return comment
+}
+
+func TestValidateCommentReplies(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("comments that are not replies are not checked", func(t *testing.T) {
+		require.NoError(t, th.App.validateCommentReplies([]*model.Block{makeComment("comment-id", "card-id", "")}))
+	})
+
+	t.Run("reply to a comment of the same card", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("parent-id").Return(makeComment("parent-id", "card-id", ""), nil)
+		require.NoError(t, th.App.validateCommentReplies([]*model.Block{makeComment("reply-id", "card-id", "parent-id")}))
+	})
+
+	t.Run("reply to a comment of the same batch", func(t *testing.T) {
+		blocks := []*model.Block{
+			makeComment("parent-id", "card-id", ""),
+			makeComment("reply-id", "card-id", "parent-id"),
+		}
+		require.NoError(t, th.App.validateCommentReplies(blocks))
+	})
+
+	t.Run("reply to a comment of another card", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("parent-id").Return(makeComment("parent-id", "other-card-id", ""), nil)
+		err := th.App.validateCommentReplies([]*model.Block{makeComment("reply-id", "card-id", "parent-id")})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("reply to a reply", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("parent-id").Return(makeComment("parent-id", "card-id", "root-id"), nil)
+		err := th.App.validateCommentReplies([]*model.Block{makeComment("reply-id", "card-id", "parent-id")})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("reply to a missing comment", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("parent-id").Return(nil, model.NewErrNotFound("parent-id"))
+		err := th.App.validateCommentReplies([]*model.Block{makeComment("reply-id", "card-id", "parent-id")})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+}
+
+func TestPrepareCommentPatch(t *testing.T) {
+	title := "Edited"
+
+	t.Run("changing the text marks the comment as edited", func(t *testing.T) {
+		patch := &model.BlockPatch{Title: &title, UpdatedFields: map[string]interface{}{"other": "value"}}
+		commentPatch, err := prepareCommentPatch(makeComment("comment-id", "card-id", ""), patch)
+		require.NoError(t, err)
+		require.NotZero(t, commentPatch.UpdatedFields[model.CommentEditedAtField])
+		require.Equal(t, "value", commentPatch.UpdatedFields["other"])
+		require.NotContains(t, patch.UpdatedFields, model.CommentEditedAtField)
+	})
+
+	t.Run("patches that don't change the text are kept", func(t *testing.T) {
+		unchanged := "Comment"
+		patch := &model.BlockPatch{Title: &unchanged}
+		commentPatch, err := prepareCommentPatch(makeComment("comment-id", "card-id", ""), patch)
+		require.NoError(t, err)
+		require.Same(t, patch, commentPatch)
+	})
+
+	t.Run("the replied comment cannot be changed", func(t *testing.T) {
+		comment := makeComment("reply-id", "card-id", "parent-id")
+		_, err := prepareCommentPatch(comment, &model.BlockPatch{
+			UpdatedFields: map[string]interface{}{model.CommentParentIDField: "other-id"},
+		})
+		require.True(t, model.IsErrBadRequest(err))
+
+		_, err = prepareCommentPatch(comment, &model.BlockPatch{DeletedFields: []string{model.CommentParentIDField}})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("other blocks are not changed", func(t *testing.T) {
+		patch := &model.BlockPatch{Title: &title}
+		blockPatch, err := prepareCommentPatch(&model.Block{Type: model.TypeText, Title: "Text"}, patch)
+		require.NoError(t, err)
+		require.Same(t, patch, blockPatch)
+	})
+}
+
+func TestEditComment(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("only the author can edit a comment", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("comment-id").Return(makeComment("comment-id", "card-id", ""), nil)
+		_, err := th.App.EditComment("board-id", "comment-id", "Edited", "other-user-id")
+		require.True(t, model.IsErrForbidden(err))
+	})
+
+	t.Run("the comment must belong to the board", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("comment-id").Return(makeComment("comment-id", "card-id", ""), nil)
+		_, err := th.App.EditComment("other-board-id", "comment-id", "Edited", "author-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
+
+func TestGetCommentRevisions(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	revision := func(title string, updateAt, deleteAt int64) *model.Block {
+		comment := makeComment("comment-id", "card-id", "")
+		comment.Title = title
+		comment.UpdateAt = updateAt
+		comment.DeleteAt = deleteAt
+		return comment
+	}
+
+	th.Store.EXPECT().GetBlock("comment-id").Return(makeComment("comment-id", "card-id", ""), nil)
+	th.Store.EXPECT().GetBlockHistory("comment-id", model.QueryBlockHistoryOptions{Descending: true}).Return([]*model.Block{
+		revision("Third", 4000, 0),
+		revision("Second", 3000, 0),
+		revision("Second", 2000, 0),
+		revision("First", 1000, 0),
+	}, nil)
+
+	revisions, err := th.App.GetCommentRevisions("board-id", "comment-id")
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	require.Equal(t, "Third", revisions[0].Title)
+	require.Equal(t, int64(2000), revisions[1].UpdateAt)
+	require.Equal(t, "First", revisions[2].Title)
+}
+
+func TestCommentReactions(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	board := &model.Board{ID: "board-id", TeamID: "team-id"}
+	th.Store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{}, nil).AnyTimes()
+
+	t.Run("invalid emoji", func(t *testing.T) {
+		_, err := th.App.AddCommentReaction("board-id", "comment-id", "user-id", "Not An Emoji")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("add reaction", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("comment-id").Return(makeComment("comment-id", "card-id", ""), nil)
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().AddCommentReaction(&model.CommentReaction{
+			CommentID: "comment-id",
+			BoardID:   "board-id",
+			UserID:    "user-id",
+			Emoji:     "+1",
+		}).Return(&model.CommentReaction{CommentID: "comment-id", BoardID: "board-id", UserID: "user-id", Emoji: "+1", CreateAt: 1000}, nil)
+
+		reaction, err := th.App.AddCommentReaction("board-id", "comment-id", "user-id", "+1")
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), reaction.CreateAt)
+	})
+
+	t.Run("reactions only apply to comments", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("card-id").Return(&model.Block{ID: "card-id", BoardID: "board-id", Type: model.TypeCard}, nil)
+		_, err := th.App.AddCommentReaction("board-id", "card-id", "user-id", "smile")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("remove reaction", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock("comment-id").Return(makeComment("comment-id", "card-id", ""), nil)
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+		th.Store.EXPECT().DeleteCommentReaction("comment-id", "user-id", "+1").Return(nil)
+
+		require.NoError(t, th.App.RemoveCommentReaction("board-id", "comment-id", "user-id", "+1"))
+	})
+
+	t.Run("get the reactions of the comments of a card", func(t *testing.T) {
+		th.Store.EXPECT().GetBlocksWithParentAndType("board-id", "card-id", string(model.TypeComment)).Return([]*model.Block{
+			makeComment("comment-id-1", "card-id", ""),
+			makeComment("comment-id-2", "card-id", "comment-id-1"),
+		}, nil)
+		th.Store.EXPECT().GetCommentReactions(gomock.InAnyOrder([]string{"comment-id-1", "comment-id-2"})).Return([]*model.CommentReaction{}, nil)
+
+		_, err := th.App.GetCardCommentReactions("board-id", "card-id")
+		require.NoError(t, err)
+	})
+}
@@ -111,6 +111,16 @@ func (a *App) writeArchiveBoard(zw *zip.Writer, board model.Board, opt model.Exp
 		}
 	}
 
+	if opt.IncludeHistory {
+		if err = a.writeArchiveHistory(zw, board); err != nil {
+			return fmt.Errorf("cannot write history to archive: %w", err)
+		}
+	}
+
+	if opt.ExcludeFiles {
+		return nil
+	}
+
 	// write the files
 	for _, filename := range files {
 		if err := a.writeArchiveFile(zw, filename, board.ID, opt); err != nil {
@@ -120,6 +130,36 @@ func (a *App) writeArchiveBoard(zw *zip.Writer, board model.Board, opt model.Exp
 	return nil
 }
 
+// writeArchiveHistory writes the previous versions of a board and of its
+// blocks, oldest first, to a `history.jsonl` file in the board directory.
+func (a *App) writeArchiveHistory(zw *zip.Writer, board model.Board) error {
+	w, err := zw.Create(board.ID + "/history.jsonl")
+	if err != nil {
+		return err
+	}
+
+	boards, err := a.store.GetBoardHistory(board.ID, model.QueryBoardHistoryOptions{})
+	if err != nil {
+		return err
+	}
+	for _, b := range boards {
+		if err = a.writeArchiveBoardLine(w, *b); err != nil {
+			return err
+		}
+	}
+
+	blocks, err := a.store.GetBlockHistoryDescendants(board.ID, model.QueryBlockHistoryOptions{})
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		if err = a.writeArchiveBlockLine(w, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // writeArchiveBoardMemberLine writes a single boardMember to the archive.
 func (a *App) writeArchiveBoardMemberLine(w io.Writer, boardMember *model.BoardMember) error {
 	bm, err := json.Marshal(&boardMember)
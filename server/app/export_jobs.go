@@ -0,0 +1,321 @@
+package app
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// exportJobsRoot is the directory of the files storage holding the
+	// archives of the exports, out of the reach of the file garbage
+	// collection.
+	exportJobsRoot = "exports"
+
+	exportArchiveExtension = ".boardarchive"
+
+	// exportJobStaleAfter is the time after which a pending or running
+	// export that was not updated is resumed, like the exports of a server
+	// that stopped. The exports queued or running on a server are updated
+	// by each export cleanup, which runs more often.
+	exportJobStaleAfter = time.Hour
+
+	// exportJobMaxAge is the time after which a stale export is failed
+	// instead of resumed.
+	exportJobMaxAge = 24 * time.Hour
+
+	defaultExportRetention = 72 * time.Hour
+)
+
+// CreateExportJob records an export of boards of a team to an archive,
+// and runs it in the background.
+func (a *App) CreateExportJob(teamID, userID string, boardIDs []string, opts model.ExportJobOptions) (*model.ExportJob, error) {
+	now := utils.GetMillis()
+	id := utils.NewID(utils.IDTypeNone)
+	job := &model.ExportJob{
+		ID:             id,
+		TeamID:         teamID,
+		CreatedBy:      userID,
+		Status:         model.ExportJobStatusPending,
+		BoardIDs:       boardIDs,
+		ExcludeFiles:   opts.ExcludeFiles,
+		ExcludeHistory: opts.ExcludeHistory,
+		BoardCount:     len(boardIDs),
+		Path:           path.Join(exportJobsRoot, teamID, id+exportArchiveExtension),
+		CreateAt:       now,
+		UpdateAt:       now,
+	}
+	if err := a.store.CreateExportJob(job); err != nil {
+		return nil, err
+	}
+
+	running := *job
+	a.enqueueExportJob(&running)
+	return job, nil
+}
+
+// enqueueExportJob runs an export in the background, keeping it from
+// becoming stale until it ends.
+func (a *App) enqueueExportJob(job *model.ExportJob) {
+	a.exportJobsMux.Lock()
+	a.activeExportJobs[job.ID] = true
+	a.exportJobsMux.Unlock()
+
+	a.exportQueue.Enqueue(func() error {
+		defer func() {
+			a.exportJobsMux.Lock()
+			delete(a.activeExportJobs, job.ID)
+			a.exportJobsMux.Unlock()
+		}()
+		return a.runExportJob(job)
+	})
+}
+
+// touchExportJobs updates the exports queued or running on this server,
+// so that other servers don't take them for stale.
+func (a *App) touchExportJobs(now int64) {
+	a.exportJobsMux.Lock()
+	ids := make([]string, 0, len(a.activeExportJobs))
+	for id := range a.activeExportJobs {
+		ids = append(ids, id)
+	}
+	a.exportJobsMux.Unlock()
+
+	if len(ids) == 0 {
+		return
+	}
+	if err := a.store.TouchExportJobs(ids, now); err != nil {
+		a.logger.Error("Cannot update active exports", mlog.Err(err))
+	}
+}
+
+// GetExportJob returns an export of a team requested by a user.
+func (a *App) GetExportJob(teamID, id, userID string) (*model.ExportJob, error) {
+	job, err := a.store.GetExportJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.TeamID != teamID || job.CreatedBy != userID {
+		return nil, model.NewErrNotFound("export job ID=" + id)
+	}
+	return job, nil
+}
+
+// GetExportJobsForUser returns the exports of a team requested by a user,
+// the most recent first.
+func (a *App) GetExportJobsForUser(teamID, userID string) ([]*model.ExportJob, error) {
+	return a.store.GetExportJobsForUser(teamID, userID)
+}
+
+// GetExportArchive returns an export of a team requested by a user along
+// with a reader of its archive, once the export is done.
+func (a *App) GetExportArchive(teamID, id, userID string) (*model.ExportJob, ReadCloseSeeker, error) {
+	job, err := a.GetExportJob(teamID, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != model.ExportJobStatusDone {
+		return nil, nil, model.NewErrBadRequest(fmt.Sprintf("export %s is %s", id, job.Status))
+	}
+
+	reader, err := a.filesBackend.Reader(job.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, reader, nil
+}
+
+// DeleteExportJob deletes a finished export of a team requested by a
+// user, along with its archive.
+func (a *App) DeleteExportJob(teamID, id, userID string) error {
+	job, err := a.GetExportJob(teamID, id, userID)
+	if err != nil {
+		return err
+	}
+	if !job.IsFinished() {
+		return model.NewErrBadRequest(fmt.Sprintf("export %s is %s", id, job.Status))
+	}
+
+	if err := a.removeExportArchive(job); err != nil {
+		return err
+	}
+	return a.store.DeleteExportJob(id)
+}
+
+// RunExportCleanup resumes the exports of servers that stopped, and
+// deletes the exports whose retention period is over. Exports that stay
+// stale for too long are failed instead.
+func (a *App) RunExportCleanup() {
+	now := utils.GetMillis()
+	a.touchExportJobs(now)
+
+	stale, err := a.store.GetStaleExportJobs(now - exportJobStaleAfter.Milliseconds())
+	if err != nil {
+		a.logger.Error("Cannot fetch stale exports", mlog.Err(err))
+		return
+	}
+	resumed := 0
+	for _, job := range stale {
+		if job.CreateAt < now-exportJobMaxAge.Milliseconds() {
+			a.finishExportJob(job, 0, errors.New("export interrupted"))
+			continue
+		}
+
+		requeued, err := a.store.RequeueExportJob(job, now)
+		if err != nil {
+			a.logger.Error("Cannot resume stale export", mlog.String("job_id", job.ID), mlog.Err(err))
+			continue
+		}
+		if !requeued {
+			// another server resumed it first
+			continue
+		}
+		job.Status = model.ExportJobStatusPending
+		job.ExportedBoards = 0
+		job.UpdateAt = now
+		a.enqueueExportJob(job)
+		resumed++
+	}
+
+	expired, err := a.store.GetExpiredExportJobs(now)
+	if err != nil {
+		a.logger.Error("Cannot fetch expired exports", mlog.Err(err))
+		return
+	}
+	deleted := 0
+	for _, job := range expired {
+		if err := a.removeExportArchive(job); err != nil {
+			a.logger.Error("Cannot remove archive of expired export", mlog.String("job_id", job.ID), mlog.Err(err))
+			continue
+		}
+		if err := a.store.DeleteExportJob(job.ID); err != nil && !model.IsErrNotFound(err) {
+			a.logger.Error("Cannot delete expired export", mlog.String("job_id", job.ID), mlog.Err(err))
+			continue
+		}
+		deleted++
+	}
+
+	if len(stale) > 0 || deleted > 0 {
+		a.logger.Info("Export cleanup done",
+			mlog.Int("resumed", resumed),
+			mlog.Int("interrupted", len(stale)-resumed),
+			mlog.Int("deleted", deleted),
+		)
+	}
+}
+
+// runExportJob streams the archive of an export to the files storage,
+// unless it is no longer pending, like an export failed or resumed by
+// another server while it was queued. The archive of a failed export is
+// removed, so only complete archives can be downloaded.
+func (a *App) runExportJob(job *model.ExportJob) error {
+	now := utils.GetMillis()
+	claimed, err := a.store.ClaimExportJob(job.ID, now)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		a.logger.Debug("Export no longer pending", mlog.String("job_id", job.ID))
+		return nil
+	}
+	job.Status = model.ExportJobStatusRunning
+	job.UpdateAt = now
+
+	pr, pw := io.Pipe()
+	writeErr := make(chan error, 1)
+	go func() {
+		err := a.writeExportArchive(pw, job)
+		pw.CloseWithError(err)
+		writeErr <- err
+	}()
+
+	size, err := a.filesBackend.WriteFile(pr, job.Path)
+	// unblock the writer if the storage stopped reading
+	pr.Close()
+	if errWrite := <-writeErr; errWrite != nil && !errors.Is(errWrite, io.ErrClosedPipe) {
+		err = errWrite
+	}
+
+	a.finishExportJob(job, size, err)
+	return err
+}
+
+// writeExportArchive writes the boards of an export to an archive, saving
+// the progress of the export after each board. Boards deleted since the
+// export was requested are skipped.
+func (a *App) writeExportArchive(w io.Writer, job *model.ExportJob) error {
+	opt := model.ExportArchiveOptions{
+		TeamID:         job.TeamID,
+		BoardIDs:       job.BoardIDs,
+		ExcludeFiles:   job.ExcludeFiles,
+		IncludeHistory: !job.ExcludeHistory,
+	}
+
+	zw := zip.NewWriter(w)
+	if err := a.writeArchiveVersion(zw); err != nil {
+		return err
+	}
+
+	for _, boardID := range job.BoardIDs {
+		board, err := a.GetBoard(boardID)
+		if err != nil && !model.IsErrNotFound(err) {
+			return fmt.Errorf("could not fetch board %s: %w", boardID, err)
+		}
+		if board != nil {
+			if err := a.writeArchiveBoard(zw, *board, opt); err != nil {
+				return fmt.Errorf("cannot export board %s: %w", boardID, err)
+			}
+		}
+
+		job.ExportedBoards++
+		a.saveExportJob(job)
+	}
+	return zw.Close()
+}
+
+// finishExportJob records the outcome of an export, which expires after
+// the retention period.
+func (a *App) finishExportJob(job *model.ExportJob, size int64, err error) {
+	retention := time.Duration(a.config.ExportRetentionHours) * time.Hour
+	if retention <= 0 {
+		retention = defaultExportRetention
+	}
+	job.ExpireAt = utils.GetMillis() + retention.Milliseconds()
+
+	if err != nil {
+		a.logger.Error("Export failed", mlog.String("job_id", job.ID), mlog.String("team_id", job.TeamID), mlog.Err(err))
+		job.Status = model.ExportJobStatusFailed
+		job.Error = err.Error()
+		if errRemove := a.removeExportArchive(job); errRemove != nil {
+			a.logger.Error("Cannot remove archive of failed export", mlog.String("job_id", job.ID), mlog.Err(errRemove))
+		}
+	} else {
+		a.logger.Debug("Export done", mlog.String("job_id", job.ID), mlog.Int("boards", job.ExportedBoards), mlog.Int("size", size))
+		job.Status = model.ExportJobStatusDone
+		job.Size = size
+	}
+	a.saveExportJob(job)
+}
+
+func (a *App) saveExportJob(job *model.ExportJob) {
+	job.UpdateAt = utils.GetMillis()
+	if err := a.store.UpdateExportJob(job); err != nil {
+		a.logger.Error("Cannot save export", mlog.String("job_id", job.ID), mlog.Err(err))
+	}
+}
+
+func (a *App) removeExportArchive(job *model.ExportJob) error {
+	exists, err := a.filesBackend.FileExists(job.Path)
+	if err != nil || !exists {
+		return err
+	}
+	return a.filesBackend.RemoveFile(job.Path)
+}
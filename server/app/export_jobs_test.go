@@ -0,0 +1,238 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore/mocks"
+)
+
+func TestExportJobAccess(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	job := &model.ExportJob{
+		ID:        "job-id",
+		TeamID:    "team-id",
+		CreatedBy: "user-id",
+		Status:    model.ExportJobStatusRunning,
+		Path:      "exports/team-id/job-id.boardarchive",
+	}
+
+	t.Run("only the creator can see a job", func(t *testing.T) {
+		th.Store.EXPECT().GetExportJob("job-id").Return(job, nil).Times(3)
+
+		retrieved, err := th.App.GetExportJob("team-id", "job-id", "user-id")
+		require.NoError(t, err)
+		require.Equal(t, job, retrieved)
+
+		_, err = th.App.GetExportJob("team-id", "job-id", "other-user-id")
+		require.True(t, model.IsErrNotFound(err))
+
+		_, err = th.App.GetExportJob("other-team-id", "job-id", "user-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("a running job cannot be downloaded nor deleted", func(t *testing.T) {
+		th.Store.EXPECT().GetExportJob("job-id").Return(job, nil).Times(2)
+
+		_, _, err := th.App.GetExportArchive("team-id", "job-id", "user-id")
+		require.True(t, model.IsErrBadRequest(err))
+
+		err = th.App.DeleteExportJob("team-id", "job-id", "user-id")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("deleting a finished job removes its archive", func(t *testing.T) {
+		done := *job
+		done.Status = model.ExportJobStatusDone
+		th.Store.EXPECT().GetExportJob("job-id").Return(&done, nil)
+		th.Store.EXPECT().DeleteExportJob("job-id").Return(nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", done.Path).Return(true, nil)
+		mockedFileBackend.On("RemoveFile", done.Path).Return(nil)
+
+		require.NoError(t, th.App.DeleteExportJob("team-id", "job-id", "user-id"))
+		mockedFileBackend.AssertCalled(t, "RemoveFile", done.Path)
+	})
+}
+
+func TestRunExportCleanup(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	stale := &model.ExportJob{ID: "stale-id", Status: model.ExportJobStatusRunning, Path: "exports/team-id/stale-id.boardarchive"}
+	expired := &model.ExportJob{ID: "expired-id", Status: model.ExportJobStatusDone, Path: "exports/team-id/expired-id.boardarchive"}
+
+	th.Store.EXPECT().GetStaleExportJobs(gomock.Any()).Return([]*model.ExportJob{stale}, nil)
+	th.Store.EXPECT().GetExpiredExportJobs(gomock.Any()).Return([]*model.ExportJob{expired}, nil)
+	th.Store.EXPECT().UpdateExportJob(gomock.Any()).DoAndReturn(func(job *model.ExportJob) error {
+		require.Equal(t, "stale-id", job.ID)
+		require.Equal(t, model.ExportJobStatusFailed, job.Status)
+		require.Equal(t, "export interrupted", job.Error)
+		require.NotZero(t, job.ExpireAt)
+		return nil
+	})
+	th.Store.EXPECT().DeleteExportJob("expired-id").Return(nil)
+
+	mockedFileBackend := &mocks.FileBackend{}
+	th.App.filesBackend = mockedFileBackend
+	mockedFileBackend.On("FileExists", stale.Path).Return(false, nil)
+	mockedFileBackend.On("FileExists", expired.Path).Return(true, nil)
+	mockedFileBackend.On("RemoveFile", expired.Path).Return(nil)
+
+	th.App.RunExportCleanup()
+	mockedFileBackend.AssertCalled(t, "RemoveFile", expired.Path)
+	mockedFileBackend.AssertNotCalled(t, "RemoveFile", stale.Path)
+}
+
+func TestResumeStaleExportJobs(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	stale := &model.ExportJob{
+		ID:       "stale-id",
+		Status:   model.ExportJobStatusRunning,
+		Path:     "exports/team-id/stale-id.boardarchive",
+		CreateAt: utils.GetMillis(),
+		UpdateAt: 1,
+	}
+	resumed := &model.ExportJob{ID: "resumed-id", Status: model.ExportJobStatusPending, CreateAt: utils.GetMillis(), UpdateAt: 1}
+
+	claimed := make(chan string, 1)
+	th.Store.EXPECT().GetStaleExportJobs(gomock.Any()).Return([]*model.ExportJob{stale, resumed}, nil)
+	th.Store.EXPECT().RequeueExportJob(stale, gomock.Any()).Return(true, nil)
+	th.Store.EXPECT().RequeueExportJob(resumed, gomock.Any()).Return(false, nil)
+	th.Store.EXPECT().GetExpiredExportJobs(gomock.Any()).Return([]*model.ExportJob{}, nil)
+	th.Store.EXPECT().ClaimExportJob("stale-id", gomock.Any()).DoAndReturn(func(id string, updateAt int64) (bool, error) {
+		claimed <- id
+		// another server ran it meanwhile.
+		return false, nil
+	})
+
+	th.App.RunExportCleanup()
+
+	select {
+	case id := <-claimed:
+		require.Equal(t, "stale-id", id)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the stale export was not resumed")
+	}
+	require.Equal(t, model.ExportJobStatusPending, stale.Status)
+}
+
+func TestRunExportJobNotPending(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	job := &model.ExportJob{ID: "job-id", Status: model.ExportJobStatusPending, Path: "exports/team-id/job-id.boardarchive"}
+	th.Store.EXPECT().ClaimExportJob("job-id", gomock.Any()).Return(false, nil)
+
+	mockedFileBackend := &mocks.FileBackend{}
+	th.App.filesBackend = mockedFileBackend
+
+	require.NoError(t, th.App.runExportJob(job))
+	mockedFileBackend.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
+}
+
+func TestTouchExportJobs(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.touchExportJobs(1)
+
+	th.App.activeExportJobs["job-id"] = true
+	defer delete(th.App.activeExportJobs, "job-id")
+	th.Store.EXPECT().TouchExportJobs([]string{"job-id"}, int64(2)).Return(nil)
+	th.App.touchExportJobs(2)
+}
+
+func TestWriteExportArchive(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	board := &model.Board{ID: "board-id", TeamID: "team-id", Title: "Board"}
+	blocks := []*model.Block{
+		{ID: "card-id", BoardID: "board-id", Type: model.TypeCard},
+		{ID: "image-id", BoardID: "board-id", Type: model.TypeImage, Fields: map[string]interface{}{"fileId": "7image.png"}},
+	}
+
+	setup := func(job *model.ExportJob) {
+		th.Store.EXPECT().GetBoard("board-id").Return(board, nil).AnyTimes()
+		th.Store.EXPECT().GetBoard("deleted-board-id").Return(nil, model.NewErrNotFound("board ID=deleted-board-id"))
+		th.Store.EXPECT().GetBlocksForBoard("board-id").Return(blocks, nil)
+		th.Store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{}, nil)
+		th.Store.EXPECT().UpdateExportJob(job).Return(nil).Times(2)
+	}
+
+	archiveFiles := func(t *testing.T, buf *bytes.Buffer) []string {
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		names := []string{}
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	t.Run("history is included and deleted boards are skipped", func(t *testing.T) {
+		job := &model.ExportJob{
+			ID:           "job-id",
+			TeamID:       "team-id",
+			BoardIDs:     []string{"board-id", "deleted-board-id"},
+			BoardCount:   2,
+			ExcludeFiles: true,
+		}
+		setup(job)
+		th.Store.EXPECT().GetBoardHistory("board-id", gomock.Any()).Return([]*model.Board{board}, nil)
+		th.Store.EXPECT().GetBlockHistoryDescendants("board-id", gomock.Any()).Return(blocks, nil)
+
+		var buf bytes.Buffer
+		require.NoError(t, th.App.writeExportArchive(&buf, job))
+		require.Equal(t, 2, job.ExportedBoards)
+		require.Equal(t, []string{"version.json", "board-id/board.jsonl", "board-id/history.jsonl"}, archiveFiles(t, &buf))
+	})
+
+	t.Run("files are included and history excluded", func(t *testing.T) {
+		job := &model.ExportJob{
+			ID:             "job-id",
+			TeamID:         "team-id",
+			BoardIDs:       []string{"board-id", "deleted-board-id"},
+			BoardCount:     2,
+			ExcludeHistory: true,
+		}
+		setup(job)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", mock.Anything).Return(true, nil)
+		mockedFileBackend.On("Reader", mock.Anything).Return(newReadCloseSeeker("image"), nil)
+		th.Store.EXPECT().GetFileInfo("image").Return(nil, model.NewErrNotFound("file info ID=image")).AnyTimes()
+
+		var buf bytes.Buffer
+		require.NoError(t, th.App.writeExportArchive(&buf, job))
+		require.Equal(t, 2, job.ExportedBoards)
+		require.Equal(t, []string{"version.json", "board-id/board.jsonl", "board-id/7image.png"}, archiveFiles(t, &buf))
+	})
+
+	t.Run("a store error fails the export", func(t *testing.T) {
+		job := &model.ExportJob{ID: "job-id", TeamID: "team-id", BoardIDs: []string{"failing-board-id"}}
+		th.Store.EXPECT().GetBoard("failing-board-id").Return(nil, errors.New("store error"))
+
+		var buf bytes.Buffer
+		require.Error(t, th.App.writeExportArchive(&buf, job))
+		require.Zero(t, job.ExportedBoards)
+	})
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	fileDedupDoneKey    = "file_dedup_done"
+	fileDedupLastRunKey = "file_dedup_last_run"
+
+	// fileDedupMinInterval is the minimum time between two scheduled
+	// runs, so a cluster of servers moves the files once.
+	fileDedupMinInterval = 23 * time.Hour
+
+	fileDedupBatchSize = 100
+)
+
+// RunFileDeduplication moves the files uploaded before the content of
+// uploads was deduplicated to the blobs of their content, unless it was
+// done already or another server of the cluster is doing it.
+//
+// This is the data migration of the deduplication. It runs as a
+// scheduled job rather than as a database migration because it reads
+// and copies the files of the files storage, which migrations can't
+// reach, and because it can take long on large installations. Files
+// that can't be moved are served from where they are and tried again
+// on the next run, while the missing and quarantined ones are skipped.
+// Once every other file is moved, the job is marked done and never
+// runs again, as uploads are always stored in blobs since.
+func (a *App) RunFileDeduplication() {
+	done, err := a.store.GetSystemSetting(fileDedupDoneKey)
+	if err != nil {
+		a.logger.Error("Cannot fetch file deduplication status", mlog.Err(err))
+		return
+	}
+	if done == "true" {
+		return
+	}
+	if !a.claimScheduledRun(fileDedupLastRunKey, fileDedupMinInterval) {
+		return
+	}
+
+	moved, skipped, failed, err := a.DeduplicateFiles()
+	if err != nil {
+		a.logger.Error("File deduplication failed", mlog.Err(err))
+		return
+	}
+	a.logger.Info("File deduplication done",
+		mlog.Int("moved", moved),
+		mlog.Int("skipped", skipped),
+		mlog.Int("failed", failed),
+	)
+
+	// files that couldn't be moved are served from where they are, and
+	// are tried again on the next run
+	if failed > 0 {
+		return
+	}
+	if err := a.store.SetSystemSetting(fileDedupDoneKey, "true"); err != nil {
+		a.logger.Error("Cannot save file deduplication status", mlog.Err(err))
+	}
+}
+
+// DeduplicateFiles moves the files uploaded before the content of
+// uploads was deduplicated to the blobs of their content, removing
+// their duplicates. It returns the number of files moved, of files
+// skipped because they are missing or quarantined, and of files that
+// couldn't be moved.
+func (a *App) DeduplicateFiles() (int, int, int, error) {
+	moved, skipped, failed := 0, 0, 0
+	afterCreateAt, afterID := int64(0), ""
+	for {
+		fileInfos, err := a.store.GetLegacyFileInfos(afterCreateAt, afterID, fileDedupBatchSize)
+		if err != nil {
+			return moved, skipped, failed, err
+		}
+		if len(fileInfos) == 0 {
+			return moved, skipped, failed, nil
+		}
+
+		for _, fileInfo := range fileInfos {
+			skip, err := a.skipLegacyFile(fileInfo)
+			if err != nil {
+				a.logger.Warn("File deduplication cannot check file", mlog.String("path", fileInfo.Path), mlog.Err(err))
+				failed++
+				continue
+			}
+			if skip {
+				skipped++
+				continue
+			}
+
+			if err := a.moveFileToBlob(fileInfo); err != nil {
+				a.logger.Warn("File deduplication cannot move file", mlog.String("path", fileInfo.Path), mlog.Err(err))
+				failed++
+				continue
+			}
+			moved++
+		}
+
+		last := fileInfos[len(fileInfos)-1]
+		afterCreateAt, afterID = last.CreateAt, last.Id
+	}
+}
+
+// skipLegacyFile checks if a file can't be moved because it was
+// quarantined by the upload scanner, which moved it out of its path, or
+// because it is missing from the files storage. These files would fail
+// on every run, so they are left as they are.
+func (a *App) skipLegacyFile(fileInfo *mm_model.FileInfo) (bool, error) {
+	scanResult, err := a.store.GetFileScanResult(fileInfo.Id)
+	if err != nil && !model.IsErrNotFound(err) {
+		return false, err
+	}
+	if err == nil && scanResult.Status == model.FileScanStatusInfected {
+		a.logger.Info("File deduplication skips quarantined file", mlog.String("id", fileInfo.Id))
+		return true, nil
+	}
+
+	exists, err := a.filesBackend.FileExists(fileInfo.Path)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		a.logger.Warn("File deduplication skips missing file", mlog.String("path", fileInfo.Path))
+		return true, nil
+	}
+	return false, nil
+}
+
+// moveFileToBlob moves a stored file and its renditions to the blob of
+// its content. The file is copied before its file info is updated, so
+// it is never lost if the update fails, and copied again if the blob
+// was removed before the update referenced it.
+func (a *App) moveFileToBlob(fileInfo *mm_model.FileInfo) error {
+	reader, err := a.filesBackend.Reader(fileInfo.Path)
+	if err != nil {
+		return err
+	}
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+
+	blobInfo := *fileInfo
+	blobInfo.Path = model.FileBlobPath(hex.EncodeToString(hasher.Sum(nil)))
+	if fileInfo.HasPreviewImage {
+		blobInfo.ThumbnailPath = renditionPath(blobInfo.Path, model.FileRenditionThumb)
+		blobInfo.PreviewPath = renditionPath(blobInfo.Path, model.FileRenditionPreview)
+	}
+
+	if err := a.copyToBlob(fileInfo, &blobInfo); err != nil {
+		return err
+	}
+	if err := a.store.UpdateFileInfoPath(&blobInfo); err != nil {
+		return err
+	}
+	// the blob is referenced now, so the file garbage collection keeps it
+	if err := a.copyToBlob(fileInfo, &blobInfo); err != nil {
+		if errRevert := a.store.UpdateFileInfoPath(fileInfo); errRevert != nil {
+			a.logger.Error("File deduplication cannot restore file info", mlog.String("id", fileInfo.Id), mlog.Err(errRevert))
+		}
+		return err
+	}
+
+	for _, p := range []string{fileInfo.Path, fileInfo.ThumbnailPath, fileInfo.PreviewPath} {
+		if p == "" {
+			continue
+		}
+		if err := a.filesBackend.RemoveFile(p); err != nil {
+			a.logger.Warn("File deduplication cannot remove duplicated file", mlog.String("path", p), mlog.Err(err))
+		}
+	}
+	return nil
+}
+
+// copyToBlob copies a stored file and its renditions to the paths of
+// the blob of its content, unless they are already there. Renditions
+// that are missing are served as the original file, so failing to copy
+// them is only logged.
+func (a *App) copyToBlob(fileInfo, blobInfo *mm_model.FileInfo) error {
+	if err := a.copyFileIfMissing(fileInfo.Path, blobInfo.Path); err != nil {
+		return err
+	}
+	if !fileInfo.HasPreviewImage {
+		return nil
+	}
+
+	renditions := [][2]string{
+		{fileInfo.ThumbnailPath, blobInfo.ThumbnailPath},
+		{fileInfo.PreviewPath, blobInfo.PreviewPath},
+	}
+	for _, r := range renditions {
+		if err := a.copyFileIfMissing(r[0], r[1]); err != nil {
+			a.logger.Warn("File deduplication cannot copy image rendition", mlog.String("path", r[0]), mlog.Err(err))
+		}
+	}
+	return nil
+}
+
+func (a *App) copyFileIfMissing(source, destination string) error {
+	exists, err := a.filesBackend.FileExists(destination)
+	if err != nil || exists {
+		return err
+	}
+	return a.filesBackend.CopyFile(source, destination)
+}
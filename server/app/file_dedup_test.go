@@ -0,0 +1,280 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore/mocks"
+)
+
+func contentBlobPath(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return model.FileBlobPath(hex.EncodeToString(hash[:]))
+}
+
+func TestSaveFileDeduplication(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.Store.EXPECT().GetStorageQuota(gomock.Any(), gomock.Any()).Return(nil, model.NewErrNotFound("storage quota")).AnyTimes()
+	th.Store.EXPECT().GetUploadPolicy("team-id").Return(nil, model.NewErrNotFound("upload policy")).AnyTimes()
+
+	content := "some content"
+	blobPath := contentBlobPath(content)
+
+	expectFileInfo := func(t *testing.T) {
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "team-id", "board-id").DoAndReturn(func(fileInfo *mm_model.FileInfo, _, _ string) error {
+			require.Equal(t, blobPath, fileInfo.Path)
+			require.Equal(t, int64(len(content)), fileInfo.Size)
+			return nil
+		})
+	}
+
+	t.Run("new content is stored in its blob", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", blobPath).Return(false, nil)
+		mockedFileBackend.On("WriteFile", mock.Anything, blobPath).Return(int64(len(content)), nil)
+		expectFileInfo(t)
+
+		_, err := th.App.SaveFile(strings.NewReader(content), "team-id", "board-id", "user-id", "file.txt", false)
+		require.NoError(t, err)
+		mockedFileBackend.AssertExpectations(t)
+	})
+
+	t.Run("stored content is not stored again", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", blobPath).Return(true, nil)
+		expectFileInfo(t)
+
+		_, err := th.App.SaveFile(strings.NewReader(content), "team-id", "board-id", "user-id", "copy.txt", false)
+		require.NoError(t, err)
+		mockedFileBackend.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("the blob is referenced before checking it is stored", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		referenced := false
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "team-id", "board-id").DoAndReturn(func(_ *mm_model.FileInfo, _, _ string) error {
+			referenced = true
+			return nil
+		})
+		mockedFileBackend.On("FileExists", blobPath).Run(func(mock.Arguments) {
+			require.True(t, referenced)
+		}).Return(true, nil)
+
+		_, err := th.App.SaveFile(strings.NewReader(content), "team-id", "board-id", "user-id", "copy.txt", false)
+		require.NoError(t, err)
+	})
+
+	t.Run("a blob being removed is referenced once removed", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", blobPath).Return(false, nil)
+		mockedFileBackend.On("WriteFile", mock.Anything, blobPath).Return(int64(len(content)), nil)
+		gomock.InOrder(
+			th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "team-id", "board-id").Return(model.ErrFileBlobRemoving),
+			th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "team-id", "board-id").Return(nil),
+		)
+
+		_, err := th.App.SaveFile(strings.NewReader(content), "team-id", "board-id", "user-id", "file.txt", false)
+		require.NoError(t, err)
+		mockedFileBackend.AssertCalled(t, "WriteFile", mock.Anything, blobPath)
+	})
+
+	t.Run("streamed uploads of stored content are removed", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		var uploadPath string
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(func(reader io.Reader, path string) int64 {
+			uploadPath = path
+			n, _ := io.Copy(io.Discard, reader)
+			return n
+		}, nil)
+		mockedFileBackend.On("FileExists", blobPath).Return(true, nil)
+		mockedFileBackend.On("RemoveFile", mock.Anything).Return(nil)
+		expectFileInfo(t)
+
+		_, err := th.App.SaveFile(io.MultiReader(strings.NewReader(content)), "team-id", "board-id", "user-id", "copy.txt", false)
+		require.NoError(t, err)
+		require.NotEqual(t, blobPath, uploadPath)
+		mockedFileBackend.AssertCalled(t, "RemoveFile", uploadPath)
+	})
+
+	t.Run("template files are not deduplicated", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("WriteFile", mock.Anything, "team-id/board-id/7template.txt").Return(int64(len(content)), nil)
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "team-id", "board-id").Return(nil)
+
+		_, err := th.App.SaveFile(strings.NewReader(content), "team-id", "board-id", "user-id", "7template.txt", true)
+		require.NoError(t, err)
+		mockedFileBackend.AssertNotCalled(t, "FileExists", mock.Anything)
+	})
+}
+
+func TestCopyCardFilesSharesBlobs(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	blobPath := contentBlobPath("image")
+	board := &model.Board{ID: "board-id", TeamID: "team-id"}
+	th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+	th.Store.EXPECT().GetFileInfo("fileInfoID").Return(&mm_model.FileInfo{
+		Id:   "fileInfoID",
+		Path: blobPath,
+		Size: 5,
+	}, nil)
+	th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "team-id", "board-id").DoAndReturn(func(fileInfo *mm_model.FileInfo, _, _ string) error {
+		require.Equal(t, blobPath, fileInfo.Path)
+		require.NotEqual(t, "fileInfoID", fileInfo.Id)
+		return nil
+	})
+	th.Store.EXPECT().GetFileScanResult("fileInfoID").Return(nil, model.NewErrNotFound("file scan result fileInfoID"))
+
+	mockedFileBackend := &mocks.FileBackend{}
+	th.App.filesBackend = mockedFileBackend
+
+	blocks := []*model.Block{{
+		ID:      "image-id",
+		BoardID: "board-id",
+		Type:    model.TypeImage,
+		Fields:  map[string]interface{}{"fileId": "7fileInfoID.png"},
+	}}
+	newFileNames, err := th.App.CopyCardFiles("board-id", "user-id", blocks, false)
+	require.NoError(t, err)
+	require.Len(t, newFileNames, 1)
+	mockedFileBackend.AssertNotCalled(t, "CopyFile", mock.Anything, mock.Anything)
+}
+
+func TestDeduplicateFiles(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	content := "legacy content"
+	blobPath := contentBlobPath(content)
+	legacy := []*mm_model.FileInfo{
+		{Id: "legacy-1", CreateAt: 1, Path: "boards/20240101/7legacy1.txt", Size: 14},
+		{Id: "legacy-2", CreateAt: 2, Path: "boards/20240102/7legacy2.txt", Size: 14},
+		{Id: "missing", CreateAt: 3, Path: "boards/20240103/7missing.txt"},
+		{Id: "quarantined", CreateAt: 4, Path: "boards/20240104/7quarantined.txt"},
+		{Id: "unreadable", CreateAt: 5, Path: "boards/20240105/7unreadable.txt"},
+	}
+
+	th.Store.EXPECT().GetLegacyFileInfos(int64(0), "", fileDedupBatchSize).Return(legacy, nil)
+	th.Store.EXPECT().GetLegacyFileInfos(int64(5), "unreadable", fileDedupBatchSize).Return([]*mm_model.FileInfo{}, nil)
+	for _, id := range []string{"legacy-1", "legacy-2", "missing", "unreadable"} {
+		th.Store.EXPECT().GetFileScanResult(id).Return(nil, model.NewErrNotFound("file scan result "+id))
+	}
+	th.Store.EXPECT().GetFileScanResult("quarantined").Return(&model.FileScanResult{
+		FileID: "quarantined",
+		Status: model.FileScanStatusInfected,
+	}, nil)
+	th.Store.EXPECT().UpdateFileInfoPath(gomock.Any()).DoAndReturn(func(fileInfo *mm_model.FileInfo) error {
+		require.Equal(t, blobPath, fileInfo.Path)
+		return nil
+	}).Times(2)
+
+	mockedFileBackend := &mocks.FileBackend{}
+	th.App.filesBackend = mockedFileBackend
+	mockedFileBackend.On("Reader", "boards/20240101/7legacy1.txt").Return(newReadCloseSeeker(content), nil)
+	mockedFileBackend.On("Reader", "boards/20240102/7legacy2.txt").Return(newReadCloseSeeker(content), nil)
+	mockedFileBackend.On("FileExists", "boards/20240101/7legacy1.txt").Return(true, nil)
+	mockedFileBackend.On("FileExists", "boards/20240102/7legacy2.txt").Return(true, nil)
+	mockedFileBackend.On("FileExists", "boards/20240103/7missing.txt").Return(false, nil)
+	mockedFileBackend.On("FileExists", "boards/20240105/7unreadable.txt").Return(true, nil)
+	mockedFileBackend.On("Reader", "boards/20240105/7unreadable.txt").Return(nil, &TestError{})
+	mockedFileBackend.On("FileExists", blobPath).Return(false, nil).Once()
+	mockedFileBackend.On("FileExists", blobPath).Return(true, nil)
+	mockedFileBackend.On("CopyFile", "boards/20240101/7legacy1.txt", blobPath).Return(nil)
+	mockedFileBackend.On("RemoveFile", mock.Anything).Return(nil)
+
+	moved, skipped, failed, err := th.App.DeduplicateFiles()
+	require.NoError(t, err)
+	require.Equal(t, 2, moved)
+	require.Equal(t, 2, skipped)
+	require.Equal(t, 1, failed)
+
+	mockedFileBackend.AssertNumberOfCalls(t, "CopyFile", 1)
+	mockedFileBackend.AssertCalled(t, "RemoveFile", "boards/20240101/7legacy1.txt")
+	mockedFileBackend.AssertCalled(t, "RemoveFile", "boards/20240102/7legacy2.txt")
+}
+
+func TestRunFileDeduplication(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("skipped files don't prevent the job from being done", func(t *testing.T) {
+		missing := &mm_model.FileInfo{Id: "missing", CreateAt: 1, Path: "boards/20240101/7missing.txt"}
+
+		th.Store.EXPECT().GetSystemSetting(fileDedupDoneKey).Return("", nil)
+		th.Store.EXPECT().GetSystemSetting(fileDedupLastRunKey).Return("", nil)
+		th.Store.EXPECT().CompareAndSetSystemSetting(fileDedupLastRunKey, "", gomock.Any()).Return(true, nil)
+		th.Store.EXPECT().GetLegacyFileInfos(int64(0), "", fileDedupBatchSize).Return([]*mm_model.FileInfo{missing}, nil)
+		th.Store.EXPECT().GetLegacyFileInfos(int64(1), "missing", fileDedupBatchSize).Return([]*mm_model.FileInfo{}, nil)
+		th.Store.EXPECT().GetFileScanResult("missing").Return(nil, model.NewErrNotFound("file scan result missing"))
+		th.Store.EXPECT().SetSystemSetting(fileDedupDoneKey, "true").Return(nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", missing.Path).Return(false, nil)
+
+		th.App.RunFileDeduplication()
+	})
+}
+
+func TestMoveFileToBlob(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	content := "legacy content"
+	blobPath := contentBlobPath(content)
+	fileInfo := &mm_model.FileInfo{Id: "legacy-1", Path: "boards/20240101/7legacy1.txt", Size: 14}
+
+	t.Run("a blob removed before being referenced is copied again", func(t *testing.T) {
+		th.Store.EXPECT().UpdateFileInfoPath(gomock.Any()).Return(nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("Reader", fileInfo.Path).Return(newReadCloseSeeker(content), nil)
+		mockedFileBackend.On("FileExists", blobPath).Return(true, nil).Once()
+		mockedFileBackend.On("FileExists", blobPath).Return(false, nil).Once()
+		mockedFileBackend.On("CopyFile", fileInfo.Path, blobPath).Return(nil)
+		mockedFileBackend.On("RemoveFile", fileInfo.Path).Return(nil)
+
+		require.NoError(t, th.App.moveFileToBlob(fileInfo))
+		mockedFileBackend.AssertNumberOfCalls(t, "CopyFile", 1)
+	})
+
+	t.Run("the file info is restored if the blob can't be stored", func(t *testing.T) {
+		gomock.InOrder(
+			th.Store.EXPECT().UpdateFileInfoPath(gomock.Any()).DoAndReturn(func(info *mm_model.FileInfo) error {
+				require.Equal(t, blobPath, info.Path)
+				return nil
+			}),
+			th.Store.EXPECT().UpdateFileInfoPath(fileInfo).Return(nil),
+		)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("Reader", fileInfo.Path).Return(newReadCloseSeeker(content), nil)
+		mockedFileBackend.On("FileExists", blobPath).Return(true, nil).Once()
+		mockedFileBackend.On("FileExists", blobPath).Return(false, nil).Once()
+		mockedFileBackend.On("CopyFile", fileInfo.Path, blobPath).Return(&TestError{})
+
+		require.Error(t, th.App.moveFileToBlob(fileInfo))
+		mockedFileBackend.AssertNotCalled(t, "RemoveFile", mock.Anything)
+	})
+}
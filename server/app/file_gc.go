@@ -0,0 +1,299 @@
+package app
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	fileGCLastRunKey = "file_gc_last_run"
+
+	// fileGCMinInterval is the minimum time between two scheduled runs,
+	// so a cluster of servers collects the files once.
+	fileGCMinInterval = 23 * time.Hour
+
+	// fileGCRoot is the directory of the files storage holding the
+	// uploads of boards, which are named after their file info.
+	// Template files are stored by team and board, and are only
+	// collected through their file info.
+	fileGCRoot = "boards"
+
+	defaultFileGCGracePeriod = 7 * 24 * time.Hour
+)
+
+// RunFileGC removes the files that are no longer attached to any block,
+// unless another server of the cluster did it recently.
+func (a *App) RunFileGC() {
+	if !a.claimScheduledRun(fileGCLastRunKey, fileGCMinInterval) {
+		return
+	}
+
+	report, err := a.CollectOrphanedFiles(a.config.FileGCDryRun)
+	if err != nil {
+		a.logger.Error("File garbage collection failed", mlog.Err(err))
+		return
+	}
+
+	a.logger.Info("File garbage collection done",
+		mlog.Bool("dry_run", report.DryRun),
+		mlog.Int("orphans", len(report.Orphans)),
+		mlog.Int("removed", report.RemovedCount),
+		mlog.Int("removed_size", report.RemovedSize),
+		mlog.Int("errors", report.ErrorCount),
+	)
+}
+
+// claimScheduledRun records a run of a scheduled job in the system
+// settings, and returns false if another server of the cluster ran it
+// less than minInterval ago. The run is recorded only if the last run
+// is still the one read, so two servers never both claim it.
+func (a *App) claimScheduledRun(lastRunKey string, minInterval time.Duration) bool {
+	_, _, claimed := a.claimScheduledRunSince(lastRunKey, minInterval)
+	return claimed
+}
+
+// claimScheduledRunSince is claimScheduledRun for the jobs that process
+// what happened since their last run. It also returns the time of the
+// last run, which is zero for the first one, and the time of the claimed
+// run, so the servers of the cluster process consecutive periods.
+func (a *App) claimScheduledRunSince(lastRunKey string, minInterval time.Duration) (int64, int64, bool) {
+	now := utils.GetMillis()
+
+	lastRun, err := a.store.GetSystemSetting(lastRunKey)
+	if err != nil {
+		a.logger.Error("Cannot fetch last run of scheduled job", mlog.String("key", lastRunKey), mlog.Err(err))
+		return 0, 0, false
+	}
+	var last int64
+	if lastRun != "" {
+		if last, err = strconv.ParseInt(lastRun, 10, 64); err != nil {
+			a.logger.Error("Invalid last run of scheduled job", mlog.String("key", lastRunKey), mlog.String("value", lastRun))
+			last = 0
+		}
+		if last != 0 && now-last < minInterval.Milliseconds() {
+			return 0, 0, false
+		}
+	}
+
+	claimed, err := a.store.CompareAndSetSystemSetting(lastRunKey, lastRun, strconv.FormatInt(now, 10))
+	if err != nil {
+		a.logger.Error("Cannot save run of scheduled job", mlog.String("key", lastRunKey), mlog.Err(err))
+		return 0, 0, false
+	}
+	return last, now, claimed
+}
+
+// CollectOrphanedFiles finds the files stored before the grace period
+// that are not attached to any block, nor to a version of a block that
+// can still be restored, and removes them unless dryRun is set. The file infos, the files of the storage
+// without one and the blobs no file info references are checked.
+func (a *App) CollectOrphanedFiles(dryRun bool) (*model.FileGCReport, error) {
+	gracePeriod := time.Duration(a.config.FileGCGracePeriodHours) * time.Hour
+	if gracePeriod <= 0 {
+		gracePeriod = defaultFileGCGracePeriod
+	}
+	cutoff := utils.GetMillisForTime(time.Now().Add(-gracePeriod))
+
+	report := &model.FileGCReport{
+		DryRun:      dryRun,
+		GraceCutoff: cutoff,
+		Orphans:     []*model.OrphanedFile{},
+	}
+
+	fileIDs, err := a.store.GetFileReferences()
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool, len(fileIDs))
+	for _, fileID := range fileIDs {
+		referenced[fileID] = true
+	}
+
+	fileInfos, err := a.store.GetFileInfosCreatedBefore(cutoff)
+	if err != nil {
+		return nil, err
+	}
+	report.CheckedFileInfos = len(fileInfos)
+
+	// paths of the files with a file info, which are handled with it
+	known := map[string]bool{}
+	orphanedIDs := []string{}
+	for _, fileInfo := range fileInfos {
+		paths := []string{fileInfo.Path, fileInfo.ThumbnailPath, fileInfo.PreviewPath}
+		for _, p := range paths {
+			known[p] = true
+		}
+		// the files of legacy file infos without a path can't be
+		// found safely
+		if referenced[fileInfo.Id] || fileInfo.Path == "" || fileInfo.Path == emptyString {
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, &model.OrphanedFile{
+			Path:       fileInfo.Path,
+			FileInfoID: fileInfo.Id,
+			Size:       fileInfo.Size,
+		})
+		if dryRun {
+			continue
+		}
+		// blobs are shared by the uploads with the same content, and are
+		// removed once no file info references them
+		if _, isBlob := model.FileBlobHash(fileInfo.Path); isBlob {
+			report.RemovedCount++
+			orphanedIDs = append(orphanedIDs, fileInfo.Id)
+			continue
+		}
+		if !a.removeOrphanedFiles(report, paths...) {
+			continue
+		}
+		report.RemovedSize += fileInfo.Size
+		orphanedIDs = append(orphanedIDs, fileInfo.Id)
+	}
+
+	if len(orphanedIDs) > 0 {
+		if err := a.store.DeleteFileInfos(orphanedIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.collectUnreferencedFileBlobs(report, dryRun); err != nil {
+		return nil, err
+	}
+
+	paths, err := a.filesBackend.ListDirectoryRecursively(fileGCRoot)
+	if err != nil {
+		return nil, err
+	}
+	report.CheckedPaths = len(paths)
+
+	for _, p := range paths {
+		if known[p] {
+			continue
+		}
+		fileID, ok := fileIDFromStoragePath(p)
+		if !ok || referenced[fileID] {
+			continue
+		}
+
+		// the file info of recent files is not fetched, so they are
+		// recognized by their modification time
+		modTime, err := a.filesBackend.FileModTime(p)
+		if err != nil {
+			a.logger.Warn("File garbage collection cannot get modification time", mlog.String("path", p), mlog.Err(err))
+			continue
+		}
+		if utils.GetMillisForTime(modTime) >= cutoff {
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, &model.OrphanedFile{Path: p})
+		if !dryRun {
+			a.removeOrphanedFiles(report, p)
+		}
+	}
+
+	return report, nil
+}
+
+// collectUnreferencedFileBlobs removes the blobs that no file info
+// references anymore, with their renditions. A blob is claimed before
+// its files are removed, and uploads of the same content can't
+// reference it until its row is deleted, so they never rely on files
+// being removed. Blobs left claimed by an interrupted run are removed
+// again.
+func (a *App) collectUnreferencedFileBlobs(report *model.FileGCReport, dryRun bool) error {
+	blobs, err := a.store.GetUnreferencedFileBlobs()
+	if err != nil {
+		return err
+	}
+
+	for _, blob := range blobs {
+		blobPath := model.FileBlobPath(blob.Hash)
+		if !dryRun && blob.RefCount == 0 {
+			claimed, err := a.store.ClaimFileBlob(blob.Hash)
+			if err != nil {
+				return err
+			}
+			// the blob was referenced again since it was listed
+			if !claimed {
+				continue
+			}
+		}
+
+		report.Orphans = append(report.Orphans, &model.OrphanedFile{
+			Path: blobPath,
+			Size: blob.Size,
+		})
+		if dryRun {
+			continue
+		}
+
+		paths := []string{
+			blobPath,
+			renditionPath(blobPath, model.FileRenditionThumb),
+			renditionPath(blobPath, model.FileRenditionPreview),
+		}
+		if !a.removeOrphanedFiles(report, paths...) {
+			if err := a.store.UnclaimFileBlob(blob.Hash); err != nil {
+				return err
+			}
+			continue
+		}
+		report.RemovedSize += blob.Size
+		if err := a.store.DeleteFileBlob(blob.Hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeOrphanedFiles removes the files of an orphan, and returns
+// whether they were all removed.
+func (a *App) removeOrphanedFiles(report *model.FileGCReport, paths ...string) bool {
+	removed := true
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		exists, err := a.filesBackend.FileExists(p)
+		if err == nil && exists {
+			err = a.filesBackend.RemoveFile(p)
+		}
+		if err != nil {
+			a.logger.Error("File garbage collection cannot remove file", mlog.String("path", p), mlog.Err(err))
+			report.ErrorCount++
+			removed = false
+			continue
+		}
+		a.logger.Info("File garbage collection removed file", mlog.String("path", p))
+	}
+	if removed {
+		report.RemovedCount++
+	}
+	return removed
+}
+
+// fileIDFromStoragePath returns the ID of the file info of a stored
+// upload or rendition, named after the ID with a one character prefix,
+// like 7<id>.png or 7<id>_thumb.jpg. Files named otherwise are not
+// uploads and are never collected.
+func fileIDFromStoragePath(storagePath string) (string, bool) {
+	name := path.Base(storagePath)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	for _, rendition := range []model.FileRendition{model.FileRenditionThumb, model.FileRenditionPreview} {
+		name = strings.TrimSuffix(name, "_"+string(rendition))
+	}
+
+	if len(name) != 27 {
+		return "", false
+	}
+	return getFileInfoID(name), true
+}
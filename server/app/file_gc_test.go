@@ -0,0 +1,227 @@
+package app
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore/mocks"
+)
+
+func TestCollectOrphanedFiles(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	referencedID := "7referencedxxxxxxxxxxxxxxxx"
+	orphanedID := "7orphanedxxxxxxxxxxxxxxxxxx"
+	strayID := "7strayxxxxxxxxxxxxxxxxxxxxx"
+	recentID := "7recentxxxxxxxxxxxxxxxxxxxx"
+
+	fileInfos := []*mm_model.FileInfo{
+		{Id: referencedID[1:], Path: "boards/20240101/" + referencedID + ".png"},
+		{
+			Id:            orphanedID[1:],
+			Path:          "boards/20240101/" + orphanedID + ".png",
+			ThumbnailPath: "boards/20240101/" + orphanedID + "_thumb.jpg",
+			Size:          100,
+		},
+		{Id: "legacyxxxxxxxxxxxxxxxxxxxx", Path: ""},
+	}
+	storagePaths := []string{
+		"boards/20240101/" + referencedID + ".png",
+		"boards/20240101/" + orphanedID + ".png",
+		"boards/20240101/" + orphanedID + "_thumb.jpg",
+		"boards/20240101/" + strayID + ".pdf",
+		"boards/20240101/" + recentID + ".pdf",
+		"boards/20240101/notes.txt",
+	}
+	old := time.Now().Add(-30 * 24 * time.Hour)
+
+	setup := func(t *testing.T) *mocks.FileBackend {
+		th.Store.EXPECT().GetFileReferences().Return([]string{referencedID[1:]}, nil)
+		th.Store.EXPECT().GetFileInfosCreatedBefore(gomock.Any()).Return(fileInfos, nil)
+		th.Store.EXPECT().GetUnreferencedFileBlobs().Return([]*model.FileBlob{}, nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("ListDirectoryRecursively", "boards").Return(storagePaths, nil)
+		mockedFileBackend.On("FileModTime", "boards/20240101/"+strayID+".pdf").Return(old, nil)
+		mockedFileBackend.On("FileModTime", "boards/20240101/"+recentID+".pdf").Return(time.Now(), nil)
+		return mockedFileBackend
+	}
+
+	t.Run("dry run only reports the orphans", func(t *testing.T) {
+		mockedFileBackend := setup(t)
+
+		report, err := th.App.CollectOrphanedFiles(true)
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Equal(t, 3, report.CheckedFileInfos)
+		require.Equal(t, len(storagePaths), report.CheckedPaths)
+		require.Equal(t, []*model.OrphanedFile{
+			{Path: "boards/20240101/" + orphanedID + ".png", FileInfoID: orphanedID[1:], Size: 100},
+			{Path: "boards/20240101/" + strayID + ".pdf"},
+		}, report.Orphans)
+		require.Zero(t, report.RemovedCount)
+		mockedFileBackend.AssertNotCalled(t, "RemoveFile", mock.Anything)
+	})
+
+	t.Run("orphans are removed with their renditions and file info", func(t *testing.T) {
+		mockedFileBackend := setup(t)
+		th.Store.EXPECT().DeleteFileInfos([]string{orphanedID[1:]}).Return(nil)
+		mockedFileBackend.On("FileExists", mock.Anything).Return(true, nil)
+		mockedFileBackend.On("RemoveFile", mock.Anything).Return(nil)
+
+		report, err := th.App.CollectOrphanedFiles(false)
+		require.NoError(t, err)
+		require.Len(t, report.Orphans, 2)
+		require.Equal(t, 2, report.RemovedCount)
+		require.Equal(t, int64(100), report.RemovedSize)
+		require.Zero(t, report.ErrorCount)
+
+		mockedFileBackend.AssertCalled(t, "RemoveFile", "boards/20240101/"+orphanedID+".png")
+		mockedFileBackend.AssertCalled(t, "RemoveFile", "boards/20240101/"+orphanedID+"_thumb.jpg")
+		mockedFileBackend.AssertCalled(t, "RemoveFile", "boards/20240101/"+strayID+".pdf")
+		mockedFileBackend.AssertNumberOfCalls(t, "RemoveFile", 3)
+	})
+}
+
+func TestClaimScheduledRun(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("a recent run is not claimed again", func(t *testing.T) {
+		lastRun := strconv.FormatInt(utils.GetMillis(), 10)
+		th.Store.EXPECT().GetSystemSetting(fileGCLastRunKey).Return(lastRun, nil)
+
+		require.False(t, th.App.claimScheduledRun(fileGCLastRunKey, fileGCMinInterval))
+	})
+
+	t.Run("a run claimed by another server is not claimed", func(t *testing.T) {
+		lastRun := strconv.FormatInt(utils.GetMillisForTime(time.Now().Add(-2*fileGCMinInterval)), 10)
+		th.Store.EXPECT().GetSystemSetting(fileGCLastRunKey).Return(lastRun, nil)
+		th.Store.EXPECT().CompareAndSetSystemSetting(fileGCLastRunKey, lastRun, gomock.Any()).Return(false, nil)
+
+		require.False(t, th.App.claimScheduledRun(fileGCLastRunKey, fileGCMinInterval))
+	})
+
+	t.Run("a first run is claimed", func(t *testing.T) {
+		th.Store.EXPECT().GetSystemSetting(fileGCLastRunKey).Return("", nil)
+		th.Store.EXPECT().CompareAndSetSystemSetting(fileGCLastRunKey, "", gomock.Any()).Return(true, nil)
+
+		require.True(t, th.App.claimScheduledRun(fileGCLastRunKey, fileGCMinInterval))
+	})
+}
+
+func TestFileIDFromStoragePath(t *testing.T) {
+	id := "7abcdefghijklmnopqrstuvwxyz"
+
+	for _, storagePath := range []string{
+		"boards/20240101/" + id + ".png",
+		"boards/20240101/" + id + "_thumb.jpg",
+		"boards/20240101/" + id + "_preview.jpg",
+		"boards/20240101/" + id,
+	} {
+		fileID, ok := fileIDFromStoragePath(storagePath)
+		require.True(t, ok, storagePath)
+		require.Equal(t, id[1:], fileID)
+	}
+
+	_, ok := fileIDFromStoragePath("boards/20240101/notes.txt")
+	require.False(t, ok)
+}
+
+func TestCollectOrphanedFileBlobs(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	sharedPath := model.FileBlobPath("aabbccddeeff00112233445566778899aabbccddeeff00112233445566778899")
+	unreferencedHash := "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"
+	unreferencedPath := model.FileBlobPath(unreferencedHash)
+
+	th.Store.EXPECT().GetFileReferences().Return([]string{}, nil)
+	th.Store.EXPECT().GetFileInfosCreatedBefore(gomock.Any()).Return([]*mm_model.FileInfo{
+		{Id: "orphanedxxxxxxxxxxxxxxxxxx", Path: sharedPath, Size: 10},
+	}, nil)
+	th.Store.EXPECT().DeleteFileInfos([]string{"orphanedxxxxxxxxxxxxxxxxxx"}).Return(nil)
+	th.Store.EXPECT().GetUnreferencedFileBlobs().Return([]*model.FileBlob{{Hash: unreferencedHash, Size: 20}}, nil)
+	th.Store.EXPECT().ClaimFileBlob(unreferencedHash).Return(true, nil)
+	th.Store.EXPECT().DeleteFileBlob(unreferencedHash).Return(nil)
+
+	mockedFileBackend := &mocks.FileBackend{}
+	th.App.filesBackend = mockedFileBackend
+	mockedFileBackend.On("ListDirectoryRecursively", "boards").Return([]string{sharedPath, unreferencedPath}, nil)
+	mockedFileBackend.On("FileExists", mock.Anything).Return(true, nil)
+	mockedFileBackend.On("RemoveFile", mock.Anything).Return(nil)
+
+	report, err := th.App.CollectOrphanedFiles(false)
+	require.NoError(t, err)
+	require.Len(t, report.Orphans, 2)
+	require.Equal(t, int64(20), report.RemovedSize)
+
+	// the blob of the orphaned file info may be shared, so it is only
+	// removed once unreferenced
+	mockedFileBackend.AssertNotCalled(t, "RemoveFile", sharedPath)
+	mockedFileBackend.AssertCalled(t, "RemoveFile", unreferencedPath)
+	mockedFileBackend.AssertCalled(t, "RemoveFile", unreferencedPath+"_thumb.jpg")
+}
+
+func TestCollectUnreferencedFileBlobs(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	hash := "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"
+	blobPath := model.FileBlobPath(hash)
+
+	t.Run("blobs referenced again are kept", func(t *testing.T) {
+		th.Store.EXPECT().GetUnreferencedFileBlobs().Return([]*model.FileBlob{{Hash: hash, Size: 20}}, nil)
+		th.Store.EXPECT().ClaimFileBlob(hash).Return(false, nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		report := &model.FileGCReport{Orphans: []*model.OrphanedFile{}}
+		require.NoError(t, th.App.collectUnreferencedFileBlobs(report, false))
+		require.Empty(t, report.Orphans)
+		mockedFileBackend.AssertNotCalled(t, "RemoveFile", mock.Anything)
+	})
+
+	t.Run("blobs that can't be removed are released", func(t *testing.T) {
+		th.Store.EXPECT().GetUnreferencedFileBlobs().Return([]*model.FileBlob{{Hash: hash, Size: 20}}, nil)
+		th.Store.EXPECT().ClaimFileBlob(hash).Return(true, nil)
+		th.Store.EXPECT().UnclaimFileBlob(hash).Return(nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", mock.Anything).Return(true, nil)
+		mockedFileBackend.On("RemoveFile", mock.Anything).Return(&TestError{})
+
+		report := &model.FileGCReport{Orphans: []*model.OrphanedFile{}}
+		require.NoError(t, th.App.collectUnreferencedFileBlobs(report, false))
+		require.NotZero(t, report.ErrorCount)
+		require.Zero(t, report.RemovedSize)
+	})
+
+	t.Run("blobs left claimed are removed without claiming them again", func(t *testing.T) {
+		th.Store.EXPECT().GetUnreferencedFileBlobs().Return([]*model.FileBlob{{Hash: hash, Size: 20, RefCount: -1}}, nil)
+		th.Store.EXPECT().DeleteFileBlob(hash).Return(nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", mock.Anything).Return(true, nil)
+		mockedFileBackend.On("RemoveFile", mock.Anything).Return(nil)
+
+		report := &model.FileGCReport{Orphans: []*model.OrphanedFile{}}
+		require.NoError(t, th.App.collectUnreferencedFileBlobs(report, false))
+		require.Equal(t, int64(20), report.RemovedSize)
+		mockedFileBackend.AssertCalled(t, "RemoveFile", blobPath)
+	})
+}
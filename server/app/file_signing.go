@@ -0,0 +1,129 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+const (
+	// FileURLExpiresParam is the query parameter of signed file URLs
+	// holding when they expire, in milliseconds since the epoch.
+	FileURLExpiresParam = "expires"
+	// FileURLSignatureParam is the query parameter of signed file URLs
+	// holding their signature.
+	FileURLSignatureParam = "signature"
+
+	defaultFileSignedURLExpiry = 5 * time.Minute
+)
+
+// SignFileURL returns a URL to download a file of a board without a
+// session or a read token. The URL is valid for expiresIn, capped to
+// the lifetime of signed URLs set in the configuration, which is also
+// used when expiresIn is zero.
+func (a *App) SignFileURL(teamID, boardID, filename string, expiresIn time.Duration) (*model.FileSignedURL, error) {
+	if a.config.Secret == "" {
+		return nil, model.NewErrNotImplemented("signed file URLs require a server secret")
+	}
+
+	maxExpiry := a.fileSignedURLExpiry()
+	if expiresIn <= 0 || expiresIn > maxExpiry {
+		expiresIn = maxExpiry
+	}
+	expiresAt := utils.GetMillis() + expiresIn.Milliseconds()
+
+	query := url.Values{}
+	query.Set(FileURLExpiresParam, strconv.FormatInt(expiresAt, 10))
+	query.Set(FileURLSignatureParam, a.fileURLSignature(teamID, boardID, filename, expiresAt))
+
+	return &model.FileSignedURL{
+		URL:       utils.MakeFileLink(a.config.ServerRoot, teamID, boardID, filename) + "?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// CheckFileURLSignature checks that the signature of a signed file URL
+// was made by this server for the file, and that the URL hasn't
+// expired.
+func (a *App) CheckFileURLSignature(teamID, boardID, filename, expires, signature string) error {
+	if a.config.Secret == "" {
+		return model.NewErrUnauthorized("invalid file signature")
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return model.NewErrBadRequest("invalid expires, must be a time in milliseconds")
+	}
+
+	expected := a.fileURLSignature(teamID, boardID, filename, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return model.NewErrUnauthorized("invalid file signature")
+	}
+	if utils.GetMillis() > expiresAt {
+		return model.NewErrUnauthorized("file URL expired")
+	}
+	return nil
+}
+
+// GetFilePresignedURL returns a URL of the files storage to download a
+// file from directly, when signed URLs are configured to redirect to
+// presigned S3 URLs. An empty URL means the file must be served by the
+// server.
+func (a *App) GetFilePresignedURL(teamID, boardID, filename string) (string, error) {
+	if !a.config.FileSignedURLRedirectS3 || a.config.FilesDriver != mm_model.ImageDriverS3 {
+		return "", nil
+	}
+
+	linkGenerator, ok := a.filesBackend.(filestore.FileBackendWithLinkGenerator)
+	if !ok {
+		return "", nil
+	}
+
+	fileInfo, filePath, err := a.GetFilePath(teamID, boardID, filename)
+	if err != nil {
+		return "", err
+	}
+	if err := a.checkFileNotBlocked(fileInfo); err != nil {
+		return "", err
+	}
+
+	// files of boards created before the move to teams may still be in
+	// a channel directory, they are moved when served
+	exists, err := a.filesBackend.FileExists(filePath)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+
+	link, _, err := linkGenerator.GeneratePublicLink(filePath)
+	if err != nil {
+		a.logger.Error("GetFilePresignedURL: Failed to generate the presigned URL", mlog.String("Path", filePath), mlog.Err(err))
+		return "", err
+	}
+	return link, nil
+}
+
+func (a *App) fileSignedURLExpiry() time.Duration {
+	if a.config.FileSignedURLExpirySeconds <= 0 {
+		return defaultFileSignedURLExpiry
+	}
+	return time.Duration(a.config.FileSignedURLExpirySeconds) * time.Second
+}
+
+func (a *App) fileURLSignature(teamID, boardID, filename string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, []byte(a.config.Secret))
+	mac.Write([]byte(strings.Join([]string{"file", teamID, boardID, filename, strconv.FormatInt(expiresAt, 10)}, "\n")))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
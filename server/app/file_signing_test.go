@@ -0,0 +1,89 @@
+package app
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+func TestSignFileURL(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.ServerRoot = "http://localhost:8000"
+	th.App.config.Secret = "test-secret"
+	th.App.config.FileSignedURLExpirySeconds = 60
+
+	parseSignedURL := func(t *testing.T, signedURL *model.FileSignedURL) (string, string) {
+		u, err := url.Parse(signedURL.URL)
+		require.NoError(t, err)
+		require.Equal(t, "/api/v2/files/teams/team-id/board-id/7file.txt", u.Path)
+		require.Equal(t, strconv.FormatInt(signedURL.ExpiresAt, 10), u.Query().Get(FileURLExpiresParam))
+		return u.Query().Get(FileURLExpiresParam), u.Query().Get(FileURLSignatureParam)
+	}
+
+	t.Run("should accept the signature of a file", func(t *testing.T) {
+		signedURL, err := th.App.SignFileURL("team-id", "board-id", "7file.txt", 30*time.Second)
+		require.NoError(t, err)
+		require.LessOrEqual(t, signedURL.ExpiresAt, utils.GetMillis()+30*1000)
+
+		expires, signature := parseSignedURL(t, signedURL)
+		require.NoError(t, th.App.CheckFileURLSignature("team-id", "board-id", "7file.txt", expires, signature))
+	})
+
+	t.Run("should cap the expiry to the configured one", func(t *testing.T) {
+		signedURL, err := th.App.SignFileURL("team-id", "board-id", "7file.txt", time.Hour)
+		require.NoError(t, err)
+		require.LessOrEqual(t, signedURL.ExpiresAt, utils.GetMillis()+60*1000)
+	})
+
+	t.Run("should reject the signature of another file or expiry", func(t *testing.T) {
+		signedURL, err := th.App.SignFileURL("team-id", "board-id", "7file.txt", 0)
+		require.NoError(t, err)
+		expires, signature := parseSignedURL(t, signedURL)
+
+		err = th.App.CheckFileURLSignature("team-id", "board-id", "7other.txt", expires, signature)
+		require.True(t, model.IsErrUnauthorized(err))
+
+		err = th.App.CheckFileURLSignature("team-id", "other-board-id", "7file.txt", expires, signature)
+		require.True(t, model.IsErrUnauthorized(err))
+
+		err = th.App.CheckFileURLSignature("team-id", "board-id", "7file.txt", strconv.FormatInt(signedURL.ExpiresAt+1000, 10), signature)
+		require.True(t, model.IsErrUnauthorized(err))
+
+		err = th.App.CheckFileURLSignature("team-id", "board-id", "7file.txt", "invalid", signature)
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("should reject an expired signature", func(t *testing.T) {
+		expiresAt := utils.GetMillis() - 1000
+		signature := th.App.fileURLSignature("team-id", "board-id", "7file.txt", expiresAt)
+
+		err := th.App.CheckFileURLSignature("team-id", "board-id", "7file.txt", strconv.FormatInt(expiresAt, 10), signature)
+		require.True(t, model.IsErrUnauthorized(err))
+	})
+
+	t.Run("should not sign without a secret", func(t *testing.T) {
+		th.App.config.Secret = ""
+		defer func() { th.App.config.Secret = "test-secret" }()
+
+		signedURL, err := th.App.SignFileURL("team-id", "board-id", "7file.txt", 0)
+		require.True(t, model.IsErrNotImplemented(err))
+		require.Nil(t, signedURL)
+	})
+
+	t.Run("should not redirect to the files storage unless configured", func(t *testing.T) {
+		th.App.config.FilesDriver = "local"
+		th.App.config.FileSignedURLRedirectS3 = true
+
+		presignedURL, err := th.App.GetFilePresignedURL("team-id", "board-id", "7file.txt")
+		require.NoError(t, err)
+		require.Empty(t, presignedURL)
+	})
+}
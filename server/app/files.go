@@ -1,11 +1,15 @@
 package app
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mattermost/focalboard/server/model"
 	mm_model "github.com/mattermost/mattermost/server/public/model"
@@ -15,14 +19,36 @@ import (
 	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
 )
 
-const emptyString = "empty"
+const (
+	emptyString = "empty"
+
+	// fileBlobSaveAttempts is the number of times the file info of an
+	// upload is saved while the blob of its content is being removed.
+	fileBlobSaveAttempts = 5
+	fileBlobRetryDelay   = 200 * time.Millisecond
+)
 
 var errEmptyFilename = errors.New("IsFileArchived: empty filename not allowed")
 var ErrFileNotFound = errors.New("file not found")
 
-func (a *App) SaveFile(reader io.Reader, teamID, boardID, filename string, asTemplate bool) (string, error) {
+// SaveFile stores a file uploaded by a user in a board, and adds it to
+// the storage usage of the board. Files whose type the upload policy of
+// the team doesn't allow are rejected with an ErrUploadRejected, and
+// files that don't fit in the storage quota of the board or its team
+// with an ErrStorageQuotaExceeded. The content of uploads is stored
+// once, in a blob shared by the uploads with the same content. Stored
+// files are then scanned in the background if an upload scanner is
+// configured.
+func (a *App) SaveFile(reader io.Reader, teamID, boardID, userID, filename string, asTemplate bool) (string, error) {
+	_, newFileName, err := a.saveFile(reader, teamID, boardID, userID, filename, asTemplate)
+	return newFileName, err
+}
+
+// saveFile saves a file like SaveFile, also returning its file info.
+func (a *App) saveFile(reader io.Reader, teamID, boardID, userID, filename string, asTemplate bool) (*mm_model.FileInfo, string, error) {
 	// NOTE: File extension includes the dot
 	fileExtension := strings.ToLower(filepath.Ext(filename))
+	uploadedExtension := fileExtension
 	if fileExtension == ".jpeg" {
 		fileExtension = ".jpg"
 	}
@@ -34,22 +60,235 @@ func (a *App) SaveFile(reader io.Reader, teamID, boardID, filename string, asTem
 	}
 	filePath := getDestinationFilePath(asTemplate, teamID, boardID, newFileName)
 
-	fileSize, appErr := a.filesBackend.WriteFile(reader, filePath)
-	if appErr != nil {
-		return "", fmt.Errorf("unable to store the file in the files storage: %w", appErr)
+	fileInfo := model.NewFileInfo(filename)
+	fileInfo.CreatorId = userID
+
+	// uploads are seekable, so their content can be inspected before
+	// and after being stored
+	seeker, isSeeker := reader.(io.ReadSeeker)
+	if isSeeker {
+		mimeType, err := detectMimeType(seeker, fileExtension)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to detect the type of the file: %w", err)
+		}
+		fileInfo.MimeType = mimeType
+
+		size, err := seekerSize(seeker)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to get the size of the file: %w", err)
+		}
+		if err := a.checkStorageQuota(teamID, boardID, size); err != nil {
+			return nil, "", err
+		}
+	} else {
+		// the head of other uploads is read for sniffing, then put back
+		// in front of the rest
+		head := make([]byte, sniffLen)
+		n, err := io.ReadFull(reader, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, "", fmt.Errorf("unable to detect the type of the file: %w", err)
+		}
+		fileInfo.MimeType = sniffMimeType(head[:n], fileExtension)
+		reader = io.MultiReader(bytes.NewReader(head[:n]), reader)
+	}
+
+	if err := a.checkUploadPolicy(teamID, uploadedExtension, fileInfo.MimeType); err != nil {
+		return nil, "", err
 	}
 
-	fileInfo := model.NewFileInfo(filename)
 	fileInfo.Id = getFileInfoID(createdFilename)
+
+	// uploads are stored in the blob of their content, except template
+	// files which are looked up by name
+	var err error
+	switch {
+	case asTemplate:
+		err = a.saveTemplateFile(reader, fileInfo, teamID, boardID, filePath)
+	case isSeeker:
+		err = a.saveSeekableFile(seeker, fileInfo, teamID, boardID)
+	default:
+		err = a.saveStreamedFile(reader, fileInfo, teamID, boardID, filePath)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	a.enqueueUploadScan(fileInfo, teamID, boardID, newFileName)
+
+	return fileInfo, newFileName, nil
+}
+
+// saveTemplateFile stores a template file at its path, then saves its
+// file info.
+func (a *App) saveTemplateFile(reader io.Reader, fileInfo *mm_model.FileInfo, teamID, boardID, filePath string) error {
+	fileSize, err := a.filesBackend.WriteFile(reader, filePath)
+	if err != nil {
+		return fmt.Errorf("unable to store the file in the files storage: %w", err)
+	}
+
+	seeker, isSeeker := reader.(io.ReadSeeker)
+	if !isSeeker {
+		if err := a.checkStorageQuota(teamID, boardID, fileSize); err != nil {
+			if removeErr := a.filesBackend.RemoveFile(filePath); removeErr != nil {
+				a.logger.Error("SaveFile: cannot remove file over quota", mlog.String("path", filePath), mlog.Err(removeErr))
+			}
+			return err
+		}
+	}
+
 	fileInfo.Path = filePath
 	fileInfo.Size = fileSize
+	if isSeeker {
+		a.writeImageRenditions(a.renderImageRenditions(seeker, fileInfo))
+	}
+	return a.store.SaveFileInfoWithUsage(fileInfo, teamID, boardID)
+}
+
+// saveSeekableFile saves the file info of an upload, referencing the
+// blob of its content, then stores the content unless it is already
+// stored. Taking the reference first keeps the file garbage collection
+// from removing a blob an upload relies on.
+func (a *App) saveSeekableFile(seeker io.ReadSeeker, fileInfo *mm_model.FileInfo, teamID, boardID string) error {
+	hash, size, err := hashContent(seeker)
+	if err != nil {
+		return fmt.Errorf("unable to store the file in the files storage: %w", err)
+	}
+	fileInfo.Path = model.FileBlobPath(hash)
+	fileInfo.Size = size
+	renditions := a.renderImageRenditions(seeker, fileInfo)
+
+	if err := a.saveFileInfoWithBlob(fileInfo, teamID, boardID); err != nil {
+		return err
+	}
+
+	if err := a.writeFileBlob(seeker, fileInfo.Path); err != nil {
+		a.discardFileInfo(fileInfo)
+		return fmt.Errorf("unable to store the file in the files storage: %w", err)
+	}
+	a.writeImageRenditions(renditions)
+	return nil
+}
+
+// saveStreamedFile stores an upload that can't be read twice at its
+// path to learn its hash, saves its file info referencing the blob of
+// its content, then moves it to the blob unless the content is already
+// stored.
+func (a *App) saveStreamedFile(reader io.Reader, fileInfo *mm_model.FileInfo, teamID, boardID, filePath string) error {
+	hasher := sha256.New()
+	fileSize, err := a.filesBackend.WriteFile(io.TeeReader(reader, hasher), filePath)
+	if err != nil {
+		return fmt.Errorf("unable to store the file in the files storage: %w", err)
+	}
+	removeUpload := func() {
+		if err := a.filesBackend.RemoveFile(filePath); err != nil {
+			a.logger.Error("SaveFile: cannot remove stored upload", mlog.String("path", filePath), mlog.Err(err))
+		}
+	}
+
+	// the size of the upload is only known once it is stored
+	if err := a.checkStorageQuota(teamID, boardID, fileSize); err != nil {
+		removeUpload()
+		return err
+	}
+
+	fileInfo.Path = model.FileBlobPath(hex.EncodeToString(hasher.Sum(nil)))
+	fileInfo.Size = fileSize
+	if err := a.saveFileInfoWithBlob(fileInfo, teamID, boardID); err != nil {
+		removeUpload()
+		return err
+	}
 
-	err := a.store.SaveFileInfo(fileInfo)
+	if err := a.moveToFileBlob(filePath, fileInfo.Path); err != nil {
+		a.discardFileInfo(fileInfo)
+		removeUpload()
+		return fmt.Errorf("unable to store the file in the files storage: %w", err)
+	}
+	return nil
+}
+
+// saveFileInfoWithBlob saves the file info of an upload, referencing
+// the blob of its content. A blob can't be referenced while the file
+// garbage collection removes it, which takes a moment, so the save is
+// tried again meanwhile.
+func (a *App) saveFileInfoWithBlob(fileInfo *mm_model.FileInfo, teamID, boardID string) error {
+	var err error
+	for attempt := 0; attempt < fileBlobSaveAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(fileBlobRetryDelay)
+		}
+		err = a.store.SaveFileInfoWithUsage(fileInfo, teamID, boardID)
+		if !errors.Is(err, model.ErrFileBlobRemoving) {
+			return err
+		}
+	}
+	return err
+}
+
+// discardFileInfo deletes the file info of an upload that couldn't be
+// stored, releasing its blob and its storage usage.
+func (a *App) discardFileInfo(fileInfo *mm_model.FileInfo) {
+	if err := a.store.DeleteFileInfos([]string{fileInfo.Id}); err != nil {
+		a.logger.Error("SaveFile: cannot delete file info of failed upload", mlog.String("id", fileInfo.Id), mlog.Err(err))
+	}
+}
+
+// hashContent returns the SHA-256 hash of the content of a seeker and
+// its size. The seeker is rewound afterwards.
+func hashContent(seeker io.ReadSeeker) (string, int64, error) {
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, seeker)
 	if err != nil {
-		return "", err
+		return "", 0, err
+	}
+	if _, err = seeker.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
 	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
 
-	return newFileName, nil
+// writeFileBlob stores the content of a seekable upload in its blob,
+// unless the same content is already stored. The blob must be
+// referenced first.
+func (a *App) writeFileBlob(seeker io.ReadSeeker, blobPath string) error {
+	exists, err := a.filesBackend.FileExists(blobPath)
+	if err != nil || exists {
+		return err
+	}
+	if _, err = seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = a.filesBackend.WriteFile(seeker, blobPath)
+	return err
+}
+
+// moveToFileBlob moves a stored file to its blob, or removes it if the
+// same content is already stored. The blob must be referenced first.
+func (a *App) moveToFileBlob(filePath, blobPath string) error {
+	exists, err := a.filesBackend.FileExists(blobPath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return a.filesBackend.MoveFile(filePath, blobPath)
+	}
+
+	if err := a.filesBackend.RemoveFile(filePath); err != nil {
+		a.logger.Warn("moveToFileBlob: cannot remove duplicated file", mlog.String("path", filePath), mlog.Err(err))
+	}
+	return nil
+}
+
+// seekerSize returns the size of the content of a seeker, which is
+// rewound afterwards.
+func seekerSize(seeker io.Seeker) (int64, error) {
+	size, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return size, nil
 }
 
 func (a *App) GetFileInfo(filename string) (*mm_model.FileInfo, error) {
@@ -76,6 +315,9 @@ func (a *App) GetFile(teamID, rootID, fileName string) (*mm_model.FileInfo, file
 		a.logger.Error("GetFile: Failed to GetFilePath.", mlog.String("Team", teamID), mlog.String("board", rootID), mlog.String("filename", fileName), mlog.Err(err))
 		return nil, nil, err
 	}
+	if err := a.checkFileNotBlocked(fileInfo); err != nil {
+		return nil, nil, err
+	}
 
 	exists, err := a.filesBackend.FileExists(filePath)
 	if err != nil {
@@ -96,6 +338,55 @@ func (a *App) GetFile(teamID, rootID, fileName string) (*mm_model.FileInfo, file
 	return fileInfo, reader, nil
 }
 
+// GetFileRendition returns a rendition of a file. Files without the
+// rendition, like the ones that are not images, are returned as they
+// were uploaded.
+func (a *App) GetFileRendition(teamID, rootID, fileName string, rendition model.FileRendition) (*mm_model.FileInfo, filestore.ReadCloseSeeker, error) {
+	if rendition == model.FileRenditionOriginal {
+		return a.GetFile(teamID, rootID, fileName)
+	}
+
+	fileInfo, err := a.GetFileInfo(fileName)
+	if err != nil && !model.IsErrNotFound(err) {
+		return nil, nil, err
+	}
+	if fileInfo == nil || !fileInfo.HasPreviewImage {
+		return a.GetFile(teamID, rootID, fileName)
+	}
+	if err := a.checkFileNotBlocked(fileInfo); err != nil {
+		return nil, nil, err
+	}
+
+	filePath := fileInfo.ThumbnailPath
+	if rendition == model.FileRenditionPreview {
+		filePath = fileInfo.PreviewPath
+	}
+
+	exists, err := a.filesBackend.FileExists(filePath)
+	if err != nil {
+		a.logger.Error("GetFileRendition: Failed to check if rendition exists as path. ", mlog.String("Path", filePath), mlog.Err(err))
+		return nil, nil, err
+	}
+	if !exists {
+		a.logger.Warn("GetFileRendition: rendition not found, returning the original file", mlog.String("Path", filePath))
+		return a.GetFile(teamID, rootID, fileName)
+	}
+
+	reader, err := a.filesBackend.Reader(filePath)
+	if err != nil {
+		a.logger.Error("GetFileRendition: Failed to get file reader of rendition at path", mlog.String("Path", filePath), mlog.Err(err))
+		return nil, nil, err
+	}
+
+	renditionInfo := *fileInfo
+	renditionInfo.Path = filePath
+	renditionInfo.MimeType = renditionMimeType
+	// the size of the rendition is not recorded, the response gets it
+	// from the reader
+	renditionInfo.Size = 0
+	return &renditionInfo, reader, nil
+}
+
 func (a *App) GetFilePath(teamID, rootID, fileName string) (*mm_model.FileInfo, string, error) {
 	fileInfo, err := a.GetFileInfo(fileName)
 	if err != nil && !model.IsErrNotFound(err) {
@@ -183,21 +474,34 @@ func (a *App) MoveFile(channelID, teamID, boardID, filename string) error {
 	return nil
 }
 func (a *App) CopyAndUpdateCardFiles(boardID, userID string, blocks []*model.Block, asTemplate bool) error {
-	newFileNames, err := a.CopyCardFiles(boardID, blocks, asTemplate)
+	newFileNames, err := a.CopyCardFiles(boardID, userID, blocks, asTemplate)
 	if err != nil {
 		a.logger.Error("Could not copy files while duplicating board", mlog.String("BoardID", boardID), mlog.Err(err))
 	}
 
 	// blocks now has updated file ids for any blocks containing files.  We need to update the database for them.
+	if err := a.updateCopiedFileIDs(blocks, newFileNames, userID); err != nil {
+		return fmt.Errorf("could not patch file IDs while duplicating board %s: %w", boardID, err)
+	}
+	return nil
+}
+
+// updateCopiedFileIDs makes the blocks reference the copies of their files.
+// Blocks whose file could not be copied keep referencing the original.
+func (a *App) updateCopiedFileIDs(blocks []*model.Block, newFileNames map[string]string, userID string) error {
 	blockIDs := make([]string, 0)
 	blockPatches := make([]model.BlockPatch, 0)
 	for _, block := range blocks {
 		if block.Type == model.TypeImage || block.Type == model.TypeAttachment {
 			if fileID, ok := block.Fields["fileId"].(string); ok {
+				newFileName, copied := newFileNames[fileID]
+				if !copied {
+					continue
+				}
 				blockIDs = append(blockIDs, block.ID)
 				blockPatches = append(blockPatches, model.BlockPatch{
 					UpdatedFields: map[string]interface{}{
-						"fileId": newFileNames[fileID],
+						"fileId": newFileName,
 					},
 					DeletedFields: []string{"attachmentId"},
 				})
@@ -206,25 +510,40 @@ func (a *App) CopyAndUpdateCardFiles(boardID, userID string, blocks []*model.Blo
 	}
 	a.logger.Debug("Duplicate boards patching file IDs", mlog.Int("count", len(blockIDs)))
 
-	if len(blockIDs) != 0 {
-		patches := &model.BlockPatchBatch{
-			BlockIDs:     blockIDs,
-			BlockPatches: blockPatches,
-		}
-		if err := a.store.PatchBlocks(patches, userID); err != nil {
-			return fmt.Errorf("could not patch file IDs while duplicating board %s: %w", boardID, err)
-		}
+	if len(blockIDs) == 0 {
+		return nil
+	}
+	patches := &model.BlockPatchBatch{
+		BlockIDs:     blockIDs,
+		BlockPatches: blockPatches,
 	}
+	return a.store.PatchBlocks(patches, userID)
+}
 
-	return nil
+// cardFileID returns the id of the file of an image or attachment block, or an
+// empty string for the other blocks.
+func cardFileID(block *model.Block) string {
+	if block.Type != model.TypeImage && block.Type != model.TypeAttachment {
+		return ""
+	}
+	fileID, ok := block.Fields["fileId"].(string)
+	if !ok {
+		fileID, _ = block.Fields["attachmentId"].(string)
+	}
+	return fileID
 }
 
-func (a *App) CopyCardFiles(sourceBoardID string, copiedBlocks []*model.Block, asTemplate bool) (map[string]string, error) {
+func (a *App) CopyCardFiles(sourceBoardID, userID string, copiedBlocks []*model.Block, asTemplate bool) (map[string]string, error) {
 	// Images attached in cards have a path comprising the card's board ID.
 	// When we create a template from this board, we need to copy the files
 	// with the new board ID in path.
 	// Not doing so causing images in templates (and boards created from this
 	// template) to fail to load.
+	// The copies are added to the storage usage of the user copying them,
+	// but are not checked against the quotas so duplicating a board never
+	// loses its files. Files stored in blobs are not copied, their copies
+	// reference the same blob. Files that can't be copied are left out of
+	// the returned names.
 
 	// look up ID of source sourceBoard, which may be different than the blocks.
 	sourceBoard, err := a.GetBoard(sourceBoardID)
@@ -257,7 +576,7 @@ func (a *App) CopyCardFiles(sourceBoardID string, copiedBlocks []*model.Block, a
 			if block.BoardID != destBoard.ID {
 				destBoard, err = a.GetBoard(block.BoardID)
 				if err != nil {
-					return nil, fmt.Errorf("cannot fetch destination board %s for CopyCardFiles: %w", sourceBoardID, err)
+					return newFileNames, fmt.Errorf("cannot fetch destination board %s for CopyCardFiles: %w", sourceBoardID, err)
 				}
 			}
 		}
@@ -266,36 +585,83 @@ func (a *App) CopyCardFiles(sourceBoardID string, copiedBlocks []*model.Block, a
 		// depending on whether FileInfo table is used for the file.
 		fileInfo, sourceFilePath, err := a.GetFilePath(sourceBoard.TeamID, sourceBoard.ID, fileID)
 		if err != nil {
-			return nil, fmt.Errorf("cannot fetch destination board %s for CopyCardFiles: %w", sourceBoardID, err)
+			return newFileNames, fmt.Errorf("cannot fetch destination board %s for CopyCardFiles: %w", sourceBoardID, err)
 		}
 		destinationFilePath := getDestinationFilePath(asTemplate, destBoard.TeamID, destBoard.ID, destFilename)
 
+		sourceFileInfoID := ""
 		if fileInfo == nil {
 			fileInfo = model.NewFileInfo(destFilename)
+		} else {
+			sourceFileInfoID = fileInfo.Id
 		}
+		_, isBlob := model.FileBlobHash(sourceFilePath)
+		shareBlob := isBlob && !asTemplate
+
 		fileInfo.Id = getFileInfoID(fileInfoID)
-		fileInfo.Path = destinationFilePath
-		err = a.store.SaveFileInfo(fileInfo)
+		fileInfo.CreatorId = userID
+		if !shareBlob {
+			a.logger.Debug(
+				"Copying card file",
+				mlog.String("sourceFilePath", sourceFilePath),
+				mlog.String("destinationFilePath", destinationFilePath),
+			)
+
+			if err := a.filesBackend.CopyFile(sourceFilePath, destinationFilePath); err != nil {
+				a.logger.Error(
+					"CopyCardFiles failed to copy file",
+					mlog.String("sourceFilePath", sourceFilePath),
+					mlog.String("destinationFilePath", destinationFilePath),
+					mlog.Err(err),
+				)
+				continue
+			}
+
+			fileInfo.Path = destinationFilePath
+			if fileInfo.HasPreviewImage {
+				a.copyImageRenditions(fileInfo)
+			}
+		}
+		err = a.store.SaveFileInfoWithUsage(fileInfo, destBoard.TeamID, destBoard.ID)
 		if err != nil {
-			return nil, fmt.Errorf("CopyCardFiles: cannot create fileinfo: %w", err)
+			return newFileNames, fmt.Errorf("CopyCardFiles: cannot create fileinfo: %w", err)
+		}
+		if err = a.copyFileScanResult(sourceFileInfoID, fileInfo, destBoard.TeamID, destBoard.ID, destFilename); err != nil {
+			return newFileNames, fmt.Errorf("CopyCardFiles: cannot copy scan result: %w", err)
 		}
+		newFileNames[fileID] = destFilename
+	}
 
-		a.logger.Debug(
-			"Copying card file",
-			mlog.String("sourceFilePath", sourceFilePath),
-			mlog.String("destinationFilePath", destinationFilePath),
-		)
+	return newFileNames, nil
+}
 
-		if err := a.filesBackend.CopyFile(sourceFilePath, destinationFilePath); err != nil {
+// copyImageRenditions copies the renditions of an image to the
+// location of its copy, whose path has already been set on the file
+// info. If a rendition can't be copied, the copy is served without
+// renditions.
+func (a *App) copyImageRenditions(fileInfo *mm_model.FileInfo) {
+	renditions := []struct {
+		rendition model.FileRendition
+		path      *string
+	}{
+		{model.FileRenditionThumb, &fileInfo.ThumbnailPath},
+		{model.FileRenditionPreview, &fileInfo.PreviewPath},
+	}
+
+	for _, r := range renditions {
+		destinationPath := renditionPath(fileInfo.Path, r.rendition)
+		if err := a.filesBackend.CopyFile(*r.path, destinationPath); err != nil {
 			a.logger.Error(
-				"CopyCardFiles failed to copy file",
-				mlog.String("sourceFilePath", sourceFilePath),
-				mlog.String("destinationFilePath", destinationFilePath),
+				"CopyCardFiles failed to copy image rendition",
+				mlog.String("sourceFilePath", *r.path),
+				mlog.String("destinationFilePath", destinationPath),
 				mlog.Err(err),
 			)
+			fileInfo.HasPreviewImage = false
+			fileInfo.ThumbnailPath = ""
+			fileInfo.PreviewPath = ""
+			return
 		}
-		newFileNames[fileID] = destFilename
+		*r.path = destinationPath
 	}
-
-	return newFileNames, nil
 }
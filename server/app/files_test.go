@@ -1,13 +1,14 @@
 package app
 
 import (
+	"bytes"
 	"errors"
+	"image"
+	"image/png"
 	"io"
-	"os"
 	"path/filepath"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -189,18 +190,22 @@ func TestGetFileReader(t *testing.T) {
 
 func TestSaveFile(t *testing.T) {
 	th, _ := SetupTestHelper(t)
+	// the mocked reader has no content
+	emptyContentBlobPath := model.FileBlobPath("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
 	mockedReadCloseSeek := &mocks.ReadCloseSeeker{}
+	mockedReadCloseSeek.On("Read", mock.Anything).Return(0, io.EOF)
+	mockedReadCloseSeek.On("Seek", int64(0), io.SeekStart).Return(int64(0), nil)
+	mockedReadCloseSeek.On("Seek", int64(0), io.SeekEnd).Return(int64(10), nil)
+	th.Store.EXPECT().GetStorageQuota(gomock.Any(), gomock.Any()).Return(nil, model.NewErrNotFound("storage quota")).AnyTimes()
+	th.Store.EXPECT().GetUploadPolicy("1").Return(nil, model.NewErrNotFound("upload policy")).AnyTimes()
 	t.Run("should save file to file store using file backend", func(t *testing.T) {
 		fileName := "temp-file-name.txt"
 		mockedFileBackend := &mocks.FileBackend{}
 		th.App.filesBackend = mockedFileBackend
-		th.Store.EXPECT().SaveFileInfo(gomock.Any()).Return(nil)
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "1", gomock.Any()).Return(nil)
 
 		writeFileFunc := func(reader io.Reader, path string) int64 {
-			paths := strings.Split(path, string(os.PathSeparator))
-			assert.Equal(t, "boards", paths[0])
-			assert.Equal(t, time.Now().Format("20060102"), paths[1])
-			fileName = paths[2]
+			assert.Equal(t, emptyContentBlobPath, path)
 			return int64(10)
 		}
 
@@ -208,9 +213,10 @@ func TestSaveFile(t *testing.T) {
 			return nil
 		}
 
+		mockedFileBackend.On("FileExists", emptyContentBlobPath).Return(false, nil)
 		mockedFileBackend.On("WriteFile", mockedReadCloseSeek, mock.Anything).Return(writeFileFunc, writeFileErrorFunc)
-		actual, err := th.App.SaveFile(mockedReadCloseSeek, "1", testBoardID, fileName, false)
-		assert.Equal(t, fileName, actual)
+		actual, err := th.App.SaveFile(mockedReadCloseSeek, "1", testBoardID, "user-id", fileName, false)
+		assert.Equal(t, ".txt", filepath.Ext(actual))
 		assert.Nil(t, err)
 	})
 
@@ -218,13 +224,10 @@ func TestSaveFile(t *testing.T) {
 		fileName := "temp-file-name.jpeg"
 		mockedFileBackend := &mocks.FileBackend{}
 		th.App.filesBackend = mockedFileBackend
-		th.Store.EXPECT().SaveFileInfo(gomock.Any()).Return(nil)
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "1", gomock.Any()).Return(nil)
 
 		writeFileFunc := func(reader io.Reader, path string) int64 {
-			paths := strings.Split(path, string(os.PathSeparator))
-			assert.Equal(t, "boards", paths[0])
-			assert.Equal(t, time.Now().Format("20060102"), paths[1])
-			assert.Equal(t, "jpg", strings.Split(paths[2], ".")[1])
+			assert.Equal(t, emptyContentBlobPath, path)
 			return int64(10)
 		}
 
@@ -232,10 +235,11 @@ func TestSaveFile(t *testing.T) {
 			return nil
 		}
 
+		mockedFileBackend.On("FileExists", emptyContentBlobPath).Return(false, nil)
 		mockedFileBackend.On("WriteFile", mockedReadCloseSeek, mock.Anything).Return(writeFileFunc, writeFileErrorFunc)
-		actual, err := th.App.SaveFile(mockedReadCloseSeek, "1", "test-board-id", fileName, false)
+		actual, err := th.App.SaveFile(mockedReadCloseSeek, "1", "test-board-id", "user-id", fileName, false)
 		assert.Nil(t, err)
-		assert.NotNil(t, actual)
+		assert.Equal(t, ".jpg", filepath.Ext(actual))
 	})
 
 	t.Run("should return error when fileBackend.WriteFile returns error", func(t *testing.T) {
@@ -244,11 +248,20 @@ func TestSaveFile(t *testing.T) {
 		th.App.filesBackend = mockedFileBackend
 		mockedError := &TestError{}
 
+		// the file info referencing the blob is saved first, and
+		// deleted when the blob can't be stored
+		var savedID string
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "1", gomock.Any()).DoAndReturn(func(fileInfo *mm_model.FileInfo, _, _ string) error {
+			savedID = fileInfo.Id
+			return nil
+		})
+		th.Store.EXPECT().DeleteFileInfos(gomock.Any()).DoAndReturn(func(ids []string) error {
+			assert.Equal(t, []string{savedID}, ids)
+			return nil
+		})
+
 		writeFileFunc := func(reader io.Reader, path string) int64 {
-			paths := strings.Split(path, string(os.PathSeparator))
-			assert.Equal(t, "boards", paths[0])
-			assert.Equal(t, time.Now().Format("20060102"), paths[1])
-			assert.Equal(t, "jpg", strings.Split(paths[2], ".")[1])
+			assert.Equal(t, emptyContentBlobPath, path)
 			return int64(10)
 		}
 
@@ -256,13 +269,145 @@ func TestSaveFile(t *testing.T) {
 			return mockedError
 		}
 
+		mockedFileBackend.On("FileExists", emptyContentBlobPath).Return(false, nil)
 		mockedFileBackend.On("WriteFile", mockedReadCloseSeek, mock.Anything).Return(writeFileFunc, writeFileErrorFunc)
-		actual, err := th.App.SaveFile(mockedReadCloseSeek, "1", "test-board-id", fileName, false)
+		actual, err := th.App.SaveFile(mockedReadCloseSeek, "1", "test-board-id", "user-id", fileName, false)
 		assert.Equal(t, "", actual)
 		assert.Equal(t, "unable to store the file in the files storage: Mocked File backend error", err.Error())
 	})
 }
 
+func TestSaveImageFile(t *testing.T) {
+	th, _ := SetupTestHelper(t)
+	th.Store.EXPECT().GetStorageQuota(gomock.Any(), gomock.Any()).Return(nil, model.NewErrNotFound("storage quota")).AnyTimes()
+	th.Store.EXPECT().GetUploadPolicy("1").Return(nil, model.NewErrNotFound("upload policy")).AnyTimes()
+
+	img := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+
+	t.Run("should record the image metadata and store its renditions", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		written := map[string]bool{}
+		writeFileFunc := func(reader io.Reader, path string) int64 {
+			written[path] = true
+			data, _ := io.ReadAll(reader)
+			return int64(len(data))
+		}
+		writeFileErrorFunc := func(reader io.Reader, filePath string) error {
+			return nil
+		}
+		mockedFileBackend.On("FileExists", mock.Anything).Return(false, nil)
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(writeFileFunc, writeFileErrorFunc)
+
+		var savedFileInfo *mm_model.FileInfo
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "1", testBoardID).DoAndReturn(func(fileInfo *mm_model.FileInfo, teamID, boardID string) error {
+			savedFileInfo = fileInfo
+			return nil
+		})
+
+		// the content is an image regardless of the extension
+		_, err := th.App.SaveFile(bytes.NewReader(buf.Bytes()), "1", testBoardID, "user-id", "image.bin", false)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "image/png", savedFileInfo.MimeType)
+		assert.Equal(t, 800, savedFileInfo.Width)
+		assert.Equal(t, 600, savedFileInfo.Height)
+		assert.True(t, savedFileInfo.HasPreviewImage)
+		assert.Equal(t, strings.TrimSuffix(savedFileInfo.Path, ".bin")+"_thumb.jpg", savedFileInfo.ThumbnailPath)
+		assert.Equal(t, strings.TrimSuffix(savedFileInfo.Path, ".bin")+"_preview.jpg", savedFileInfo.PreviewPath)
+		assert.True(t, written[savedFileInfo.Path])
+		assert.True(t, written[savedFileInfo.ThumbnailPath])
+		assert.True(t, written[savedFileInfo.PreviewPath])
+	})
+
+	t.Run("should save a file that can't be decoded without renditions", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", mock.Anything).Return(false, nil)
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(int64(10), nil)
+
+		var savedFileInfo *mm_model.FileInfo
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "1", testBoardID).DoAndReturn(func(fileInfo *mm_model.FileInfo, teamID, boardID string) error {
+			savedFileInfo = fileInfo
+			return nil
+		})
+
+		truncated := buf.Bytes()[:100]
+		_, err := th.App.SaveFile(bytes.NewReader(truncated), "1", testBoardID, "user-id", "image.png", false)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "image/png", savedFileInfo.MimeType)
+		assert.False(t, savedFileInfo.HasPreviewImage)
+		assert.Empty(t, savedFileInfo.ThumbnailPath)
+		mockedFileBackend.AssertNumberOfCalls(t, "WriteFile", 1)
+	})
+}
+
+func TestGetFileRendition(t *testing.T) {
+	th, _ := SetupTestHelper(t)
+	th.Store.EXPECT().GetFileScanResult(gomock.Any()).Return(nil, model.NewErrNotFound("file scan result")).AnyTimes()
+	imageFileInfo := &mm_model.FileInfo{
+		Id:              "fileInfoID",
+		Path:            "/path/to/file/fileName.png",
+		MimeType:        "image/png",
+		HasPreviewImage: true,
+		ThumbnailPath:   "/path/to/file/fileName_thumb.jpg",
+		PreviewPath:     "/path/to/file/fileName_preview.jpg",
+	}
+
+	t.Run("should return the rendition of an image", func(t *testing.T) {
+		th.Store.EXPECT().GetFileInfo("fileInfoID").Return(imageFileInfo, nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedReadCloseSeek := &mocks.ReadCloseSeeker{}
+		mockedFileBackend.On("FileExists", imageFileInfo.ThumbnailPath).Return(true, nil)
+		mockedFileBackend.On("Reader", imageFileInfo.ThumbnailPath).Return(mockedReadCloseSeek, nil)
+
+		fileInfo, reader, err := th.App.GetFileRendition("teamID", "boardID", "7fileInfoID.png", model.FileRenditionThumb)
+		assert.NoError(t, err)
+		assert.Equal(t, mockedReadCloseSeek, reader)
+		assert.Equal(t, "image/jpeg", fileInfo.MimeType)
+		assert.Equal(t, imageFileInfo.ThumbnailPath, fileInfo.Path)
+		assert.Equal(t, "image/png", imageFileInfo.MimeType)
+	})
+
+	t.Run("should return the original file if it has no renditions", func(t *testing.T) {
+		fileInfo := &mm_model.FileInfo{Id: "fileInfoID", Path: testPath}
+		th.Store.EXPECT().GetFileInfo("fileInfoID").Return(fileInfo, nil).Times(2)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedReadCloseSeek := &mocks.ReadCloseSeeker{}
+		mockedFileBackend.On("FileExists", testPath).Return(true, nil)
+		mockedFileBackend.On("Reader", testPath).Return(mockedReadCloseSeek, nil)
+
+		returnedFileInfo, reader, err := th.App.GetFileRendition("teamID", "boardID", "7fileInfoID.txt", model.FileRenditionPreview)
+		assert.NoError(t, err)
+		assert.Equal(t, mockedReadCloseSeek, reader)
+		assert.Equal(t, fileInfo, returnedFileInfo)
+	})
+
+	t.Run("should return the original file if the rendition is missing", func(t *testing.T) {
+		th.Store.EXPECT().GetFileInfo("fileInfoID").Return(imageFileInfo, nil).Times(2)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedReadCloseSeek := &mocks.ReadCloseSeeker{}
+		mockedFileBackend.On("FileExists", imageFileInfo.PreviewPath).Return(false, nil)
+		mockedFileBackend.On("FileExists", imageFileInfo.Path).Return(true, nil)
+		mockedFileBackend.On("Reader", imageFileInfo.Path).Return(mockedReadCloseSeek, nil)
+
+		fileInfo, reader, err := th.App.GetFileRendition("teamID", "boardID", "7fileInfoID.png", model.FileRenditionPreview)
+		assert.NoError(t, err)
+		assert.Equal(t, mockedReadCloseSeek, reader)
+		assert.Equal(t, imageFileInfo, fileInfo)
+	})
+}
+
 func TestGetFileInfo(t *testing.T) {
 	th, _ := SetupTestHelper(t)
 
@@ -310,6 +455,7 @@ func TestGetFileInfo(t *testing.T) {
 
 func TestGetFile(t *testing.T) {
 	th, _ := SetupTestHelper(t)
+	th.Store.EXPECT().GetFileScanResult(gomock.Any()).Return(nil, model.NewErrNotFound("file scan result")).AnyTimes()
 	t.Run("happy path, no errors", func(t *testing.T) {
 		th.Store.EXPECT().GetFileInfo("fileInfoID").Return(&mm_model.FileInfo{
 			Id:   "fileInfoID",
@@ -432,7 +578,7 @@ func TestCopyCard(t *testing.T) {
 	}
 	t.Run("Board doesn't exist", func(t *testing.T) {
 		th.Store.EXPECT().GetBoard("boardID").Return(nil, errDummy)
-		_, err := th.App.CopyCardFiles("boardID", []*model.Block{}, false)
+		_, err := th.App.CopyCardFiles("boardID", "user-id", []*model.Block{}, false)
 		assert.Error(t, err)
 	})
 
@@ -446,13 +592,14 @@ func TestCopyCard(t *testing.T) {
 			IsTemplate: false,
 		}, nil)
 		th.Store.EXPECT().GetFileInfo("fileName").Return(fileInfo, nil)
-		th.Store.EXPECT().SaveFileInfo(fileInfo).Return(nil)
+		th.Store.EXPECT().SaveFileInfoWithUsage(fileInfo, "", "boardID").Return(nil)
+		th.Store.EXPECT().GetFileScanResult("imageBlock").Return(nil, model.NewErrNotFound("file scan result imageBlock"))
 
 		mockedFileBackend := &mocks.FileBackend{}
 		th.App.filesBackend = mockedFileBackend
 		mockedFileBackend.On("CopyFile", mock.Anything, mock.Anything).Return(nil)
 
-		updatedFileNames, err := th.App.CopyCardFiles("boardID", []*model.Block{imageBlock}, false)
+		updatedFileNames, err := th.App.CopyCardFiles("boardID", "user-id", []*model.Block{imageBlock}, false)
 		assert.NoError(t, err)
 		assert.Equal(t, "7fileName.jpg", imageBlock.Fields["fileId"])
 		assert.NotNil(t, updatedFileNames["7fileName.jpg"])
@@ -484,13 +631,14 @@ func TestCopyCard(t *testing.T) {
 			IsTemplate: false,
 		}, nil)
 		th.Store.EXPECT().GetFileInfo("fileName").Return(fileInfo, nil)
-		th.Store.EXPECT().SaveFileInfo(fileInfo).Return(nil)
+		th.Store.EXPECT().SaveFileInfoWithUsage(fileInfo, "", "boardID").Return(nil)
+		th.Store.EXPECT().GetFileScanResult("attachmentBlock").Return(nil, model.NewErrNotFound("file scan result attachmentBlock"))
 
 		mockedFileBackend := &mocks.FileBackend{}
 		th.App.filesBackend = mockedFileBackend
 		mockedFileBackend.On("CopyFile", mock.Anything, mock.Anything).Return(nil)
 
-		updatedFileNames, err := th.App.CopyCardFiles("boardID", []*model.Block{attachmentBlock}, false)
+		updatedFileNames, err := th.App.CopyCardFiles("boardID", "user-id", []*model.Block{attachmentBlock}, false)
 		assert.NoError(t, err)
 		assert.NotNil(t, updatedFileNames[imageBlock.Fields["fileId"].(string)])
 	})
@@ -501,13 +649,13 @@ func TestCopyCard(t *testing.T) {
 			IsTemplate: false,
 		}, nil)
 		th.Store.EXPECT().GetFileInfo(gomock.Any()).Return(nil, nil)
-		th.Store.EXPECT().SaveFileInfo(gomock.Any()).Return(nil)
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "", "boardID").Return(nil)
 
 		mockedFileBackend := &mocks.FileBackend{}
 		th.App.filesBackend = mockedFileBackend
 		mockedFileBackend.On("CopyFile", mock.Anything, mock.Anything).Return(nil)
 
-		updatedFileNames, err := th.App.CopyCardFiles("boardID", []*model.Block{imageBlock}, false)
+		updatedFileNames, err := th.App.CopyCardFiles("boardID", "user-id", []*model.Block{imageBlock}, false)
 		assert.NoError(t, err)
 		assert.NotNil(t, imageBlock.Fields["fileId"].(string))
 		assert.NotNil(t, updatedFileNames[imageBlock.Fields["fileId"].(string)])
@@ -541,7 +689,8 @@ func TestCopyAndUpdateCardFiles(t *testing.T) {
 			IsTemplate: false,
 		}, nil)
 		th.Store.EXPECT().GetFileInfo("fileName").Return(fileInfo, nil)
-		th.Store.EXPECT().SaveFileInfo(fileInfo).Return(nil)
+		th.Store.EXPECT().SaveFileInfoWithUsage(fileInfo, "", "boardID").Return(nil)
+		th.Store.EXPECT().GetFileScanResult("imageBlock").Return(nil, model.NewErrNotFound("file scan result imageBlock"))
 		th.Store.EXPECT().PatchBlocks(gomock.Any(), "userID").Return(nil)
 
 		mockedFileBackend := &mocks.FileBackend{}
@@ -553,4 +702,24 @@ func TestCopyAndUpdateCardFiles(t *testing.T) {
 
 		assert.NotEqual(t, testPath, imageBlock.Fields["fileId"])
 	})
+
+	t.Run("Board exists, image block, file copy fails", func(t *testing.T) {
+		fileInfo := &mm_model.FileInfo{
+			Id:   "imageBlock",
+			Path: testPath,
+		}
+		th.Store.EXPECT().GetBoard("boardID").Return(&model.Board{
+			ID:         "boardID",
+			IsTemplate: false,
+		}, nil)
+		th.Store.EXPECT().GetFileInfo("fileName").Return(fileInfo, nil)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("CopyFile", mock.Anything, mock.Anything).Return(errDummy)
+
+		// the block is not patched, it keeps referencing the original file.
+		err := th.App.CopyAndUpdateCardFiles("boardID", "userID", []*model.Block{imageBlock}, false)
+		assert.NoError(t, err)
+	})
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/golang/mock/gomock"
 
 	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/config"
 	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/services/permissions/mmpermissions"
@@ -72,3 +73,49 @@ func SetupTestHelper(t *testing.T) (*TestHelper, func()) {
 		API:          mockAPI,
 	}, tearDown
 }
+
+// makeTestBoard returns a board of the team-id team with a status, an
+// owner and an estimate card property. The ids of the properties and
+// of their options start with prefix, so the properties of two boards
+// can be told apart.
+func makeTestBoard(id, prefix string) *model.Board {
+	return &model.Board{
+		ID:     id,
+		TeamID: "team-id",
+		Type:   model.BoardTypeOpen,
+		CardProperties: []map[string]interface{}{
+			{
+				"id":   prefix + "status",
+				"name": "Status",
+				"type": "select",
+				"options": []interface{}{
+					map[string]interface{}{"id": prefix + "todo", "value": "To Do", "color": "propColorGray"},
+					map[string]interface{}{"id": prefix + "doing", "value": "Doing", "color": "propColorYellow"},
+					map[string]interface{}{"id": prefix + "done", "value": "Done", "color": "propColorGreen"},
+				},
+			},
+			{"id": prefix + "owner", "name": "Owner", "type": "person"},
+			{"id": prefix + "estimate", "name": "Estimate", "type": "number"},
+		},
+	}
+}
+
+// setTestWIPLimit limits the cards of a test board with the doing
+// status to two.
+func setTestWIPLimit(board *model.Board, mode model.WIPLimitMode) {
+	doing := board.CardProperties[0]["options"].([]interface{})[1].(map[string]interface{})
+	doing["wipLimit"] = float64(2)
+	doing["wipLimitMode"] = string(mode)
+}
+
+// makeTestCard returns a card of the board-id test board with a status.
+func makeTestCard(id, status string) *model.Block {
+	return &model.Block{
+		ID:      id,
+		BoardID: "board-id",
+		Type:    model.TypeCard,
+		Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"status": status},
+		},
+	}
+}
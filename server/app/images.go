@@ -0,0 +1,282 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	// register the decoders of the image formats with renditions
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/mattermost/focalboard/server/model"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// sniffLen is the number of bytes used to detect the MIME type of
+	// an upload.
+	sniffLen = 512
+
+	// maxImagePixels is the largest image, in pixels, that renditions
+	// are generated for, as the whole image is decoded in memory.
+	maxImagePixels = 7680 * 4320
+
+	thumbMaxWidth    = 400
+	thumbMaxHeight   = 400
+	previewMaxWidth  = 1920
+	previewMaxHeight = 1920
+
+	renditionQuality  = 85
+	renditionMimeType = "image/jpeg"
+)
+
+// imageMimeTypes are the image types renditions can be generated for.
+var imageMimeTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+const (
+	// peOffsetField is the offset of the field of the DOS header of
+	// Windows executables holding the offset of their PE header.
+	peOffsetField = 0x3c
+	peMimeType    = "application/x-msdownload"
+)
+
+// executableSignatures are the magic bytes of executables, which the
+// content sniffing of net/http doesn't recognize. Windows executables
+// are recognized by their PE header, as their two bytes DOS signature
+// starts ordinary text too.
+var executableSignatures = []struct {
+	prefix   string
+	mimeType string
+}{
+	{"\x7fELF", "application/x-executable"},
+	{"\xfe\xed\xfa\xce", "application/x-mach-binary"},
+	{"\xfe\xed\xfa\xcf", "application/x-mach-binary"},
+	{"\xcf\xfa\xed\xfe", "application/x-mach-binary"},
+	{"\xce\xfa\xed\xfe", "application/x-mach-binary"},
+}
+
+// detectMimeType detects the MIME type of a file from its first bytes,
+// falling back to the one of its extension if the content is not
+// recognized. The reader is rewound afterwards.
+func detectMimeType(reader io.ReadSeeker, extension string) (string, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return sniffMimeType(head[:n], extension), nil
+}
+
+// sniffMimeType detects the MIME type of a file from its first bytes,
+// falling back to the one of its extension if the content is not
+// recognized.
+func sniffMimeType(head []byte, extension string) string {
+	if isPortableExecutable(head) {
+		return peMimeType
+	}
+	for _, signature := range executableSignatures {
+		if bytes.HasPrefix(head, []byte(signature.prefix)) {
+			return signature.mimeType
+		}
+	}
+
+	mimeType := http.DetectContentType(head)
+	byExtension := mime.TypeByExtension(extension)
+	isGeneric := mimeType == "application/octet-stream" || strings.HasPrefix(mimeType, "text/plain")
+	if isGeneric && byExtension != "" {
+		return byExtension
+	}
+	return mimeType
+}
+
+// isPortableExecutable checks if the first bytes of a file are the
+// DOS header of a Windows executable pointing to its PE header. The PE
+// header must be within the sniffed bytes, as it is in executables
+// produced by common linkers.
+func isPortableExecutable(head []byte) bool {
+	if len(head) < peOffsetField+4 || !bytes.HasPrefix(head, []byte("MZ")) {
+		return false
+	}
+	offset := int(binary.LittleEndian.Uint32(head[peOffsetField:]))
+	if offset < peOffsetField+4 || offset > len(head)-4 {
+		return false
+	}
+	return bytes.Equal(head[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+// renditionPath returns where a rendition of a file is stored, next to
+// the original.
+func renditionPath(path string, rendition model.FileRendition) string {
+	return fmt.Sprintf("%s_%s.jpg", strings.TrimSuffix(path, filepath.Ext(path)), rendition)
+}
+
+// imageRendition is an encoded rendition of an image, to store at its
+// path.
+type imageRendition struct {
+	path string
+	data *bytes.Buffer
+}
+
+// renderImageRenditions records the dimensions of an uploaded image
+// and encodes its thumbnail and preview renditions, recording where
+// they are stored next to the file. The file is usable without
+// renditions, so images that can't be decoded are only logged.
+func (a *App) renderImageRenditions(reader io.ReadSeeker, fileInfo *mm_model.FileInfo) []imageRendition {
+	if !imageMimeTypes[fileInfo.MimeType] {
+		return nil
+	}
+	renditions, err := encodeImageRenditions(reader, fileInfo)
+	if err != nil {
+		a.logger.Warn("SaveFile: cannot generate image renditions",
+			mlog.String("path", fileInfo.Path),
+			mlog.Err(err),
+		)
+		return nil
+	}
+	return renditions
+}
+
+// writeImageRenditions stores the renditions of an image. Renditions
+// that are missing are served as the original file, so failures are
+// only logged.
+func (a *App) writeImageRenditions(renditions []imageRendition) {
+	for _, r := range renditions {
+		if _, err := a.filesBackend.WriteFile(r.data, r.path); err != nil {
+			a.logger.Warn("SaveFile: unable to store image rendition in the files storage",
+				mlog.String("path", r.path),
+				mlog.Err(err),
+			)
+		}
+	}
+}
+
+func encodeImageRenditions(reader io.ReadSeeker, fileInfo *mm_model.FileInfo) ([]imageRendition, error) {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image config: %w", err)
+	}
+	fileInfo.Width = config.Width
+	fileInfo.Height = config.Height
+
+	if config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("image of %dx%d is too large for renditions", config.Width, config.Height)
+	}
+
+	if _, err = reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+	if _, err = reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	sizes := []struct {
+		rendition model.FileRendition
+		maxWidth  int
+		maxHeight int
+	}{
+		{model.FileRenditionThumb, thumbMaxWidth, thumbMaxHeight},
+		{model.FileRenditionPreview, previewMaxWidth, previewMaxHeight},
+	}
+
+	renditions := []imageRendition{}
+	for _, size := range sizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, fitImage(img, size.maxWidth, size.maxHeight), &jpeg.Options{Quality: renditionQuality}); err != nil {
+			return nil, fmt.Errorf("cannot encode %s rendition: %w", size.rendition, err)
+		}
+		renditions = append(renditions, imageRendition{
+			path: renditionPath(fileInfo.Path, size.rendition),
+			data: &buf,
+		})
+	}
+
+	fileInfo.ThumbnailPath = renditions[0].path
+	fileInfo.PreviewPath = renditions[1].path
+	fileInfo.HasPreviewImage = true
+	return renditions, nil
+}
+
+// fitImage scales an image down to fit in the given bounds, keeping
+// its aspect ratio, and flattens it on a white background as the
+// renditions have no transparency.
+func fitImage(src image.Image, maxWidth, maxHeight int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxWidth {
+		height = max(1, height*maxWidth/width)
+		width = maxWidth
+	}
+	if height > maxHeight {
+		width = max(1, width*maxHeight/height)
+		height = maxHeight
+	}
+
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return flat
+	}
+	return downscale(flat, width, height)
+}
+
+// downscale resizes an image to smaller dimensions, each pixel being
+// the average of the source pixels it covers.
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max(y0+1, (y+1)*srcHeight/height)
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max(x0+1, (x+1)*srcWidth/width)
+
+			var r, g, b, count int
+			for sy := y0; sy < y1; sy++ {
+				offset := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[offset])
+					g += int(src.Pix[offset+1])
+					b += int(src.Pix[offset+2])
+					offset += 4
+					count++
+				}
+			}
+
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / count)
+			dst.Pix[i+1] = uint8(g / count)
+			dst.Pix[i+2] = uint8(b / count)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectMimeType(t *testing.T) {
+	testCases := []struct {
+		name      string
+		content   []byte
+		extension string
+		expected  string
+	}{
+		{"png content", []byte("\x89PNG\r\n\x1a\nrest"), ".txt", "image/png"},
+		{"html content with an image extension", []byte("<html><body></body></html>"), ".png", "text/html; charset=utf-8"},
+		{"unknown content", []byte{0x00, 0x01, 0x02}, ".pdf", "application/pdf"},
+		{"unknown content and extension", []byte{0x00, 0x01, 0x02}, ".unknown", "application/octet-stream"},
+		{"empty file", []byte{}, ".csv", "text/csv; charset=utf-8"},
+		{"windows executable", testPortableExecutable(), ".png", "application/x-msdownload"},
+		{"text starting with the dos signature", []byte("MZ notes, with more text"), ".txt", "text/plain; charset=utf-8"},
+		{"dos signature without pe header", append([]byte("MZ"), make([]byte, 200)...), ".bin", "application/octet-stream"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := bytes.NewReader(tc.content)
+			mimeType, err := detectMimeType(reader, tc.extension)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, mimeType)
+
+			// the reader is rewound
+			assert.Equal(t, int64(len(tc.content)), int64(reader.Len()))
+		})
+	}
+}
+
+func TestRenditionPath(t *testing.T) {
+	assert.Equal(t, "boards/20221012/7abc_thumb.jpg", renditionPath("boards/20221012/7abc.png", model.FileRenditionThumb))
+	assert.Equal(t, "team/board/7abc_preview.jpg", renditionPath("team/board/7abc", model.FileRenditionPreview))
+}
+
+func TestFitImage(t *testing.T) {
+	t.Run("should scale down keeping the aspect ratio", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+		fitted := fitImage(img, 400, 400)
+		assert.Equal(t, 400, fitted.Bounds().Dx())
+		assert.Equal(t, 200, fitted.Bounds().Dy())
+
+		img = image.NewRGBA(image.Rect(0, 0, 300, 900))
+		fitted = fitImage(img, 400, 400)
+		assert.Equal(t, 133, fitted.Bounds().Dx())
+		assert.Equal(t, 400, fitted.Bounds().Dy())
+	})
+
+	t.Run("should keep small images at their size", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(10, 10, 110, 60))
+		fitted := fitImage(img, 400, 400)
+		assert.Equal(t, image.Rect(0, 0, 100, 50), fitted.Bounds())
+	})
+
+	t.Run("should average the pixels and flatten transparency", func(t *testing.T) {
+		// red on the top half, blue on the bottom one
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				if y < 2 {
+					img.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+				} else {
+					img.Set(x, y, color.RGBA{B: 0xff, A: 0xff})
+				}
+			}
+		}
+		fitted := fitImage(img, 2, 2)
+		assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, fitted.At(0, 0))
+		assert.Equal(t, color.RGBA{B: 0xff, A: 0xff}, fitted.At(1, 1))
+
+		fitted = fitImage(img, 1, 1)
+		assert.Equal(t, color.RGBA{R: 0x7f, B: 0x7f, A: 0xff}, fitted.At(0, 0))
+
+		transparent := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		fitted = fitImage(transparent, 2, 2)
+		assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, fitted.At(0, 0))
+	})
+}
+
+// testPortableExecutable returns the headers of a Windows executable.
+func testPortableExecutable() []byte {
+	head := make([]byte, 0x84)
+	copy(head, "MZ")
+	binary.LittleEndian.PutUint32(head[0x3c:], 0x80)
+	copy(head[0x80:], "PE\x00\x00")
+	return head
+}
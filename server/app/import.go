@@ -9,6 +9,7 @@ import (
 	"io"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/krolaw/zipstream"
@@ -16,6 +17,7 @@ import (
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/utils"
 
+	mm_model "github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 )
 
@@ -30,36 +32,78 @@ var (
 	errSizeLimitExceeded = errors.New("size limit exceeded")
 )
 
+// boardImport is the state of the import of a board file of an archive.
+type boardImport struct {
+	report *model.ArchiveImportBoard
+
+	// boards are the boards created from the file, empty for dry runs
+	// and files that could not be imported
+	boards []*model.Board
+
+	// pending are the boards and blocks read from the file, with their
+	// new IDs, until they are created along with their files
+	pending *model.BoardsAndBlocks
+
+	// members are the members of the archive to add to the boards
+	members []*model.BoardMember
+
+	// savedFiles are the files saved for the pending boards, and
+	// fileNames maps their names in the archive to their new names
+	savedFiles []*mm_model.FileInfo
+	fileNames  map[string]string
+
+	// fileRefs maps the files referenced by image and attachment blocks
+	// to the line of the first block referencing them
+	fileRefs map[string]int
+
+	// files are the names of the files of the board found in the archive
+	files map[string]bool
+}
+
 // ImportArchive imports an archive containing zero or more boards, plus all
 // associated content, including cards, content blocks, views, and images.
 //
 // Archives are ZIP files containing a `version.json` file and zero or more
-// directories, each containing a `board.jsonl` and zero or more image files.
-func (a *App) ImportArchive(r io.Reader, opt model.ImportArchiveOptions) error {
+// directories, each containing a `board.jsonl`, an optional `history.jsonl`
+// that is not imported, and zero or more image files.
+//
+// Each board is imported entirely or not at all: a board with invalid lines is
+// skipped, and the files of a board are saved before the board, its blocks and
+// its members are created in one transaction, which are discarded if one of
+// them cannot be saved, while the other boards are still imported. The
+// problems are listed in the returned report. With opt.DryRun the archive is
+// only validated. An error is returned, with the report of the boards read so
+// far, if the archive itself cannot be read; the board being read is then
+// discarded.
+func (a *App) ImportArchive(r io.Reader, opt model.ImportArchiveOptions) (*model.ArchiveImportReport, error) {
+	report := model.NewArchiveImportReport(opt.DryRun)
+
 	// peek at the first bytes to see if this is a legacy archive format
 	br := bufio.NewReader(r)
 	peek, err := br.Peek(len(legacyFileBegin))
 	if err == nil && string(peek) == legacyFileBegin {
 		a.logger.Debug("importing legacy archive")
-		_, errImport := a.ImportBoardJSONL(br, opt)
-
-		return errImport
+		imp := a.importBoardJSONL(br, "", opt, report)
+		a.commitBoardImport(imp, opt, report)
+		return report, nil
 	}
 
 	zr := zipstream.NewReader(br)
 
-	boardMap := make(map[string]*model.Board) // maps old board ids to new
-	fileMap := make(map[string]string)        // maps old fileIds to new
+	var imports []*boardImport
+	importMap := make(map[string]*boardImport) // maps old board ids to their import
+
+	// the board being read waits for its files, which follow it in the
+	// archive, before being created
+	var pending *boardImport
 
 	for {
 		hdr, err := zr.Next()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				a.fixImagesAttachments(boardMap, fileMap, opt.TeamID, opt.ModifiedBy)
-				a.logger.Debug("import archive - done", mlog.Int("boards_imported", len(boardMap)))
-				return nil
+				break
 			}
-			return err
+			return report, a.abortArchiveImport(pending, fmt.Errorf("cannot read archive: %w", err))
 		}
 
 		dir, filename := filepath.Split(hdr.Name)
@@ -69,32 +113,46 @@ func (a *App) ImportArchive(r io.Reader, opt model.ImportArchiveOptions) error {
 		case "version.json":
 			ver, errVer := parseVersionFile(zr)
 			if errVer != nil {
-				return errVer
+				return report, a.abortArchiveImport(pending, errVer)
 			}
 			if ver != archiveVersion {
-				return model.NewErrUnsupportedArchiveVersion(ver, archiveVersion)
+				return report, a.abortArchiveImport(pending, model.NewErrUnsupportedArchiveVersion(ver, archiveVersion))
 			}
 		case "board.jsonl":
-			board, err := a.ImportBoardJSONL(zr, opt)
-			if err != nil {
-				return fmt.Errorf("cannot import board %s: %w", dir, err)
-			}
-			boardMap[dir] = board
+			a.commitBoardImport(pending, opt, report)
+			imp := a.importBoardJSONL(zr, hdr.Name, opt, report)
+			imports = append(imports, imp)
+			importMap[dir] = imp
+			pending = imp
+		case "history.jsonl":
+			// the previous versions of the board are not imported
+			continue
 		default:
 			// import file/image;  dir is the old board id
-			board, ok := boardMap[dir]
+			imp, ok := importMap[dir]
 			if !ok {
-				a.logger.Warn("skipping orphan image in archive",
-					mlog.String("dir", dir),
-					mlog.String("filename", filename),
-				)
+				report.AddWarning(hdr.Name, 0, "file outside of a board directory, skipped")
 				continue
 			}
-			newFileName, err := a.SaveFile(zr, opt.TeamID, board.ID, filename, board.IsTemplate)
+			if len(imp.boards) > 0 {
+				report.AddWarning(hdr.Name, 0, "file after the directory of another board, skipped")
+				continue
+			}
+			imp.files[filename] = true
+			imp.report.Files++
+			if imp.pending == nil {
+				continue
+			}
+
+			board := imp.pending.Boards[0]
+			fileInfo, newFileName, err := a.saveFile(zr, opt.TeamID, board.ID, opt.ModifiedBy, filename, board.IsTemplate)
 			if err != nil {
-				return fmt.Errorf("cannot import file %s for board %s: %w", filename, dir, err)
+				report.AddError(hdr.Name, 0, fmt.Sprintf("cannot import file: %s", err))
+				a.discardBoardImport(imp)
+				continue
 			}
-			fileMap[filename] = newFileName
+			imp.savedFiles = append(imp.savedFiles, fileInfo)
+			imp.fileNames[filename] = newFileName
 
 			a.logger.Debug("import archive file",
 				mlog.String("TeamID", opt.TeamID),
@@ -104,51 +162,160 @@ func (a *App) ImportArchive(r io.Reader, opt model.ImportArchiveOptions) error {
 			)
 		}
 	}
+
+	a.commitBoardImport(pending, opt, report)
+
+	for _, imp := range imports {
+		fileIDs := make([]string, 0, len(imp.fileRefs))
+		for fileID := range imp.fileRefs {
+			if !imp.files[fileID] {
+				fileIDs = append(fileIDs, fileID)
+			}
+		}
+		sort.Strings(fileIDs)
+		for _, fileID := range fileIDs {
+			report.AddWarning(imp.report.File, imp.fileRefs[fileID], fmt.Sprintf("file %s is missing from the archive", fileID))
+		}
+	}
+
+	a.logger.Debug("import archive - done",
+		mlog.Bool("dry_run", opt.DryRun),
+		mlog.Int("boards", len(imports)),
+		mlog.Int("errors", len(report.Errors)),
+	)
+	return report, nil
+}
+
+// abortArchiveImport discards the board being imported when the rest of
+// the archive, which may contain its files, cannot be read.
+func (a *App) abortArchiveImport(pending *boardImport, err error) error {
+	if pending != nil {
+		a.discardBoardImport(pending)
+	}
+	return model.NewErrBadRequest(err.Error())
 }
 
-// Update image and attachment blocks.
-func (a *App) fixImagesAttachments(boardMap map[string]*model.Board, fileMap map[string]string, teamID string, userID string) {
-	blockIDs := make([]string, 0)
-	blockPatches := make([]model.BlockPatch, 0)
-	for _, board := range boardMap {
-		if board.IsTemplate {
+// discardBoardImport gives up on the pending boards of a board file,
+// removing the files saved for them.
+func (a *App) discardBoardImport(imp *boardImport) {
+	if imp.pending == nil {
+		return
+	}
+
+	fileIDs := make([]string, 0, len(imp.savedFiles))
+	for _, fileInfo := range imp.savedFiles {
+		fileIDs = append(fileIDs, fileInfo.Id)
+	}
+	// the blobs of the files are released with their file infos
+	if len(fileIDs) > 0 {
+		if err := a.store.DeleteFileInfos(fileIDs); err != nil {
+			a.logger.Error("cannot remove files of failed import", mlog.Int("count", len(fileIDs)), mlog.Err(err))
+		}
+	}
+	for _, fileInfo := range imp.savedFiles {
+		if _, ok := model.FileBlobHash(fileInfo.Path); ok {
 			continue
 		}
+		for _, filePath := range []string{fileInfo.Path, fileInfo.ThumbnailPath, fileInfo.PreviewPath} {
+			if filePath == "" {
+				continue
+			}
+			if err := a.filesBackend.RemoveFile(filePath); err != nil {
+				a.logger.Error("cannot remove file of failed import", mlog.String("path", filePath), mlog.Err(err))
+			}
+		}
+	}
+
+	imp.pending = nil
+	imp.savedFiles = nil
+	imp.report.Failed = true
+}
+
+// commitBoardImport creates the pending boards of a board file, with their
+// blocks referencing the files saved for them and their members, in one
+// transaction. The saved files are discarded if the boards can't be created.
+func (a *App) commitBoardImport(imp *boardImport, opt model.ImportArchiveOptions, report *model.ArchiveImportReport) {
+	if imp == nil || imp.pending == nil {
+		return
+	}
 
-		opts := model.QueryBlocksOptions{
-			BoardID: board.ID,
+	bab := imp.pending
+	for _, block := range bab.Blocks {
+		if block.Type != model.TypeImage && block.Type != model.TypeAttachment {
+			continue
 		}
-		newBlocks, err := a.store.GetBlocks(opts)
-		if err != nil {
-			a.logger.Info("cannot retrieve imported blocks for board", mlog.String("BoardID", board.ID), mlog.Err(err))
-			return
+		oldID, _ := stringValue(block.Fields, "fileId")
+		if newID, ok := imp.fileNames[oldID]; ok {
+			block.Fields["fileId"] = newID
 		}
+	}
 
-		for _, block := range newBlocks {
-			if block.Type == "image" || block.Type == "attachment" {
-				fieldName := "fileId"
-				oldID := block.Fields[fieldName]
-				blockIDs = append(blockIDs, block.ID)
-
-				blockPatches = append(blockPatches, model.BlockPatch{
-					UpdatedFields: map[string]interface{}{
-						fieldName: fileMap[oldID.(string)],
-					},
-				})
-			}
-		}
+	newBab, members, err := a.store.CreateBoardsAndBlocksWithMembers(bab, importedBoardMembers(bab.Boards, imp.members, opt), opt.ModifiedBy)
+	if err != nil {
+		report.AddError(imp.report.File, 0, fmt.Sprintf("cannot insert blocks: %s", err))
+		a.discardBoardImport(imp)
+		return
+	}
+	imp.pending = nil
+	imp.boards = newBab.Boards
+	if len(imp.boards) == 0 {
+		return
+	}
+	imp.report.BoardID = imp.boards[0].ID
 
-		blockPatchBatch := model.BlockPatchBatch{BlockIDs: blockIDs, BlockPatches: blockPatches}
-		err = a.PatchBlocks(teamID, &blockPatchBatch, userID)
-		if err != nil {
-			a.logger.Info("Error patching blocks for image import", mlog.Err(err))
+	a.notifyBoardsAndBlocksCreated(newBab, members, opt.ModifiedBy)
+	for _, member := range members {
+		for _, board := range newBab.Boards {
+			if board.ID != member.BoardID || board.IsTemplate {
+				continue
+			}
+			if err := a.addBoardsToDefaultCategory(member.UserID, board.TeamID, []*model.Board{board}); err != nil {
+				report.AddWarning(imp.report.File, 0, fmt.Sprintf("cannot add board to the sidebar of user %s: %s", member.UserID, err))
+			}
 		}
 	}
 }
 
 // ImportBoardJSONL imports a JSONL file containing blocks for one board. The resulting
-// board id is returned.
+// board is returned, or the first problem that prevented importing it.
 func (a *App) ImportBoardJSONL(r io.Reader, opt model.ImportArchiveOptions) (*model.Board, error) {
+	report := model.NewArchiveImportReport(opt.DryRun)
+	imp := a.importBoardJSONL(r, "", opt, report)
+	a.commitBoardImport(imp, opt, report)
+	if len(report.Errors) > 0 {
+		return nil, report.Errors[0]
+	}
+	if len(imp.boards) == 0 {
+		return nil, fmt.Errorf("missing board in archive: %w", model.ErrInvalidBoardBlock)
+	}
+	return imp.boards[0], nil
+}
+
+// importBoardJSONL reads a JSONL file containing the blocks of one board,
+// adding every invalid line to the report. Unless the file has errors or
+// opt.DryRun is set, the board is left pending, with new IDs, to be created
+// with its files by commitBoardImport.
+func (a *App) importBoardJSONL(r io.Reader, file string, opt model.ImportArchiveOptions, report *model.ArchiveImportReport) *boardImport {
+	imp := &boardImport{
+		report: &model.ArchiveImportBoard{
+			File:   file,
+			Blocks: make(map[model.BlockType]int),
+		},
+		fileRefs:  make(map[string]int),
+		files:     make(map[string]bool),
+		fileNames: make(map[string]string),
+	}
+	report.Boards = append(report.Boards, imp.report)
+
+	errorCount := len(report.Errors)
+	addError := func(line int, format string, args ...interface{}) {
+		report.AddError(file, line, fmt.Sprintf(format, args...))
+	}
+	failed := func() *boardImport {
+		imp.report.Failed = true
+		return imp
+	}
+
 	// TODO: Stream this once `model.GenerateBlockIDs` can take a stream of blocks.
 	//       We don't want to load the whole file in memory, even though it's a single board.
 	boardsAndBlocks := &model.BoardsAndBlocks{
@@ -164,121 +331,174 @@ func (a *App) ImportBoardJSONL(r io.Reader, opt model.ImportArchiveOptions) (*mo
 	}
 	now := utils.GetMillis()
 	var boardID string
+	var hasBoardLine bool
 	var boardMembers []*model.BoardMember
 
-	lineNum := 1
+	lineNum := 0
 	firstLine := true
 	for scanner.Scan() {
+		lineNum++
 		if lineReader.N <= 0 {
-			return nil, fmt.Errorf("error parsing archive line %d: %w", lineNum, errSizeLimitExceeded)
+			addError(lineNum, "%s", errSizeLimitExceeded)
+			break
 		}
 
 		line := bytes.TrimSpace(scanner.Bytes())
-		if len(line) != 0 {
-			var skip bool
-			if firstLine {
-				// first line might be a header tag (old archive format)
-				if strings.HasPrefix(string(line), legacyFileBegin) {
-					skip = true
-				}
-			}
+		if len(line) == 0 {
+			continue
+		}
+		// first line might be a header tag (old archive format)
+		if firstLine && strings.HasPrefix(string(line), legacyFileBegin) {
+			continue
+		}
 
-			if !skip {
-				var archiveLine model.ArchiveLine
-				if err := json.Unmarshal(line, &archiveLine); err != nil {
-					return nil, fmt.Errorf("error parsing archive line %d: %w", lineNum, err)
-				}
+		var archiveLine model.ArchiveLine
+		if err := json.Unmarshal(line, &archiveLine); err != nil {
+			addError(lineNum, "invalid JSON: %s", err)
+			firstLine = false
+			continue
+		}
 
-				// first line must be a board
-				if firstLine && archiveLine.Type == "block" {
-					archiveLine.Type = "board_block"
+		// first line must be a board
+		if firstLine && archiveLine.Type == "block" {
+			archiveLine.Type = "board_block"
+		}
+		firstLine = false
+
+		switch archiveLine.Type {
+		case "board":
+			hasBoardLine = true
+			var board model.Board
+			if err := json.Unmarshal(archiveLine.Data, &board); err != nil {
+				addError(lineNum, "invalid board: %s", err)
+				continue
+			}
+			board.ModifiedBy = userID
+			board.UpdateAt = now
+			board.TeamID = opt.TeamID
+			boardID = board.ID
+			if err := board.IsValid(); err != nil {
+				addError(lineNum, "invalid board %s: %s", board.ID, err)
+				continue
+			}
+			boardsAndBlocks.Boards = append(boardsAndBlocks.Boards, &board)
+		case "board_block":
+			// legacy archives encoded boards as blocks; we need to convert them to real boards.
+			hasBoardLine = true
+			var block *model.Block
+			if err := json.Unmarshal(archiveLine.Data, &block); err != nil {
+				addError(lineNum, "invalid board block: %s", err)
+				continue
+			}
+			block.ModifiedBy = userID
+			block.UpdateAt = now
+			board, err := a.blockToBoard(block, opt)
+			if err != nil {
+				addError(lineNum, "cannot convert block %s to board: %s", block.ID, err)
+				continue
+			}
+			boardsAndBlocks.Boards = append(boardsAndBlocks.Boards, board)
+			boardID = board.ID
+		case "block":
+			var block *model.Block
+			if err := json.Unmarshal(archiveLine.Data, &block); err != nil {
+				addError(lineNum, "invalid block: %s", err)
+				continue
+			}
+			if !hasBoardLine {
+				addError(lineNum, "block %s is before the board", block.ID)
+				continue
+			}
+			block.ModifiedBy = userID
+			block.UpdateAt = now
+			block.BoardID = boardID
+			if boardID != "" {
+				if err := block.IsValid(); err != nil {
+					addError(lineNum, "invalid block %s: %s", block.ID, err)
+					continue
 				}
-
-				switch archiveLine.Type {
-				case "board":
-					var board model.Board
-					if err2 := json.Unmarshal(archiveLine.Data, &board); err2 != nil {
-						return nil, fmt.Errorf("invalid board in archive line %d: %w", lineNum, err2)
-					}
-					board.ModifiedBy = userID
-					board.UpdateAt = now
-					board.TeamID = opt.TeamID
-					boardsAndBlocks.Boards = append(boardsAndBlocks.Boards, &board)
-					boardID = board.ID
-				case "board_block":
-					// legacy archives encoded boards as blocks; we need to convert them to real boards.
-					var block *model.Block
-					if err2 := json.Unmarshal(archiveLine.Data, &block); err2 != nil {
-						return nil, fmt.Errorf("invalid board block in archive line %d: %w", lineNum, err2)
-					}
-					block.ModifiedBy = userID
-					block.UpdateAt = now
-					board, err := a.blockToBoard(block, opt)
-					if err != nil {
-						return nil, fmt.Errorf("cannot convert archive line %d to block: %w", lineNum, err)
-					}
-					boardsAndBlocks.Boards = append(boardsAndBlocks.Boards, board)
-					boardID = board.ID
-				case "block":
-					var block *model.Block
-					if err2 := json.Unmarshal(archiveLine.Data, &block); err2 != nil {
-						return nil, fmt.Errorf("invalid block in archive line %d: %w", lineNum, err2)
-					}
-					block.ModifiedBy = userID
-					block.UpdateAt = now
-					block.BoardID = boardID
-					boardsAndBlocks.Blocks = append(boardsAndBlocks.Blocks, block)
-				case "boardMember":
-					var boardMember *model.BoardMember
-					if err2 := json.Unmarshal(archiveLine.Data, &boardMember); err2 != nil {
-						return nil, fmt.Errorf("invalid board Member in archive line %d: %w", lineNum, err2)
+			}
+			if block.Type == model.TypeImage || block.Type == model.TypeAttachment {
+				if fileID, ok := stringValue(block.Fields, "fileId"); ok && fileID != "" {
+					if _, ok := imp.fileRefs[fileID]; !ok {
+						imp.fileRefs[fileID] = lineNum
 					}
-					boardMembers = append(boardMembers, boardMember)
-				default:
-					return nil, model.NewErrUnsupportedArchiveLineType(lineNum, archiveLine.Type)
 				}
-				firstLine = false
 			}
+			boardsAndBlocks.Blocks = append(boardsAndBlocks.Blocks, block)
+		case "boardMember":
+			var boardMember *model.BoardMember
+			if err := json.Unmarshal(archiveLine.Data, &boardMember); err != nil {
+				addError(lineNum, "invalid board member: %s", err)
+				continue
+			}
+			// skip the people who are not part of the team and system
+			if _, err := a.GetUser(boardMember.UserID); err != nil {
+				report.AddWarning(file, lineNum, fmt.Sprintf("user %s not found, member skipped", boardMember.UserID))
+				continue
+			}
+			boardMembers = append(boardMembers, boardMember)
+		default:
+			addError(lineNum, "unsupported line type %q", archiveLine.Type)
 		}
 	}
 
 	if errRead := scanner.Err(); errRead != nil {
-		return nil, fmt.Errorf("error reading archive line %d: %w", lineNum, errRead)
+		addError(lineNum+1, "cannot read line: %s", errRead)
 	}
 
-	// loop to remove the people how are not part of the team and system
-	for i := len(boardMembers) - 1; i >= 0; i-- {
-		if _, err := a.GetUser(boardMembers[i].UserID); err != nil {
-			boardMembers = append(boardMembers[:i], boardMembers[i+1:]...)
-		}
+	if len(boardsAndBlocks.Boards) > 0 {
+		imp.report.ArchiveID = boardsAndBlocks.Boards[0].ID
+		imp.report.Title = boardsAndBlocks.Boards[0].Title
+	} else if len(report.Errors) == errorCount {
+		addError(0, "missing board")
+	}
+	if len(report.Errors) > errorCount {
+		return failed()
 	}
 
 	a.fixBoardsandBlocks(boardsAndBlocks, opt)
+	for _, block := range boardsAndBlocks.Blocks {
+		imp.report.Blocks[block.Type]++
+	}
+	imp.report.Members = len(boardMembers)
+
+	if opt.DryRun {
+		return imp
+	}
 
 	var err error
 	boardsAndBlocks, err = model.GenerateBoardsAndBlocksIDs(boardsAndBlocks, a.logger)
 	if err != nil {
-		return nil, fmt.Errorf("error generating archive block IDs: %w", err)
+		addError(0, "cannot generate block IDs: %s", err)
+		return failed()
 	}
 
-	boardsAndBlocks, err = a.CreateBoardsAndBlocks(boardsAndBlocks, opt.ModifiedBy, false)
-	if err != nil {
-		return nil, fmt.Errorf("error inserting archive blocks: %w", err)
+	if len(boardsAndBlocks.Boards) > 0 {
+		imp.pending = boardsAndBlocks
+		imp.members = boardMembers
 	}
+	return imp
+}
 
-	// add users to all the new boards (if not the fake system user).
-	for _, board := range boardsAndBlocks.Boards {
+// importedBoardMembers returns the members of imported boards: the importing
+// user as admin, along with the members of the archive.
+func importedBoardMembers(boards []*model.Board, boardMembers []*model.BoardMember, opt model.ImportArchiveOptions) []*model.BoardMember {
+	members := []*model.BoardMember{}
+	for _, board := range boards {
 		// make sure an admin user gets added
-		adminMember := &model.BoardMember{
+		members = append(members, &model.BoardMember{
 			BoardID:     board.ID,
 			UserID:      opt.ModifiedBy,
 			SchemeAdmin: true,
-		}
-		if _, err2 := a.AddMemberToBoard(adminMember); err2 != nil {
-			return nil, fmt.Errorf("cannot add adminMember to board: %w", err2)
-		}
+		})
+		added := map[string]bool{opt.ModifiedBy: true}
 		for _, boardMember := range boardMembers {
-			bm := &model.BoardMember{
+			if added[boardMember.UserID] {
+				continue
+			}
+			added[boardMember.UserID] = true
+			members = append(members, &model.BoardMember{
 				BoardID:         board.ID,
 				UserID:          boardMember.UserID,
 				Roles:           boardMember.Roles,
@@ -288,18 +508,10 @@ func (a *App) ImportBoardJSONL(r io.Reader, opt model.ImportArchiveOptions) (*mo
 				SchemeCommenter: boardMember.SchemeCommenter,
 				SchemeViewer:    boardMember.SchemeViewer,
 				Synthetic:       boardMember.Synthetic,
-			}
-			if _, err2 := a.AddMemberToBoard(bm); err2 != nil {
-				return nil, fmt.Errorf("cannot add member to board: %w", err2)
-			}
+			})
 		}
 	}
-
-	// find new board id
-	for _, board := range boardsAndBlocks.Boards {
-		return board, nil
-	}
-	return nil, fmt.Errorf("missing board in archive: %w", model.ErrInvalidBoardBlock)
+	return members
 }
 
 // fixBoardsandBlocks allows the caller of `ImportArchive` to modify or filters boards and blocks being
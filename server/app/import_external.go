@@ -0,0 +1,147 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/importer"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// ImportExternal imports the export of another tool, such as Trello, Jira or
+// Asana, converted to boards by the importer of the tool. The people of the
+// export are mapped to the members of the team with the same username or
+// email, and the fields without an equivalent on boards are reported.
+func (a *App) ImportExternal(tool string, r io.Reader, opt model.ImportArchiveOptions) (*model.ExternalImportResult, error) {
+	imp, ok := importer.Get(tool)
+	if !ok {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("unsupported tool %s, must be one of %v", tool, importer.Names()))
+	}
+
+	converted, err := imp.Convert(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ExternalImportResult{
+		Tool:           tool,
+		UnmappedFields: []model.ExternalImportField{},
+		Members:        []model.ExternalImportMember{},
+	}
+	for name, count := range converted.Unmapped {
+		result.UnmappedFields = append(result.UnmappedFields, model.ExternalImportField{Name: name, Count: count})
+	}
+	sort.Slice(result.UnmappedFields, func(i, j int) bool { return result.UnmappedFields[i].Name < result.UnmappedFields[j].Name })
+
+	users := map[string]string{}
+	for id, member := range converted.Members {
+		user, err := a.findTeamUser(opt.TeamID, member.Username, member.Email)
+		if err != nil {
+			return nil, err
+		}
+		var userID string
+		if user != nil {
+			userID = user.ID
+			users[id] = userID
+		}
+		result.Members = append(result.Members, model.ExternalImportMember{Name: member.DisplayName(), UserID: userID})
+	}
+	sort.Slice(result.Members, func(i, j int) bool { return result.Members[i].Name < result.Members[j].Name })
+
+	userID := opt.ModifiedBy
+	if userID == model.SingleUser {
+		userID = ""
+	}
+	now := utils.GetMillis()
+	schemas := map[string]model.PropSchema{}
+	for _, board := range converted.Boards {
+		board.TeamID = opt.TeamID
+		board.ModifiedBy = userID
+		board.UpdateAt = now
+		schema, err := model.ParsePropertySchema(board)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse the properties of imported board %s: %w", board.Title, err)
+		}
+		schemas[board.ID] = schema
+	}
+	for _, block := range converted.Blocks {
+		block.ModifiedBy = userID
+		block.UpdateAt = now
+		if block.Type == model.TypeCard {
+			mapImportedPersonProperties(block, schemas[block.BoardID], users)
+			result.CardCount++
+		}
+	}
+
+	boardsAndBlocks := &model.BoardsAndBlocks{Boards: converted.Boards, Blocks: converted.Blocks}
+	a.fixBoardsandBlocks(boardsAndBlocks, opt)
+	if len(boardsAndBlocks.Boards) == 0 {
+		return nil, model.NewErrBadRequest("the export has no boards to import")
+	}
+
+	boardsAndBlocks, err = a.CreateBoardsAndBlocks(boardsAndBlocks, opt.ModifiedBy, false)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting imported blocks: %w", err)
+	}
+
+	for _, board := range boardsAndBlocks.Boards {
+		adminMember := &model.BoardMember{
+			BoardID:     board.ID,
+			UserID:      opt.ModifiedBy,
+			SchemeAdmin: true,
+		}
+		if _, err := a.AddMemberToBoard(adminMember); err != nil {
+			return nil, fmt.Errorf("cannot add adminMember to board: %w", err)
+		}
+	}
+	result.Boards = boardsAndBlocks.Boards
+
+	a.logger.Debug("import external - done",
+		mlog.String("tool", tool),
+		mlog.Int("boards_imported", len(result.Boards)),
+		mlog.Int("cards_imported", result.CardCount),
+		mlog.Int("members_mapped", len(users)),
+	)
+	return result, nil
+}
+
+// mapImportedPersonProperties replaces the people of an export set in the
+// person properties of a card with the users they map to, and clears the
+// people without a user.
+func mapImportedPersonProperties(card *model.Block, schema model.PropSchema, users map[string]string) {
+	props, ok := card.Fields["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for propID, value := range props {
+		propDef, ok := schema[propID]
+		if !ok || (propDef.Type != "person" && propDef.Type != "multiPerson") {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			if userID, ok := users[v]; ok {
+				props[propID] = userID
+			} else {
+				delete(props, propID)
+			}
+		case []interface{}:
+			userIDs := make([]interface{}, 0, len(v))
+			for _, id := range v {
+				if userID, ok := users[fmt.Sprint(id)]; ok {
+					userIDs = append(userIDs, userID)
+				}
+			}
+			if len(userIDs) == 0 {
+				delete(props, propID)
+			} else {
+				props[propID] = userIDs
+			}
+		}
+	}
+}
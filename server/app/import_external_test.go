@@ -0,0 +1,106 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const trelloImportExport = `{
+	"name": "Roadmap",
+	"lists": [{"id": "list-1", "name": "To Do", "pos": 1}],
+	"members": [
+		{"id": "member-1", "username": "alice", "fullName": "Alice Doe"},
+		{"id": "member-2", "username": "bob", "fullName": "Bob Roe"}
+	],
+	"cards": [
+		{"id": "card-1", "name": "Plan", "idList": "list-1", "idMembers": ["member-1", "member-2"], "attachments": [{"name": "spec.pdf"}]}
+	]
+}`
+
+func TestImportExternal(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	opts := model.ImportArchiveOptions{
+		TeamID:     "test-team",
+		ModifiedBy: "user",
+	}
+
+	t.Run("unsupported tool", func(t *testing.T) {
+		_, err := th.App.ImportExternal("notion", strings.NewReader("{}"), opts)
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("invalid export", func(t *testing.T) {
+		_, err := th.App.ImportExternal("trello", strings.NewReader("<rss/>"), opts)
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("import trello board", func(t *testing.T) {
+		var created *model.BoardsAndBlocks
+		// bob is only a user of another team
+		th.Store.EXPECT().GetUserByUsername("alice").Return(&model.User{ID: "user-1", Username: "alice"}, nil)
+		th.Store.EXPECT().GetUserByUsername("bob").Return(&model.User{ID: "user-2", Username: "bob"}, nil)
+		th.API.EXPECT().HasPermissionToTeam("user-1", "test-team", model.PermissionViewTeam).Return(true)
+		th.API.EXPECT().HasPermissionToTeam("user-2", "test-team", model.PermissionViewTeam).Return(false)
+		th.Store.EXPECT().CreateBoardsAndBlocks(gomock.AssignableToTypeOf(&model.BoardsAndBlocks{}), "user").DoAndReturn(
+			func(bab *model.BoardsAndBlocks, userID string) (*model.BoardsAndBlocks, error) {
+				created = bab
+				return bab, nil
+			})
+		th.Store.EXPECT().GetMembersForBoard(gomock.Any()).AnyTimes().Return([]*model.BoardMember{}, nil)
+		th.Store.EXPECT().GetBoard(gomock.Any()).DoAndReturn(func(boardID string) (*model.Board, error) {
+			return created.Boards[0], nil
+		})
+		th.Store.EXPECT().GetMemberForBoard(gomock.Any(), "user").Return(&model.BoardMember{UserID: "user", SchemeAdmin: true}, nil)
+		th.Store.EXPECT().GetUserCategoryBoards("user", "test-team").Return([]model.CategoryBoards{
+			{
+				Category: model.Category{
+					Type: "default",
+					Name: "Boards",
+					ID:   "boards_category_id",
+				},
+			},
+		}, nil)
+		th.Store.EXPECT().GetUserCategoryBoards("user", "test-team")
+		th.Store.EXPECT().CreateCategory(utils.Anything).Return(nil)
+		th.Store.EXPECT().GetCategory(utils.Anything).Return(&model.Category{
+			ID:   "boards_category_id",
+			Name: "Boards",
+		}, nil)
+		th.Store.EXPECT().GetBoardsForUserAndTeam("user", "test-team", false).Return([]*model.Board{}, nil)
+		th.Store.EXPECT().GetMembersForUser("user").Return([]*model.BoardMember{}, nil)
+		th.Store.EXPECT().AddUpdateCategoryBoard("user", utils.Anything, utils.Anything).Return(nil)
+
+		result, err := th.App.ImportExternal("trello", strings.NewReader(trelloImportExport), opts)
+		require.NoError(t, err)
+		require.Equal(t, "trello", result.Tool)
+		require.Len(t, result.Boards, 1)
+		require.Equal(t, "test-team", result.Boards[0].TeamID)
+		require.Equal(t, 1, result.CardCount)
+		require.Equal(t, []model.ExternalImportField{{Name: "Attachments", Count: 1}}, result.UnmappedFields)
+		require.Equal(t, []model.ExternalImportMember{
+			{Name: "Alice Doe", UserID: "user-1"},
+			{Name: "Bob Roe", UserID: ""},
+		}, result.Members)
+
+		schema, err := model.ParsePropertySchema(created.Boards[0])
+		require.NoError(t, err)
+		for _, block := range created.Blocks {
+			if block.Type != model.TypeCard {
+				continue
+			}
+			props := block.Fields["properties"].(map[string]interface{})
+			for propID, value := range props {
+				if schema[propID].Type == "multiPerson" {
+					require.Equal(t, []interface{}{"user-1"}, value)
+				}
+			}
+		}
+	})
+}
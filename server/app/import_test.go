@@ -1,14 +1,21 @@
 package app
 
 import (
+	"archive/zip"
 	"bytes"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/mattermost/focalboard/server/utils"
 
 	"github.com/golang/mock/gomock"
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore/mocks"
 )
 
 func TestApp_ImportArchive(t *testing.T) {
@@ -45,11 +52,15 @@ func TestApp_ImportArchive(t *testing.T) {
 			ModifiedBy: "user",
 		}
 
-		th.Store.EXPECT().CreateBoardsAndBlocks(gomock.AssignableToTypeOf(&model.BoardsAndBlocks{}), "user").Return(babs, nil)
+		// the board, its blocks and its admin are created together
+		th.Store.EXPECT().CreateBoardsAndBlocksWithMembers(gomock.AssignableToTypeOf(&model.BoardsAndBlocks{}), gomock.Any(), "user").DoAndReturn(
+			func(bab *model.BoardsAndBlocks, members []*model.BoardMember, _ string) (*model.BoardsAndBlocks, []*model.BoardMember, error) {
+				require.Len(t, bab.Boards, 1)
+				require.Equal(t, []*model.BoardMember{{BoardID: bab.Boards[0].ID, UserID: "user", SchemeAdmin: true}}, members)
+				return babs, []*model.BoardMember{boardMember}, nil
+			})
 		th.Store.EXPECT().GetMembersForBoard(board.ID).AnyTimes().Return([]*model.BoardMember{boardMember}, nil)
-		th.Store.EXPECT().GetBoard(board.ID).Return(board, nil)
-		th.Store.EXPECT().GetMemberForBoard(board.ID, "user").Return(boardMember, nil)
-		th.Store.EXPECT().GetUserCategoryBoards("user", "test-team").Return([]model.CategoryBoards{
+		th.Store.EXPECT().GetUserCategoryBoards("user", "test-team").Times(2).Return([]model.CategoryBoards{
 			{
 				Category: model.Category{
 					Type: "default",
@@ -58,18 +69,118 @@ func TestApp_ImportArchive(t *testing.T) {
 				},
 			},
 		}, nil)
-		th.Store.EXPECT().GetUserCategoryBoards("user", "test-team")
-		th.Store.EXPECT().CreateCategory(utils.Anything).Return(nil)
-		th.Store.EXPECT().GetCategory(utils.Anything).Return(&model.Category{
-			ID:   "boards_category_id",
-			Name: "Boards",
-		}, nil)
-		th.Store.EXPECT().GetBoardsForUserAndTeam("user", "test-team", false).Return([]*model.Board{}, nil)
-		th.Store.EXPECT().GetMembersForUser("user").Return([]*model.BoardMember{}, nil)
-		th.Store.EXPECT().AddUpdateCategoryBoard("user", utils.Anything, utils.Anything).Return(nil)
+		th.Store.EXPECT().AddUpdateCategoryBoard("user", "boards_category_id", []string{board.ID}).Return(nil)
 
-		err := th.App.ImportArchive(r, opts)
+		report, err := th.App.ImportArchive(r, opts)
 		require.NoError(t, err, "import archive should not fail")
+		require.Empty(t, report.Errors)
+		require.Len(t, report.Boards, 1)
+		require.Equal(t, board.ID, report.Boards[0].BoardID)
+		require.Equal(t, 10, report.Boards[0].Blocks[model.TypeCard]+report.Boards[0].Blocks[model.TypeText]+report.Boards[0].Blocks[model.TypeView])
+	})
+
+	t.Run("dry run reports every problem", func(t *testing.T) {
+		archive := makeTestArchive(t, [][2]string{
+			{"version.json", `{"version":2,"date":1614714686842}`},
+			{board.ID + "/board.jsonl", invalidBoardArchive},
+			{board.ID + "/present.png", "image"},
+			{"bfoi6yy6pa3yzika53spj7pq9ee/board.jsonl", boardArchive},
+			{"orphan/image.png", "image"},
+		})
+		opts := model.ImportArchiveOptions{
+			TeamID:     "test-team",
+			ModifiedBy: "user",
+			DryRun:     true,
+		}
+
+		th.Store.EXPECT().GetUserByID("f1tydgc697fcbp8ampr6881jea").Return(&model.User{ID: "f1tydgc697fcbp8ampr6881jea"}, nil)
+		th.Store.EXPECT().GetUserByID("hxxzooc3ff8cubsgtcmpn8733e").Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().GetUserByID("nto73edn5ir6ifimo5a53y1dwa").Return(&model.User{ID: "nto73edn5ir6ifimo5a53y1dwa"}, nil)
+
+		report, err := th.App.ImportArchive(bytes.NewReader(archive), opts)
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Len(t, report.Boards, 2)
+
+		invalid := report.Boards[0]
+		require.True(t, invalid.Failed)
+		require.Equal(t, 1, invalid.Files)
+		require.Equal(t, []*model.ArchiveImportIssue{
+			{File: board.ID + "/board.jsonl", Line: 3, Message: `invalid JSON: invalid character 'n' looking for beginning of object key string`},
+			{File: board.ID + "/board.jsonl", Line: 4, Message: `unsupported line type "comment"`},
+		}, report.Errors)
+
+		valid := report.Boards[1]
+		require.False(t, valid.Failed)
+		require.Empty(t, valid.BoardID)
+		require.Equal(t, "Custom", valid.Title)
+		require.Equal(t, map[model.BlockType]int{model.TypeCard: 1, model.TypeView: 1}, valid.Blocks)
+		require.Equal(t, 2, valid.Members)
+		require.Equal(t, []*model.ArchiveImportIssue{
+			{File: "bfoi6yy6pa3yzika53spj7pq9ee/board.jsonl", Line: 5, Message: "user hxxzooc3ff8cubsgtcmpn8733e not found, member skipped"},
+			{File: "orphan/image.png", Message: "file outside of a board directory, skipped"},
+			{File: board.ID + "/board.jsonl", Line: 5, Message: "file missing.png is missing from the archive"},
+		}, report.Warnings)
+	})
+
+	t.Run("discard board and its files when it cannot be created", func(t *testing.T) {
+		archive := makeTestArchive(t, [][2]string{
+			{"version.json", `{"version":2,"date":1614714686842}`},
+			{"bfoi6yy6pa3yzika53spj7pq9ee/board.jsonl", boardArchive},
+			{"bfoi6yy6pa3yzika53spj7pq9ee/image.png", "image"},
+		})
+		opts := model.ImportArchiveOptions{
+			TeamID:     "test-team",
+			ModifiedBy: "user",
+		}
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		writeFileFunc := func(reader io.Reader, path string) int64 {
+			n, err := io.Copy(io.Discard, reader)
+			require.NoError(t, err)
+			return n
+		}
+		writeFileErrorFunc := func(reader io.Reader, path string) error {
+			return nil
+		}
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(writeFileFunc, writeFileErrorFunc)
+		mockedFileBackend.On("FileExists", mock.Anything).Return(true, nil)
+		mockedFileBackend.On("RemoveFile", mock.Anything).Return(nil)
+
+		th.Store.EXPECT().GetUserByID("f1tydgc697fcbp8ampr6881jea").Return(&model.User{ID: "f1tydgc697fcbp8ampr6881jea"}, nil)
+		th.Store.EXPECT().GetUserByID("hxxzooc3ff8cubsgtcmpn8733e").Return(&model.User{ID: "hxxzooc3ff8cubsgtcmpn8733e"}, nil)
+		th.Store.EXPECT().GetUserByID("nto73edn5ir6ifimo5a53y1dwa").Return(&model.User{ID: "nto73edn5ir6ifimo5a53y1dwa"}, nil)
+		th.Store.EXPECT().GetUploadPolicy("test-team").Return(nil, model.NewErrNotFound("upload policy"))
+		th.Store.EXPECT().GetStorageQuota(gomock.Any(), gomock.Any()).Return(nil, model.NewErrNotFound("storage quota")).Times(2)
+		var savedID string
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "test-team", gomock.Any()).DoAndReturn(func(fileInfo *mm_model.FileInfo, _, _ string) error {
+			savedID = fileInfo.Id
+			return nil
+		})
+		th.Store.EXPECT().CreateBoardsAndBlocksWithMembers(gomock.Any(), gomock.Any(), "user").Return(nil, nil, errors.New("database unavailable"))
+		th.Store.EXPECT().DeleteFileInfos(gomock.Any()).DoAndReturn(func(ids []string) error {
+			require.Equal(t, []string{savedID}, ids)
+			return nil
+		})
+
+		report, err := th.App.ImportArchive(bytes.NewReader(archive), opts)
+		require.NoError(t, err)
+		require.True(t, report.Failed())
+		require.Len(t, report.Boards, 1)
+		require.True(t, report.Boards[0].Failed)
+		require.Empty(t, report.Boards[0].BoardID)
+		require.Equal(t, 1, report.Boards[0].Files)
+		require.Len(t, report.Errors, 1)
+		require.Contains(t, report.Errors[0].Message, "database unavailable")
+	})
+
+	t.Run("unsupported archive version", func(t *testing.T) {
+		archive := makeTestArchive(t, [][2]string{
+			{"version.json", `{"version":3,"date":1614714686842}`},
+		})
+		_, err := th.App.ImportArchive(bytes.NewReader(archive), model.ImportArchiveOptions{TeamID: "test-team", ModifiedBy: "user"})
+		require.True(t, model.IsErrBadRequest(err))
 	})
 
 	t.Run("import board archive", func(t *testing.T) {
@@ -106,7 +217,7 @@ func TestApp_ImportArchive(t *testing.T) {
 			ID: "nto73edn5ir6ifimo5a53y1dwa",
 		}
 
-		th.Store.EXPECT().CreateBoardsAndBlocks(gomock.AssignableToTypeOf(&model.BoardsAndBlocks{}), "f1tydgc697fcbp8ampr6881jea").Return(babs, nil)
+		th.Store.EXPECT().CreateBoardsAndBlocksWithMembers(gomock.AssignableToTypeOf(&model.BoardsAndBlocks{}), gomock.Any(), "f1tydgc697fcbp8ampr6881jea").Return(babs, []*model.BoardMember{bm1}, nil)
 		th.Store.EXPECT().GetMembersForBoard(board.ID).AnyTimes().Return([]*model.BoardMember{bm1, bm2, bm3}, nil)
 		th.Store.EXPECT().GetUserCategoryBoards("f1tydgc697fcbp8ampr6881jea", "test-team").Return([]model.CategoryBoards{}, nil)
 		th.Store.EXPECT().GetUserCategoryBoards("f1tydgc697fcbp8ampr6881jea", "test-team").Return([]model.CategoryBoards{
@@ -140,73 +251,90 @@ func TestApp_ImportArchive(t *testing.T) {
 	})
 
 	t.Run("fix image and attachment", func(t *testing.T) {
-		boardMap := map[string]*model.Board{
-			"test": board,
-		}
-
-		fileMap := map[string]string{
-			"oldFileName1.jpg": "newFileName1.jpg",
-			"oldFileName2.jpg": "newFileName2.jpg",
-		}
-
 		imageBlock := &model.Block{
-			ID:         "blockID-1",
-			ParentID:   "c3zqnh6fsu3f4mr6hzq9hizwske",
-			CreatedBy:  "6k6ynxdp47dujjhhojw9nqhmyh",
-			ModifiedBy: "6k6ynxdp47dujjhhojw9nqhmyh",
-			Schema:     1,
-			Type:       "image",
-			Title:      "",
-			Fields:     map[string]interface{}{"fileId": "oldFileName1.jpg"},
-			CreateAt:   1680725585250,
-			UpdateAt:   1680725585250,
-			DeleteAt:   0,
-			BoardID:    "board-id",
+			ID:       "blockID-1",
+			ParentID: "c3zqnh6fsu3f4mr6hzq9hizwske",
+			Type:     model.TypeImage,
+			Fields:   map[string]interface{}{"fileId": "oldFileName1.jpg"},
+			BoardID:  "board-id",
 		}
 
 		attachmentBlock := &model.Block{
-			ID:         "blockID-2",
-			ParentID:   "c3zqnh6fsu3f4mr6hzq9hizwske",
-			CreatedBy:  "6k6ynxdp47dujjhhojw9nqhmyh",
-			ModifiedBy: "6k6ynxdp47dujjhhojw9nqhmyh",
-			Schema:     1,
-			Type:       "attachment",
-			Title:      "",
-			Fields:     map[string]interface{}{"fileId": "oldFileName2.jpg"},
-			CreateAt:   1680725585250,
-			UpdateAt:   1680725585250,
-			DeleteAt:   0,
-			BoardID:    "board-id",
+			ID:       "blockID-2",
+			ParentID: "c3zqnh6fsu3f4mr6hzq9hizwske",
+			Type:     model.TypeAttachment,
+			Fields:   map[string]interface{}{"fileId": "oldFileName2.jpg"},
+			BoardID:  "board-id",
 		}
 
-		blockIDs := []string{"blockID-1", "blockID-2"}
-
-		blockPatch := model.BlockPatch{
-			UpdatedFields: map[string]interface{}{"fileId": "newFileName1.jpg"},
+		missingBlock := &model.Block{
+			ID:       "blockID-3",
+			ParentID: "c3zqnh6fsu3f4mr6hzq9hizwske",
+			Type:     model.TypeImage,
+			Fields:   map[string]interface{}{"fileId": "missing.jpg"},
+			BoardID:  "board-id",
 		}
 
-		blockPatch2 := model.BlockPatch{
-			UpdatedFields: map[string]interface{}{"fileId": "newFileName2.jpg"},
+		templateBoard := &model.Board{ID: "board-id", TeamID: "test-team", IsTemplate: true}
+		bab := &model.BoardsAndBlocks{
+			Boards: []*model.Board{templateBoard},
+			Blocks: []*model.Block{imageBlock, attachmentBlock, missingBlock},
 		}
-
-		blockPatches := []model.BlockPatch{blockPatch, blockPatch2}
-
-		blockPatchesBatch := model.BlockPatchBatch{BlockIDs: blockIDs, BlockPatches: blockPatches}
-
-		opts := model.QueryBlocksOptions{
-			BoardID: board.ID,
+		imp := &boardImport{
+			report:  &model.ArchiveImportBoard{Blocks: map[model.BlockType]int{}},
+			pending: bab,
+			fileNames: map[string]string{
+				"oldFileName1.jpg": "newFileName1.jpg",
+				"oldFileName2.jpg": "newFileName2.jpg",
+			},
 		}
-		th.Store.EXPECT().GetBlocks(opts).Return([]*model.Block{imageBlock, attachmentBlock}, nil)
-		th.Store.EXPECT().GetBlocksByIDs(blockIDs).Return([]*model.Block{imageBlock, attachmentBlock}, nil)
-		th.Store.EXPECT().GetBlock(blockIDs[0]).Return(imageBlock, nil)
-		th.Store.EXPECT().GetBlock(blockIDs[1]).Return(attachmentBlock, nil)
+		opts := model.ImportArchiveOptions{TeamID: "test-team", ModifiedBy: "my-userid"}
+
+		th.Store.EXPECT().CreateBoardsAndBlocksWithMembers(bab, gomock.Any(), "my-userid").Return(bab, []*model.BoardMember{}, nil)
 		th.Store.EXPECT().GetMembersForBoard("board-id").AnyTimes().Return([]*model.BoardMember{}, nil)
 
-		th.Store.EXPECT().PatchBlocks(&blockPatchesBatch, "my-userid")
-		th.App.fixImagesAttachments(boardMap, fileMap, "test-team", "my-userid")
+		report := model.NewArchiveImportReport(false)
+		th.App.commitBoardImport(imp, opts, report)
+		require.Empty(t, report.Errors)
+		require.Equal(t, "board-id", imp.report.BoardID)
+		require.Equal(t, "newFileName1.jpg", imageBlock.Fields["fileId"])
+		require.Equal(t, "newFileName2.jpg", attachmentBlock.Fields["fileId"])
+		require.Equal(t, "missing.jpg", missingBlock.Fields["fileId"])
 	})
 }
 
+func TestImportedBoardMembers(t *testing.T) {
+	boards := []*model.Board{{ID: "board-1"}, {ID: "board-2"}}
+	archiveMembers := []*model.BoardMember{
+		{BoardID: "archive-board", UserID: "importer", SchemeViewer: true},
+		{BoardID: "archive-board", UserID: "user-1", SchemeEditor: true},
+		{BoardID: "archive-board", UserID: "user-1", SchemeViewer: true},
+	}
+	opt := model.ImportArchiveOptions{TeamID: "test-team", ModifiedBy: "importer"}
+
+	members := importedBoardMembers(boards, archiveMembers, opt)
+	require.Equal(t, []*model.BoardMember{
+		{BoardID: "board-1", UserID: "importer", SchemeAdmin: true},
+		{BoardID: "board-1", UserID: "user-1", SchemeEditor: true},
+		{BoardID: "board-2", UserID: "importer", SchemeAdmin: true},
+		{BoardID: "board-2", UserID: "user-1", SchemeEditor: true},
+	}, members)
+}
+
+// makeTestArchive returns a zip archive of the given files, in order.
+func makeTestArchive(t *testing.T, files [][2]string) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, file := range files {
+		w, err := zw.Create(file[0])
+		require.NoError(t, err)
+		_, err = w.Write([]byte(file[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
 //nolint:lll
 const asana = `{"version":1,"date":1614714686842}
 {"type":"block","data":{"id":"d14b9df9-1f31-4732-8a64-92bc7162cd28","fields":{"icon":"","description":"","cardProperties":[{"id":"3bdcbaeb-bc78-4884-8531-a0323b74676a","name":"Section","type":"select","options":[{"id":"d8d94ef1-5e74-40bb-8be5-fc0eb3f47732","value":"Planning","color":"propColorGray"},{"id":"454559bb-b788-4ff6-873e-04def8491d2c","value":"Milestones","color":"propColorBrown"},{"id":"deaab476-c690-48df-828f-725b064dc476","value":"Next steps","color":"propColorOrange"},{"id":"2138305a-3157-461c-8bbe-f19ebb55846d","value":"Comms Plan","color":"propColorYellow"}]}]},"createAt":1614714686836,"updateAt":1614714686836,"deleteAt":0,"schema":1,"parentId":"","rootId":"d14b9df9-1f31-4732-8a64-92bc7162cd28","modifiedBy":"","type":"board","title":"Cross-Functional Project Plan"}}
@@ -230,3 +358,11 @@ const boardArchive = `{"type":"board","data":{"id":"bfoi6yy6pa3yzika53spj7pq9ee"
 {"type":"boardMember","data":{"boardId":"bfoi6yy6pa3yzika53spj7pq9ee","userId":"hxxzooc3ff8cubsgtcmpn8733e","roles":"","minimumRole":"","schemeAdmin":false,"schemeEditor":false,"schemeCommenter":false,"schemeViewer":true,"synthetic":false}}
 {"type":"boardMember","data":{"boardId":"bfoi6yy6pa3yzika53spj7pq9ee","userId":"nto73edn5ir6ifimo5a53y1dwa","roles":"","minimumRole":"","schemeAdmin":true,"schemeEditor":false,"schemeCommenter":false,"schemeViewer":false,"synthetic":false}}
 `
+
+//nolint:lll
+const invalidBoardArchive = `{"type":"board","data":{"id":"d14b9df9-1f31-4732-8a64-92bc7162cd28","type":"P","title":"Broken","cardProperties":[]}}
+{"type":"block","data":{"id":"image-1","parentId":"card-1","type":"image","fields":{"fileId":"present.png"}}}
+{"type":"block","data":{"id":"card-1",nope}}
+{"type":"comment","data":{}}
+{"type":"block","data":{"id":"image-2","parentId":"card-1","type":"image","fields":{"fileId":"missing.png"}}}
+`
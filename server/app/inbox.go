@@ -0,0 +1,180 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	inboxDueDateCheckedAtKey = "inbox_due_date_checked_at"
+	inboxBoardsPerPage       = 100
+)
+
+// AddInboxItems saves new inbox items and notifies their users of the new
+// unread count.
+func (a *App) AddInboxItems(items []*model.InboxItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	now := utils.GetMillis()
+	for _, item := range items {
+		item.ID = utils.NewID(utils.IDTypeNone)
+		item.CreateAt = now
+		item.ReadAt = 0
+	}
+
+	if err := a.store.InsertInboxItems(items); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		unreadCount, err := a.store.GetInboxUnreadCount(item.UserID, item.TeamID)
+		if err != nil {
+			a.logger.Error("Cannot get inbox unread count", mlog.String("user_id", item.UserID), mlog.Err(err))
+			continue
+		}
+		a.wsAdapter.BroadcastInboxChange(item.TeamID, item.UserID, item, unreadCount)
+	}
+	return nil
+}
+
+// GetInbox returns a page of the inbox items of a user in a team, newest first.
+func (a *App) GetInbox(userID, teamID string, unreadOnly bool, before int64, perPage int) (*model.Inbox, error) {
+	items, err := a.store.GetInboxItems(userID, model.QueryInboxItemsOptions{
+		TeamID:         teamID,
+		UnreadOnly:     unreadOnly,
+		BeforeCreateAt: before,
+		Limit:          uint64(perPage + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	unreadCount, err := a.store.GetInboxUnreadCount(userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := &model.Inbox{
+		Items:       items,
+		UnreadCount: unreadCount,
+	}
+	if len(items) > perPage {
+		inbox.Items = items[:perPage]
+		inbox.HasMore = true
+	}
+	return inbox, nil
+}
+
+// MarkInboxItemsRead marks inbox items of a user in a team as read, or all of
+// them if itemIDs is empty.
+func (a *App) MarkInboxItemsRead(userID, teamID string, itemIDs []string) (int, error) {
+	if err := a.store.MarkInboxItemsRead(userID, teamID, itemIDs, utils.GetMillis()); err != nil {
+		return 0, err
+	}
+
+	unreadCount, err := a.store.GetInboxUnreadCount(userID, teamID)
+	if err != nil {
+		return 0, err
+	}
+
+	a.wsAdapter.BroadcastInboxChange(teamID, userID, nil, unreadCount)
+	return unreadCount, nil
+}
+
+// RunDueDateNotifications adds an inbox item for the assignees of the cards
+// whose due date passed since the previous run.
+func (a *App) RunDueDateNotifications() {
+	// the run is claimed so a single server of the cluster adds the items
+	since, now, claimed := a.claimScheduledRunSince(inboxDueDateCheckedAtKey, 0)
+	if !claimed || since == 0 {
+		// the first run only records the point in time to start from.
+		return
+	}
+
+	for page := 0; ; page++ {
+		boards, hasMore, err := a.store.GetBoardsForCompliance(model.QueryBoardsForComplianceOptions{
+			Page:    page,
+			PerPage: inboxBoardsPerPage,
+		})
+		if err != nil {
+			a.logger.Error("Cannot fetch boards for due date notifications", mlog.Err(err))
+			return
+		}
+
+		for _, board := range boards {
+			items, err := a.dueDateInboxItems(board, since, now)
+			if err != nil {
+				a.logger.Error("Cannot check due dates", mlog.String("board_id", board.ID), mlog.Err(err))
+				continue
+			}
+			if err := a.AddInboxItems(items); err != nil {
+				a.logger.Error("Cannot add due date inbox items", mlog.String("board_id", board.ID), mlog.Err(err))
+			}
+		}
+
+		if !hasMore {
+			return
+		}
+	}
+}
+
+// dueDateInboxItems returns the inbox items for the assignees of the cards of a
+// board whose due date is in the (since, now] range.
+func (a *App) dueDateInboxItems(board *model.Board, since, now int64) ([]*model.InboxItem, error) {
+	if board.IsTemplate || board.DeleteAt != 0 {
+		return nil, nil
+	}
+
+	schema, err := model.ParsePropertySchema(board)
+	if err != nil {
+		return nil, err
+	}
+
+	dateProps := []model.PropDef{}
+	hasPersonProps := false
+	for _, propDef := range schema {
+		switch propDef.Type {
+		case "date":
+			dateProps = append(dateProps, propDef)
+		case "person", "multiPerson":
+			hasPersonProps = true
+		}
+	}
+	if len(dateProps) == 0 || !hasPersonProps {
+		return nil, nil
+	}
+
+	cards, err := a.store.GetBlocksWithType(board.ID, model.TypeCard)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*model.InboxItem{}
+	for _, card := range model.ExcludeArchivedCards(cards) {
+		for _, propDef := range dateProps {
+			due, ok := getCardDueDate(card, propDef.ID)
+			if !ok || due <= since || due > now {
+				continue
+			}
+			for userID := range model.GetCardAssignees(card, schema) {
+				if !a.permissions.HasPermissionToBoard(userID, board.ID, model.PermissionViewBoard) {
+					continue
+				}
+				items = append(items, &model.InboxItem{
+					UserID:    userID,
+					Type:      model.InboxItemDueDate,
+					TeamID:    board.TeamID,
+					BoardID:   board.ID,
+					CardID:    card.ID,
+					CardTitle: card.Title,
+					Message:   propDef.Name,
+				})
+			}
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddInboxItems(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("no items", func(t *testing.T) {
+		require.NoError(t, th.App.AddInboxItems(nil))
+	})
+
+	t.Run("items are initialized and saved", func(t *testing.T) {
+		items := []*model.InboxItem{
+			{UserID: "user-id", TeamID: "team-id", Type: model.InboxItemMention, ReadAt: 1000},
+		}
+		th.Store.EXPECT().InsertInboxItems(items).Return(nil)
+		th.Store.EXPECT().GetInboxUnreadCount("user-id", "team-id").Return(1, nil)
+
+		require.NoError(t, th.App.AddInboxItems(items))
+		require.NotEmpty(t, items[0].ID)
+		require.NotZero(t, items[0].CreateAt)
+		require.Zero(t, items[0].ReadAt)
+	})
+}
+
+func TestGetInbox(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	items := []*model.InboxItem{{ID: "item-1"}, {ID: "item-2"}, {ID: "item-3"}}
+
+	t.Run("more items than the page size", func(t *testing.T) {
+		th.Store.EXPECT().GetInboxItems("user-id", model.QueryInboxItemsOptions{TeamID: "team-id", Limit: 3}).Return(items, nil)
+		th.Store.EXPECT().GetInboxUnreadCount("user-id", "team-id").Return(5, nil)
+
+		inbox, err := th.App.GetInbox("user-id", "team-id", false, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, inbox.Items, 2)
+		require.True(t, inbox.HasMore)
+		require.Equal(t, 5, inbox.UnreadCount)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		opts := model.QueryInboxItemsOptions{TeamID: "team-id", UnreadOnly: true, BeforeCreateAt: 1000, Limit: 4}
+		th.Store.EXPECT().GetInboxItems("user-id", opts).Return(items, nil)
+		th.Store.EXPECT().GetInboxUnreadCount("user-id", "team-id").Return(3, nil)
+
+		inbox, err := th.App.GetInbox("user-id", "team-id", true, 1000, 3)
+		require.NoError(t, err)
+		require.Len(t, inbox.Items, 3)
+		require.False(t, inbox.HasMore)
+	})
+}
+
+func TestDueDateInboxItems(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	board := &model.Board{
+		ID:     "board-id",
+		TeamID: "team-id",
+		CardProperties: []map[string]interface{}{
+			{"id": "due-id", "name": "Due", "type": "date"},
+			{"id": "owner-id", "name": "Owner", "type": "multiPerson"},
+		},
+	}
+	card := &model.Block{
+		ID:      "card-id",
+		BoardID: "board-id",
+		Type:    model.TypeCard,
+		Fields: map[string]interface{}{
+			"properties": map[string]interface{}{
+				"due-id":   `{"from":5000}`,
+				"owner-id": []interface{}{"user-id"},
+			},
+		},
+	}
+
+	t.Run("boards without date and person properties are skipped", func(t *testing.T) {
+		items, err := th.App.dueDateInboxItems(&model.Board{ID: "board-id"}, 0, 10000)
+		require.NoError(t, err)
+		require.Empty(t, items)
+	})
+
+	t.Run("templates are skipped", func(t *testing.T) {
+		template := *board
+		template.IsTemplate = true
+		items, err := th.App.dueDateInboxItems(&template, 0, 10000)
+		require.NoError(t, err)
+		require.Empty(t, items)
+	})
+
+	t.Run("due dates out of the range are skipped", func(t *testing.T) {
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return([]*model.Block{card}, nil).Times(2)
+
+		items, err := th.App.dueDateInboxItems(board, 5000, 10000)
+		require.NoError(t, err)
+		require.Empty(t, items)
+
+		items, err = th.App.dueDateInboxItems(board, 0, 4000)
+		require.NoError(t, err)
+		require.Empty(t, items)
+	})
+
+	t.Run("store errors are returned", func(t *testing.T) {
+		th.Store.EXPECT().GetBlocksWithType("board-id", gomock.Any()).Return(nil, model.NewErrNotFound("board-id"))
+		_, err := th.App.dueDateInboxItems(board, 0, 10000)
+		require.Error(t, err)
+	})
+}
+
+func TestRunDueDateNotifications(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("a run claimed by another server adds no items", func(t *testing.T) {
+		lastRun := strconv.FormatInt(utils.GetMillis()-1000, 10)
+		th.Store.EXPECT().GetSystemSetting(inboxDueDateCheckedAtKey).Return(lastRun, nil)
+		th.Store.EXPECT().CompareAndSetSystemSetting(inboxDueDateCheckedAtKey, lastRun, gomock.Any()).Return(false, nil)
+		th.Store.EXPECT().GetBoardsForCompliance(gomock.Any()).Times(0)
+
+		th.App.RunDueDateNotifications()
+	})
+}
@@ -23,4 +23,28 @@ func (a *App) Shutdown() {
 			a.logger.Warn("blockChangeNotifier shutdown timed out")
 		}
 	}
+
+	if a.uploadScanQueue != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), uploadScanShutdownTimeout)
+		defer cancel()
+		if !a.uploadScanQueue.Shutdown(ctx) {
+			a.logger.Warn("uploadScanQueue shutdown timed out")
+		}
+	}
+
+	if a.exportQueue != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), exportShutdownTimeout)
+		defer cancel()
+		if !a.exportQueue.Shutdown(ctx) {
+			a.logger.Warn("exportQueue shutdown timed out")
+		}
+	}
+
+	if a.automationQueue != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), automationShutdownTimeout)
+		defer cancel()
+		if !a.automationQueue.Shutdown(ctx) {
+			a.logger.Warn("automationQueue shutdown timed out")
+		}
+	}
 }
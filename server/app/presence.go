@@ -0,0 +1,20 @@
+package app
+
+import "github.com/mattermost/focalboard/server/model"
+
+// GetBoardPresence returns the users currently looking at a board, or
+// only the ones that have a given card open if cardID is not empty.
+func (a *App) GetBoardPresence(boardID, cardID string) []*model.BoardPresence {
+	presences := a.wsAdapter.GetBoardPresence(boardID)
+	if cardID == "" {
+		return presences
+	}
+
+	cardPresences := []*model.BoardPresence{}
+	for _, presence := range presences {
+		if presence.CardID == cardID {
+			cardPresences = append(cardPresences, presence)
+		}
+	}
+	return cardPresences
+}
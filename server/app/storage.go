@@ -0,0 +1,152 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// GetStorageUsage returns the size of the files stored in a team, by
+// board and by user, with the quotas that apply to them.
+func (a *App) GetStorageUsage(teamID string) (*model.StorageUsage, error) {
+	usages, err := a.store.GetFileUsage(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := a.store.GetStorageQuotas(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	storageUsage := &model.StorageUsage{
+		TeamID: teamID,
+		Quota:  a.config.TeamStorageQuota,
+		Boards: []*model.BoardStorageUsage{},
+		Users:  []*model.UserStorageUsage{},
+	}
+
+	boards := map[string]*model.BoardStorageUsage{}
+	getBoard := func(boardID string) *model.BoardStorageUsage {
+		board, ok := boards[boardID]
+		if !ok {
+			board = &model.BoardStorageUsage{BoardID: boardID, Quota: a.config.BoardStorageQuota}
+			boards[boardID] = board
+			storageUsage.Boards = append(storageUsage.Boards, board)
+		}
+		return board
+	}
+	users := map[string]*model.UserStorageUsage{}
+
+	for _, usage := range usages {
+		storageUsage.FileCount += usage.FileCount
+		storageUsage.TotalSize += usage.TotalSize
+
+		board := getBoard(usage.BoardID)
+		board.FileCount += usage.FileCount
+		board.TotalSize += usage.TotalSize
+
+		user, ok := users[usage.UserID]
+		if !ok {
+			user = &model.UserStorageUsage{UserID: usage.UserID}
+			users[usage.UserID] = user
+			storageUsage.Users = append(storageUsage.Users, user)
+		}
+		user.FileCount += usage.FileCount
+		user.TotalSize += usage.TotalSize
+	}
+
+	for _, quota := range quotas {
+		switch quota.Scope {
+		case model.StorageQuotaScopeTeam:
+			storageUsage.Quota = quota.MaxSize
+		case model.StorageQuotaScopeBoard:
+			getBoard(quota.ScopeID).Quota = quota.MaxSize
+		}
+	}
+
+	sort.Slice(storageUsage.Boards, func(i, j int) bool {
+		return storageUsage.Boards[i].TotalSize > storageUsage.Boards[j].TotalSize
+	})
+	sort.Slice(storageUsage.Users, func(i, j int) bool {
+		return storageUsage.Users[i].TotalSize > storageUsage.Users[j].TotalSize
+	})
+	return storageUsage, nil
+}
+
+// SetStorageQuota overrides the default storage quota of a team or a
+// board.
+func (a *App) SetStorageQuota(quota *model.StorageQuota) error {
+	if err := quota.IsValid(); err != nil {
+		return err
+	}
+	quota.UpdateAt = utils.GetMillis()
+	return a.store.SetStorageQuota(quota)
+}
+
+// DeleteStorageQuota removes the storage quota of a team or a board,
+// which goes back to the default one.
+func (a *App) DeleteStorageQuota(scope model.StorageQuotaScope, scopeID string) error {
+	if !scope.IsValid() {
+		return model.NewErrBadRequest("invalid storage quota scope")
+	}
+	return a.store.DeleteStorageQuota(scope, scopeID)
+}
+
+// checkStorageQuota returns an ErrStorageQuotaExceeded if a file of the
+// given size doesn't fit in the storage quota of its board or team.
+// Concurrent uploads are checked against the same usage, so they can
+// exceed a quota by the size of the files uploaded at the same time.
+func (a *App) checkStorageQuota(teamID, boardID string, size int64) error {
+	boardQuota, err := a.getStorageQuota(model.StorageQuotaScopeBoard, boardID, a.config.BoardStorageQuota)
+	if err != nil {
+		return err
+	}
+	teamQuota, err := a.getStorageQuota(model.StorageQuotaScopeTeam, teamID, a.config.TeamStorageQuota)
+	if err != nil {
+		return err
+	}
+	if boardQuota <= 0 && teamQuota <= 0 {
+		return nil
+	}
+
+	boardSize, teamSize, err := a.store.GetFileUsageTotals(teamID, boardID)
+	if err != nil {
+		return err
+	}
+
+	if boardQuota > 0 && boardSize+size > boardQuota {
+		return model.NewErrStorageQuotaExceeded(model.StorageQuotaBreach{
+			Scope:   model.StorageQuotaScopeBoard,
+			ScopeID: boardID,
+			Quota:   boardQuota,
+			Used:    boardSize,
+			Size:    size,
+		})
+	}
+
+	if teamQuota > 0 && teamSize+size > teamQuota {
+		return model.NewErrStorageQuotaExceeded(model.StorageQuotaBreach{
+			Scope:   model.StorageQuotaScopeTeam,
+			ScopeID: teamID,
+			Quota:   teamQuota,
+			Used:    teamSize,
+			Size:    size,
+		})
+	}
+	return nil
+}
+
+// getStorageQuota returns the storage quota of a team or a board, the
+// default one unless it is overridden.
+func (a *App) getStorageQuota(scope model.StorageQuotaScope, scopeID string, defaultQuota int64) (int64, error) {
+	quota, err := a.store.GetStorageQuota(scope, scopeID)
+	if model.IsErrNotFound(err) {
+		return defaultQuota, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return quota.MaxSize, nil
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore/mocks"
+)
+
+func TestGetStorageUsage(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.TeamStorageQuota = 1000
+	th.App.config.BoardStorageQuota = 300
+	th.Store.EXPECT().GetFileUsage("team-id").Return([]*model.FileUsage{
+		{TeamID: "team-id", BoardID: "board-1", UserID: "user-1", FileCount: 2, TotalSize: 150},
+		{TeamID: "team-id", BoardID: "board-1", UserID: "user-2", FileCount: 1, TotalSize: 10},
+		{TeamID: "team-id", BoardID: "board-2", UserID: "user-2", FileCount: 3, TotalSize: 200},
+	}, nil)
+	th.Store.EXPECT().GetStorageQuotas("team-id").Return([]*model.StorageQuota{
+		{Scope: model.StorageQuotaScopeBoard, ScopeID: "board-1", TeamID: "team-id", MaxSize: 500},
+		{Scope: model.StorageQuotaScopeBoard, ScopeID: "board-3", TeamID: "team-id", MaxSize: 0},
+	}, nil)
+
+	usage, err := th.App.GetStorageUsage("team-id")
+	require.NoError(t, err)
+	require.Equal(t, &model.StorageUsage{
+		TeamID:    "team-id",
+		FileCount: 6,
+		TotalSize: 360,
+		Quota:     1000,
+		Boards: []*model.BoardStorageUsage{
+			{BoardID: "board-2", FileCount: 3, TotalSize: 200, Quota: 300},
+			{BoardID: "board-1", FileCount: 3, TotalSize: 160, Quota: 500},
+			{BoardID: "board-3", Quota: 0},
+		},
+		Users: []*model.UserStorageUsage{
+			{UserID: "user-2", FileCount: 4, TotalSize: 210},
+			{UserID: "user-1", FileCount: 2, TotalSize: 150},
+		},
+	}, usage)
+}
+
+func TestCheckStorageQuota(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.Store.EXPECT().GetStorageQuota(model.StorageQuotaScopeTeam, "team-id").Return(
+		&model.StorageQuota{Scope: model.StorageQuotaScopeTeam, ScopeID: "team-id", TeamID: "team-id", MaxSize: 400}, nil).AnyTimes()
+	th.Store.EXPECT().GetStorageQuota(model.StorageQuotaScopeBoard, gomock.Any()).Return(nil, model.NewErrNotFound("storage quota")).AnyTimes()
+	th.Store.EXPECT().GetFileUsageTotals("team-id", "board-1").Return(int64(150), int64(150), nil).AnyTimes()
+	th.Store.EXPECT().GetFileUsageTotals("team-id", "board-2").Return(int64(0), int64(150), nil).AnyTimes()
+
+	t.Run("files that fit are allowed", func(t *testing.T) {
+		th.App.config.BoardStorageQuota = 0
+		require.NoError(t, th.App.checkStorageQuota("team-id", "board-1", 250))
+	})
+
+	t.Run("files over the team quota are rejected", func(t *testing.T) {
+		th.App.config.BoardStorageQuota = 0
+		err := th.App.checkStorageQuota("team-id", "board-2", 251)
+		require.True(t, model.IsErrStorageQuotaExceeded(err))
+
+		var sqe *model.ErrStorageQuotaExceeded
+		require.ErrorAs(t, err, &sqe)
+		require.Equal(t, model.StorageQuotaBreach{
+			Scope:   model.StorageQuotaScopeTeam,
+			ScopeID: "team-id",
+			Quota:   400,
+			Used:    150,
+			Size:    251,
+		}, sqe.Breach)
+	})
+
+	t.Run("the default board quota applies to boards without files", func(t *testing.T) {
+		th.App.config.BoardStorageQuota = 100
+		err := th.App.checkStorageQuota("team-id", "board-2", 101)
+
+		var sqe *model.ErrStorageQuotaExceeded
+		require.ErrorAs(t, err, &sqe)
+		require.Equal(t, model.StorageQuotaScopeBoard, sqe.Breach.Scope)
+		require.Equal(t, "board-2", sqe.Breach.ScopeID)
+		require.Equal(t, int64(0), sqe.Breach.Used)
+	})
+
+	t.Run("uploads over quota are not stored", func(t *testing.T) {
+		th.App.config.BoardStorageQuota = 100
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		_, err := th.App.SaveFile(bytes.NewReader(make([]byte, 200)), "team-id", "board-2", "user-id", "file.txt", false)
+		require.True(t, model.IsErrStorageQuotaExceeded(err))
+		mockedFileBackend.AssertNotCalled(t, "WriteFile")
+	})
+}
+
+func TestCheckStorageQuotaWithoutQuotas(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	// the usage is only read when a quota applies
+	th.Store.EXPECT().GetStorageQuota(gomock.Any(), gomock.Any()).Return(nil, model.NewErrNotFound("storage quota")).Times(2)
+	th.Store.EXPECT().GetFileUsageTotals(gomock.Any(), gomock.Any()).Times(0)
+
+	require.NoError(t, th.App.checkStorageQuota("team-id", "board-1", 1000))
+}
@@ -53,7 +53,11 @@ func (a *App) initializeTemplates() (bool, error) {
 		BlockModifier: fixTemplateBlock,
 		BoardModifier: fixTemplateBoard,
 	}
-	if err = a.ImportArchive(r, opt); err != nil {
+	report, err := a.ImportArchive(r, opt)
+	if err == nil && len(report.Errors) > 0 {
+		err = report.Errors[0]
+	}
+	if err != nil {
 		return false, fmt.Errorf("cannot initialize global templates for team %s: %w", model.GlobalTeamID, err)
 	}
 	return true, nil
@@ -45,11 +45,11 @@ func TestApp_initializeTemplates(t *testing.T) {
 
 		th.Store.EXPECT().GetTemplateBoards(model.GlobalTeamID, "").Return([]*model.Board{}, nil)
 		th.Store.EXPECT().RemoveDefaultTemplates([]*model.Board{}).Return(nil)
-		th.Store.EXPECT().CreateBoardsAndBlocks(gomock.Any(), gomock.Any()).AnyTimes().Return(boardsAndBlocks, nil)
+		th.Store.EXPECT().CreateBoardsAndBlocksWithMembers(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(boardsAndBlocks, []*model.BoardMember{boardMember}, nil)
 		th.Store.EXPECT().GetMembersForBoard(board.ID).AnyTimes().Return([]*model.BoardMember{}, nil)
-		th.Store.EXPECT().GetBoard(board.ID).AnyTimes().Return(board, nil)
-		th.Store.EXPECT().GetMemberForBoard(gomock.Any(), gomock.Any()).AnyTimes().Return(boardMember, nil)
-		th.Store.EXPECT().SaveFileInfo(gomock.Any()).Return(nil).AnyTimes()
+		th.Store.EXPECT().GetStorageQuota(gomock.Any(), gomock.Any()).Return(nil, model.NewErrNotFound("storage quota")).AnyTimes()
+		th.Store.EXPECT().GetUploadPolicy(gomock.Any()).Return(nil, model.NewErrNotFound("upload policy")).AnyTimes()
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 		th.FilesBackend.On("WriteFile", mock.Anything, mock.Anything).Return(int64(1), nil)
 
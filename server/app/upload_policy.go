@@ -0,0 +1,61 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// GetUploadPolicy returns the upload policy of a team, which is the
+// default one of the server configuration unless the team overrides it.
+func (a *App) GetUploadPolicy(teamID string) (*model.UploadPolicy, error) {
+	policy, err := a.store.GetUploadPolicy(teamID)
+	if model.IsErrNotFound(err) {
+		return a.defaultUploadPolicy(teamID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// SaveUploadPolicy overrides the default upload policy of a team.
+func (a *App) SaveUploadPolicy(policy *model.UploadPolicy) error {
+	if err := policy.IsValid(); err != nil {
+		return err
+	}
+	policy.UpdateAt = utils.GetMillis()
+	return a.store.SaveUploadPolicy(policy)
+}
+
+// DeleteUploadPolicy removes the upload policy of a team, which goes
+// back to the default one.
+func (a *App) DeleteUploadPolicy(teamID string) error {
+	return a.store.DeleteUploadPolicy(teamID)
+}
+
+func (a *App) defaultUploadPolicy(teamID string) *model.UploadPolicy {
+	orEmpty := func(list []string) []string {
+		if list == nil {
+			return []string{}
+		}
+		return list
+	}
+
+	return &model.UploadPolicy{
+		TeamID:            teamID,
+		AllowedMimeTypes:  orEmpty(a.config.UploadAllowedMimeTypes),
+		DeniedMimeTypes:   orEmpty(a.config.UploadDeniedMimeTypes),
+		AllowedExtensions: orEmpty(a.config.UploadAllowedExtensions),
+		DeniedExtensions:  orEmpty(a.config.UploadDeniedExtensions),
+	}
+}
+
+// checkUploadPolicy returns an ErrUploadRejected if the upload policy
+// of a team doesn't allow a file with an extension and a MIME type.
+func (a *App) checkUploadPolicy(teamID, extension, mimeType string) error {
+	policy, err := a.GetUploadPolicy(teamID)
+	if err != nil {
+		return err
+	}
+	return policy.Check(extension, mimeType)
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"path"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// quarantineRoot is the directory of the files storage infected
+	// files are moved to, out of the reach of the file garbage collection.
+	quarantineRoot = "quarantine"
+
+	blockedField       = "blocked"
+	blockedReasonField = "blockedReason"
+)
+
+// enqueueUploadScan scans an uploaded file in the background, if an
+// upload scanner is configured.
+func (a *App) enqueueUploadScan(fileInfo *mm_model.FileInfo, teamID, boardID, fileName string) {
+	if a.scanner == nil {
+		return
+	}
+
+	a.uploadScanQueue.Enqueue(func() error {
+		return a.scanUploadedFile(fileInfo, teamID, boardID, fileName)
+	})
+}
+
+// scanUploadedFile scans a stored file and records the verdict.
+// Infected files are quarantined, and the blocks they are attached to
+// are marked as blocked. Files that can't be scanned are still served.
+func (a *App) scanUploadedFile(fileInfo *mm_model.FileInfo, teamID, boardID, fileName string) error {
+	reader, err := a.filesBackend.Reader(fileInfo.Path)
+	if err != nil {
+		a.logger.Error("scanUploadedFile: cannot read file", mlog.String("path", fileInfo.Path), mlog.Err(err))
+		return err
+	}
+	defer reader.Close()
+
+	scanResult := &model.FileScanResult{
+		FileID: fileInfo.Id,
+		Status: model.FileScanStatusClean,
+	}
+
+	result, err := a.scanner.Scan(context.Background(), reader)
+	scanResult.ScannedAt = utils.GetMillis()
+	switch {
+	case err != nil:
+		a.logger.Error("scanUploadedFile: scan failed", mlog.String("path", fileInfo.Path), mlog.Err(err))
+		scanResult.Status = model.FileScanStatusFailed
+	case result.Infected:
+		a.logger.Warn("scanUploadedFile: infected file uploaded",
+			mlog.String("path", fileInfo.Path),
+			mlog.String("signature", result.Signature),
+			mlog.String("creator_id", fileInfo.CreatorId),
+		)
+		scanResult.Status = model.FileScanStatusInfected
+		scanResult.Signature = result.Signature
+		scanResult.QuarantinePath = a.quarantineFile(fileInfo)
+	}
+
+	if err := a.store.SaveFileScanResult(scanResult); err != nil {
+		return err
+	}
+
+	if scanResult.Status == model.FileScanStatusInfected {
+		return a.blockFileBlocks(teamID, boardID, fileName, scanResult.Signature)
+	}
+	return nil
+}
+
+// copyFileScanResult gives the copy of a file the scan verdict of the
+// original, as verdicts are recorded by file info and copies may share
+// the blob of the original. Copies of files that have not been scanned
+// yet, for instance because their scan is still queued, are scanned
+// themselves.
+func (a *App) copyFileScanResult(sourceFileInfoID string, fileInfo *mm_model.FileInfo, teamID, boardID, fileName string) error {
+	if sourceFileInfoID == "" {
+		a.enqueueUploadScan(fileInfo, teamID, boardID, fileName)
+		return nil
+	}
+
+	scanResult, err := a.store.GetFileScanResult(sourceFileInfoID)
+	if model.IsErrNotFound(err) {
+		a.enqueueUploadScan(fileInfo, teamID, boardID, fileName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	copied := *scanResult
+	copied.FileID = fileInfo.Id
+	return a.store.SaveFileScanResult(&copied)
+}
+
+// quarantineFile moves an infected file out of the uploads and removes
+// its renditions, returning where it was moved. Blobs and their
+// renditions are shared by every upload of the same content, so they
+// are left in place: the file is quarantined by its scan result, which
+// keeps it from being served, and its blob is removed by the file
+// garbage collection once no file info references it. Files that can't
+// be moved are left in place, and are still never served.
+func (a *App) quarantineFile(fileInfo *mm_model.FileInfo) string {
+	if _, isBlob := model.FileBlobHash(fileInfo.Path); isBlob {
+		return ""
+	}
+
+	quarantinePath := path.Join(quarantineRoot, fileInfo.Path)
+	if err := a.filesBackend.MoveFile(fileInfo.Path, quarantinePath); err != nil {
+		a.logger.Error("quarantineFile: cannot move file", mlog.String("path", fileInfo.Path), mlog.Err(err))
+		quarantinePath = fileInfo.Path
+	}
+
+	for _, p := range []string{fileInfo.ThumbnailPath, fileInfo.PreviewPath} {
+		if p == "" {
+			continue
+		}
+		if err := a.filesBackend.RemoveFile(p); err != nil {
+			a.logger.Error("quarantineFile: cannot remove rendition", mlog.String("path", p), mlog.Err(err))
+		}
+	}
+	return quarantinePath
+}
+
+// blockFileBlocks marks the image and attachment blocks of a board
+// holding a file as blocked, so clients show why it can't be opened.
+// Blocks created after the scan are not marked, but the file is never
+// served to them either.
+func (a *App) blockFileBlocks(teamID, boardID, fileName, signature string) error {
+	blockIDs := []string{}
+	blockPatches := []model.BlockPatch{}
+	for _, blockType := range []string{model.TypeImage, model.TypeAttachment} {
+		blocks, err := a.store.GetBlocksWithType(boardID, blockType)
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			fileID, _ := block.Fields["fileId"].(string)
+			attachmentID, _ := block.Fields["attachmentId"].(string)
+			if fileID != fileName && attachmentID != fileName {
+				continue
+			}
+			blockIDs = append(blockIDs, block.ID)
+			blockPatches = append(blockPatches, model.BlockPatch{
+				UpdatedFields: map[string]interface{}{
+					blockedField:       true,
+					blockedReasonField: "malware detected: " + signature,
+				},
+			})
+		}
+	}
+	if len(blockIDs) == 0 {
+		return nil
+	}
+
+	patches := &model.BlockPatchBatch{
+		BlockIDs:     blockIDs,
+		BlockPatches: blockPatches,
+	}
+	return a.PatchBlocks(teamID, patches, model.SystemUserID)
+}
+
+// checkFileNotBlocked returns an ErrPermission if a file was found
+// infected by the upload scanner.
+func (a *App) checkFileNotBlocked(fileInfo *mm_model.FileInfo) error {
+	if fileInfo == nil || fileInfo.Id == "" {
+		return nil
+	}
+
+	scanResult, err := a.store.GetFileScanResult(fileInfo.Id)
+	if model.IsErrNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if scanResult.Status == model.FileScanStatusInfected {
+		return model.NewErrPermission("file blocked by the upload scanner")
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/scanner"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore/mocks"
+)
+
+const eicarTestFile = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!H+H*`
+
+type readCloseSeeker struct {
+	*bytes.Reader
+}
+
+func (readCloseSeeker) Close() error { return nil }
+
+func newReadCloseSeeker(content string) ReadCloseSeeker {
+	return readCloseSeeker{bytes.NewReader([]byte(content))}
+}
+
+func TestScanUploadedFile(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.scanner = scanner.NewEICARScanner()
+
+	fileInfo := &mm_model.FileInfo{
+		Id:            "fileInfoID",
+		Path:          "boards/20240101/7fileInfoID.png",
+		ThumbnailPath: "boards/20240101/7fileInfoID_thumb.jpg",
+	}
+
+	t.Run("clean files are recorded", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("Reader", fileInfo.Path).Return(newReadCloseSeeker("clean"), nil)
+		th.Store.EXPECT().SaveFileScanResult(gomock.Any()).DoAndReturn(func(result *model.FileScanResult) error {
+			require.Equal(t, model.FileScanStatusClean, result.Status)
+			return nil
+		})
+
+		require.NoError(t, th.App.scanUploadedFile(fileInfo, "team-id", "board-id", "7fileInfoID.png"))
+		mockedFileBackend.AssertNotCalled(t, "MoveFile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("infected files are quarantined and their blocks blocked", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("Reader", fileInfo.Path).Return(newReadCloseSeeker(eicarTestFile), nil)
+		mockedFileBackend.On("MoveFile", fileInfo.Path, "quarantine/"+fileInfo.Path).Return(nil)
+		mockedFileBackend.On("RemoveFile", fileInfo.ThumbnailPath).Return(nil)
+
+		th.Store.EXPECT().SaveFileScanResult(gomock.Any()).DoAndReturn(func(result *model.FileScanResult) error {
+			require.Equal(t, model.FileScanStatusInfected, result.Status)
+			require.Equal(t, "Eicar-Test-Signature", result.Signature)
+			require.Equal(t, "quarantine/"+fileInfo.Path, result.QuarantinePath)
+			return nil
+		})
+
+		imageBlock := &model.Block{ID: "image-1", BoardID: "board-id", Type: model.TypeImage, Fields: map[string]interface{}{"fileId": "7fileInfoID.png"}}
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeImage).Return([]*model.Block{
+			imageBlock,
+			{ID: "image-2", BoardID: "board-id", Type: model.TypeImage, Fields: map[string]interface{}{"fileId": "7otherxxxx.png"}},
+		}, nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeAttachment).Return([]*model.Block{}, nil)
+		th.Store.EXPECT().GetBlocksByIDs([]string{"image-1"}).Return([]*model.Block{imageBlock}, nil)
+		th.Store.EXPECT().PatchBlocks(gomock.Any(), model.SystemUserID).DoAndReturn(func(patches *model.BlockPatchBatch, _ string) error {
+			require.Equal(t, []string{"image-1"}, patches.BlockIDs)
+			require.Equal(t, true, patches.BlockPatches[0].UpdatedFields["blocked"])
+			return nil
+		})
+		// these calls come from the notification of the change
+		th.Store.EXPECT().GetBlock("image-1").Return(imageBlock, nil).AnyTimes()
+		th.Store.EXPECT().GetMembersForBoard(gomock.Any()).AnyTimes()
+
+		require.NoError(t, th.App.scanUploadedFile(fileInfo, "team-id", "board-id", "7fileInfoID.png"))
+		mockedFileBackend.AssertExpectations(t)
+	})
+
+	t.Run("infected blobs are left in place for the other uploads", func(t *testing.T) {
+		blobPath := contentBlobPath(eicarTestFile)
+		blobInfo := &mm_model.FileInfo{
+			Id:            "blobInfoID",
+			Path:          blobPath,
+			ThumbnailPath: renditionPath(blobPath, model.FileRenditionThumb),
+		}
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("Reader", blobPath).Return(newReadCloseSeeker(eicarTestFile), nil)
+
+		th.Store.EXPECT().SaveFileScanResult(gomock.Any()).DoAndReturn(func(result *model.FileScanResult) error {
+			require.Equal(t, "blobInfoID", result.FileID)
+			require.Equal(t, model.FileScanStatusInfected, result.Status)
+			require.Empty(t, result.QuarantinePath)
+			return nil
+		})
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeImage).Return([]*model.Block{}, nil)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeAttachment).Return([]*model.Block{}, nil)
+
+		require.NoError(t, th.App.scanUploadedFile(blobInfo, "team-id", "board-id", "7blobInfoID.png"))
+		mockedFileBackend.AssertNotCalled(t, "MoveFile", mock.Anything, mock.Anything)
+		mockedFileBackend.AssertNotCalled(t, "RemoveFile", mock.Anything)
+	})
+}
+
+func TestGetBlockedFile(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.Store.EXPECT().GetFileInfo("fileInfoID").Return(&mm_model.FileInfo{
+		Id:   "fileInfoID",
+		Path: "/path/to/file/fileName.txt",
+	}, nil)
+	th.Store.EXPECT().GetFileScanResult("fileInfoID").Return(&model.FileScanResult{
+		FileID: "fileInfoID",
+		Status: model.FileScanStatusInfected,
+	}, nil)
+
+	_, _, err := th.App.GetFile("teamID", "boardID", "7fileInfoID")
+	require.True(t, model.IsErrForbidden(err))
+}
+
+func TestGetCopiedInfectedFile(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	blobPath := contentBlobPath(eicarTestFile)
+	board := &model.Board{ID: "board-id", TeamID: "team-id"}
+	th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+	th.Store.EXPECT().GetFileInfo("fileInfoID").Return(&mm_model.FileInfo{Id: "fileInfoID", Path: blobPath}, nil)
+	th.Store.EXPECT().GetFileScanResult("fileInfoID").Return(&model.FileScanResult{
+		FileID:    "fileInfoID",
+		Status:    model.FileScanStatusInfected,
+		Signature: "Eicar-Signature",
+	}, nil)
+
+	var copied *mm_model.FileInfo
+	var copiedScanResult *model.FileScanResult
+	th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "team-id", "board-id").DoAndReturn(func(fileInfo *mm_model.FileInfo, _, _ string) error {
+		copied = fileInfo
+		return nil
+	})
+	th.Store.EXPECT().SaveFileScanResult(gomock.Any()).DoAndReturn(func(result *model.FileScanResult) error {
+		copiedScanResult = result
+		return nil
+	})
+
+	mockedFileBackend := &mocks.FileBackend{}
+	th.App.filesBackend = mockedFileBackend
+
+	blocks := []*model.Block{{
+		ID:      "image-id",
+		BoardID: "board-id",
+		Type:    model.TypeImage,
+		Fields:  map[string]interface{}{"fileId": "7fileInfoID.png"},
+	}}
+	newFileNames, err := th.App.CopyCardFiles("board-id", "user-id", blocks, false)
+	require.NoError(t, err)
+	require.Equal(t, copied.Id, copiedScanResult.FileID)
+	require.Equal(t, model.FileScanStatusInfected, copiedScanResult.Status)
+
+	th.Store.EXPECT().GetFileInfo(copied.Id).Return(copied, nil)
+	th.Store.EXPECT().GetFileScanResult(copied.Id).Return(copiedScanResult, nil)
+
+	_, _, err = th.App.GetFile("team-id", "board-id", newFileNames["7fileInfoID.png"])
+	require.True(t, model.IsErrForbidden(err))
+	mockedFileBackend.AssertNotCalled(t, "Reader", mock.Anything)
+}
+
+func TestSaveFileUploadPolicy(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.Store.EXPECT().GetStorageQuota(gomock.Any(), gomock.Any()).Return(nil, model.NewErrNotFound("storage quota")).AnyTimes()
+	th.Store.EXPECT().GetUploadPolicy("team-id").Return(&model.UploadPolicy{
+		TeamID:          "team-id",
+		DeniedMimeTypes: []string{"application/x-msdownload"},
+	}, nil).AnyTimes()
+
+	testCases := []struct {
+		name   string
+		reader func(content string) io.Reader
+	}{
+		{"seekable uploads", func(content string) io.Reader { return strings.NewReader(content) }},
+		{"streamed uploads", func(content string) io.Reader { return io.MultiReader(strings.NewReader(content)) }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockedFileBackend := &mocks.FileBackend{}
+			th.App.filesBackend = mockedFileBackend
+
+			// executables are sniffed from their content, whatever their
+			// extension
+			_, err := th.App.SaveFile(tc.reader(string(testPortableExecutable())), "team-id", "board-id", "user-id", "image.png", false)
+			require.True(t, model.IsErrUploadRejected(err))
+			mockedFileBackend.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("streamed uploads are stored whole after sniffing", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		content := strings.Repeat("text ", 200)
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(func(reader io.Reader, path string) int64 {
+			stored, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, content, string(stored))
+			return int64(len(stored))
+		}, nil)
+		mockedFileBackend.On("FileExists", mock.Anything).Return(false, nil)
+		mockedFileBackend.On("MoveFile", mock.Anything, mock.Anything).Return(nil)
+		th.Store.EXPECT().SaveFileInfoWithUsage(gomock.Any(), "team-id", "board-id").DoAndReturn(func(fileInfo *mm_model.FileInfo, _, _ string) error {
+			require.Equal(t, "text/plain; charset=utf-8", fileInfo.MimeType)
+			return nil
+		})
+
+		_, err := th.App.SaveFile(io.MultiReader(strings.NewReader(content)), "team-id", "board-id", "user-id", "notes.txt", false)
+		require.NoError(t, err)
+	})
+}
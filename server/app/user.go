@@ -92,3 +92,28 @@ func (a *App) SanitizeProfile(user *model.User, isAdmin bool) {
 	}
 	user.Sanitize(options)
 }
+
+// findTeamUser returns the user with a username, or else with an email,
+// nil if there is none among the members of a team. Users of other teams
+// are not returned, so that their existence isn't disclosed.
+func (a *App) findTeamUser(teamID, username, email string) (*model.User, error) {
+	if username != "" {
+		user, err := a.store.GetUserByUsername(username)
+		if err != nil && !model.IsErrNotFound(err) {
+			return nil, err
+		}
+		if user != nil && a.permissions.HasPermissionToTeam(user.ID, teamID, model.PermissionViewTeam) {
+			return user, nil
+		}
+	}
+	if email != "" {
+		user, err := a.store.GetUserByEmail(email)
+		if err != nil && !model.IsErrNotFound(err) {
+			return nil, err
+		}
+		if user != nil && a.permissions.HasPermissionToTeam(user.ID, teamID, model.PermissionViewTeam) {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
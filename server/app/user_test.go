@@ -83,3 +83,41 @@ func TestSearchUsers(t *testing.T) {
 		assert.Equal(t, 0, len(channels))
 	})
 }
+
+func TestFindTeamUser(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	teamID := "team-id-1"
+	member := &model.User{ID: "user-id-1", Username: "alice", Email: "alice@example.com"}
+	outsider := &model.User{ID: "user-id-2", Username: "bob", Email: "bob@example.com"}
+
+	t.Run("returns a member of the team by username", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("alice").Return(member, nil)
+		th.API.EXPECT().HasPermissionToTeam(member.ID, teamID, model.PermissionViewTeam).Return(true)
+
+		user, err := th.App.findTeamUser(teamID, "alice", "")
+		assert.NoError(t, err)
+		assert.Equal(t, member, user)
+	})
+
+	t.Run("falls back to the email", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("unknown").Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().GetUserByEmail("alice@example.com").Return(member, nil)
+		th.API.EXPECT().HasPermissionToTeam(member.ID, teamID, model.PermissionViewTeam).Return(true)
+
+		user, err := th.App.findTeamUser(teamID, "unknown", "alice@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, member, user)
+	})
+
+	t.Run("doesn't return users of other teams", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("bob").Return(outsider, nil)
+		th.Store.EXPECT().GetUserByEmail("bob@example.com").Return(outsider, nil)
+		th.API.EXPECT().HasPermissionToTeam(outsider.ID, teamID, model.PermissionViewTeam).Return(false).Times(2)
+
+		user, err := th.App.findTeamUser(teamID, "bob", "bob@example.com")
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+	})
+}
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// wipColumn identifies a column of a board grouped by a select property.
+type wipColumn struct {
+	propertyID string
+	optionID   string
+}
+
+// getWIPLimitedOptions returns the select options of a board that have a WIP limit,
+// keyed by property ID and then by option ID.
+func getWIPLimitedOptions(board *model.Board) map[string]map[string]model.PropDefOption {
+	limited := map[string]map[string]model.PropDefOption{}
+
+	schema, err := model.ParsePropertySchema(board)
+	if err != nil {
+		return limited
+	}
+
+	for propID, propDef := range schema {
+		if propDef.Type != "select" {
+			continue
+		}
+		for optID, opt := range propDef.Options {
+			if opt.WIPLimit <= 0 {
+				continue
+			}
+			if limited[propID] == nil {
+				limited[propID] = map[string]model.PropDefOption{}
+			}
+			limited[propID][optID] = opt
+		}
+	}
+	return limited
+}
+
+// countCardsPerWIPColumn counts the cards in each of the limited columns.
+// Archived cards are not counted.
+func countCardsPerWIPColumn(cards []*model.Block, limited map[string]map[string]model.PropDefOption) map[wipColumn]int {
+	counts := map[wipColumn]int{}
+	for _, card := range cards {
+		if model.IsArchivedCard(card) {
+			continue
+		}
+		props := getCardProperties(card)
+		for propID, options := range limited {
+			optID, _ := props[propID].(string)
+			if _, ok := options[optID]; ok {
+				counts[wipColumn{propertyID: propID, optionID: optID}]++
+			}
+		}
+	}
+	return counts
+}
+
+// newWIPLimitBreach builds the breach description of a column.
+func newWIPLimitBreach(column wipColumn, opt model.PropDefOption, count int) model.WIPLimitBreach {
+	mode := opt.WIPLimitMode
+	if mode != model.WIPLimitHard {
+		mode = model.WIPLimitSoft
+	}
+	return model.WIPLimitBreach{
+		PropertyID:  column.propertyID,
+		OptionID:    column.optionID,
+		OptionValue: opt.Value,
+		Limit:       opt.WIPLimit,
+		Count:       count,
+		Mode:        mode,
+	}
+}
+
+// checkWIPLimits verifies that applying the patches to the cards of a board does not move
+// cards into a column that is at its hard WIP limit. Users with the permission to override
+// WIP limits, as well as soft limits, only get the breach logged.
+// The cards and patches must be the same length and in the same order.
+func (a *App) checkWIPLimits(board *model.Board, cards []*model.Block, patches []*model.BlockPatch, userID string) error {
+	return a.checkWIPLimitsWithBoardCards(board, nil, cards, patches, userID)
+}
+
+// checkWIPLimitsWithBoardCards is checkWIPLimits for callers that already fetched the cards
+// of the board. If boardCards is nil, they are fetched when needed.
+func (a *App) checkWIPLimitsWithBoardCards(board *model.Board, boardCards, cards []*model.Block, patches []*model.BlockPatch, userID string) error {
+	limited := getWIPLimitedOptions(board)
+	if len(limited) == 0 {
+		return nil
+	}
+
+	// the net number of cards each column gains from the patches.
+	delta := map[wipColumn]int{}
+	for i, card := range cards {
+		if card.Type != model.TypeCard || model.IsArchivedCard(card) || !patchChangesProperties(patches[i]) {
+			continue
+		}
+
+		oldProps := getCardProperties(card)
+		newProps, _ := patches[i].UpdatedFields["properties"].(map[string]interface{})
+
+		for propID, options := range limited {
+			oldValue, _ := oldProps[propID].(string)
+			newValue, _ := newProps[propID].(string)
+			if oldValue == newValue {
+				continue
+			}
+			if _, ok := options[oldValue]; ok {
+				delta[wipColumn{propertyID: propID, optionID: oldValue}]--
+			}
+			if _, ok := options[newValue]; ok {
+				delta[wipColumn{propertyID: propID, optionID: newValue}]++
+			}
+		}
+	}
+
+	incoming := []wipColumn{}
+	for column, n := range delta {
+		if n > 0 {
+			incoming = append(incoming, column)
+		}
+	}
+	if len(incoming) == 0 {
+		return nil
+	}
+
+	if boardCards == nil {
+		var err error
+		if boardCards, err = a.store.GetBlocksWithType(board.ID, model.TypeCard); err != nil {
+			return err
+		}
+	}
+	counts := countCardsPerWIPColumn(boardCards, limited)
+
+	sortWIPColumns(incoming)
+	for _, column := range incoming {
+		opt := limited[column.propertyID][column.optionID]
+		count := counts[column] + delta[column]
+		if count <= opt.WIPLimit {
+			continue
+		}
+
+		breach := newWIPLimitBreach(column, opt, count)
+		if breach.Mode == model.WIPLimitHard && !a.permissions.HasPermissionToBoard(userID, board.ID, model.PermissionOverrideWIPLimits) {
+			return model.NewErrWIPLimitExceeded(breach)
+		}
+
+		a.logger.Debug("WIP limit exceeded",
+			mlog.String("board_id", board.ID),
+			mlog.String("property_id", column.propertyID),
+			mlog.String("option_id", column.optionID),
+			mlog.Int("limit", breach.Limit),
+			mlog.Int("count", count),
+			mlog.String("mode", string(breach.Mode)),
+			mlog.String("user_id", userID),
+		)
+	}
+	return nil
+}
+
+// checkWIPLimitsForPatchBatch runs checkWIPLimits for each board touched by a batch of
+// patches that changes card properties.
+func (a *App) checkWIPLimitsForPatchBatch(oldBlocks []*model.Block, blockPatches *model.BlockPatchBatch, userID string) error {
+	blocksByID := make(map[string]*model.Block, len(oldBlocks))
+	for _, block := range oldBlocks {
+		blocksByID[block.ID] = block
+	}
+
+	cardsByBoard := map[string][]*model.Block{}
+	patchesByBoard := map[string][]*model.BlockPatch{}
+	for i, blockID := range blockPatches.BlockIDs {
+		block, ok := blocksByID[blockID]
+		if !ok || block.Type != model.TypeCard || !patchChangesProperties(&blockPatches.BlockPatches[i]) {
+			continue
+		}
+		cardsByBoard[block.BoardID] = append(cardsByBoard[block.BoardID], block)
+		patchesByBoard[block.BoardID] = append(patchesByBoard[block.BoardID], &blockPatches.BlockPatches[i])
+	}
+
+	for boardID, cards := range cardsByBoard {
+		board, err := a.store.GetBoard(boardID)
+		if err != nil {
+			return err
+		}
+		if err := a.checkWIPLimits(board, cards, patchesByBoard[boardID], userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortWIPColumns(columns []wipColumn) {
+	sort.Slice(columns, func(i, j int) bool {
+		if columns[i].propertyID != columns[j].propertyID {
+			return columns[i].propertyID < columns[j].propertyID
+		}
+		return columns[i].optionID < columns[j].optionID
+	})
+}
+
+func patchChangesProperties(patch *model.BlockPatch) bool {
+	if _, ok := patch.UpdatedFields["properties"]; ok {
+		return true
+	}
+	for _, key := range patch.DeletedFields {
+		if key == "properties" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetWIPLimitBreaches returns the columns of a board that hold more cards than their WIP limit.
+func (a *App) GetWIPLimitBreaches(board *model.Board) ([]model.WIPLimitBreach, error) {
+	breaches := []model.WIPLimitBreach{}
+
+	limited := getWIPLimitedOptions(board)
+	if len(limited) == 0 {
+		return breaches, nil
+	}
+
+	cards, err := a.store.GetBlocksWithType(board.ID, model.TypeCard)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := countCardsPerWIPColumn(cards, limited)
+	columns := make([]wipColumn, 0, len(counts))
+	for column := range counts {
+		columns = append(columns, column)
+	}
+	sortWIPColumns(columns)
+
+	for _, column := range columns {
+		opt := limited[column.propertyID][column.optionID]
+		if counts[column] > opt.WIPLimit {
+			breaches = append(breaches, newWIPLimitBreach(column, opt, counts[column]))
+		}
+	}
+	return breaches, nil
+}
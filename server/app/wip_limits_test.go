@@ -0,0 +1,142 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/permissions/localpermissions"
+	"github.com/stretchr/testify/require"
+)
+
+func makeStatusPatch(status string) *model.BlockPatch {
+	return &model.BlockPatch{
+		UpdatedFields: map[string]interface{}{
+			"properties": map[string]interface{}{"status": status},
+		},
+	}
+}
+
+func TestCheckWIPLimits(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.permissions = localpermissions.New(th.Store, th.logger)
+
+	boardCards := []*model.Block{
+		makeTestCard("card-1", "doing"),
+		makeTestCard("card-2", "doing"),
+		makeTestCard("card-3", "todo"),
+	}
+
+	t.Run("hard limit rejects card moving into a full column", func(t *testing.T) {
+		board := makeTestBoard("board-id", "")
+		setTestWIPLimit(board, model.WIPLimitHard)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(boardCards, nil)
+		th.Store.EXPECT().GetMemberForBoard("board-id", "user-id").Return(&model.BoardMember{SchemeEditor: true}, nil)
+
+		err := th.App.checkWIPLimits(board, []*model.Block{boardCards[2]}, []*model.BlockPatch{makeStatusPatch("doing")}, "user-id")
+		require.True(t, model.IsErrWIPLimitExceeded(err))
+
+		var wipErr *model.ErrWIPLimitExceeded
+		require.ErrorAs(t, err, &wipErr)
+		require.Equal(t, "doing", wipErr.Breach.OptionID)
+		require.Equal(t, 2, wipErr.Breach.Limit)
+		require.Equal(t, 3, wipErr.Breach.Count)
+	})
+
+	t.Run("hard limit overridden by board admin", func(t *testing.T) {
+		board := makeTestBoard("board-id", "")
+		setTestWIPLimit(board, model.WIPLimitHard)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(boardCards, nil)
+		th.Store.EXPECT().GetMemberForBoard("board-id", "admin-id").Return(&model.BoardMember{SchemeAdmin: true}, nil)
+
+		err := th.App.checkWIPLimits(board, []*model.Block{boardCards[2]}, []*model.BlockPatch{makeStatusPatch("doing")}, "admin-id")
+		require.NoError(t, err)
+	})
+
+	t.Run("soft limit allows the change", func(t *testing.T) {
+		board := makeTestBoard("board-id", "")
+		setTestWIPLimit(board, model.WIPLimitSoft)
+		th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(boardCards, nil)
+
+		err := th.App.checkWIPLimits(board, []*model.Block{boardCards[2]}, []*model.BlockPatch{makeStatusPatch("doing")}, "user-id")
+		require.NoError(t, err)
+	})
+
+	t.Run("swapping cards keeps the column within its limit", func(t *testing.T) {
+		board := makeTestBoard("board-id", "")
+		setTestWIPLimit(board, model.WIPLimitHard)
+
+		cards := []*model.Block{boardCards[0], boardCards[2]}
+		patches := []*model.BlockPatch{makeStatusPatch("todo"), makeStatusPatch("doing")}
+		err := th.App.checkWIPLimits(board, cards, patches, "user-id")
+		require.NoError(t, err)
+	})
+
+	t.Run("changes not moving cards into a limited column are not checked", func(t *testing.T) {
+		board := makeTestBoard("board-id", "")
+		setTestWIPLimit(board, model.WIPLimitHard)
+		titlePatch := &model.BlockPatch{}
+
+		err := th.App.checkWIPLimits(board, []*model.Block{boardCards[2]}, []*model.BlockPatch{titlePatch}, "user-id")
+		require.NoError(t, err)
+
+		err = th.App.checkWIPLimits(board, []*model.Block{boardCards[0]}, []*model.BlockPatch{makeStatusPatch("todo")}, "user-id")
+		require.NoError(t, err)
+	})
+}
+
+func TestPatchBlocksWIPLimits(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.permissions = localpermissions.New(th.Store, th.logger)
+
+	board := makeTestBoard("board-id", "")
+	setTestWIPLimit(board, model.WIPLimitHard)
+	boardCards := []*model.Block{
+		makeTestCard("card-1", "doing"),
+		makeTestCard("card-2", "todo"),
+		makeTestCard("card-3", "todo"),
+	}
+
+	blockPatches := model.BlockPatchBatch{
+		BlockIDs:     []string{"card-2", "card-3"},
+		BlockPatches: []model.BlockPatch{*makeStatusPatch("doing"), *makeStatusPatch("doing")},
+	}
+
+	th.Store.EXPECT().GetBlocksByIDs(blockPatches.BlockIDs).Return(boardCards[1:], nil)
+	th.Store.EXPECT().GetBoard("board-id").Return(board, nil)
+	th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(boardCards, nil)
+	th.Store.EXPECT().GetMemberForBoard("board-id", "user-id").Return(&model.BoardMember{SchemeEditor: true}, nil)
+	th.Store.EXPECT().PatchBlocks(gomock.Any(), gomock.Any()).Times(0)
+
+	err := th.App.PatchBlocks("team-id", &blockPatches, "user-id")
+	require.True(t, model.IsErrWIPLimitExceeded(err))
+}
+
+func TestGetWIPLimitBreaches(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	board := makeTestBoard("board-id", "")
+	setTestWIPLimit(board, model.WIPLimitSoft)
+	boardCards := []*model.Block{
+		makeTestCard("card-1", "doing"),
+		makeTestCard("card-2", "doing"),
+		makeTestCard("card-3", "doing"),
+		makeTestCard("card-4", "todo"),
+	}
+
+	th.Store.EXPECT().GetBlocksWithType("board-id", model.TypeCard).Return(boardCards, nil)
+
+	breaches, err := th.App.GetWIPLimitBreaches(board)
+	require.NoError(t, err)
+	require.Equal(t, []model.WIPLimitBreach{{
+		PropertyID:  "status",
+		OptionID:    "doing",
+		OptionValue: "Doing",
+		Limit:       2,
+		Count:       3,
+		Mode:        model.WIPLimitSoft,
+	}}, breaches)
+}
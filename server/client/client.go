@@ -846,6 +846,25 @@ func (c *Client) TeamUploadFileInfo(teamID, boardID string, fileName string) (*m
 	return fileInfoResponse, BuildResponse(r)
 }
 
+func (c *Client) SignFileURL(teamID, boardID, fileName string, expiresIn int) (*model.FileSignedURL, *Response) {
+	var queryParams string
+	if expiresIn > 0 {
+		queryParams = fmt.Sprintf("?expires_in=%d", expiresIn)
+	}
+	r, err := c.DoAPIPost(fmt.Sprintf("/files/teams/%s/%s/%s/signed_url%s", teamID, boardID, fileName, queryParams), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var signedURL *model.FileSignedURL
+	if err := json.NewDecoder(r.Body).Decode(&signedURL); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return signedURL, BuildResponse(r)
+}
+
 func (c *Client) GetSubscriptionsRoute() string {
 	return "/subscriptions"
 }
@@ -918,15 +937,59 @@ func (c *Client) ExportBoardArchive(boardID string) ([]byte, *Response) {
 	return buf, BuildResponse(r)
 }
 
-func (c *Client) ImportArchive(teamID string, data io.Reader) *Response {
+func (c *Client) ImportArchive(teamID string, data io.Reader) (*model.ArchiveImportReport, *Response) {
+	return c.importArchive(teamID, data, false)
+}
+
+// ValidateArchive reports what importing an archive would do, without
+// importing it.
+func (c *Client) ValidateArchive(teamID string, data io.Reader) (*model.ArchiveImportReport, *Response) {
+	return c.importArchive(teamID, data, true)
+}
+
+func (c *Client) importArchive(teamID string, data io.Reader, dryRun bool) (*model.ArchiveImportReport, *Response) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(api.UploadFormFileKey, "file")
+	if err != nil {
+		return nil, &Response{Error: err}
+	}
+	if _, err = io.Copy(part, data); err != nil {
+		return nil, &Response{Error: err}
+	}
+	writer.Close()
+
+	opt := func(r *http.Request) {
+		r.Header.Add("Content-Type", writer.FormDataContentType())
+	}
+
+	route := c.APIURL + c.GetTeamRoute(teamID) + "/archive/import"
+	if dryRun {
+		route += "?dry_run=true"
+	}
+	r, err := c.doAPIRequestReader(http.MethodPost, route, body, "", opt)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var report *model.ArchiveImportReport
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return report, BuildResponse(r)
+}
+
+func (c *Client) ImportExternal(teamID, tool string, data io.Reader) (*model.ExternalImportResult, *Response) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 	part, err := writer.CreateFormFile(api.UploadFormFileKey, "file")
 	if err != nil {
-		return &Response{Error: err}
+		return nil, &Response{Error: err}
 	}
 	if _, err = io.Copy(part, data); err != nil {
-		return &Response{Error: err}
+		return nil, &Response{Error: err}
 	}
 	writer.Close()
 
@@ -934,7 +997,89 @@ func (c *Client) ImportArchive(teamID string, data io.Reader) *Response {
 		r.Header.Add("Content-Type", writer.FormDataContentType())
 	}
 
-	r, err := c.doAPIRequestReader(http.MethodPost, c.APIURL+c.GetTeamRoute(teamID)+"/archive/import", body, "", opt)
+	r, err := c.doAPIRequestReader(http.MethodPost, c.APIURL+c.GetTeamRoute(teamID)+"/import/"+tool, body, "", opt)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var result *model.ExternalImportResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return result, BuildResponse(r)
+}
+
+func (c *Client) GetExportJobsRoute(teamID string) string {
+	return fmt.Sprintf("%s/archive/exports", c.GetTeamRoute(teamID))
+}
+
+func (c *Client) GetExportJobRoute(teamID, exportID string) string {
+	return fmt.Sprintf("%s/%s", c.GetExportJobsRoute(teamID), exportID)
+}
+
+func (c *Client) CreateExportJob(teamID string, opts model.ExportJobOptions) (*model.ExportJob, *Response) {
+	r, err := c.DoAPIPost(c.GetExportJobsRoute(teamID), toJSON(opts))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var job *model.ExportJob
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return job, BuildResponse(r)
+}
+
+func (c *Client) GetExportJobs(teamID string) ([]*model.ExportJob, *Response) {
+	r, err := c.DoAPIGet(c.GetExportJobsRoute(teamID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var jobs []*model.ExportJob
+	if err := json.NewDecoder(r.Body).Decode(&jobs); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return jobs, BuildResponse(r)
+}
+
+func (c *Client) GetExportJob(teamID, exportID string) (*model.ExportJob, *Response) {
+	r, err := c.DoAPIGet(c.GetExportJobRoute(teamID, exportID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var job *model.ExportJob
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return job, BuildResponse(r)
+}
+
+func (c *Client) DownloadExport(teamID, exportID string) ([]byte, *Response) {
+	r, err := c.DoAPIGet(c.GetExportJobRoute(teamID, exportID)+"/download", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	buf, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return buf, BuildResponse(r)
+}
+
+func (c *Client) DeleteExportJob(teamID, exportID string) *Response {
+	r, err := c.DoAPIDelete(c.GetExportJobRoute(teamID, exportID), "")
 	if err != nil {
 		return BuildErrorResponse(r, err)
 	}
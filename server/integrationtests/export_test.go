@@ -3,6 +3,7 @@ package integrationtests
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/utils"
@@ -48,13 +49,27 @@ func TestExportBoard(t *testing.T) {
 		th.CheckOK(resp)
 		require.NotNil(t, buf)
 
+		// validating the archive imports nothing
+		report, resp := th.Client.ValidateArchive(model.GlobalTeamID, bytes.NewReader(buf))
+		th.CheckOK(resp)
+		require.True(t, report.DryRun)
+		require.Empty(t, report.Errors)
+		require.Len(t, report.Boards, 1)
+		require.Empty(t, report.Boards[0].BoardID)
+
+		boardsImported, err := th.Server.App().GetBoardsForUserAndTeam(th.GetUser1().ID, model.GlobalTeamID, true)
+		require.NoError(t, err)
+		require.Empty(t, boardsImported)
+
 		// import the archive file to team 0
-		resp = th.Client.ImportArchive(model.GlobalTeamID, bytes.NewReader(buf))
+		report, resp = th.Client.ImportArchive(model.GlobalTeamID, bytes.NewReader(buf))
 		th.CheckOK(resp)
 		require.NoError(t, resp.Error)
+		require.Empty(t, report.Errors)
+		require.Len(t, report.Boards, 1)
 
 		// check for test card
-		boardsImported, err := th.Server.App().GetBoardsForUserAndTeam(th.GetUser1().ID, model.GlobalTeamID, true)
+		boardsImported, err = th.Server.App().GetBoardsForUserAndTeam(th.GetUser1().ID, model.GlobalTeamID, true)
 		require.NoError(t, err)
 		require.Len(t, boardsImported, 1)
 		boardImported := boardsImported[0]
@@ -64,3 +79,107 @@ func TestExportBoard(t *testing.T) {
 		require.Equal(t, block.Title, blocksImported[0].Title)
 	})
 }
+
+func TestExportJobs(t *testing.T) {
+	t.Run("export a team in the background", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		board := &model.Board{
+			ID:        utils.NewID(utils.IDTypeBoard),
+			TeamID:    "test-team",
+			Title:     "Export Job Board",
+			CreatedBy: th.GetUser1().ID,
+			Type:      model.BoardTypeOpen,
+			CreateAt:  utils.GetMillis(),
+			UpdateAt:  utils.GetMillis(),
+		}
+		_, resp := th.Client.CreateBoardsAndBlocks(&model.BoardsAndBlocks{Boards: []*model.Board{board}})
+		th.CheckOK(resp)
+
+		job, resp := th.Client.CreateExportJob("test-team", model.ExportJobOptions{ExcludeFiles: true})
+		th.CheckOK(resp)
+		require.Equal(t, 1, job.BoardCount)
+		require.True(t, job.ExcludeFiles)
+
+		require.Eventually(t, func() bool {
+			job, resp = th.Client.GetExportJob("test-team", job.ID)
+			th.CheckOK(resp)
+			return job.IsFinished()
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, model.ExportJobStatusDone, job.Status)
+		require.Equal(t, 1, job.ExportedBoards)
+		require.NotZero(t, job.ExpireAt)
+
+		jobs, resp := th.Client.GetExportJobs("test-team")
+		th.CheckOK(resp)
+		require.Len(t, jobs, 1)
+
+		buf, resp := th.Client.DownloadExport("test-team", job.ID)
+		th.CheckOK(resp)
+		require.EqualValues(t, job.Size, len(buf))
+
+		report, resp := th.Client.ValidateArchive(model.GlobalTeamID, bytes.NewReader(buf))
+		th.CheckOK(resp)
+		require.Empty(t, report.Errors)
+		require.Len(t, report.Boards, 1)
+
+		resp = th.Client.DeleteExportJob("test-team", job.ID)
+		th.CheckOK(resp)
+
+		_, resp = th.Client.GetExportJob("test-team", job.ID)
+		th.CheckNotFound(resp)
+	})
+
+	t.Run("other users cannot see an export", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		job, resp := th.Client.CreateExportJob("test-team", model.ExportJobOptions{})
+		th.CheckOK(resp)
+
+		_, resp = th.Client2.GetExportJob("test-team", job.ID)
+		th.CheckNotFound(resp)
+
+		_, resp = th.Client2.DownloadExport("test-team", job.ID)
+		th.CheckNotFound(resp)
+	})
+}
+
+func TestImportExternal(t *testing.T) {
+	t.Run("import trello board", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		export := `{
+			"name": "Trello board",
+			"lists": [{"id": "list-1", "name": "To Do", "pos": 1}],
+			"members": [{"id": "member-1", "username": "` + th.GetUser1().Username + `", "fullName": "User 1"}],
+			"cards": [{"id": "card-1", "name": "Imported card", "idList": "list-1", "idMembers": ["member-1"]}]
+		}`
+
+		result, resp := th.Client.ImportExternal(model.GlobalTeamID, "trello", bytes.NewReader([]byte(export)))
+		th.CheckOK(resp)
+		require.Len(t, result.Boards, 1)
+		require.Equal(t, 1, result.CardCount)
+		require.Equal(t, []model.ExternalImportMember{{Name: "User 1", UserID: th.GetUser1().ID}}, result.Members)
+
+		blocks, err := th.Server.App().GetBlocksForBoard(result.Boards[0].ID)
+		require.NoError(t, err)
+		var titles []string
+		for _, block := range blocks {
+			if block.Type == model.TypeCard {
+				titles = append(titles, block.Title)
+			}
+		}
+		require.Equal(t, []string{"Imported card"}, titles)
+	})
+
+	t.Run("unsupported tool", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		_, resp := th.Client.ImportExternal(model.GlobalTeamID, "notion", bytes.NewReader([]byte("{}")))
+		th.CheckBadRequest(resp)
+	})
+}
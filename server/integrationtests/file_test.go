@@ -2,9 +2,13 @@ package integrationtests
 
 import (
 	"bytes"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
 
 	"github.com/stretchr/testify/require"
 )
@@ -86,3 +90,52 @@ func TestFileInfo(t *testing.T) {
 		require.NotNil(t, fileInfo.Id)
 	})
 }
+
+func TestSignedFileURL(t *testing.T) {
+	const (
+		testTeamID = "team-id"
+	)
+
+	t.Run("a user without permissions should be rejected", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		signedURL, resp := th.Client.SignFileURL(testTeamID, "not-valid-board", "7file.txt", 0)
+		th.CheckForbidden(resp)
+		require.Nil(t, signedURL)
+	})
+
+	t.Run("a signed URL should serve the file without a session", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		config := th.Server.App().GetConfig()
+		config.Secret = "test-secret"
+		th.Server.App().SetConfig(config)
+
+		testBoard := th.CreateBoard(testTeamID, model.BoardTypeOpen)
+		file, resp := th.Client.TeamUploadFile(testTeamID, testBoard.ID, bytes.NewBuffer([]byte("0123456789")))
+		th.CheckOK(resp)
+
+		signedURL, resp := th.Client.SignFileURL(testTeamID, testBoard.ID, file.FileID, 60)
+		th.CheckOK(resp)
+		require.NotNil(t, signedURL)
+		require.LessOrEqual(t, signedURL.ExpiresAt, utils.GetMillis()+60*1000)
+
+		req, err := http.NewRequest(http.MethodGet, signedURL.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", "bytes=2-5")
+		r, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer r.Body.Close()
+		require.Equal(t, http.StatusPartialContent, r.StatusCode)
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "2345", string(data))
+
+		r, err = http.Get(strings.Replace(signedURL.URL, "signature=", "signature=x", 1))
+		require.NoError(t, err)
+		defer r.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, r.StatusCode)
+	})
+}
@@ -0,0 +1,98 @@
+package model
+
+// ActivityType is the kind of change an activity entry describes.
+type ActivityType string
+
+const (
+	ActivityCardCreated       ActivityType = "cardCreated"
+	ActivityCardDeleted       ActivityType = "cardDeleted"
+	ActivityCardMoved         ActivityType = "cardMoved"
+	ActivityCardArchived      ActivityType = "cardArchived"
+	ActivityCardUnarchived    ActivityType = "cardUnarchived"
+	ActivityTitleChanged      ActivityType = "titleChanged"
+	ActivityPropertyChanged   ActivityType = "propertyChanged"
+	ActivityCommentAdded      ActivityType = "commentAdded"
+	ActivityCommentEdited     ActivityType = "commentEdited"
+	ActivityCommentDeleted    ActivityType = "commentDeleted"
+	ActivityAttachmentAdded   ActivityType = "attachmentAdded"
+	ActivityAttachmentRemoved ActivityType = "attachmentRemoved"
+	ActivityContentAdded      ActivityType = "contentAdded"
+	ActivityContentChanged    ActivityType = "contentChanged"
+	ActivityContentDeleted    ActivityType = "contentDeleted"
+)
+
+// ActivityEntry is a single change made to a card or to its content
+// swagger:model
+type ActivityEntry struct {
+	// The kind of change
+	// required: true
+	Type ActivityType `json:"type"`
+
+	// The board the change was made on
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The card that was changed, or that the changed block belongs to
+	// required: true
+	CardID string `json:"cardId"`
+
+	// The block that was changed
+	// required: true
+	BlockID string `json:"blockId"`
+
+	// The type of the block that was changed
+	// required: true
+	BlockType BlockType `json:"blockType"`
+
+	// The user that made the change
+	// required: true
+	UserID string `json:"userId"`
+
+	// The time of the change in milliseconds since the current epoch
+	// required: true
+	At int64 `json:"at"`
+
+	// The changed property, for propertyChanged
+	// required: false
+	PropertyID string `json:"propertyId,omitempty"`
+
+	// The name of the changed property, for propertyChanged
+	// required: false
+	PropertyName string `json:"propertyName,omitempty"`
+
+	// The value before the change, resolved to its display value for properties
+	// required: false
+	OldValue string `json:"oldValue,omitempty"`
+
+	// The value after the change, resolved to its display value for properties
+	// required: false
+	NewValue string `json:"newValue,omitempty"`
+}
+
+// ActivityFeed is a page of activity entries, newest first
+// swagger:model
+type ActivityFeed struct {
+	// The activity entries
+	// required: true
+	Entries []*ActivityEntry `json:"entries"`
+
+	// True if there are older entries
+	// required: true
+	HasMore bool `json:"hasMore"`
+
+	// The before parameter to use to fetch the next page
+	// required: false
+	NextBefore int64 `json:"nextBefore,omitempty"`
+
+	// The before_id parameter to use to fetch the next page
+	// required: false
+	NextBeforeID string `json:"nextBeforeId,omitempty"`
+}
+
+// QueryActivityOptions are query options that can be passed to GetActivity.
+type QueryActivityOptions struct {
+	CardID         string // if non-empty then only the activity of the card and its content is returned
+	BeforeUpdateAt int64  // if non-zero then only changes made before BeforeUpdateAt are returned
+	BeforeID       string // if non-empty then the changes made at BeforeUpdateAt to blocks with an id less than BeforeID are returned too
+	PerPage        int    // the maximum number of block revisions to read for the page
+}
@@ -0,0 +1,338 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// AutomationTriggerType is the kind of board event that fires an automation rule.
+type AutomationTriggerType string
+
+const (
+	AutomationTriggerCardCreated     AutomationTriggerType = "cardCreated"
+	AutomationTriggerPropertyChanged AutomationTriggerType = "propertyChanged"
+	AutomationTriggerCardMoved       AutomationTriggerType = "cardMoved"
+	AutomationTriggerCommentAdded    AutomationTriggerType = "commentAdded"
+	AutomationTriggerDueDatePassed   AutomationTriggerType = "dueDatePassed"
+)
+
+func (tt AutomationTriggerType) IsValid() bool {
+	switch tt {
+	case AutomationTriggerCardCreated, AutomationTriggerPropertyChanged, AutomationTriggerCardMoved,
+		AutomationTriggerCommentAdded, AutomationTriggerDueDatePassed:
+		return true
+	}
+	return false
+}
+
+// AutomationActionType is the kind of change an automation rule applies.
+type AutomationActionType string
+
+const (
+	AutomationActionSetProperty  AutomationActionType = "setProperty"
+	AutomationActionAssignPerson AutomationActionType = "assignPerson"
+	AutomationActionAddComment   AutomationActionType = "addComment"
+	AutomationActionNotify       AutomationActionType = "notify"
+	AutomationActionWebhook      AutomationActionType = "webhook"
+	AutomationActionMoveCard     AutomationActionType = "moveCard"
+)
+
+func (at AutomationActionType) IsValid() bool {
+	switch at {
+	case AutomationActionSetProperty, AutomationActionAssignPerson, AutomationActionAddComment,
+		AutomationActionNotify, AutomationActionWebhook, AutomationActionMoveCard:
+		return true
+	}
+	return false
+}
+
+// AutomationExecutionStatus is the outcome of a single rule execution.
+type AutomationExecutionStatus string
+
+const (
+	AutomationExecutionSuccess AutomationExecutionStatus = "success"
+	AutomationExecutionFailed  AutomationExecutionStatus = "failed"
+	AutomationExecutionSkipped AutomationExecutionStatus = "skipped"
+)
+
+// AutomationTrigger describes the event an automation rule reacts to.
+// swagger:model
+type AutomationTrigger struct {
+	// The trigger type
+	// required: true
+	Type AutomationTriggerType `json:"type"`
+
+	// The card property the trigger watches. Required for propertyChanged and dueDatePassed
+	// required: false
+	PropertyID string `json:"propertyId,omitempty"`
+
+	// The value the property must change to for propertyChanged. Any value matches if empty
+	// required: false
+	Value string `json:"value,omitempty"`
+}
+
+// AutomationAction describes a change applied when an automation rule fires.
+// swagger:model
+type AutomationAction struct {
+	// The action type
+	// required: true
+	Type AutomationActionType `json:"type"`
+
+	// The card property to modify, for setProperty and assignPerson
+	// required: false
+	PropertyID string `json:"propertyId,omitempty"`
+
+	// The value to set for setProperty. A null value clears the property
+	// required: false
+	Value interface{} `json:"value,omitempty"`
+
+	// The users to assign for assignPerson, or to notify for notify
+	// required: false
+	UserIDs []string `json:"userIds,omitempty"`
+
+	// The text of the comment for addComment, or of the message for notify
+	// required: false
+	Text string `json:"text,omitempty"`
+
+	// The URL to call for webhook
+	// required: false
+	URL string `json:"url,omitempty"`
+
+	// The board to move the card to for moveCard
+	// required: false
+	BoardID string `json:"boardId,omitempty"`
+}
+
+// AutomationRule is a board level "when X then Y" rule
+// swagger:model
+type AutomationRule struct {
+	// The id of the rule
+	// required: true
+	ID string `json:"id"`
+
+	// The id of the board the rule belongs to
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The display title of the rule
+	// required: false
+	Title string `json:"title"`
+
+	// Indicates if the rule is evaluated
+	// required: true
+	Enabled bool `json:"enabled"`
+
+	// The event that fires the rule
+	// required: true
+	Trigger AutomationTrigger `json:"trigger"`
+
+	// The actions applied when the rule fires, in order
+	// required: true
+	Actions []AutomationAction `json:"actions"`
+
+	// The id of the user that created the rule
+	// required: true
+	CreatedBy string `json:"createdBy"`
+
+	// The id of the user that last modified the rule. Rule actions are performed on their behalf
+	// required: true
+	ModifiedBy string `json:"modifiedBy"`
+
+	// The creation time in miliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// The last modified time in miliseconds since the current epoch
+	// required: true
+	UpdateAt int64 `json:"updateAt"`
+
+	// The deleted time in miliseconds since the current epoch. Set to indicate this rule is deleted
+	// required: false
+	DeleteAt int64 `json:"deleteAt"`
+}
+
+// Populate populates an AutomationRule with default values.
+func (r *AutomationRule) Populate() {
+	if r.ID == "" {
+		r.ID = utils.NewID(utils.IDTypeNone)
+	}
+	if r.Actions == nil {
+		r.Actions = []AutomationAction{}
+	}
+	now := utils.GetMillis()
+	if r.CreateAt == 0 {
+		r.CreateAt = now
+	}
+	if r.UpdateAt == 0 {
+		r.UpdateAt = r.CreateAt
+	}
+}
+
+// IsValid returns an error if the AutomationRule has invalid field values.
+func (r *AutomationRule) IsValid() error {
+	if r == nil {
+		return ErrInvalidAutomationRule{"cannot be nil"}
+	}
+	if r.ID == "" {
+		return ErrInvalidAutomationRule{"missing id"}
+	}
+	if r.BoardID == "" {
+		return ErrInvalidAutomationRule{"missing board id"}
+	}
+	if !r.Trigger.Type.IsValid() {
+		return ErrInvalidAutomationRule{"invalid trigger type " + string(r.Trigger.Type)}
+	}
+	if (r.Trigger.Type == AutomationTriggerPropertyChanged || r.Trigger.Type == AutomationTriggerDueDatePassed) &&
+		r.Trigger.PropertyID == "" {
+		return ErrInvalidAutomationRule{"trigger " + string(r.Trigger.Type) + " requires a property id"}
+	}
+	if len(r.Actions) == 0 {
+		return ErrInvalidAutomationRule{"at least one action is required"}
+	}
+	for _, action := range r.Actions {
+		if err := action.IsValid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsValid returns an error if the AutomationAction is missing the fields its type requires.
+func (a AutomationAction) IsValid() error {
+	if !a.Type.IsValid() {
+		return ErrInvalidAutomationRule{"invalid action type " + string(a.Type)}
+	}
+
+	switch a.Type {
+	case AutomationActionSetProperty:
+		if a.PropertyID == "" {
+			return ErrInvalidAutomationRule{"action setProperty requires a property id"}
+		}
+	case AutomationActionAssignPerson:
+		if a.PropertyID == "" || len(a.UserIDs) == 0 {
+			return ErrInvalidAutomationRule{"action assignPerson requires a property id and users"}
+		}
+	case AutomationActionAddComment:
+		if strings.TrimSpace(a.Text) == "" {
+			return ErrInvalidAutomationRule{"action addComment requires a text"}
+		}
+	case AutomationActionNotify:
+		if len(a.UserIDs) == 0 || strings.TrimSpace(a.Text) == "" {
+			return ErrInvalidAutomationRule{"action notify requires users and a text"}
+		}
+	case AutomationActionWebhook:
+		u, err := url.Parse(a.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidAutomationRule{"action webhook requires a valid http(s) url"}
+		}
+	case AutomationActionMoveCard:
+		if a.BoardID == "" {
+			return ErrInvalidAutomationRule{"action moveCard requires a board id"}
+		}
+	}
+	return nil
+}
+
+// AutomationRulePatch is a patch for modifying automation rules
+// swagger:model
+type AutomationRulePatch struct {
+	// The display title of the rule
+	// required: false
+	Title *string `json:"title"`
+
+	// Indicates if the rule is evaluated
+	// required: false
+	Enabled *bool `json:"enabled"`
+
+	// The event that fires the rule
+	// required: false
+	Trigger *AutomationTrigger `json:"trigger"`
+
+	// The actions applied when the rule fires
+	// required: false
+	Actions *[]AutomationAction `json:"actions"`
+}
+
+// Patch returns an updated version of the rule.
+func (p *AutomationRulePatch) Patch(rule *AutomationRule) *AutomationRule {
+	if p.Title != nil {
+		rule.Title = *p.Title
+	}
+	if p.Enabled != nil {
+		rule.Enabled = *p.Enabled
+	}
+	if p.Trigger != nil {
+		rule.Trigger = *p.Trigger
+	}
+	if p.Actions != nil {
+		rule.Actions = *p.Actions
+	}
+	return rule
+}
+
+// AutomationExecution is a log entry recording one evaluation of an automation rule.
+// swagger:model
+type AutomationExecution struct {
+	// The id of the execution
+	// required: true
+	ID string `json:"id"`
+
+	// The id of the rule that was evaluated
+	// required: true
+	RuleID string `json:"ruleId"`
+
+	// The id of the board the rule belongs to
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The id of the card the rule was evaluated for
+	// required: true
+	CardID string `json:"cardId"`
+
+	// The trigger that fired the rule
+	// required: true
+	TriggerType AutomationTriggerType `json:"triggerType"`
+
+	// The outcome of the execution
+	// required: true
+	Status AutomationExecutionStatus `json:"status"`
+
+	// Details about the outcome, such as an error or the reason it was skipped
+	// required: false
+	Message string `json:"message"`
+
+	// How many automation rules preceded this one in the chain of changes
+	// required: true
+	Depth int `json:"depth"`
+
+	// The execution time in miliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
+
+// QueryAutomationExecutionsOptions are query options that can be passed to GetAutomationExecutions.
+type QueryAutomationExecutionsOptions struct {
+	BoardID string // required
+	RuleID  string // if non-empty, only executions of this rule are returned
+	Limit   uint64 // if non-zero, limits the number of executions returned
+}
+
+func AutomationRuleFromJSON(data io.Reader) (*AutomationRule, error) {
+	var rule AutomationRule
+	if err := json.NewDecoder(data).Decode(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+type ErrInvalidAutomationRule struct {
+	msg string
+}
+
+func (e ErrInvalidAutomationRule) Error() string {
+	return "invalid automation rule, " + e.msg
+}
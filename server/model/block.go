@@ -108,6 +108,11 @@ type BlockPatch struct {
 	// The block removed fields
 	// required: false
 	DeletedFields []string `json:"deletedFields"`
+
+	// The update time of the block the patch was based on. If set and the block has been
+	// modified since, the patch is rejected with a conflict
+	// required: false
+	ExpectedUpdateAt int64 `json:"expectedUpdateAt,omitempty"`
 }
 
 // BlockPatchBatch is a batch of IDs and patches for modify blocks
@@ -211,6 +216,8 @@ type QueryBlocksOptions struct {
 	BlockType BlockType // if not empty and not `TypeUnknown` then filter for records of specified block type
 	Page      int       // page number to select when paginating
 	PerPage   int       // number of blocks per page (default=-1, meaning unlimited)
+
+	ExcludeArchived bool // if true then archived cards are filtered out
 }
 
 // QuerySubtreeOptions are query options that can be passed to GetSubTree methods.
@@ -226,6 +233,9 @@ type QueryBlockHistoryOptions struct {
 	AfterUpdateAt  int64  // if non-zero then filter for records with update_at greater than AfterUpdateAt
 	Limit          uint64 // if non-zero then limit the number of returned records
 	Descending     bool   // if true then the records are sorted by insert_at in descending order
+	CardID         string // if non-empty then GetBlockHistoryDescendants filters for the card and its children
+	BeforeID       string // if non-empty then GetBlockHistoryDescendants also returns the records with update_at equal to BeforeUpdateAt and id less than BeforeID
+	SortByUpdateAt bool   // if true then GetBlockHistoryDescendants sorts the records by update_at and id instead of insert_at
 }
 
 // QueryBoardHistoryOptions are query options that can be passed to GetBoardHistory.
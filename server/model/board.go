@@ -166,6 +166,11 @@ type BoardPatch struct {
 	// The board removed card properties
 	// required: false
 	DeletedCardProperties []string `json:"deletedCardProperties"`
+
+	// The update time of the board the patch was based on. If set and the board has been
+	// modified since, the patch is rejected with a conflict
+	// required: false
+	ExpectedUpdateAt int64 `json:"expectedUpdateAt,omitempty"`
 }
 
 // BoardMember stores the information of the membership of a user on a board
@@ -230,6 +235,10 @@ type BoardMetadata struct {
 	// The ID of the user that last modified the most recently modified descendant
 	// required: true
 	LastModifiedBy string `json:"lastModifiedBy"`
+
+	// The columns currently holding more cards than their WIP limit
+	// required: false
+	WIPLimitBreaches []WIPLimitBreach `json:"wipLimitBreaches"`
 }
 
 func BoardFromJSON(data io.Reader) *Board {
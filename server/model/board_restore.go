@@ -0,0 +1,91 @@
+package model
+
+// BoardSnapshot is the state of a board and its blocks at a point in time,
+// rebuilt from the board and block history
+// swagger:model
+type BoardSnapshot struct {
+	// The timestamp of the snapshot in milliseconds since the current epoch
+	// required: true
+	At int64 `json:"at"`
+
+	// The board as it was at the timestamp
+	// required: true
+	Board *Board `json:"board"`
+
+	// The blocks of the board as they were at the timestamp
+	// required: true
+	Blocks []*Block `json:"blocks"`
+}
+
+// BoardRestoreOptions are the options of a board restore
+// swagger:model
+type BoardRestoreOptions struct {
+	// The timestamp to restore the board to in milliseconds since the current epoch
+	// required: true
+	At int64 `json:"at"`
+
+	// If true, the snapshot is restored as a new board of the same team, which requires the
+	// permission to create boards there, and the original board is left untouched
+	// required: false
+	AsNewBoard bool `json:"asNewBoard"`
+
+	// The title of the new board, defaults to the title of the snapshot
+	// required: false
+	Title string `json:"title,omitempty"`
+
+	// If true, the changes are reported but nothing is restored
+	// required: false
+	DryRun bool `json:"dryRun"`
+}
+
+// IsValid returns an error if the options are incomplete or inconsistent.
+func (o *BoardRestoreOptions) IsValid() error {
+	if o.At <= 0 {
+		return NewErrBadRequest("a restore timestamp is required")
+	}
+	if o.Title != "" && !o.AsNewBoard {
+		return NewErrBadRequest("a title can only be set when restoring as a new board")
+	}
+	return nil
+}
+
+// BoardRestoreResult is the outcome of a board restore
+// swagger:model
+type BoardRestoreResult struct {
+	// True if the restore was a dry run and nothing was changed
+	// required: true
+	DryRun bool `json:"dryRun"`
+
+	// The restored board, or for a dry run the board as it was at the timestamp
+	// required: true
+	Board *Board `json:"board"`
+
+	// The ids of the blocks that are, or for a dry run would be, recreated
+	// required: true
+	CreatedBlockIDs []string `json:"createdBlockIds"`
+
+	// The ids of the blocks that are, or for a dry run would be, reverted
+	// required: true
+	UpdatedBlockIDs []string `json:"updatedBlockIds"`
+
+	// The ids of the blocks that are, or for a dry run would be, deleted
+	// required: true
+	DeletedBlockIDs []string `json:"deletedBlockIds"`
+
+	// The ids of the blocks of the snapshot that have since moved to another board and are not restored
+	// required: true
+	SkippedBlockIDs []string `json:"skippedBlockIds"`
+}
+
+// BoardRestoreChanges are the changes of an in place board restore, applied by
+// the store in a single transaction.
+type BoardRestoreChanges struct {
+	// Board is the board with its content reverted to the snapshot.
+	Board *Board
+
+	// Blocks are the blocks to recreate or revert to their snapshot version.
+	Blocks []*Block
+
+	// DeletedBlockIDs are the blocks created since the snapshot, to delete.
+	DeletedBlockIDs []string
+}
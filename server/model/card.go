@@ -3,6 +3,7 @@ package model
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/mattermost/focalboard/server/utils"
 	"github.com/rivo/uniseg"
@@ -26,6 +27,9 @@ func (e ErrInvalidCard) Error() string {
 
 var ErrNotCardBlock = errors.New("not a card block")
 
+// CardArchivedAtField is the block field that holds the time a card was archived.
+const CardArchivedAtField = "archivedAt"
+
 type ErrInvalidFieldType struct {
 	field string
 }
@@ -84,6 +88,10 @@ type Card struct {
 	// The deleted time in milliseconds since the current epoch. Set to indicate this card is deleted
 	// required: false
 	DeleteAt int64 `json:"deleteAt"`
+
+	// The archived time in milliseconds since the current epoch. Set to indicate this card is archived
+	// required: false
+	ArchivedAt int64 `json:"archivedAt,omitempty"`
 }
 
 // Populate populates a Card with default values.
@@ -155,6 +163,11 @@ type CardPatch struct {
 	// A map of property ids to property option ids to be updated
 	// required: false
 	UpdatedProperties map[string]any `json:"updatedProperties"`
+
+	// The update time of the card the patch was based on. If set and the card has been
+	// modified since, the patch is rejected with a conflict
+	// required: false
+	ExpectedUpdateAt int64 `json:"expectedUpdateAt,omitempty"`
 }
 
 // Patch returns an updated version of the card.
@@ -192,6 +205,86 @@ func (p *CardPatch) CheckValid() error {
 	return nil
 }
 
+// CardMoveOptions describes how a card is moved to another board
+// swagger:model
+type CardMoveOptions struct {
+	// The id of the board to move the card to
+	// required: true
+	DestinationBoardID string `json:"destinationBoardId"`
+
+	// A map of source property ids to destination property ids. Properties not in the
+	// map are matched by name and type; mapping a property to an empty id drops its value
+	// required: false
+	PropertyMapping map[string]string `json:"propertyMapping"`
+
+	// A map of source select option ids to destination option ids. Options not in the
+	// map are matched by value
+	// required: false
+	OptionMapping map[string]string `json:"optionMapping"`
+}
+
+// CardFilter selects cards of a board, for instance to archive them. All the
+// specified criteria must match, and at least one must be specified
+// swagger:model
+type CardFilter struct {
+	// The ids of the cards to select
+	// required: false
+	CardIDs []string `json:"cardIds"`
+
+	// The id of a card property to match, requires value
+	// required: false
+	PropertyID string `json:"propertyId"`
+
+	// The value (option id for select properties) the property must have
+	// required: false
+	Value string `json:"value"`
+
+	// Selects cards last updated before this time, in milliseconds since the current epoch
+	// required: false
+	UpdatedBefore int64 `json:"updatedBefore"`
+}
+
+// IsValid returns an error if the filter has no criteria or incomplete ones.
+func (f CardFilter) IsValid() error {
+	if (f.PropertyID == "") != (f.Value == "") {
+		return NewErrBadRequest("propertyId and value must be specified together")
+	}
+	if len(f.CardIDs) == 0 && f.PropertyID == "" && f.UpdatedBefore == 0 {
+		return NewErrBadRequest("at least one of cardIds, propertyId or updatedBefore is required")
+	}
+	return nil
+}
+
+// Matches returns true if the card block satisfies all the criteria of the filter.
+func (f CardFilter) Matches(block *Block) bool {
+	if block.Type != TypeCard {
+		return false
+	}
+	if len(f.CardIDs) != 0 {
+		found := false
+		for _, id := range f.CardIDs {
+			if id == block.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.UpdatedBefore != 0 && block.UpdateAt >= f.UpdatedBefore {
+		return false
+	}
+	if f.PropertyID != "" {
+		props, _ := block.Fields["properties"].(map[string]interface{})
+		value, _ := props[f.PropertyID].(string)
+		if value != f.Value {
+			return false
+		}
+	}
+	return true
+}
+
 // Card2Block converts a card to block using a shallow copy. Not needed once cards are first class entities.
 func Card2Block(card *Card) *Block {
 	fields := make(map[string]interface{})
@@ -200,6 +293,9 @@ func Card2Block(card *Card) *Block {
 	fields["icon"] = card.Icon
 	fields["isTemplate"] = card.IsTemplate
 	fields["properties"] = card.Properties
+	if card.ArchivedAt != 0 {
+		fields[CardArchivedAtField] = card.ArchivedAt
+	}
 
 	return &Block{
 		ID:         card.ID,
@@ -271,6 +367,8 @@ func Block2Card(block *Block) (*Card, error) {
 		}
 	}
 
+	archivedAt := getFieldMillis(block.Fields, CardArchivedAtField)
+
 	card := &Card{
 		ID:           block.ID,
 		BoardID:      block.BoardID,
@@ -284,11 +382,89 @@ func Block2Card(block *Block) (*Card, error) {
 		CreateAt:     block.CreateAt,
 		UpdateAt:     block.UpdateAt,
 		DeleteAt:     block.DeleteAt,
+		ArchivedAt:   archivedAt,
 	}
 	card.Populate()
 	return card, nil
 }
 
+// IsArchivedCard returns true if the block is a card that has been archived.
+func IsArchivedCard(block *Block) bool {
+	if block.Type != TypeCard {
+		return false
+	}
+	archivedAt := getFieldMillis(block.Fields, CardArchivedAtField)
+	return archivedAt != 0
+}
+
+// ExcludeArchivedCards returns the blocks without the archived cards.
+func ExcludeArchivedCards(blocks []*Block) []*Block {
+	result := make([]*Block, 0, len(blocks))
+	for _, block := range blocks {
+		if !IsArchivedCard(block) {
+			result = append(result, block)
+		}
+	}
+	return result
+}
+
+// GetCardAssignees returns the ids of the users set in the person and multi
+// person properties of a card.
+func GetCardAssignees(card *Block, schema PropSchema) map[string]struct{} {
+	assignees := map[string]struct{}{}
+	if card == nil {
+		return assignees
+	}
+	props, _ := card.Fields["properties"].(map[string]interface{})
+
+	for propID, value := range props {
+		propDef, ok := schema[propID]
+		if !ok || (propDef.Type != "person" && propDef.Type != "multiPerson") {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				assignees[v] = struct{}{}
+			}
+		case []interface{}:
+			for _, item := range v {
+				if userID, ok := item.(string); ok && userID != "" {
+					assignees[userID] = struct{}{}
+				}
+			}
+		case []string:
+			for _, userID := range v {
+				if userID != "" {
+					assignees[userID] = struct{}{}
+				}
+			}
+		}
+	}
+	return assignees
+}
+
+// getFieldMillis returns the value of a timestamp field, which is a float64
+// when the fields were read from JSON. Numeric strings, as written by some
+// older clients, are parsed; missing or other values are zero.
+func getFieldMillis(fields map[string]interface{}, key string) int64 {
+	switch v := fields[key].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case string:
+		millis, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0
+		}
+		return millis
+	}
+	return 0
+}
+
 // CardPatch2BlockPatch converts a CardPatch to a BlockPatch. Not needed once cards are first class entities.
 func CardPatch2BlockPatch(cardPatch *CardPatch) (*BlockPatch, error) {
 	if err := cardPatch.CheckValid(); err != nil {
@@ -296,7 +472,8 @@ func CardPatch2BlockPatch(cardPatch *CardPatch) (*BlockPatch, error) {
 	}
 
 	blockPatch := &BlockPatch{
-		Title: cardPatch.Title,
+		Title:            cardPatch.Title,
+		ExpectedUpdateAt: cardPatch.ExpectedUpdateAt,
 	}
 
 	updatedFields := make(map[string]any, 0)
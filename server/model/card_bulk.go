@@ -0,0 +1,165 @@
+package model
+
+import "fmt"
+
+// CardBulkOperationType is the kind of change a bulk card operation applies.
+type CardBulkOperationType string
+
+const (
+	CardBulkSetProperty   CardBulkOperationType = "setProperty"
+	CardBulkClearProperty CardBulkOperationType = "clearProperty"
+	CardBulkAddPerson     CardBulkOperationType = "addPerson"
+	CardBulkRemovePerson  CardBulkOperationType = "removePerson"
+	CardBulkMove          CardBulkOperationType = "move"
+	CardBulkArchive       CardBulkOperationType = "archive"
+	CardBulkDelete        CardBulkOperationType = "delete"
+	CardBulkDuplicate     CardBulkOperationType = "duplicate"
+)
+
+// CardBulkOperation is a change applied to every card selected by a bulk request
+// swagger:model
+type CardBulkOperation struct {
+	// The operation type
+	// required: true
+	Type CardBulkOperationType `json:"type"`
+
+	// The card property to modify, for setProperty, clearProperty, addPerson and removePerson
+	// required: false
+	PropertyID string `json:"propertyId,omitempty"`
+
+	// The value to set for setProperty
+	// required: false
+	Value interface{} `json:"value,omitempty"`
+
+	// The user to add or remove for addPerson and removePerson
+	// required: false
+	UserID string `json:"userId,omitempty"`
+
+	// The board to move the cards to for move
+	// required: false
+	BoardID string `json:"boardId,omitempty"`
+}
+
+// IsValid returns an error if the operation is missing the fields its type requires.
+func (o CardBulkOperation) IsValid() error {
+	switch o.Type {
+	case CardBulkSetProperty:
+		if o.PropertyID == "" || o.Value == nil {
+			return NewErrBadRequest("operation setProperty requires a property id and a value")
+		}
+	case CardBulkClearProperty:
+		if o.PropertyID == "" {
+			return NewErrBadRequest("operation clearProperty requires a property id")
+		}
+	case CardBulkAddPerson, CardBulkRemovePerson:
+		if o.PropertyID == "" || o.UserID == "" {
+			return NewErrBadRequest(fmt.Sprintf("operation %s requires a property id and a user id", o.Type))
+		}
+	case CardBulkMove:
+		if o.BoardID == "" {
+			return NewErrBadRequest("operation move requires a board id")
+		}
+	case CardBulkArchive, CardBulkDelete, CardBulkDuplicate:
+	default:
+		return NewErrBadRequest("invalid operation type " + string(o.Type))
+	}
+	return nil
+}
+
+// CardBulkRequest applies a set of operations to the cards of a board selected by a filter.
+// The operations are applied in order. delete and duplicate can't be combined with
+// other operations, and move must be the last one
+// swagger:model
+type CardBulkRequest struct {
+	// The filter selecting the cards
+	// required: true
+	Filter CardFilter `json:"filter"`
+
+	// The operations to apply to each card
+	// required: true
+	Operations []CardBulkOperation `json:"operations"`
+
+	// If true, the affected cards and failures are reported but nothing is changed
+	// required: false
+	DryRun bool `json:"dryRun"`
+}
+
+// IsValid returns an error if the request is incomplete or combines operations
+// that can't be applied together.
+func (r *CardBulkRequest) IsValid() error {
+	if err := r.Filter.IsValid(); err != nil {
+		return err
+	}
+	if len(r.Operations) == 0 {
+		return NewErrBadRequest("at least one operation is required")
+	}
+
+	for i, op := range r.Operations {
+		if err := op.IsValid(); err != nil {
+			return err
+		}
+		switch op.Type {
+		case CardBulkDelete, CardBulkDuplicate:
+			if len(r.Operations) != 1 {
+				return NewErrBadRequest(fmt.Sprintf("operation %s can't be combined with other operations", op.Type))
+			}
+		case CardBulkMove:
+			if i != len(r.Operations)-1 {
+				return NewErrBadRequest("operation move must be the last operation")
+			}
+		}
+	}
+	return nil
+}
+
+// CardBulkFailure describes why a card selected by a bulk request was not changed
+// swagger:model
+type CardBulkFailure struct {
+	// The id of the card
+	// required: true
+	CardID string `json:"cardId"`
+
+	// The reason the card was not changed
+	// required: true
+	Error string `json:"error"`
+}
+
+// CardBulkResult is the outcome of a bulk card request
+// swagger:model
+type CardBulkResult struct {
+	// True if the request was a dry run and nothing was changed
+	// required: true
+	DryRun bool `json:"dryRun"`
+
+	// The ids of the cards that were, or for a dry run would be, changed
+	// required: true
+	CardIDs []string `json:"cardIds"`
+
+	// The ids of the cards created by duplicate
+	// required: true
+	CreatedCardIDs []string `json:"createdCardIds"`
+
+	// The cards that were selected but could not be changed
+	// required: true
+	Failures []CardBulkFailure `json:"failures"`
+}
+
+// CardBulkChanges are the block changes of a bulk card request, applied by the store
+// in a single transaction.
+type CardBulkChanges struct {
+	// BoardID is the board the cards belong to.
+	BoardID string
+
+	// Patches are applied to cards that stay on the board.
+	Patches BlockPatchBatch
+
+	// MovedBlocks are the cards and their children to move from BoardID to the
+	// board set in each block.
+	MovedBlocks []*Block
+
+	// DeletedCardIDs are the cards to delete with their children.
+	DeletedCardIDs []string
+
+	// DuplicatedCardIDs are the cards to duplicate with their children.
+	DuplicatedCardIDs []string
+}
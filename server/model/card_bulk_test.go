@@ -0,0 +1,55 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardBulkRequestIsValid(t *testing.T) {
+	filter := CardFilter{CardIDs: []string{"card-id"}}
+
+	testCases := []struct {
+		name       string
+		operations []CardBulkOperation
+		valid      bool
+	}{
+		{"no operations", nil, false},
+		{"unknown operation", []CardBulkOperation{{Type: "unknown"}}, false},
+		{"setProperty without value", []CardBulkOperation{{Type: CardBulkSetProperty, PropertyID: "status"}}, false},
+		{"addPerson without user", []CardBulkOperation{{Type: CardBulkAddPerson, PropertyID: "assignee"}}, false},
+		{"property changes and archive", []CardBulkOperation{
+			{Type: CardBulkSetProperty, PropertyID: "status", Value: "done"},
+			{Type: CardBulkRemovePerson, PropertyID: "assignee", UserID: "user-id"},
+			{Type: CardBulkArchive},
+		}, true},
+		{"move last", []CardBulkOperation{
+			{Type: CardBulkClearProperty, PropertyID: "status"},
+			{Type: CardBulkMove, BoardID: "board-id"},
+		}, true},
+		{"move not last", []CardBulkOperation{
+			{Type: CardBulkMove, BoardID: "board-id"},
+			{Type: CardBulkArchive},
+		}, false},
+		{"delete alone", []CardBulkOperation{{Type: CardBulkDelete}}, true},
+		{"delete combined", []CardBulkOperation{{Type: CardBulkArchive}, {Type: CardBulkDelete}}, false},
+		{"duplicate combined", []CardBulkOperation{{Type: CardBulkDuplicate}, {Type: CardBulkArchive}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &CardBulkRequest{Filter: filter, Operations: tc.operations}
+			err := req.IsValid()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.True(t, IsErrBadRequest(err))
+			}
+		})
+	}
+
+	t.Run("filter is required", func(t *testing.T) {
+		req := &CardBulkRequest{Operations: []CardBulkOperation{{Type: CardBulkArchive}}}
+		require.True(t, IsErrBadRequest(req.IsValid()))
+	})
+}
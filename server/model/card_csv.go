@@ -0,0 +1,100 @@
+package model
+
+import "strings"
+
+// CardCSVTitleColumn is the property id of the column holding the title
+// of the cards in a CSV file.
+const CardCSVTitleColumn = "title"
+
+// CardCSVColumn maps a column of a CSV file to a card property
+// swagger:model
+type CardCSVColumn struct {
+	// The header of the column
+	// required: true
+	Name string `json:"name"`
+
+	// The id of the property the column is imported to, "title" for the title of the cards.
+	// Empty to create a new property named after the column
+	// required: false
+	PropertyID string `json:"propertyId,omitempty"`
+
+	// The type of the property to create, text if empty
+	// required: false
+	Type string `json:"type,omitempty"`
+
+	// If true, the column is not imported
+	// required: false
+	Skip bool `json:"skip,omitempty"`
+}
+
+// CardCSVImportOptions provides options when importing cards from a CSV file.
+type CardCSVImportOptions struct {
+	BoardID    string
+	ModifiedBy string
+
+	// Columns overrides how columns are mapped to properties. Columns
+	// not listed are mapped to the property with the same name, or to a
+	// new text property.
+	Columns []CardCSVColumn
+
+	// DryRun reports what would be imported without changing the board.
+	DryRun bool
+}
+
+// ColumnFor returns the mapping set in the options for a column.
+func (o CardCSVImportOptions) ColumnFor(name string) (CardCSVColumn, bool) {
+	for _, column := range o.Columns {
+		if strings.EqualFold(strings.TrimSpace(column.Name), strings.TrimSpace(name)) {
+			return column, true
+		}
+	}
+	return CardCSVColumn{}, false
+}
+
+// CardCSVWarning is a value of a CSV file that couldn't be imported
+// swagger:model
+type CardCSVWarning struct {
+	// The line of the value in the file, 1 for the header
+	// required: true
+	Line int `json:"line"`
+
+	// The column of the value
+	// required: true
+	Column string `json:"column"`
+
+	// Why the value was not imported
+	// required: true
+	Error string `json:"error"`
+}
+
+// CardCSVImportResult is the outcome of importing cards from a CSV file
+// swagger:model
+type CardCSVImportResult struct {
+	// True if nothing was imported and the result is a preview
+	// required: true
+	DryRun bool `json:"dryRun"`
+
+	// How each column of the file was mapped to a property
+	// required: true
+	Columns []CardCSVColumn `json:"columns"`
+
+	// The names of the properties that were, or for a dry run would be, created
+	// required: true
+	NewProperties []string `json:"newProperties"`
+
+	// The select options that were, or for a dry run would be, created, by property name
+	// required: true
+	NewOptions map[string][]string `json:"newOptions"`
+
+	// The number of cards imported, or for a dry run to import
+	// required: true
+	CardCount int `json:"cardCount"`
+
+	// The first cards of the file, for a dry run
+	// required: true
+	Preview []*Card `json:"preview"`
+
+	// The values that couldn't be imported
+	// required: true
+	Warnings []CardCSVWarning `json:"warnings"`
+}
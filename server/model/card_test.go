@@ -45,6 +45,34 @@ func TestBlock2Card(t *testing.T) {
 		assert.EqualValues(t, fields["properties"], card.Properties)
 	})
 
+	t.Run("Archived card", func(t *testing.T) {
+		archived := *block
+		archived.Fields = map[string]interface{}{CardArchivedAtField: float64(now)}
+
+		card, err := Block2Card(&archived)
+		require.NoError(t, err)
+		assert.Equal(t, now, card.ArchivedAt)
+		assert.True(t, IsArchivedCard(&archived))
+		assert.False(t, IsArchivedCard(block))
+
+		assert.Equal(t, now, Card2Block(card).Fields[CardArchivedAtField])
+	})
+
+	t.Run("Archived card with a string timestamp", func(t *testing.T) {
+		archived := *block
+		archived.Fields = map[string]interface{}{CardArchivedAtField: "1234"}
+
+		card, err := Block2Card(&archived)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1234), card.ArchivedAt)
+
+		archived.Fields = map[string]interface{}{CardArchivedAtField: "yesterday"}
+		card, err = Block2Card(&archived)
+		require.NoError(t, err)
+		assert.Zero(t, card.ArchivedAt)
+		assert.False(t, IsArchivedCard(&archived))
+	})
+
 	t.Run("Not a card", func(t *testing.T) {
 		blockNotCard := &Block{}
 
@@ -54,6 +82,31 @@ func TestBlock2Card(t *testing.T) {
 	})
 }
 
+func TestCardFilter(t *testing.T) {
+	card := &Block{
+		ID:       "card-id",
+		Type:     TypeCard,
+		UpdateAt: 1000,
+		Fields: map[string]any{
+			"properties": map[string]any{"status": "done"},
+		},
+	}
+
+	t.Run("requires criteria", func(t *testing.T) {
+		require.True(t, IsErrBadRequest(CardFilter{}.IsValid()))
+		require.True(t, IsErrBadRequest(CardFilter{PropertyID: "status"}.IsValid()))
+		require.NoError(t, CardFilter{UpdatedBefore: 2000}.IsValid())
+	})
+
+	t.Run("all criteria must match", func(t *testing.T) {
+		require.True(t, CardFilter{PropertyID: "status", Value: "done", UpdatedBefore: 2000}.Matches(card))
+		require.True(t, CardFilter{CardIDs: []string{"other-id", "card-id"}}.Matches(card))
+		require.False(t, CardFilter{PropertyID: "status", Value: "done", UpdatedBefore: 1000}.Matches(card))
+		require.False(t, CardFilter{PropertyID: "status", Value: "todo"}.Matches(card))
+		require.False(t, CardFilter{CardIDs: []string{"other-id"}}.Matches(card))
+	})
+}
+
 const sampleBlockFieldsJSON = `
 {
 	"contentOrder":[
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"regexp"
+)
+
+const (
+	// CommentParentIDField is the field of a comment block that holds the id of
+	// the comment it replies to.
+	CommentParentIDField = "parentCommentId"
+
+	// CommentEditedAtField is the field of a comment block that holds the time
+	// its text was last edited.
+	CommentEditedAtField = "editedAt"
+)
+
+var emojiNameRegexp = regexp.MustCompile(`^[a-z0-9_+-]{1,64}$`)
+
+// CommentReaction is an emoji reaction of a user to a comment
+// swagger:model
+type CommentReaction struct {
+	// The id of the comment
+	// required: true
+	CommentID string `json:"commentId"`
+
+	// The board the comment belongs to
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The user that reacted
+	// required: true
+	UserID string `json:"userId"`
+
+	// The name of the emoji
+	// required: true
+	Emoji string `json:"emoji"`
+
+	// The creation time in milliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
+
+// IsValidEmojiName returns true if the name can be used as a reaction emoji.
+func IsValidEmojiName(name string) bool {
+	return emojiNameRegexp.MatchString(name)
+}
+
+// CommentEdit is the new text of an edited comment
+// swagger:model
+type CommentEdit struct {
+	// The new text of the comment
+	// required: true
+	Title string `json:"title"`
+}
+
+// GetCommentParentID returns the id of the comment a comment replies to, or an
+// empty string if it is not a reply.
+func GetCommentParentID(block *Block) string {
+	if block == nil || block.Type != TypeComment {
+		return ""
+	}
+	parentID, _ := block.Fields[CommentParentIDField].(string)
+	return parentID
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidEmojiName(t *testing.T) {
+	for _, name := range []string{"smile", "+1", "-1", "thumbs_up", "100"} {
+		require.True(t, IsValidEmojiName(name), name)
+	}
+	for _, name := range []string{"", "Smile", "two words", ":smile:", "<script>"} {
+		require.False(t, IsValidEmojiName(name), name)
+	}
+}
+
+func TestGetCommentParentID(t *testing.T) {
+	reply := &Block{Type: TypeComment, Fields: map[string]interface{}{CommentParentIDField: "parent-id"}}
+	require.Equal(t, "parent-id", GetCommentParentID(reply))
+
+	comment := &Block{Type: TypeComment, Fields: map[string]interface{}{}}
+	require.Empty(t, GetCommentParentID(comment))
+
+	text := &Block{Type: TypeText, Fields: map[string]interface{}{CommentParentIDField: "parent-id"}}
+	require.Empty(t, GetCommentParentID(text))
+}
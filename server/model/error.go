@@ -160,6 +160,82 @@ func (ni *ErrNotImplemented) Error() string {
 	return ni.msg
 }
 
+// ErrWIPLimitExceeded is returned when a card change would move cards
+// into a column that is at its hard work-in-progress limit.
+type ErrWIPLimitExceeded struct {
+	Breach WIPLimitBreach
+}
+
+// NewErrWIPLimitExceeded creates a new ErrWIPLimitExceeded instance.
+func NewErrWIPLimitExceeded(breach WIPLimitBreach) *ErrWIPLimitExceeded {
+	return &ErrWIPLimitExceeded{
+		Breach: breach,
+	}
+}
+
+func (e *ErrWIPLimitExceeded) Error() string {
+	return fmt.Sprintf("WIP limit exceeded for column {%s}: limit %d, would have %d cards",
+		e.Breach.OptionValue, e.Breach.Limit, e.Breach.Count)
+}
+
+// ErrStorageQuotaExceeded is returned when a file doesn't fit in the
+// storage quota of its team or board.
+type ErrStorageQuotaExceeded struct {
+	Breach StorageQuotaBreach
+}
+
+// NewErrStorageQuotaExceeded creates a new ErrStorageQuotaExceeded instance.
+func NewErrStorageQuotaExceeded(breach StorageQuotaBreach) *ErrStorageQuotaExceeded {
+	return &ErrStorageQuotaExceeded{
+		Breach: breach,
+	}
+}
+
+func (e *ErrStorageQuotaExceeded) Error() string {
+	return fmt.Sprintf("storage quota exceeded for %s {%s}: quota %d bytes, %d used, file of %d",
+		e.Breach.Scope, e.Breach.ScopeID, e.Breach.Quota, e.Breach.Used, e.Breach.Size)
+}
+
+// ErrUploadRejected is returned when the upload policy of a team
+// doesn't allow the type of a file.
+type ErrUploadRejected struct {
+	reason string
+}
+
+// NewErrUploadRejected creates a new ErrUploadRejected instance.
+func NewErrUploadRejected(reason string) *ErrUploadRejected {
+	return &ErrUploadRejected{
+		reason: reason,
+	}
+}
+
+func (e *ErrUploadRejected) Error() string {
+	return e.reason
+}
+
+// ErrVersionConflict is returned when a patch is based on a version of an
+// entity that has since been modified.
+type ErrVersionConflict struct {
+	Entity          string
+	ID              string
+	CurrentUpdateAt int64
+	Current         interface{}
+}
+
+// NewErrVersionConflict creates a new ErrVersionConflict instance.
+func NewErrVersionConflict(entity, id string, currentUpdateAt int64, current interface{}) *ErrVersionConflict {
+	return &ErrVersionConflict{
+		Entity:          entity,
+		ID:              id,
+		CurrentUpdateAt: currentUpdateAt,
+		Current:         current,
+	}
+}
+
+func (e *ErrVersionConflict) Error() string {
+	return fmt.Sprintf("{%s} ID=%s has been modified, current version %d", e.Entity, e.ID, e.CurrentUpdateAt)
+}
+
 // IsErrBadRequest returns true if `err` is or wraps one of:
 // - model.ErrBadRequest
 // - model.ErrViewsLimitReached
@@ -311,6 +387,46 @@ func IsErrRequestEntityTooLarge(err error) bool {
 	return errors.Is(err, ErrRequestEntityTooLarge)
 }
 
+// IsErrWIPLimitExceeded returns true if `err` is or wraps a model.ErrWIPLimitExceeded.
+func IsErrWIPLimitExceeded(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var wle *ErrWIPLimitExceeded
+	return errors.As(err, &wle)
+}
+
+// IsErrStorageQuotaExceeded returns true if `err` is or wraps a model.ErrStorageQuotaExceeded.
+func IsErrStorageQuotaExceeded(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var sqe *ErrStorageQuotaExceeded
+	return errors.As(err, &sqe)
+}
+
+// IsErrUploadRejected returns true if `err` is or wraps a model.ErrUploadRejected.
+func IsErrUploadRejected(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var ure *ErrUploadRejected
+	return errors.As(err, &ure)
+}
+
+// IsErrVersionConflict returns true if `err` is or wraps a model.ErrVersionConflict.
+func IsErrVersionConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var vc *ErrVersionConflict
+	return errors.As(err, &vc)
+}
+
 // IsErrNotImplemented returns true if `err` is or wraps one of:
 // - model.ErrNotImplemented
 // - model.ErrInsufficientLicense.
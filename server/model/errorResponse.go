@@ -10,4 +10,16 @@ type ErrorResponse struct {
 	// The error code
 	// required: false
 	ErrorCode int `json:"errorCode"`
+
+	// The current update time of the entity, for version conflicts
+	// required: false
+	CurrentUpdateAt int64 `json:"currentUpdateAt,omitempty"`
+
+	// The current server version of the entity, for version conflicts
+	// required: false
+	Current interface{} `json:"current,omitempty"`
+
+	// The quota the uploaded file didn't fit in, for exceeded storage quotas
+	// required: false
+	QuotaExceeded *StorageQuotaBreach `json:"quotaExceeded,omitempty"`
 }
@@ -0,0 +1,91 @@
+package model
+
+const (
+	ExportJobStatusPending = "pending"
+	ExportJobStatusRunning = "running"
+	ExportJobStatusDone    = "done"
+	ExportJobStatusFailed  = "failed"
+)
+
+// ExportJobOptions are the options of an export of the boards of a team.
+// swagger:model
+type ExportJobOptions struct {
+	// Leave the images and attachments out of the archive
+	// required: false
+	ExcludeFiles bool `json:"excludeFiles"`
+
+	// Leave the previous versions of the boards and blocks out of the archive
+	// required: false
+	ExcludeHistory bool `json:"excludeHistory"`
+}
+
+// ExportJob is an export of the boards of a team to an archive, written
+// in the background to the files storage, where it is kept until it expires.
+// swagger:model
+type ExportJob struct {
+	// The ID of the export
+	// required: true
+	ID string `json:"id"`
+
+	// The team the boards are exported from
+	// required: true
+	TeamID string `json:"teamId"`
+
+	// The user who requested the export, the only one who can download it
+	// required: true
+	CreatedBy string `json:"createdBy"`
+
+	// The status of the export, one of pending, running, done or failed
+	// required: true
+	Status string `json:"status"`
+
+	// The boards to export
+	// required: true
+	BoardIDs []string `json:"boardIds"`
+
+	// Leave the images and attachments out of the archive
+	// required: true
+	ExcludeFiles bool `json:"excludeFiles"`
+
+	// Leave the previous versions of the boards and blocks out of the archive
+	// required: true
+	ExcludeHistory bool `json:"excludeHistory"`
+
+	// The number of boards to export
+	// required: true
+	BoardCount int `json:"boardCount"`
+
+	// The number of boards written to the archive so far
+	// required: true
+	ExportedBoards int `json:"exportedBoards"`
+
+	// The size in bytes of the archive, once done
+	// required: true
+	Size int64 `json:"size"`
+
+	// The reason the export failed
+	// required: false
+	Error string `json:"error,omitempty"`
+
+	// The path of the archive in the files storage
+	Path string `json:"-"`
+
+	// The creation time in milliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// The last update time in milliseconds since the current epoch, also
+	// updated while the export is queued or runs
+	// required: true
+	UpdateAt int64 `json:"updateAt"`
+
+	// The time in milliseconds since the current epoch the archive is
+	// deleted at, zero until the export ends
+	// required: true
+	ExpireAt int64 `json:"expireAt"`
+}
+
+// IsFinished returns true if the export is done or failed.
+func (j *ExportJob) IsFinished() bool {
+	return j.Status == ExportJobStatusDone || j.Status == ExportJobStatusFailed
+}
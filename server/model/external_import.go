@@ -0,0 +1,50 @@
+package model
+
+// ExternalImportResult is the outcome of importing the export of another tool
+// swagger:model
+type ExternalImportResult struct {
+	// The tool the export is from
+	// required: true
+	Tool string `json:"tool"`
+
+	// The boards created by the import
+	// required: true
+	Boards []*Board `json:"boards"`
+
+	// The number of cards imported
+	// required: true
+	CardCount int `json:"cardCount"`
+
+	// The fields of the export that have no equivalent and were not imported
+	// required: true
+	UnmappedFields []ExternalImportField `json:"unmappedFields"`
+
+	// The people of the export, and the users they were mapped to
+	// required: true
+	Members []ExternalImportMember `json:"members"`
+}
+
+// ExternalImportField is a field of the export of another tool that was not imported
+// swagger:model
+type ExternalImportField struct {
+	// The name of the field
+	// required: true
+	Name string `json:"name"`
+
+	// The number of times the field was set in the export
+	// required: true
+	Count int `json:"count"`
+}
+
+// ExternalImportMember is a person of the export of another tool
+// swagger:model
+type ExternalImportMember struct {
+	// The name of the person in the export
+	// required: true
+	Name string `json:"name"`
+
+	// The id of the member of the team with the same username or email, empty if there is none.
+	// Person properties are not set for people without a user.
+	// required: true
+	UserID string `json:"userId"`
+}
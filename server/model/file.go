@@ -3,7 +3,11 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"mime"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -23,3 +27,134 @@ func NewFileInfo(name string) *mm_model.FileInfo {
 		MimeType:  mime.TypeByExtension(extension),
 	}
 }
+
+// FileRendition is a version of an uploaded file to serve.
+type FileRendition string
+
+const (
+	// FileRenditionOriginal is the file as it was uploaded.
+	FileRenditionOriginal FileRendition = ""
+	// FileRenditionThumb is a small version of an image, for card
+	// previews.
+	FileRenditionThumb FileRendition = "thumb"
+	// FileRenditionPreview is a version of an image scaled down to
+	// fit on a screen.
+	FileRenditionPreview FileRendition = "preview"
+)
+
+// IsValid checks if the rendition is a known one.
+func (r FileRendition) IsValid() bool {
+	switch r {
+	case FileRenditionOriginal, FileRenditionThumb, FileRenditionPreview:
+		return true
+	}
+	return false
+}
+
+// FileGCReport is the outcome of a run of the garbage collection of
+// the files that are no longer attached to any block
+// swagger:model
+type FileGCReport struct {
+	// True if the orphaned files were only reported, not removed
+	// required: true
+	DryRun bool `json:"dryRun"`
+
+	// Files stored after this time in milliseconds since the current epoch were kept
+	// required: true
+	GraceCutoff int64 `json:"graceCutoff"`
+
+	// The number of file infos checked
+	// required: true
+	CheckedFileInfos int `json:"checkedFileInfos"`
+
+	// The number of paths of the files storage checked
+	// required: true
+	CheckedPaths int `json:"checkedPaths"`
+
+	// The orphaned files
+	// required: true
+	Orphans []*OrphanedFile `json:"orphans"`
+
+	// The number of orphaned files removed
+	// required: true
+	RemovedCount int `json:"removedCount"`
+
+	// The total size in bytes of the orphaned files removed, for the ones with a file info
+	// required: true
+	RemovedSize int64 `json:"removedSize"`
+
+	// The number of orphaned files that couldn't be removed
+	// required: true
+	ErrorCount int `json:"errorCount"`
+}
+
+// OrphanedFile is a file that is no longer attached to any block
+// swagger:model
+type OrphanedFile struct {
+	// The path of the file in the files storage
+	// required: true
+	Path string `json:"path"`
+
+	// The ID of the file info of the file, empty for files without one
+	// required: false
+	FileInfoID string `json:"fileInfoId,omitempty"`
+
+	// The size of the file in bytes, zero if unknown
+	// required: false
+	Size int64 `json:"size,omitempty"`
+}
+
+// FileBlobRoot is the directory of the files storage holding the
+// content of uploads, stored once per distinct content and named after
+// its SHA-256 hash.
+const FileBlobRoot = "boards/blobs"
+
+// ErrFileBlobRemoving is returned when referencing a blob that the
+// file garbage collection is removing.
+var ErrFileBlobRemoving = errors.New("file blob is being removed")
+
+// FileBlob is the content shared by the uploads with the same hash,
+// which is removed once no file info references it. The garbage
+// collection claims a blob by setting its reference count to -1 before
+// removing it, so it can't be referenced again meanwhile.
+type FileBlob struct {
+	Hash     string
+	Size     int64
+	RefCount int64
+	CreateAt int64
+}
+
+// FileBlobPath returns where the content with a hash is stored.
+func FileBlobPath(hash string) string {
+	return path.Join(FileBlobRoot, hash[:2], hash[2:4], hash)
+}
+
+// FileBlobHash returns the hash of the content stored at a path, if it
+// is the path of a blob. Renditions of blobs are not blobs.
+func FileBlobHash(storagePath string) (string, bool) {
+	if !strings.HasPrefix(storagePath, FileBlobRoot+"/") {
+		return "", false
+	}
+
+	hash := path.Base(storagePath)
+	if len(hash) != sha256.Size*2 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", false
+	}
+	return hash, FileBlobPath(hash) == storagePath
+}
+
+// FileSignedURL is a link to download a file without a session, valid
+// until it expires
+// swagger:model
+type FileSignedURL struct {
+	// The signed URL of the file
+	// required: true
+	URL string `json:"url"`
+
+	// The time the URL expires, in milliseconds since the current epoch
+	// required: true
+	ExpiresAt int64 `json:"expiresAt"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBlobHash(t *testing.T) {
+	hash := "aabbccddeeff00112233445566778899aabbccddeeff00112233445566778899"
+	blobPath := FileBlobPath(hash)
+	require.Equal(t, "boards/blobs/aa/bb/"+hash, blobPath)
+
+	found, ok := FileBlobHash(blobPath)
+	require.True(t, ok)
+	require.Equal(t, hash, found)
+
+	for _, p := range []string{
+		blobPath + "_thumb.jpg",
+		"boards/20240101/7abcdefghijklmnopqrstuvwxyz.png",
+		"boards/blobs/bb/aa/" + hash,
+		"team-id/board-id/" + hash,
+	} {
+		_, ok := FileBlobHash(p)
+		require.False(t, ok, p)
+	}
+}
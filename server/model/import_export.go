@@ -38,6 +38,13 @@ type ExportArchiveOptions struct {
 	// BoardIDs is the list of boards to include in the archive.
 	// Empty slice means export all boards from workspace/team.
 	BoardIDs []string
+
+	// ExcludeFiles leaves the images and attachments out of the archive.
+	ExcludeFiles bool
+
+	// IncludeHistory adds the previous versions of the boards and blocks
+	// to the archive, in a `history.jsonl` file per board.
+	IncludeHistory bool
 }
 
 // ImportArchiveOptions provides options when importing an archive.
@@ -46,6 +53,127 @@ type ImportArchiveOptions struct {
 	ModifiedBy    string
 	BoardModifier BoardModifier
 	BlockModifier BlockModifier
+
+	// DryRun validates the archive and reports what would be imported,
+	// without importing anything.
+	DryRun bool
+}
+
+// ArchiveImportReport is the outcome of the import, or the validation, of
+// an archive.
+// swagger:model
+type ArchiveImportReport struct {
+	// True if the archive was only validated, not imported
+	// required: true
+	DryRun bool `json:"dryRun"`
+
+	// The boards of the archive, one per board file
+	// required: true
+	Boards []*ArchiveImportBoard `json:"boards"`
+
+	// The problems that didn't prevent importing a board, like members whose user doesn't exist
+	// required: true
+	Warnings []*ArchiveImportIssue `json:"warnings"`
+
+	// The problems that prevented importing a board
+	// required: true
+	Errors []*ArchiveImportIssue `json:"errors"`
+}
+
+// ArchiveImportBoard is the outcome of the import of a board of an archive.
+// swagger:model
+type ArchiveImportBoard struct {
+	// The path of the board file in the archive, empty for legacy archives
+	// required: true
+	File string `json:"file"`
+
+	// The ID of the board in the archive
+	// required: true
+	ArchiveID string `json:"archiveId"`
+
+	// The title of the board
+	// required: true
+	Title string `json:"title"`
+
+	// The ID of the imported board, empty for dry runs and boards that were not imported
+	// required: false
+	BoardID string `json:"boardId,omitempty"`
+
+	// The number of blocks of the board by block type
+	// required: true
+	Blocks map[BlockType]int `json:"blocks"`
+
+	// The number of members of the board whose user exists
+	// required: true
+	Members int `json:"members"`
+
+	// The number of files of the board in the archive
+	// required: true
+	Files int `json:"files"`
+
+	// True if the board has errors, and was not imported
+	// required: true
+	Failed bool `json:"failed"`
+}
+
+// ArchiveImportIssue is a problem found while importing an archive.
+// swagger:model
+type ArchiveImportIssue struct {
+	// The path of the file of the archive, empty for legacy archives
+	// required: true
+	File string `json:"file"`
+
+	// The line of the file, if the problem is on a line
+	// required: false
+	Line int `json:"line,omitempty"`
+
+	// The description of the problem
+	// required: true
+	Message string `json:"message"`
+}
+
+func (i *ArchiveImportIssue) Error() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s line %d: %s", i.File, i.Line, i.Message)
+	}
+	if i.File != "" {
+		return fmt.Sprintf("%s: %s", i.File, i.Message)
+	}
+	return i.Message
+}
+
+// NewArchiveImportReport creates an empty ArchiveImportReport.
+func NewArchiveImportReport(dryRun bool) *ArchiveImportReport {
+	return &ArchiveImportReport{
+		DryRun:   dryRun,
+		Boards:   []*ArchiveImportBoard{},
+		Warnings: []*ArchiveImportIssue{},
+		Errors:   []*ArchiveImportIssue{},
+	}
+}
+
+// AddWarning adds a problem that didn't prevent the import to the report.
+func (r *ArchiveImportReport) AddWarning(file string, line int, message string) {
+	r.Warnings = append(r.Warnings, &ArchiveImportIssue{File: file, Line: line, Message: message})
+}
+
+// AddError adds a problem that prevented the import of a board to the report.
+func (r *ArchiveImportReport) AddError(file string, line int, message string) {
+	r.Errors = append(r.Errors, &ArchiveImportIssue{File: file, Line: line, Message: message})
+}
+
+// Failed returns true if the archive has errors and none of its boards
+// was imported, or would be imported for dry runs.
+func (r *ArchiveImportReport) Failed() bool {
+	if len(r.Errors) == 0 {
+		return false
+	}
+	for _, board := range r.Boards {
+		if !board.Failed {
+			return false
+		}
+	}
+	return true
 }
 
 // ErrUnsupportedArchiveVersion is an error returned when trying to import an
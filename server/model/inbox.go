@@ -0,0 +1,95 @@
+package model
+
+// InboxItemType is the reason an inbox item was created for a user.
+type InboxItemType string
+
+const (
+	InboxItemMention  InboxItemType = "mention"
+	InboxItemAssigned InboxItemType = "assigned"
+	InboxItemComment  InboxItemType = "comment"
+	InboxItemDueDate  InboxItemType = "dueDate"
+)
+
+// InboxItem is a notification about a change that concerns a user
+// swagger:model
+type InboxItem struct {
+	// The id of the inbox item
+	// required: true
+	ID string `json:"id"`
+
+	// The user the item is for
+	// required: true
+	UserID string `json:"userId"`
+
+	// The kind of notification
+	// required: true
+	Type InboxItemType `json:"type"`
+
+	// The team of the board the change was made on
+	// required: true
+	TeamID string `json:"teamId"`
+
+	// The board the change was made on
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The card the change was made on
+	// required: true
+	CardID string `json:"cardId"`
+
+	// The block that was changed, if it is not the card itself
+	// required: false
+	BlockID string `json:"blockId,omitempty"`
+
+	// The user that made the change, empty for due dates
+	// required: false
+	ActorID string `json:"actorId,omitempty"`
+
+	// The title of the card at the time of the change
+	// required: false
+	CardTitle string `json:"cardTitle"`
+
+	// An extract of the changed text, or the name of the due date property
+	// required: false
+	Message string `json:"message"`
+
+	// The creation time in milliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// The time the item was read in milliseconds since the current epoch, zero if unread
+	// required: true
+	ReadAt int64 `json:"readAt"`
+}
+
+// Inbox is a page of the inbox items of a user, newest first
+// swagger:model
+type Inbox struct {
+	// The inbox items
+	// required: true
+	Items []*InboxItem `json:"items"`
+
+	// The number of unread items of the user in the team
+	// required: true
+	UnreadCount int `json:"unreadCount"`
+
+	// True if there are older items
+	// required: true
+	HasMore bool `json:"hasMore"`
+}
+
+// InboxReadRequest is the list of inbox items to mark as read
+// swagger:model
+type InboxReadRequest struct {
+	// The ids of the items to mark as read, all the items of the team are marked if empty
+	// required: false
+	IDs []string `json:"ids"`
+}
+
+// QueryInboxItemsOptions are query options that can be passed to GetInboxItems.
+type QueryInboxItemsOptions struct {
+	TeamID         string // if non-empty then only the items of the team are returned
+	UnreadOnly     bool   // if true then only unread items are returned
+	BeforeCreateAt int64  // if non-zero then only items created before BeforeCreateAt are returned
+	Limit          uint64 // if non-zero then no more than Limit items are returned
+}
@@ -22,4 +22,6 @@ var (
 	PermissionManageBoardProperties = &mmModel.Permission{Id: "manage_board_properties", Name: "", Description: "", Scope: ""}
 	PermissionCommentBoardCards     = &mmModel.Permission{Id: "comment_board_cards", Name: "", Description: "", Scope: ""}
 	PermissionDeleteOthersComments  = &mmModel.Permission{Id: "delete_others_comments", Name: "", Description: "", Scope: ""}
+	PermissionOverrideWIPLimits     = &mmModel.Permission{Id: "override_wip_limits", Name: "", Description: "", Scope: ""}
+	PermissionRestoreBoard          = &mmModel.Permission{Id: "restore_board", Name: "", Description: "", Scope: ""}
 )
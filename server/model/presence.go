@@ -0,0 +1,44 @@
+package model
+
+// PresenceState is the state of a user on a board.
+type PresenceState string
+
+const (
+	PresenceActive PresenceState = "active"
+	PresenceIdle   PresenceState = "idle"
+	PresenceLeft   PresenceState = "left"
+)
+
+// BoardPresence is a user currently looking at a board
+// swagger:model
+type BoardPresence struct {
+	// The user looking at the board
+	// required: true
+	UserID string `json:"userId"`
+
+	// The board the user is looking at
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The card the user has open, if any
+	// required: false
+	CardID string `json:"cardId,omitempty"`
+
+	// Whether the user is active, idle or has left the board
+	// required: true
+	State PresenceState `json:"state"`
+
+	// The time the user joined the board, in miliseconds since the current epoch
+	// required: true
+	Since int64 `json:"since"`
+}
+
+// EditingSignal tells that a user started or stopped editing a field
+// of a card. Editing signals are ephemeral and are not stored.
+type EditingSignal struct {
+	UserID  string `json:"userId"`
+	BoardID string `json:"boardId"`
+	CardID  string `json:"cardId"`
+	Field   string `json:"field"`
+	Editing bool   `json:"editing"`
+}
@@ -43,10 +43,31 @@ type PropSchema map[string]PropDef
 
 // PropDefOption represents an option within a property definition.
 type PropDefOption struct {
-	ID    string `json:"id"`
-	Index int    `json:"index"`
-	Color string `json:"color"`
-	Value string `json:"value"`
+	ID           string       `json:"id"`
+	Index        int          `json:"index"`
+	Color        string       `json:"color"`
+	Value        string       `json:"value"`
+	WIPLimit     int          `json:"wipLimit,omitempty"`
+	WIPLimitMode WIPLimitMode `json:"wipLimitMode,omitempty"`
+}
+
+// propOptionColors are the colors of the options created by imports.
+var propOptionColors = []string{
+	"propColorGray",
+	"propColorBrown",
+	"propColorOrange",
+	"propColorYellow",
+	"propColorGreen",
+	"propColorBlue",
+	"propColorPurple",
+	"propColorPink",
+	"propColorRed",
+}
+
+// PropOptionColor returns the color of the i-th option created by an
+// import, the colors being given in turn.
+func PropOptionColor(i int) string {
+	return propOptionColors[i%len(propOptionColors)]
 }
 
 // PropDef represents a property definition as defined in a board's Fields member.
@@ -198,10 +219,12 @@ func ParsePropertySchema(board *Board) (PropSchema, error) {
 					return nil, ErrInvalidPropSchema
 				}
 				po := PropDefOption{
-					ID:    getMapString("id", propOpt),
-					Index: j,
-					Value: getMapString("value", propOpt),
-					Color: getMapString("color", propOpt),
+					ID:           getMapString("id", propOpt),
+					Index:        j,
+					Value:        getMapString("value", propOpt),
+					Color:        getMapString("color", propOpt),
+					WIPLimit:     getMapInt("wipLimit", propOpt),
+					WIPLimitMode: WIPLimitMode(getMapString("wipLimitMode", propOpt)),
 				}
 				pd.Options[po.ID] = po
 			}
@@ -224,6 +247,21 @@ func getMapString(key string, m map[string]interface{}) string {
 	return s
 }
 
+func getMapInt(key string, m map[string]interface{}) int {
+	iface, ok := m[key]
+	if !ok {
+		return 0
+	}
+
+	switch v := iface.(type) {
+	case float64: // numbers decoded from JSON
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
 // ParseProperties parses a block's `Fields` to extract the properties. Properties typically exist on
 // card blocks.  A resolver can optionally be provided to fetch usernames for `person` prop type.
 func ParseProperties(block *Block, schema PropSchema, resolver PropValueResolver) (BlockProperties, error) {
@@ -0,0 +1,158 @@
+package model
+
+// StorageQuotaScope is what a storage quota applies to.
+type StorageQuotaScope string
+
+const (
+	StorageQuotaScopeTeam  StorageQuotaScope = "team"
+	StorageQuotaScopeBoard StorageQuotaScope = "board"
+)
+
+// IsValid checks if the scope is a known one.
+func (s StorageQuotaScope) IsValid() bool {
+	return s == StorageQuotaScopeTeam || s == StorageQuotaScopeBoard
+}
+
+// StorageQuota is the maximum size of the files stored for a team or a
+// board, overriding the default one of the server configuration
+// swagger:model
+type StorageQuota struct {
+	// The kind of entity the quota applies to, team or board
+	// required: true
+	Scope StorageQuotaScope `json:"scope"`
+
+	// The ID of the team or board the quota applies to
+	// required: true
+	ScopeID string `json:"scopeId"`
+
+	// The team of the entity the quota applies to
+	// required: true
+	TeamID string `json:"teamId"`
+
+	// The maximum size in bytes, zero for unlimited
+	// required: true
+	MaxSize int64 `json:"maxSize"`
+
+	// The last update time in milliseconds since the current epoch
+	// required: true
+	UpdateAt int64 `json:"updateAt"`
+}
+
+// IsValid checks the quota has a scope and a size.
+func (q *StorageQuota) IsValid() error {
+	if !q.Scope.IsValid() {
+		return NewErrBadRequest("invalid storage quota scope")
+	}
+	if q.ScopeID == "" || q.TeamID == "" {
+		return NewErrBadRequest("storage quota scope and team are required")
+	}
+	if q.MaxSize < 0 {
+		return NewErrBadRequest("storage quota size cannot be negative")
+	}
+	return nil
+}
+
+// StorageQuotaPatch is the new size of a storage quota
+// swagger:model
+type StorageQuotaPatch struct {
+	// The maximum size in bytes, zero for unlimited
+	// required: true
+	MaxSize int64 `json:"maxSize"`
+}
+
+// FileUsage is the running total of the files a user stored in a board.
+type FileUsage struct {
+	TeamID    string
+	BoardID   string
+	UserID    string
+	FileCount int64
+	TotalSize int64
+}
+
+// StorageUsage is the size of the files stored in a team
+// swagger:model
+type StorageUsage struct {
+	// The team the files are stored in
+	// required: true
+	TeamID string `json:"teamId"`
+
+	// The number of files
+	// required: true
+	FileCount int64 `json:"fileCount"`
+
+	// The total size of the files in bytes
+	// required: true
+	TotalSize int64 `json:"totalSize"`
+
+	// The quota of the team in bytes, zero for unlimited
+	// required: true
+	Quota int64 `json:"quota"`
+
+	// The usage of each board of the team with files or a quota
+	// required: true
+	Boards []*BoardStorageUsage `json:"boards"`
+
+	// The usage of each user that stored files in the team
+	// required: true
+	Users []*UserStorageUsage `json:"users"`
+}
+
+// BoardStorageUsage is the size of the files stored in a board
+// swagger:model
+type BoardStorageUsage struct {
+	// The board the files are stored in
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The number of files
+	// required: true
+	FileCount int64 `json:"fileCount"`
+
+	// The total size of the files in bytes
+	// required: true
+	TotalSize int64 `json:"totalSize"`
+
+	// The quota of the board in bytes, zero for unlimited
+	// required: true
+	Quota int64 `json:"quota"`
+}
+
+// UserStorageUsage is the size of the files a user stored in a team
+// swagger:model
+type UserStorageUsage struct {
+	// The user that stored the files
+	// required: true
+	UserID string `json:"userId"`
+
+	// The number of files
+	// required: true
+	FileCount int64 `json:"fileCount"`
+
+	// The total size of the files in bytes
+	// required: true
+	TotalSize int64 `json:"totalSize"`
+}
+
+// StorageQuotaBreach describes the quota a file didn't fit in
+// swagger:model
+type StorageQuotaBreach struct {
+	// The kind of entity whose quota was exceeded, team or board
+	// required: true
+	Scope StorageQuotaScope `json:"scope"`
+
+	// The ID of the team or board whose quota was exceeded
+	// required: true
+	ScopeID string `json:"scopeId"`
+
+	// The quota in bytes
+	// required: true
+	Quota int64 `json:"quota"`
+
+	// The size of the files already stored in bytes
+	// required: true
+	Used int64 `json:"used"`
+
+	// The size of the file that didn't fit in bytes
+	// required: true
+	Size int64 `json:"size"`
+}
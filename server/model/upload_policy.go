@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UploadPolicy is the types of files that can be uploaded in a team,
+// overriding the default one of the server configuration. Denied types
+// win over allowed ones, and empty allowed lists allow everything.
+// swagger:model
+type UploadPolicy struct {
+	// The team the policy applies to
+	// required: true
+	TeamID string `json:"teamId"`
+
+	// The MIME types files can have, like image/png or image/*
+	// required: false
+	AllowedMimeTypes []string `json:"allowedMimeTypes"`
+
+	// The MIME types files cannot have, like image/png or image/*
+	// required: false
+	DeniedMimeTypes []string `json:"deniedMimeTypes"`
+
+	// The extensions files can have, like .pdf
+	// required: false
+	AllowedExtensions []string `json:"allowedExtensions"`
+
+	// The extensions files cannot have, like .exe
+	// required: false
+	DeniedExtensions []string `json:"deniedExtensions"`
+
+	// The last update time in milliseconds since the current epoch, zero
+	// for the default policy
+	// required: true
+	UpdateAt int64 `json:"updateAt"`
+}
+
+// IsValid checks the policy has a team and well formed types.
+func (p *UploadPolicy) IsValid() error {
+	if p.TeamID == "" {
+		return NewErrBadRequest("upload policy team is required")
+	}
+	for _, mimeType := range append(append([]string{}, p.AllowedMimeTypes...), p.DeniedMimeTypes...) {
+		if !strings.Contains(mimeType, "/") {
+			return NewErrBadRequest(fmt.Sprintf("invalid MIME type in upload policy: %s", mimeType))
+		}
+	}
+	for _, extension := range append(append([]string{}, p.AllowedExtensions...), p.DeniedExtensions...) {
+		if !strings.HasPrefix(extension, ".") {
+			return NewErrBadRequest(fmt.Sprintf("invalid extension in upload policy, it must start with a dot: %s", extension))
+		}
+	}
+	return nil
+}
+
+// Check returns an ErrUploadRejected if a file with an extension and a
+// sniffed MIME type cannot be uploaded.
+func (p *UploadPolicy) Check(extension, mimeType string) error {
+	extension = strings.ToLower(extension)
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+
+	if extension != "" && matchesExtension(p.DeniedExtensions, extension) {
+		return NewErrUploadRejected(fmt.Sprintf("files with the %s extension are not allowed", extension))
+	}
+	if len(p.AllowedExtensions) > 0 && !matchesExtension(p.AllowedExtensions, extension) {
+		return NewErrUploadRejected(fmt.Sprintf("files with the %s extension are not allowed", extension))
+	}
+	if matchesMimeType(p.DeniedMimeTypes, mimeType) {
+		return NewErrUploadRejected(fmt.Sprintf("files of type %s are not allowed", mimeType))
+	}
+	if len(p.AllowedMimeTypes) > 0 && !matchesMimeType(p.AllowedMimeTypes, mimeType) {
+		return NewErrUploadRejected(fmt.Sprintf("files of type %s are not allowed", mimeType))
+	}
+	return nil
+}
+
+func matchesExtension(extensions []string, extension string) bool {
+	for _, e := range extensions {
+		if strings.EqualFold(e, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMimeType checks if a MIME type is in a list, where entries
+// like image/* match all the subtypes of a type.
+func matchesMimeType(mimeTypes []string, mimeType string) bool {
+	for _, m := range mimeTypes {
+		m = strings.ToLower(m)
+		if m == mimeType || m == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(m, "/*"); ok && strings.HasPrefix(mimeType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// FileScanStatus is the verdict of the upload scanner on a file.
+type FileScanStatus string
+
+const (
+	FileScanStatusClean    FileScanStatus = "clean"
+	FileScanStatusInfected FileScanStatus = "infected"
+	FileScanStatusFailed   FileScanStatus = "failed"
+)
+
+// FileScanResult is the result of the scan of an uploaded file.
+type FileScanResult struct {
+	FileID    string
+	Status    FileScanStatus
+	Signature string
+	// QuarantinePath is where infected files are moved to, empty for
+	// blobs, which are shared by the uploads with the same content and
+	// are left in place
+	QuarantinePath string
+	ScannedAt      int64
+}
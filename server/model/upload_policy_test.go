@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadPolicyCheck(t *testing.T) {
+	policy := &UploadPolicy{
+		TeamID:            "team-id",
+		AllowedMimeTypes:  []string{"image/*", "application/pdf"},
+		DeniedMimeTypes:   []string{"image/svg+xml"},
+		AllowedExtensions: []string{},
+		DeniedExtensions:  []string{".exe"},
+	}
+
+	testCases := []struct {
+		name      string
+		extension string
+		mimeType  string
+		allowed   bool
+	}{
+		{"allowed type", ".pdf", "application/pdf", true},
+		{"allowed wildcard type", ".png", "image/png", true},
+		{"type with parameters", ".pdf", "application/pdf; charset=binary", true},
+		{"denied type wins over wildcard", ".svg", "image/svg+xml", false},
+		{"type not allowed", ".txt", "text/plain; charset=utf-8", false},
+		{"denied extension", ".EXE", "image/png", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(tc.extension, tc.mimeType)
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				require.True(t, IsErrUploadRejected(err))
+			}
+		})
+	}
+
+	t.Run("empty policies allow everything", func(t *testing.T) {
+		require.NoError(t, (&UploadPolicy{}).Check(".exe", "application/x-msdownload"))
+	})
+
+	t.Run("allowed extensions", func(t *testing.T) {
+		policy := &UploadPolicy{AllowedExtensions: []string{".pdf"}}
+		require.NoError(t, policy.Check(".pdf", "application/pdf"))
+		require.True(t, IsErrUploadRejected(policy.Check(".docx", "application/pdf")))
+		require.True(t, IsErrUploadRejected(policy.Check("", "application/pdf")))
+	})
+}
+
+func TestUploadPolicyIsValid(t *testing.T) {
+	require.NoError(t, (&UploadPolicy{TeamID: "team-id", DeniedMimeTypes: []string{"image/*"}, DeniedExtensions: []string{".exe"}}).IsValid())
+	require.Error(t, (&UploadPolicy{}).IsValid())
+	require.Error(t, (&UploadPolicy{TeamID: "team-id", AllowedMimeTypes: []string{"image"}}).IsValid())
+	require.Error(t, (&UploadPolicy{TeamID: "team-id", DeniedExtensions: []string{"exe"}}).IsValid())
+}
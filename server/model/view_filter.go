@@ -0,0 +1,265 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ViewFilterTitle is the property id filter clauses use for the title
+	// of cards.
+	ViewFilterTitle = "title"
+
+	viewFilterHalfDay = 12 * 60 * 60 * 1000
+)
+
+// ViewFilterClause is a condition on a property of the cards shown by a
+// board view.
+type ViewFilterClause struct {
+	PropertyID string   `json:"propertyId"`
+	Condition  string   `json:"condition"`
+	Values     []string `json:"values"`
+}
+
+// ViewFilterGroup is the filter of a board view. Cards are shown if they
+// meet all (and) or any (or) of the clauses and nested groups.
+type ViewFilterGroup struct {
+	Operation string
+	Clauses   []ViewFilterClause
+	Groups    []ViewFilterGroup
+}
+
+// ParseViewFilterGroup parses the `filter` field of a view block. Groups
+// and clauses are told apart the same way the webapp does, by the
+// presence of the operation and filters keys.
+func ParseViewFilterGroup(v interface{}) ViewFilterGroup {
+	group := ViewFilterGroup{Operation: "and"}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return group
+	}
+	if op, ok := m["operation"].(string); ok && op != "" {
+		group.Operation = op
+	}
+
+	filters, _ := m["filters"].([]interface{})
+	for _, filter := range filters {
+		fm, ok := filter.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		_, hasOperation := fm["operation"]
+		_, hasFilters := fm["filters"]
+		if hasOperation && hasFilters {
+			group.Groups = append(group.Groups, ParseViewFilterGroup(fm))
+			continue
+		}
+
+		clause := ViewFilterClause{
+			PropertyID: getMapString("propertyId", fm),
+			Condition:  getMapString("condition", fm),
+		}
+		values, _ := fm["values"].([]interface{})
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				clause.Values = append(clause.Values, s)
+			}
+		}
+		group.Clauses = append(group.Clauses, clause)
+	}
+	return group
+}
+
+// Matches returns true if a card meets the filter, following the rules
+// the webapp uses to show the cards of a view.
+func (g ViewFilterGroup) Matches(card *Block, schema PropSchema) bool {
+	if len(g.Clauses) == 0 && len(g.Groups) == 0 {
+		return true
+	}
+
+	if g.Operation == "or" {
+		for _, clause := range g.Clauses {
+			if clause.Matches(card, schema) {
+				return true
+			}
+		}
+		for _, group := range g.Groups {
+			if group.Matches(card, schema) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, clause := range g.Clauses {
+		if !clause.Matches(card, schema) {
+			return false
+		}
+	}
+	for _, group := range g.Groups {
+		if !group.Matches(card, schema) {
+			return false
+		}
+	}
+	return true
+}
+
+// viewFilterDate is the value of a date property, or the creation or
+// update time of a card.
+type viewFilterDate struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+func parseViewFilterDate(s string) *viewFilterDate {
+	date := &viewFilterDate{}
+	if s == "" {
+		return date
+	}
+	if millis, err := strconv.ParseInt(s, 10, 64); err == nil {
+		date.From = millis
+		return date
+	}
+	_ = json.Unmarshal([]byte(s), date)
+	return date
+}
+
+// Matches returns true if a card meets the clause. Clauses without
+// values, and with unknown conditions, are met by every card.
+func (c ViewFilterClause) Matches(card *Block, schema PropSchema) bool {
+	props, _ := card.Fields["properties"].(map[string]interface{})
+	value := props[c.PropertyID]
+	if c.PropertyID == ViewFilterTitle {
+		value = strings.ToLower(card.Title)
+	}
+
+	propDef, hasPropDef := schema[c.PropertyID]
+	var dateValue *viewFilterDate
+	if hasPropDef && propDef.Type == "date" {
+		s, _ := value.(string)
+		dateValue = parseViewFilterDate(s)
+	}
+	isTimestamp := hasPropDef && (propDef.Type == "createdTime" || propDef.Type == "updatedTime")
+	if isViewFilterValueEmpty(value) && hasPropDef {
+		switch propDef.Type {
+		case "createdBy":
+			value = card.CreatedBy
+		case "updatedBy":
+			value = card.ModifiedBy
+		case "createdTime":
+			value = strconv.FormatInt(card.CreateAt, 10)
+			dateValue = &viewFilterDate{From: card.CreateAt}
+		case "updatedTime":
+			value = strconv.FormatInt(card.UpdateAt, 10)
+			dateValue = &viewFilterDate{From: card.UpdateAt}
+		}
+	}
+	s, _ := value.(string)
+
+	if len(c.Values) == 0 {
+		switch c.Condition {
+		case "isEmpty":
+			return isViewFilterValueEmpty(value)
+		case "isNotEmpty":
+			return !isViewFilterValueEmpty(value)
+		case "isSet":
+			return value != nil && value != ""
+		case "isNotSet":
+			return value == nil || value == ""
+		}
+		return true
+	}
+	filterValue := strings.ToLower(c.Values[0])
+
+	switch c.Condition {
+	case "includes":
+		return viewFilterIncludesAny(value, c.Values)
+	case "notIncludes":
+		return !viewFilterIncludesAny(value, c.Values)
+	case "isEmpty":
+		return isViewFilterValueEmpty(value)
+	case "isNotEmpty":
+		return !isViewFilterValueEmpty(value)
+	case "isSet":
+		return value != nil && value != ""
+	case "isNotSet":
+		return value == nil || value == ""
+	case "is":
+		if dateValue != nil {
+			day, _ := strconv.ParseInt(c.Values[0], 10, 64)
+			if isTimestamp {
+				return dateValue.From != 0 && dateValue.From > day-viewFilterHalfDay && dateValue.From < day+viewFilterHalfDay
+			}
+			if dateValue.From != 0 && dateValue.To != 0 {
+				return dateValue.From <= day && dateValue.To >= day
+			}
+			return dateValue.From == day
+		}
+		return filterValue == s
+	case "contains":
+		return strings.Contains(s, filterValue)
+	case "notContains":
+		return !strings.Contains(s, filterValue)
+	case "startsWith":
+		return strings.HasPrefix(s, filterValue)
+	case "notStartsWith":
+		return !strings.HasPrefix(s, filterValue)
+	case "endsWith":
+		return strings.HasSuffix(s, filterValue)
+	case "notEndsWith":
+		return !strings.HasSuffix(s, filterValue)
+	case "isBefore":
+		if dateValue == nil {
+			return false
+		}
+		day, _ := strconv.ParseInt(c.Values[0], 10, 64)
+		if isTimestamp {
+			return dateValue.From != 0 && dateValue.From < day-viewFilterHalfDay
+		}
+		return dateValue.From != 0 && dateValue.From < day
+	case "isAfter":
+		if dateValue == nil {
+			return false
+		}
+		day, _ := strconv.ParseInt(c.Values[0], 10, 64)
+		if isTimestamp {
+			return dateValue.From != 0 && dateValue.From > day+viewFilterHalfDay
+		}
+		if dateValue.To != 0 {
+			return dateValue.To > day
+		}
+		return dateValue.From != 0 && dateValue.From > day
+	}
+	return true
+}
+
+func isViewFilterValueEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func viewFilterIncludesAny(value interface{}, values []string) bool {
+	for _, filterValue := range values {
+		switch v := value.(type) {
+		case string:
+			if v == filterValue {
+				return true
+			}
+		case []interface{}:
+			for _, item := range v {
+				if item == filterValue {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
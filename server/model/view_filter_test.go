@@ -0,0 +1,61 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewFilterGroupMatches(t *testing.T) {
+	schema := PropSchema{
+		"status": {ID: "status", Type: "select"},
+		"due":    {ID: "due", Type: "date"},
+		"tags":   {ID: "tags", Type: "multiSelect"},
+	}
+	card := &Block{
+		Type:  TypeCard,
+		Title: "Fix The Bug",
+		Fields: map[string]interface{}{
+			"properties": map[string]interface{}{
+				"status": "doing",
+				"due":    `{"from":1000,"to":3000}`,
+				"tags":   []interface{}{"backend", "urgent"},
+			},
+		},
+	}
+
+	parse := func(operation string, filters ...interface{}) ViewFilterGroup {
+		return ParseViewFilterGroup(map[string]interface{}{"operation": operation, "filters": filters})
+	}
+	clause := func(propertyID, condition string, values ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"propertyId": propertyID, "condition": condition, "values": values}
+	}
+
+	testCases := []struct {
+		name    string
+		group   ViewFilterGroup
+		matches bool
+	}{
+		{"no filters", parse("and"), true},
+		{"includes", parse("and", clause("status", "includes", "todo", "doing")), true},
+		{"not includes", parse("and", clause("status", "notIncludes", "doing")), false},
+		{"includes in a multi select", parse("and", clause("tags", "includes", "urgent")), true},
+		{"is empty", parse("and", clause("unknown", "isEmpty")), true},
+		{"title contains", parse("and", clause(ViewFilterTitle, "contains", "The")), true},
+		{"title starts with", parse("and", clause(ViewFilterTitle, "startsWith", "bug")), false},
+		{"date in range", parse("and", clause("due", "is", "2000")), true},
+		{"date before", parse("and", clause("due", "isBefore", "500")), false},
+		{"date after", parse("and", clause("due", "isAfter", "2000")), true},
+		{"or", parse("or", clause("status", "includes", "todo"), clause("tags", "includes", "backend")), true},
+		{"and", parse("and", clause("status", "includes", "todo"), clause("tags", "includes", "backend")), false},
+		{"nested group", parse("and", clause("status", "includes", "doing"), map[string]interface{}{
+			"operation": "or",
+			"filters":   []interface{}{clause("status", "includes", "todo")},
+		}), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.matches, tc.group.Matches(card, schema))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+// WIPLimitMode describes how the work-in-progress limit of a column is enforced.
+type WIPLimitMode string
+
+const (
+	// WIPLimitSoft allows the limit to be exceeded; breaches are only reported.
+	// This is the default mode.
+	WIPLimitSoft WIPLimitMode = "soft"
+
+	// WIPLimitHard rejects changes that move cards into a full column, unless
+	// the user has the permission to override WIP limits.
+	WIPLimitHard WIPLimitMode = "hard"
+)
+
+// WIPLimitBreach describes a column that holds, or would hold, more cards than its limit.
+// swagger:model
+type WIPLimitBreach struct {
+	// The id of the select property the board is grouped by
+	// required: true
+	PropertyID string `json:"propertyId"`
+
+	// The id of the select option of the column
+	// required: true
+	OptionID string `json:"optionId"`
+
+	// The display value of the select option of the column
+	// required: true
+	OptionValue string `json:"optionValue"`
+
+	// The maximum number of cards allowed in the column
+	// required: true
+	Limit int `json:"limit"`
+
+	// The number of cards in the column
+	// required: true
+	Count int `json:"count"`
+
+	// How the limit is enforced
+	// required: true
+	Mode WIPLimitMode `json:"mode"`
+}
@@ -19,10 +19,13 @@ import (
 	"github.com/mattermost/focalboard/server/auth"
 	appModel "github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/cluster"
 	"github.com/mattermost/focalboard/server/services/config"
 	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/notify/notifyinbox"
 	"github.com/mattermost/focalboard/server/services/notify/notifylogger"
+	"github.com/mattermost/focalboard/server/services/scanner"
 	"github.com/mattermost/focalboard/server/services/scheduler"
 	"github.com/mattermost/focalboard/server/services/store"
 	"github.com/mattermost/focalboard/server/services/store/sqlstore"
@@ -38,12 +41,20 @@ import (
 )
 
 const (
-	cleanupSessionTaskFrequency = 10 * time.Minute
-	updateMetricsTaskFrequency  = 15 * time.Minute
+	cleanupSessionTaskFrequency       = 10 * time.Minute
+	updateMetricsTaskFrequency        = 15 * time.Minute
+	dueDateAutomationsTaskFrequency   = 5 * time.Minute
+	dueDateNotificationsTaskFrequency = 5 * time.Minute
+	fileGCTaskFrequency               = time.Hour
+	fileDedupTaskFrequency            = time.Hour
+	exportCleanupTaskFrequency        = 15 * time.Minute
 
 	minSessionExpiryTime = int64(60 * 60 * 24 * 31) // 31 days
 
 	MattermostAuthMod = "mattermost"
+
+	clusterBusPostgres = "postgres"
+	clusterBusChannel  = "focalboard_cluster"
 )
 
 type Server struct {
@@ -58,8 +69,14 @@ type Server struct {
 	metricsServer          *metrics.Service
 	metricsService         *metrics.Metrics
 	metricsUpdaterTask     *scheduler.ScheduledTask
+	dueDateAutomationsTask *scheduler.ScheduledTask
+	dueDateInboxTask       *scheduler.ScheduledTask
+	fileGCTask             *scheduler.ScheduledTask
+	fileDedupTask          *scheduler.ScheduledTask
+	exportCleanupTask      *scheduler.ScheduledTask
 	auditService           *audit.Audit
 	notificationService    *notify.Service
+	clusterBus             cluster.Bus
 	servicesStartStopMutex sync.Mutex
 
 	localRouter     *mux.Router
@@ -75,10 +92,39 @@ func New(params Params) (*Server, error) {
 
 	authenticator := auth.New(params.Cfg, params.DBStore, params.PermissionsService)
 
+	// Init metrics
+	instanceInfo := metrics.InstanceInfo{
+		Version:        appModel.CurrentVersion,
+		BuildNum:       appModel.BuildNumber,
+		Edition:        appModel.Edition,
+		InstallationID: os.Getenv("MM_CLOUD_INSTALLATION_ID"),
+	}
+	metricsService := metrics.NewMetrics(instanceInfo)
+
 	// if no ws adapter is provided, we spin up a websocket server
 	wsAdapter := params.WSAdapter
+	var clusterBus cluster.Bus
 	if wsAdapter == nil {
-		wsAdapter = ws.NewServer(authenticator, params.SingleUserToken, params.Cfg.AuthMode == MattermostAuthMod, params.Logger, params.DBStore)
+		wsServer := ws.NewServer(authenticator, params.SingleUserToken, params.Cfg.AuthMode == MattermostAuthMod, params.Logger, params.DBStore)
+		wsServer.SetMetrics(metricsService)
+
+		slowConsumerPolicy := ws.SlowConsumerPolicy(params.Cfg.WebsocketSlowConsumerPolicy)
+		if slowConsumerPolicy != "" && !slowConsumerPolicy.IsValid() {
+			return nil, fmt.Errorf("unsupported websocket slow consumer policy %q", params.Cfg.WebsocketSlowConsumerPolicy)
+		}
+		wsServer.SetSendQueue(params.Cfg.WebsocketSendQueueSize, slowConsumerPolicy)
+
+		// nodes of a standalone cluster relay their broadcasts
+		// to each other through the cluster bus
+		if params.Cfg.ClusterBus != "" {
+			bus, err := newClusterBus(params.Cfg, params.Logger)
+			if err != nil {
+				return nil, err
+			}
+			wsServer.SetClusterBus(bus)
+			clusterBus = bus
+		}
+		wsAdapter = wsServer
 	}
 
 	filesBackendSettings := filestore.FileBackendSettings{}
@@ -95,6 +141,12 @@ func New(params Params) (*Server, error) {
 	filesBackendSettings.AmazonS3SSE = params.Cfg.FilesS3Config.SSE
 	filesBackendSettings.AmazonS3Trace = params.Cfg.FilesS3Config.Trace
 	filesBackendSettings.AmazonS3RequestTimeoutMilliseconds = params.Cfg.FilesS3Config.Timeout
+	// the presigned URLs signed file URLs redirect to only have to last
+	// as long as the signed URLs
+	filesBackendSettings.AmazonS3PresignExpiresSeconds = int64(params.Cfg.FileSignedURLExpirySeconds)
+	if filesBackendSettings.AmazonS3PresignExpiresSeconds <= 0 {
+		filesBackendSettings.AmazonS3PresignExpiresSeconds = 300
+	}
 
 	filesBackend, appErr := filestore.NewFileBackend(filesBackendSettings)
 	if appErr != nil {
@@ -103,16 +155,16 @@ func New(params Params) (*Server, error) {
 		return nil, errors.New("unable to initialize the files storage")
 	}
 
-	webhookClient := webhook.NewClient(params.Cfg, params.Logger)
-
-	// Init metrics
-	instanceInfo := metrics.InstanceInfo{
-		Version:        appModel.CurrentVersion,
-		BuildNum:       appModel.BuildNumber,
-		Edition:        appModel.Edition,
-		InstallationID: os.Getenv("MM_CLOUD_INSTALLATION_ID"),
+	uploadScanner, err := scanner.New(scanner.Config{
+		Driver:  params.Cfg.UploadScanner,
+		Address: params.Cfg.UploadScannerAddress,
+		Timeout: time.Duration(params.Cfg.UploadScannerTimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize the upload scanner: %w", err)
 	}
-	metricsService := metrics.NewMetrics(instanceInfo)
+
+	webhookClient := webhook.NewClient(params.Cfg, params.Logger)
 
 	// Init audit
 	auditService, errAudit := audit.NewAudit()
@@ -138,11 +190,21 @@ func New(params Params) (*Server, error) {
 		Notifications:    notificationService,
 		Logger:           params.Logger,
 		Permissions:      params.PermissionsService,
+		Scanner:          uploadScanner,
 		ServicesAPI:      params.ServicesAPI,
 		SkipTemplateInit: utils.IsRunningUnitTests(),
 	}
 	app := app.New(params.Cfg, wsAdapter, appServices)
 
+	inboxBackend := notifyinbox.New(notifyinbox.BackendParams{
+		AppAPI:      &inboxAppAPI{Store: params.DBStore, app: app},
+		Permissions: params.PermissionsService,
+		Logger:      params.Logger,
+	})
+	if err := notificationService.AddBackend(inboxBackend); err != nil {
+		return nil, fmt.Errorf("cannot initialize inbox notification backend: %w", err)
+	}
+
 	focalboardAPI := api.NewAPI(app, params.SingleUserToken, params.Cfg.AuthMode, params.PermissionsService, params.Logger, auditService)
 
 	// Local router for admin APIs
@@ -197,6 +259,7 @@ func New(params Params) (*Server, error) {
 		metricsService:      metricsService,
 		auditService:        auditService,
 		notificationService: notificationService,
+		clusterBus:          clusterBus,
 		logger:              params.Logger,
 		localRouter:         localRouter,
 		api:                 focalboardAPI,
@@ -208,6 +271,21 @@ func New(params Params) (*Server, error) {
 	return &server, nil
 }
 
+func newClusterBus(cfg *config.Configuration, logger mlog.LoggerIFace) (cluster.Bus, error) {
+	if cfg.ClusterBus != clusterBusPostgres {
+		return nil, fmt.Errorf("unsupported cluster bus %q", cfg.ClusterBus)
+	}
+	if cfg.DBType != appModel.PostgresDBType {
+		return nil, fmt.Errorf("the %s cluster bus requires a %s database", cfg.ClusterBus, appModel.PostgresDBType)
+	}
+
+	bus, err := cluster.NewPostgresBus(cfg.DBConfigString, cfg.DBTablePrefix+clusterBusChannel, logger)
+	if err != nil {
+		return nil, err
+	}
+	return bus, nil
+}
+
 func NewStore(config *config.Configuration, isSingleUser bool, logger mlog.LoggerIFace) (store.Store, error) {
 	sqlDB, err := sql.Open(config.DBType, config.DBConfigString)
 	if err != nil {
@@ -294,6 +372,20 @@ func (s *Server) Start() error {
 	// metricsUpdater()   Calling this immediately causes integration unit tests to fail.
 	s.metricsUpdaterTask = scheduler.CreateRecurringTask("updateMetrics", metricsUpdater, updateMetricsTaskFrequency)
 
+	if s.config.EnableAutomations {
+		s.dueDateAutomationsTask = scheduler.CreateRecurringTask("dueDateAutomations", s.app.RunDueDateAutomations, dueDateAutomationsTaskFrequency)
+	}
+
+	s.dueDateInboxTask = scheduler.CreateRecurringTask("dueDateNotifications", s.app.RunDueDateNotifications, dueDateNotificationsTaskFrequency)
+
+	if s.config.EnableFileGC {
+		s.fileGCTask = scheduler.CreateRecurringTask("fileGC", s.app.RunFileGC, fileGCTaskFrequency)
+	}
+
+	s.fileDedupTask = scheduler.CreateRecurringTask("fileDedup", s.app.RunFileDeduplication, fileDedupTaskFrequency)
+
+	s.exportCleanupTask = scheduler.CreateRecurringTask("exportCleanup", s.app.RunExportCleanup, exportCleanupTaskFrequency)
+
 	if s.config.Telemetry {
 		firstRun := utils.GetMillis()
 		s.telemetry.RunTelemetryJob(firstRun)
@@ -335,6 +427,26 @@ func (s *Server) Shutdown() error {
 		s.metricsUpdaterTask.Cancel()
 	}
 
+	if s.dueDateAutomationsTask != nil {
+		s.dueDateAutomationsTask.Cancel()
+	}
+
+	if s.dueDateInboxTask != nil {
+		s.dueDateInboxTask.Cancel()
+	}
+
+	if s.fileGCTask != nil {
+		s.fileGCTask.Cancel()
+	}
+
+	if s.fileDedupTask != nil {
+		s.fileDedupTask.Cancel()
+	}
+
+	if s.exportCleanupTask != nil {
+		s.exportCleanupTask.Cancel()
+	}
+
 	if err := s.telemetry.Shutdown(); err != nil {
 		s.logger.Warn("Error occurred when shutting down telemetry", mlog.Err(err))
 	}
@@ -349,6 +461,12 @@ func (s *Server) Shutdown() error {
 
 	s.app.Shutdown()
 
+	if s.clusterBus != nil {
+		if err := s.clusterBus.Close(); err != nil {
+			s.logger.Warn("Error occurred when shutting down the cluster bus", mlog.Err(err))
+		}
+	}
+
 	defer s.logger.Info("Server.Shutdown")
 
 	return s.store.Shutdown()
@@ -520,3 +638,14 @@ func initNotificationService(backends []notify.Backend, logger mlog.LoggerIFace)
 	service, err := notify.New(logger, backends...)
 	return service, err
 }
+
+// inboxAppAPI gives the inbox notification backend access to the store, and
+// to the app to save and broadcast the new inbox items.
+type inboxAppAPI struct {
+	store.Store
+	app *app.App
+}
+
+func (a *inboxAppAPI) AddInboxItems(items []*appModel.InboxItem) error {
+	return a.app.AddInboxItems(items)
+}
@@ -0,0 +1,295 @@
+package cluster
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// notifyChunkSize keeps the notification payloads under the 8000
+	// bytes limit of Postgres.
+	notifyChunkSize = 7000
+
+	// incompleteMessageTimeout is how long the chunks of a message are
+	// kept waiting for the rest of them.
+	incompleteMessageTimeout = time.Minute
+
+	listenerMinReconnectInterval = 10 * time.Second
+	listenerMaxReconnectInterval = time.Minute
+
+	// publishQueueSize is the number of messages waiting to be sent
+	// before new ones are dropped.
+	publishQueueSize = 1024
+)
+
+var ErrPublishQueueFull = errors.New("cluster bus publish queue is full")
+
+// Bus relays messages between the nodes of a cluster.
+type Bus interface {
+	// Publish sends a message to all the nodes of the cluster.
+	Publish(message []byte) error
+	// Subscribe registers a function that receives the messages
+	// published by any node, including the current one.
+	Subscribe(handler func(message []byte))
+	// Close stops relaying messages.
+	Close() error
+}
+
+// PostgresBus is a cluster bus that relays the messages between nodes
+// through the LISTEN/NOTIFY commands of the Postgres database the nodes
+// share.
+type PostgresBus struct {
+	db       *sql.DB
+	listener *pq.Listener
+	channel  string
+	logger   mlog.LoggerIFace
+
+	handlersMu sync.RWMutex
+	handlers   []func(message []byte)
+
+	// pending holds the chunks of the messages that are not complete
+	// yet, by message ID.
+	pending map[string]*pendingMessage
+
+	// outgoing holds the published messages until they are sent.
+	outgoing chan []byte
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type pendingMessage struct {
+	chunks   []string
+	received int
+	createAt time.Time
+}
+
+// NewPostgresBus creates a bus that listens to a channel of the
+// database, and starts receiving its messages.
+func NewPostgresBus(connectionString, channel string, logger mlog.LoggerIFace) (*PostgresBus, error) {
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open cluster bus database: %w", err)
+	}
+
+	bus := &PostgresBus{
+		db:       db,
+		channel:  channel,
+		logger:   logger,
+		pending:  make(map[string]*pendingMessage),
+		outgoing: make(chan []byte, publishQueueSize),
+		done:     make(chan struct{}),
+	}
+
+	bus.listener = pq.NewListener(connectionString, listenerMinReconnectInterval, listenerMaxReconnectInterval, bus.onListenerEvent)
+	if err := bus.listener.Listen(channel); err != nil {
+		_ = bus.listener.Close()
+		_ = db.Close()
+		return nil, fmt.Errorf("cannot listen to cluster bus channel %s: %w", channel, err)
+	}
+
+	bus.wg.Add(2)
+	go bus.receive()
+	go bus.send()
+
+	return bus, nil
+}
+
+// Publish queues a message to be sent to all the nodes listening to
+// the channel, so the caller doesn't wait for the database. The
+// message is dropped if the queue is full.
+func (b *PostgresBus) Publish(message []byte) error {
+	select {
+	case b.outgoing <- message:
+		return nil
+	default:
+		return ErrPublishQueueFull
+	}
+}
+
+func (b *PostgresBus) send() {
+	defer b.wg.Done()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case message := <-b.outgoing:
+			if err := b.notify(message); err != nil {
+				b.logger.Error("Cannot send cluster bus message", mlog.Err(err))
+			}
+		}
+	}
+}
+
+// notify sends a message through the channel. Messages that don't fit
+// in a notification are split in several ones, sent in a single
+// transaction so they are delivered together.
+func (b *PostgresBus) notify(message []byte) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, payload := range encodeChunks(utils.NewID(utils.IDTypeNone), message) {
+		if _, err := tx.Exec("SELECT pg_notify($1, $2)", b.channel, payload); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Subscribe registers a function that receives the messages of the
+// channel.
+func (b *PostgresBus) Subscribe(handler func(message []byte)) {
+	b.handlersMu.Lock()
+	defer b.handlersMu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Close stops listening to the channel. The queued messages that
+// have not been sent yet are dropped.
+func (b *PostgresBus) Close() error {
+	close(b.done)
+	b.wg.Wait()
+
+	if err := b.listener.Close(); err != nil {
+		return err
+	}
+	return b.db.Close()
+}
+
+func (b *PostgresBus) onListenerEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventConnectionAttemptFailed, pq.ListenerEventDisconnected:
+		b.logger.Warn("Cluster bus lost its database connection", mlog.Err(err))
+	case pq.ListenerEventReconnected:
+		// the messages published while disconnected are lost
+		b.logger.Warn("Cluster bus reconnected, messages may have been lost")
+	}
+}
+
+func (b *PostgresBus) receive() {
+	defer b.wg.Done()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case notification := <-b.listener.Notify:
+			// a nil notification is sent after a reconnection
+			if notification == nil {
+				continue
+			}
+
+			message, err := b.addChunk(notification.Extra)
+			if err != nil {
+				b.logger.Error("Invalid cluster bus notification", mlog.Err(err))
+				continue
+			}
+			if message == nil {
+				continue
+			}
+
+			b.handlersMu.RLock()
+			handlers := b.handlers
+			b.handlersMu.RUnlock()
+			for _, handler := range handlers {
+				handler(message)
+			}
+		}
+	}
+}
+
+// addChunk adds a received chunk to its message, and returns the
+// message if it is complete.
+func (b *PostgresBus) addChunk(payload string) ([]byte, error) {
+	id, index, count, data, err := decodeChunk(payload)
+	if err != nil {
+		return nil, err
+	}
+	if count == 1 {
+		return base64.StdEncoding.DecodeString(data)
+	}
+
+	now := time.Now()
+	for pendingID, pending := range b.pending {
+		if now.Sub(pending.createAt) > incompleteMessageTimeout {
+			delete(b.pending, pendingID)
+		}
+	}
+
+	pending, ok := b.pending[id]
+	if !ok {
+		pending = &pendingMessage{chunks: make([]string, count), createAt: now}
+		b.pending[id] = pending
+	}
+	if len(pending.chunks) != count || pending.chunks[index] != "" {
+		delete(b.pending, id)
+		return nil, fmt.Errorf("inconsistent chunks for message %s", id)
+	}
+	pending.chunks[index] = data
+	pending.received++
+
+	if pending.received < count {
+		return nil, nil
+	}
+	delete(b.pending, id)
+	return base64.StdEncoding.DecodeString(strings.Join(pending.chunks, ""))
+}
+
+// encodeChunks splits a message in notification payloads with the
+// "id:index:count:data" format, where data is a part of the message
+// encoded in base64 to keep the payloads valid text.
+func encodeChunks(id string, message []byte) []string {
+	data := base64.StdEncoding.EncodeToString(message)
+
+	count := (len(data) + notifyChunkSize - 1) / notifyChunkSize
+	if count == 0 {
+		count = 1
+	}
+
+	payloads := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * notifyChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		payloads = append(payloads, fmt.Sprintf("%s:%d:%d:%s", id, i, count, data[i*notifyChunkSize:end]))
+	}
+	return payloads
+}
+
+func decodeChunk(payload string) (id string, index, count int, data string, err error) {
+	parts := strings.SplitN(payload, ":", 4)
+	if len(parts) != 4 {
+		return "", 0, 0, "", fmt.Errorf("malformed payload")
+	}
+
+	index, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, 0, "", fmt.Errorf("malformed chunk index: %w", err)
+	}
+	count, err = strconv.Atoi(parts[2])
+	if err != nil {
+		return "", 0, 0, "", fmt.Errorf("malformed chunk count: %w", err)
+	}
+	if count < 1 || index < 0 || index >= count {
+		return "", 0, 0, "", fmt.Errorf("chunk %d out of %d", index, count)
+	}
+	return parts[0], index, count, parts[3], nil
+}
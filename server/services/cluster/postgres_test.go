@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store/sqlstore"
+	"github.com/mattermost/focalboard/server/ws"
+	wsMocks "github.com/mattermost/focalboard/server/ws/mocks"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func TestChunks(t *testing.T) {
+	bus := &PostgresBus{pending: map[string]*pendingMessage{}}
+
+	t.Run("small messages fit in a notification", func(t *testing.T) {
+		payloads := encodeChunks("message-id", []byte(`{"type":"blockChange"}`))
+		require.Len(t, payloads, 1)
+
+		message, err := bus.addChunk(payloads[0])
+		require.NoError(t, err)
+		require.Equal(t, `{"type":"blockChange"}`, string(message))
+	})
+
+	t.Run("large messages are split", func(t *testing.T) {
+		original := []byte(strings.Repeat("ñ", 3*notifyChunkSize))
+		payloads := encodeChunks("message-id", original)
+		require.Greater(t, len(payloads), 1)
+
+		for i, payload := range payloads {
+			require.LessOrEqual(t, len(payload), notifyChunkSize+100)
+
+			message, err := bus.addChunk(payload)
+			require.NoError(t, err)
+			if i < len(payloads)-1 {
+				require.Nil(t, message)
+			} else {
+				require.Equal(t, original, message)
+			}
+		}
+		require.Empty(t, bus.pending)
+	})
+
+	t.Run("malformed payloads", func(t *testing.T) {
+		for _, payload := range []string{"", "id:0:1", "id:a:1:data", "id:1:1:data", "id:0:0:data"} {
+			_, err := bus.addChunk(payload)
+			require.Error(t, err, payload)
+		}
+	})
+}
+
+func TestPublish(t *testing.T) {
+	bus := &PostgresBus{outgoing: make(chan []byte, 1)}
+
+	require.NoError(t, bus.Publish([]byte("first")))
+	require.ErrorIs(t, bus.Publish([]byte("second")), ErrPublishQueueFull)
+	require.Equal(t, []byte("first"), <-bus.outgoing)
+}
+
+func TestPostgresBus(t *testing.T) {
+	dbType, connectionString, err := sqlstore.PrepareNewTestDatabase()
+	require.NoError(t, err)
+	if dbType != model.PostgresDBType {
+		t.Skip("the Postgres cluster bus needs a Postgres database")
+	}
+
+	db, err := sql.Open(dbType, connectionString)
+	require.NoError(t, err)
+	require.NoError(t, db.Ping())
+	require.NoError(t, db.Close())
+
+	logger := mlog.CreateConsoleTestLogger(t)
+	ctrl := gomock.NewController(t)
+	store := wsMocks.NewMockStore(ctrl)
+	store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{{UserID: model.SingleUser}}, nil).AnyTimes()
+
+	// two nodes sharing the same database
+	newNode := func() *ws.Server {
+		bus, err := NewPostgresBus(connectionString, "test_focalboard_cluster", logger)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, bus.Close()) })
+
+		server := ws.NewServer(&auth.Auth{}, "token", false, logger, store)
+		server.SetClusterBus(bus)
+		return server
+	}
+	serverA := newNode()
+	serverB := newNode()
+
+	r := mux.NewRouter()
+	serverB.RegisterRoutes(r)
+	httpServer := httptest.NewServer(r)
+	defer httpServer.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.WriteJSON(ws.WebsocketCommand{Action: "AUTH", Token: "token"}))
+	require.NoError(t, client.WriteJSON(ws.WebsocketCommand{Action: "SUBSCRIBE_TEAM", TeamID: "team-id"}))
+
+	// a block bigger than a notification, broadcasted until the client
+	// has been subscribed to the team and receives it. A read timeout
+	// would break the connection, so the broadcasts run in the
+	// background
+	block := &model.Block{ID: "block-id", BoardID: "board-id", Title: strings.Repeat("a", 2*notifyChunkSize)}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				serverA.BroadcastBlockChange("team-id", block)
+			}
+		}
+	}()
+
+	var message ws.UpdateBlockMsg
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(10*time.Second)))
+	require.NoError(t, client.ReadJSON(&message))
+	require.Equal(t, block.Title, message.Block.Title)
+}
@@ -58,6 +58,35 @@ type Configuration struct {
 	TeammateNameDisplay      string            `json:"teammate_name_display" mapstructure:"teammateNameDisplay"`
 	ShowEmailAddress         bool              `json:"show_email_address" mapstructure:"showEmailAddress"`
 	ShowFullName             bool              `json:"show_full_name" mapstructure:"showFullName"`
+	EnableAutomations        bool              `json:"enable_automations" mapstructure:"enable_automations"`
+	ClusterBus               string            `json:"cluster_bus" mapstructure:"cluster_bus"`
+
+	AutomationWebhookAllowedHosts []string `json:"automation_webhook_allowed_hosts" mapstructure:"automation_webhook_allowed_hosts"`
+	AutomationWebhookDeniedHosts  []string `json:"automation_webhook_denied_hosts" mapstructure:"automation_webhook_denied_hosts"`
+
+	WebsocketSendQueueSize      int    `json:"websocket_send_queue_size" mapstructure:"websocket_send_queue_size"`
+	WebsocketSlowConsumerPolicy string `json:"websocket_slow_consumer_policy" mapstructure:"websocket_slow_consumer_policy"`
+
+	TeamStorageQuota  int64 `json:"team_storage_quota" mapstructure:"team_storage_quota"`
+	BoardStorageQuota int64 `json:"board_storage_quota" mapstructure:"board_storage_quota"`
+
+	EnableFileGC           bool `json:"enable_file_gc" mapstructure:"enable_file_gc"`
+	FileGCGracePeriodHours int  `json:"file_gc_grace_period_hours" mapstructure:"file_gc_grace_period_hours"`
+	FileGCDryRun           bool `json:"file_gc_dry_run" mapstructure:"file_gc_dry_run"`
+
+	UploadAllowedMimeTypes  []string `json:"upload_allowed_mime_types" mapstructure:"upload_allowed_mime_types"`
+	UploadDeniedMimeTypes   []string `json:"upload_denied_mime_types" mapstructure:"upload_denied_mime_types"`
+	UploadAllowedExtensions []string `json:"upload_allowed_extensions" mapstructure:"upload_allowed_extensions"`
+	UploadDeniedExtensions  []string `json:"upload_denied_extensions" mapstructure:"upload_denied_extensions"`
+
+	UploadScanner               string `json:"upload_scanner" mapstructure:"upload_scanner"`
+	UploadScannerAddress        string `json:"upload_scanner_address" mapstructure:"upload_scanner_address"`
+	UploadScannerTimeoutSeconds int    `json:"upload_scanner_timeout_seconds" mapstructure:"upload_scanner_timeout_seconds"`
+
+	FileSignedURLExpirySeconds int  `json:"file_signed_url_expiry_seconds" mapstructure:"file_signed_url_expiry_seconds"`
+	FileSignedURLRedirectS3    bool `json:"file_signed_url_redirect_s3" mapstructure:"file_signed_url_redirect_s3"`
+
+	ExportRetentionHours int `json:"export_retention_hours" mapstructure:"export_retention_hours"`
 
 	AuthMode string `json:"authMode" mapstructure:"authMode"`
 
@@ -120,6 +149,27 @@ func ReadConfigFile(configFilePath string) (*Configuration, error) {
 	viper.SetDefault("TeammateNameDisplay", "username")
 	viper.SetDefault("ShowEmailAddress", false)
 	viper.SetDefault("ShowFullName", false)
+	viper.SetDefault("EnableAutomations", true)
+	viper.SetDefault("AutomationWebhookAllowedHosts", []string{})
+	viper.SetDefault("AutomationWebhookDeniedHosts", []string{})
+	viper.SetDefault("ClusterBus", "")
+	viper.SetDefault("WebsocketSendQueueSize", 1024)
+	viper.SetDefault("WebsocketSlowConsumerPolicy", "resync")
+	viper.SetDefault("TeamStorageQuota", 0)
+	viper.SetDefault("BoardStorageQuota", 0)
+	viper.SetDefault("EnableFileGC", true)
+	viper.SetDefault("FileGCGracePeriodHours", 168)
+	viper.SetDefault("FileGCDryRun", false)
+	viper.SetDefault("UploadAllowedMimeTypes", []string{})
+	viper.SetDefault("UploadDeniedMimeTypes", []string{})
+	viper.SetDefault("UploadAllowedExtensions", []string{})
+	viper.SetDefault("UploadDeniedExtensions", []string{})
+	viper.SetDefault("UploadScanner", "")
+	viper.SetDefault("UploadScannerAddress", "")
+	viper.SetDefault("UploadScannerTimeoutSeconds", 60)
+	viper.SetDefault("FileSignedURLExpirySeconds", 300)
+	viper.SetDefault("FileSignedURLRedirectS3", false)
+	viper.SetDefault("ExportRetentionHours", 72)
 
 	err := viper.ReadInConfig() // Find and read the config file
 	if err != nil {             // Handle errors reading the config file
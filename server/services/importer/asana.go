@@ -0,0 +1,255 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// asanaExport is the JSON export of the tasks of Asana projects, limited
+// to the fields that are imported or reported.
+type asanaExport struct {
+	Data []asanaTask `json:"data"`
+}
+
+type asanaTask struct {
+	GID          string             `json:"gid"`
+	Name         string             `json:"name"`
+	Notes        string             `json:"notes"`
+	Completed    bool               `json:"completed"`
+	CreatedAt    string             `json:"created_at"`
+	StartOn      string             `json:"start_on"`
+	DueOn        string             `json:"due_on"`
+	DueAt        string             `json:"due_at"`
+	Assignee     *asanaUser         `json:"assignee"`
+	Memberships  []asanaMembership  `json:"memberships"`
+	Tags         []asanaRef         `json:"tags"`
+	CustomFields []asanaCustomField `json:"custom_fields"`
+	Subtasks     []asanaTask        `json:"subtasks"`
+	PermalinkURL string             `json:"permalink_url"`
+	NumLikes     int                `json:"num_likes"`
+}
+
+type asanaUser struct {
+	GID   string `json:"gid"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type asanaRef struct {
+	GID  string `json:"gid"`
+	Name string `json:"name"`
+}
+
+type asanaMembership struct {
+	Project asanaRef `json:"project"`
+	Section asanaRef `json:"section"`
+}
+
+type asanaCustomField struct {
+	GID             string      `json:"gid"`
+	Name            string      `json:"name"`
+	Type            string      `json:"type"`
+	EnumOptions     []asanaRef  `json:"enum_options"`
+	EnumValue       *asanaRef   `json:"enum_value"`
+	MultiEnumValues []asanaRef  `json:"multi_enum_values"`
+	NumberValue     *float64    `json:"number_value"`
+	TextValue       string      `json:"text_value"`
+	PeopleValue     []asanaUser `json:"people_value"`
+	DisplayValue    string      `json:"display_value"`
+	DateValue       *struct {
+		Date     string `json:"date"`
+		DateTime string `json:"date_time"`
+	} `json:"date_value"`
+}
+
+// asanaCustomFieldTypes maps the types of Asana custom fields to the
+// types of card properties.
+var asanaCustomFieldTypes = map[string]string{
+	"text":       "text",
+	"number":     "number",
+	"enum":       "select",
+	"multi_enum": "multiSelect",
+	"date":       "date",
+	"people":     "multiPerson",
+}
+
+// asanaImporter converts the JSON export of Asana tasks, with a board per
+// project grouped by the section of the tasks. Subtasks are imported as
+// checkboxes.
+type asanaImporter struct{}
+
+func (asanaImporter) Name() string {
+	return "asana"
+}
+
+func (asanaImporter) Convert(r io.Reader) (*Result, error) {
+	var input asanaExport
+	if err := json.NewDecoder(io.LimitReader(r, maxExportSize)).Decode(&input); err != nil {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("invalid Asana export: %s", err))
+	}
+
+	result := newResult()
+	var projects []asanaRef
+	tasksByProject := map[string][]asanaTask{}
+	for _, task := range input.Data {
+		if len(task.Memberships) == 0 {
+			result.unmapped("Tasks without a project")
+			continue
+		}
+		reportAsanaTask(task, result)
+		for _, membership := range task.Memberships {
+			if _, ok := tasksByProject[membership.Project.GID]; !ok {
+				projects = append(projects, membership.Project)
+			}
+			tasksByProject[membership.Project.GID] = append(tasksByProject[membership.Project.GID], task)
+		}
+	}
+	if len(projects) == 0 {
+		return nil, model.NewErrBadRequest("invalid Asana export: no projects")
+	}
+
+	for _, project := range projects {
+		convertAsanaProject(project, tasksByProject[project.GID], result)
+	}
+	return result, nil
+}
+
+func convertAsanaProject(project asanaRef, tasks []asanaTask, result *Result) {
+	b := newBoardBuilder(project.Name, "")
+
+	sectionPropID := b.addProperty("Section", "select")
+	assigneePropID := b.addProperty("Assignee", "person")
+	completedPropID := b.addProperty("Completed", "checkbox")
+	duePropID := b.addProperty("Due date", "date")
+	var tagsPropID string
+	customFields := map[string]string{}
+	urlPropID := b.addProperty("Original URL", "url")
+
+	cardOrder := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		card := b.addCard(task.Name, parseMillis(task.CreatedAt, time.RFC3339))
+		cardOrder = append(cardOrder, card.ID)
+
+		for _, membership := range task.Memberships {
+			if membership.Project.GID == project.GID && membership.Section.Name != "" {
+				setProperty(card, sectionPropID, b.addOption(sectionPropID, membership.Section.Name))
+			}
+		}
+		if task.Assignee != nil {
+			result.addMember(asanaMember(*task.Assignee))
+			setProperty(card, assigneePropID, task.Assignee.GID)
+		}
+		if task.Completed {
+			setProperty(card, completedPropID, "true")
+		}
+		due := parseDay(task.DueOn)
+		if task.DueAt != "" {
+			due = parseMillis(task.DueAt, time.RFC3339)
+		}
+		setProperty(card, duePropID, dateValue(parseDay(task.StartOn), due))
+		setProperty(card, urlPropID, task.PermalinkURL)
+
+		if len(task.Tags) > 0 && tagsPropID == "" {
+			tagsPropID = b.addProperty("Tags", "multiSelect")
+		}
+		tags := []interface{}{}
+		for _, tag := range task.Tags {
+			tags = append(tags, b.addOption(tagsPropID, tag.Name))
+		}
+		setProperty(card, tagsPropID, tags)
+
+		for _, field := range task.CustomFields {
+			propType, ok := asanaCustomFieldTypes[field.Type]
+			if !ok {
+				continue
+			}
+			propID, ok := customFields[field.GID]
+			if !ok {
+				propID = b.addProperty(field.Name, propType)
+				customFields[field.GID] = propID
+				for _, option := range field.EnumOptions {
+					b.addOption(propID, option.Name)
+				}
+			}
+			setProperty(card, propID, asanaCustomFieldValue(b, propID, field, result))
+		}
+
+		if notes := strings.TrimSpace(task.Notes); notes != "" {
+			b.addContent(card, model.TypeText, notes, nil)
+		}
+		for _, subtask := range task.Subtasks {
+			b.addContent(card, model.TypeCheckbox, subtask.Name, map[string]interface{}{"value": subtask.Completed})
+		}
+	}
+
+	b.addView("Board view", sectionPropID, cardOrder)
+	b.addTo(result)
+}
+
+// reportAsanaTask counts the fields of a task that are not imported.
+func reportAsanaTask(task asanaTask, result *Result) {
+	for _, field := range task.CustomFields {
+		if _, ok := asanaCustomFieldTypes[field.Type]; !ok && field.DisplayValue != "" {
+			result.unmapped("Custom field " + field.Name)
+		}
+	}
+	for _, subtask := range task.Subtasks {
+		if subtask.Notes != "" || subtask.Assignee != nil || subtask.DueOn != "" || len(subtask.Subtasks) > 0 {
+			result.unmapped("Subtask details")
+		}
+	}
+	if task.NumLikes > 0 {
+		result.unmapped("Likes")
+	}
+}
+
+// asanaCustomFieldValue returns the value of the property a custom field
+// is imported to.
+func asanaCustomFieldValue(b *boardBuilder, propID string, field asanaCustomField, result *Result) interface{} {
+	switch field.Type {
+	case "text":
+		return field.TextValue
+	case "number":
+		if field.NumberValue == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*field.NumberValue, 'f', -1, 64)
+	case "enum":
+		if field.EnumValue == nil {
+			return ""
+		}
+		return b.addOption(propID, field.EnumValue.Name)
+	case "multi_enum":
+		ids := []interface{}{}
+		for _, value := range field.MultiEnumValues {
+			ids = append(ids, b.addOption(propID, value.Name))
+		}
+		return ids
+	case "date":
+		if field.DateValue == nil {
+			return ""
+		}
+		if field.DateValue.DateTime != "" {
+			return dateValue(parseMillis(field.DateValue.DateTime, time.RFC3339), 0)
+		}
+		return dateValue(parseDay(field.DateValue.Date), 0)
+	case "people":
+		ids := []interface{}{}
+		for _, user := range field.PeopleValue {
+			result.addMember(asanaMember(user))
+			ids = append(ids, user.GID)
+		}
+		return ids
+	}
+	return ""
+}
+
+func asanaMember(user asanaUser) Member {
+	return Member{ID: user.GID, Email: user.Email, FullName: user.Name}
+}
@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+const asanaTestExport = `{
+	"data": [
+		{
+			"gid": "1",
+			"name": "Write the launch post",
+			"notes": "Draft first",
+			"completed": false,
+			"created_at": "2021-10-20T16:20:18.442Z",
+			"start_on": "2021-10-21",
+			"due_on": "2021-10-22",
+			"assignee": {"gid": "user-1", "name": "Alice Doe", "email": "alice@example.com"},
+			"memberships": [
+				{"project": {"gid": "project-1", "name": "Launch"}, "section": {"gid": "section-1", "name": "Doing"}},
+				{"project": {"gid": "project-2", "name": "Marketing"}, "section": {"gid": "section-2", "name": "Ideas"}}
+			],
+			"tags": [{"gid": "tag-1", "name": "Blog"}],
+			"custom_fields": [
+				{"gid": "field-1", "name": "Priority", "type": "enum", "enum_options": [{"gid": "p1", "name": "High"}, {"gid": "p2", "name": "Low"}], "enum_value": {"gid": "p2", "name": "Low"}},
+				{"gid": "field-2", "name": "Cost", "type": "number", "number_value": 2.5},
+				{"gid": "field-3", "name": "Progress", "type": "formula", "display_value": "50%"}
+			],
+			"subtasks": [
+				{"gid": "2", "name": "Outline", "completed": true},
+				{"gid": "3", "name": "Review", "completed": false, "assignee": {"gid": "user-2", "name": "Bob"}}
+			],
+			"permalink_url": "https://app.asana.com/0/1/1",
+			"num_likes": 1
+		},
+		{
+			"gid": "4",
+			"name": "Private task",
+			"memberships": []
+		}
+	]
+}`
+
+func TestAsanaImporter(t *testing.T) {
+	result, err := asanaImporter{}.Convert(strings.NewReader(asanaTestExport))
+	require.NoError(t, err)
+
+	require.Len(t, result.Boards, 2)
+	board := result.Boards[0]
+	require.Equal(t, "Launch", board.Title)
+	require.Equal(t, "Marketing", result.Boards[1].Title)
+
+	section := findProperty(t, board, "Section")
+	assignee := findProperty(t, board, "Assignee")
+	due := findProperty(t, board, "Due date")
+	tags := findProperty(t, board, "Tags")
+	priority := findProperty(t, board, "Priority")
+	cost := findProperty(t, board, "Cost")
+	require.Len(t, priority.Options, 2)
+
+	cards := findBlocks(result.Blocks, model.TypeCard, "")
+	require.Len(t, cards, 2)
+	card := cards[0]
+	require.Equal(t, board.ID, card.BoardID)
+	require.Equal(t, "Write the launch post", card.Title)
+
+	props := cardProperties(card)
+	require.Equal(t, optionID(t, section, "Doing"), props[section.ID])
+	require.Equal(t, "user-1", props[assignee.ID])
+	require.Equal(t, `{"from":1634817600000,"to":1634904000000}`, props[due.ID])
+	require.Equal(t, []interface{}{optionID(t, tags, "Blog")}, props[tags.ID])
+	require.Equal(t, optionID(t, priority, "Low"), props[priority.ID])
+	require.Equal(t, "2.5", props[cost.ID])
+
+	checkboxes := findBlocks(result.Blocks, model.TypeCheckbox, card.ID)
+	require.Len(t, checkboxes, 2)
+	require.Equal(t, "Outline", checkboxes[0].Title)
+	require.Equal(t, true, checkboxes[0].Fields["value"])
+	require.Len(t, findBlocks(result.Blocks, model.TypeText, card.ID), 1)
+
+	require.Equal(t, Member{ID: "user-1", Email: "alice@example.com", FullName: "Alice Doe"}, result.Members["user-1"])
+	require.Equal(t, map[string]int{
+		"Tasks without a project": 1,
+		"Custom field Progress":   1,
+		"Subtask details":         1,
+		"Likes":                   1,
+	}, result.Unmapped)
+}
+
+func TestAsanaImporterInvalidExport(t *testing.T) {
+	_, err := asanaImporter{}.Convert(strings.NewReader(`{"data": []}`))
+	require.True(t, model.IsErrBadRequest(err))
+}
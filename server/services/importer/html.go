@@ -0,0 +1,128 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	spaces     = regexp.MustCompile(`\s+`)
+	blankLines = regexp.MustCompile(`[ ]*\n[ \n]*\n`)
+)
+
+// htmlToMarkdown converts the rich text of an export to the markdown of
+// text blocks. Formatting without a markdown equivalent is dropped.
+func htmlToMarkdown(s string) string {
+	if !strings.Contains(s, "<") {
+		return strings.TrimSpace(html.UnescapeString(s))
+	}
+
+	var sb strings.Builder
+	var links []string
+	listDepth := 0
+	inPre := false
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			out := blankLines.ReplaceAllString(sb.String(), "\n\n")
+			return strings.TrimSpace(out)
+		case html.TextToken:
+			text := string(z.Text())
+			if !inPre {
+				text = spaces.ReplaceAllString(text, " ")
+				if out := sb.String(); out == "" || strings.HasSuffix(out, "\n") || strings.HasSuffix(out, " ") {
+					text = strings.TrimLeft(text, " ")
+				}
+			}
+			sb.WriteString(text)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "p", "div", "table", "tr":
+				sb.WriteString("\n\n")
+			case "br":
+				sb.WriteString("\n")
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				sb.WriteString("\n\n" + strings.Repeat("#", int(name[1]-'0')) + " ")
+			case "ul", "ol":
+				listDepth++
+				sb.WriteString("\n")
+			case "li":
+				sb.WriteString("\n" + strings.Repeat("  ", max(listDepth-1, 0)) + "- ")
+			case "b", "strong":
+				sb.WriteString("**")
+			case "i", "em":
+				sb.WriteString("_")
+			case "del", "s":
+				sb.WriteString("~~")
+			case "code", "tt":
+				if !inPre {
+					sb.WriteString("`")
+				}
+			case "pre":
+				inPre = true
+				sb.WriteString("\n\n```\n")
+			case "blockquote":
+				sb.WriteString("\n\n> ")
+			case "td", "th":
+				sb.WriteString(" | ")
+			case "a":
+				href := ""
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					if string(key) == "href" {
+						href = string(val)
+					}
+				}
+				links = append(links, href)
+				if href != "" {
+					sb.WriteString("[")
+				}
+			case "img":
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					if string(key) == "src" {
+						sb.WriteString("![](" + string(val) + ")")
+					}
+				}
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "p", "div", "table", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote":
+				sb.WriteString("\n\n")
+			case "ul", "ol":
+				listDepth--
+				sb.WriteString("\n")
+			case "b", "strong":
+				sb.WriteString("**")
+			case "i", "em":
+				sb.WriteString("_")
+			case "del", "s":
+				sb.WriteString("~~")
+			case "code", "tt":
+				if !inPre {
+					sb.WriteString("`")
+				}
+			case "pre":
+				inPre = false
+				sb.WriteString("\n```\n\n")
+			case "a":
+				if len(links) > 0 {
+					href := links[len(links)-1]
+					links = links[:len(links)-1]
+					if href != "" {
+						sb.WriteString("](" + href + ")")
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// maxExportSize is the size limit of the exports read by the importers,
+// which are decoded in memory.
+const maxExportSize = 1024 * 1024 * 70
+
+// Importer converts the export of another tool to boards and blocks.
+type Importer interface {
+	// Name returns the name of the tool, as used in import URLs.
+	Name() string
+
+	// Convert reads an export of the tool. The boards and blocks of the
+	// result have new ids, but no team and author.
+	Convert(r io.Reader) (*Result, error)
+}
+
+// Member is a person of an export. Person properties of the converted
+// cards hold member ids, to be replaced by the ids of the users they map to.
+type Member struct {
+	ID       string
+	Username string
+	Email    string
+	FullName string
+}
+
+// DisplayName returns the name the member is reported with.
+func (m Member) DisplayName() string {
+	switch {
+	case m.FullName != "":
+		return m.FullName
+	case m.Username != "":
+		return m.Username
+	case m.Email != "":
+		return m.Email
+	}
+	return m.ID
+}
+
+// Result is an export converted to boards and blocks.
+type Result struct {
+	Boards []*model.Board
+	Blocks []*model.Block
+
+	// Members are the people set in person properties, by member id.
+	Members map[string]Member
+
+	// Unmapped counts, by name, the fields of the export that have no
+	// equivalent on boards and were not imported.
+	Unmapped map[string]int
+}
+
+func newResult() *Result {
+	return &Result{
+		Members:  map[string]Member{},
+		Unmapped: map[string]int{},
+	}
+}
+
+func (r *Result) addMember(member Member) {
+	if member.ID == "" {
+		return
+	}
+	if _, ok := r.Members[member.ID]; !ok {
+		r.Members[member.ID] = member
+	}
+}
+
+func (r *Result) unmapped(name string) {
+	r.Unmapped[name]++
+}
+
+var importers = map[string]Importer{}
+
+func register(importer Importer) {
+	importers[importer.Name()] = importer
+}
+
+func init() {
+	register(trelloImporter{})
+	register(jiraImporter{})
+	register(asanaImporter{})
+}
+
+// Get returns the importer of a tool.
+func Get(name string) (Importer, bool) {
+	importer, ok := importers[name]
+	return importer, ok
+}
+
+// Names returns the names of the tools that can be imported, sorted.
+func Names() []string {
+	names := make([]string, 0, len(importers))
+	for name := range importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// boardBuilder creates a board and its blocks.
+type boardBuilder struct {
+	board      *model.Board
+	blocks     []*model.Block
+	now        int64
+	colorIndex int
+}
+
+func newBoardBuilder(title, description string) *boardBuilder {
+	now := utils.GetMillis()
+	return &boardBuilder{
+		board: &model.Board{
+			ID:             utils.NewID(utils.IDTypeBoard),
+			Type:           model.BoardTypePrivate,
+			Title:          title,
+			Description:    description,
+			Properties:     map[string]interface{}{},
+			CardProperties: []map[string]interface{}{},
+			CreateAt:       now,
+			UpdateAt:       now,
+		},
+		now: now,
+	}
+}
+
+// addProperty adds a card property to the board and returns its id.
+func (b *boardBuilder) addProperty(name, propType string) string {
+	id := utils.NewID(utils.IDTypeBlock)
+	b.board.CardProperties = append(b.board.CardProperties, map[string]interface{}{
+		"id":      id,
+		"name":    name,
+		"type":    propType,
+		"options": []interface{}{},
+	})
+	return id
+}
+
+// addOption adds an option to a select property, unless one has the same
+// value, and returns its id.
+func (b *boardBuilder) addOption(propID, value string) string {
+	for _, prop := range b.board.CardProperties {
+		if prop["id"] != propID {
+			continue
+		}
+		options, _ := prop["options"].([]interface{})
+		for _, o := range options {
+			if option, ok := o.(map[string]interface{}); ok && option["value"] == value {
+				id, _ := option["id"].(string)
+				return id
+			}
+		}
+		id := utils.NewID(utils.IDTypeBlock)
+		prop["options"] = append(options, map[string]interface{}{
+			"id":    id,
+			"value": value,
+			"color": model.PropOptionColor(b.colorIndex),
+		})
+		b.colorIndex++
+		return id
+	}
+	return ""
+}
+
+// addView adds a board view grouped by a select property, with the cards
+// in order.
+func (b *boardBuilder) addView(title, groupByID string, cardOrder []string) {
+	order := make([]interface{}, 0, len(cardOrder))
+	for _, id := range cardOrder {
+		order = append(order, id)
+	}
+	visiblePropertyIDs := []interface{}{}
+	if groupByID != "" {
+		visiblePropertyIDs = append(visiblePropertyIDs, groupByID)
+	}
+
+	b.blocks = append(b.blocks, &model.Block{
+		ID:       utils.NewID(utils.IDTypeView),
+		ParentID: b.board.ID,
+		BoardID:  b.board.ID,
+		Schema:   1,
+		Type:     model.TypeView,
+		Title:    title,
+		Fields: map[string]interface{}{
+			"viewType":           "board",
+			"groupById":          groupByID,
+			"sortOptions":        []interface{}{},
+			"visiblePropertyIds": visiblePropertyIDs,
+			"visibleOptionIds":   []interface{}{},
+			"hiddenOptionIds":    []interface{}{},
+			"collapsedOptionIds": []interface{}{},
+			"filter":             map[string]interface{}{"operation": "and", "filters": []interface{}{}},
+			"cardOrder":          order,
+			"columnWidths":       map[string]interface{}{},
+			"columnCalculations": map[string]interface{}{},
+			"kanbanCalculations": map[string]interface{}{},
+			"defaultTemplateId":  "",
+		},
+		CreateAt: b.now,
+		UpdateAt: b.now,
+	})
+}
+
+// addCard adds a card to the board, created at a time of the export if
+// it is known.
+func (b *boardBuilder) addCard(title string, createAt int64) *model.Block {
+	if createAt == 0 {
+		createAt = b.now
+	}
+	card := &model.Block{
+		ID:       utils.NewID(utils.IDTypeCard),
+		ParentID: b.board.ID,
+		BoardID:  b.board.ID,
+		Schema:   1,
+		Type:     model.TypeCard,
+		Title:    title,
+		Fields: map[string]interface{}{
+			"icon":         "",
+			"isTemplate":   false,
+			"properties":   map[string]interface{}{},
+			"contentOrder": []interface{}{},
+		},
+		CreateAt: createAt,
+		UpdateAt: b.now,
+	}
+	b.blocks = append(b.blocks, card)
+	return card
+}
+
+// setProperty sets a property of a card, unless the value is empty.
+func setProperty(card *model.Block, propID string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return
+		}
+	}
+	props, _ := card.Fields["properties"].(map[string]interface{})
+	props[propID] = value
+}
+
+// addContent adds a content block to a card. Text, checkbox and divider
+// blocks are listed in the content order of the card.
+func (b *boardBuilder) addContent(card *model.Block, blockType model.BlockType, title string, fields map[string]interface{}) *model.Block {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	block := &model.Block{
+		ID:       utils.NewID(model.BlockType2IDType(blockType)),
+		ParentID: card.ID,
+		BoardID:  b.board.ID,
+		Schema:   1,
+		Type:     blockType,
+		Title:    title,
+		Fields:   fields,
+		CreateAt: b.now,
+		UpdateAt: b.now,
+	}
+	b.blocks = append(b.blocks, block)
+
+	if blockType != model.TypeComment {
+		contentOrder, _ := card.Fields["contentOrder"].([]interface{})
+		card.Fields["contentOrder"] = append(contentOrder, block.ID)
+	}
+	return block
+}
+
+// archive marks a card as archived at a time of the export.
+func archive(card *model.Block, archivedAt int64) {
+	if archivedAt == 0 {
+		archivedAt = utils.GetMillis()
+	}
+	card.Fields[model.CardArchivedAtField] = archivedAt
+}
+
+// addTo appends the board and its blocks to a result.
+func (b *boardBuilder) addTo(result *Result) {
+	result.Boards = append(result.Boards, b.board)
+	result.Blocks = append(result.Blocks, b.blocks...)
+}
+
+// dateValue returns the value of a date property, a range if both times
+// are set.
+func dateValue(from, to int64) string {
+	switch {
+	case from == 0 && to == 0:
+		return ""
+	case from == 0 || to == 0 || from == to:
+		if from == 0 {
+			from = to
+		}
+		return fmt.Sprintf(`{"from":%d}`, from)
+	}
+	return fmt.Sprintf(`{"from":%d,"to":%d}`, from, to)
+}
+
+// parseMillis parses a time of an export, in any of the layouts, to
+// milliseconds. It returns 0 if the time is not set or invalid.
+func parseMillis(s string, layouts ...string) int64 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return model.GetMillisForTime(t)
+		}
+	}
+	return 0
+}
+
+// parseDay parses a date without time of an export to the milliseconds
+// of noon UTC, the time the date properties of boards use.
+func parseDay(s string) int64 {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return 0
+	}
+	return model.GetMillisForTime(t.Add(12 * time.Hour))
+}
@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+// findProperty returns the card property of a board with a name.
+func findProperty(t *testing.T, board *model.Board, name string) model.PropDef {
+	schema, err := model.ParsePropertySchema(board)
+	require.NoError(t, err)
+	for _, propDef := range schema {
+		if propDef.Name == name {
+			return propDef
+		}
+	}
+	require.Failf(t, "missing property", "no property %s", name)
+	return model.PropDef{}
+}
+
+// optionID returns the id of the option of a property with a value.
+func optionID(t *testing.T, propDef model.PropDef, value string) string {
+	for _, option := range propDef.Options {
+		if option.Value == value {
+			return option.ID
+		}
+	}
+	require.Failf(t, "missing option", "no option %s in %s", value, propDef.Name)
+	return ""
+}
+
+// findBlocks returns the blocks of a type, with a parent if it is set.
+func findBlocks(blocks []*model.Block, blockType model.BlockType, parentID string) []*model.Block {
+	var found []*model.Block
+	for _, block := range blocks {
+		if block.Type == blockType && (parentID == "" || block.ParentID == parentID) {
+			found = append(found, block)
+		}
+	}
+	return found
+}
+
+func cardProperties(card *model.Block) map[string]interface{} {
+	props, _ := card.Fields["properties"].(map[string]interface{})
+	return props
+}
+
+func TestGet(t *testing.T) {
+	require.Equal(t, []string{"asana", "jira", "trello"}, Names())
+
+	imp, ok := Get("trello")
+	require.True(t, ok)
+	require.Equal(t, "trello", imp.Name())
+
+	_, ok = Get("notion")
+	require.False(t, ok)
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	testCases := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"plain text", "Kicking off &amp; more", "Kicking off & more"},
+		{"paragraphs", "<p>First</p><p>Second <b>bold</b> and <em>italic</em></p>", "First\n\nSecond **bold** and _italic_"},
+		{"lists", "<ul><li>one</li><li>two <a href=\"https://example.com\">link</a></li></ul>", "- one\n- two [link](https://example.com)"},
+		{"line breaks", "one<br/>two", "one\ntwo"},
+		{"code", "<pre>a  b</pre>", "```\na  b\n```"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, htmlToMarkdown(tc.html))
+		})
+	}
+}
+
+func TestDateValue(t *testing.T) {
+	require.Equal(t, "", dateValue(0, 0))
+	require.Equal(t, `{"from":10}`, dateValue(10, 0))
+	require.Equal(t, `{"from":20}`, dateValue(0, 20))
+	require.Equal(t, `{"from":10,"to":20}`, dateValue(10, 20))
+	require.Equal(t, int64(1642161600000), parseDay("2022-01-14"))
+}
@@ -0,0 +1,295 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// jiraRSS is the XML export of the issues of a Jira search, limited to
+// the fields that are imported or reported.
+type jiraRSS struct {
+	Channel struct {
+		Items []jiraItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type jiraItem struct {
+	Link    string `xml:"link"`
+	Project struct {
+		Key  string `xml:"key,attr"`
+		Name string `xml:",chardata"`
+	} `xml:"project"`
+	Key                  string            `xml:"key"`
+	Summary              string            `xml:"summary"`
+	Description          string            `xml:"description"`
+	Environment          string            `xml:"environment"`
+	Type                 string            `xml:"type"`
+	Priority             string            `xml:"priority"`
+	Status               string            `xml:"status"`
+	Resolution           string            `xml:"resolution"`
+	Assignee             jiraUser          `xml:"assignee"`
+	Reporter             jiraUser          `xml:"reporter"`
+	Labels               []string          `xml:"labels>label"`
+	Created              string            `xml:"created"`
+	Due                  string            `xml:"due"`
+	Votes                int               `xml:"votes"`
+	FixVersions          []string          `xml:"fixVersion"`
+	Components           []string          `xml:"component"`
+	Parent               string            `xml:"parent"`
+	Subtasks             []string          `xml:"subtasks>subtask"`
+	IssueLinks           []string          `xml:"issuelinks>issuelinktype"`
+	Attachments          []string          `xml:"attachments>attachment"`
+	TimeOriginalEstimate string            `xml:"timeoriginalestimate"`
+	TimeSpent            string            `xml:"timespent"`
+	Comments             []jiraComment     `xml:"comments>comment"`
+	CustomFields         []jiraCustomField `xml:"customfields>customfield"`
+}
+
+type jiraUser struct {
+	AccountID string `xml:"accountid,attr"`
+	Username  string `xml:"username,attr"`
+	Name      string `xml:",chardata"`
+}
+
+// id returns the id of the user, empty if the issue is unassigned.
+func (u jiraUser) id() string {
+	if u.AccountID == "-1" {
+		return ""
+	}
+	if u.AccountID != "" {
+		return u.AccountID
+	}
+	return u.Username
+}
+
+type jiraComment struct {
+	Author  string `xml:"author,attr"`
+	Created string `xml:"created,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type jiraCustomField struct {
+	ID     string   `xml:"id,attr"`
+	Key    string   `xml:"key,attr"`
+	Name   string   `xml:"customfieldname"`
+	Values []string `xml:"customfieldvalues>customfieldvalue"`
+}
+
+// jiraRankKey is the key of the custom field Jira orders issues by.
+const jiraRankKey = "com.pyxis.greenhopper.jira:gh-lexo-rank"
+
+// jiraCustomFieldTypes maps the types of Jira custom fields, the end of
+// their keys, to the types of card properties.
+var jiraCustomFieldTypes = map[string]string{
+	"textfield":       "text",
+	"textarea":        "text",
+	"url":             "url",
+	"float":           "number",
+	"datepicker":      "date",
+	"datetime":        "date",
+	"select":          "select",
+	"radiobuttons":    "select",
+	"multiselect":     "multiSelect",
+	"multicheckboxes": "multiSelect",
+	"labels":          "multiSelect",
+	"gh-sprint":       "multiSelect",
+}
+
+// jiraImporter converts the XML export of Jira issues, with a board per
+// project grouped by the status of the issues.
+type jiraImporter struct{}
+
+func (jiraImporter) Name() string {
+	return "jira"
+}
+
+func (jiraImporter) Convert(r io.Reader) (*Result, error) {
+	var input jiraRSS
+	decoder := xml.NewDecoder(io.LimitReader(r, maxExportSize))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&input); err != nil {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("invalid Jira export: %s", err))
+	}
+	if len(input.Channel.Items) == 0 {
+		return nil, model.NewErrBadRequest("invalid Jira export: no issues")
+	}
+
+	result := newResult()
+	var projects []string
+	itemsByProject := map[string][]jiraItem{}
+	for _, item := range input.Channel.Items {
+		for _, user := range []jiraUser{item.Assignee, item.Reporter} {
+			result.addMember(Member{ID: user.id(), Username: user.Username, FullName: strings.TrimSpace(user.Name)})
+		}
+		if _, ok := itemsByProject[item.Project.Key]; !ok {
+			projects = append(projects, item.Project.Key)
+		}
+		itemsByProject[item.Project.Key] = append(itemsByProject[item.Project.Key], item)
+	}
+
+	for _, project := range projects {
+		items := itemsByProject[project]
+		convertJiraProject(items, result)
+	}
+	return result, nil
+}
+
+func convertJiraProject(items []jiraItem, result *Result) {
+	title := strings.TrimSpace(items[0].Project.Name)
+	if title == "" {
+		title = "Jira import"
+	}
+	b := newBoardBuilder(title, "")
+
+	statusPropID := b.addProperty("Status", "select")
+	priorityPropID := b.addProperty("Priority", "select")
+	typePropID := b.addProperty("Type", "select")
+	resolutionPropID := b.addProperty("Resolution", "select")
+	labelsPropID := b.addProperty("Labels", "multiSelect")
+	assigneePropID := b.addProperty("Assignee", "person")
+	reporterPropID := b.addProperty("Reporter", "person")
+	keyPropID := b.addProperty("Key", "text")
+	urlPropID := b.addProperty("Original URL", "url")
+	createdPropID := b.addProperty("Created date", "date")
+	duePropID := b.addProperty("Due date", "date")
+	var fixVersionsPropID, componentsPropID string
+	customFields := map[string]string{}
+
+	// issues are ordered by rank if they have one
+	ranks := map[string]string{}
+	for _, item := range items {
+		for _, field := range item.CustomFields {
+			if field.Key == jiraRankKey && len(field.Values) > 0 {
+				ranks[item.Key] = field.Values[0]
+			}
+		}
+	}
+	if len(ranks) > 0 {
+		sort.SliceStable(items, func(i, j int) bool { return ranks[items[i].Key] < ranks[items[j].Key] })
+	}
+
+	cardOrder := make([]string, 0, len(items))
+	for _, item := range items {
+		card := b.addCard(strings.TrimSpace(item.Summary), parseMillis(item.Created, time.RFC1123Z))
+		cardOrder = append(cardOrder, card.ID)
+
+		for _, field := range []struct{ propID, value string }{
+			{statusPropID, item.Status},
+			{priorityPropID, item.Priority},
+			{typePropID, item.Type},
+			{resolutionPropID, item.Resolution},
+		} {
+			if value := strings.TrimSpace(field.value); value != "" {
+				setProperty(card, field.propID, b.addOption(field.propID, value))
+			}
+		}
+		setProperty(card, labelsPropID, jiraOptions(b, labelsPropID, item.Labels))
+		setProperty(card, assigneePropID, item.Assignee.id())
+		setProperty(card, reporterPropID, item.Reporter.id())
+		setProperty(card, keyPropID, item.Key)
+		setProperty(card, urlPropID, item.Link)
+		setProperty(card, createdPropID, dateValue(parseMillis(item.Created, time.RFC1123Z), 0))
+		setProperty(card, duePropID, dateValue(jiraDay(item.Due), 0))
+
+		if len(item.FixVersions) > 0 && fixVersionsPropID == "" {
+			fixVersionsPropID = b.addProperty("Fix versions", "multiSelect")
+		}
+		setProperty(card, fixVersionsPropID, jiraOptions(b, fixVersionsPropID, item.FixVersions))
+		if len(item.Components) > 0 && componentsPropID == "" {
+			componentsPropID = b.addProperty("Components", "multiSelect")
+		}
+		setProperty(card, componentsPropID, jiraOptions(b, componentsPropID, item.Components))
+
+		for _, field := range item.CustomFields {
+			if field.Key == jiraRankKey || len(field.Values) == 0 {
+				continue
+			}
+			fieldType := field.Key[strings.LastIndex(field.Key, ":")+1:]
+			propType, ok := jiraCustomFieldTypes[fieldType]
+			if !ok {
+				result.unmapped("Custom field " + field.Name)
+				continue
+			}
+			propID, ok := customFields[field.ID]
+			if !ok {
+				propID = b.addProperty(field.Name, propType)
+				customFields[field.ID] = propID
+			}
+			switch propType {
+			case "date":
+				setProperty(card, propID, dateValue(jiraDay(field.Values[0]), 0))
+			case "select":
+				setProperty(card, propID, b.addOption(propID, strings.TrimSpace(field.Values[0])))
+			case "multiSelect":
+				setProperty(card, propID, jiraOptions(b, propID, field.Values))
+			default:
+				setProperty(card, propID, htmlToMarkdown(field.Values[0]))
+			}
+		}
+
+		if description := htmlToMarkdown(item.Description); description != "" {
+			b.addContent(card, model.TypeText, description, nil)
+		}
+		for _, comment := range item.Comments {
+			text := htmlToMarkdown(comment.Text)
+			if member, ok := result.Members[comment.Author]; ok {
+				text = member.DisplayName() + ": " + text
+			}
+			block := b.addContent(card, model.TypeComment, text, nil)
+			if createAt := parseMillis(comment.Created, time.RFC1123Z); createAt != 0 {
+				block.CreateAt = createAt
+			}
+		}
+
+		if strings.TrimSpace(item.Environment) != "" {
+			result.unmapped("Environment")
+		}
+		if len(item.Attachments) > 0 {
+			result.unmapped("Attachments")
+		}
+		if item.Parent != "" || len(item.Subtasks) > 0 {
+			result.unmapped("Sub-tasks")
+		}
+		if len(item.IssueLinks) > 0 {
+			result.unmapped("Issue links")
+		}
+		if item.TimeOriginalEstimate != "" || item.TimeSpent != "" {
+			result.unmapped("Time tracking")
+		}
+		if item.Votes > 0 {
+			result.unmapped("Votes")
+		}
+	}
+
+	b.addView("Board view", statusPropID, cardOrder)
+	b.addTo(result)
+}
+
+// jiraOptions returns the ids of the options of a multi select property
+// with the values, adding the missing options.
+func jiraOptions(b *boardBuilder, propID string, values []string) []interface{} {
+	ids := []interface{}{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			ids = append(ids, b.addOption(propID, value))
+		}
+	}
+	return ids
+}
+
+// jiraDay parses a date of a Jira export, which are midnight times, to
+// the value of a date property.
+func jiraDay(s string) int64 {
+	t, err := time.Parse(time.RFC1123Z, strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	return parseDay(t.Format("2006-01-02"))
+}
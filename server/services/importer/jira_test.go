@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+const jiraExport = `<!--
+RSS generated by JIRA
+-->
+<rss version="0.92">
+	<channel>
+		<title>Jira</title>
+		<item>
+			<title>[AR-9] Investigate feature area</title>
+			<link>https://areca.atlassian.net/browse/AR-9</link>
+			<project id="10000" key="AR">Areca</project>
+			<description>&lt;p&gt;Look at &lt;b&gt;everything&lt;/b&gt;&lt;/p&gt;</description>
+			<environment></environment>
+			<key id="10008">AR-9</key>
+			<summary>Investigate feature area</summary>
+			<type id="10001">Task</type>
+			<priority id="3">Medium</priority>
+			<status id="10001">In Progress</status>
+			<resolution id="-1">Unresolved</resolution>
+			<assignee accountid="-1">Unassigned</assignee>
+			<reporter accountid="557058:10df">Chen Lim</reporter>
+			<labels></labels>
+			<created>Fri, 24 Sep 2021 14:22:15 -0700</created>
+			<due></due>
+			<votes>0</votes>
+			<attachments>
+				<attachment id="1" name="spec.pdf"/>
+			</attachments>
+			<customfields>
+				<customfield id="customfield_10019" key="com.pyxis.greenhopper.jira:gh-lexo-rank">
+					<customfieldname>Rank</customfieldname>
+					<customfieldvalues>
+						<customfieldvalue>0|i0001r:</customfieldvalue>
+					</customfieldvalues>
+				</customfield>
+				<customfield id="customfield_10017" key="com.pyxis.greenhopper.jira:jsw-issue-color">
+					<customfieldname>Issue color</customfieldname>
+					<customfieldvalues>
+						<customfieldvalue>blue</customfieldvalue>
+					</customfieldvalues>
+				</customfield>
+			</customfields>
+		</item>
+		<item>
+			<title>[AR-1] Investigate feature</title>
+			<link>https://areca.atlassian.net/browse/AR-1</link>
+			<project id="10000" key="AR">Areca</project>
+			<description></description>
+			<key id="10000">AR-1</key>
+			<summary>Investigate feature</summary>
+			<type id="10002">Epic</type>
+			<priority id="3">Medium</priority>
+			<status id="10000">To Do</status>
+			<resolution id="-1">Unresolved</resolution>
+			<assignee accountid="557058:10df">Chen Lim</assignee>
+			<reporter accountid="557058:10df">Chen Lim</reporter>
+			<labels>
+				<label>PM</label>
+			</labels>
+			<created>Fri, 24 Sep 2021 14:19:44 -0700</created>
+			<due>Fri, 12 Nov 2021 00:00:00 +0000</due>
+			<comments>
+				<comment id="10000" author="557058:10df" created="Fri, 24 Sep 2021 14:21:15 -0700">&lt;p&gt;Kicking off Project Areca&amp;#33;&lt;/p&gt;</comment>
+			</comments>
+			<customfields>
+				<customfield id="customfield_10019" key="com.pyxis.greenhopper.jira:gh-lexo-rank">
+					<customfieldname>Rank</customfieldname>
+					<customfieldvalues>
+						<customfieldvalue>0|hzzzzz:</customfieldvalue>
+					</customfieldvalues>
+				</customfield>
+				<customfield id="customfield_10015" key="com.atlassian.jira.plugin.system.customfieldtypes:datepicker">
+					<customfieldname>Start date</customfieldname>
+					<customfieldvalues>
+						<customfieldvalue>Tue, 28 Sep 2021 00:00:00 +0000</customfieldvalue>
+					</customfieldvalues>
+				</customfield>
+			</customfields>
+		</item>
+	</channel>
+</rss>`
+
+func TestJiraImporter(t *testing.T) {
+	result, err := jiraImporter{}.Convert(strings.NewReader(jiraExport))
+	require.NoError(t, err)
+
+	require.Len(t, result.Boards, 1)
+	board := result.Boards[0]
+	require.Equal(t, "Areca", board.Title)
+
+	status := findProperty(t, board, "Status")
+	labels := findProperty(t, board, "Labels")
+	assignee := findProperty(t, board, "Assignee")
+	reporter := findProperty(t, board, "Reporter")
+	key := findProperty(t, board, "Key")
+	due := findProperty(t, board, "Due date")
+	start := findProperty(t, board, "Start date")
+	require.Equal(t, "person", assignee.Type)
+	require.Equal(t, "date", start.Type)
+
+	// cards are ordered by rank
+	cards := findBlocks(result.Blocks, model.TypeCard, "")
+	require.Len(t, cards, 2)
+	epic := cards[0]
+	require.Equal(t, "Investigate feature", epic.Title)
+	require.Equal(t, int64(1632518384000), epic.CreateAt)
+
+	props := cardProperties(epic)
+	require.Equal(t, optionID(t, status, "To Do"), props[status.ID])
+	require.Equal(t, []interface{}{optionID(t, labels, "PM")}, props[labels.ID])
+	require.Equal(t, "557058:10df", props[assignee.ID])
+	require.Equal(t, "AR-1", props[key.ID])
+	require.Equal(t, `{"from":1636718400000}`, props[due.ID])
+	require.Equal(t, `{"from":1632830400000}`, props[start.ID])
+
+	comments := findBlocks(result.Blocks, model.TypeComment, epic.ID)
+	require.Len(t, comments, 1)
+	require.Equal(t, "Chen Lim: Kicking off Project Areca!", comments[0].Title)
+
+	task := cards[1]
+	props = cardProperties(task)
+	require.NotContains(t, props, assignee.ID)
+	require.Equal(t, "557058:10df", props[reporter.ID])
+	text := findBlocks(result.Blocks, model.TypeText, task.ID)
+	require.Len(t, text, 1)
+	require.Equal(t, "Look at **everything**", text[0].Title)
+
+	views := findBlocks(result.Blocks, model.TypeView, board.ID)
+	require.Len(t, views, 1)
+	require.Equal(t, status.ID, views[0].Fields["groupById"])
+
+	require.Equal(t, map[string]Member{"557058:10df": {ID: "557058:10df", FullName: "Chen Lim"}}, result.Members)
+	require.Equal(t, map[string]int{"Attachments": 1, "Custom field Issue color": 1}, result.Unmapped)
+}
+
+func TestJiraImporterInvalidExport(t *testing.T) {
+	_, err := jiraImporter{}.Convert(strings.NewReader(`{"name": "board"}`))
+	require.True(t, model.IsErrBadRequest(err))
+
+	_, err = jiraImporter{}.Convert(strings.NewReader(`<rss><channel></channel></rss>`))
+	require.True(t, model.IsErrBadRequest(err))
+}
@@ -0,0 +1,317 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// trelloBoard is the JSON export of a Trello board, limited to the
+// fields that are imported or reported.
+type trelloBoard struct {
+	Name         string              `json:"name"`
+	Desc         string              `json:"desc"`
+	Lists        []trelloList        `json:"lists"`
+	Cards        []trelloCard        `json:"cards"`
+	Labels       []trelloLabel       `json:"labels"`
+	Members      []trelloMember      `json:"members"`
+	Checklists   []trelloChecklist   `json:"checklists"`
+	CustomFields []trelloCustomField `json:"customFields"`
+	Actions      []trelloAction      `json:"actions"`
+}
+
+type trelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type trelloCard struct {
+	ID               string                  `json:"id"`
+	Name             string                  `json:"name"`
+	Desc             string                  `json:"desc"`
+	Closed           bool                    `json:"closed"`
+	Pos              float64                 `json:"pos"`
+	IDList           string                  `json:"idList"`
+	IDLabels         []string                `json:"idLabels"`
+	IDMembers        []string                `json:"idMembers"`
+	IDMembersVoted   []string                `json:"idMembersVoted"`
+	IDChecklists     []string                `json:"idChecklists"`
+	Start            string                  `json:"start"`
+	Due              string                  `json:"due"`
+	DueComplete      bool                    `json:"dueComplete"`
+	DateLastActivity string                  `json:"dateLastActivity"`
+	URL              string                  `json:"url"`
+	Attachments      []json.RawMessage       `json:"attachments"`
+	CustomFieldItems []trelloCustomFieldItem `json:"customFieldItems"`
+}
+
+type trelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type trelloMember struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+}
+
+type trelloChecklist struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Pos        float64           `json:"pos"`
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+type trelloCheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+type trelloCustomField struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Options []struct {
+		ID    string `json:"id"`
+		Value struct {
+			Text string `json:"text"`
+		} `json:"value"`
+	} `json:"options"`
+}
+
+type trelloCustomFieldItem struct {
+	IDCustomField string            `json:"idCustomField"`
+	IDValue       string            `json:"idValue"`
+	Value         map[string]string `json:"value"`
+}
+
+type trelloAction struct {
+	Type string `json:"type"`
+	Date string `json:"date"`
+	Data struct {
+		Text string `json:"text"`
+		Card struct {
+			ID string `json:"id"`
+		} `json:"card"`
+	} `json:"data"`
+	MemberCreator trelloMember `json:"memberCreator"`
+}
+
+// trelloCustomFieldTypes maps the types of Trello custom fields to the
+// types of card properties.
+var trelloCustomFieldTypes = map[string]string{
+	"text":     "text",
+	"number":   "number",
+	"checkbox": "checkbox",
+	"date":     "date",
+	"list":     "select",
+}
+
+// trelloImporter converts the JSON export of a Trello board. Lists are
+// imported as the options of a List property the board view is grouped
+// by, and closed cards are archived.
+type trelloImporter struct{}
+
+func (trelloImporter) Name() string {
+	return "trello"
+}
+
+func (trelloImporter) Convert(r io.Reader) (*Result, error) {
+	var input trelloBoard
+	if err := json.NewDecoder(io.LimitReader(r, maxExportSize)).Decode(&input); err != nil {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("invalid Trello export: %s", err))
+	}
+	if input.Lists == nil && input.Cards == nil {
+		return nil, model.NewErrBadRequest("invalid Trello export: no lists or cards")
+	}
+
+	result := newResult()
+	b := newBoardBuilder(input.Name, input.Desc)
+
+	sort.SliceStable(input.Lists, func(i, j int) bool { return input.Lists[i].Pos < input.Lists[j].Pos })
+	listPropID := b.addProperty("List", "select")
+	listOptions := map[string]string{}
+	listPos := map[string]int{}
+	for i, list := range input.Lists {
+		listOptions[list.ID] = b.addOption(listPropID, list.Name)
+		listPos[list.ID] = i
+	}
+
+	var labelsPropID, membersPropID, duePropID, dueCompletePropID string
+	labelOptions := map[string]string{}
+	if len(input.Labels) > 0 {
+		labelsPropID = b.addProperty("Labels", "multiSelect")
+		for _, label := range input.Labels {
+			name := label.Name
+			if name == "" {
+				name = label.Color
+			}
+			labelOptions[label.ID] = b.addOption(labelsPropID, name)
+		}
+	}
+	for _, card := range input.Cards {
+		if len(card.IDMembers) > 0 && membersPropID == "" {
+			membersPropID = b.addProperty("Members", "multiPerson")
+		}
+		if (card.Due != "" || card.Start != "") && duePropID == "" {
+			duePropID = b.addProperty("Due date", "date")
+			dueCompletePropID = b.addProperty("Due complete", "checkbox")
+		}
+	}
+	members := map[string]trelloMember{}
+	for _, member := range input.Members {
+		members[member.ID] = member
+	}
+
+	customFields := map[string]string{}
+	customOptions := map[string]string{}
+	for _, field := range input.CustomFields {
+		propType, ok := trelloCustomFieldTypes[field.Type]
+		if !ok {
+			result.unmapped("Custom field " + field.Name)
+			continue
+		}
+		customFields[field.ID] = b.addProperty(field.Name, propType)
+		for _, option := range field.Options {
+			customOptions[option.ID] = b.addOption(customFields[field.ID], option.Value.Text)
+		}
+	}
+	urlPropID := b.addProperty("Original URL", "url")
+
+	checklists := map[string]trelloChecklist{}
+	for _, checklist := range input.Checklists {
+		checklists[checklist.ID] = checklist
+	}
+	comments := map[string][]trelloAction{}
+	// actions are listed from the most recent
+	for i := len(input.Actions) - 1; i >= 0; i-- {
+		action := input.Actions[i]
+		if action.Type == "commentCard" {
+			comments[action.Data.Card.ID] = append(comments[action.Data.Card.ID], action)
+		}
+	}
+
+	sort.SliceStable(input.Cards, func(i, j int) bool {
+		ci, cj := input.Cards[i], input.Cards[j]
+		if listPos[ci.IDList] != listPos[cj.IDList] {
+			return listPos[ci.IDList] < listPos[cj.IDList]
+		}
+		return ci.Pos < cj.Pos
+	})
+	cardOrder := make([]string, 0, len(input.Cards))
+	for _, tc := range input.Cards {
+		card := b.addCard(tc.Name, trelloIDMillis(tc.ID))
+		cardOrder = append(cardOrder, card.ID)
+
+		setProperty(card, listPropID, listOptions[tc.IDList])
+		labels := []interface{}{}
+		for _, id := range tc.IDLabels {
+			if optionID, ok := labelOptions[id]; ok {
+				labels = append(labels, optionID)
+			}
+		}
+		setProperty(card, labelsPropID, labels)
+
+		assignees := []interface{}{}
+		for _, id := range tc.IDMembers {
+			member := members[id]
+			result.addMember(Member{ID: id, Username: member.Username, FullName: member.FullName})
+			assignees = append(assignees, id)
+		}
+		setProperty(card, membersPropID, assignees)
+
+		if tc.Due != "" || tc.Start != "" {
+			setProperty(card, duePropID, dateValue(parseMillis(tc.Start, time.RFC3339), parseMillis(tc.Due, time.RFC3339)))
+			if tc.DueComplete {
+				setProperty(card, dueCompletePropID, "true")
+			}
+		}
+		setProperty(card, urlPropID, tc.URL)
+
+		for _, item := range tc.CustomFieldItems {
+			propID, ok := customFields[item.IDCustomField]
+			if !ok {
+				continue
+			}
+			switch {
+			case item.IDValue != "":
+				setProperty(card, propID, customOptions[item.IDValue])
+			case item.Value["text"] != "":
+				setProperty(card, propID, item.Value["text"])
+			case item.Value["number"] != "":
+				setProperty(card, propID, item.Value["number"])
+			case item.Value["checked"] == "true":
+				setProperty(card, propID, "true")
+			case item.Value["date"] != "":
+				setProperty(card, propID, dateValue(parseMillis(item.Value["date"], time.RFC3339), 0))
+			}
+		}
+
+		if tc.Desc != "" {
+			b.addContent(card, model.TypeText, tc.Desc, nil)
+		}
+		for _, id := range tc.IDChecklists {
+			checklist, ok := checklists[id]
+			if !ok {
+				continue
+			}
+			if len(tc.IDChecklists) > 1 {
+				b.addContent(card, model.TypeText, "### "+checklist.Name, nil)
+			}
+			sort.SliceStable(checklist.CheckItems, func(i, j int) bool { return checklist.CheckItems[i].Pos < checklist.CheckItems[j].Pos })
+			for _, item := range checklist.CheckItems {
+				b.addContent(card, model.TypeCheckbox, item.Name, map[string]interface{}{"value": item.State == "complete"})
+			}
+		}
+		for _, comment := range comments[tc.ID] {
+			block := b.addContent(card, model.TypeComment, trelloCommentText(comment), nil)
+			if createAt := parseMillis(comment.Date, time.RFC3339); createAt != 0 {
+				block.CreateAt = createAt
+			}
+		}
+
+		if len(tc.Attachments) > 0 {
+			result.unmapped("Attachments")
+		}
+		if len(tc.IDMembersVoted) > 0 {
+			result.unmapped("Votes")
+		}
+		if tc.Closed {
+			archive(card, parseMillis(tc.DateLastActivity, time.RFC3339))
+		}
+	}
+
+	b.addView("Board view", listPropID, cardOrder)
+	b.addTo(result)
+	return result, nil
+}
+
+// trelloIDMillis returns the creation time of a Trello object, which
+// starts its id.
+func trelloIDMillis(id string) int64 {
+	if len(id) < 8 {
+		return 0
+	}
+	seconds, err := strconv.ParseInt(id[:8], 16, 64)
+	if err != nil {
+		return 0
+	}
+	return seconds * 1000
+}
+
+func trelloCommentText(comment trelloAction) string {
+	if comment.MemberCreator.FullName == "" {
+		return comment.Data.Text
+	}
+	return comment.MemberCreator.FullName + ": " + comment.Data.Text
+}
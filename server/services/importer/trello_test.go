@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+const trelloExport = `{
+	"name": "Roadmap",
+	"desc": "Product roadmap",
+	"lists": [
+		{"id": "list-done", "name": "Done", "pos": 2},
+		{"id": "list-todo", "name": "To Do", "pos": 1}
+	],
+	"labels": [
+		{"id": "label-1", "name": "Bug", "color": "red"},
+		{"id": "label-2", "name": "", "color": "green"}
+	],
+	"members": [
+		{"id": "member-1", "username": "alice", "fullName": "Alice Doe"}
+	],
+	"customFields": [
+		{"id": "field-1", "name": "Estimate", "type": "number"},
+		{"id": "field-2", "name": "Size", "type": "list", "options": [{"id": "size-s", "value": {"text": "S"}}]},
+		{"id": "field-3", "name": "Location", "type": "map"}
+	],
+	"checklists": [
+		{"id": "checklist-1", "name": "Steps", "checkItems": [
+			{"name": "second", "state": "incomplete", "pos": 2},
+			{"name": "first", "state": "complete", "pos": 1}
+		]}
+	],
+	"actions": [
+		{"type": "commentCard", "date": "2021-10-02T10:00:00.000Z", "data": {"text": "Newer", "card": {"id": "5f7b5e1a0000000000000001"}}, "memberCreator": {"fullName": "Alice Doe"}},
+		{"type": "commentCard", "date": "2021-10-01T10:00:00.000Z", "data": {"text": "Older", "card": {"id": "5f7b5e1a0000000000000001"}}, "memberCreator": {"fullName": "Alice Doe"}},
+		{"type": "updateCard", "date": "2021-10-01T09:00:00.000Z", "data": {"card": {"id": "5f7b5e1a0000000000000001"}}}
+	],
+	"cards": [
+		{
+			"id": "5f7b5e1a0000000000000002",
+			"name": "Shipped",
+			"idList": "list-done",
+			"closed": true,
+			"dateLastActivity": "2021-10-05T00:00:00.000Z",
+			"pos": 1,
+			"attachments": [{"name": "spec.pdf"}]
+		},
+		{
+			"id": "5f7b5e1a0000000000000001",
+			"name": "Plan",
+			"desc": "Write the plan",
+			"idList": "list-todo",
+			"pos": 1,
+			"idLabels": ["label-1", "label-2"],
+			"idMembers": ["member-1", "member-2"],
+			"idChecklists": ["checklist-1"],
+			"due": "2021-10-10T12:00:00.000Z",
+			"dueComplete": true,
+			"url": "https://trello.com/c/abc",
+			"customFieldItems": [
+				{"idCustomField": "field-1", "value": {"number": "3"}},
+				{"idCustomField": "field-2", "idValue": "size-s"}
+			]
+		}
+	]
+}`
+
+func TestTrelloImporter(t *testing.T) {
+	result, err := trelloImporter{}.Convert(strings.NewReader(trelloExport))
+	require.NoError(t, err)
+
+	require.Len(t, result.Boards, 1)
+	board := result.Boards[0]
+	require.Equal(t, "Roadmap", board.Title)
+	require.Equal(t, "Product roadmap", board.Description)
+
+	list := findProperty(t, board, "List")
+	require.Equal(t, "select", list.Type)
+	require.Len(t, list.Options, 2)
+	require.Equal(t, 0, list.Options[optionID(t, list, "To Do")].Index)
+	labels := findProperty(t, board, "Labels")
+	bug, green := optionID(t, labels, "Bug"), optionID(t, labels, "green")
+	members := findProperty(t, board, "Members")
+	estimate := findProperty(t, board, "Estimate")
+	size := findProperty(t, board, "Size")
+	due := findProperty(t, board, "Due date")
+	dueComplete := findProperty(t, board, "Due complete")
+
+	cards := findBlocks(result.Blocks, model.TypeCard, "")
+	require.Len(t, cards, 2)
+	plan := cards[0]
+	require.Equal(t, "Plan", plan.Title)
+	require.Equal(t, int64(0x5f7b5e1a*1000), plan.CreateAt)
+
+	props := cardProperties(plan)
+	require.Equal(t, optionID(t, list, "To Do"), props[list.ID])
+	require.Equal(t, []interface{}{bug, green}, props[labels.ID])
+	require.Equal(t, []interface{}{"member-1", "member-2"}, props[members.ID])
+	require.Equal(t, "3", props[estimate.ID])
+	require.Equal(t, optionID(t, size, "S"), props[size.ID])
+	require.Equal(t, `{"from":1633867200000}`, props[due.ID])
+	require.Equal(t, "true", props[dueComplete.ID])
+	require.NotContains(t, plan.Fields, model.CardArchivedAtField)
+
+	content := findBlocks(result.Blocks, model.TypeCheckbox, plan.ID)
+	require.Len(t, content, 2)
+	require.Equal(t, "first", content[0].Title)
+	require.Equal(t, true, content[0].Fields["value"])
+	require.Len(t, plan.Fields["contentOrder"], 3)
+
+	comments := findBlocks(result.Blocks, model.TypeComment, plan.ID)
+	require.Len(t, comments, 2)
+	require.Equal(t, "Alice Doe: Older", comments[0].Title)
+
+	shipped := cards[1]
+	require.Equal(t, "Shipped", shipped.Title)
+	require.Equal(t, int64(1633392000000), shipped.Fields[model.CardArchivedAtField])
+
+	views := findBlocks(result.Blocks, model.TypeView, board.ID)
+	require.Len(t, views, 1)
+	require.Equal(t, list.ID, views[0].Fields["groupById"])
+	require.Equal(t, []interface{}{plan.ID, shipped.ID}, views[0].Fields["cardOrder"])
+
+	require.Equal(t, Member{ID: "member-1", Username: "alice", FullName: "Alice Doe"}, result.Members["member-1"])
+	require.Contains(t, result.Members, "member-2")
+	require.Equal(t, map[string]int{"Attachments": 1, "Custom field Location": 1}, result.Unmapped)
+
+	for _, block := range result.Blocks {
+		require.Equal(t, board.ID, block.BoardID)
+		require.NoError(t, block.IsValid())
+	}
+}
+
+func TestTrelloImporterInvalidExport(t *testing.T) {
+	_, err := trelloImporter{}.Convert(strings.NewReader("<rss></rss>"))
+	require.True(t, model.IsErrBadRequest(err))
+
+	_, err = trelloImporter{}.Convert(strings.NewReader(`{"data": []}`))
+	require.True(t, model.IsErrBadRequest(err))
+}
@@ -13,6 +13,7 @@ const (
 	MetricsSubsystemBoards = "boards"
 	MetricsSubsystemTeams  = "teams"
 	MetricsSubsystemSystem = "system"
+	MetricsSubsystemWS     = "websocket"
 
 	MetricsCloudInstallationLabel = "installationId"
 )
@@ -44,6 +45,11 @@ type Metrics struct {
 	teamCount  prometheus.Gauge
 
 	blockLastActivity prometheus.Gauge
+
+	wsQueueDepth      prometheus.Gauge
+	wsDroppedCount    prometheus.Counter
+	wsSlowConsumers   *prometheus.CounterVec
+	wsDeadConnections prometheus.Counter
 }
 
 // NewMetrics Factory method to create a new metrics collector.
@@ -172,6 +178,42 @@ func NewMetrics(info InstanceInfo) *Metrics {
 	})
 	m.registry.MustRegister(m.blockLastActivity)
 
+	m.wsQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemWS,
+		Name:        "send_queue_depth",
+		Help:        "Number of messages waiting in the send queues of the websocket connections.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.wsQueueDepth)
+
+	m.wsDroppedCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemWS,
+		Name:        "messages_dropped_total",
+		Help:        "Total number of messages dropped for websocket connections that fell behind.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.wsDroppedCount)
+
+	m.wsSlowConsumers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemWS,
+		Name:        "slow_consumers_total",
+		Help:        "Total number of times a websocket connection fell behind, by the action taken.",
+		ConstLabels: additionalLabels,
+	}, []string{"Policy"})
+	m.registry.MustRegister(m.wsSlowConsumers)
+
+	m.wsDeadConnections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemWS,
+		Name:        "dead_connections_total",
+		Help:        "Total number of websocket connections closed for not answering pings.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.wsDeadConnections)
+
 	return m
 }
 
@@ -231,3 +273,27 @@ func (m *Metrics) ObserveTeamCount(count int64) {
 		m.teamCount.Set(float64(count))
 	}
 }
+
+func (m *Metrics) AddWebsocketQueueDepth(num int) {
+	if m != nil {
+		m.wsQueueDepth.Add(float64(num))
+	}
+}
+
+func (m *Metrics) IncrementWebsocketDroppedMessages(num int) {
+	if m != nil {
+		m.wsDroppedCount.Add(float64(num))
+	}
+}
+
+func (m *Metrics) IncrementWebsocketSlowConsumers(policy string) {
+	if m != nil {
+		m.wsSlowConsumers.WithLabelValues(policy).Inc()
+	}
+}
+
+func (m *Metrics) IncrementWebsocketDeadConnections(num int) {
+	if m != nil {
+		m.wsDeadConnections.Add(float64(num))
+	}
+}
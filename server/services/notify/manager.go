@@ -18,6 +18,7 @@ type NotificationStore interface {
 	GetUserByUsername(username string) (*model.User, error)
 	GetTelegramNotificationPreferences(userID string) (map[string]bool, error)
 	GetMembersForBoard(boardID string) ([]*model.BoardMember, error)
+	GetBlockHistory(blockID string, opts model.QueryBlockHistoryOptions) ([]*model.Block, error)
 }
 
 func NewNotificationManager(telegramBotToken string, store NotificationStore, logger mlog.LoggerIFace) *NotificationManager {
@@ -0,0 +1,13 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package notifyinbox
+
+import "github.com/mattermost/focalboard/server/model"
+
+type AppAPI interface {
+	GetUserByUsername(username string) (*model.User, error)
+	GetBlockHistory(blockID string, opts model.QueryBlockHistoryOptions) ([]*model.Block, error)
+	GetSubscribersForBlock(blockID string) ([]*model.Subscriber, error)
+	AddInboxItems(items []*model.InboxItem) error
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package notifyinbox
+
+import (
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/notify/notifymentions"
+	"github.com/mattermost/focalboard/server/services/permissions"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	backendName = "notifyInbox"
+
+	// maxMessageLength is the maximum number of characters of the changed
+	// text that is kept in an inbox item.
+	maxMessageLength = 300
+)
+
+type BackendParams struct {
+	AppAPI      AppAPI
+	Permissions permissions.PermissionsService
+	Logger      mlog.LoggerIFace
+}
+
+// Backend provides the notification backend that fills the in-app inbox of
+// the users concerned by a change.
+type Backend struct {
+	appAPI      AppAPI
+	permissions permissions.PermissionsService
+	logger      mlog.LoggerIFace
+}
+
+func New(params BackendParams) *Backend {
+	return &Backend{
+		appAPI:      params.AppAPI,
+		permissions: params.Permissions,
+		logger:      params.Logger,
+	}
+}
+
+func (b *Backend) Start() error {
+	return nil
+}
+
+func (b *Backend) ShutDown() error {
+	return nil
+}
+
+func (b *Backend) Name() string {
+	return backendName
+}
+
+func (b *Backend) BlockChanged(evt notify.BlockChangeEvent) error {
+	if evt.Board == nil || evt.Card == nil || evt.BlockChanged == nil || evt.ModifiedBy == nil {
+		return nil
+	}
+
+	if evt.Action == notify.Delete {
+		return nil
+	}
+
+	// a user gets a single item per change, the first reason found wins
+	items := map[string]*model.InboxItem{}
+	addItem := func(userID string, itemType model.InboxItemType) {
+		if userID == evt.ModifiedBy.UserID {
+			return
+		}
+		if _, exists := items[userID]; exists {
+			return
+		}
+		if !b.permissions.HasPermissionToBoard(userID, evt.Board.ID, model.PermissionViewBoard) {
+			return
+		}
+		items[userID] = b.newItem(userID, itemType, evt)
+	}
+
+	mentionedUserIDs, err := b.newMentions(evt)
+	if err != nil {
+		return fmt.Errorf("cannot get mentions for block %s: %w", evt.BlockChanged.ID, err)
+	}
+	for _, userID := range mentionedUserIDs {
+		addItem(userID, model.InboxItemMention)
+	}
+
+	if evt.BlockChanged.Type == model.TypeCard {
+		for _, userID := range newAssignees(evt) {
+			addItem(userID, model.InboxItemAssigned)
+		}
+	}
+
+	if evt.BlockChanged.Type == model.TypeComment && evt.Action == notify.Add {
+		subscribers, err := b.appAPI.GetSubscribersForBlock(evt.Card.ID)
+		if err != nil {
+			return fmt.Errorf("cannot get subscribers for card %s: %w", evt.Card.ID, err)
+		}
+		for _, subscriber := range subscribers {
+			if subscriber.SubscriberType == model.SubTypeUser {
+				addItem(subscriber.SubscriberID, model.InboxItemComment)
+			}
+		}
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	list := make([]*model.InboxItem, 0, len(items))
+	for _, item := range items {
+		list = append(list, item)
+	}
+	return b.appAPI.AddInboxItems(list)
+}
+
+func (b *Backend) newItem(userID string, itemType model.InboxItemType, evt notify.BlockChangeEvent) *model.InboxItem {
+	item := &model.InboxItem{
+		UserID:    userID,
+		Type:      itemType,
+		TeamID:    evt.TeamID,
+		BoardID:   evt.Board.ID,
+		CardID:    evt.Card.ID,
+		ActorID:   evt.ModifiedBy.UserID,
+		CardTitle: evt.Card.Title,
+	}
+	if evt.BlockChanged.ID != evt.Card.ID {
+		item.BlockID = evt.BlockChanged.ID
+		item.Message = truncate(evt.BlockChanged.Title, maxMessageLength)
+	}
+	return item
+}
+
+// newMentions returns the ids of the users mentioned in the changed block that were
+// not mentioned in its previous version, so that edits don't notify again. When the
+// title of a text or comment is edited, the version before it is checked too.
+func (b *Backend) newMentions(evt notify.BlockChangeEvent) ([]string, error) {
+	switch evt.BlockChanged.Type {
+	case model.TypeText, model.TypeComment, model.TypeImage:
+	default:
+		return nil, nil
+	}
+
+	mentions := notifymentions.ExtractMentions(evt.BlockChanged)
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	previous := []*model.Block{evt.BlockOld}
+	if evt.Action == notify.Update && evt.BlockOld != nil && evt.BlockOld.Title != evt.BlockChanged.Title &&
+		evt.BlockChanged.Type != model.TypeImage {
+		history, err := b.appAPI.GetBlockHistory(evt.BlockChanged.ID, model.QueryBlockHistoryOptions{
+			BeforeUpdateAt: evt.BlockOld.UpdateAt,
+			Limit:          1,
+			Descending:     true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, history...)
+	}
+	for _, block := range previous {
+		for username := range notifymentions.ExtractMentions(block) {
+			delete(mentions, username)
+		}
+	}
+
+	userIDs := []string{}
+	for username := range mentions {
+		user, err := b.appAPI.GetUserByUsername(username)
+		if model.IsErrNotFound(err) {
+			// not really an error; could just be someone typed "@sometext"
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+	return userIDs, nil
+}
+
+// newAssignees returns the ids of the users that were added to a person property
+// of the changed card.
+func newAssignees(evt notify.BlockChangeEvent) []string {
+	schema, err := model.ParsePropertySchema(evt.Board)
+	if err != nil {
+		return nil
+	}
+
+	oldAssignees := map[string]struct{}{}
+	if evt.Action == notify.Update {
+		oldAssignees = model.GetCardAssignees(evt.BlockOld, schema)
+	}
+
+	userIDs := []string{}
+	for userID := range model.GetCardAssignees(evt.BlockChanged, schema) {
+		if _, exists := oldAssignees[userID]; !exists {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs
+}
+
+func truncate(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+	return string(runes[:maxLength]) + "…"
+}
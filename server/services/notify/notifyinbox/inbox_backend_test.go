@@ -0,0 +1,181 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package notifyinbox
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/permissions"
+	"github.com/stretchr/testify/require"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+type fakeAppAPI struct {
+	users       map[string]*model.User
+	history     []*model.Block
+	subscribers []*model.Subscriber
+	items       []*model.InboxItem
+}
+
+func (a *fakeAppAPI) GetUserByUsername(username string) (*model.User, error) {
+	user, ok := a.users[username]
+	if !ok {
+		return nil, model.NewErrNotFound(username)
+	}
+	return user, nil
+}
+
+func (a *fakeAppAPI) GetBlockHistory(blockID string, opts model.QueryBlockHistoryOptions) ([]*model.Block, error) {
+	return a.history, nil
+}
+
+func (a *fakeAppAPI) GetSubscribersForBlock(blockID string) ([]*model.Subscriber, error) {
+	return a.subscribers, nil
+}
+
+func (a *fakeAppAPI) AddInboxItems(items []*model.InboxItem) error {
+	a.items = append(a.items, items...)
+	return nil
+}
+
+// fakePermissions allows every user but the ones in denied.
+type fakePermissions struct {
+	permissions.PermissionsService
+	denied map[string]bool
+}
+
+func (p *fakePermissions) HasPermissionToBoard(userID, boardID string, permission *mmModel.Permission) bool {
+	return !p.denied[userID]
+}
+
+func setupBackend(t *testing.T, appAPI *fakeAppAPI, denied ...string) *Backend {
+	perms := &fakePermissions{denied: map[string]bool{}}
+	for _, userID := range denied {
+		perms.denied[userID] = true
+	}
+	return New(BackendParams{
+		AppAPI:      appAPI,
+		Permissions: perms,
+		Logger:      mlog.CreateConsoleTestLogger(t),
+	})
+}
+
+func itemsByUser(items []*model.InboxItem) map[string]model.InboxItemType {
+	result := map[string]model.InboxItemType{}
+	for _, item := range items {
+		result[item.UserID] = item.Type
+	}
+	return result
+}
+
+func TestBlockChanged(t *testing.T) {
+	board := &model.Board{
+		ID:     "board-id",
+		TeamID: "team-id",
+		CardProperties: []map[string]interface{}{
+			{"id": "assignee", "name": "Assignee", "type": "multiPerson"},
+		},
+	}
+	card := &model.Block{
+		ID:      "card-id",
+		BoardID: "board-id",
+		Type:    model.TypeCard,
+		Title:   "Card",
+		Fields:  map[string]interface{}{"properties": map[string]interface{}{}},
+	}
+	actor := &model.BoardMember{UserID: "actor-id"}
+	users := map[string]*model.User{
+		"user1": {ID: "user-id-1"},
+		"user2": {ID: "user-id-2"},
+		"actor": {ID: "actor-id"},
+	}
+
+	t.Run("mentions in a new comment", func(t *testing.T) {
+		appAPI := &fakeAppAPI{users: users}
+		comment := &model.Block{ID: "comment-id", ParentID: "card-id", Type: model.TypeComment, Title: "Hi @user1 @user2 @actor @nobody"}
+		err := setupBackend(t, appAPI, "user-id-2").BlockChanged(notify.BlockChangeEvent{
+			Action: notify.Add, TeamID: "team-id", Board: board, Card: card, BlockChanged: comment, ModifiedBy: actor,
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]model.InboxItemType{"user-id-1": model.InboxItemMention}, itemsByUser(appAPI.items))
+		require.Equal(t, "comment-id", appAPI.items[0].BlockID)
+		require.Equal(t, "Card", appAPI.items[0].CardTitle)
+		require.Equal(t, "actor-id", appAPI.items[0].ActorID)
+	})
+
+	t.Run("edits don't notify users mentioned before", func(t *testing.T) {
+		appAPI := &fakeAppAPI{users: users, history: []*model.Block{
+			{ID: "comment-id", Type: model.TypeComment, Title: "Hi @user1", UpdateAt: 1000},
+		}}
+		oldComment := &model.Block{ID: "comment-id", Type: model.TypeComment, Title: "Hi", UpdateAt: 2000}
+		comment := &model.Block{ID: "comment-id", ParentID: "card-id", Type: model.TypeComment, Title: "Hi @user1 and @user2", UpdateAt: 3000}
+		err := setupBackend(t, appAPI).BlockChanged(notify.BlockChangeEvent{
+			Action: notify.Update, TeamID: "team-id", Board: board, Card: card, BlockChanged: comment, BlockOld: oldComment, ModifiedBy: actor,
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]model.InboxItemType{"user-id-2": model.InboxItemMention}, itemsByUser(appAPI.items))
+	})
+
+	t.Run("assignments", func(t *testing.T) {
+		appAPI := &fakeAppAPI{users: users}
+		oldCard := &model.Block{ID: "card-id", Type: model.TypeCard, Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"assignee": []interface{}{"user-id-1"}},
+		}}
+		newCard := &model.Block{ID: "card-id", BoardID: "board-id", Type: model.TypeCard, Title: "Card", Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"assignee": []interface{}{"user-id-1", "user-id-2", "actor-id"}},
+		}}
+		err := setupBackend(t, appAPI).BlockChanged(notify.BlockChangeEvent{
+			Action: notify.Update, TeamID: "team-id", Board: board, Card: newCard, BlockChanged: newCard, BlockOld: oldCard, ModifiedBy: actor,
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]model.InboxItemType{"user-id-2": model.InboxItemAssigned}, itemsByUser(appAPI.items))
+		require.Empty(t, appAPI.items[0].BlockID)
+	})
+
+	t.Run("comments on subscribed cards", func(t *testing.T) {
+		appAPI := &fakeAppAPI{users: users, subscribers: []*model.Subscriber{
+			{SubscriberType: model.SubTypeUser, SubscriberID: "user-id-1"},
+			{SubscriberType: model.SubTypeUser, SubscriberID: "user-id-2"},
+			{SubscriberType: model.SubTypeUser, SubscriberID: "actor-id"},
+			{SubscriberType: model.SubTypeChannel, SubscriberID: "channel-id"},
+		}}
+		comment := &model.Block{ID: "comment-id", ParentID: "card-id", Type: model.TypeComment, Title: "Hi @user1"}
+		err := setupBackend(t, appAPI).BlockChanged(notify.BlockChangeEvent{
+			Action: notify.Add, TeamID: "team-id", Board: board, Card: card, BlockChanged: comment, ModifiedBy: actor,
+		})
+		require.NoError(t, err)
+
+		userIDs := []string{}
+		for _, item := range appAPI.items {
+			userIDs = append(userIDs, item.UserID)
+		}
+		sort.Strings(userIDs)
+		require.Equal(t, []string{"user-id-1", "user-id-2"}, userIDs)
+		// a mention takes precedence over the subscription
+		require.Equal(t, map[string]model.InboxItemType{
+			"user-id-1": model.InboxItemMention,
+			"user-id-2": model.InboxItemComment,
+		}, itemsByUser(appAPI.items))
+	})
+
+	t.Run("deletes don't create items", func(t *testing.T) {
+		appAPI := &fakeAppAPI{users: users}
+		comment := &model.Block{ID: "comment-id", ParentID: "card-id", Type: model.TypeComment, Title: "Hi @user1"}
+		err := setupBackend(t, appAPI).BlockChanged(notify.BlockChangeEvent{
+			Action: notify.Delete, TeamID: "team-id", Board: board, Card: card, BlockChanged: comment, ModifiedBy: actor,
+		})
+		require.NoError(t, err)
+		require.Empty(t, appAPI.items)
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "hello", truncate("hello", 5))
+	require.Equal(t, "hé…", truncate("héllo", 2))
+}
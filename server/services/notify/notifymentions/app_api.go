@@ -7,4 +7,5 @@ import "github.com/mattermost/focalboard/server/model"
 type AppAPI interface {
 	GetMemberForBoard(boardID, userID string) (*model.BoardMember, error)
 	AddMemberToBoard(member *model.BoardMember) (*model.BoardMember, error)
+	GetBlockHistory(blockID string, opts model.QueryBlockHistoryOptions) ([]*model.Block, error)
 }
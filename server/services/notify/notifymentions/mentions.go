@@ -14,6 +14,11 @@ import (
 
 var atMentionRegexp = regexp.MustCompile(`\B@[[:alnum:]][[:alnum:]\.\-_:]*`)
 
+// ExtractMentions returns the usernames mentioned in the specified block.
+func ExtractMentions(block *model.Block) map[string]struct{} {
+	return extractMentions(block)
+}
+
 // extractMentions extracts any mentions in the specified block and returns
 // a slice of usernames.
 func extractMentions(block *model.Block) map[string]struct{} {
@@ -108,7 +108,10 @@ func (b *Backend) BlockChanged(evt notify.BlockChangeEvent) error {
 		return nil
 	}
 
-	oldMentions := extractMentions(evt.BlockOld)
+	oldMentions, err := b.previousMentions(evt)
+	if err != nil {
+		return fmt.Errorf("cannot get previous mentions for block %s: %w", evt.BlockChanged.ID, err)
+	}
 	merr := merror.New()
 
 	b.mux.RLock()
@@ -150,6 +153,35 @@ func (b *Backend) BlockChanged(evt notify.BlockChangeEvent) error {
 	return merr.ErrorOrNil()
 }
 
+// previousMentions returns the mentions of the previous version of the changed
+// block. When the title of a text or comment is edited, the mentions of the version
+// before it are included too, so an edit restoring a mention removed by the last
+// edit doesn't notify again.
+func (b *Backend) previousMentions(evt notify.BlockChangeEvent) (map[string]struct{}, error) {
+	mentions := extractMentions(evt.BlockOld)
+	if evt.Action != notify.Update || evt.BlockOld == nil || evt.BlockOld.Title == evt.BlockChanged.Title {
+		return mentions, nil
+	}
+	if evt.BlockChanged.Type != model.TypeText && evt.BlockChanged.Type != model.TypeComment {
+		return mentions, nil
+	}
+
+	history, err := b.appAPI.GetBlockHistory(evt.BlockChanged.ID, model.QueryBlockHistoryOptions{
+		BeforeUpdateAt: evt.BlockOld.UpdateAt,
+		Limit:          1,
+		Descending:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range history {
+		for username := range extractMentions(block) {
+			mentions[username] = struct{}{}
+		}
+	}
+	return mentions, nil
+}
+
 func safeCallListener(listener MentionListener, userID string, evt notify.BlockChangeEvent, logger mlog.LoggerIFace) {
 	// don't let panicky listeners stop notifications
 	defer func() {
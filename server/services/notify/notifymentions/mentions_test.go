@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
 
 	mm_model "github.com/mattermost/mattermost/server/public/model"
 )
@@ -50,3 +51,68 @@ func makeMap(mentions ...string) map[string]struct{} {
 	}
 	return m
 }
+
+type historyAppAPI struct {
+	AppAPI
+	history []*model.Block
+	calls   []model.QueryBlockHistoryOptions
+}
+
+func (a *historyAppAPI) GetBlockHistory(blockID string, opts model.QueryBlockHistoryOptions) ([]*model.Block, error) {
+	a.calls = append(a.calls, opts)
+	return a.history, nil
+}
+
+func TestBackend_previousMentions(t *testing.T) {
+	oldBlock := makeBlock("Hello @user2")
+	oldBlock.UpdateAt = 2000
+	newBlock := makeBlock("Hello @user1, @user2 and @user3")
+	newBlock.ID = oldBlock.ID
+	newBlock.UpdateAt = 3000
+	appAPI := &historyAppAPI{history: []*model.Block{makeBlock("Hello @user1")}}
+	b := New(BackendParams{AppAPI: appAPI})
+
+	t.Run("edits include the mentions of the version before the previous one", func(t *testing.T) {
+		appAPI.calls = nil
+		evt := notify.BlockChangeEvent{Action: notify.Update, BlockChanged: newBlock, BlockOld: oldBlock}
+		got, err := b.previousMentions(evt)
+		if err != nil {
+			t.Fatalf("previousMentions() error = %v", err)
+		}
+		if want := makeMap("user1", "user2"); !reflect.DeepEqual(got, want) {
+			t.Errorf("previousMentions() = %v, want %v", got, want)
+		}
+		want := []model.QueryBlockHistoryOptions{{BeforeUpdateAt: 2000, Limit: 1, Descending: true}}
+		if !reflect.DeepEqual(appAPI.calls, want) {
+			t.Errorf("GetBlockHistory() calls = %v, want %v", appAPI.calls, want)
+		}
+	})
+
+	t.Run("edits keeping the title don't load the history", func(t *testing.T) {
+		appAPI.calls = nil
+		sameTitle := *oldBlock
+		sameTitle.UpdateAt = 3000
+		evt := notify.BlockChangeEvent{Action: notify.Update, BlockChanged: &sameTitle, BlockOld: oldBlock}
+		got, err := b.previousMentions(evt)
+		if err != nil {
+			t.Fatalf("previousMentions() error = %v", err)
+		}
+		if want := makeMap("user2"); !reflect.DeepEqual(got, want) {
+			t.Errorf("previousMentions() = %v, want %v", got, want)
+		}
+		if len(appAPI.calls) != 0 {
+			t.Errorf("GetBlockHistory() called %d times, want 0", len(appAPI.calls))
+		}
+	})
+
+	t.Run("new blocks have no previous mentions", func(t *testing.T) {
+		evt := notify.BlockChangeEvent{Action: notify.Add, BlockChanged: newBlock}
+		got, err := b.previousMentions(evt)
+		if err != nil {
+			t.Fatalf("previousMentions() error = %v", err)
+		}
+		if want := makeMap(); !reflect.DeepEqual(got, want) {
+			t.Errorf("previousMentions() = %v, want %v", got, want)
+		}
+	})
+}
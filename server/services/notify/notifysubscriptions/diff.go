@@ -291,20 +291,27 @@ func (dg *diffGenerator) generateDiffForBlock(newBlock *model.Block, schema mode
 }
 
 func (dg *diffGenerator) generatePropDiffs(oldBlock, newBlock *model.Block, schema model.PropSchema) []PropDiff {
+	return GeneratePropDiffs(oldBlock, newBlock, schema, dg.store, dg.logger)
+}
+
+// GeneratePropDiffs returns the properties that were added, changed or removed between two
+// versions of a block, sorted by their index in the board schema. Property values are resolved
+// using the schema and the resolver, so option ids and user ids are returned as display values.
+func GeneratePropDiffs(oldBlock, newBlock *model.Block, schema model.PropSchema, resolver model.PropValueResolver, logger mlog.LoggerIFace) []PropDiff {
 	var propDiffs []PropDiff
 
-	oldProps, err := model.ParseProperties(oldBlock, schema, dg.store)
+	oldProps, err := model.ParseProperties(oldBlock, schema, resolver)
 	if err != nil {
-		dg.logger.Error("Cannot parse properties for old block",
+		logger.Error("Cannot parse properties for old block",
 			mlog.String("block_id", oldBlock.ID),
 			mlog.Err(err),
 		)
 	}
 
-	newProps, err := model.ParseProperties(newBlock, schema, dg.store)
+	newProps, err := model.ParseProperties(newBlock, schema, resolver)
 	if err != nil {
-		dg.logger.Error("Cannot parse properties for new block",
-			mlog.String("block_id", oldBlock.ID),
+		logger.Error("Cannot parse properties for new block",
+			mlog.String("block_id", newBlock.ID),
 			mlog.Err(err),
 		)
 	}
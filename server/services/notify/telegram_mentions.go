@@ -64,12 +64,33 @@ func (tmb *TelegramMentionsBackend) BlockChanged(evt BlockChangeEvent) error {
 		return nil
 	}
 
-	// If this is an update, check if these are new mentions
+	// If this is an update, check if these are new mentions. When the title of a
+	// text or comment is edited, the version before the previous one is checked
+	// too, so that restoring a mention removed by the last edit doesn't notify again.
 	oldMentions := make(map[string]bool)
 	if evt.Action == Update && evt.BlockOld != nil {
 		for _, username := range extractMentions(evt.BlockOld.Title) {
 			oldMentions[username] = true
 		}
+
+		if blockType != model.TypeImage && evt.BlockOld.Title != evt.BlockChanged.Title {
+			history, err := tmb.store.GetBlockHistory(evt.BlockChanged.ID, model.QueryBlockHistoryOptions{
+				BeforeUpdateAt: evt.BlockOld.UpdateAt,
+				Limit:          1,
+				Descending:     true,
+			})
+			if err != nil {
+				tmb.logger.Error("Failed to get block history for mentions",
+					mlog.String("block_id", evt.BlockChanged.ID),
+					mlog.Err(err),
+				)
+			}
+			for _, block := range history {
+				for _, username := range extractMentions(block.Title) {
+					oldMentions[username] = true
+				}
+			}
+		}
 	}
 
 	// Get the user who made the change
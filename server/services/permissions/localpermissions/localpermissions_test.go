@@ -68,6 +68,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 			model.PermissionManageBoardCards,
 			model.PermissionViewBoard,
 			model.PermissionManageBoardProperties,
@@ -96,6 +98,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 		}
 
 		th.checkBoardPermissions("editor", member, hasPermissionTo, hasNotPermissionTo)
@@ -117,6 +121,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 			model.PermissionManageBoardCards,
 			model.PermissionManageBoardProperties,
 		}
@@ -140,6 +146,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 			model.PermissionManageBoardCards,
 			model.PermissionManageBoardProperties,
 		}
@@ -163,6 +171,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 			model.PermissionManageBoardCards,
 			model.PermissionManageBoardProperties,
 		}
@@ -141,6 +141,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 			model.PermissionManageBoardCards,
 			model.PermissionViewBoard,
 			model.PermissionManageBoardProperties,
@@ -169,6 +171,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 		}
 
 		th.checkBoardPermissions("editor", member, teamID, hasPermissionTo, hasNotPermissionTo)
@@ -190,6 +194,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 			model.PermissionManageBoardCards,
 			model.PermissionManageBoardProperties,
 		}
@@ -213,6 +219,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 			model.PermissionManageBoardCards,
 			model.PermissionManageBoardProperties,
 		}
@@ -232,6 +240,8 @@ func TestHasPermissionToBoard(t *testing.T) {
 			model.PermissionDeleteBoard,
 			model.PermissionManageBoardRoles,
 			model.PermissionShareBoard,
+			model.PermissionOverrideWIPLimits,
+			model.PermissionRestoreBoard,
 			model.PermissionManageBoardCards,
 			model.PermissionViewBoard,
 			model.PermissionManageBoardProperties,
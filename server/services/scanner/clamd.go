@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is the size of the chunks the files are streamed in.
+// It must be under the StreamMaxLength of the daemon.
+const clamdChunkSize = 64 * 1024
+
+// ClamdScanner scans files with a ClamAV daemon, streaming them over
+// its INSTREAM command.
+type ClamdScanner struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner creates a scanner using the daemon listening at a
+// TCP address, or at a unix socket for addresses starting with unix:.
+func NewClamdScanner(address string, timeout time.Duration) *ClamdScanner {
+	return &ClamdScanner{
+		address: address,
+		timeout: timeout,
+	}
+}
+
+func (s *ClamdScanner) dial(ctx context.Context) (net.Conn, error) {
+	network, address := "tcp", s.address
+	if strings.HasPrefix(address, "unix:") {
+		network, address = "unix", strings.TrimPrefix(address, "unix:")
+	}
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, address)
+}
+
+// Scan streams a file to the daemon and parses its verdict.
+func (s *ClamdScanner) Scan(ctx context.Context, reader io.Reader) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to clamd: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err = conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("cannot send command to clamd: %w", err)
+	}
+
+	chunk := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := reader.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err = conn.Write(size); err != nil {
+				return nil, fmt.Errorf("cannot stream file to clamd: %w", err)
+			}
+			if _, err = conn.Write(chunk[:n]); err != nil {
+				return nil, fmt.Errorf("cannot stream file to clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	// a zero length chunk ends the stream
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err = conn.Write(size); err != nil {
+		return nil, fmt.Errorf("cannot stream file to clamd: %w", err)
+	}
+
+	response, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("cannot read clamd response: %w", err)
+	}
+	return parseClamdResponse(strings.TrimRight(response, "\x00\n"))
+}
+
+// parseClamdResponse parses the responses of the daemon, like
+// "stream: OK" or "stream: Eicar-Signature FOUND".
+func parseClamdResponse(response string) (*Result, error) {
+	verdict := strings.TrimSpace(strings.TrimPrefix(response, "stream:"))
+
+	switch {
+	case verdict == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{
+			Infected:  true,
+			Signature: strings.TrimSuffix(verdict, " FOUND"),
+		}, nil
+	}
+	return nil, fmt.Errorf("clamd scan failed: %s", response)
+}
@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// eicarSignature is the content of the EICAR antivirus test file,
+// which every antivirus detects.
+const eicarSignature = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!H+H*`
+
+// EICARScanner is a local scanner that only detects the EICAR test
+// file. It stands in for an antivirus in tests and development setups.
+type EICARScanner struct{}
+
+// NewEICARScanner creates a scanner detecting the EICAR test file.
+func NewEICARScanner() *EICARScanner {
+	return &EICARScanner{}
+}
+
+// Scan reports files containing the EICAR test string as infected.
+func (s *EICARScanner) Scan(ctx context.Context, reader io.Reader) (*Result, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(content, []byte(eicarSignature)) {
+		return &Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return &Result{}, nil
+}
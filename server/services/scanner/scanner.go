@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	DriverClamd = "clamd"
+	DriverEICAR = "eicar"
+
+	defaultTimeout = time.Minute
+)
+
+// Result is the verdict of a scanner on a file.
+type Result struct {
+	// Infected is true if the file must not be served.
+	Infected bool
+	// Signature is the name of what was detected in an infected file.
+	Signature string
+}
+
+// Scanner inspects the content of uploaded files, like antivirus
+// daemons do.
+type Scanner interface {
+	// Scan reads a file until its end and returns the verdict on it.
+	Scan(ctx context.Context, reader io.Reader) (*Result, error)
+}
+
+// Config selects the scanner of the uploads.
+type Config struct {
+	// Driver is the kind of scanner, empty to not scan the uploads.
+	Driver string
+	// Address is the network address of the scanner daemon.
+	Address string
+	// Timeout is the maximum duration of a scan.
+	Timeout time.Duration
+}
+
+// New creates the scanner of a configuration, or nil if uploads are
+// not scanned.
+func New(cfg Config) (Scanner, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case DriverClamd:
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("the %s upload scanner requires an address", DriverClamd)
+		}
+		return NewClamdScanner(cfg.Address, timeout), nil
+	case DriverEICAR:
+		return NewEICARScanner(), nil
+	}
+	return nil, fmt.Errorf("unknown upload scanner: %s", cfg.Driver)
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd accepts one INSTREAM scan and answers it with response,
+// sending the streamed content on received.
+func fakeClamd(t *testing.T, response string) (string, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		command := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+			return
+		}
+
+		var content bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(&content, conn, int64(n)); err != nil {
+				return
+			}
+		}
+		received <- content.Bytes()
+		_, _ = conn.Write([]byte(response + "\x00"))
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestClamdScanner(t *testing.T) {
+	t.Run("clean files", func(t *testing.T) {
+		address, received := fakeClamd(t, "stream: OK")
+		content := strings.Repeat("a", 3*clamdChunkSize+1)
+
+		result, err := NewClamdScanner(address, time.Second).Scan(context.Background(), strings.NewReader(content))
+		require.NoError(t, err)
+		require.False(t, result.Infected)
+		require.Equal(t, content, string(<-received))
+	})
+
+	t.Run("infected files", func(t *testing.T) {
+		address, _ := fakeClamd(t, "stream: Win.Test.EICAR_HDB-1 FOUND")
+
+		result, err := NewClamdScanner(address, time.Second).Scan(context.Background(), strings.NewReader(eicarSignature))
+		require.NoError(t, err)
+		require.True(t, result.Infected)
+		require.Equal(t, "Win.Test.EICAR_HDB-1", result.Signature)
+	})
+
+	t.Run("scan errors", func(t *testing.T) {
+		address, _ := fakeClamd(t, "INSTREAM size limit exceeded. ERROR")
+
+		_, err := NewClamdScanner(address, time.Second).Scan(context.Background(), strings.NewReader("content"))
+		require.Error(t, err)
+	})
+}
+
+func TestEICARScanner(t *testing.T) {
+	scanner := NewEICARScanner()
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader("some content"))
+	require.NoError(t, err)
+	require.False(t, result.Infected)
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader("prefix "+eicarSignature))
+	require.NoError(t, err)
+	require.True(t, result.Infected)
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(Config{})
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	s, err = New(Config{Driver: DriverEICAR})
+	require.NoError(t, err)
+	require.IsType(t, &EICARScanner{}, s)
+
+	_, err = New(Config{Driver: DriverClamd})
+	require.Error(t, err)
+
+	_, err = New(Config{Driver: "unknown"})
+	require.Error(t, err)
+}
@@ -36,6 +36,35 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AddCommentReaction mocks base method.
+func (m *MockStore) AddCommentReaction(arg0 *model.CommentReaction) (*model.CommentReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCommentReaction", arg0)
+	ret0, _ := ret[0].(*model.CommentReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCommentReaction indicates an expected call of AddCommentReaction.
+func (mr *MockStoreMockRecorder) AddCommentReaction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommentReaction", reflect.TypeOf((*MockStore)(nil).AddCommentReaction), arg0)
+}
+
+// AddFileUsage mocks base method.
+func (m *MockStore) AddFileUsage(arg0 *model.FileUsage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFileUsage", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFileUsage indicates an expected call of AddFileUsage.
+func (mr *MockStoreMockRecorder) AddFileUsage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFileUsage", reflect.TypeOf((*MockStore)(nil).AddFileUsage), arg0)
+}
+
 // AddUpdateCategoryBoard mocks base method.
 func (m *MockStore) AddUpdateCategoryBoard(arg0, arg1 string, arg2 []string) error {
 	m.ctrl.T.Helper()
@@ -50,6 +79,21 @@ func (mr *MockStoreMockRecorder) AddUpdateCategoryBoard(arg0, arg1, arg2 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUpdateCategoryBoard", reflect.TypeOf((*MockStore)(nil).AddUpdateCategoryBoard), arg0, arg1, arg2)
 }
 
+// ApplyCardBulkChanges mocks base method.
+func (m *MockStore) ApplyCardBulkChanges(arg0 *model.CardBulkChanges, arg1 string) ([]*model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyCardBulkChanges", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyCardBulkChanges indicates an expected call of ApplyCardBulkChanges.
+func (mr *MockStoreMockRecorder) ApplyCardBulkChanges(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyCardBulkChanges", reflect.TypeOf((*MockStore)(nil).ApplyCardBulkChanges), arg0, arg1)
+}
+
 // CanSeeUser mocks base method.
 func (m *MockStore) CanSeeUser(arg0, arg1 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +109,36 @@ func (mr *MockStoreMockRecorder) CanSeeUser(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanSeeUser", reflect.TypeOf((*MockStore)(nil).CanSeeUser), arg0, arg1)
 }
 
+// ClaimExportJob mocks base method.
+func (m *MockStore) ClaimExportJob(arg0 string, arg1 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExportJob", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExportJob indicates an expected call of ClaimExportJob.
+func (mr *MockStoreMockRecorder) ClaimExportJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExportJob", reflect.TypeOf((*MockStore)(nil).ClaimExportJob), arg0, arg1)
+}
+
+// ClaimFileBlob mocks base method.
+func (m *MockStore) ClaimFileBlob(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimFileBlob", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimFileBlob indicates an expected call of ClaimFileBlob.
+func (mr *MockStoreMockRecorder) ClaimFileBlob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimFileBlob", reflect.TypeOf((*MockStore)(nil).ClaimFileBlob), arg0)
+}
+
 // CleanUpSessions mocks base method.
 func (m *MockStore) CleanUpSessions(arg0 int64) error {
 	m.ctrl.T.Helper()
@@ -79,6 +153,36 @@ func (mr *MockStoreMockRecorder) CleanUpSessions(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanUpSessions", reflect.TypeOf((*MockStore)(nil).CleanUpSessions), arg0)
 }
 
+// CompareAndSetSystemSetting mocks base method.
+func (m *MockStore) CompareAndSetSystemSetting(arg0, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndSetSystemSetting", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompareAndSetSystemSetting indicates an expected call of CompareAndSetSystemSetting.
+func (mr *MockStoreMockRecorder) CompareAndSetSystemSetting(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndSetSystemSetting", reflect.TypeOf((*MockStore)(nil).CompareAndSetSystemSetting), arg0, arg1, arg2)
+}
+
+// CreateAutomationRule mocks base method.
+func (m *MockStore) CreateAutomationRule(arg0 *model.AutomationRule) (*model.AutomationRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAutomationRule", arg0)
+	ret0, _ := ret[0].(*model.AutomationRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAutomationRule indicates an expected call of CreateAutomationRule.
+func (mr *MockStoreMockRecorder) CreateAutomationRule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAutomationRule", reflect.TypeOf((*MockStore)(nil).CreateAutomationRule), arg0)
+}
+
 // CreateBoardsAndBlocks mocks base method.
 func (m *MockStore) CreateBoardsAndBlocks(arg0 *model.BoardsAndBlocks, arg1 string) (*model.BoardsAndBlocks, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +214,22 @@ func (mr *MockStoreMockRecorder) CreateBoardsAndBlocksWithAdmin(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBoardsAndBlocksWithAdmin", reflect.TypeOf((*MockStore)(nil).CreateBoardsAndBlocksWithAdmin), arg0, arg1)
 }
 
+// CreateBoardsAndBlocksWithMembers mocks base method.
+func (m *MockStore) CreateBoardsAndBlocksWithMembers(arg0 *model.BoardsAndBlocks, arg1 []*model.BoardMember, arg2 string) (*model.BoardsAndBlocks, []*model.BoardMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBoardsAndBlocksWithMembers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.BoardsAndBlocks)
+	ret1, _ := ret[1].([]*model.BoardMember)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateBoardsAndBlocksWithMembers indicates an expected call of CreateBoardsAndBlocksWithMembers.
+func (mr *MockStoreMockRecorder) CreateBoardsAndBlocksWithMembers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBoardsAndBlocksWithMembers", reflect.TypeOf((*MockStore)(nil).CreateBoardsAndBlocksWithMembers), arg0, arg1, arg2)
+}
+
 // CreateCategory mocks base method.
 func (m *MockStore) CreateCategory(arg0 model.Category) error {
 	m.ctrl.T.Helper()
@@ -124,6 +244,20 @@ func (mr *MockStoreMockRecorder) CreateCategory(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockStore)(nil).CreateCategory), arg0)
 }
 
+// CreateExportJob mocks base method.
+func (m *MockStore) CreateExportJob(arg0 *model.ExportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExportJob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateExportJob indicates an expected call of CreateExportJob.
+func (mr *MockStoreMockRecorder) CreateExportJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExportJob", reflect.TypeOf((*MockStore)(nil).CreateExportJob), arg0)
+}
+
 // CreateSession mocks base method.
 func (m *MockStore) CreateSession(arg0 *model.Session) error {
 	m.ctrl.T.Helper()
@@ -196,6 +330,20 @@ func (mr *MockStoreMockRecorder) DBVersion() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DBVersion", reflect.TypeOf((*MockStore)(nil).DBVersion))
 }
 
+// DeleteAutomationRule mocks base method.
+func (m *MockStore) DeleteAutomationRule(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAutomationRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAutomationRule indicates an expected call of DeleteAutomationRule.
+func (mr *MockStoreMockRecorder) DeleteAutomationRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAutomationRule", reflect.TypeOf((*MockStore)(nil).DeleteAutomationRule), arg0, arg1)
+}
+
 // DeleteBlock mocks base method.
 func (m *MockStore) DeleteBlock(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -280,6 +428,62 @@ func (mr *MockStoreMockRecorder) DeleteCategory(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockStore)(nil).DeleteCategory), arg0, arg1, arg2)
 }
 
+// DeleteCommentReaction mocks base method.
+func (m *MockStore) DeleteCommentReaction(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCommentReaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCommentReaction indicates an expected call of DeleteCommentReaction.
+func (mr *MockStoreMockRecorder) DeleteCommentReaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCommentReaction", reflect.TypeOf((*MockStore)(nil).DeleteCommentReaction), arg0, arg1, arg2)
+}
+
+// DeleteExportJob mocks base method.
+func (m *MockStore) DeleteExportJob(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExportJob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExportJob indicates an expected call of DeleteExportJob.
+func (mr *MockStoreMockRecorder) DeleteExportJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExportJob", reflect.TypeOf((*MockStore)(nil).DeleteExportJob), arg0)
+}
+
+// DeleteFileBlob mocks base method.
+func (m *MockStore) DeleteFileBlob(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileBlob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFileBlob indicates an expected call of DeleteFileBlob.
+func (mr *MockStoreMockRecorder) DeleteFileBlob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileBlob", reflect.TypeOf((*MockStore)(nil).DeleteFileBlob), arg0)
+}
+
+// DeleteFileInfos mocks base method.
+func (m *MockStore) DeleteFileInfos(arg0 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileInfos", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFileInfos indicates an expected call of DeleteFileInfos.
+func (mr *MockStoreMockRecorder) DeleteFileInfos(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileInfos", reflect.TypeOf((*MockStore)(nil).DeleteFileInfos), arg0)
+}
+
 // DeleteMember mocks base method.
 func (m *MockStore) DeleteMember(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -322,6 +526,20 @@ func (mr *MockStoreMockRecorder) DeleteSession(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockStore)(nil).DeleteSession), arg0)
 }
 
+// DeleteStorageQuota mocks base method.
+func (m *MockStore) DeleteStorageQuota(arg0 model.StorageQuotaScope, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStorageQuota", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStorageQuota indicates an expected call of DeleteStorageQuota.
+func (mr *MockStoreMockRecorder) DeleteStorageQuota(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStorageQuota", reflect.TypeOf((*MockStore)(nil).DeleteStorageQuota), arg0, arg1)
+}
+
 // DeleteSubscription mocks base method.
 func (m *MockStore) DeleteSubscription(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -336,6 +554,20 @@ func (mr *MockStoreMockRecorder) DeleteSubscription(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockStore)(nil).DeleteSubscription), arg0, arg1)
 }
 
+// DeleteUploadPolicy mocks base method.
+func (m *MockStore) DeleteUploadPolicy(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUploadPolicy", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUploadPolicy indicates an expected call of DeleteUploadPolicy.
+func (mr *MockStoreMockRecorder) DeleteUploadPolicy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUploadPolicy", reflect.TypeOf((*MockStore)(nil).DeleteUploadPolicy), arg0)
+}
+
 // DuplicateBlock mocks base method.
 func (m *MockStore) DuplicateBlock(arg0, arg1, arg2 string, arg3 bool) ([]*model.Block, error) {
 	m.ctrl.T.Helper()
@@ -397,6 +629,51 @@ func (mr *MockStoreMockRecorder) GetAllTeams() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTeams", reflect.TypeOf((*MockStore)(nil).GetAllTeams))
 }
 
+// GetAutomationExecutions mocks base method.
+func (m *MockStore) GetAutomationExecutions(arg0 model.QueryAutomationExecutionsOptions) ([]*model.AutomationExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutomationExecutions", arg0)
+	ret0, _ := ret[0].([]*model.AutomationExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutomationExecutions indicates an expected call of GetAutomationExecutions.
+func (mr *MockStoreMockRecorder) GetAutomationExecutions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutomationExecutions", reflect.TypeOf((*MockStore)(nil).GetAutomationExecutions), arg0)
+}
+
+// GetAutomationRule mocks base method.
+func (m *MockStore) GetAutomationRule(arg0 string) (*model.AutomationRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutomationRule", arg0)
+	ret0, _ := ret[0].(*model.AutomationRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutomationRule indicates an expected call of GetAutomationRule.
+func (mr *MockStoreMockRecorder) GetAutomationRule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutomationRule", reflect.TypeOf((*MockStore)(nil).GetAutomationRule), arg0)
+}
+
+// GetAutomationRulesForBoard mocks base method.
+func (m *MockStore) GetAutomationRulesForBoard(arg0 string) ([]*model.AutomationRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutomationRulesForBoard", arg0)
+	ret0, _ := ret[0].([]*model.AutomationRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutomationRulesForBoard indicates an expected call of GetAutomationRulesForBoard.
+func (mr *MockStoreMockRecorder) GetAutomationRulesForBoard(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutomationRulesForBoard", reflect.TypeOf((*MockStore)(nil).GetAutomationRulesForBoard), arg0)
+}
+
 // GetBlock mocks base method.
 func (m *MockStore) GetBlock(arg0 string) (*model.Block, error) {
 	m.ctrl.T.Helper()
@@ -671,6 +948,21 @@ func (mr *MockStoreMockRecorder) GetBoardMemberHistory(arg0, arg1, arg2 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardMemberHistory", reflect.TypeOf((*MockStore)(nil).GetBoardMemberHistory), arg0, arg1, arg2)
 }
 
+// GetBoardSnapshot mocks base method.
+func (m *MockStore) GetBoardSnapshot(arg0 string, arg1 int64) (*model.BoardSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardSnapshot", arg0, arg1)
+	ret0, _ := ret[0].(*model.BoardSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardSnapshot indicates an expected call of GetBoardSnapshot.
+func (mr *MockStoreMockRecorder) GetBoardSnapshot(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardSnapshot", reflect.TypeOf((*MockStore)(nil).GetBoardSnapshot), arg0, arg1)
+}
+
 // GetBoardsComplianceHistory mocks base method.
 func (m *MockStore) GetBoardsComplianceHistory(arg0 model.QueryBoardsComplianceHistoryOptions) ([]*model.BoardHistory, bool, error) {
 	m.ctrl.T.Helper()
@@ -778,6 +1070,96 @@ func (mr *MockStoreMockRecorder) GetChannel(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannel", reflect.TypeOf((*MockStore)(nil).GetChannel), arg0, arg1)
 }
 
+// GetCommentReactions mocks base method.
+func (m *MockStore) GetCommentReactions(arg0 []string) ([]*model.CommentReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentReactions", arg0)
+	ret0, _ := ret[0].([]*model.CommentReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentReactions indicates an expected call of GetCommentReactions.
+func (mr *MockStoreMockRecorder) GetCommentReactions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentReactions", reflect.TypeOf((*MockStore)(nil).GetCommentReactions), arg0)
+}
+
+// GetEnabledAutomationRulesByTrigger mocks base method.
+func (m *MockStore) GetEnabledAutomationRulesByTrigger(arg0 model.AutomationTriggerType) ([]*model.AutomationRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnabledAutomationRulesByTrigger", arg0)
+	ret0, _ := ret[0].([]*model.AutomationRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnabledAutomationRulesByTrigger indicates an expected call of GetEnabledAutomationRulesByTrigger.
+func (mr *MockStoreMockRecorder) GetEnabledAutomationRulesByTrigger(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnabledAutomationRulesByTrigger", reflect.TypeOf((*MockStore)(nil).GetEnabledAutomationRulesByTrigger), arg0)
+}
+
+// GetExpiredExportJobs mocks base method.
+func (m *MockStore) GetExpiredExportJobs(arg0 int64) ([]*model.ExportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredExportJobs", arg0)
+	ret0, _ := ret[0].([]*model.ExportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredExportJobs indicates an expected call of GetExpiredExportJobs.
+func (mr *MockStoreMockRecorder) GetExpiredExportJobs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredExportJobs", reflect.TypeOf((*MockStore)(nil).GetExpiredExportJobs), arg0)
+}
+
+// GetExportJob mocks base method.
+func (m *MockStore) GetExportJob(arg0 string) (*model.ExportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportJob", arg0)
+	ret0, _ := ret[0].(*model.ExportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExportJob indicates an expected call of GetExportJob.
+func (mr *MockStoreMockRecorder) GetExportJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportJob", reflect.TypeOf((*MockStore)(nil).GetExportJob), arg0)
+}
+
+// GetExportJobsForUser mocks base method.
+func (m *MockStore) GetExportJobsForUser(arg0, arg1 string) ([]*model.ExportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportJobsForUser", arg0, arg1)
+	ret0, _ := ret[0].([]*model.ExportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExportJobsForUser indicates an expected call of GetExportJobsForUser.
+func (mr *MockStoreMockRecorder) GetExportJobsForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportJobsForUser", reflect.TypeOf((*MockStore)(nil).GetExportJobsForUser), arg0, arg1)
+}
+
+// GetFileBlob mocks base method.
+func (m *MockStore) GetFileBlob(arg0 string) (*model.FileBlob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileBlob", arg0)
+	ret0, _ := ret[0].(*model.FileBlob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileBlob indicates an expected call of GetFileBlob.
+func (mr *MockStoreMockRecorder) GetFileBlob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileBlob", reflect.TypeOf((*MockStore)(nil).GetFileBlob), arg0)
+}
+
 // GetFileInfo mocks base method.
 func (m *MockStore) GetFileInfo(arg0 string) (*model0.FileInfo, error) {
 	m.ctrl.T.Helper()
@@ -793,6 +1175,127 @@ func (mr *MockStoreMockRecorder) GetFileInfo(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileInfo", reflect.TypeOf((*MockStore)(nil).GetFileInfo), arg0)
 }
 
+// GetFileInfosCreatedBefore mocks base method.
+func (m *MockStore) GetFileInfosCreatedBefore(arg0 int64) ([]*model0.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileInfosCreatedBefore", arg0)
+	ret0, _ := ret[0].([]*model0.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileInfosCreatedBefore indicates an expected call of GetFileInfosCreatedBefore.
+func (mr *MockStoreMockRecorder) GetFileInfosCreatedBefore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileInfosCreatedBefore", reflect.TypeOf((*MockStore)(nil).GetFileInfosCreatedBefore), arg0)
+}
+
+// GetFileReferences mocks base method.
+func (m *MockStore) GetFileReferences() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileReferences")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileReferences indicates an expected call of GetFileReferences.
+func (mr *MockStoreMockRecorder) GetFileReferences() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileReferences", reflect.TypeOf((*MockStore)(nil).GetFileReferences))
+}
+
+// GetFileScanResult mocks base method.
+func (m *MockStore) GetFileScanResult(arg0 string) (*model.FileScanResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileScanResult", arg0)
+	ret0, _ := ret[0].(*model.FileScanResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileScanResult indicates an expected call of GetFileScanResult.
+func (mr *MockStoreMockRecorder) GetFileScanResult(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileScanResult", reflect.TypeOf((*MockStore)(nil).GetFileScanResult), arg0)
+}
+
+// GetFileUsage mocks base method.
+func (m *MockStore) GetFileUsage(arg0 string) ([]*model.FileUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileUsage", arg0)
+	ret0, _ := ret[0].([]*model.FileUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileUsage indicates an expected call of GetFileUsage.
+func (mr *MockStoreMockRecorder) GetFileUsage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileUsage", reflect.TypeOf((*MockStore)(nil).GetFileUsage), arg0)
+}
+
+// GetFileUsageTotals mocks base method.
+func (m *MockStore) GetFileUsageTotals(arg0, arg1 string) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileUsageTotals", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetFileUsageTotals indicates an expected call of GetFileUsageTotals.
+func (mr *MockStoreMockRecorder) GetFileUsageTotals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileUsageTotals", reflect.TypeOf((*MockStore)(nil).GetFileUsageTotals), arg0, arg1)
+}
+
+// GetInboxItems mocks base method.
+func (m *MockStore) GetInboxItems(arg0 string, arg1 model.QueryInboxItemsOptions) ([]*model.InboxItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInboxItems", arg0, arg1)
+	ret0, _ := ret[0].([]*model.InboxItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInboxItems indicates an expected call of GetInboxItems.
+func (mr *MockStoreMockRecorder) GetInboxItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInboxItems", reflect.TypeOf((*MockStore)(nil).GetInboxItems), arg0, arg1)
+}
+
+// GetInboxUnreadCount mocks base method.
+func (m *MockStore) GetInboxUnreadCount(arg0, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInboxUnreadCount", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInboxUnreadCount indicates an expected call of GetInboxUnreadCount.
+func (mr *MockStoreMockRecorder) GetInboxUnreadCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInboxUnreadCount", reflect.TypeOf((*MockStore)(nil).GetInboxUnreadCount), arg0, arg1)
+}
+
+// GetLegacyFileInfos mocks base method.
+func (m *MockStore) GetLegacyFileInfos(arg0 int64, arg1 string, arg2 int) ([]*model0.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLegacyFileInfos", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*model0.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLegacyFileInfos indicates an expected call of GetLegacyFileInfos.
+func (mr *MockStoreMockRecorder) GetLegacyFileInfos(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLegacyFileInfos", reflect.TypeOf((*MockStore)(nil).GetLegacyFileInfos), arg0, arg1, arg2)
+}
+
 // GetLicense mocks base method.
 func (m *MockStore) GetLicense() *model0.License {
 	m.ctrl.T.Helper()
@@ -927,6 +1430,51 @@ func (mr *MockStoreMockRecorder) GetSharing(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharing", reflect.TypeOf((*MockStore)(nil).GetSharing), arg0)
 }
 
+// GetStaleExportJobs mocks base method.
+func (m *MockStore) GetStaleExportJobs(arg0 int64) ([]*model.ExportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStaleExportJobs", arg0)
+	ret0, _ := ret[0].([]*model.ExportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStaleExportJobs indicates an expected call of GetStaleExportJobs.
+func (mr *MockStoreMockRecorder) GetStaleExportJobs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStaleExportJobs", reflect.TypeOf((*MockStore)(nil).GetStaleExportJobs), arg0)
+}
+
+// GetStorageQuota mocks base method.
+func (m *MockStore) GetStorageQuota(arg0 model.StorageQuotaScope, arg1 string) (*model.StorageQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStorageQuota", arg0, arg1)
+	ret0, _ := ret[0].(*model.StorageQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStorageQuota indicates an expected call of GetStorageQuota.
+func (mr *MockStoreMockRecorder) GetStorageQuota(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorageQuota", reflect.TypeOf((*MockStore)(nil).GetStorageQuota), arg0, arg1)
+}
+
+// GetStorageQuotas mocks base method.
+func (m *MockStore) GetStorageQuotas(arg0 string) ([]*model.StorageQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStorageQuotas", arg0)
+	ret0, _ := ret[0].([]*model.StorageQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStorageQuotas indicates an expected call of GetStorageQuotas.
+func (mr *MockStoreMockRecorder) GetStorageQuotas(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorageQuotas", reflect.TypeOf((*MockStore)(nil).GetStorageQuotas), arg0)
+}
+
 // GetSubTree2 mocks base method.
 func (m *MockStore) GetSubTree2(arg0, arg1 string, arg2 model.QuerySubtreeOptions) ([]*model.Block, error) {
 	m.ctrl.T.Helper()
@@ -1077,6 +1625,21 @@ func (mr *MockStoreMockRecorder) GetTeamsForUser(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamsForUser", reflect.TypeOf((*MockStore)(nil).GetTeamsForUser), arg0)
 }
 
+// GetTelegramNotificationPreferences mocks base method.
+func (m *MockStore) GetTelegramNotificationPreferences(arg0 string) (map[string]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTelegramNotificationPreferences", arg0)
+	ret0, _ := ret[0].(map[string]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTelegramNotificationPreferences indicates an expected call of GetTelegramNotificationPreferences.
+func (mr *MockStoreMockRecorder) GetTelegramNotificationPreferences(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTelegramNotificationPreferences", reflect.TypeOf((*MockStore)(nil).GetTelegramNotificationPreferences), arg0)
+}
+
 // GetTemplateBoards mocks base method.
 func (m *MockStore) GetTemplateBoards(arg0, arg1 string) ([]*model.Board, error) {
 	m.ctrl.T.Helper()
@@ -1092,6 +1655,36 @@ func (mr *MockStoreMockRecorder) GetTemplateBoards(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateBoards", reflect.TypeOf((*MockStore)(nil).GetTemplateBoards), arg0, arg1)
 }
 
+// GetUnreferencedFileBlobs mocks base method.
+func (m *MockStore) GetUnreferencedFileBlobs() ([]*model.FileBlob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnreferencedFileBlobs")
+	ret0, _ := ret[0].([]*model.FileBlob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnreferencedFileBlobs indicates an expected call of GetUnreferencedFileBlobs.
+func (mr *MockStoreMockRecorder) GetUnreferencedFileBlobs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnreferencedFileBlobs", reflect.TypeOf((*MockStore)(nil).GetUnreferencedFileBlobs))
+}
+
+// GetUploadPolicy mocks base method.
+func (m *MockStore) GetUploadPolicy(arg0 string) (*model.UploadPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUploadPolicy", arg0)
+	ret0, _ := ret[0].(*model.UploadPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUploadPolicy indicates an expected call of GetUploadPolicy.
+func (mr *MockStoreMockRecorder) GetUploadPolicy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUploadPolicy", reflect.TypeOf((*MockStore)(nil).GetUploadPolicy), arg0)
+}
+
 // GetUsedCardsCount mocks base method.
 func (m *MockStore) GetUsedCardsCount() (int, error) {
 	m.ctrl.T.Helper()
@@ -1242,6 +1835,20 @@ func (mr *MockStoreMockRecorder) GetUsersList(arg0, arg1, arg2 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersList", reflect.TypeOf((*MockStore)(nil).GetUsersList), arg0, arg1, arg2)
 }
 
+// InsertAutomationExecution mocks base method.
+func (m *MockStore) InsertAutomationExecution(arg0 *model.AutomationExecution) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAutomationExecution", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAutomationExecution indicates an expected call of InsertAutomationExecution.
+func (mr *MockStoreMockRecorder) InsertAutomationExecution(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAutomationExecution", reflect.TypeOf((*MockStore)(nil).InsertAutomationExecution), arg0)
+}
+
 // InsertBlock mocks base method.
 func (m *MockStore) InsertBlock(arg0 *model.Block, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -1301,6 +1908,48 @@ func (mr *MockStoreMockRecorder) InsertBoardWithAdmin(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBoardWithAdmin", reflect.TypeOf((*MockStore)(nil).InsertBoardWithAdmin), arg0, arg1)
 }
 
+// InsertInboxItems mocks base method.
+func (m *MockStore) InsertInboxItems(arg0 []*model.InboxItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertInboxItems", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertInboxItems indicates an expected call of InsertInboxItems.
+func (mr *MockStoreMockRecorder) InsertInboxItems(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertInboxItems", reflect.TypeOf((*MockStore)(nil).InsertInboxItems), arg0)
+}
+
+// MarkInboxItemsRead mocks base method.
+func (m *MockStore) MarkInboxItemsRead(arg0, arg1 string, arg2 []string, arg3 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkInboxItemsRead", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkInboxItemsRead indicates an expected call of MarkInboxItemsRead.
+func (mr *MockStoreMockRecorder) MarkInboxItemsRead(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkInboxItemsRead", reflect.TypeOf((*MockStore)(nil).MarkInboxItemsRead), arg0, arg1, arg2, arg3)
+}
+
+// MoveBlocks mocks base method.
+func (m *MockStore) MoveBlocks(arg0 []*model.Block, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveBlocks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveBlocks indicates an expected call of MoveBlocks.
+func (mr *MockStoreMockRecorder) MoveBlocks(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveBlocks", reflect.TypeOf((*MockStore)(nil).MoveBlocks), arg0, arg1, arg2)
+}
+
 // PatchBlock mocks base method.
 func (m *MockStore) PatchBlock(arg0 string, arg1 *model.BlockPatch, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -1446,6 +2095,36 @@ func (mr *MockStoreMockRecorder) ReorderCategoryBoards(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderCategoryBoards", reflect.TypeOf((*MockStore)(nil).ReorderCategoryBoards), arg0, arg1)
 }
 
+// RequeueExportJob mocks base method.
+func (m *MockStore) RequeueExportJob(arg0 *model.ExportJob, arg1 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueExportJob", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueExportJob indicates an expected call of RequeueExportJob.
+func (mr *MockStoreMockRecorder) RequeueExportJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueExportJob", reflect.TypeOf((*MockStore)(nil).RequeueExportJob), arg0, arg1)
+}
+
+// RestoreBoard mocks base method.
+func (m *MockStore) RestoreBoard(arg0 *model.BoardRestoreChanges, arg1 string) (*model.Board, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreBoard", arg0, arg1)
+	ret0, _ := ret[0].(*model.Board)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreBoard indicates an expected call of RestoreBoard.
+func (mr *MockStoreMockRecorder) RestoreBoard(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreBoard", reflect.TypeOf((*MockStore)(nil).RestoreBoard), arg0, arg1)
+}
+
 // RunDataRetention mocks base method.
 func (m *MockStore) RunDataRetention(arg0, arg1 int64) (int64, error) {
 	m.ctrl.T.Helper()
@@ -1475,6 +2154,34 @@ func (mr *MockStoreMockRecorder) SaveFileInfo(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFileInfo", reflect.TypeOf((*MockStore)(nil).SaveFileInfo), arg0)
 }
 
+// SaveFileInfoWithUsage mocks base method.
+func (m *MockStore) SaveFileInfoWithUsage(arg0 *model0.FileInfo, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFileInfoWithUsage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFileInfoWithUsage indicates an expected call of SaveFileInfoWithUsage.
+func (mr *MockStoreMockRecorder) SaveFileInfoWithUsage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFileInfoWithUsage", reflect.TypeOf((*MockStore)(nil).SaveFileInfoWithUsage), arg0, arg1, arg2)
+}
+
+// SaveFileScanResult mocks base method.
+func (m *MockStore) SaveFileScanResult(arg0 *model.FileScanResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFileScanResult", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFileScanResult indicates an expected call of SaveFileScanResult.
+func (mr *MockStoreMockRecorder) SaveFileScanResult(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFileScanResult", reflect.TypeOf((*MockStore)(nil).SaveFileScanResult), arg0)
+}
+
 // SaveMember mocks base method.
 func (m *MockStore) SaveMember(arg0 *model.BoardMember) (*model.BoardMember, error) {
 	m.ctrl.T.Helper()
@@ -1490,6 +2197,20 @@ func (mr *MockStoreMockRecorder) SaveMember(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMember", reflect.TypeOf((*MockStore)(nil).SaveMember), arg0)
 }
 
+// SaveUploadPolicy mocks base method.
+func (m *MockStore) SaveUploadPolicy(arg0 *model.UploadPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUploadPolicy", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveUploadPolicy indicates an expected call of SaveUploadPolicy.
+func (mr *MockStoreMockRecorder) SaveUploadPolicy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUploadPolicy", reflect.TypeOf((*MockStore)(nil).SaveUploadPolicy), arg0)
+}
+
 // SearchBoardsForUser mocks base method.
 func (m *MockStore) SearchBoardsForUser(arg0 string, arg1 model.BoardSearchField, arg2 string, arg3 bool) ([]*model.Board, error) {
 	m.ctrl.T.Helper()
@@ -1578,6 +2299,20 @@ func (mr *MockStoreMockRecorder) SetBoardVisibility(arg0, arg1, arg2, arg3 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBoardVisibility", reflect.TypeOf((*MockStore)(nil).SetBoardVisibility), arg0, arg1, arg2, arg3)
 }
 
+// SetStorageQuota mocks base method.
+func (m *MockStore) SetStorageQuota(arg0 *model.StorageQuota) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStorageQuota", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStorageQuota indicates an expected call of SetStorageQuota.
+func (mr *MockStoreMockRecorder) SetStorageQuota(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStorageQuota", reflect.TypeOf((*MockStore)(nil).SetStorageQuota), arg0)
+}
+
 // SetSystemSetting mocks base method.
 func (m *MockStore) SetSystemSetting(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -1606,6 +2341,34 @@ func (mr *MockStoreMockRecorder) Shutdown() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockStore)(nil).Shutdown))
 }
 
+// TouchExportJobs mocks base method.
+func (m *MockStore) TouchExportJobs(arg0 []string, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchExportJobs", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchExportJobs indicates an expected call of TouchExportJobs.
+func (mr *MockStoreMockRecorder) TouchExportJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchExportJobs", reflect.TypeOf((*MockStore)(nil).TouchExportJobs), arg0, arg1)
+}
+
+// UnclaimFileBlob mocks base method.
+func (m *MockStore) UnclaimFileBlob(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnclaimFileBlob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnclaimFileBlob indicates an expected call of UnclaimFileBlob.
+func (mr *MockStoreMockRecorder) UnclaimFileBlob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnclaimFileBlob", reflect.TypeOf((*MockStore)(nil).UnclaimFileBlob), arg0)
+}
+
 // UndeleteBlock mocks base method.
 func (m *MockStore) UndeleteBlock(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -1634,6 +2397,20 @@ func (mr *MockStoreMockRecorder) UndeleteBoard(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UndeleteBoard", reflect.TypeOf((*MockStore)(nil).UndeleteBoard), arg0, arg1)
 }
 
+// UpdateAutomationRule mocks base method.
+func (m *MockStore) UpdateAutomationRule(arg0 *model.AutomationRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAutomationRule", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAutomationRule indicates an expected call of UpdateAutomationRule.
+func (mr *MockStoreMockRecorder) UpdateAutomationRule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAutomationRule", reflect.TypeOf((*MockStore)(nil).UpdateAutomationRule), arg0)
+}
+
 // UpdateCardLimitTimestamp mocks base method.
 func (m *MockStore) UpdateCardLimitTimestamp(arg0 int) (int64, error) {
 	m.ctrl.T.Helper()
@@ -1663,6 +2440,34 @@ func (mr *MockStoreMockRecorder) UpdateCategory(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockStore)(nil).UpdateCategory), arg0)
 }
 
+// UpdateExportJob mocks base method.
+func (m *MockStore) UpdateExportJob(arg0 *model.ExportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExportJob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateExportJob indicates an expected call of UpdateExportJob.
+func (mr *MockStoreMockRecorder) UpdateExportJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExportJob", reflect.TypeOf((*MockStore)(nil).UpdateExportJob), arg0)
+}
+
+// UpdateFileInfoPath mocks base method.
+func (m *MockStore) UpdateFileInfoPath(arg0 *model0.FileInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFileInfoPath", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFileInfoPath indicates an expected call of UpdateFileInfoPath.
+func (mr *MockStoreMockRecorder) UpdateFileInfoPath(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileInfoPath", reflect.TypeOf((*MockStore)(nil).UpdateFileInfoPath), arg0)
+}
+
 // UpdateSession mocks base method.
 func (m *MockStore) UpdateSession(arg0 *model.Session) error {
 	m.ctrl.T.Helper()
@@ -1790,3 +2595,17 @@ func (mr *MockStoreMockRecorder) UpsertTeamSignupToken(arg0 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTeamSignupToken", reflect.TypeOf((*MockStore)(nil).UpsertTeamSignupToken), arg0)
 }
+
+// UpsertTelegramNotificationPreferences mocks base method.
+func (m *MockStore) UpsertTelegramNotificationPreferences(arg0 string, arg1 map[string]bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTelegramNotificationPreferences", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertTelegramNotificationPreferences indicates an expected call of UpsertTelegramNotificationPreferences.
+func (mr *MockStoreMockRecorder) UpsertTelegramNotificationPreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTelegramNotificationPreferences", reflect.TypeOf((*MockStore)(nil).UpsertTelegramNotificationPreferences), arg0, arg1)
+}
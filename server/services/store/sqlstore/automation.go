@@ -0,0 +1,333 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+var automationRuleFields = []string{
+	"id",
+	"board_id",
+	"title",
+	"enabled",
+	"trigger_params",
+	"actions",
+	"created_by",
+	"modified_by",
+	"create_at",
+	"update_at",
+	"delete_at",
+}
+
+var automationExecutionFields = []string{
+	"id",
+	"rule_id",
+	"board_id",
+	"card_id",
+	"trigger_type",
+	"status",
+	"message",
+	"depth",
+	"create_at",
+}
+
+func (s *SQLStore) automationRulesFromRows(rows *sql.Rows) ([]*model.AutomationRule, error) {
+	rules := []*model.AutomationRule{}
+
+	for rows.Next() {
+		var rule model.AutomationRule
+		var triggerBytes []byte
+		var actionsBytes []byte
+
+		err := rows.Scan(
+			&rule.ID,
+			&rule.BoardID,
+			&rule.Title,
+			&rule.Enabled,
+			&triggerBytes,
+			&actionsBytes,
+			&rule.CreatedBy,
+			&rule.ModifiedBy,
+			&rule.CreateAt,
+			&rule.UpdateAt,
+			&rule.DeleteAt,
+		)
+		if err != nil {
+			s.logger.Error("automationRulesFromRows scan error", mlog.Err(err))
+			return nil, err
+		}
+
+		if err = json.Unmarshal(triggerBytes, &rule.Trigger); err != nil {
+			s.logger.Error("automation rule trigger unmarshal error", mlog.String("rule_id", rule.ID), mlog.Err(err))
+			return nil, err
+		}
+		if err = json.Unmarshal(actionsBytes, &rule.Actions); err != nil {
+			s.logger.Error("automation rule actions unmarshal error", mlog.String("rule_id", rule.ID), mlog.Err(err))
+			return nil, err
+		}
+
+		rules = append(rules, &rule)
+	}
+	return rules, nil
+}
+
+func (s *SQLStore) createAutomationRule(db sq.BaseRunner, rule *model.AutomationRule) (*model.AutomationRule, error) {
+	ruleAdd := *rule
+	ruleAdd.Populate()
+	if err := ruleAdd.IsValid(); err != nil {
+		return nil, err
+	}
+
+	triggerBytes, err := json.Marshal(ruleAdd.Trigger)
+	if err != nil {
+		return nil, err
+	}
+	actionsBytes, err := json.Marshal(ruleAdd.Actions)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"automation_rules").
+		Columns(append(automationRuleFields, "trigger_type")...).
+		Values(
+			ruleAdd.ID,
+			ruleAdd.BoardID,
+			ruleAdd.Title,
+			ruleAdd.Enabled,
+			string(triggerBytes),
+			string(actionsBytes),
+			ruleAdd.CreatedBy,
+			ruleAdd.ModifiedBy,
+			ruleAdd.CreateAt,
+			ruleAdd.UpdateAt,
+			ruleAdd.DeleteAt,
+			ruleAdd.Trigger.Type,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("Cannot create automation rule",
+			mlog.String("board_id", ruleAdd.BoardID),
+			mlog.Err(err),
+		)
+		return nil, err
+	}
+	return &ruleAdd, nil
+}
+
+func (s *SQLStore) updateAutomationRule(db sq.BaseRunner, rule *model.AutomationRule) error {
+	if err := rule.IsValid(); err != nil {
+		return err
+	}
+
+	triggerBytes, err := json.Marshal(rule.Trigger)
+	if err != nil {
+		return err
+	}
+	actionsBytes, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return err
+	}
+
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"automation_rules").
+		Set("title", rule.Title).
+		Set("enabled", rule.Enabled).
+		Set("trigger_type", rule.Trigger.Type).
+		Set("trigger_params", string(triggerBytes)).
+		Set("actions", string(actionsBytes)).
+		Set("modified_by", rule.ModifiedBy).
+		Set("update_at", rule.UpdateAt).
+		Where(sq.Eq{"id": rule.ID}).
+		Where(sq.Eq{"delete_at": 0})
+
+	result, err := query.Exec()
+	if err != nil {
+		s.logger.Error("Cannot update automation rule", mlog.String("rule_id", rule.ID), mlog.Err(err))
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return model.NewErrNotFound("automation rule ID=" + rule.ID)
+	}
+	return nil
+}
+
+// deleteAutomationRule soft deletes an automation rule.
+func (s *SQLStore) deleteAutomationRule(db sq.BaseRunner, ruleID string, modifiedBy string) error {
+	now := utils.GetMillis()
+
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"automation_rules").
+		Set("modified_by", modifiedBy).
+		Set("update_at", now).
+		Set("delete_at", now).
+		Where(sq.Eq{"id": ruleID}).
+		Where(sq.Eq{"delete_at": 0})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return model.NewErrNotFound("automation rule ID=" + ruleID)
+	}
+	return nil
+}
+
+func (s *SQLStore) getAutomationRule(db sq.BaseRunner, ruleID string) (*model.AutomationRule, error) {
+	query := s.getQueryBuilder(db).
+		Select(automationRuleFields...).
+		From(s.tablePrefix + "automation_rules").
+		Where(sq.Eq{"id": ruleID}).
+		Where(sq.Eq{"delete_at": 0})
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("Cannot fetch automation rule", mlog.String("rule_id", ruleID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	rules, err := s.automationRulesFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, model.NewErrNotFound("automation rule ID=" + ruleID)
+	}
+	return rules[0], nil
+}
+
+func (s *SQLStore) getAutomationRulesForBoard(db sq.BaseRunner, boardID string) ([]*model.AutomationRule, error) {
+	query := s.getQueryBuilder(db).
+		Select(automationRuleFields...).
+		From(s.tablePrefix + "automation_rules").
+		Where(sq.Eq{"board_id": boardID}).
+		Where(sq.Eq{"delete_at": 0}).
+		OrderBy("create_at")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("Cannot fetch automation rules for board", mlog.String("board_id", boardID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.automationRulesFromRows(rows)
+}
+
+// getEnabledAutomationRulesByTrigger fetches the enabled rules of every board for a trigger type.
+func (s *SQLStore) getEnabledAutomationRulesByTrigger(db sq.BaseRunner, triggerType model.AutomationTriggerType) ([]*model.AutomationRule, error) {
+	query := s.getQueryBuilder(db).
+		Select(automationRuleFields...).
+		From(s.tablePrefix+"automation_rules").
+		Where(sq.Eq{"trigger_type": triggerType}).
+		Where(sq.Eq{"enabled": true}).
+		Where(sq.Eq{"delete_at": 0}).
+		OrderBy("board_id", "create_at")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("Cannot fetch automation rules by trigger",
+			mlog.String("trigger_type", string(triggerType)),
+			mlog.Err(err),
+		)
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.automationRulesFromRows(rows)
+}
+
+func (s *SQLStore) insertAutomationExecution(db sq.BaseRunner, execution *model.AutomationExecution) error {
+	if execution.ID == "" {
+		execution.ID = utils.NewID(utils.IDTypeNone)
+	}
+	if execution.CreateAt == 0 {
+		execution.CreateAt = utils.GetMillis()
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"automation_executions").
+		Columns(automationExecutionFields...).
+		Values(
+			execution.ID,
+			execution.RuleID,
+			execution.BoardID,
+			execution.CardID,
+			execution.TriggerType,
+			execution.Status,
+			execution.Message,
+			execution.Depth,
+			execution.CreateAt,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("Cannot insert automation execution",
+			mlog.String("rule_id", execution.RuleID),
+			mlog.Err(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// getAutomationExecutions fetches the execution log of a board, newest first.
+func (s *SQLStore) getAutomationExecutions(db sq.BaseRunner, opts model.QueryAutomationExecutionsOptions) ([]*model.AutomationExecution, error) {
+	query := s.getQueryBuilder(db).
+		Select(automationExecutionFields...).
+		From(s.tablePrefix+"automation_executions").
+		Where(sq.Eq{"board_id": opts.BoardID}).
+		OrderBy("create_at DESC", "id")
+
+	if opts.RuleID != "" {
+		query = query.Where(sq.Eq{"rule_id": opts.RuleID})
+	}
+	if opts.Limit != 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("Cannot fetch automation executions", mlog.String("board_id", opts.BoardID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	executions := []*model.AutomationExecution{}
+	for rows.Next() {
+		var execution model.AutomationExecution
+		err := rows.Scan(
+			&execution.ID,
+			&execution.RuleID,
+			&execution.BoardID,
+			&execution.CardID,
+			&execution.TriggerType,
+			&execution.Status,
+			&execution.Message,
+			&execution.Depth,
+			&execution.CreateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, &execution)
+	}
+	return executions, nil
+}
@@ -53,6 +53,19 @@ func (s *SQLStore) blockFields(tableAlias string) []string {
 	}
 }
 
+// notArchivedCondition returns a condition matching the blocks that are not archived cards,
+// based on the archivedAt field of the blocks.
+func (s *SQLStore) notArchivedCondition(tableAlias string) sq.Sqlizer {
+	if tableAlias != "" && !strings.HasSuffix(tableAlias, ".") {
+		tableAlias += "."
+	}
+
+	if s.dbType == model.PostgresDBType {
+		return sq.Expr(tableAlias + "fields->>'" + model.CardArchivedAtField + "' IS NULL")
+	}
+	return sq.Expr("JSON_EXTRACT("+tableAlias+"fields, ?) IS NULL", "$."+model.CardArchivedAtField)
+}
+
 func (s *SQLStore) getBlocks(db sq.BaseRunner, opts model.QueryBlocksOptions) ([]*model.Block, error) {
 	query := s.getQueryBuilder(db).
 		Select(s.blockFields("")...).
@@ -70,6 +83,10 @@ func (s *SQLStore) getBlocks(db sq.BaseRunner, opts model.QueryBlocksOptions) ([
 		query = query.Where(sq.Eq{"type": opts.BlockType})
 	}
 
+	if opts.ExcludeArchived {
+		query = query.Where(s.notArchivedCondition(""))
+	}
+
 	if opts.Page != 0 {
 		query = query.Offset(uint64(opts.Page * opts.PerPage))
 	}
@@ -315,11 +332,21 @@ func (s *SQLStore) insertBlock(db sq.BaseRunner, block *model.Block, userID stri
 }
 
 func (s *SQLStore) patchBlock(db sq.BaseRunner, blockID string, blockPatch *model.BlockPatch, userID string) error {
+	if blockPatch.ExpectedUpdateAt != 0 {
+		if err := s.lockRowForUpdate(db, "blocks", blockID); err != nil {
+			return err
+		}
+	}
+
 	existingBlock, err := s.getBlock(db, blockID)
 	if err != nil {
 		return err
 	}
 
+	if blockPatch.ExpectedUpdateAt != 0 && existingBlock.UpdateAt != blockPatch.ExpectedUpdateAt {
+		return model.NewErrVersionConflict("block", blockID, existingBlock.UpdateAt, existingBlock)
+	}
+
 	block := blockPatch.Patch(existingBlock)
 	return s.insertBlock(db, block, userID)
 }
@@ -334,6 +361,112 @@ func (s *SQLStore) patchBlocks(db sq.BaseRunner, blockPatches *model.BlockPatchB
 	return nil
 }
 
+// moveBlocks moves blocks from the source board to the board set in each block,
+// keeping their IDs, authorship and creation time.
+func (s *SQLStore) moveBlocks(db sq.BaseRunner, blocks []*model.Block, sourceBoardID string, userID string) error {
+	now := utils.GetMillis()
+
+	for _, block := range blocks {
+		if err := block.IsValid(); err != nil {
+			return fmt.Errorf("error validating block %s: %w", block.ID, err)
+		}
+
+		fieldsJSON, err := json.Marshal(block.Fields)
+		if err != nil {
+			return err
+		}
+
+		block.ModifiedBy = userID
+		block.UpdateAt = now
+
+		query := s.getQueryBuilder(db).Update(s.tablePrefix+"blocks").
+			Where(sq.Eq{"id": block.ID}).
+			Where(sq.Eq{"board_id": sourceBoardID}).
+			Set("board_id", block.BoardID).
+			Set("parent_id", block.ParentID).
+			Set("fields", fieldsJSON).
+			Set("modified_by", block.ModifiedBy).
+			Set("update_at", block.UpdateAt)
+
+		result, err := query.Exec()
+		if err != nil {
+			s.logger.Error(`moveBlocks error occurred while updating block`, mlog.String("blockID", block.ID), mlog.Err(err))
+			return err
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return model.NewErrNotFound("block ID=" + block.ID + " in board ID=" + sourceBoardID)
+		}
+
+		// writing block history
+		historyQuery := s.getQueryBuilder(db).Insert(s.tablePrefix + "blocks_history").
+			SetMap(map[string]interface{}{
+				"channel_id":            "",
+				"id":                    block.ID,
+				"parent_id":             block.ParentID,
+				s.escapeField("schema"): block.Schema,
+				"type":                  block.Type,
+				"title":                 block.Title,
+				"fields":                fieldsJSON,
+				"delete_at":             block.DeleteAt,
+				"created_by":            block.CreatedBy,
+				"modified_by":           block.ModifiedBy,
+				"create_at":             block.CreateAt,
+				"update_at":             block.UpdateAt,
+				"board_id":              block.BoardID,
+			})
+		if _, err := historyQuery.Exec(); err != nil {
+			return err
+		}
+	}
+
+	// the files of the moved blocks now count in the storage usage of their new board.
+	blocksByBoard := map[string][]*model.Block{}
+	for _, block := range blocks {
+		blocksByBoard[block.BoardID] = append(blocksByBoard[block.BoardID], block)
+	}
+	for boardID, boardBlocks := range blocksByBoard {
+		if err := s.moveFileUsage(db, fileIDsFromBlocks(boardBlocks), boardID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyCardBulkChanges applies all the changes of a bulk card request and returns
+// the blocks created by duplicating cards.
+func (s *SQLStore) applyCardBulkChanges(db sq.BaseRunner, changes *model.CardBulkChanges, userID string) ([]*model.Block, error) {
+	if err := s.patchBlocks(db, &changes.Patches, userID); err != nil {
+		return nil, err
+	}
+
+	if len(changes.MovedBlocks) != 0 {
+		if err := s.moveBlocks(db, changes.MovedBlocks, changes.BoardID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cardID := range changes.DeletedCardIDs {
+		if err := s.deleteBlock(db, cardID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	created := []*model.Block{}
+	for _, cardID := range changes.DuplicatedCardIDs {
+		blocks, err := s.duplicateBlock(db, changes.BoardID, cardID, userID, false)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, blocks...)
+	}
+
+	return created, nil
+}
+
 func (s *SQLStore) insertBlocks(db sq.BaseRunner, blocks []*model.Block, userID string) error {
 	for _, block := range blocks {
 		if err := block.IsValid(); err != nil {
@@ -426,6 +559,10 @@ func (s *SQLStore) deleteBlockAndChildren(db sq.BaseRunner, blockID string, modi
 	}
 
 	if fileID != "" {
+		if err := s.releaseFileUsage(db, []string{fileID}); err != nil {
+			return err
+		}
+
 		deleteFileInfoQuery := s.getQueryBuilder(db).
 			Update("FileInfo").
 			Set("DeleteAt", model.GetMillis()).
@@ -519,6 +656,10 @@ func (s *SQLStore) undeleteBlock(db sq.BaseRunner, blockID string, modifiedBy st
 		return err
 	}
 
+	if err := s.restoreFileUsage(db, fileIDsFromBlocks([]*model.Block{block})); err != nil {
+		return err
+	}
+
 	return s.undeleteBlockChildren(db, block.BoardID, block.ID, modifiedBy)
 }
 
@@ -641,10 +782,24 @@ func (s *SQLStore) getBlockHistoryDescendants(db sq.BaseRunner, boardID string,
 	query := s.getQueryBuilder(db).
 		Select(s.blockFields("")...).
 		From(s.tablePrefix + "blocks_history").
-		Where(sq.Eq{"board_id": boardID}).
-		OrderBy("insert_at " + order + ", update_at" + order)
+		Where(sq.Eq{"board_id": boardID})
 
-	if opts.BeforeUpdateAt != 0 {
+	if opts.SortByUpdateAt {
+		query = query.OrderBy("update_at " + order + ", id" + order)
+	} else {
+		query = query.OrderBy("insert_at " + order + ", update_at" + order)
+	}
+
+	if opts.CardID != "" {
+		query = query.Where(sq.Or{sq.Eq{"id": opts.CardID}, sq.Eq{"parent_id": opts.CardID}})
+	}
+
+	if opts.BeforeUpdateAt != 0 && opts.BeforeID != "" {
+		query = query.Where(sq.Or{
+			sq.Lt{"update_at": opts.BeforeUpdateAt},
+			sq.And{sq.Eq{"update_at": opts.BeforeUpdateAt}, sq.Lt{"id": opts.BeforeID}},
+		})
+	} else if opts.BeforeUpdateAt != 0 {
 		query = query.Where(sq.Lt{"update_at": opts.BeforeUpdateAt})
 	}
 
@@ -983,6 +1138,10 @@ func (s *SQLStore) deleteBlockChildren(db sq.BaseRunner, boardID string, parentI
 	}
 
 	if len(fileIDs) > 0 {
+		if err := s.releaseFileUsage(db, fileIDs); err != nil {
+			return err
+		}
+
 		deleteFileInfoQuery := s.getQueryBuilder(db).
 			Update("FileInfo").
 			Set("DeleteAt", model.GetMillis()).
@@ -1096,5 +1255,16 @@ func (s *SQLStore) undeleteBlockChildren(db sq.BaseRunner, boardID string, paren
 	rowsAffected, _ = result.RowsAffected()
 	s.logger.Debug("undeleteBlockChildren - insertHistoryQuery", mlog.Int("rows_affected", rowsAffected))
 
-	return nil
+	// the files of the blocks that were already there are not deleted,
+	// so only the ones of the undeleted blocks are restored
+	var children []*model.Block
+	if parentID != "" {
+		children, err = s.getBlocksWithParent(db, boardID, parentID)
+	} else {
+		children, err = s.getBlocksForBoard(db, boardID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.restoreFileUsage(db, fileIDsFromBlocks(children))
 }
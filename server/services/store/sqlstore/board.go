@@ -402,11 +402,21 @@ func (s *SQLStore) insertBoard(db sq.BaseRunner, board *model.Board, userID stri
 }
 
 func (s *SQLStore) patchBoard(db sq.BaseRunner, boardID string, boardPatch *model.BoardPatch, userID string) (*model.Board, error) {
+	if boardPatch.ExpectedUpdateAt != 0 {
+		if err := s.lockRowForUpdate(db, "boards", boardID); err != nil {
+			return nil, err
+		}
+	}
+
 	existingBoard, err := s.getBoard(db, boardID)
 	if err != nil {
 		return nil, err
 	}
 
+	if boardPatch.ExpectedUpdateAt != 0 && existingBoard.UpdateAt != boardPatch.ExpectedUpdateAt {
+		return nil, model.NewErrVersionConflict("board", boardID, existingBoard.UpdateAt, existingBoard)
+	}
+
 	board := boardPatch.Patch(existingBoard)
 	return s.insertBoard(db, board, userID)
 }
@@ -899,3 +909,90 @@ func (s *SQLStore) getBoardMemberHistory(db sq.BaseRunner, boardID, userID strin
 
 	return memberHistory, nil
 }
+
+// getBoardSnapshot rebuilds a board and its blocks as they were at the given
+// time from the latest history row of each of them.
+func (s *SQLStore) getBoardSnapshot(db sq.BaseRunner, boardID string, at int64) (*model.BoardSnapshot, error) {
+	boardQuery := s.getQueryBuilder(db).
+		Select(boardHistoryFields()...).
+		From(s.tablePrefix+"boards_history").
+		Where(sq.Eq{"id": boardID}).
+		Where(sq.LtOrEq{"update_at": at}).
+		OrderBy("update_at DESC", "insert_at DESC").
+		Limit(1)
+
+	rows, err := boardQuery.Query()
+	if err != nil {
+		s.logger.Error(`getBoardSnapshot ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	boards, err := s.boardsFromRows(rows)
+	s.CloseRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(boards) == 0 || boards[0].DeleteAt != 0 {
+		return nil, model.NewErrNotFound("board ID=" + boardID + " at the requested time")
+	}
+
+	// blocks can move between boards, so every revision of a block that was
+	// on the board at some point is considered, and only the ones whose latest
+	// revision at the given time is on the board and not deleted are kept
+	blocksQuery := s.getQueryBuilder(db).
+		Select(s.blockFields("")...).
+		From(s.tablePrefix+"blocks_history").
+		Where(sq.Expr("id IN (SELECT id FROM "+s.tablePrefix+"blocks_history WHERE board_id = ?)", boardID)).
+		Where(sq.LtOrEq{"update_at": at}).
+		OrderBy("update_at", "insert_at")
+
+	rows, err = blocksQuery.Query()
+	if err != nil {
+		s.logger.Error(`getBoardSnapshot ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	revisions, err := s.blocksFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := map[string]*model.Block{}
+	blockIDs := []string{}
+	for _, revision := range revisions {
+		if _, ok := latest[revision.ID]; !ok {
+			blockIDs = append(blockIDs, revision.ID)
+		}
+		latest[revision.ID] = revision
+	}
+
+	blocks := []*model.Block{}
+	for _, id := range blockIDs {
+		block := latest[id]
+		if block.BoardID == boardID && block.DeleteAt == 0 {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return &model.BoardSnapshot{
+		At:     at,
+		Board:  boards[0],
+		Blocks: blocks,
+	}, nil
+}
+
+func (s *SQLStore) restoreBoard(db sq.BaseRunner, changes *model.BoardRestoreChanges, userID string) (*model.Board, error) {
+	for _, blockID := range changes.DeletedBlockIDs {
+		if err := s.deleteBlock(db, blockID, userID); err != nil {
+			return nil, fmt.Errorf("cannot delete block %s while restoring board %s: %w", blockID, changes.Board.ID, err)
+		}
+	}
+
+	for _, block := range changes.Blocks {
+		if err := s.insertBlock(db, block, userID); err != nil {
+			return nil, fmt.Errorf("cannot restore block %s of board %s: %w", block.ID, changes.Board.ID, err)
+		}
+	}
+
+	return s.insertBoard(db, changes.Board, userID)
+}
@@ -41,6 +41,27 @@ func (s *SQLStore) createBoardsAndBlocksWithAdmin(db sq.BaseRunner, bab *model.B
 	return newBab, members, nil
 }
 
+// createBoardsAndBlocksWithMembers creates the boards and blocks along
+// with the given members, which must belong to the created boards.
+func (s *SQLStore) createBoardsAndBlocksWithMembers(db sq.BaseRunner, bab *model.BoardsAndBlocks, members []*model.BoardMember, userID string) (*model.BoardsAndBlocks, []*model.BoardMember, error) {
+	newBab, err := s.createBoardsAndBlocks(db, bab, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newMembers := []*model.BoardMember{}
+	for _, bm := range members {
+		nbm, err := s.saveMember(db, bm)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		newMembers = append(newMembers, nbm)
+	}
+
+	return newBab, newMembers, nil
+}
+
 func (s *SQLStore) createBoardsAndBlocks(db sq.BaseRunner, bab *model.BoardsAndBlocks, userID string) (*model.BoardsAndBlocks, error) {
 	boards := []*model.Board{}
 	blocks := []*model.Block{}
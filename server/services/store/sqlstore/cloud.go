@@ -27,7 +27,8 @@ func (s *SQLStore) activeCardsQuery(builder sq.StatementBuilderType, selectStr s
 			"b.delete_at":    0,
 			"b.type":         model.TypeCard,
 			"bd.is_template": false,
-		})
+		}).
+		Where(s.notArchivedCondition("b"))
 
 	if cardLimit != 0 {
 		query = query.
@@ -39,6 +40,7 @@ func (s *SQLStore) activeCardsQuery(builder sq.StatementBuilderType, selectStr s
 }
 
 // getUsedCardsCount returns the amount of active cards in the server.
+// Archived cards are not counted.
 func (s *SQLStore) getUsedCardsCount(db sq.BaseRunner) (int, error) {
 	row := s.activeCardsQuery(s.getQueryBuilder(db), "count(b.id)", 0).
 		QueryRow()
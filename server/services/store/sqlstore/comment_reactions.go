@@ -0,0 +1,129 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+var commentReactionFields = []string{
+	"comment_id",
+	"board_id",
+	"user_id",
+	"emoji",
+	"create_at",
+}
+
+func (s *SQLStore) commentReactionsFromRows(rows *sql.Rows) ([]*model.CommentReaction, error) {
+	reactions := []*model.CommentReaction{}
+
+	for rows.Next() {
+		var reaction model.CommentReaction
+		err := rows.Scan(
+			&reaction.CommentID,
+			&reaction.BoardID,
+			&reaction.UserID,
+			&reaction.Emoji,
+			&reaction.CreateAt,
+		)
+		if err != nil {
+			s.logger.Error("commentReactionsFromRows scan error", mlog.Err(err))
+			return nil, err
+		}
+		reactions = append(reactions, &reaction)
+	}
+	return reactions, nil
+}
+
+// addCommentReaction saves a reaction. Adding a reaction that already exists
+// returns the existing one.
+func (s *SQLStore) addCommentReaction(db sq.BaseRunner, reaction *model.CommentReaction) (*model.CommentReaction, error) {
+	rows, err := s.getQueryBuilder(db).
+		Select(commentReactionFields...).
+		From(s.tablePrefix + "comment_reactions").
+		Where(sq.Eq{"comment_id": reaction.CommentID}).
+		Where(sq.Eq{"user_id": reaction.UserID}).
+		Where(sq.Eq{"emoji": reaction.Emoji}).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.commentReactionsFromRows(rows)
+	s.CloseRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) != 0 {
+		return existing[0], nil
+	}
+
+	reactionAdd := *reaction
+	reactionAdd.CreateAt = utils.GetMillis()
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"comment_reactions").
+		Columns(commentReactionFields...).
+		Values(
+			reactionAdd.CommentID,
+			reactionAdd.BoardID,
+			reactionAdd.UserID,
+			reactionAdd.Emoji,
+			reactionAdd.CreateAt,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("Cannot add comment reaction",
+			mlog.String("comment_id", reactionAdd.CommentID),
+			mlog.Err(err),
+		)
+		return nil, err
+	}
+	return &reactionAdd, nil
+}
+
+func (s *SQLStore) deleteCommentReaction(db sq.BaseRunner, commentID, userID, emoji string) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "comment_reactions").
+		Where(sq.Eq{"comment_id": commentID}).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"emoji": emoji})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return model.NewErrNotFound("reaction " + emoji + " on comment ID=" + commentID)
+	}
+	return nil
+}
+
+func (s *SQLStore) getCommentReactions(db sq.BaseRunner, commentIDs []string) ([]*model.CommentReaction, error) {
+	if len(commentIDs) == 0 {
+		return []*model.CommentReaction{}, nil
+	}
+
+	query := s.getQueryBuilder(db).
+		Select(commentReactionFields...).
+		From(s.tablePrefix + "comment_reactions").
+		Where(sq.Eq{"comment_id": commentIDs}).
+		OrderBy("create_at")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error(`getCommentReactions ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.commentReactionsFromRows(rows)
+}
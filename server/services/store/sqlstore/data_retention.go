@@ -67,6 +67,16 @@ func (s *SQLStore) runDataRetention(db sq.BaseRunner, globalRetentionDate int64,
 			PrimaryKeys:   []string{"id"},
 			BoardIDColumn: "board_id",
 		},
+		{
+			Table:         "comment_reactions",
+			PrimaryKeys:   []string{"comment_id"},
+			BoardIDColumn: "board_id",
+		},
+		{
+			Table:         "inbox_items",
+			PrimaryKeys:   []string{"id"},
+			BoardIDColumn: "board_id",
+		},
 	}
 
 	subBuilder := s.getQueryBuilder(db).
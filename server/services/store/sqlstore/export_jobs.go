@@ -0,0 +1,297 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+var exportJobFields = []string{
+	"id",
+	"team_id",
+	"created_by",
+	"status",
+	"board_ids",
+	"exclude_files",
+	"exclude_history",
+	"board_count",
+	"exported_boards",
+	"size",
+	"COALESCE(error, '')",
+	"COALESCE(path, '')",
+	"create_at",
+	"update_at",
+	"expire_at",
+}
+
+func (s *SQLStore) exportJobsFromRows(rows *sql.Rows) ([]*model.ExportJob, error) {
+	jobs := []*model.ExportJob{}
+
+	for rows.Next() {
+		var job model.ExportJob
+		var boardIDs sql.NullString
+
+		err := rows.Scan(
+			&job.ID,
+			&job.TeamID,
+			&job.CreatedBy,
+			&job.Status,
+			&boardIDs,
+			&job.ExcludeFiles,
+			&job.ExcludeHistory,
+			&job.BoardCount,
+			&job.ExportedBoards,
+			&job.Size,
+			&job.Error,
+			&job.Path,
+			&job.CreateAt,
+			&job.UpdateAt,
+			&job.ExpireAt,
+		)
+		if err != nil {
+			s.logger.Error("exportJobsFromRows scan error", mlog.Err(err))
+			return nil, err
+		}
+
+		job.BoardIDs = []string{}
+		if boardIDs.Valid && boardIDs.String != "" {
+			if err = json.Unmarshal([]byte(boardIDs.String), &job.BoardIDs); err != nil {
+				s.logger.Error("export job board ids unmarshal error", mlog.String("job_id", job.ID), mlog.Err(err))
+				return nil, err
+			}
+		}
+
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *SQLStore) queryExportJobs(query sq.SelectBuilder) ([]*model.ExportJob, error) {
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("queryExportJobs error", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.exportJobsFromRows(rows)
+}
+
+func (s *SQLStore) createExportJob(db sq.BaseRunner, job *model.ExportJob) error {
+	boardIDs := job.BoardIDs
+	if boardIDs == nil {
+		boardIDs = []string{}
+	}
+	boardIDsBytes, err := json.Marshal(boardIDs)
+	if err != nil {
+		return err
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"export_jobs").
+		Columns(
+			"id",
+			"team_id",
+			"created_by",
+			"status",
+			"board_ids",
+			"exclude_files",
+			"exclude_history",
+			"board_count",
+			"exported_boards",
+			"size",
+			"error",
+			"path",
+			"create_at",
+			"update_at",
+			"expire_at",
+		).
+		Values(
+			job.ID,
+			job.TeamID,
+			job.CreatedBy,
+			job.Status,
+			string(boardIDsBytes),
+			job.ExcludeFiles,
+			job.ExcludeHistory,
+			job.BoardCount,
+			job.ExportedBoards,
+			job.Size,
+			job.Error,
+			job.Path,
+			job.CreateAt,
+			job.UpdateAt,
+			job.ExpireAt,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("createExportJob error", mlog.String("job_id", job.ID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+// updateExportJob saves the status and progress of an export.
+func (s *SQLStore) updateExportJob(db sq.BaseRunner, job *model.ExportJob) error {
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"export_jobs").
+		Set("status", job.Status).
+		Set("exported_boards", job.ExportedBoards).
+		Set("size", job.Size).
+		Set("error", job.Error).
+		Set("update_at", job.UpdateAt).
+		Set("expire_at", job.ExpireAt).
+		Where(sq.Eq{"id": job.ID})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("updateExportJob error", mlog.String("job_id", job.ID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+// claimExportJob marks a pending export as running, returning false if
+// the export is no longer pending, so that it only runs once.
+func (s *SQLStore) claimExportJob(db sq.BaseRunner, id string, updateAt int64) (bool, error) {
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"export_jobs").
+		Set("status", model.ExportJobStatusRunning).
+		Set("update_at", updateAt).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"status": model.ExportJobStatusPending})
+
+	result, err := query.Exec()
+	if err != nil {
+		s.logger.Error("claimExportJob error", mlog.String("job_id", id), mlog.Err(err))
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// touchExportJobs updates the pending and running exports of a server,
+// so that they are not taken for the exports of a server that stopped.
+func (s *SQLStore) touchExportJobs(db sq.BaseRunner, ids []string, updateAt int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"export_jobs").
+		Set("update_at", updateAt).
+		Where(sq.Eq{"id": ids}).
+		Where(sq.Eq{"status": []string{model.ExportJobStatusPending, model.ExportJobStatusRunning}})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("touchExportJobs error", mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+// requeueExportJob resets a stale export to pending, returning false if
+// it was updated since it was found stale, so that only one server
+// resumes it.
+func (s *SQLStore) requeueExportJob(db sq.BaseRunner, job *model.ExportJob, updateAt int64) (bool, error) {
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"export_jobs").
+		Set("status", model.ExportJobStatusPending).
+		Set("exported_boards", 0).
+		Set("update_at", updateAt).
+		Where(sq.Eq{"id": job.ID}).
+		Where(sq.Eq{"update_at": job.UpdateAt}).
+		Where(sq.Eq{"status": []string{model.ExportJobStatusPending, model.ExportJobStatusRunning}})
+
+	result, err := query.Exec()
+	if err != nil {
+		s.logger.Error("requeueExportJob error", mlog.String("job_id", job.ID), mlog.Err(err))
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (s *SQLStore) getExportJob(db sq.BaseRunner, id string) (*model.ExportJob, error) {
+	query := s.getQueryBuilder(db).
+		Select(exportJobFields...).
+		From(s.tablePrefix + "export_jobs").
+		Where(sq.Eq{"id": id})
+
+	jobs, err := s.queryExportJobs(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, model.NewErrNotFound("export job ID=" + id)
+	}
+	return jobs[0], nil
+}
+
+// getExportJobsForUser returns the exports of a team requested by a
+// user, the most recent first.
+func (s *SQLStore) getExportJobsForUser(db sq.BaseRunner, teamID, userID string) ([]*model.ExportJob, error) {
+	query := s.getQueryBuilder(db).
+		Select(exportJobFields...).
+		From(s.tablePrefix+"export_jobs").
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Eq{"created_by": userID}).
+		OrderBy("create_at DESC", "id")
+
+	return s.queryExportJobs(query)
+}
+
+// getExpiredExportJobs returns the finished exports that expire before
+// a time.
+func (s *SQLStore) getExpiredExportJobs(db sq.BaseRunner, expireBefore int64) ([]*model.ExportJob, error) {
+	query := s.getQueryBuilder(db).
+		Select(exportJobFields...).
+		From(s.tablePrefix+"export_jobs").
+		Where(sq.Gt{"expire_at": 0}).
+		Where(sq.Lt{"expire_at": expireBefore}).
+		OrderBy("expire_at", "id")
+
+	return s.queryExportJobs(query)
+}
+
+// getStaleExportJobs returns the pending and running exports that were
+// not updated since a time, like the ones of a server that stopped.
+func (s *SQLStore) getStaleExportJobs(db sq.BaseRunner, updatedBefore int64) ([]*model.ExportJob, error) {
+	query := s.getQueryBuilder(db).
+		Select(exportJobFields...).
+		From(s.tablePrefix+"export_jobs").
+		Where(sq.Eq{"status": []string{model.ExportJobStatusPending, model.ExportJobStatusRunning}}).
+		Where(sq.Lt{"update_at": updatedBefore}).
+		OrderBy("update_at", "id")
+
+	return s.queryExportJobs(query)
+}
+
+func (s *SQLStore) deleteExportJob(db sq.BaseRunner, id string) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "export_jobs").
+		Where(sq.Eq{"id": id})
+
+	result, err := query.Exec()
+	if err != nil {
+		s.logger.Error("deleteExportJob error", mlog.String("job_id", id), mlog.Err(err))
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.NewErrNotFound("export job ID=" + id)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 
 	sq "github.com/Masterminds/squirrel"
@@ -13,6 +14,13 @@ import (
 )
 
 func (s *SQLStore) saveFileInfo(db sq.BaseRunner, fileInfo *mmModel.FileInfo) error {
+	return s.insertFileInfo(db, fileInfo, "")
+}
+
+// insertFileInfo saves a file info, with the board the file is stored
+// in if it counts in its storage usage, and references the blob of its
+// content if it has one.
+func (s *SQLStore) insertFileInfo(db sq.BaseRunner, fileInfo *mmModel.FileInfo, boardID string) error {
 	query := s.getQueryBuilder(db).
 		Insert(s.tablePrefix+"file_info").
 		Columns(
@@ -24,6 +32,14 @@ func (s *SQLStore) saveFileInfo(db sq.BaseRunner, fileInfo *mmModel.FileInfo) er
 			"delete_at",
 			"path",
 			"archived",
+			"mime_type",
+			"width",
+			"height",
+			"has_preview_image",
+			"thumbnail_path",
+			"preview_path",
+			"creator_id",
+			"board_id",
 		).
 		Values(
 			fileInfo.Id,
@@ -34,6 +50,14 @@ func (s *SQLStore) saveFileInfo(db sq.BaseRunner, fileInfo *mmModel.FileInfo) er
 			fileInfo.DeleteAt,
 			fileInfo.Path,
 			false,
+			fileInfo.MimeType,
+			fileInfo.Width,
+			fileInfo.Height,
+			fileInfo.HasPreviewImage,
+			fileInfo.ThumbnailPath,
+			fileInfo.PreviewPath,
+			fileInfo.CreatorId,
+			boardID,
 		)
 
 	if _, err := query.Exec(); err != nil {
@@ -46,7 +70,7 @@ func (s *SQLStore) saveFileInfo(db sq.BaseRunner, fileInfo *mmModel.FileInfo) er
 		return err
 	}
 
-	return nil
+	return s.acquireFileBlob(db, fileInfo.Path, fileInfo.Size)
 }
 
 func (s *SQLStore) getFileInfo(db sq.BaseRunner, id string) (*mmModel.FileInfo, error) {
@@ -60,6 +84,13 @@ func (s *SQLStore) getFileInfo(db sq.BaseRunner, id string) (*mmModel.FileInfo,
 			"size",
 			"archived",
 			"path",
+			"COALESCE(mime_type, '')",
+			"COALESCE(width, 0)",
+			"COALESCE(height, 0)",
+			"COALESCE(has_preview_image, false)",
+			"COALESCE(thumbnail_path, '')",
+			"COALESCE(preview_path, '')",
+			"COALESCE(creator_id, '')",
 		).
 		From(s.tablePrefix + "file_info").
 		Where(sq.Eq{"Id": id})
@@ -77,6 +108,13 @@ func (s *SQLStore) getFileInfo(db sq.BaseRunner, id string) (*mmModel.FileInfo,
 		&fileInfo.Size,
 		&fileInfo.Archived,
 		&fileInfo.Path,
+		&fileInfo.MimeType,
+		&fileInfo.Width,
+		&fileInfo.Height,
+		&fileInfo.HasPreviewImage,
+		&fileInfo.ThumbnailPath,
+		&fileInfo.PreviewPath,
+		&fileInfo.CreatorId,
 	)
 
 	if err != nil {
@@ -90,3 +128,154 @@ func (s *SQLStore) getFileInfo(db sq.BaseRunner, id string) (*mmModel.FileInfo,
 
 	return &fileInfo, nil
 }
+
+// getFileReferences returns the IDs of the file infos of the files
+// attached to blocks, and to all the saved versions of blocks, which
+// include the deleted blocks, so the files of any version that can
+// still be restored are kept.
+func (s *SQLStore) getFileReferences(db sq.BaseRunner) ([]string, error) {
+	fileTypes := []model.BlockType{model.TypeImage, model.TypeAttachment}
+
+	queries := []sq.SelectBuilder{
+		s.getQueryBuilder(db).
+			Select("fields").
+			From(s.tablePrefix + "blocks").
+			Where(sq.Eq{"type": fileTypes}),
+		s.getQueryBuilder(db).
+			Select("fields").
+			From(s.tablePrefix + "blocks_history").
+			Where(sq.Eq{"type": fileTypes}),
+	}
+
+	fileIDs := []string{}
+	for _, query := range queries {
+		rows, err := query.Query()
+		if err != nil {
+			s.logger.Error("getFileReferences error", mlog.Err(err))
+			return nil, err
+		}
+
+		for rows.Next() {
+			var fieldsJSON string
+			if err := rows.Scan(&fieldsJSON); err != nil {
+				s.CloseRows(rows)
+				return nil, err
+			}
+
+			block := &model.Block{}
+			if err := json.Unmarshal([]byte(fieldsJSON), &block.Fields); err != nil {
+				s.CloseRows(rows)
+				return nil, err
+			}
+			fileIDs = append(fileIDs, fileIDsFromBlocks([]*model.Block{block})...)
+		}
+		err = rows.Err()
+		s.CloseRows(rows)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fileIDs, nil
+}
+
+// getFileInfosCreatedBefore returns the file infos of the files stored
+// before the given time.
+func (s *SQLStore) getFileInfosCreatedBefore(db sq.BaseRunner, createdBefore int64) ([]*mmModel.FileInfo, error) {
+	query := s.getQueryBuilder(db).
+		Select(
+			"id",
+			"create_at",
+			"delete_at",
+			"size",
+			"path",
+			"COALESCE(thumbnail_path, '')",
+			"COALESCE(preview_path, '')",
+		).
+		From(s.tablePrefix + "file_info").
+		Where(sq.Lt{"create_at": createdBefore}).
+		OrderBy("create_at")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("getFileInfosCreatedBefore error", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	fileInfos := []*mmModel.FileInfo{}
+	for rows.Next() {
+		var fileInfo mmModel.FileInfo
+		var path sql.NullString
+		err := rows.Scan(
+			&fileInfo.Id,
+			&fileInfo.CreateAt,
+			&fileInfo.DeleteAt,
+			&fileInfo.Size,
+			&path,
+			&fileInfo.ThumbnailPath,
+			&fileInfo.PreviewPath,
+		)
+		if err != nil {
+			s.logger.Error("getFileInfosCreatedBefore scan error", mlog.Err(err))
+			return nil, err
+		}
+		fileInfo.Path = path.String
+		fileInfos = append(fileInfos, &fileInfo)
+	}
+	return fileInfos, rows.Err()
+}
+
+// deleteFileInfos deletes file infos, removing the files that were
+// still counted from the storage usage of their board, and releasing
+// the blobs of their content.
+func (s *SQLStore) deleteFileInfos(db sq.BaseRunner, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.releaseFileUsage(db, ids); err != nil {
+		return err
+	}
+
+	pathsQuery := s.getQueryBuilder(db).
+		Select("path").
+		From(s.tablePrefix + "file_info").
+		Where(sq.Eq{"id": ids}).
+		Where(sq.Like{"path": model.FileBlobRoot + "/%"})
+
+	rows, err := pathsQuery.Query()
+	if err != nil {
+		s.logger.Error("deleteFileInfos select error", mlog.Err(err))
+		return err
+	}
+	paths := []string{}
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			s.CloseRows(rows)
+			return err
+		}
+		paths = append(paths, path)
+	}
+	err = rows.Err()
+	s.CloseRows(rows)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := s.releaseFileBlob(db, path); err != nil {
+			return err
+		}
+	}
+
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "file_info").
+		Where(sq.Eq{"id": ids})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("deleteFileInfos error", mlog.Int("count", len(ids)), mlog.Err(err))
+		return err
+	}
+	return nil
+}
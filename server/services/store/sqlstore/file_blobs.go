@@ -0,0 +1,271 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// acquireFileBlob adds a reference to the blob stored at a path, if it
+// is the path of a blob, creating the blob on its first reference. A
+// blob claimed by the file garbage collection can't be referenced.
+func (s *SQLStore) acquireFileBlob(db sq.BaseRunner, storagePath string, size int64) error {
+	hash, ok := model.FileBlobHash(storagePath)
+	if !ok {
+		return nil
+	}
+
+	insertQuery := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"file_blobs").
+		Columns("hash", "size", "ref_count", "create_at").
+		Values(hash, size, 0, utils.GetMillis())
+
+	if s.dbType == model.MysqlDBType {
+		insertQuery = insertQuery.Options("IGNORE")
+	} else {
+		insertQuery = insertQuery.Suffix("ON CONFLICT (hash) DO NOTHING")
+	}
+
+	if _, err := insertQuery.Exec(); err != nil {
+		s.logger.Error("acquireFileBlob insert error", mlog.String("hash", hash), mlog.Err(err))
+		return err
+	}
+
+	updateQuery := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"file_blobs").
+		Set("ref_count", sq.Expr("ref_count + 1")).
+		Where(sq.Eq{"hash": hash}).
+		Where(sq.GtOrEq{"ref_count": 0})
+
+	result, err := updateQuery.Exec()
+	if err != nil {
+		s.logger.Error("acquireFileBlob update error", mlog.String("hash", hash), mlog.Err(err))
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrFileBlobRemoving
+	}
+	return nil
+}
+
+// releaseFileBlob removes a reference to the blob stored at a path, if
+// it is the path of a blob. Blobs without references are kept until
+// the file garbage collection removes them.
+func (s *SQLStore) releaseFileBlob(db sq.BaseRunner, storagePath string) error {
+	hash, ok := model.FileBlobHash(storagePath)
+	if !ok {
+		return nil
+	}
+
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"file_blobs").
+		Set("ref_count", sq.Expr("ref_count - 1")).
+		Where(sq.Eq{"hash": hash}).
+		Where(sq.Gt{"ref_count": 0})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("releaseFileBlob error", mlog.String("hash", hash), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+func (s *SQLStore) getFileBlob(db sq.BaseRunner, hash string) (*model.FileBlob, error) {
+	query := s.getQueryBuilder(db).
+		Select("hash", "size", "ref_count", "COALESCE(create_at, 0)").
+		From(s.tablePrefix + "file_blobs").
+		Where(sq.Eq{"hash": hash})
+
+	var blob model.FileBlob
+	err := query.QueryRow().Scan(&blob.Hash, &blob.Size, &blob.RefCount, &blob.CreateAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.NewErrNotFound("file blob hash=" + hash)
+	}
+	if err != nil {
+		s.logger.Error("getFileBlob error", mlog.String("hash", hash), mlog.Err(err))
+		return nil, err
+	}
+	return &blob, nil
+}
+
+func (s *SQLStore) getUnreferencedFileBlobs(db sq.BaseRunner) ([]*model.FileBlob, error) {
+	query := s.getQueryBuilder(db).
+		Select("hash", "size", "ref_count", "COALESCE(create_at, 0)").
+		From(s.tablePrefix + "file_blobs").
+		Where(sq.LtOrEq{"ref_count": 0}).
+		OrderBy("hash")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("getUnreferencedFileBlobs error", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	blobs := []*model.FileBlob{}
+	for rows.Next() {
+		var blob model.FileBlob
+		if err := rows.Scan(&blob.Hash, &blob.Size, &blob.RefCount, &blob.CreateAt); err != nil {
+			s.logger.Error("getUnreferencedFileBlobs scan error", mlog.Err(err))
+			return nil, err
+		}
+		blobs = append(blobs, &blob)
+	}
+	return blobs, rows.Err()
+}
+
+// claimFileBlob marks a blob without references as being removed, so
+// it can't be referenced again, and returns whether it was claimed.
+func (s *SQLStore) claimFileBlob(db sq.BaseRunner, hash string) (bool, error) {
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"file_blobs").
+		Set("ref_count", -1).
+		Where(sq.Eq{"hash": hash}).
+		Where(sq.Eq{"ref_count": 0})
+
+	result, err := query.Exec()
+	if err != nil {
+		s.logger.Error("claimFileBlob error", mlog.String("hash", hash), mlog.Err(err))
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// unclaimFileBlob makes a claimed blob that couldn't be removed
+// available again.
+func (s *SQLStore) unclaimFileBlob(db sq.BaseRunner, hash string) error {
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"file_blobs").
+		Set("ref_count", 0).
+		Where(sq.Eq{"hash": hash}).
+		Where(sq.Lt{"ref_count": 0})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("unclaimFileBlob error", mlog.String("hash", hash), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+// deleteFileBlob deletes a claimed blob once its files are removed.
+func (s *SQLStore) deleteFileBlob(db sq.BaseRunner, hash string) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "file_blobs").
+		Where(sq.Eq{"hash": hash}).
+		Where(sq.Lt{"ref_count": 0})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("deleteFileBlob error", mlog.String("hash", hash), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+// updateFileInfoPath moves a file info to the paths of its file and
+// renditions, moving its reference from its previous blob to its new
+// one.
+func (s *SQLStore) updateFileInfoPath(db sq.BaseRunner, fileInfo *mmModel.FileInfo) error {
+	var oldPath sql.NullString
+	selectQuery := s.getQueryBuilder(db).
+		Select("path").
+		From(s.tablePrefix + "file_info").
+		Where(sq.Eq{"id": fileInfo.Id})
+
+	if err := selectQuery.QueryRow().Scan(&oldPath); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.NewErrNotFound("file info ID=" + fileInfo.Id)
+		}
+		s.logger.Error("updateFileInfoPath select error", mlog.String("id", fileInfo.Id), mlog.Err(err))
+		return err
+	}
+	if oldPath.String == fileInfo.Path {
+		return nil
+	}
+
+	updateQuery := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"file_info").
+		Set("path", fileInfo.Path).
+		Set("thumbnail_path", fileInfo.ThumbnailPath).
+		Set("preview_path", fileInfo.PreviewPath).
+		Set("has_preview_image", fileInfo.HasPreviewImage).
+		Where(sq.Eq{"id": fileInfo.Id})
+
+	if _, err := updateQuery.Exec(); err != nil {
+		s.logger.Error("updateFileInfoPath update error", mlog.String("id", fileInfo.Id), mlog.Err(err))
+		return err
+	}
+
+	if err := s.releaseFileBlob(db, oldPath.String); err != nil {
+		return err
+	}
+	return s.acquireFileBlob(db, fileInfo.Path, fileInfo.Size)
+}
+
+// getLegacyFileInfos returns the file infos of uploads stored before
+// the files were deduplicated, in batches ordered by creation, after
+// the file info with the given creation time and ID. Template files are
+// stored by team and board and are never deduplicated.
+func (s *SQLStore) getLegacyFileInfos(db sq.BaseRunner, afterCreateAt int64, afterID string, limit int) ([]*mmModel.FileInfo, error) {
+	query := s.getQueryBuilder(db).
+		Select(
+			"id",
+			"create_at",
+			"delete_at",
+			"size",
+			"path",
+			"COALESCE(has_preview_image, false)",
+			"COALESCE(thumbnail_path, '')",
+			"COALESCE(preview_path, '')",
+		).
+		From(s.tablePrefix+"file_info").
+		Where(sq.Like{"path": "boards/%"}).
+		Where(sq.NotLike{"path": model.FileBlobRoot + "/%"}).
+		Where(sq.Or{
+			sq.Gt{"create_at": afterCreateAt},
+			sq.And{sq.Eq{"create_at": afterCreateAt}, sq.Gt{"id": afterID}},
+		}).
+		OrderBy("create_at", "id").
+		Limit(uint64(limit))
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("getLegacyFileInfos error", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	fileInfos := []*mmModel.FileInfo{}
+	for rows.Next() {
+		var fileInfo mmModel.FileInfo
+		err := rows.Scan(
+			&fileInfo.Id,
+			&fileInfo.CreateAt,
+			&fileInfo.DeleteAt,
+			&fileInfo.Size,
+			&fileInfo.Path,
+			&fileInfo.HasPreviewImage,
+			&fileInfo.ThumbnailPath,
+			&fileInfo.PreviewPath,
+		)
+		if err != nil {
+			s.logger.Error("getLegacyFileInfos scan error", mlog.Err(err))
+			return nil, err
+		}
+		fileInfos = append(fileInfos, &fileInfo)
+	}
+	return fileInfos, rows.Err()
+}
@@ -0,0 +1,163 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+var inboxItemFields = []string{
+	"id",
+	"user_id",
+	"type",
+	"team_id",
+	"board_id",
+	"card_id",
+	"block_id",
+	"actor_id",
+	"card_title",
+	"message",
+	"create_at",
+	"read_at",
+}
+
+func (s *SQLStore) inboxItemsFromRows(rows *sql.Rows) ([]*model.InboxItem, error) {
+	items := []*model.InboxItem{}
+
+	for rows.Next() {
+		var item model.InboxItem
+		var blockID sql.NullString
+		var actorID sql.NullString
+		var cardTitle sql.NullString
+		var message sql.NullString
+
+		err := rows.Scan(
+			&item.ID,
+			&item.UserID,
+			&item.Type,
+			&item.TeamID,
+			&item.BoardID,
+			&item.CardID,
+			&blockID,
+			&actorID,
+			&cardTitle,
+			&message,
+			&item.CreateAt,
+			&item.ReadAt,
+		)
+		if err != nil {
+			s.logger.Error("inboxItemsFromRows scan error", mlog.Err(err))
+			return nil, err
+		}
+		item.BlockID = blockID.String
+		item.ActorID = actorID.String
+		item.CardTitle = cardTitle.String
+		item.Message = message.String
+		items = append(items, &item)
+	}
+	return items, nil
+}
+
+func (s *SQLStore) insertInboxItems(db sq.BaseRunner, items []*model.InboxItem) error {
+	for _, item := range items {
+		query := s.getQueryBuilder(db).
+			Insert(s.tablePrefix+"inbox_items").
+			Columns(inboxItemFields...).
+			Values(
+				item.ID,
+				item.UserID,
+				item.Type,
+				item.TeamID,
+				item.BoardID,
+				item.CardID,
+				item.BlockID,
+				item.ActorID,
+				item.CardTitle,
+				item.Message,
+				item.CreateAt,
+				item.ReadAt,
+			)
+
+		if _, err := query.Exec(); err != nil {
+			s.logger.Error("Cannot insert inbox item",
+				mlog.String("user_id", item.UserID),
+				mlog.String("card_id", item.CardID),
+				mlog.Err(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLStore) getInboxItems(db sq.BaseRunner, userID string, opts model.QueryInboxItemsOptions) ([]*model.InboxItem, error) {
+	query := s.getQueryBuilder(db).
+		Select(inboxItemFields...).
+		From(s.tablePrefix+"inbox_items").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("create_at DESC", "id DESC")
+
+	if opts.TeamID != "" {
+		query = query.Where(sq.Eq{"team_id": opts.TeamID})
+	}
+	if opts.UnreadOnly {
+		query = query.Where(sq.Eq{"read_at": 0})
+	}
+	if opts.BeforeCreateAt != 0 {
+		query = query.Where(sq.Lt{"create_at": opts.BeforeCreateAt})
+	}
+	if opts.Limit != 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error(`getInboxItems ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.inboxItemsFromRows(rows)
+}
+
+func (s *SQLStore) getInboxUnreadCount(db sq.BaseRunner, userID, teamID string) (int, error) {
+	query := s.getQueryBuilder(db).
+		Select("COUNT(*)").
+		From(s.tablePrefix + "inbox_items").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Eq{"read_at": 0})
+
+	row := query.QueryRow()
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		s.logger.Error(`getInboxUnreadCount ERROR`, mlog.Err(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// markInboxItemsRead marks the unread items of a user in a team as read. If itemIDs
+// is empty all the items of the team are marked.
+func (s *SQLStore) markInboxItemsRead(db sq.BaseRunner, userID, teamID string, itemIDs []string, readAt int64) error {
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"inbox_items").
+		Set("read_at", readAt).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Eq{"read_at": 0})
+
+	if len(itemIDs) != 0 {
+		query = query.Where(sq.Eq{"id": itemIDs})
+	}
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error(`markInboxItemsRead ERROR`, mlog.Err(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.prefix}}automation_executions;
+DROP TABLE IF EXISTS {{.prefix}}automation_rules;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}automation_rules (
+    id VARCHAR(36) NOT NULL,
+    board_id VARCHAR(36) NOT NULL,
+    title TEXT,
+    enabled BOOLEAN,
+    trigger_type VARCHAR(32) NOT NULL,
+    trigger_params TEXT,
+    actions TEXT,
+    created_by VARCHAR(36),
+    modified_by VARCHAR(36),
+    create_at BIGINT,
+    update_at BIGINT,
+    delete_at BIGINT,
+    PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}automation_executions (
+    id VARCHAR(36) NOT NULL,
+    rule_id VARCHAR(36) NOT NULL,
+    board_id VARCHAR(36) NOT NULL,
+    card_id VARCHAR(36),
+    trigger_type VARCHAR(32),
+    status VARCHAR(16),
+    message TEXT,
+    depth INTEGER,
+    create_at BIGINT,
+    PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "automation_rules" "board_id" }}
+{{ createIndexIfNeeded "automation_rules" "trigger_type" }}
+{{ createIndexIfNeeded "automation_executions" "board_id, create_at" }}
+{{ createIndexIfNeeded "automation_executions" "rule_id" }}
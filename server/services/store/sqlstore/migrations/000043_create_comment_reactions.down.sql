@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.prefix}}comment_reactions;
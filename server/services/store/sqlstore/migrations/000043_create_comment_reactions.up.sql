@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}comment_reactions (
+    comment_id VARCHAR(36) NOT NULL,
+    board_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    emoji VARCHAR(64) NOT NULL,
+    create_at BIGINT,
+    PRIMARY KEY (comment_id, user_id, emoji)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "comment_reactions" "board_id" }}
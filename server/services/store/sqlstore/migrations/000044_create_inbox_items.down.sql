@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.prefix}}inbox_items;
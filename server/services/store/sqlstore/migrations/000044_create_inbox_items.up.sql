@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}inbox_items (
+    id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    team_id VARCHAR(36) NOT NULL,
+    board_id VARCHAR(36) NOT NULL,
+    card_id VARCHAR(36) NOT NULL,
+    block_id VARCHAR(36),
+    actor_id VARCHAR(36),
+    card_title TEXT,
+    message TEXT,
+    create_at BIGINT,
+    read_at BIGINT,
+    PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "inbox_items" "user_id, team_id, create_at" }}
+{{ createIndexIfNeeded "inbox_items" "board_id" }}
@@ -0,0 +1,7 @@
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "file_info" "mime_type" "varchar(256)" "" }}
+{{ addColumnIfNeeded "file_info" "width" "int" "default 0" }}
+{{ addColumnIfNeeded "file_info" "height" "int" "default 0" }}
+{{ addColumnIfNeeded "file_info" "has_preview_image" "boolean" "default false" }}
+{{ addColumnIfNeeded "file_info" "thumbnail_path" "varchar(512)" "" }}
+{{ addColumnIfNeeded "file_info" "preview_path" "varchar(512)" "" }}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.prefix}}storage_quotas;
+DROP TABLE IF EXISTS {{.prefix}}file_usage;
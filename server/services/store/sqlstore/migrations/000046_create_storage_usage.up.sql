@@ -0,0 +1,25 @@
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "file_info" "creator_id" "varchar(36)" "" }}
+{{ addColumnIfNeeded "file_info" "board_id" "varchar(36)" "" }}
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}file_usage (
+    board_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    team_id VARCHAR(36) NOT NULL,
+    file_count BIGINT NOT NULL DEFAULT 0,
+    total_size BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (board_id, user_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}storage_quotas (
+    scope VARCHAR(16) NOT NULL,
+    scope_id VARCHAR(36) NOT NULL,
+    team_id VARCHAR(36) NOT NULL,
+    max_size BIGINT NOT NULL,
+    update_at BIGINT,
+    PRIMARY KEY (scope, scope_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "file_usage" "team_id" }}
+{{ createIndexIfNeeded "storage_quotas" "team_id" }}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.prefix}}upload_policies;
+DROP TABLE IF EXISTS {{.prefix}}file_scan_results;
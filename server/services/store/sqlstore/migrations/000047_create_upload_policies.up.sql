@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}upload_policies (
+    team_id VARCHAR(36) NOT NULL,
+    allowed_mime_types TEXT,
+    denied_mime_types TEXT,
+    allowed_extensions TEXT,
+    denied_extensions TEXT,
+    update_at BIGINT,
+    PRIMARY KEY (team_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}file_scan_results (
+    file_id VARCHAR(36) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    signature TEXT,
+    quarantine_path TEXT,
+    scanned_at BIGINT,
+    PRIMARY KEY (file_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
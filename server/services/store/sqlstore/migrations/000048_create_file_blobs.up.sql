@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}file_blobs (
+    hash VARCHAR(64) NOT NULL,
+    size BIGINT NOT NULL,
+    ref_count BIGINT NOT NULL DEFAULT 0,
+    create_at BIGINT,
+    PRIMARY KEY (hash)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "file_blobs" "ref_count" }}
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}export_jobs (
+    id VARCHAR(36) NOT NULL,
+    team_id VARCHAR(36) NOT NULL,
+    created_by VARCHAR(36) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    board_ids TEXT,
+    exclude_files BOOLEAN,
+    exclude_history BOOLEAN,
+    board_count INTEGER,
+    exported_boards INTEGER,
+    size BIGINT,
+    error TEXT,
+    path TEXT,
+    create_at BIGINT,
+    update_at BIGINT,
+    expire_at BIGINT,
+    PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "export_jobs" "team_id, created_by" }}
+{{ createIndexIfNeeded "export_jobs" "status, update_at" }}
+{{ createIndexIfNeeded "export_jobs" "expire_at" }}
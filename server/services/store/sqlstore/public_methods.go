@@ -22,6 +22,16 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 )
 
+func (s *SQLStore) AddCommentReaction(reaction *model.CommentReaction) (*model.CommentReaction, error) {
+	return s.addCommentReaction(s.db, reaction)
+
+}
+
+func (s *SQLStore) AddFileUsage(usage *model.FileUsage) error {
+	return s.addFileUsage(s.db, usage)
+
+}
+
 func (s *SQLStore) AddUpdateCategoryBoard(userID string, categoryID string, boardIDs []string) error {
 	if s.dbType == model.SqliteDBType {
 		return s.addUpdateCategoryBoard(s.db, userID, categoryID, boardIDs)
@@ -46,16 +56,60 @@ func (s *SQLStore) AddUpdateCategoryBoard(userID string, categoryID string, boar
 
 }
 
+func (s *SQLStore) ApplyCardBulkChanges(changes *model.CardBulkChanges, userID string) ([]*model.Block, error) {
+	if s.dbType == model.SqliteDBType {
+		return s.applyCardBulkChanges(s.db, changes, userID)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return nil, txErr
+	}
+	result, err := s.applyCardBulkChanges(tx, changes, userID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "ApplyCardBulkChanges"))
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+
+}
+
 func (s *SQLStore) CanSeeUser(seerID string, seenID string) (bool, error) {
 	return s.canSeeUser(s.db, seerID, seenID)
 
 }
 
+func (s *SQLStore) ClaimExportJob(id string, updateAt int64) (bool, error) {
+	return s.claimExportJob(s.db, id, updateAt)
+
+}
+
+func (s *SQLStore) ClaimFileBlob(hash string) (bool, error) {
+	return s.claimFileBlob(s.db, hash)
+
+}
+
 func (s *SQLStore) CleanUpSessions(expireTime int64) error {
 	return s.cleanUpSessions(s.db, expireTime)
 
 }
 
+func (s *SQLStore) CompareAndSetSystemSetting(key string, expected string, value string) (bool, error) {
+	return s.compareAndSetSystemSetting(s.db, key, expected, value)
+
+}
+
+func (s *SQLStore) CreateAutomationRule(rule *model.AutomationRule) (*model.AutomationRule, error) {
+	return s.createAutomationRule(s.db, rule)
+
+}
+
 func (s *SQLStore) CreateBoardsAndBlocks(bab *model.BoardsAndBlocks, userID string) (*model.BoardsAndBlocks, error) {
 	if s.dbType == model.SqliteDBType {
 		return s.createBoardsAndBlocks(s.db, bab, userID)
@@ -104,6 +158,30 @@ func (s *SQLStore) CreateBoardsAndBlocksWithAdmin(bab *model.BoardsAndBlocks, us
 
 }
 
+func (s *SQLStore) CreateBoardsAndBlocksWithMembers(bab *model.BoardsAndBlocks, members []*model.BoardMember, userID string) (*model.BoardsAndBlocks, []*model.BoardMember, error) {
+	if s.dbType == model.SqliteDBType {
+		return s.createBoardsAndBlocksWithMembers(s.db, bab, members, userID)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return nil, nil, txErr
+	}
+	result, resultVar1, err := s.createBoardsAndBlocksWithMembers(tx, bab, members, userID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "CreateBoardsAndBlocksWithMembers"))
+		}
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return result, resultVar1, nil
+
+}
+
 func (s *SQLStore) CreateCategory(category model.Category) error {
 	if s.dbType == model.SqliteDBType {
 		return s.createCategory(s.db, category)
@@ -128,6 +206,11 @@ func (s *SQLStore) CreateCategory(category model.Category) error {
 
 }
 
+func (s *SQLStore) CreateExportJob(job *model.ExportJob) error {
+	return s.createExportJob(s.db, job)
+
+}
+
 func (s *SQLStore) CreateSession(session *model.Session) error {
 	return s.createSession(s.db, session)
 
@@ -143,6 +226,11 @@ func (s *SQLStore) CreateUser(user *model.User) (*model.User, error) {
 
 }
 
+func (s *SQLStore) DeleteAutomationRule(ruleID string, modifiedBy string) error {
+	return s.deleteAutomationRule(s.db, ruleID, modifiedBy)
+
+}
+
 func (s *SQLStore) DeleteBlock(blockID string, modifiedBy string) error {
 	if s.dbType == model.SqliteDBType {
 		return s.deleteBlock(s.db, blockID, modifiedBy)
@@ -230,6 +318,45 @@ func (s *SQLStore) DeleteCategory(categoryID string, userID string, teamID strin
 
 }
 
+func (s *SQLStore) DeleteCommentReaction(commentID string, userID string, emoji string) error {
+	return s.deleteCommentReaction(s.db, commentID, userID, emoji)
+
+}
+
+func (s *SQLStore) DeleteExportJob(id string) error {
+	return s.deleteExportJob(s.db, id)
+
+}
+
+func (s *SQLStore) DeleteFileBlob(hash string) error {
+	return s.deleteFileBlob(s.db, hash)
+
+}
+
+func (s *SQLStore) DeleteFileInfos(ids []string) error {
+	if s.dbType == model.SqliteDBType {
+		return s.deleteFileInfos(s.db, ids)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.deleteFileInfos(tx, ids)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "DeleteFileInfos"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
 func (s *SQLStore) DeleteMember(boardID string, userID string) error {
 	return s.deleteMember(s.db, boardID, userID)
 
@@ -245,11 +372,21 @@ func (s *SQLStore) DeleteSession(sessionID string) error {
 
 }
 
+func (s *SQLStore) DeleteStorageQuota(scope model.StorageQuotaScope, scopeID string) error {
+	return s.deleteStorageQuota(s.db, scope, scopeID)
+
+}
+
 func (s *SQLStore) DeleteSubscription(blockID string, subscriberID string) error {
 	return s.deleteSubscription(s.db, blockID, subscriberID)
 
 }
 
+func (s *SQLStore) DeleteUploadPolicy(teamID string) error {
+	return s.deleteUploadPolicy(s.db, teamID)
+
+}
+
 func (s *SQLStore) DuplicateBlock(boardID string, blockID string, userID string, asTemplate bool) ([]*model.Block, error) {
 	if s.dbType == model.SqliteDBType {
 		return s.duplicateBlock(s.db, boardID, blockID, userID, asTemplate)
@@ -308,6 +445,21 @@ func (s *SQLStore) GetAllTeams() ([]*model.Team, error) {
 
 }
 
+func (s *SQLStore) GetAutomationExecutions(opts model.QueryAutomationExecutionsOptions) ([]*model.AutomationExecution, error) {
+	return s.getAutomationExecutions(s.db, opts)
+
+}
+
+func (s *SQLStore) GetAutomationRule(ruleID string) (*model.AutomationRule, error) {
+	return s.getAutomationRule(s.db, ruleID)
+
+}
+
+func (s *SQLStore) GetAutomationRulesForBoard(boardID string) ([]*model.AutomationRule, error) {
+	return s.getAutomationRulesForBoard(s.db, boardID)
+
+}
+
 func (s *SQLStore) GetBlock(blockID string) (*model.Block, error) {
 	return s.getBlock(s.db, blockID)
 
@@ -398,6 +550,11 @@ func (s *SQLStore) GetBoardMemberHistory(boardID string, userID string, limit ui
 
 }
 
+func (s *SQLStore) GetBoardSnapshot(boardID string, at int64) (*model.BoardSnapshot, error) {
+	return s.getBoardSnapshot(s.db, boardID, at)
+
+}
+
 func (s *SQLStore) GetBoardsComplianceHistory(opts model.QueryBoardsComplianceHistoryOptions) ([]*model.BoardHistory, bool, error) {
 	return s.getBoardsComplianceHistory(s.db, opts)
 
@@ -433,11 +590,81 @@ func (s *SQLStore) GetChannel(teamID string, channelID string) (*mmModel.Channel
 
 }
 
+func (s *SQLStore) GetCommentReactions(commentIDs []string) ([]*model.CommentReaction, error) {
+	return s.getCommentReactions(s.db, commentIDs)
+
+}
+
+func (s *SQLStore) GetEnabledAutomationRulesByTrigger(triggerType model.AutomationTriggerType) ([]*model.AutomationRule, error) {
+	return s.getEnabledAutomationRulesByTrigger(s.db, triggerType)
+
+}
+
+func (s *SQLStore) GetExpiredExportJobs(expireBefore int64) ([]*model.ExportJob, error) {
+	return s.getExpiredExportJobs(s.db, expireBefore)
+
+}
+
+func (s *SQLStore) GetExportJob(id string) (*model.ExportJob, error) {
+	return s.getExportJob(s.db, id)
+
+}
+
+func (s *SQLStore) GetExportJobsForUser(teamID string, userID string) ([]*model.ExportJob, error) {
+	return s.getExportJobsForUser(s.db, teamID, userID)
+
+}
+
+func (s *SQLStore) GetFileBlob(hash string) (*model.FileBlob, error) {
+	return s.getFileBlob(s.db, hash)
+
+}
+
 func (s *SQLStore) GetFileInfo(id string) (*mmModel.FileInfo, error) {
 	return s.getFileInfo(s.db, id)
 
 }
 
+func (s *SQLStore) GetFileInfosCreatedBefore(createdBefore int64) ([]*mmModel.FileInfo, error) {
+	return s.getFileInfosCreatedBefore(s.db, createdBefore)
+
+}
+
+func (s *SQLStore) GetFileReferences() ([]string, error) {
+	return s.getFileReferences(s.db)
+
+}
+
+func (s *SQLStore) GetFileScanResult(fileID string) (*model.FileScanResult, error) {
+	return s.getFileScanResult(s.db, fileID)
+
+}
+
+func (s *SQLStore) GetFileUsage(teamID string) ([]*model.FileUsage, error) {
+	return s.getFileUsage(s.db, teamID)
+
+}
+
+func (s *SQLStore) GetFileUsageTotals(teamID string, boardID string) (int64, int64, error) {
+	return s.getFileUsageTotals(s.db, teamID, boardID)
+
+}
+
+func (s *SQLStore) GetInboxItems(userID string, opts model.QueryInboxItemsOptions) ([]*model.InboxItem, error) {
+	return s.getInboxItems(s.db, userID, opts)
+
+}
+
+func (s *SQLStore) GetInboxUnreadCount(userID string, teamID string) (int, error) {
+	return s.getInboxUnreadCount(s.db, userID, teamID)
+
+}
+
+func (s *SQLStore) GetLegacyFileInfos(afterCreateAt int64, afterID string, limit int) ([]*mmModel.FileInfo, error) {
+	return s.getLegacyFileInfos(s.db, afterCreateAt, afterID, limit)
+
+}
+
 func (s *SQLStore) GetLicense() *mmModel.License {
 	return s.getLicense(s.db)
 
@@ -483,6 +710,21 @@ func (s *SQLStore) GetSharing(rootID string) (*model.Sharing, error) {
 
 }
 
+func (s *SQLStore) GetStaleExportJobs(updatedBefore int64) ([]*model.ExportJob, error) {
+	return s.getStaleExportJobs(s.db, updatedBefore)
+
+}
+
+func (s *SQLStore) GetStorageQuota(scope model.StorageQuotaScope, scopeID string) (*model.StorageQuota, error) {
+	return s.getStorageQuota(s.db, scope, scopeID)
+
+}
+
+func (s *SQLStore) GetStorageQuotas(teamID string) ([]*model.StorageQuota, error) {
+	return s.getStorageQuotas(s.db, teamID)
+
+}
+
 func (s *SQLStore) GetSubTree2(boardID string, blockID string, opts model.QuerySubtreeOptions) ([]*model.Block, error) {
 	return s.getSubTree2(s.db, boardID, blockID, opts)
 
@@ -533,11 +775,26 @@ func (s *SQLStore) GetTeamsForUser(userID string) ([]*model.Team, error) {
 
 }
 
+func (s *SQLStore) GetTelegramNotificationPreferences(userID string) (map[string]bool, error) {
+	return s.getTelegramNotificationPreferences(s.db, userID)
+
+}
+
 func (s *SQLStore) GetTemplateBoards(teamID string, userID string) ([]*model.Board, error) {
 	return s.getTemplateBoards(s.db, teamID, userID)
 
 }
 
+func (s *SQLStore) GetUnreferencedFileBlobs() ([]*model.FileBlob, error) {
+	return s.getUnreferencedFileBlobs(s.db)
+
+}
+
+func (s *SQLStore) GetUploadPolicy(teamID string) (*model.UploadPolicy, error) {
+	return s.getUploadPolicy(s.db, teamID)
+
+}
+
 func (s *SQLStore) GetUsedCardsCount() (int, error) {
 	return s.getUsedCardsCount(s.db)
 
@@ -573,16 +830,6 @@ func (s *SQLStore) GetUserPreferences(userID string) (mmModel.Preferences, error
 
 }
 
-func (s *SQLStore) GetTelegramNotificationPreferences(userID string) (map[string]bool, error) {
-	return s.getTelegramNotificationPreferences(s.db, userID)
-
-}
-
-func (s *SQLStore) UpsertTelegramNotificationPreferences(userID string, prefs map[string]bool) error {
-	return s.upsertTelegramNotificationPreferences(s.db, userID, prefs)
-
-}
-
 func (s *SQLStore) GetUserTimezone(userID string) (string, error) {
 	return s.getUserTimezone(s.db, userID)
 
@@ -598,6 +845,11 @@ func (s *SQLStore) GetUsersList(userIDs []string, showEmail bool, showName bool)
 
 }
 
+func (s *SQLStore) InsertAutomationExecution(execution *model.AutomationExecution) error {
+	return s.insertAutomationExecution(s.db, execution)
+
+}
+
 func (s *SQLStore) InsertBlock(block *model.Block, userID string) error {
 	if s.dbType == model.SqliteDBType {
 		return s.insertBlock(s.db, block, userID)
@@ -675,6 +927,59 @@ func (s *SQLStore) InsertBoardWithAdmin(board *model.Board, userID string) (*mod
 
 }
 
+func (s *SQLStore) InsertInboxItems(items []*model.InboxItem) error {
+	if s.dbType == model.SqliteDBType {
+		return s.insertInboxItems(s.db, items)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.insertInboxItems(tx, items)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "InsertInboxItems"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (s *SQLStore) MarkInboxItemsRead(userID string, teamID string, itemIDs []string, readAt int64) error {
+	return s.markInboxItemsRead(s.db, userID, teamID, itemIDs, readAt)
+
+}
+
+func (s *SQLStore) MoveBlocks(blocks []*model.Block, sourceBoardID string, userID string) error {
+	if s.dbType == model.SqliteDBType {
+		return s.moveBlocks(s.db, blocks, sourceBoardID, userID)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.moveBlocks(tx, blocks, sourceBoardID, userID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "MoveBlocks"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
 func (s *SQLStore) PatchBlock(blockID string, blockPatch *model.BlockPatch, userID string) error {
 	if s.dbType == model.SqliteDBType {
 		return s.patchBlock(s.db, blockID, blockPatch, userID)
@@ -801,6 +1106,35 @@ func (s *SQLStore) ReorderCategoryBoards(categoryID string, newBoardsOrder []str
 
 }
 
+func (s *SQLStore) RequeueExportJob(job *model.ExportJob, updateAt int64) (bool, error) {
+	return s.requeueExportJob(s.db, job, updateAt)
+
+}
+
+func (s *SQLStore) RestoreBoard(changes *model.BoardRestoreChanges, userID string) (*model.Board, error) {
+	if s.dbType == model.SqliteDBType {
+		return s.restoreBoard(s.db, changes, userID)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return nil, txErr
+	}
+	result, err := s.restoreBoard(tx, changes, userID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "RestoreBoard"))
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+
+}
+
 func (s *SQLStore) RunDataRetention(globalRetentionDate int64, batchSize int64) (int64, error) {
 	if s.dbType == model.SqliteDBType {
 		return s.runDataRetention(s.db, globalRetentionDate, batchSize)
@@ -826,7 +1160,74 @@ func (s *SQLStore) RunDataRetention(globalRetentionDate int64, batchSize int64)
 }
 
 func (s *SQLStore) SaveFileInfo(fileInfo *mmModel.FileInfo) error {
-	return s.saveFileInfo(s.db, fileInfo)
+	if s.dbType == model.SqliteDBType {
+		return s.saveFileInfo(s.db, fileInfo)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.saveFileInfo(tx, fileInfo)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "SaveFileInfo"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (s *SQLStore) SaveFileInfoWithUsage(fileInfo *mmModel.FileInfo, teamID string, boardID string) error {
+	if s.dbType == model.SqliteDBType {
+		return s.saveFileInfoWithUsage(s.db, fileInfo, teamID, boardID)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.saveFileInfoWithUsage(tx, fileInfo, teamID, boardID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "SaveFileInfoWithUsage"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (s *SQLStore) SaveFileScanResult(result *model.FileScanResult) error {
+	if s.dbType == model.SqliteDBType {
+		return s.saveFileScanResult(s.db, result)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.saveFileScanResult(tx, result)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "SaveFileScanResult"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
 
 }
 
@@ -835,6 +1236,30 @@ func (s *SQLStore) SaveMember(bm *model.BoardMember) (*model.BoardMember, error)
 
 }
 
+func (s *SQLStore) SaveUploadPolicy(policy *model.UploadPolicy) error {
+	if s.dbType == model.SqliteDBType {
+		return s.saveUploadPolicy(s.db, policy)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.saveUploadPolicy(tx, policy)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "SaveUploadPolicy"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
 func (s *SQLStore) SearchBoardsForUser(term string, searchField model.BoardSearchField, userID string, includePublicBoards bool) ([]*model.Board, error) {
 	return s.searchBoardsForUser(s.db, term, searchField, userID, includePublicBoards)
 
@@ -865,11 +1290,45 @@ func (s *SQLStore) SetBoardVisibility(userID string, categoryID string, boardID
 
 }
 
+func (s *SQLStore) SetStorageQuota(quota *model.StorageQuota) error {
+	if s.dbType == model.SqliteDBType {
+		return s.setStorageQuota(s.db, quota)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.setStorageQuota(tx, quota)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "SetStorageQuota"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
 func (s *SQLStore) SetSystemSetting(key string, value string) error {
 	return s.setSystemSetting(s.db, key, value)
 
 }
 
+func (s *SQLStore) TouchExportJobs(ids []string, updateAt int64) error {
+	return s.touchExportJobs(s.db, ids, updateAt)
+
+}
+
+func (s *SQLStore) UnclaimFileBlob(hash string) error {
+	return s.unclaimFileBlob(s.db, hash)
+
+}
+
 func (s *SQLStore) UndeleteBlock(blockID string, modifiedBy string) error {
 	if s.dbType == model.SqliteDBType {
 		return s.undeleteBlock(s.db, blockID, modifiedBy)
@@ -918,6 +1377,11 @@ func (s *SQLStore) UndeleteBoard(boardID string, modifiedBy string) error {
 
 }
 
+func (s *SQLStore) UpdateAutomationRule(rule *model.AutomationRule) error {
+	return s.updateAutomationRule(s.db, rule)
+
+}
+
 func (s *SQLStore) UpdateCardLimitTimestamp(cardLimit int) (int64, error) {
 	return s.updateCardLimitTimestamp(s.db, cardLimit)
 
@@ -928,6 +1392,35 @@ func (s *SQLStore) UpdateCategory(category model.Category) error {
 
 }
 
+func (s *SQLStore) UpdateExportJob(job *model.ExportJob) error {
+	return s.updateExportJob(s.db, job)
+
+}
+
+func (s *SQLStore) UpdateFileInfoPath(fileInfo *mmModel.FileInfo) error {
+	if s.dbType == model.SqliteDBType {
+		return s.updateFileInfoPath(s.db, fileInfo)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.updateFileInfoPath(tx, fileInfo)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "UpdateFileInfoPath"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
 func (s *SQLStore) UpdateSession(session *model.Session) error {
 	return s.updateSession(s.db, session)
 
@@ -972,3 +1465,8 @@ func (s *SQLStore) UpsertTeamSignupToken(team model.Team) error {
 	return s.upsertTeamSignupToken(s.db, team)
 
 }
+
+func (s *SQLStore) UpsertTelegramNotificationPreferences(userID string, prefs map[string]bool) error {
+	return s.upsertTelegramNotificationPreferences(s.db, userID, prefs)
+
+}
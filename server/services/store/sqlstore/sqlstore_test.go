@@ -29,6 +29,12 @@ func TestSQLStore(t *testing.T) {
 	t.Run("StoreTestCategoryStore", func(t *testing.T) { storetests.StoreTestCategoryStore(t, SetupTests) })
 	t.Run("StoreTestCategoryBoardsStore", func(t *testing.T) { storetests.StoreTestCategoryBoardsStore(t, SetupTests) })
 	t.Run("ComplianceHistoryStore", func(t *testing.T) { storetests.StoreTestComplianceHistoryStore(t, SetupTests) })
+	t.Run("AutomationStore", func(t *testing.T) { storetests.StoreTestAutomationStore(t, SetupTests) })
+	t.Run("CommentReactionsStore", func(t *testing.T) { storetests.StoreTestCommentReactionsStore(t, SetupTests) })
+	t.Run("InboxStore", func(t *testing.T) { storetests.StoreTestInboxStore(t, SetupTests) })
+	t.Run("StorageStore", func(t *testing.T) { storetests.StoreTestStorageStore(t, SetupTests) })
+	t.Run("UploadPolicyStore", func(t *testing.T) { storetests.StoreTestUploadPolicyStore(t, SetupTests) })
+	t.Run("ExportJobStore", func(t *testing.T) { storetests.StoreTestExportJobStore(t, SetupTests) })
 }
 
 //  tests for  utility functions inside sqlstore.go
@@ -0,0 +1,375 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// saveFileInfoWithUsage saves the file info of a file stored in a
+// board and adds it to the storage usage of its creator in the board.
+func (s *SQLStore) saveFileInfoWithUsage(db sq.BaseRunner, fileInfo *mmModel.FileInfo, teamID, boardID string) error {
+	if err := s.insertFileInfo(db, fileInfo, boardID); err != nil {
+		return err
+	}
+
+	return s.addFileUsage(db, &model.FileUsage{
+		TeamID:    teamID,
+		BoardID:   boardID,
+		UserID:    fileInfo.CreatorId,
+		FileCount: 1,
+		TotalSize: fileInfo.Size,
+	})
+}
+
+// addFileUsage adds the deltas of a usage to the running totals of
+// its user in its board. Negative deltas for a user without totals
+// come from files stored before the usage was recorded and are ignored.
+// The totals are created or added to in one statement, so that
+// concurrent uploads don't both create them.
+func (s *SQLStore) addFileUsage(db sq.BaseRunner, usage *model.FileUsage) error {
+	if usage.FileCount <= 0 {
+		updateQuery := s.getQueryBuilder(db).
+			Update(s.tablePrefix+"file_usage").
+			Set("file_count", sq.Expr("file_count + ?", usage.FileCount)).
+			Set("total_size", sq.Expr("total_size + ?", usage.TotalSize)).
+			Where(sq.Eq{"board_id": usage.BoardID}).
+			Where(sq.Eq{"user_id": usage.UserID})
+
+		if _, err := updateQuery.Exec(); err != nil {
+			s.logger.Error("addFileUsage update error", mlog.String("board_id", usage.BoardID), mlog.Err(err))
+			return err
+		}
+		return nil
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"file_usage").
+		Columns("board_id", "user_id", "team_id", "file_count", "total_size").
+		Values(usage.BoardID, usage.UserID, usage.TeamID, usage.FileCount, usage.TotalSize)
+
+	if s.dbType == model.MysqlDBType {
+		query = query.Suffix(
+			"ON DUPLICATE KEY UPDATE file_count = file_count + ?, total_size = total_size + ?",
+			usage.FileCount, usage.TotalSize)
+	} else {
+		table := s.tablePrefix + "file_usage"
+		query = query.Suffix(
+			`ON CONFLICT (board_id, user_id)
+			 DO UPDATE SET file_count = ` + table + `.file_count + EXCLUDED.file_count,
+			   total_size = ` + table + `.total_size + EXCLUDED.total_size`,
+		)
+	}
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("addFileUsage error", mlog.String("board_id", usage.BoardID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+// releaseFileUsage marks the files of deleted blocks as deleted and
+// removes them from the storage usage of the board.
+func (s *SQLStore) releaseFileUsage(db sq.BaseRunner, fileIDs []string) error {
+	return s.updateFileUsageForFiles(db, fileIDs, true)
+}
+
+// restoreFileUsage marks the files of undeleted blocks as not deleted
+// and adds them back to the storage usage of the board.
+func (s *SQLStore) restoreFileUsage(db sq.BaseRunner, fileIDs []string) error {
+	return s.updateFileUsageForFiles(db, fileIDs, false)
+}
+
+func (s *SQLStore) updateFileUsageForFiles(db sq.BaseRunner, fileIDs []string, deleted bool) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	deleteAtCondition := sq.Sqlizer(sq.NotEq{"delete_at": 0})
+	deleteAt := int64(0)
+	sign := int64(1)
+	if deleted {
+		deleteAtCondition = sq.Eq{"delete_at": 0}
+		deleteAt = utils.GetMillis()
+		sign = -1
+	}
+
+	// only the files saved with a board have been counted
+	query := s.getQueryBuilder(db).
+		Select("id", "board_id", "creator_id", "size").
+		From(s.tablePrefix + "file_info").
+		Where(sq.Eq{"id": fileIDs}).
+		Where(deleteAtCondition).
+		Where(sq.NotEq{"board_id": ""}).
+		Where(sq.NotEq{"creator_id": ""})
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("updateFileUsageForFiles select error", mlog.Err(err))
+		return err
+	}
+	defer s.CloseRows(rows)
+
+	ids := []string{}
+	usages := map[[2]string]*model.FileUsage{}
+	for rows.Next() {
+		var id, boardID, userID string
+		var size int64
+		if err := rows.Scan(&id, &boardID, &userID, &size); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+
+		key := [2]string{boardID, userID}
+		usage, ok := usages[key]
+		if !ok {
+			usage = &model.FileUsage{BoardID: boardID, UserID: userID}
+			usages[key] = usage
+		}
+		usage.FileCount += sign
+		usage.TotalSize += sign * size
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	updateQuery := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"file_info").
+		Set("delete_at", deleteAt).
+		Where(sq.Eq{"id": ids})
+	if _, err := updateQuery.Exec(); err != nil {
+		s.logger.Error("updateFileUsageForFiles update error", mlog.Err(err))
+		return err
+	}
+
+	for _, usage := range usages {
+		if err := s.addFileUsage(db, usage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveFileUsage moves the files of blocks moved to another board to the
+// storage usage of that board.
+func (s *SQLStore) moveFileUsage(db sq.BaseRunner, fileIDs []string, boardID string) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	board, err := s.getBoard(db, boardID)
+	if err != nil {
+		return err
+	}
+
+	// only the files saved with a board have been counted
+	query := s.getQueryBuilder(db).
+		Select("id", "board_id", "creator_id", "size").
+		From(s.tablePrefix + "file_info").
+		Where(sq.Eq{"id": fileIDs}).
+		Where(sq.Eq{"delete_at": 0}).
+		Where(sq.NotEq{"board_id": ""}).
+		Where(sq.NotEq{"board_id": boardID}).
+		Where(sq.NotEq{"creator_id": ""})
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("moveFileUsage select error", mlog.Err(err))
+		return err
+	}
+	defer s.CloseRows(rows)
+
+	ids := []string{}
+	usages := map[[2]string]*model.FileUsage{}
+	addUsage := func(teamID, boardID, userID string, count, size int64) {
+		key := [2]string{boardID, userID}
+		usage, ok := usages[key]
+		if !ok {
+			usage = &model.FileUsage{TeamID: teamID, BoardID: boardID, UserID: userID}
+			usages[key] = usage
+		}
+		usage.FileCount += count
+		usage.TotalSize += size
+	}
+	for rows.Next() {
+		var id, oldBoardID, userID string
+		var size int64
+		if err := rows.Scan(&id, &oldBoardID, &userID, &size); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		addUsage("", oldBoardID, userID, -1, -size)
+		addUsage(board.TeamID, boardID, userID, 1, size)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	updateQuery := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"file_info").
+		Set("board_id", boardID).
+		Where(sq.Eq{"id": ids})
+	if _, err := updateQuery.Exec(); err != nil {
+		s.logger.Error("moveFileUsage update error", mlog.Err(err))
+		return err
+	}
+
+	for _, usage := range usages {
+		if err := s.addFileUsage(db, usage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileIDsFromBlocks returns the IDs of the file infos of the files
+// attached to blocks.
+func fileIDsFromBlocks(blocks []*model.Block) []string {
+	fileIDs := []string{}
+	for _, block := range blocks {
+		for _, field := range []string{"fileId", "attachmentId"} {
+			if id, ok := block.Fields[field].(string); ok && id != "" {
+				fileIDs = append(fileIDs, retrieveFileIDFromBlockFieldStorage(id))
+			}
+		}
+	}
+	return fileIDs
+}
+
+func (s *SQLStore) getFileUsage(db sq.BaseRunner, teamID string) ([]*model.FileUsage, error) {
+	query := s.getQueryBuilder(db).
+		Select("team_id", "board_id", "user_id", "file_count", "total_size").
+		From(s.tablePrefix+"file_usage").
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Gt{"file_count": 0}).
+		OrderBy("board_id", "user_id")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("getFileUsage error", mlog.String("team_id", teamID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	usages := []*model.FileUsage{}
+	for rows.Next() {
+		var usage model.FileUsage
+		if err := rows.Scan(&usage.TeamID, &usage.BoardID, &usage.UserID, &usage.FileCount, &usage.TotalSize); err != nil {
+			s.logger.Error("getFileUsage scan error", mlog.Err(err))
+			return nil, err
+		}
+		usages = append(usages, &usage)
+	}
+	return usages, rows.Err()
+}
+
+// getFileUsageTotals returns the total size of the files stored in a
+// board and in its team.
+func (s *SQLStore) getFileUsageTotals(db sq.BaseRunner, teamID, boardID string) (int64, int64, error) {
+	query := s.getQueryBuilder(db).
+		Select().
+		Column(sq.Expr("COALESCE(SUM(CASE WHEN board_id = ? THEN total_size ELSE 0 END), 0)", boardID)).
+		Column("COALESCE(SUM(total_size), 0)").
+		From(s.tablePrefix + "file_usage").
+		Where(sq.Eq{"team_id": teamID})
+
+	var boardSize, teamSize int64
+	if err := query.QueryRow().Scan(&boardSize, &teamSize); err != nil {
+		s.logger.Error("getFileUsageTotals error", mlog.String("team_id", teamID), mlog.String("board_id", boardID), mlog.Err(err))
+		return 0, 0, err
+	}
+	return boardSize, teamSize, nil
+}
+
+// getStorageQuota returns the storage quota of a team or a board, an
+// ErrNotFound if it has the default one.
+func (s *SQLStore) getStorageQuota(db sq.BaseRunner, scope model.StorageQuotaScope, scopeID string) (*model.StorageQuota, error) {
+	query := s.getQueryBuilder(db).
+		Select("scope", "scope_id", "team_id", "max_size", "update_at").
+		From(s.tablePrefix + "storage_quotas").
+		Where(sq.Eq{"scope": scope}).
+		Where(sq.Eq{"scope_id": scopeID})
+
+	var quota model.StorageQuota
+	var updateAt sql.NullInt64
+	err := query.QueryRow().Scan(&quota.Scope, &quota.ScopeID, &quota.TeamID, &quota.MaxSize, &updateAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.NewErrNotFound("storage quota " + scopeID)
+	}
+	if err != nil {
+		s.logger.Error("getStorageQuota error", mlog.String("scope_id", scopeID), mlog.Err(err))
+		return nil, err
+	}
+	quota.UpdateAt = updateAt.Int64
+	return &quota, nil
+}
+
+func (s *SQLStore) getStorageQuotas(db sq.BaseRunner, teamID string) ([]*model.StorageQuota, error) {
+	query := s.getQueryBuilder(db).
+		Select("scope", "scope_id", "team_id", "max_size", "update_at").
+		From(s.tablePrefix+"storage_quotas").
+		Where(sq.Eq{"team_id": teamID}).
+		OrderBy("scope", "scope_id")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("getStorageQuotas error", mlog.String("team_id", teamID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	quotas := []*model.StorageQuota{}
+	for rows.Next() {
+		var quota model.StorageQuota
+		var updateAt sql.NullInt64
+		if err := rows.Scan(&quota.Scope, &quota.ScopeID, &quota.TeamID, &quota.MaxSize, &updateAt); err != nil {
+			s.logger.Error("getStorageQuotas scan error", mlog.Err(err))
+			return nil, err
+		}
+		quota.UpdateAt = updateAt.Int64
+		quotas = append(quotas, &quota)
+	}
+	return quotas, rows.Err()
+}
+
+func (s *SQLStore) setStorageQuota(db sq.BaseRunner, quota *model.StorageQuota) error {
+	if err := s.deleteStorageQuota(db, quota.Scope, quota.ScopeID); err != nil {
+		return err
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"storage_quotas").
+		Columns("scope", "scope_id", "team_id", "max_size", "update_at").
+		Values(quota.Scope, quota.ScopeID, quota.TeamID, quota.MaxSize, quota.UpdateAt)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("setStorageQuota error", mlog.String("scope_id", quota.ScopeID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+func (s *SQLStore) deleteStorageQuota(db sq.BaseRunner, scope model.StorageQuotaScope, scopeID string) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "storage_quotas").
+		Where(sq.Eq{"scope": scope}).
+		Where(sq.Eq{"scope_id": scopeID})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("deleteStorageQuota error", mlog.String("scope_id", scopeID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
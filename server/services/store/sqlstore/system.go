@@ -63,3 +63,50 @@ func (s *SQLStore) setSystemSetting(db sq.BaseRunner, id, value string) error {
 
 	return nil
 }
+
+// compareAndSetSystemSetting sets a setting to a value if its current
+// value is the expected one, an empty expected value matching a setting
+// that is not set, and returns whether it was set. Servers of a cluster
+// use it to claim work only one of them should do.
+func (s *SQLStore) compareAndSetSystemSetting(db sq.BaseRunner, id, expected, value string) (bool, error) {
+	if expected == "" {
+		insertQuery := s.getQueryBuilder(db).
+			Insert(s.tablePrefix+"system_settings").
+			Columns("id", "value").
+			Values(id, value)
+
+		if s.dbType == model.MysqlDBType {
+			insertQuery = insertQuery.Options("IGNORE")
+		} else {
+			insertQuery = insertQuery.Suffix("ON CONFLICT (id) DO NOTHING")
+		}
+
+		result, err := insertQuery.Exec()
+		if err != nil {
+			return false, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+		if rowsAffected > 0 {
+			return true, nil
+		}
+	}
+
+	updateQuery := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"system_settings").
+		Set("value", value).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"value": expected})
+
+	result, err := updateQuery.Exec()
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
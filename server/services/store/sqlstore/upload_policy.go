@@ -0,0 +1,144 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (s *SQLStore) getUploadPolicy(db sq.BaseRunner, teamID string) (*model.UploadPolicy, error) {
+	query := s.getQueryBuilder(db).
+		Select("team_id", "allowed_mime_types", "denied_mime_types", "allowed_extensions", "denied_extensions", "update_at").
+		From(s.tablePrefix + "upload_policies").
+		Where(sq.Eq{"team_id": teamID})
+
+	var policy model.UploadPolicy
+	var allowedMimeTypes, deniedMimeTypes, allowedExtensions, deniedExtensions sql.NullString
+	var updateAt sql.NullInt64
+	err := query.QueryRow().Scan(
+		&policy.TeamID,
+		&allowedMimeTypes,
+		&deniedMimeTypes,
+		&allowedExtensions,
+		&deniedExtensions,
+		&updateAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.NewErrNotFound("upload policy teamID=" + teamID)
+	}
+	if err != nil {
+		s.logger.Error("getUploadPolicy error", mlog.String("team_id", teamID), mlog.Err(err))
+		return nil, err
+	}
+	policy.UpdateAt = updateAt.Int64
+
+	lists := []struct {
+		value  sql.NullString
+		target *[]string
+	}{
+		{allowedMimeTypes, &policy.AllowedMimeTypes},
+		{deniedMimeTypes, &policy.DeniedMimeTypes},
+		{allowedExtensions, &policy.AllowedExtensions},
+		{deniedExtensions, &policy.DeniedExtensions},
+	}
+	for _, list := range lists {
+		*list.target = []string{}
+		if !list.value.Valid || list.value.String == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(list.value.String), list.target); err != nil {
+			s.logger.Error("upload policy unmarshal error", mlog.String("team_id", teamID), mlog.Err(err))
+			return nil, err
+		}
+	}
+	return &policy, nil
+}
+
+func (s *SQLStore) saveUploadPolicy(db sq.BaseRunner, policy *model.UploadPolicy) error {
+	if err := s.deleteUploadPolicy(db, policy.TeamID); err != nil {
+		return err
+	}
+
+	values := []interface{}{policy.TeamID}
+	for _, list := range [][]string{policy.AllowedMimeTypes, policy.DeniedMimeTypes, policy.AllowedExtensions, policy.DeniedExtensions} {
+		if list == nil {
+			list = []string{}
+		}
+		listBytes, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+		values = append(values, string(listBytes))
+	}
+	values = append(values, policy.UpdateAt)
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"upload_policies").
+		Columns("team_id", "allowed_mime_types", "denied_mime_types", "allowed_extensions", "denied_extensions", "update_at").
+		Values(values...)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("saveUploadPolicy error", mlog.String("team_id", policy.TeamID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+func (s *SQLStore) deleteUploadPolicy(db sq.BaseRunner, teamID string) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "upload_policies").
+		Where(sq.Eq{"team_id": teamID})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("deleteUploadPolicy error", mlog.String("team_id", teamID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+func (s *SQLStore) saveFileScanResult(db sq.BaseRunner, result *model.FileScanResult) error {
+	deleteQuery := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "file_scan_results").
+		Where(sq.Eq{"file_id": result.FileID})
+
+	if _, err := deleteQuery.Exec(); err != nil {
+		s.logger.Error("saveFileScanResult delete error", mlog.String("file_id", result.FileID), mlog.Err(err))
+		return err
+	}
+
+	insertQuery := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"file_scan_results").
+		Columns("file_id", "status", "signature", "quarantine_path", "scanned_at").
+		Values(result.FileID, result.Status, result.Signature, result.QuarantinePath, result.ScannedAt)
+
+	if _, err := insertQuery.Exec(); err != nil {
+		s.logger.Error("saveFileScanResult insert error", mlog.String("file_id", result.FileID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+func (s *SQLStore) getFileScanResult(db sq.BaseRunner, fileID string) (*model.FileScanResult, error) {
+	query := s.getQueryBuilder(db).
+		Select("file_id", "status", "COALESCE(signature, '')", "COALESCE(quarantine_path, '')", "scanned_at").
+		From(s.tablePrefix + "file_scan_results").
+		Where(sq.Eq{"file_id": fileID})
+
+	var result model.FileScanResult
+	var scannedAt sql.NullInt64
+	err := query.QueryRow().Scan(&result.FileID, &result.Status, &result.Signature, &result.QuarantinePath, &scannedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.NewErrNotFound("file scan result fileID=" + fileID)
+	}
+	if err != nil {
+		s.logger.Error("getFileScanResult error", mlog.String("file_id", fileID), mlog.Err(err))
+		return nil, err
+	}
+	result.ScannedAt = scannedAt.Int64
+	return &result, nil
+}
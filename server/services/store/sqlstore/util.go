@@ -20,6 +20,27 @@ func (s *SQLStore) CloseRows(rows *sql.Rows) {
 	}
 }
 
+// lockRowForUpdate locks a row of a table until the end of the current transaction, so that
+// it can be read, checked and written without concurrent modifications. SQLite doesn't
+// support row locks and the store doesn't use transactions for it, so it is a no-op there.
+func (s *SQLStore) lockRowForUpdate(db sq.BaseRunner, table, id string) error {
+	if s.dbType == model.SqliteDBType {
+		return nil
+	}
+
+	rows, err := s.getQueryBuilder(db).
+		Select("id").
+		From(s.tablePrefix + table).
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		Query()
+	if err != nil {
+		return err
+	}
+	s.CloseRows(rows)
+	return nil
+}
+
 func (s *SQLStore) IsErrNotFound(err error) bool {
 	return model.IsErrNotFound(err)
 }
@@ -48,12 +48,17 @@ type Store interface {
 	DuplicateBlock(boardID string, blockID string, userID string, asTemplate bool) ([]*model.Block, error)
 	// @withTransaction
 	PatchBlocks(blockPatches *model.BlockPatchBatch, userID string) error
+	// @withTransaction
+	MoveBlocks(blocks []*model.Block, sourceBoardID string, userID string) error
+	// @withTransaction
+	ApplyCardBulkChanges(changes *model.CardBulkChanges, userID string) ([]*model.Block, error)
 
 	Shutdown() error
 
 	GetSystemSetting(key string) (string, error)
 	GetSystemSettings() (map[string]string, error)
 	SetSystemSetting(key, value string) error
+	CompareAndSetSystemSetting(key, expected, value string) (bool, error)
 
 	GetRegisteredUserCount() (int, error)
 	GetUserByID(userID string) (*model.User, error)
@@ -99,6 +104,9 @@ type Store interface {
 	GetBoardsInTeamByIds(boardIDs []string, teamID string) ([]*model.Board, error)
 	// @withTransaction
 	DeleteBoard(boardID, userID string) error
+	GetBoardSnapshot(boardID string, at int64) (*model.BoardSnapshot, error)
+	// @withTransaction
+	RestoreBoard(changes *model.BoardRestoreChanges, userID string) (*model.Board, error)
 
 	SaveMember(bm *model.BoardMember) (*model.BoardMember, error)
 	DeleteMember(boardID, userID string) error
@@ -113,6 +121,8 @@ type Store interface {
 	// @withTransaction
 	CreateBoardsAndBlocksWithAdmin(bab *model.BoardsAndBlocks, userID string) (*model.BoardsAndBlocks, []*model.BoardMember, error)
 	// @withTransaction
+	CreateBoardsAndBlocksWithMembers(bab *model.BoardsAndBlocks, members []*model.BoardMember, userID string) (*model.BoardsAndBlocks, []*model.BoardMember, error)
+	// @withTransaction
 	CreateBoardsAndBlocks(bab *model.BoardsAndBlocks, userID string) (*model.BoardsAndBlocks, error)
 	// @withTransaction
 	PatchBoardsAndBlocks(pbab *model.PatchBoardsAndBlocks, userID string) (*model.BoardsAndBlocks, error)
@@ -131,7 +141,48 @@ type Store interface {
 	GetUserCategoryBoards(userID, teamID string) ([]model.CategoryBoards, error)
 
 	GetFileInfo(id string) (*mmModel.FileInfo, error)
+	// @withTransaction
 	SaveFileInfo(fileInfo *mmModel.FileInfo) error
+	// @withTransaction
+	SaveFileInfoWithUsage(fileInfo *mmModel.FileInfo, teamID, boardID string) error
+	AddFileUsage(usage *model.FileUsage) error
+	GetFileUsage(teamID string) ([]*model.FileUsage, error)
+	GetFileUsageTotals(teamID, boardID string) (int64, int64, error)
+	GetStorageQuotas(teamID string) ([]*model.StorageQuota, error)
+	GetStorageQuota(scope model.StorageQuotaScope, scopeID string) (*model.StorageQuota, error)
+	// @withTransaction
+	SetStorageQuota(quota *model.StorageQuota) error
+	DeleteStorageQuota(scope model.StorageQuotaScope, scopeID string) error
+	GetUploadPolicy(teamID string) (*model.UploadPolicy, error)
+	// @withTransaction
+	SaveUploadPolicy(policy *model.UploadPolicy) error
+	DeleteUploadPolicy(teamID string) error
+	// @withTransaction
+	SaveFileScanResult(result *model.FileScanResult) error
+	GetFileScanResult(fileID string) (*model.FileScanResult, error)
+	GetFileBlob(hash string) (*model.FileBlob, error)
+	GetUnreferencedFileBlobs() ([]*model.FileBlob, error)
+	ClaimFileBlob(hash string) (bool, error)
+	UnclaimFileBlob(hash string) error
+	DeleteFileBlob(hash string) error
+	// @withTransaction
+	UpdateFileInfoPath(fileInfo *mmModel.FileInfo) error
+	GetLegacyFileInfos(afterCreateAt int64, afterID string, limit int) ([]*mmModel.FileInfo, error)
+	GetFileReferences() ([]string, error)
+	GetFileInfosCreatedBefore(createdBefore int64) ([]*mmModel.FileInfo, error)
+	// @withTransaction
+	DeleteFileInfos(ids []string) error
+
+	CreateExportJob(job *model.ExportJob) error
+	UpdateExportJob(job *model.ExportJob) error
+	ClaimExportJob(id string, updateAt int64) (bool, error)
+	TouchExportJobs(ids []string, updateAt int64) error
+	RequeueExportJob(job *model.ExportJob, updateAt int64) (bool, error)
+	GetExportJob(id string) (*model.ExportJob, error)
+	GetExportJobsForUser(teamID, userID string) ([]*model.ExportJob, error)
+	GetExpiredExportJobs(expireBefore int64) ([]*model.ExportJob, error)
+	GetStaleExportJobs(updatedBefore int64) ([]*model.ExportJob, error)
+	DeleteExportJob(id string) error
 
 	// @withTransaction
 	AddUpdateCategoryBoard(userID, categoryID string, boardIDs []string) error
@@ -151,6 +202,25 @@ type Store interface {
 	GetNotificationHint(blockID string) (*model.NotificationHint, error)
 	GetNextNotificationHint(remove bool) (*model.NotificationHint, error)
 
+	CreateAutomationRule(rule *model.AutomationRule) (*model.AutomationRule, error)
+	UpdateAutomationRule(rule *model.AutomationRule) error
+	DeleteAutomationRule(ruleID string, modifiedBy string) error
+	GetAutomationRule(ruleID string) (*model.AutomationRule, error)
+	GetAutomationRulesForBoard(boardID string) ([]*model.AutomationRule, error)
+	GetEnabledAutomationRulesByTrigger(triggerType model.AutomationTriggerType) ([]*model.AutomationRule, error)
+	InsertAutomationExecution(execution *model.AutomationExecution) error
+	GetAutomationExecutions(opts model.QueryAutomationExecutionsOptions) ([]*model.AutomationExecution, error)
+
+	AddCommentReaction(reaction *model.CommentReaction) (*model.CommentReaction, error)
+	DeleteCommentReaction(commentID, userID, emoji string) error
+	GetCommentReactions(commentIDs []string) ([]*model.CommentReaction, error)
+
+	// @withTransaction
+	InsertInboxItems(items []*model.InboxItem) error
+	GetInboxItems(userID string, opts model.QueryInboxItemsOptions) ([]*model.InboxItem, error)
+	GetInboxUnreadCount(userID, teamID string) (int, error)
+	MarkInboxItemsRead(userID, teamID string, itemIDs []string, readAt int64) error
+
 	RemoveDefaultTemplates(boards []*model.Board) error
 	GetTemplateBoards(teamID, userID string) ([]*model.Board, error)
 
@@ -0,0 +1,133 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+func StoreTestAutomationStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("AutomationRules", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testAutomationRules(t, store)
+	})
+
+	t.Run("AutomationExecutions", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testAutomationExecutions(t, store)
+	})
+}
+
+func createTestAutomationRule(t *testing.T, store store.Store, boardID string, triggerType model.AutomationTriggerType, enabled bool) *model.AutomationRule {
+	rule := &model.AutomationRule{
+		BoardID:   boardID,
+		Title:     "rule",
+		Enabled:   enabled,
+		Trigger:   model.AutomationTrigger{Type: triggerType, PropertyID: "prop"},
+		Actions:   []model.AutomationAction{{Type: model.AutomationActionAddComment, Text: "hello"}},
+		CreatedBy: "user-1",
+	}
+	newRule, err := store.CreateAutomationRule(rule)
+	require.NoError(t, err)
+	return newRule
+}
+
+func testAutomationRules(t *testing.T, store store.Store) {
+	boardID := utils.NewID(utils.IDTypeBoard)
+
+	t.Run("create and get", func(t *testing.T) {
+		rule := createTestAutomationRule(t, store, boardID, model.AutomationTriggerCardCreated, true)
+		require.NotEmpty(t, rule.ID)
+
+		fetched, err := store.GetAutomationRule(rule.ID)
+		require.NoError(t, err)
+		require.Equal(t, rule.Trigger, fetched.Trigger)
+		require.Equal(t, rule.Actions, fetched.Actions)
+		require.True(t, fetched.Enabled)
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		_, err := store.CreateAutomationRule(&model.AutomationRule{BoardID: boardID})
+		require.Error(t, err)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		rule := createTestAutomationRule(t, store, boardID, model.AutomationTriggerCardCreated, true)
+		rule.Title = "updated"
+		rule.Enabled = false
+		rule.Trigger = model.AutomationTrigger{Type: model.AutomationTriggerDueDatePassed, PropertyID: "due"}
+		require.NoError(t, store.UpdateAutomationRule(rule))
+
+		fetched, err := store.GetAutomationRule(rule.ID)
+		require.NoError(t, err)
+		require.Equal(t, "updated", fetched.Title)
+		require.False(t, fetched.Enabled)
+		require.Equal(t, model.AutomationTriggerDueDatePassed, fetched.Trigger.Type)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		rule := createTestAutomationRule(t, store, boardID, model.AutomationTriggerCardCreated, true)
+		require.NoError(t, store.DeleteAutomationRule(rule.ID, "user-1"))
+
+		_, err := store.GetAutomationRule(rule.ID)
+		require.True(t, model.IsErrNotFound(err))
+
+		err = store.DeleteAutomationRule(rule.ID, "user-1")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("get for board and by trigger", func(t *testing.T) {
+		otherBoardID := utils.NewID(utils.IDTypeBoard)
+		createTestAutomationRule(t, store, otherBoardID, model.AutomationTriggerCommentAdded, true)
+		createTestAutomationRule(t, store, otherBoardID, model.AutomationTriggerCommentAdded, false)
+		createTestAutomationRule(t, store, otherBoardID, model.AutomationTriggerCardCreated, true)
+
+		rules, err := store.GetAutomationRulesForBoard(otherBoardID)
+		require.NoError(t, err)
+		require.Len(t, rules, 3)
+
+		rules, err = store.GetEnabledAutomationRulesByTrigger(model.AutomationTriggerCommentAdded)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Equal(t, otherBoardID, rules[0].BoardID)
+	})
+}
+
+func testAutomationExecutions(t *testing.T, store store.Store) {
+	boardID := utils.NewID(utils.IDTypeBoard)
+
+	for i := 0; i < 5; i++ {
+		ruleID := "rule-1"
+		if i%2 == 1 {
+			ruleID = "rule-2"
+		}
+		err := store.InsertAutomationExecution(&model.AutomationExecution{
+			RuleID:      ruleID,
+			BoardID:     boardID,
+			CardID:      "card-1",
+			TriggerType: model.AutomationTriggerCardCreated,
+			Status:      model.AutomationExecutionSuccess,
+			CreateAt:    int64(1000 + i),
+		})
+		require.NoError(t, err)
+	}
+
+	executions, err := store.GetAutomationExecutions(model.QueryAutomationExecutionsOptions{BoardID: boardID})
+	require.NoError(t, err)
+	require.Len(t, executions, 5)
+	require.Equal(t, int64(1004), executions[0].CreateAt)
+
+	executions, err = store.GetAutomationExecutions(model.QueryAutomationExecutionsOptions{BoardID: boardID, RuleID: "rule-2"})
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+
+	executions, err = store.GetAutomationExecutions(model.QueryAutomationExecutionsOptions{BoardID: boardID, Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, executions, 3)
+}
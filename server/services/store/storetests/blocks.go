@@ -42,6 +42,16 @@ func StoreTestBlocksStore(t *testing.T, setup func(t *testing.T) (store.Store, f
 		defer tearDown()
 		testPatchBlocks(t, store)
 	})
+	t.Run("MoveBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testMoveBlocks(t, store)
+	})
+	t.Run("ApplyCardBulkChanges", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testApplyCardBulkChanges(t, store)
+	})
 	t.Run("DeleteBlock", func(t *testing.T) {
 		store, tearDown := setup(t)
 		defer tearDown()
@@ -452,6 +462,38 @@ func testPatchBlock(t *testing.T, store store.Store) {
 		require.Equal(t, "test value 2", retrievedBlock.Fields["test2"])
 		require.Equal(t, nil, retrievedBlock.Fields["test3"])
 	})
+
+	t.Run("expected update time", func(t *testing.T) {
+		existingBlock, err := store.GetBlock("id-test")
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		staleTitle := "Stale title"
+		err = store.PatchBlock("id-test", &model.BlockPatch{
+			Title:            &staleTitle,
+			ExpectedUpdateAt: existingBlock.UpdateAt - 1,
+		}, "user-id-2")
+		require.True(t, model.IsErrVersionConflict(err))
+		var vc *model.ErrVersionConflict
+		require.ErrorAs(t, err, &vc)
+		require.Equal(t, existingBlock.UpdateAt, vc.CurrentUpdateAt)
+
+		retrievedBlock, err := store.GetBlock("id-test")
+		require.NoError(t, err)
+		require.Equal(t, existingBlock.Title, retrievedBlock.Title)
+
+		newTitle := "Current title"
+		err = store.PatchBlock("id-test", &model.BlockPatch{
+			Title:            &newTitle,
+			ExpectedUpdateAt: existingBlock.UpdateAt,
+		}, "user-id-2")
+		require.NoError(t, err)
+
+		retrievedBlock, err = store.GetBlock("id-test")
+		require.NoError(t, err)
+		require.Equal(t, newTitle, retrievedBlock.Title)
+	})
 }
 
 func testPatchBlocks(t *testing.T, store store.Store) {
@@ -525,6 +567,120 @@ func testPatchBlocks(t *testing.T, store store.Store) {
 	})
 }
 
+func testMoveBlocks(t *testing.T, store store.Store) {
+	card := &model.Block{
+		ID:       "card-to-move",
+		BoardID:  "source-board",
+		ParentID: "source-board",
+		Type:     model.TypeCard,
+		Title:    "Card",
+		Fields:   map[string]interface{}{"properties": map[string]interface{}{"status": "old"}},
+	}
+	text := &model.Block{
+		ID:       "text-to-move",
+		BoardID:  "source-board",
+		ParentID: "card-to-move",
+		Type:     model.TypeText,
+		Title:    "Content",
+	}
+	err := store.InsertBlocks([]*model.Block{card, text}, "user-id-1")
+	require.NoError(t, err)
+
+	t.Run("blocks from another board are not moved", func(t *testing.T) {
+		moved := *card
+		moved.BoardID = "dest-board"
+		err := store.MoveBlocks([]*model.Block{&moved}, "other-board", "user-id-2")
+		var nf *model.ErrNotFound
+		require.ErrorAs(t, err, &nf)
+
+		retrievedBlock, err := store.GetBlock(card.ID)
+		require.NoError(t, err)
+		require.Equal(t, "source-board", retrievedBlock.BoardID)
+	})
+
+	t.Run("move card and its content", func(t *testing.T) {
+		movedCard := *card
+		movedCard.BoardID = "dest-board"
+		movedCard.ParentID = "dest-board"
+		movedCard.Fields = map[string]interface{}{"properties": map[string]interface{}{"status": "new"}}
+		movedText := *text
+		movedText.BoardID = "dest-board"
+
+		time.Sleep(1 * time.Millisecond)
+		err := store.MoveBlocks([]*model.Block{&movedCard, &movedText}, "source-board", "user-id-2")
+		require.NoError(t, err)
+
+		retrievedCard, err := store.GetBlock(card.ID)
+		require.NoError(t, err)
+		require.Equal(t, "dest-board", retrievedCard.BoardID)
+		require.Equal(t, "dest-board", retrievedCard.ParentID)
+		require.Equal(t, "user-id-1", retrievedCard.CreatedBy)
+		require.Equal(t, "user-id-2", retrievedCard.ModifiedBy)
+		require.Equal(t, "new", retrievedCard.Fields["properties"].(map[string]interface{})["status"])
+
+		retrievedText, err := store.GetBlock(text.ID)
+		require.NoError(t, err)
+		require.Equal(t, "dest-board", retrievedText.BoardID)
+		require.Equal(t, card.ID, retrievedText.ParentID)
+
+		sourceBlocks, err := store.GetBlocks(model.QueryBlocksOptions{BoardID: "source-board"})
+		require.NoError(t, err)
+		require.Empty(t, sourceBlocks)
+	})
+}
+
+func testApplyCardBulkChanges(t *testing.T, store store.Store) {
+	boardID := "bulk-board"
+	cards := []*model.Block{}
+	for _, id := range []string{"patched", "moved", "deleted", "duplicated"} {
+		cards = append(cards, &model.Block{
+			ID:       id,
+			BoardID:  boardID,
+			ParentID: boardID,
+			Type:     model.TypeCard,
+			Title:    id,
+		})
+	}
+	InsertBlocks(t, store, cards, "user-id-1")
+
+	title := "updated"
+	moved := *cards[1]
+	moved.BoardID = "other-board"
+	moved.ParentID = "other-board"
+
+	changes := &model.CardBulkChanges{
+		BoardID: boardID,
+		Patches: model.BlockPatchBatch{
+			BlockIDs:     []string{"patched"},
+			BlockPatches: []model.BlockPatch{{Title: &title}},
+		},
+		MovedBlocks:       []*model.Block{&moved},
+		DeletedCardIDs:    []string{"deleted"},
+		DuplicatedCardIDs: []string{"duplicated"},
+	}
+
+	time.Sleep(1 * time.Millisecond)
+	created, err := store.ApplyCardBulkChanges(changes, "user-id-2")
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	require.NotEqual(t, "duplicated", created[0].ID)
+
+	patched, err := store.GetBlock("patched")
+	require.NoError(t, err)
+	require.Equal(t, title, patched.Title)
+
+	movedBlock, err := store.GetBlock("moved")
+	require.NoError(t, err)
+	require.Equal(t, "other-board", movedBlock.BoardID)
+
+	_, err = store.GetBlock("deleted")
+	require.True(t, model.IsErrNotFound(err))
+
+	boardCards, err := store.GetBlocksWithType(boardID, model.TypeCard)
+	require.NoError(t, err)
+	require.Len(t, boardCards, 3)
+}
+
 var (
 	subtreeSampleBlocks = []*model.Block{
 		{
@@ -888,6 +1044,40 @@ func testGetBlocks(t *testing.T, store store.Store) {
 		require.True(t, model.IsErrNotFound(err))
 		require.Empty(t, blocks)
 	})
+
+	t.Run("cards excluding the archived ones", func(t *testing.T) {
+		cards := []*model.Block{
+			{
+				ID:       "active-card",
+				BoardID:  "cards-board",
+				ParentID: "cards-board",
+				Type:     model.TypeCard,
+			},
+			{
+				ID:       "archived-card",
+				BoardID:  "cards-board",
+				ParentID: "cards-board",
+				Type:     model.TypeCard,
+				Fields:   map[string]interface{}{model.CardArchivedAtField: utils.GetMillis()},
+			},
+		}
+		InsertBlocks(t, store, cards, "user-id-1")
+		defer DeleteBlocks(t, store, cards, "test")
+
+		opts := model.QueryBlocksOptions{BoardID: "cards-board", BlockType: model.TypeCard}
+		blocks, err = store.GetBlocks(opts)
+		require.NoError(t, err)
+		require.Len(t, blocks, 2)
+
+		opts.ExcludeArchived = true
+		blocks, err = store.GetBlocks(opts)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		require.Equal(t, "active-card", blocks[0].ID)
+
+		// Wait for not colliding the ID+insert_at key on delete
+		time.Sleep(1 * time.Millisecond)
+	})
 }
 
 func testGetBlock(t *testing.T, store store.Store) {
@@ -1062,6 +1252,18 @@ func testGetBlockMetadata(t *testing.T, store store.Store) {
 		require.Len(t, blocks, 3)
 	})
 
+	t.Run("get card block history", func(t *testing.T) {
+		opts := model.QueryBlockHistoryOptions{
+			CardID: "block1",
+		}
+		blocks, err = store.GetBlockHistoryDescendants(boardID, opts)
+		require.NoError(t, err)
+		require.Len(t, blocks, 4)
+		for _, block := range blocks {
+			require.NotEqual(t, "block5", block.ID)
+		}
+	})
+
 	t.Run("get first block history", func(t *testing.T) {
 		opts := model.QueryBlockHistoryOptions{
 			Limit:      1,
@@ -1126,6 +1328,22 @@ func testGetBlockMetadata(t *testing.T, store store.Store) {
 		require.Equal(t, expectedBlock.ID, block.ID)
 	})
 
+	t.Run("get block history page after a revision", func(t *testing.T) {
+		opts := model.QueryBlockHistoryOptions{
+			Descending:     true,
+			SortByUpdateAt: true,
+		}
+		blocks, err = store.GetBlockHistoryDescendants(boardID, opts)
+		require.NoError(t, err)
+		require.Len(t, blocks, 5)
+
+		opts.BeforeUpdateAt = blocks[1].UpdateAt
+		opts.BeforeID = blocks[1].ID
+		page, err := store.GetBlockHistoryDescendants(boardID, opts)
+		require.NoError(t, err)
+		require.Equal(t, blocks[2:], page)
+	})
+
 	t.Run("get full block history after delete", func(t *testing.T) {
 		time.Sleep(20 * time.Millisecond)
 		// this will delete `block1` and any other blocks with `block1` as parent.
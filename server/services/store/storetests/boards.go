@@ -97,6 +97,16 @@ func StoreTestBoardStore(t *testing.T, setup func(t *testing.T) (store.Store, fu
 		defer tearDown()
 		testGetBoardCount(t, store)
 	})
+	t.Run("GetBoardSnapshot", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBoardSnapshot(t, store)
+	})
+	t.Run("RestoreBoard", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testRestoreBoard(t, store)
+	})
 }
 
 func testGetBoard(t *testing.T, store store.Store) {
@@ -416,6 +426,39 @@ func testPatchBoard(t *testing.T, store store.Store) {
 		require.Equal(t, userID2, patchedBoard.ModifiedBy)
 	})
 
+	t.Run("should reject a patch with a stale expected update time", func(t *testing.T) {
+		boardID := utils.NewID(utils.IDTypeBoard)
+
+		board := &model.Board{
+			ID:     boardID,
+			TeamID: testTeamID,
+			Type:   model.BoardTypeOpen,
+			Title:  "A simple title",
+		}
+
+		newBoard, err := store.InsertBoard(board, userID)
+		require.NoError(t, err)
+
+		// wait to avoid hitting pk uniqueness constraint in history
+		time.Sleep(10 * time.Millisecond)
+
+		staleTitle := "A stale title"
+		patch := &model.BoardPatch{Title: &staleTitle, ExpectedUpdateAt: newBoard.UpdateAt - 1}
+		patchedBoard, err := store.PatchBoard(boardID, patch, userID)
+		require.True(t, model.IsErrVersionConflict(err))
+		require.Nil(t, patchedBoard)
+
+		var vc *model.ErrVersionConflict
+		require.ErrorAs(t, err, &vc)
+		require.Equal(t, newBoard.UpdateAt, vc.CurrentUpdateAt)
+
+		newTitle := "A new title"
+		patch = &model.BoardPatch{Title: &newTitle, ExpectedUpdateAt: newBoard.UpdateAt}
+		patchedBoard, err = store.PatchBoard(boardID, patch, userID)
+		require.NoError(t, err)
+		require.Equal(t, newTitle, patchedBoard.Title)
+	})
+
 	t.Run("should correctly update the board properties", func(t *testing.T) {
 		boardID := utils.NewID(utils.IDTypeBoard)
 
@@ -1199,3 +1242,130 @@ func testGetBoardCount(t *testing.T, store store.Store) {
 		require.Equal(t, originalCount+1, newCount)
 	})
 }
+
+func testGetBoardSnapshot(t *testing.T, store store.Store) {
+	userID := testUserID
+	boardID := utils.NewID(utils.IDTypeBoard)
+
+	board := &model.Board{
+		ID:     boardID,
+		TeamID: testTeamID,
+		Type:   model.BoardTypeOpen,
+		Title:  "Original title",
+	}
+	_, err := store.InsertBoard(board, userID)
+	require.NoError(t, err)
+
+	blocks := []*model.Block{
+		{ID: "card-1", BoardID: boardID, Type: model.TypeCard, Title: "Card 1"},
+		{ID: "card-2", BoardID: boardID, Type: model.TypeCard, Title: "Card 2"},
+	}
+	require.NoError(t, store.InsertBlocks(blocks, userID))
+
+	// wait to avoid hitting pk uniqueness constraint in history
+	time.Sleep(10 * time.Millisecond)
+	at := utils.GetMillis()
+	time.Sleep(10 * time.Millisecond)
+
+	newTitle := "New title"
+	_, err = store.PatchBoard(boardID, &model.BoardPatch{Title: &newTitle}, userID)
+	require.NoError(t, err)
+	require.NoError(t, store.PatchBlock("card-1", &model.BlockPatch{Title: &newTitle}, userID))
+	require.NoError(t, store.DeleteBlock("card-2", userID))
+	require.NoError(t, store.InsertBlock(&model.Block{ID: "card-3", BoardID: boardID, Type: model.TypeCard}, userID))
+
+	t.Run("should return the board as it was at the given time", func(t *testing.T) {
+		snapshot, err := store.GetBoardSnapshot(boardID, at)
+		require.NoError(t, err)
+		require.Equal(t, at, snapshot.At)
+		require.Equal(t, "Original title", snapshot.Board.Title)
+		require.Len(t, snapshot.Blocks, 2)
+
+		titles := map[string]string{}
+		for _, block := range snapshot.Blocks {
+			titles[block.ID] = block.Title
+		}
+		require.Equal(t, map[string]string{"card-1": "Card 1", "card-2": "Card 2"}, titles)
+	})
+
+	t.Run("should return the current state for the current time", func(t *testing.T) {
+		snapshot, err := store.GetBoardSnapshot(boardID, utils.GetMillis())
+		require.NoError(t, err)
+		require.Equal(t, newTitle, snapshot.Board.Title)
+		require.Len(t, snapshot.Blocks, 2)
+
+		titles := map[string]string{}
+		for _, block := range snapshot.Blocks {
+			titles[block.ID] = block.Title
+		}
+		require.Equal(t, map[string]string{"card-1": newTitle, "card-3": ""}, titles)
+	})
+
+	t.Run("should return not found before the board existed", func(t *testing.T) {
+		snapshot, err := store.GetBoardSnapshot(boardID, 1)
+		require.True(t, model.IsErrNotFound(err))
+		require.Nil(t, snapshot)
+	})
+}
+
+func testRestoreBoard(t *testing.T, store store.Store) {
+	userID := testUserID
+	boardID := utils.NewID(utils.IDTypeBoard)
+
+	board := &model.Board{
+		ID:     boardID,
+		TeamID: testTeamID,
+		Type:   model.BoardTypeOpen,
+		Title:  "Original title",
+	}
+	_, err := store.InsertBoard(board, userID)
+	require.NoError(t, err)
+
+	blocks := []*model.Block{
+		{ID: "card-1", BoardID: boardID, Type: model.TypeCard, Title: "Card 1"},
+		{ID: "card-2", BoardID: boardID, Type: model.TypeCard, Title: "Card 2"},
+	}
+	require.NoError(t, store.InsertBlocks(blocks, userID))
+
+	// wait to avoid hitting pk uniqueness constraint in history
+	time.Sleep(10 * time.Millisecond)
+	at := utils.GetMillis()
+	time.Sleep(10 * time.Millisecond)
+
+	newTitle := "New title"
+	_, err = store.PatchBoard(boardID, &model.BoardPatch{Title: &newTitle}, userID)
+	require.NoError(t, err)
+	require.NoError(t, store.PatchBlock("card-1", &model.BlockPatch{Title: &newTitle}, userID))
+	require.NoError(t, store.DeleteBlock("card-2", userID))
+	require.NoError(t, store.InsertBlock(&model.Block{ID: "card-3", BoardID: boardID, Type: model.TypeCard}, userID))
+
+	snapshot, err := store.GetBoardSnapshot(boardID, at)
+	require.NoError(t, err)
+
+	currentBoard, err := store.GetBoard(boardID)
+	require.NoError(t, err)
+	currentBoard.Title = snapshot.Board.Title
+
+	time.Sleep(10 * time.Millisecond)
+	restoredBoard, err := store.RestoreBoard(&model.BoardRestoreChanges{
+		Board:           currentBoard,
+		Blocks:          snapshot.Blocks,
+		DeletedBlockIDs: []string{"card-3"},
+	}, "user-id-2")
+	require.NoError(t, err)
+	require.Equal(t, "Original title", restoredBoard.Title)
+	require.Equal(t, "user-id-2", restoredBoard.ModifiedBy)
+
+	restoredBlocks, err := store.GetBlocksForBoard(boardID)
+	require.NoError(t, err)
+	titles := map[string]string{}
+	for _, block := range restoredBlocks {
+		titles[block.ID] = block.Title
+	}
+	require.Equal(t, map[string]string{"card-1": "Card 1", "card-2": "Card 2"}, titles)
+
+	// the restore is recorded as new revisions
+	history, err := store.GetBoardHistory(boardID, model.QueryBoardHistoryOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+}
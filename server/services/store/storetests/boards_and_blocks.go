@@ -119,6 +119,57 @@ func testCreateBoardsAndBlocks(t *testing.T, store store.Store) {
 		require.ElementsMatch(t, []string{"board-id-4", "board-id-5", "board-id-6"}, memberBoardIDs)
 	})
 
+	t.Run("create boards and blocks with members", func(t *testing.T) {
+		newBab := &model.BoardsAndBlocks{
+			Boards: []*model.Board{
+				{ID: "board-id-10", TeamID: teamID, Type: model.BoardTypePrivate},
+			},
+			Blocks: []*model.Block{
+				{ID: "block-id-7", BoardID: "board-id-10", Type: model.TypeCard},
+			},
+		}
+		newMembers := []*model.BoardMember{
+			{BoardID: "board-id-10", UserID: userID, SchemeAdmin: true},
+			{BoardID: "board-id-10", UserID: "user-id-2", SchemeViewer: true},
+		}
+
+		bab, members, err := store.CreateBoardsAndBlocksWithMembers(newBab, newMembers, userID)
+		require.NoError(t, err)
+		require.Len(t, bab.Boards, 1)
+		require.Len(t, bab.Blocks, 1)
+		require.Len(t, members, 2)
+
+		member, err := store.GetMemberForBoard("board-id-10", "user-id-2")
+		require.NoError(t, err)
+		require.True(t, member.SchemeViewer)
+		require.False(t, member.SchemeAdmin)
+	})
+
+	t.Run("on failure, no member should be saved", func(t *testing.T) {
+		// the block is invalid as it doesn't have BoardID
+		newBab := &model.BoardsAndBlocks{
+			Boards: []*model.Board{
+				{ID: "board-id-11", TeamID: teamID, Type: model.BoardTypePrivate},
+			},
+			Blocks: []*model.Block{
+				{ID: "block-id-8", BoardID: "", Type: model.TypeCard},
+			},
+		}
+		newMembers := []*model.BoardMember{
+			{BoardID: "board-id-11", UserID: userID, SchemeAdmin: true},
+		}
+
+		bab, members, err := store.CreateBoardsAndBlocksWithMembers(newBab, newMembers, userID)
+		require.Error(t, err)
+		require.Empty(t, bab)
+		require.Empty(t, members)
+
+		_, err = store.GetBoard("board-id-11")
+		require.True(t, model.IsErrNotFound(err))
+		_, err = store.GetMemberForBoard("board-id-11", userID)
+		require.True(t, model.IsErrNotFound(err))
+	})
+
 	t.Run("on failure, nothing should be saved", func(t *testing.T) {
 		// one of the blocks is invalid as it doesn't have BoardID
 		newBab := &model.BoardsAndBlocks{
@@ -156,6 +156,24 @@ func testGetUsedCardsCount(t *testing.T, store storeservice.Store) {
 		require.Equal(t, 5, count)
 	})
 
+	t.Run("should not take into account archived cards", func(t *testing.T) {
+		card10 := &model.Block{
+			ID:       "card10",
+			ParentID: "board1",
+			BoardID:  "board1",
+			Type:     model.TypeCard,
+			Fields: map[string]interface{}{
+				model.CardArchivedAtField: utils.GetMillis(),
+			},
+		}
+		require.NoError(t, store.InsertBlock(card10, userID))
+
+		// and count should still be the same
+		count, err := store.GetUsedCardsCount()
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+	})
+
 	t.Run("should not take into account cards from deleted boards", func(t *testing.T) {
 		require.NoError(t, store.DeleteBoard("board2", "user-id"))
 
@@ -0,0 +1,119 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+func StoreTestCommentReactionsStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("AddCommentReaction", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testAddCommentReaction(t, store)
+	})
+
+	t.Run("DeleteCommentReaction", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteCommentReaction(t, store)
+	})
+
+	t.Run("GetCommentReactions", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetCommentReactions(t, store)
+	})
+}
+
+func makeCommentReaction(commentID, userID, emoji string) *model.CommentReaction {
+	return &model.CommentReaction{
+		CommentID: commentID,
+		BoardID:   "board-id",
+		UserID:    userID,
+		Emoji:     emoji,
+	}
+}
+
+func testAddCommentReaction(t *testing.T, store store.Store) {
+	commentID := utils.NewID(utils.IDTypeBlock)
+
+	t.Run("add reaction", func(t *testing.T) {
+		reaction, err := store.AddCommentReaction(makeCommentReaction(commentID, "user-id", "smile"))
+		require.NoError(t, err)
+		require.Equal(t, commentID, reaction.CommentID)
+		require.NotZero(t, reaction.CreateAt)
+	})
+
+	t.Run("adding the same reaction twice returns the existing one", func(t *testing.T) {
+		reactions, err := store.GetCommentReactions([]string{commentID})
+		require.NoError(t, err)
+		require.Len(t, reactions, 1)
+
+		reaction, err := store.AddCommentReaction(makeCommentReaction(commentID, "user-id", "smile"))
+		require.NoError(t, err)
+		require.Equal(t, reactions[0].CreateAt, reaction.CreateAt)
+
+		reactions, err = store.GetCommentReactions([]string{commentID})
+		require.NoError(t, err)
+		require.Len(t, reactions, 1)
+	})
+}
+
+func testDeleteCommentReaction(t *testing.T, store store.Store) {
+	commentID := utils.NewID(utils.IDTypeBlock)
+
+	_, err := store.AddCommentReaction(makeCommentReaction(commentID, "user-id", "smile"))
+	require.NoError(t, err)
+	_, err = store.AddCommentReaction(makeCommentReaction(commentID, "user-id", "tada"))
+	require.NoError(t, err)
+
+	t.Run("delete reaction", func(t *testing.T) {
+		require.NoError(t, store.DeleteCommentReaction(commentID, "user-id", "smile"))
+
+		reactions, err := store.GetCommentReactions([]string{commentID})
+		require.NoError(t, err)
+		require.Len(t, reactions, 1)
+		require.Equal(t, "tada", reactions[0].Emoji)
+	})
+
+	t.Run("delete nonexistent reaction", func(t *testing.T) {
+		err := store.DeleteCommentReaction(commentID, "other-user-id", "tada")
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
+
+func testGetCommentReactions(t *testing.T, store store.Store) {
+	commentID1 := utils.NewID(utils.IDTypeBlock)
+	commentID2 := utils.NewID(utils.IDTypeBlock)
+	commentID3 := utils.NewID(utils.IDTypeBlock)
+
+	for _, reaction := range []*model.CommentReaction{
+		makeCommentReaction(commentID1, "user-id-1", "smile"),
+		makeCommentReaction(commentID1, "user-id-2", "smile"),
+		makeCommentReaction(commentID2, "user-id-1", "+1"),
+		makeCommentReaction(commentID3, "user-id-1", "tada"),
+	} {
+		_, err := store.AddCommentReaction(reaction)
+		require.NoError(t, err)
+	}
+
+	t.Run("get reactions of several comments", func(t *testing.T) {
+		reactions, err := store.GetCommentReactions([]string{commentID1, commentID2})
+		require.NoError(t, err)
+		require.Len(t, reactions, 3)
+		for _, reaction := range reactions {
+			require.NotEqual(t, commentID3, reaction.CommentID)
+		}
+	})
+
+	t.Run("no comments", func(t *testing.T) {
+		reactions, err := store.GetCommentReactions([]string{})
+		require.NoError(t, err)
+		require.Empty(t, reactions)
+	})
+}
@@ -0,0 +1,203 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+func StoreTestExportJobStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("ExportJobs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testExportJobs(t, store)
+	})
+
+	t.Run("CleanupExportJobs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCleanupExportJobs(t, store)
+	})
+
+	t.Run("ClaimExportJobs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testClaimExportJobs(t, store)
+	})
+}
+
+func newTestExportJob(teamID, userID string, createAt int64) *model.ExportJob {
+	id := utils.NewID(utils.IDTypeNone)
+	return &model.ExportJob{
+		ID:         id,
+		TeamID:     teamID,
+		CreatedBy:  userID,
+		Status:     model.ExportJobStatusPending,
+		BoardIDs:   []string{"board-1", "board-2"},
+		BoardCount: 2,
+		Path:       "exports/" + teamID + "/" + id + ".boardarchive",
+		CreateAt:   createAt,
+		UpdateAt:   createAt,
+	}
+}
+
+func testExportJobs(t *testing.T, store store.Store) {
+	t.Run("no job", func(t *testing.T) {
+		_, err := store.GetExportJob("job-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	job := newTestExportJob("team-id", "user-id", 1)
+
+	t.Run("create a job", func(t *testing.T) {
+		require.NoError(t, store.CreateExportJob(job))
+
+		retrieved, err := store.GetExportJob(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, job, retrieved)
+	})
+
+	t.Run("update a job", func(t *testing.T) {
+		job.Status = model.ExportJobStatusDone
+		job.ExportedBoards = 2
+		job.Size = 1024
+		job.UpdateAt = 2
+		job.ExpireAt = 3
+		require.NoError(t, store.UpdateExportJob(job))
+
+		retrieved, err := store.GetExportJob(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, job, retrieved)
+	})
+
+	t.Run("get jobs for user", func(t *testing.T) {
+		newer := newTestExportJob("team-id", "user-id", 10)
+		require.NoError(t, store.CreateExportJob(newer))
+		require.NoError(t, store.CreateExportJob(newTestExportJob("team-id", "other-user-id", 10)))
+		require.NoError(t, store.CreateExportJob(newTestExportJob("other-team-id", "user-id", 10)))
+
+		jobs, err := store.GetExportJobsForUser("team-id", "user-id")
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		require.Equal(t, newer.ID, jobs[0].ID)
+		require.Equal(t, job.ID, jobs[1].ID)
+	})
+
+	t.Run("delete a job", func(t *testing.T) {
+		require.NoError(t, store.DeleteExportJob(job.ID))
+
+		_, err := store.GetExportJob(job.ID)
+		require.True(t, model.IsErrNotFound(err))
+
+		err = store.DeleteExportJob(job.ID)
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
+
+func testCleanupExportJobs(t *testing.T, store store.Store) {
+	pending := newTestExportJob("team-id", "user-id", 100)
+	require.NoError(t, store.CreateExportJob(pending))
+
+	running := newTestExportJob("team-id", "user-id", 100)
+	running.Status = model.ExportJobStatusRunning
+	running.UpdateAt = 300
+	require.NoError(t, store.CreateExportJob(running))
+
+	done := newTestExportJob("team-id", "user-id", 100)
+	done.Status = model.ExportJobStatusDone
+	done.ExpireAt = 500
+	require.NoError(t, store.CreateExportJob(done))
+
+	failed := newTestExportJob("team-id", "user-id", 100)
+	failed.Status = model.ExportJobStatusFailed
+	failed.ExpireAt = 1000
+	require.NoError(t, store.CreateExportJob(failed))
+
+	t.Run("stale jobs", func(t *testing.T) {
+		jobs, err := store.GetStaleExportJobs(200)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		require.Equal(t, pending.ID, jobs[0].ID)
+
+		jobs, err = store.GetStaleExportJobs(400)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+	})
+
+	t.Run("expired jobs", func(t *testing.T) {
+		jobs, err := store.GetExpiredExportJobs(400)
+		require.NoError(t, err)
+		require.Empty(t, jobs)
+
+		jobs, err = store.GetExpiredExportJobs(600)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		require.Equal(t, done.ID, jobs[0].ID)
+
+		jobs, err = store.GetExpiredExportJobs(2000)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+	})
+}
+
+func testClaimExportJobs(t *testing.T, store store.Store) {
+	job := newTestExportJob("team-id", "user-id", 100)
+	require.NoError(t, store.CreateExportJob(job))
+
+	t.Run("a pending job is claimed once", func(t *testing.T) {
+		claimed, err := store.ClaimExportJob(job.ID, 200)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = store.ClaimExportJob(job.ID, 300)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		retrieved, err := store.GetExportJob(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ExportJobStatusRunning, retrieved.Status)
+		require.Equal(t, int64(200), retrieved.UpdateAt)
+	})
+
+	t.Run("touched jobs are not stale", func(t *testing.T) {
+		require.NoError(t, store.TouchExportJobs([]string{job.ID}, 400))
+
+		jobs, err := store.GetStaleExportJobs(300)
+		require.NoError(t, err)
+		require.Empty(t, jobs)
+	})
+
+	t.Run("a stale job is requeued once", func(t *testing.T) {
+		stale, err := store.GetExportJob(job.ID)
+		require.NoError(t, err)
+
+		requeued, err := store.RequeueExportJob(stale, 500)
+		require.NoError(t, err)
+		require.True(t, requeued)
+
+		requeued, err = store.RequeueExportJob(stale, 600)
+		require.NoError(t, err)
+		require.False(t, requeued)
+
+		retrieved, err := store.GetExportJob(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ExportJobStatusPending, retrieved.Status)
+		require.Equal(t, int64(500), retrieved.UpdateAt)
+	})
+
+	t.Run("finished jobs are not touched", func(t *testing.T) {
+		done := newTestExportJob("team-id", "user-id", 100)
+		done.Status = model.ExportJobStatusDone
+		require.NoError(t, store.CreateExportJob(done))
+
+		require.NoError(t, store.TouchExportJobs([]string{done.ID}, 700))
+
+		retrieved, err := store.GetExportJob(done.ID)
+		require.NoError(t, err)
+		require.Equal(t, done.UpdateAt, retrieved.UpdateAt)
+	})
+}
@@ -36,6 +36,186 @@ func StoreTestFileStore(t *testing.T, setup func(t *testing.T) (store.Store, fun
 		require.Equal(t, int64(112233), retrievedFileInfo.Size)
 		require.Equal(t, int64(0), retrievedFileInfo.DeleteAt)
 		require.False(t, retrievedFileInfo.Archived)
+		require.Empty(t, retrievedFileInfo.MimeType)
+		require.False(t, retrievedFileInfo.HasPreviewImage)
+	})
+
+	t.Run("should save and retrieve image metadata", func(t *testing.T) {
+		fileInfo := &mmModel.FileInfo{
+			Id:              "file_info_2",
+			CreateAt:        utils.GetMillis(),
+			Name:            "Scranton Branch.png",
+			Extension:       ".png",
+			Size:            4455,
+			Path:            "boards/20221012/7abc.png",
+			MimeType:        "image/png",
+			Width:           1024,
+			Height:          768,
+			HasPreviewImage: true,
+			ThumbnailPath:   "boards/20221012/7abc_thumb.jpg",
+			PreviewPath:     "boards/20221012/7abc_preview.jpg",
+		}
+
+		err := sqlStore.SaveFileInfo(fileInfo)
+		require.NoError(t, err)
+
+		retrievedFileInfo, err := sqlStore.GetFileInfo("file_info_2")
+		require.NoError(t, err)
+		require.Equal(t, "image/png", retrievedFileInfo.MimeType)
+		require.Equal(t, 1024, retrievedFileInfo.Width)
+		require.Equal(t, 768, retrievedFileInfo.Height)
+		require.True(t, retrievedFileInfo.HasPreviewImage)
+		require.Equal(t, "boards/20221012/7abc_thumb.jpg", retrievedFileInfo.ThumbnailPath)
+		require.Equal(t, "boards/20221012/7abc_preview.jpg", retrievedFileInfo.PreviewPath)
+	})
+
+	t.Run("should return the files referenced by blocks", func(t *testing.T) {
+		block := &model.Block{
+			ID:       utils.NewID(utils.IDTypeBlock),
+			BoardID:  "board-id",
+			ParentID: "card-id",
+			Type:     model.TypeImage,
+			Fields:   map[string]interface{}{"fileId": "7referenced.png"},
+		}
+		require.NoError(t, sqlStore.InsertBlock(block, "user-id"))
+
+		fileIDs, err := sqlStore.GetFileReferences()
+		require.NoError(t, err)
+		require.Contains(t, fileIDs, "referenced")
+	})
+
+	t.Run("should return the files referenced by deleted blocks", func(t *testing.T) {
+		block := &model.Block{
+			ID:       utils.NewID(utils.IDTypeBlock),
+			BoardID:  "board-id",
+			ParentID: "card-id",
+			Type:     model.TypeAttachment,
+			Fields:   map[string]interface{}{"fileId": "7deleted.pdf"},
+		}
+		require.NoError(t, sqlStore.InsertBlock(block, "user-id"))
+		require.NoError(t, sqlStore.DeleteBlock(block.ID, "user-id"))
+
+		fileIDs, err := sqlStore.GetFileReferences()
+		require.NoError(t, err)
+		require.Contains(t, fileIDs, "deleted")
+	})
+
+	t.Run("should list and delete old file infos", func(t *testing.T) {
+		now := utils.GetMillis()
+		oldFileInfo := &mmModel.FileInfo{
+			Id:        "file_info_old",
+			CreatorId: "user-id",
+			CreateAt:  now - 10000,
+			Name:      "old.txt",
+			Size:      10,
+			Path:      "boards/20221012/7old.txt",
+		}
+		require.NoError(t, sqlStore.SaveFileInfoWithUsage(oldFileInfo, "team-id", "board-id"))
+		require.NoError(t, sqlStore.SaveFileInfo(&mmModel.FileInfo{Id: "file_info_new", CreateAt: now, Name: "new.txt"}))
+
+		fileInfos, err := sqlStore.GetFileInfosCreatedBefore(now - 5000)
+		require.NoError(t, err)
+		ids := []string{}
+		for _, fileInfo := range fileInfos {
+			ids = append(ids, fileInfo.Id)
+		}
+		require.Contains(t, ids, "file_info_old")
+		require.NotContains(t, ids, "file_info_new")
+
+		require.NoError(t, sqlStore.DeleteFileInfos([]string{"file_info_old"}))
+		_, err = sqlStore.GetFileInfo("file_info_old")
+		require.True(t, model.IsErrNotFound(err))
+
+		usages, err := sqlStore.GetFileUsage("team-id")
+		require.NoError(t, err)
+		require.Empty(t, usages)
+	})
+
+	t.Run("should count the references to blobs", func(t *testing.T) {
+		hash := "aabbccddeeff00112233445566778899aabbccddeeff00112233445566778899"
+		blobPath := model.FileBlobPath(hash)
+		for _, id := range []string{"file_info_blob_1", "file_info_blob_2"} {
+			require.NoError(t, sqlStore.SaveFileInfo(&mmModel.FileInfo{Id: id, CreateAt: utils.GetMillis(), Name: "blob.txt", Path: blobPath, Size: 10}))
+		}
+
+		blob, err := sqlStore.GetFileBlob(hash)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), blob.RefCount)
+		require.Equal(t, int64(10), blob.Size)
+
+		require.NoError(t, sqlStore.DeleteFileInfos([]string{"file_info_blob_1"}))
+		blobs, err := sqlStore.GetUnreferencedFileBlobs()
+		require.NoError(t, err)
+		require.Empty(t, blobs)
+
+		require.NoError(t, sqlStore.DeleteFileInfos([]string{"file_info_blob_2"}))
+		blobs, err = sqlStore.GetUnreferencedFileBlobs()
+		require.NoError(t, err)
+		require.Len(t, blobs, 1)
+		require.Equal(t, hash, blobs[0].Hash)
+
+		// deleting a blob that is not claimed does nothing
+		require.NoError(t, sqlStore.DeleteFileBlob(hash))
+		_, err = sqlStore.GetFileBlob(hash)
+		require.NoError(t, err)
+
+		claimed, err := sqlStore.ClaimFileBlob(hash)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		// a claimed blob can't be referenced until it is deleted
+		err = sqlStore.SaveFileInfo(&mmModel.FileInfo{Id: "file_info_blob_3", CreateAt: utils.GetMillis(), Name: "blob.txt", Path: blobPath, Size: 10})
+		require.ErrorIs(t, err, model.ErrFileBlobRemoving)
+
+		require.NoError(t, sqlStore.UnclaimFileBlob(hash))
+		claimed, err = sqlStore.ClaimFileBlob(hash)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		require.NoError(t, sqlStore.DeleteFileBlob(hash))
+		_, err = sqlStore.GetFileBlob(hash)
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("should not claim a referenced blob", func(t *testing.T) {
+		hash := "ffeeddccbbaa99887766554433221100ffeeddccbbaa99887766554433221100"
+		require.NoError(t, sqlStore.SaveFileInfo(&mmModel.FileInfo{Id: "file_info_claim", CreateAt: utils.GetMillis(), Name: "blob.txt", Path: model.FileBlobPath(hash), Size: 10}))
+
+		claimed, err := sqlStore.ClaimFileBlob(hash)
+		require.NoError(t, err)
+		require.False(t, claimed)
+	})
+
+	t.Run("should move legacy file infos to blobs", func(t *testing.T) {
+		hash := "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"
+		fileInfo := &mmModel.FileInfo{
+			Id:       "file_info_legacy",
+			CreateAt: 1,
+			Name:     "legacy.txt",
+			Path:     "boards/20221012/7legacy.txt",
+			Size:     10,
+		}
+		require.NoError(t, sqlStore.SaveFileInfo(fileInfo))
+
+		fileInfos, err := sqlStore.GetLegacyFileInfos(0, "", 10)
+		require.NoError(t, err)
+		require.Len(t, fileInfos, 1)
+		require.Equal(t, "file_info_legacy", fileInfos[0].Id)
+
+		fileInfo.Path = model.FileBlobPath(hash)
+		require.NoError(t, sqlStore.UpdateFileInfoPath(fileInfo))
+
+		retrieved, err := sqlStore.GetFileInfo("file_info_legacy")
+		require.NoError(t, err)
+		require.Equal(t, fileInfo.Path, retrieved.Path)
+
+		blob, err := sqlStore.GetFileBlob(hash)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), blob.RefCount)
+
+		fileInfos, err = sqlStore.GetLegacyFileInfos(0, "", 10)
+		require.NoError(t, err)
+		require.Empty(t, fileInfos)
 	})
 
 	t.Run("should return an error on not found", func(t *testing.T) {
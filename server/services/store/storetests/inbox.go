@@ -0,0 +1,128 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+func StoreTestInboxStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("GetInboxItems", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetInboxItems(t, store)
+	})
+
+	t.Run("MarkInboxItemsRead", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testMarkInboxItemsRead(t, store)
+	})
+}
+
+func makeInboxItem(userID, teamID string, createAt int64) *model.InboxItem {
+	return &model.InboxItem{
+		ID:        utils.NewID(utils.IDTypeNone),
+		UserID:    userID,
+		Type:      model.InboxItemMention,
+		TeamID:    teamID,
+		BoardID:   "board-id",
+		CardID:    "card-id",
+		BlockID:   "comment-id",
+		ActorID:   "actor-id",
+		CardTitle: "Card",
+		Message:   "Hello @user",
+		CreateAt:  createAt,
+	}
+}
+
+func testGetInboxItems(t *testing.T, store store.Store) {
+	items := []*model.InboxItem{
+		makeInboxItem("user-id", "team-id", 1000),
+		makeInboxItem("user-id", "team-id", 2000),
+		makeInboxItem("user-id", "team-id", 3000),
+		makeInboxItem("user-id", "other-team-id", 4000),
+		makeInboxItem("other-user-id", "team-id", 5000),
+	}
+	items[0].ReadAt = 1500
+	require.NoError(t, store.InsertInboxItems(items))
+
+	t.Run("get the items of a team newest first", func(t *testing.T) {
+		result, err := store.GetInboxItems("user-id", model.QueryInboxItemsOptions{TeamID: "team-id"})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		require.Equal(t, items[2], result[0])
+		require.Equal(t, items[1], result[1])
+		require.Equal(t, items[0], result[2])
+	})
+
+	t.Run("get the items of all teams", func(t *testing.T) {
+		result, err := store.GetInboxItems("user-id", model.QueryInboxItemsOptions{})
+		require.NoError(t, err)
+		require.Len(t, result, 4)
+	})
+
+	t.Run("get unread items", func(t *testing.T) {
+		result, err := store.GetInboxItems("user-id", model.QueryInboxItemsOptions{TeamID: "team-id", UnreadOnly: true})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+	})
+
+	t.Run("paginate", func(t *testing.T) {
+		result, err := store.GetInboxItems("user-id", model.QueryInboxItemsOptions{TeamID: "team-id", BeforeCreateAt: 3000, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, items[1].ID, result[0].ID)
+	})
+
+	t.Run("unread count", func(t *testing.T) {
+		count, err := store.GetInboxUnreadCount("user-id", "team-id")
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+	})
+}
+
+func testMarkInboxItemsRead(t *testing.T, store store.Store) {
+	items := []*model.InboxItem{
+		makeInboxItem("user-id", "team-id", 1000),
+		makeInboxItem("user-id", "team-id", 2000),
+		makeInboxItem("user-id", "team-id", 3000),
+		makeInboxItem("user-id", "other-team-id", 4000),
+		makeInboxItem("other-user-id", "team-id", 5000),
+	}
+	require.NoError(t, store.InsertInboxItems(items))
+
+	t.Run("mark some items", func(t *testing.T) {
+		require.NoError(t, store.MarkInboxItemsRead("user-id", "team-id", []string{items[0].ID, items[4].ID}, 6000))
+
+		count, err := store.GetInboxUnreadCount("user-id", "team-id")
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		// the items of other users are not changed
+		count, err = store.GetInboxUnreadCount("other-user-id", "team-id")
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("mark all the items of a team", func(t *testing.T) {
+		require.NoError(t, store.MarkInboxItemsRead("user-id", "team-id", nil, 7000))
+
+		count, err := store.GetInboxUnreadCount("user-id", "team-id")
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		count, err = store.GetInboxUnreadCount("user-id", "other-team-id")
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		result, err := store.GetInboxItems("user-id", model.QueryInboxItemsOptions{TeamID: "team-id"})
+		require.NoError(t, err)
+		require.Equal(t, int64(7000), result[0].ReadAt)
+		require.Equal(t, int64(6000), result[2].ReadAt)
+	})
+}
@@ -0,0 +1,223 @@
+package storetests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+)
+
+func StoreTestStorageStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("FileUsage", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testFileUsage(t, store)
+	})
+
+	t.Run("MoveBlocksFileUsage", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testMoveBlocksFileUsage(t, store)
+	})
+
+	t.Run("StorageQuotas", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testStorageQuotas(t, store)
+	})
+}
+
+func makeUsageFileInfo(userID string, size int64) *mmModel.FileInfo {
+	return &mmModel.FileInfo{
+		Id:        utils.NewID(utils.IDTypeNone),
+		CreatorId: userID,
+		CreateAt:  utils.GetMillis(),
+		Name:      "file.txt",
+		Extension: ".txt",
+		Size:      size,
+	}
+}
+
+func testFileUsage(t *testing.T, store store.Store) {
+	teamID := "team-id"
+
+	t.Run("files saved with a board are added to the usage", func(t *testing.T) {
+		require.NoError(t, store.SaveFileInfoWithUsage(makeUsageFileInfo("user-1", 100), teamID, "board-1"))
+		require.NoError(t, store.SaveFileInfoWithUsage(makeUsageFileInfo("user-1", 50), teamID, "board-1"))
+		require.NoError(t, store.SaveFileInfoWithUsage(makeUsageFileInfo("user-2", 10), teamID, "board-1"))
+		require.NoError(t, store.SaveFileInfoWithUsage(makeUsageFileInfo("user-1", 1), teamID, "board-2"))
+		require.NoError(t, store.SaveFileInfoWithUsage(makeUsageFileInfo("user-1", 1000), "other-team-id", "board-3"))
+
+		usages, err := store.GetFileUsage(teamID)
+		require.NoError(t, err)
+		require.Equal(t, []*model.FileUsage{
+			{TeamID: teamID, BoardID: "board-1", UserID: "user-1", FileCount: 2, TotalSize: 150},
+			{TeamID: teamID, BoardID: "board-1", UserID: "user-2", FileCount: 1, TotalSize: 10},
+			{TeamID: teamID, BoardID: "board-2", UserID: "user-1", FileCount: 1, TotalSize: 1},
+		}, usages)
+	})
+
+	t.Run("totals of a board and its team", func(t *testing.T) {
+		boardSize, teamSize, err := store.GetFileUsageTotals(teamID, "board-1")
+		require.NoError(t, err)
+		require.Equal(t, int64(160), boardSize)
+		require.Equal(t, int64(161), teamSize)
+
+		boardSize, teamSize, err = store.GetFileUsageTotals(teamID, "board-without-files")
+		require.NoError(t, err)
+		require.Zero(t, boardSize)
+		require.Equal(t, int64(161), teamSize)
+	})
+
+	t.Run("concurrent first uploads are all counted", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- store.SaveFileInfoWithUsage(makeUsageFileInfo("user-1", 2), "concurrent-team-id", "board-5")
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		usages, err := store.GetFileUsage("concurrent-team-id")
+		require.NoError(t, err)
+		require.Equal(t, []*model.FileUsage{
+			{TeamID: "concurrent-team-id", BoardID: "board-5", UserID: "user-1", FileCount: 5, TotalSize: 10},
+		}, usages)
+	})
+
+	t.Run("usage can be released", func(t *testing.T) {
+		err := store.AddFileUsage(&model.FileUsage{BoardID: "board-1", UserID: "user-2", FileCount: -1, TotalSize: -10})
+		require.NoError(t, err)
+
+		usages, err := store.GetFileUsage(teamID)
+		require.NoError(t, err)
+		require.Len(t, usages, 2)
+		for _, usage := range usages {
+			require.NotEqual(t, "user-2", usage.UserID)
+		}
+	})
+
+	t.Run("releasing usage that was never recorded is ignored", func(t *testing.T) {
+		err := store.AddFileUsage(&model.FileUsage{BoardID: "board-4", UserID: "user-1", FileCount: -1, TotalSize: -10})
+		require.NoError(t, err)
+
+		usages, err := store.GetFileUsage(teamID)
+		require.NoError(t, err)
+		require.Len(t, usages, 2)
+	})
+
+	t.Run("the creator of a file is saved", func(t *testing.T) {
+		fileInfo := makeUsageFileInfo("user-1", 5)
+		require.NoError(t, store.SaveFileInfoWithUsage(fileInfo, teamID, "board-1"))
+
+		retrieved, err := store.GetFileInfo(fileInfo.Id)
+		require.NoError(t, err)
+		require.Equal(t, "user-1", retrieved.CreatorId)
+	})
+}
+
+func testMoveBlocksFileUsage(t *testing.T, store store.Store) {
+	teamID := "team-id"
+	_, err := store.InsertBoard(&model.Board{ID: "dest-board", TeamID: teamID, Type: model.BoardTypeOpen}, "user-1")
+	require.NoError(t, err)
+
+	fileInfo := makeUsageFileInfo("user-1", 100)
+	require.NoError(t, store.SaveFileInfoWithUsage(fileInfo, teamID, "source-board"))
+
+	image := &model.Block{
+		ID:       "image-to-move",
+		BoardID:  "source-board",
+		ParentID: "card-id",
+		Type:     model.TypeImage,
+		Fields:   map[string]interface{}{"fileId": "7" + fileInfo.Id + ".txt"},
+	}
+	require.NoError(t, store.InsertBlock(image, "user-1"))
+
+	moved := *image
+	moved.BoardID = "dest-board"
+	require.NoError(t, store.MoveBlocks([]*model.Block{&moved}, "source-board", "user-1"))
+
+	usages, err := store.GetFileUsage(teamID)
+	require.NoError(t, err)
+	require.Equal(t, []*model.FileUsage{
+		{TeamID: teamID, BoardID: "dest-board", UserID: "user-1", FileCount: 1, TotalSize: 100},
+	}, usages)
+}
+
+func testStorageQuotas(t *testing.T, store store.Store) {
+	teamID := "team-id"
+
+	t.Run("no quotas", func(t *testing.T) {
+		quotas, err := store.GetStorageQuotas(teamID)
+		require.NoError(t, err)
+		require.Empty(t, quotas)
+	})
+
+	teamQuota := &model.StorageQuota{
+		Scope:    model.StorageQuotaScopeTeam,
+		ScopeID:  teamID,
+		TeamID:   teamID,
+		MaxSize:  1000,
+		UpdateAt: 1,
+	}
+	boardQuota := &model.StorageQuota{
+		Scope:    model.StorageQuotaScopeBoard,
+		ScopeID:  "board-1",
+		TeamID:   teamID,
+		MaxSize:  100,
+		UpdateAt: 1,
+	}
+
+	t.Run("set quotas", func(t *testing.T) {
+		require.NoError(t, store.SetStorageQuota(teamQuota))
+		require.NoError(t, store.SetStorageQuota(boardQuota))
+		require.NoError(t, store.SetStorageQuota(&model.StorageQuota{
+			Scope:   model.StorageQuotaScopeTeam,
+			ScopeID: "other-team-id",
+			TeamID:  "other-team-id",
+			MaxSize: 1,
+		}))
+
+		quotas, err := store.GetStorageQuotas(teamID)
+		require.NoError(t, err)
+		require.Equal(t, []*model.StorageQuota{boardQuota, teamQuota}, quotas)
+	})
+
+	t.Run("update a quota", func(t *testing.T) {
+		boardQuota.MaxSize = 200
+		boardQuota.UpdateAt = 2
+		require.NoError(t, store.SetStorageQuota(boardQuota))
+
+		quotas, err := store.GetStorageQuotas(teamID)
+		require.NoError(t, err)
+		require.Equal(t, []*model.StorageQuota{boardQuota, teamQuota}, quotas)
+
+		quota, err := store.GetStorageQuota(model.StorageQuotaScopeBoard, "board-1")
+		require.NoError(t, err)
+		require.Equal(t, boardQuota, quota)
+	})
+
+	t.Run("delete a quota", func(t *testing.T) {
+		require.NoError(t, store.DeleteStorageQuota(model.StorageQuotaScopeBoard, "board-1"))
+
+		quotas, err := store.GetStorageQuotas(teamID)
+		require.NoError(t, err)
+		require.Equal(t, []*model.StorageQuota{teamQuota}, quotas)
+
+		_, err = store.GetStorageQuota(model.StorageQuotaScopeBoard, "board-1")
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
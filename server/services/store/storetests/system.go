@@ -32,6 +32,12 @@ func StoreTestSystemStore(t *testing.T, setup func(t *testing.T) (store.Store, f
 		defer tearDown()
 		testSetGetSystemSettings(t, store)
 	})
+
+	t.Run("CompareAndSetSystemSetting", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCompareAndSetSystemSetting(t, store)
+	})
 }
 
 func testSetGetSystemSettings(t *testing.T, store store.Store) {
@@ -63,3 +69,29 @@ func testSetGetSystemSettings(t *testing.T, store store.Store) {
 		require.Equal(t, "test-value-1", value)
 	})
 }
+
+func testCompareAndSetSystemSetting(t *testing.T, store store.Store) {
+	t.Run("claim a setting that is not set", func(t *testing.T) {
+		claimed, err := store.CompareAndSetSystemSetting("test-claim", "", "1")
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = store.CompareAndSetSystemSetting("test-claim", "", "2")
+		require.NoError(t, err)
+		require.False(t, claimed)
+	})
+
+	t.Run("claim a setting with the expected value", func(t *testing.T) {
+		claimed, err := store.CompareAndSetSystemSetting("test-claim", "1", "3")
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = store.CompareAndSetSystemSetting("test-claim", "1", "4")
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		value, err := store.GetSystemSetting("test-claim")
+		require.NoError(t, err)
+		require.Equal(t, "3", value)
+	})
+}
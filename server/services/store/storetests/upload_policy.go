@@ -0,0 +1,102 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+func StoreTestUploadPolicyStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("UploadPolicies", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testUploadPolicies(t, store)
+	})
+
+	t.Run("FileScanResults", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testFileScanResults(t, store)
+	})
+}
+
+func testUploadPolicies(t *testing.T, store store.Store) {
+	teamID := "team-id"
+
+	t.Run("no policy", func(t *testing.T) {
+		_, err := store.GetUploadPolicy(teamID)
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	policy := &model.UploadPolicy{
+		TeamID:            teamID,
+		AllowedMimeTypes:  []string{"image/*", "application/pdf"},
+		DeniedMimeTypes:   []string{},
+		AllowedExtensions: []string{},
+		DeniedExtensions:  []string{".exe"},
+		UpdateAt:          1,
+	}
+
+	t.Run("save a policy", func(t *testing.T) {
+		require.NoError(t, store.SaveUploadPolicy(policy))
+
+		retrieved, err := store.GetUploadPolicy(teamID)
+		require.NoError(t, err)
+		require.Equal(t, policy, retrieved)
+	})
+
+	t.Run("update a policy", func(t *testing.T) {
+		policy.DeniedMimeTypes = []string{"image/svg+xml"}
+		policy.UpdateAt = 2
+		require.NoError(t, store.SaveUploadPolicy(policy))
+
+		retrieved, err := store.GetUploadPolicy(teamID)
+		require.NoError(t, err)
+		require.Equal(t, policy, retrieved)
+	})
+
+	t.Run("delete a policy", func(t *testing.T) {
+		require.NoError(t, store.DeleteUploadPolicy(teamID))
+
+		_, err := store.GetUploadPolicy(teamID)
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
+
+func testFileScanResults(t *testing.T, store store.Store) {
+	t.Run("no result", func(t *testing.T) {
+		_, err := store.GetFileScanResult("file-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("save a result", func(t *testing.T) {
+		result := &model.FileScanResult{
+			FileID:         "file-id",
+			Status:         model.FileScanStatusInfected,
+			Signature:      "Eicar-Test-Signature",
+			QuarantinePath: "quarantine/boards/20240101/7file-id.txt",
+			ScannedAt:      1,
+		}
+		require.NoError(t, store.SaveFileScanResult(result))
+
+		retrieved, err := store.GetFileScanResult("file-id")
+		require.NoError(t, err)
+		require.Equal(t, result, retrieved)
+	})
+
+	t.Run("a new scan replaces the result", func(t *testing.T) {
+		result := &model.FileScanResult{
+			FileID:    "file-id",
+			Status:    model.FileScanStatusClean,
+			ScannedAt: 2,
+		}
+		require.NoError(t, store.SaveFileScanResult(result))
+
+		retrieved, err := store.GetFileScanResult("file-id")
+		require.NoError(t, err)
+		require.Equal(t, result, retrieved)
+	})
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var ErrHostNotAllowed = errors.New("webhook host is not allowed")
+
+// reservedNetworks are the ranges that are not covered by the net.IP helpers
+// but must not be reachable from webhooks either.
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// guardedClient returns an http client that resolves the host of each
+// request itself and only connects to the addresses permitted by
+// checkAddress, so that a host can't be pointed at an internal address
+// between the check and the connection. No proxy is used for the same reason.
+func (wh *Client) guardedClient() *http.Client {
+	dialer := &net.Dialer{Timeout: postJSONTimeout}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			if err = wh.checkHost(host); err != nil {
+				return nil, err
+			}
+
+			ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+
+			var lastErr error
+			for _, ip := range ips {
+				if lastErr = wh.checkAddress(host, ip.IP); lastErr != nil {
+					continue
+				}
+				conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+				if err != nil {
+					lastErr = err
+					continue
+				}
+				return conn, nil
+			}
+			if lastErr == nil {
+				lastErr = fmt.Errorf("no address found for %s", host)
+			}
+			return nil, lastErr
+		},
+		TLSHandshakeTimeout: postJSONTimeout,
+	}
+
+	return &http.Client{
+		Timeout:   postJSONTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkHost returns an error if the host name is denied, or if allowed
+// hosts are configured and the host is not one of them.
+func (wh *Client) checkHost(host string) error {
+	if matchHost(wh.config.AutomationWebhookDeniedHosts, host, nil) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+	}
+	if len(wh.config.AutomationWebhookAllowedHosts) > 0 && !matchHost(wh.config.AutomationWebhookAllowedHosts, host, nil) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+	}
+	return nil
+}
+
+// checkAddress returns an error if an address the host resolved to is
+// denied, or is internal and the host is not explicitly allowed.
+func (wh *Client) checkAddress(host string, ip net.IP) error {
+	if matchHost(wh.config.AutomationWebhookDeniedHosts, host, ip) {
+		return fmt.Errorf("%w: %s (%s)", ErrHostNotAllowed, host, ip)
+	}
+	if isInternalIP(ip) && !matchHost(wh.config.AutomationWebhookAllowedHosts, host, ip) {
+		return fmt.Errorf("%w: %s resolves to the internal address %s", ErrHostNotAllowed, host, ip)
+	}
+	return nil
+}
+
+// matchHost returns true if one of the entries is the host name, or an
+// address or CIDR range that contains ip.
+func matchHost(entries []string, host string, ip net.IP) bool {
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.EqualFold(entry, host) {
+			return true
+		}
+		if ip == nil {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if entryIP := net.ParseIP(entry); entryIP != nil && entryIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func isInternalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
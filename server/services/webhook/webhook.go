@@ -3,8 +3,10 @@ package webhook
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/config"
@@ -12,6 +14,8 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 )
 
+const postJSONTimeout = 10 * time.Second
+
 // NotifyUpdate calls webhooks.
 func (wh *Client) NotifyUpdate(block *model.Block) {
 	if len(wh.config.WebhookUpdate) < 1 {
@@ -31,6 +35,31 @@ func (wh *Client) NotifyUpdate(block *model.Block) {
 	}
 }
 
+// PostJSON calls a single webhook url with the JSON encoding of payload.
+// Unlike NotifyUpdate, failures are returned to the caller. The url is
+// provided by board members, so only the hosts permitted by the automation
+// webhook settings may be called, and redirects are not followed.
+func (wh *Client) PostJSON(url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal webhook payload: %w", err)
+	}
+
+	resp, err := wh.guardedClient().Post(url, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.ReadAll(resp.Body)
+
+	wh.logger.Debug("webhook.PostJSON", mlog.String("url", url), mlog.Int("status", resp.StatusCode))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook %s returned status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
 // Client is a webhook client.
 type Client struct {
 	config *config.Configuration
@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -37,3 +38,107 @@ func TestClientUpdateNotify(t *testing.T) {
 		t.Error("webhook url not be notified")
 	}
 }
+
+func TestClientPostJSON(t *testing.T) {
+	logger, _ := mlog.NewLogger()
+	defer func() {
+		err := logger.Shutdown()
+		assert.NoError(t, err)
+	}()
+
+	client := NewClient(&config.Configuration{
+		AutomationWebhookAllowedHosts: []string{"127.0.0.1"},
+	}, logger)
+
+	t.Run("success", func(t *testing.T) {
+		var contentType string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+		}))
+		defer ts.Close()
+
+		err := client.PostJSON(ts.URL, map[string]string{"cardId": "card1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "application/json", contentType)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer ts.Close()
+
+		err := client.PostJSON(ts.URL, map[string]string{"cardId": "card1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		var redirected bool
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			redirected = true
+		}))
+		defer target.Close()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+		}))
+		defer ts.Close()
+
+		err := client.PostJSON(ts.URL, map[string]string{"cardId": "card1"})
+		assert.Error(t, err)
+		assert.False(t, redirected)
+	})
+
+	t.Run("internal addresses are refused", func(t *testing.T) {
+		var isCalled bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			isCalled = true
+		}))
+		defer ts.Close()
+
+		defaultClient := NewClient(&config.Configuration{}, logger)
+		err := defaultClient.PostJSON(ts.URL, map[string]string{"cardId": "card1"})
+		assert.ErrorIs(t, err, ErrHostNotAllowed)
+		assert.False(t, isCalled)
+	})
+
+	t.Run("denied hosts are refused", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		deniedClient := NewClient(&config.Configuration{
+			AutomationWebhookAllowedHosts: []string{"127.0.0.1"},
+			AutomationWebhookDeniedHosts:  []string{"127.0.0.0/8"},
+		}, logger)
+		err := deniedClient.PostJSON(ts.URL, map[string]string{"cardId": "card1"})
+		assert.ErrorIs(t, err, ErrHostNotAllowed)
+	})
+
+	t.Run("hosts that are not allowed are refused", func(t *testing.T) {
+		allowedClient := NewClient(&config.Configuration{
+			AutomationWebhookAllowedHosts: []string{"hooks.example.com"},
+		}, logger)
+		err := allowedClient.PostJSON("http://other.example.com/hook", map[string]string{"cardId": "card1"})
+		assert.ErrorIs(t, err, ErrHostNotAllowed)
+	})
+}
+
+func TestIsInternalIP(t *testing.T) {
+	testCases := map[string]bool{
+		"127.0.0.1":        true,
+		"10.1.2.3":         true,
+		"172.16.0.1":       true,
+		"192.168.1.1":      true,
+		"169.254.169.254":  true,
+		"100.64.0.1":       true,
+		"0.0.0.0":          true,
+		"::1":              true,
+		"fe80::1":          true,
+		"fd00::1":          true,
+		"::ffff:127.0.0.1": true,
+		"8.8.8.8":          false,
+		"2001:4860::8888":  false,
+	}
+	for address, internal := range testCases {
+		assert.Equal(t, internal, isInternalIP(net.ParseIP(address)), address)
+	}
+}
@@ -3,7 +3,10 @@
 
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 // MakeCardLink creates fully qualified card links based on card id and parents.
 func MakeCardLink(serverRoot string, teamID string, boardID string, cardID string) string {
@@ -13,3 +16,8 @@ func MakeCardLink(serverRoot string, teamID string, boardID string, cardID strin
 func MakeBoardLink(serverRoot string, teamID string, board string) string {
 	return fmt.Sprintf("%s/team/%s/%s", serverRoot, teamID, board)
 }
+
+// MakeFileLink creates fully qualified links to download an uploaded file.
+func MakeFileLink(serverRoot string, teamID string, boardID string, filename string) string {
+	return fmt.Sprintf("%s/api/v2/files/teams/%s/%s/%s", serverRoot, teamID, boardID, url.PathEscape(filename))
+}
@@ -11,10 +11,19 @@ const (
 	websocketActionUnsubscribeTeam          = "UNSUBSCRIBE_TEAM"
 	websocketActionSubscribeBlocks          = "SUBSCRIBE_BLOCKS"
 	websocketActionUnsubscribeBlocks        = "UNSUBSCRIBE_BLOCKS"
+	websocketActionResume                   = "RESUME"
+	websocketActionResync                   = "RESYNC"
+	websocketActionSetPresence              = "SET_PRESENCE"
+	websocketActionSetEditing               = "SET_EDITING"
+	websocketActionUpdatePresence           = "UPDATE_PRESENCE"
+	websocketActionUpdateEditing            = "UPDATE_EDITING"
 	websocketActionUpdateBoard              = "UPDATE_BOARD"
 	websocketActionUpdateMember             = "UPDATE_MEMBER"
 	websocketActionDeleteMember             = "DELETE_MEMBER"
 	websocketActionUpdateBlock              = "UPDATE_BLOCK"
+	websocketActionUpdateBlocks             = "UPDATE_BLOCKS"
+	websocketActionUpdateCommentReaction    = "UPDATE_COMMENT_REACTION"
+	websocketActionUpdateInbox              = "UPDATE_INBOX"
 	websocketActionUpdateConfig             = "UPDATE_CLIENT_CONFIG"
 	websocketActionUpdateCategory           = "UPDATE_CATEGORY"
 	websocketActionUpdateCategoryBoard      = "UPDATE_BOARD_CATEGORY"
@@ -31,7 +40,9 @@ type Store interface {
 
 type Adapter interface {
 	BroadcastBlockChange(teamID string, block *model.Block)
+	BroadcastBlocksChange(teamID, boardID string, blocks []*model.Block)
 	BroadcastBlockDelete(teamID, blockID, boardID string)
+	BroadcastCommentReactionChange(teamID string, reaction *model.CommentReaction, deleted bool)
 	BroadcastBoardChange(teamID string, board *model.Board)
 	BroadcastBoardDelete(teamID, boardID string)
 	BroadcastMemberChange(teamID, boardID string, member *model.BoardMember)
@@ -43,4 +54,6 @@ type Adapter interface {
 	BroadcastSubscriptionChange(teamID string, subscription *model.Subscription)
 	BroadcastCategoryReorder(teamID, userID string, categoryOrder []string)
 	BroadcastCategoryBoardsReorder(teamID, userID, categoryID string, boardsOrder []string)
+	BroadcastInboxChange(teamID, userID string, item *model.InboxItem, unreadCount int)
+	GetBoardPresence(boardID string) []*model.BoardPresence
 }
@@ -0,0 +1,271 @@
+package ws
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// ClusterBus relays messages between the nodes of a standalone
+// cluster.
+type ClusterBus interface {
+	// Publish sends a message to all the nodes of the cluster.
+	Publish(message []byte) error
+	// Subscribe registers a function that receives the messages
+	// published by any node, including the current one.
+	Subscribe(handler func(message []byte))
+}
+
+type clusterBroadcastType string
+
+const (
+	clusterBroadcastBlockChange           clusterBroadcastType = "blockChange"
+	clusterBroadcastBlocksChange          clusterBroadcastType = "blocksChange"
+	clusterBroadcastCommentReaction       clusterBroadcastType = "commentReaction"
+	clusterBroadcastCategoryChange        clusterBroadcastType = "categoryChange"
+	clusterBroadcastCategoryReorder       clusterBroadcastType = "categoryReorder"
+	clusterBroadcastCategoryBoardsReorder clusterBroadcastType = "categoryBoardsReorder"
+	clusterBroadcastCategoryBoardChange   clusterBroadcastType = "categoryBoardChange"
+	clusterBroadcastInboxChange           clusterBroadcastType = "inboxChange"
+	clusterBroadcastConfigChange          clusterBroadcastType = "configChange"
+	clusterBroadcastBoardChange           clusterBroadcastType = "boardChange"
+	clusterBroadcastMemberChange          clusterBroadcastType = "memberChange"
+	clusterBroadcastMemberDelete          clusterBroadcastType = "memberDelete"
+	clusterBroadcastPresence              clusterBroadcastType = "presence"
+	clusterBroadcastEditing               clusterBroadcastType = "editing"
+)
+
+// clusterBroadcast is a broadcast relayed to the other nodes of the
+// cluster, which repeat it to their own listeners. Only the fields
+// used by its type are set.
+type clusterBroadcast struct {
+	Origin          string                              `json:"origin"`
+	Type            clusterBroadcastType                `json:"type"`
+	TeamID          string                              `json:"teamId,omitempty"`
+	BoardID         string                              `json:"boardId,omitempty"`
+	UserID          string                              `json:"userId,omitempty"`
+	CategoryID      string                              `json:"categoryId,omitempty"`
+	Block           *model.Block                        `json:"block,omitempty"`
+	Blocks          []*model.Block                      `json:"blocks,omitempty"`
+	Board           *model.Board                        `json:"board,omitempty"`
+	Member          *model.BoardMember                  `json:"member,omitempty"`
+	Reaction        *model.CommentReaction              `json:"reaction,omitempty"`
+	Deleted         bool                                `json:"deleted,omitempty"`
+	Category        *model.Category                     `json:"category,omitempty"`
+	BoardCategories []*model.BoardCategoryWebsocketData `json:"boardCategories,omitempty"`
+	Order           []string                            `json:"order,omitempty"`
+	InboxItem       *model.InboxItem                    `json:"inboxItem,omitempty"`
+	UnreadCount     int                                 `json:"unreadCount,omitempty"`
+	ClientConfig    *model.ClientConfig                 `json:"clientConfig,omitempty"`
+	Presence        *model.BoardPresence                `json:"presence,omitempty"`
+	Editing         *model.EditingSignal                `json:"editing,omitempty"`
+}
+
+// SetClusterBus makes the server relay its broadcasts to the other
+// nodes of the cluster through the bus, and repeat the ones it
+// receives from them.
+func (ws *Server) SetClusterBus(bus ClusterBus) {
+	ws.nodeID = utils.NewID(utils.IDTypeNone)
+	ws.clusterBus = bus
+	bus.Subscribe(ws.handleClusterMessage)
+}
+
+func (ws *Server) publishToCluster(broadcast *clusterBroadcast) {
+	if ws.clusterBus == nil {
+		return
+	}
+
+	broadcast.Origin = ws.nodeID
+	message, err := json.Marshal(broadcast)
+	if err != nil {
+		ws.logger.Error("cannot marshal cluster broadcast",
+			mlog.String("type", string(broadcast.Type)),
+			mlog.Err(err),
+		)
+		return
+	}
+
+	if err := ws.clusterBus.Publish(message); err != nil {
+		ws.logger.Error("cannot publish cluster broadcast",
+			mlog.String("type", string(broadcast.Type)),
+			mlog.Err(err),
+		)
+	}
+}
+
+func (ws *Server) handleClusterMessage(message []byte) {
+	var broadcast clusterBroadcast
+	if err := json.Unmarshal(message, &broadcast); err != nil {
+		ws.logger.Error("cannot unmarshal cluster broadcast", mlog.Err(err))
+		return
+	}
+
+	// the broadcasts of this node have already been sent
+	if broadcast.Origin == ws.nodeID {
+		return
+	}
+
+	ws.logger.Trace("received cluster broadcast",
+		mlog.String("type", string(broadcast.Type)),
+		mlog.String("origin", broadcast.Origin),
+	)
+
+	switch broadcast.Type {
+	case clusterBroadcastBlockChange:
+		ws.broadcastBlockChangeLocal(broadcast.TeamID, broadcast.Block)
+	case clusterBroadcastBlocksChange:
+		ws.broadcastBlocksChangeLocal(broadcast.TeamID, broadcast.BoardID, broadcast.Blocks)
+	case clusterBroadcastCommentReaction:
+		ws.broadcastCommentReactionChangeLocal(broadcast.TeamID, broadcast.Reaction, broadcast.Deleted)
+	case clusterBroadcastCategoryChange:
+		ws.broadcastCategoryChangeLocal(*broadcast.Category)
+	case clusterBroadcastCategoryReorder:
+		ws.broadcastCategoryReorderLocal(broadcast.TeamID, broadcast.UserID, broadcast.Order)
+	case clusterBroadcastCategoryBoardsReorder:
+		ws.broadcastCategoryBoardsReorderLocal(broadcast.TeamID, broadcast.UserID, broadcast.CategoryID, broadcast.Order)
+	case clusterBroadcastCategoryBoardChange:
+		ws.broadcastCategoryBoardChangeLocal(broadcast.TeamID, broadcast.UserID, broadcast.BoardCategories)
+	case clusterBroadcastInboxChange:
+		ws.broadcastInboxChangeLocal(broadcast.TeamID, broadcast.UserID, broadcast.InboxItem, broadcast.UnreadCount)
+	case clusterBroadcastConfigChange:
+		ws.broadcastConfigChangeLocal(*broadcast.ClientConfig)
+	case clusterBroadcastBoardChange:
+		ws.broadcastBoardChangeLocal(broadcast.TeamID, broadcast.Board)
+	case clusterBroadcastMemberChange:
+		ws.broadcastMemberChangeLocal(broadcast.TeamID, broadcast.BoardID, broadcast.Member)
+	case clusterBroadcastMemberDelete:
+		ws.broadcastMemberDeleteLocal(broadcast.TeamID, broadcast.BoardID, broadcast.UserID)
+	case clusterBroadcastPresence:
+		ws.presence.setRelayed(broadcast.Origin, broadcast.TeamID, broadcast.Presence)
+		ws.broadcastPresenceLocal(broadcast.TeamID, broadcast.Presence)
+	case clusterBroadcastEditing:
+		ws.broadcastEditingLocal(broadcast.TeamID, broadcast.Editing, nil)
+	default:
+		ws.logger.Warn("unknown cluster broadcast type", mlog.String("type", string(broadcast.Type)))
+	}
+}
+
+// BroadcastBlockChange broadcasts update messages to clients.
+func (ws *Server) BroadcastBlockChange(teamID string, block *model.Block) {
+	ws.broadcastBlockChangeLocal(teamID, block)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:   clusterBroadcastBlockChange,
+		TeamID: teamID,
+		Block:  block,
+	})
+}
+
+// BroadcastBlocksChange broadcasts the changes of several blocks of a board
+// to clients as a single message.
+func (ws *Server) BroadcastBlocksChange(teamID, boardID string, blocks []*model.Block) {
+	ws.broadcastBlocksChangeLocal(teamID, boardID, blocks)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:    clusterBroadcastBlocksChange,
+		TeamID:  teamID,
+		BoardID: boardID,
+		Blocks:  blocks,
+	})
+}
+
+// BroadcastCommentReactionChange broadcasts a reaction added to or removed
+// from a comment to the clients of its board.
+func (ws *Server) BroadcastCommentReactionChange(teamID string, reaction *model.CommentReaction, deleted bool) {
+	ws.broadcastCommentReactionChangeLocal(teamID, reaction, deleted)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:     clusterBroadcastCommentReaction,
+		TeamID:   teamID,
+		Reaction: reaction,
+		Deleted:  deleted,
+	})
+}
+
+func (ws *Server) BroadcastCategoryChange(category model.Category) {
+	ws.broadcastCategoryChangeLocal(category)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:     clusterBroadcastCategoryChange,
+		Category: &category,
+	})
+}
+
+func (ws *Server) BroadcastCategoryReorder(teamID, userID string, categoryOrder []string) {
+	ws.broadcastCategoryReorderLocal(teamID, userID, categoryOrder)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:   clusterBroadcastCategoryReorder,
+		TeamID: teamID,
+		UserID: userID,
+		Order:  categoryOrder,
+	})
+}
+
+func (ws *Server) BroadcastCategoryBoardsReorder(teamID, userID, categoryID string, boardOrder []string) {
+	ws.broadcastCategoryBoardsReorderLocal(teamID, userID, categoryID, boardOrder)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:       clusterBroadcastCategoryBoardsReorder,
+		TeamID:     teamID,
+		UserID:     userID,
+		CategoryID: categoryID,
+		Order:      boardOrder,
+	})
+}
+
+func (ws *Server) BroadcastCategoryBoardChange(teamID, userID string, boardCategories []*model.BoardCategoryWebsocketData) {
+	ws.broadcastCategoryBoardChangeLocal(teamID, userID, boardCategories)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:            clusterBroadcastCategoryBoardChange,
+		TeamID:          teamID,
+		UserID:          userID,
+		BoardCategories: boardCategories,
+	})
+}
+
+func (ws *Server) BroadcastInboxChange(teamID, userID string, item *model.InboxItem, unreadCount int) {
+	ws.broadcastInboxChangeLocal(teamID, userID, item, unreadCount)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:        clusterBroadcastInboxChange,
+		TeamID:      teamID,
+		UserID:      userID,
+		InboxItem:   item,
+		UnreadCount: unreadCount,
+	})
+}
+
+// BroadcastConfigChange broadcasts update messages to clients.
+func (ws *Server) BroadcastConfigChange(clientConfig model.ClientConfig) {
+	ws.broadcastConfigChangeLocal(clientConfig)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:         clusterBroadcastConfigChange,
+		ClientConfig: &clientConfig,
+	})
+}
+
+func (ws *Server) BroadcastBoardChange(teamID string, board *model.Board) {
+	ws.broadcastBoardChangeLocal(teamID, board)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:   clusterBroadcastBoardChange,
+		TeamID: teamID,
+		Board:  board,
+	})
+}
+
+func (ws *Server) BroadcastMemberChange(teamID, boardID string, member *model.BoardMember) {
+	ws.broadcastMemberChangeLocal(teamID, boardID, member)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:    clusterBroadcastMemberChange,
+		TeamID:  teamID,
+		BoardID: boardID,
+		Member:  member,
+	})
+}
+
+func (ws *Server) BroadcastMemberDelete(teamID, boardID, userID string) {
+	ws.broadcastMemberDeleteLocal(teamID, boardID, userID)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:    clusterBroadcastMemberDelete,
+		TeamID:  teamID,
+		BoardID: boardID,
+		UserID:  userID,
+	})
+}
@@ -0,0 +1,138 @@
+package ws
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	wsMocks "github.com/mattermost/focalboard/server/ws/mocks"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryClusterBus relays the messages between the servers of the
+// same process.
+type memoryClusterBus struct {
+	mu       sync.Mutex
+	handlers []func(message []byte)
+}
+
+func (b *memoryClusterBus) Publish(message []byte) error {
+	b.mu.Lock()
+	handlers := b.handlers
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(message)
+	}
+	return nil
+}
+
+func (b *memoryClusterBus) Subscribe(handler func(message []byte)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// connectTestClient starts an HTTP server for a websocket server and
+// returns a client authenticated in single user mode and subscribed
+// to a team.
+func connectTestClient(t *testing.T, server *Server, teamID string) *websocket.Conn {
+	r := mux.NewRouter()
+	server.RegisterRoutes(r)
+	httpServer := httptest.NewServer(r)
+	t.Cleanup(httpServer.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: "token"}))
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionSubscribeTeam, TeamID: teamID}))
+
+	require.Eventually(t, func() bool {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		return len(server.listenersByTeam[teamID]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	return conn
+}
+
+func TestClusterBroadcast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := wsMocks.NewMockStore(ctrl)
+	store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{{UserID: model.SingleUser}}, nil).AnyTimes()
+
+	bus := &memoryClusterBus{}
+	serverA := NewServer(&auth.Auth{}, "token", false, mlog.CreateConsoleTestLogger(t), store)
+	serverA.SetClusterBus(bus)
+	serverB := NewServer(&auth.Auth{}, "token", false, mlog.CreateConsoleTestLogger(t), store)
+	serverB.SetClusterBus(bus)
+
+	clientA := connectTestClient(t, serverA, "team-id")
+	clientB := connectTestClient(t, serverB, "team-id")
+
+	t.Run("broadcasts reach the clients of all the nodes", func(t *testing.T) {
+		serverA.BroadcastBlockChange("team-id", &model.Block{ID: "block-id", BoardID: "board-id"})
+
+		for _, client := range []*websocket.Conn{clientA, clientB} {
+			var message UpdateBlockMsg
+			require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+			require.NoError(t, client.ReadJSON(&message))
+			require.Equal(t, websocketActionUpdateBlock, message.Action)
+			require.Equal(t, "block-id", message.Block.ID)
+			require.NotZero(t, message.Seq)
+		}
+	})
+
+	t.Run("relayed events can be replayed by the receiving node", func(t *testing.T) {
+		serverB.BroadcastBoardChange("team-id", &model.Board{ID: "board-id"})
+
+		var message UpdateBoardMsg
+		require.NoError(t, clientA.SetReadDeadline(time.Now().Add(time.Second)))
+		require.NoError(t, clientA.ReadJSON(&message))
+		require.Equal(t, "board-id", message.Board.ID)
+
+		events, _, ok := serverA.getStream("team-id").eventsSince(message.Seq - 1)
+		require.True(t, ok)
+		require.Len(t, events, 1)
+	})
+
+	t.Run("user messages are relayed", func(t *testing.T) {
+		serverB.BroadcastInboxChange("team-id", model.SingleUser, nil, 3)
+
+		var message UpdateInboxMsg
+		require.NoError(t, clientA.SetReadDeadline(time.Now().Add(time.Second)))
+		require.NoError(t, clientA.ReadJSON(&message))
+		require.Equal(t, websocketActionUpdateInbox, message.Action)
+		require.Equal(t, 3, message.UnreadCount)
+	})
+
+	t.Run("relayed presence is recorded by the receiving node", func(t *testing.T) {
+		require.NoError(t, clientB.WriteJSON(WebsocketCommand{
+			Action:  websocketActionSetPresence,
+			TeamID:  "team-id",
+			BoardID: "board-id",
+		}))
+
+		require.Eventually(t, func() bool {
+			return len(serverA.GetBoardPresence("board-id")) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, clientB.WriteJSON(WebsocketCommand{Action: websocketActionSetPresence}))
+
+		require.Eventually(t, func() bool {
+			return len(serverA.GetBoardPresence("board-id")) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -17,6 +17,25 @@ type UpdateBlockMsg struct {
 	Action string       `json:"action"`
 	TeamID string       `json:"teamId"`
 	Block  *model.Block `json:"block"`
+	Seq    int64        `json:"seq,omitempty"`
+}
+
+// UpdateBlocksMsg is sent when several blocks of a board are updated at once.
+type UpdateBlocksMsg struct {
+	Action  string         `json:"action"`
+	TeamID  string         `json:"teamId"`
+	BoardID string         `json:"boardId"`
+	Blocks  []*model.Block `json:"blocks"`
+	Seq     int64          `json:"seq,omitempty"`
+}
+
+// UpdateCommentReactionMsg is sent when a reaction is added to or removed from a comment.
+type UpdateCommentReactionMsg struct {
+	Action   string                 `json:"action"`
+	TeamID   string                 `json:"teamId"`
+	Reaction *model.CommentReaction `json:"reaction"`
+	Deleted  bool                   `json:"deleted"`
+	Seq      int64                  `json:"seq,omitempty"`
 }
 
 // UpdateBoardMsg is sent on block updates.
@@ -24,6 +43,7 @@ type UpdateBoardMsg struct {
 	Action string       `json:"action"`
 	TeamID string       `json:"teamId"`
 	Board  *model.Board `json:"board"`
+	Seq    int64        `json:"seq,omitempty"`
 }
 
 // UpdateMemberMsg is sent on membership updates.
@@ -31,6 +51,41 @@ type UpdateMemberMsg struct {
 	Action string             `json:"action"`
 	TeamID string             `json:"teamId"`
 	Member *model.BoardMember `json:"member"`
+	Seq    int64              `json:"seq,omitempty"`
+}
+
+// UpdateInboxMsg is sent to a user when an item is added to their inbox or
+// when items are marked as read.
+type UpdateInboxMsg struct {
+	Action      string           `json:"action"`
+	TeamID      string           `json:"teamId"`
+	Item        *model.InboxItem `json:"item,omitempty"`
+	UnreadCount int              `json:"unreadCount"`
+}
+
+// ResyncMsg is sent to a client that tries to resume a team stream
+// from a sequence number that cannot be replayed. The client should
+// fetch the team data again and use Seq as its new resume point.
+type ResyncMsg struct {
+	Action string `json:"action"`
+	TeamID string `json:"teamId"`
+	Seq    int64  `json:"seq"`
+}
+
+// UpdatePresenceMsg is sent when a user joins, leaves or becomes idle
+// on a board.
+type UpdatePresenceMsg struct {
+	Action   string               `json:"action"`
+	TeamID   string               `json:"teamId"`
+	Presence *model.BoardPresence `json:"presence"`
+}
+
+// UpdateEditingMsg is sent when a user starts or stops editing a field
+// of a card.
+type UpdateEditingMsg struct {
+	Action  string               `json:"action"`
+	TeamID  string               `json:"teamId"`
+	Editing *model.EditingSignal `json:"editing"`
 }
 
 // UpdateSubscription is sent on subscription updates.
@@ -58,6 +113,12 @@ type WebsocketCommand struct {
 	Token     string   `json:"token"`
 	ReadToken string   `json:"readToken"`
 	BlockIDs  []string `json:"blockIds"`
+	Seq       int64    `json:"seq"`
+	BoardID   string   `json:"boardId"`
+	CardID    string   `json:"cardId"`
+	Idle      bool     `json:"idle"`
+	Field     string   `json:"field"`
+	Editing   bool     `json:"editing"`
 }
 
 type CategoryReorderMessage struct {
@@ -47,6 +47,8 @@ type PluginAdapter struct {
 	subscriptionsMU  sync.RWMutex
 	listenersByTeam  map[string][]*PluginAdapterClient
 	listenersByBlock map[string][]*PluginAdapterClient
+
+	presence *presenceTracker
 }
 
 // servicesAPI is the interface required by the PluginAdapter to interact with
@@ -69,6 +71,7 @@ func NewPluginAdapter(api servicesAPI, auth auth.AuthInterface, store Store, log
 		listenersByBlock:  make(map[string][]*PluginAdapterClient),
 		listenersMU:       sync.RWMutex{},
 		subscriptionsMU:   sync.RWMutex{},
+		presence:          newPresenceTracker(),
 	}
 }
 
@@ -303,6 +306,7 @@ func (pa *PluginAdapter) OnWebSocketDisconnect(webConnID, userID string) {
 	}
 
 	atomic.StoreInt64(&pac.inactiveAt, mmModel.GetMillis())
+	pa.broadcastPresenceChanges(pa.presence.remove(webConnID))
 }
 
 func commandFromRequest(req *mmModel.WebSocketRequest) (*WebsocketCommand, error) {
@@ -322,6 +326,12 @@ func commandFromRequest(req *mmModel.WebSocketRequest) (*WebsocketCommand, error
 		c.BlockIDs = blockIDs.([]string)
 	}
 
+	c.BoardID, _ = req.Data["boardId"].(string)
+	c.CardID, _ = req.Data["cardId"].(string)
+	c.Idle, _ = req.Data["idle"].(bool)
+	c.Field, _ = req.Data["field"].(string)
+	c.Editing, _ = req.Data["editing"].(bool)
+
 	return c, nil
 }
 
@@ -364,6 +374,17 @@ func (pa *PluginAdapter) WebSocketMessageHasBeenPosted(webConnID, userID string,
 			mlog.String("teamID", command.TeamID),
 		)
 
+	// Resuming is not needed in plugin mode, as the Mattermost
+	// websocket already replays the missed events when a client
+	// reconnects
+	case websocketActionResume:
+		pa.logger.Debug(`Command not implemented in plugin mode`,
+			mlog.String("command", command.Action),
+			mlog.String("webConnID", webConnID),
+			mlog.String("userID", userID),
+			mlog.String("teamID", command.TeamID),
+		)
+
 	case websocketActionSubscribeTeam:
 		pa.logger.Debug(`Command not implemented in plugin mode`,
 			mlog.String("command", command.Action),
@@ -385,7 +406,98 @@ func (pa *PluginAdapter) WebSocketMessageHasBeenPosted(webConnID, userID string,
 		)
 
 		pa.unsubscribeListenerFromTeam(pac, command.TeamID)
+	case websocketActionSetPresence:
+		pa.logger.Debug(`Command: SET_PRESENCE`,
+			mlog.String("webConnID", webConnID),
+			mlog.String("userID", userID),
+			mlog.String("teamID", command.TeamID),
+			mlog.String("boardID", command.BoardID),
+		)
+
+		pa.setListenerPresence(pac, command)
+	case websocketActionSetEditing:
+		pa.logger.Debug(`Command: SET_EDITING`,
+			mlog.String("webConnID", webConnID),
+			mlog.String("userID", userID),
+			mlog.String("teamID", command.TeamID),
+			mlog.String("boardID", command.BoardID),
+		)
+
+		pa.broadcastEditing(pac, command)
+	}
+}
+
+// setListenerPresence updates the board and card the listener is
+// looking at, or leaves its board if the command has no board.
+func (pa *PluginAdapter) setListenerPresence(pac *PluginAdapterClient, command *WebsocketCommand) {
+	if command.BoardID == "" {
+		pa.broadcastPresenceChanges(pa.presence.remove(pac.webConnID))
+		return
+	}
+
+	if !pac.isSubscribedToTeam(command.TeamID) {
+		return
+	}
+
+	isMember, err := isBoardMember(pa.store, command.BoardID, pac.userID)
+	if err != nil {
+		pa.logger.Error("error getting members for board",
+			mlog.String("method", "setListenerPresence"),
+			mlog.String("boardID", command.BoardID),
+			mlog.Err(err),
+		)
+		return
+	}
+	if !isMember {
+		return
+	}
+
+	changes := pa.presence.set(pac.webConnID, pac.userID, command.TeamID, command.BoardID, command.CardID, command.Idle)
+	pa.broadcastPresenceChanges(changes)
+}
+
+// broadcastPresenceChanges sends the presence of users to the members
+// of their boards on all the nodes.
+func (pa *PluginAdapter) broadcastPresenceChanges(changes []presenceChange) {
+	for _, change := range changes {
+		message := UpdatePresenceMsg{
+			Action:   websocketActionUpdatePresence,
+			TeamID:   change.teamID,
+			Presence: change.presence,
+		}
+
+		pa.sendBoardMessage(change.teamID, change.presence.BoardID, utils.StructToMap(message))
+	}
+}
+
+// broadcastEditing sends to the members of a board that the user of
+// the listener started or stopped editing a field of a card. Only
+// listeners that are on the board can send these signals.
+func (pa *PluginAdapter) broadcastEditing(pac *PluginAdapterClient, command *WebsocketCommand) {
+	teamID, boardID, ok := pa.presence.getBoard(pac.webConnID)
+	if !ok || boardID != command.BoardID {
+		return
+	}
+
+	message := UpdateEditingMsg{
+		Action: websocketActionUpdateEditing,
+		TeamID: teamID,
+		Editing: &model.EditingSignal{
+			UserID:  pac.userID,
+			BoardID: boardID,
+			CardID:  command.CardID,
+			Field:   command.Field,
+			Editing: command.Editing,
+		},
 	}
+
+	pa.sendBoardMessage(teamID, boardID, utils.StructToMap(message))
+}
+
+// GetBoardPresence returns the users currently looking at a board.
+// Only the users connected to this node are returned.
+func (pa *PluginAdapter) GetBoardPresence(boardID string) []*model.BoardPresence {
+	return pa.presence.boardPresence(boardID)
 }
 
 // sendMessageToAll will send a websocket message to all clients on all nodes.
@@ -469,6 +581,40 @@ func (pa *PluginAdapter) BroadcastBlockChange(teamID string, block *model.Block)
 	pa.sendBoardMessage(teamID, block.BoardID, utils.StructToMap(message))
 }
 
+func (pa *PluginAdapter) BroadcastBlocksChange(teamID, boardID string, blocks []*model.Block) {
+	pa.logger.Trace("BroadcastingBlocksChange",
+		mlog.String("teamID", teamID),
+		mlog.String("boardID", boardID),
+		mlog.Int("blockCount", len(blocks)),
+	)
+
+	message := UpdateBlocksMsg{
+		Action:  websocketActionUpdateBlocks,
+		TeamID:  teamID,
+		BoardID: boardID,
+		Blocks:  blocks,
+	}
+
+	pa.sendBoardMessage(teamID, boardID, utils.StructToMap(message))
+}
+
+func (pa *PluginAdapter) BroadcastCommentReactionChange(teamID string, reaction *model.CommentReaction, deleted bool) {
+	pa.logger.Trace("BroadcastingCommentReactionChange",
+		mlog.String("teamID", teamID),
+		mlog.String("boardID", reaction.BoardID),
+		mlog.String("commentID", reaction.CommentID),
+	)
+
+	message := UpdateCommentReactionMsg{
+		Action:   websocketActionUpdateCommentReaction,
+		TeamID:   teamID,
+		Reaction: reaction,
+		Deleted:  deleted,
+	}
+
+	pa.sendBoardMessage(teamID, reaction.BoardID, utils.StructToMap(message))
+}
+
 func (pa *PluginAdapter) BroadcastCategoryChange(category model.Category) {
 	pa.logger.Debug("BroadcastCategoryChange",
 		mlog.String("userID", category.UserID),
@@ -574,6 +720,32 @@ func (pa *PluginAdapter) BroadcastCategoryBoardChange(teamID, userID string, boa
 	pa.sendUserMessageSkipCluster(websocketActionUpdateCategoryBoard, utils.StructToMap(message), userID)
 }
 
+func (pa *PluginAdapter) BroadcastInboxChange(teamID, userID string, item *model.InboxItem, unreadCount int) {
+	pa.logger.Debug("BroadcastInboxChange",
+		mlog.String("userID", userID),
+		mlog.String("teamID", teamID),
+		mlog.Int("unreadCount", unreadCount),
+	)
+
+	message := UpdateInboxMsg{
+		Action:      websocketActionUpdateInbox,
+		TeamID:      teamID,
+		Item:        item,
+		UnreadCount: unreadCount,
+	}
+	payload := utils.StructToMap(message)
+	go func() {
+		clusterMessage := &ClusterMessage{
+			Payload: payload,
+			UserID:  userID,
+		}
+
+		pa.sendMessageToCluster(clusterMessage)
+	}()
+
+	pa.sendUserMessageSkipCluster(message.Action, payload, userID)
+}
+
 func (pa *PluginAdapter) BroadcastBlockDelete(teamID, blockID, boardID string) {
 	now := utils.GetMillis()
 	block := &model.Block{}
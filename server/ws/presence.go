@@ -0,0 +1,226 @@
+package ws
+
+import (
+	"sync"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// presenceEntry is the presence of a connection on a board.
+type presenceEntry struct {
+	userID   string
+	teamID   string
+	boardID  string
+	cardID   string
+	idle     bool
+	since    int64
+	updateAt int64
+}
+
+// presenceChange is the presence of a user on a board after one of
+// their connections changed it.
+type presenceChange struct {
+	teamID   string
+	presence *model.BoardPresence
+}
+
+// presenceTracker keeps the presence of the connections on the boards
+// in memory. A connection, identified by a key, can only be on one
+// board at a time.
+type presenceTracker struct {
+	mu     sync.Mutex
+	conns  map[string]*presenceEntry
+	boards map[string]map[string]*presenceEntry
+}
+
+func newPresenceTracker() *presenceTracker {
+	return &presenceTracker{
+		conns:  make(map[string]*presenceEntry),
+		boards: make(map[string]map[string]*presenceEntry),
+	}
+}
+
+// set updates the presence of a connection. Moving to another board
+// leaves the previous one. It returns the resulting presence of the
+// user on the boards that changed.
+func (pt *presenceTracker) set(connKey, userID, teamID, boardID, cardID string, idle bool) []presenceChange {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	now := utils.GetMillis()
+	changes := []presenceChange{}
+
+	entry, ok := pt.conns[connKey]
+	if ok && entry.boardID != boardID {
+		pt.removeLocked(connKey)
+		changes = append(changes, presenceChange{entry.teamID, pt.userPresenceLocked(entry.boardID, userID)})
+		ok = false
+	}
+
+	if !ok {
+		entry = &presenceEntry{
+			userID:  userID,
+			teamID:  teamID,
+			boardID: boardID,
+			since:   now,
+		}
+		pt.conns[connKey] = entry
+		if pt.boards[boardID] == nil {
+			pt.boards[boardID] = make(map[string]*presenceEntry)
+		}
+		pt.boards[boardID][connKey] = entry
+	}
+	entry.cardID = cardID
+	entry.idle = idle
+	entry.updateAt = now
+
+	return append(changes, presenceChange{teamID, pt.userPresenceLocked(boardID, userID)})
+}
+
+// setRelayed records the presence of a user on a board relayed by
+// another node of the cluster. The node merges the connections of the
+// user, so it is kept as a single entry per node, user and board.
+func (pt *presenceTracker) setRelayed(origin, teamID string, presence *model.BoardPresence) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	connKey := origin + "/" + presence.UserID + "/" + presence.BoardID
+	entry, ok := pt.conns[connKey]
+	if presence.State == model.PresenceLeft {
+		if ok {
+			pt.removeLocked(connKey)
+		}
+		return
+	}
+
+	if !ok {
+		entry = &presenceEntry{
+			userID:  presence.UserID,
+			teamID:  teamID,
+			boardID: presence.BoardID,
+		}
+		pt.conns[connKey] = entry
+		if pt.boards[presence.BoardID] == nil {
+			pt.boards[presence.BoardID] = make(map[string]*presenceEntry)
+		}
+		pt.boards[presence.BoardID][connKey] = entry
+	}
+	entry.since = presence.Since
+	entry.cardID = presence.CardID
+	entry.idle = presence.State == model.PresenceIdle
+	entry.updateAt = utils.GetMillis()
+}
+
+// remove deletes the presence of a connection, returning the
+// resulting presence of its user if it was on a board.
+func (pt *presenceTracker) remove(connKey string) []presenceChange {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	entry, ok := pt.conns[connKey]
+	if !ok {
+		return nil
+	}
+	pt.removeLocked(connKey)
+	return []presenceChange{{entry.teamID, pt.userPresenceLocked(entry.boardID, entry.userID)}}
+}
+
+func (pt *presenceTracker) removeLocked(connKey string) {
+	entry := pt.conns[connKey]
+	delete(pt.conns, connKey)
+	delete(pt.boards[entry.boardID], connKey)
+	if len(pt.boards[entry.boardID]) == 0 {
+		delete(pt.boards, entry.boardID)
+	}
+}
+
+// userPresenceLocked merges the presence of all the connections of a
+// user on a board: the user is active if any of them is, and the open
+// card is the one of the most recently updated connection.
+func (pt *presenceTracker) userPresenceLocked(boardID, userID string) *model.BoardPresence {
+	presence := &model.BoardPresence{
+		UserID:  userID,
+		BoardID: boardID,
+		State:   model.PresenceLeft,
+	}
+
+	var updateAt int64
+	for _, entry := range pt.boards[boardID] {
+		if entry.userID != userID {
+			continue
+		}
+		mergePresence(presence, entry, &updateAt)
+	}
+	return presence
+}
+
+func mergePresence(presence *model.BoardPresence, entry *presenceEntry, updateAt *int64) {
+	if presence.State == model.PresenceLeft || entry.since < presence.Since {
+		presence.Since = entry.since
+	}
+	if !entry.idle {
+		presence.State = model.PresenceActive
+	} else if presence.State == model.PresenceLeft {
+		presence.State = model.PresenceIdle
+	}
+	if entry.updateAt >= *updateAt {
+		presence.CardID = entry.cardID
+		*updateAt = entry.updateAt
+	}
+}
+
+// getBoard returns the team and the board a connection is on.
+func (pt *presenceTracker) getBoard(connKey string) (string, string, bool) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	entry, ok := pt.conns[connKey]
+	if !ok {
+		return "", "", false
+	}
+	return entry.teamID, entry.boardID, true
+}
+
+// boardPresence returns the presence of the users on a board.
+func (pt *presenceTracker) boardPresence(boardID string) []*model.BoardPresence {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	byUser := map[string]*model.BoardPresence{}
+	updateAt := map[string]int64{}
+	for _, entry := range pt.boards[boardID] {
+		presence, ok := byUser[entry.userID]
+		if !ok {
+			presence = &model.BoardPresence{
+				UserID:  entry.userID,
+				BoardID: boardID,
+				State:   model.PresenceLeft,
+			}
+			byUser[entry.userID] = presence
+		}
+		userUpdateAt := updateAt[entry.userID]
+		mergePresence(presence, entry, &userUpdateAt)
+		updateAt[entry.userID] = userUpdateAt
+	}
+
+	presences := make([]*model.BoardPresence, 0, len(byUser))
+	for _, presence := range byUser {
+		presences = append(presences, presence)
+	}
+	return presences
+}
+
+// isBoardMember checks if a user is a member of a board.
+func isBoardMember(store Store, boardID, userID string) (bool, error) {
+	members, err := store.GetMembersForBoard(boardID)
+	if err != nil {
+		return false, err
+	}
+	for _, member := range members {
+		if member.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,162 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceTracker(t *testing.T) {
+	t.Run("joining a board", func(t *testing.T) {
+		pt := newPresenceTracker()
+
+		changes := pt.set("conn-1", "user-1", "team-id", "board-1", "", false)
+		require.Len(t, changes, 1)
+		require.Equal(t, "team-id", changes[0].teamID)
+		require.Equal(t, "board-1", changes[0].presence.BoardID)
+		require.Equal(t, model.PresenceActive, changes[0].presence.State)
+		require.NotZero(t, changes[0].presence.Since)
+	})
+
+	t.Run("a user is active if any of their connections is", func(t *testing.T) {
+		pt := newPresenceTracker()
+
+		pt.set("conn-1", "user-1", "team-id", "board-1", "", false)
+		changes := pt.set("conn-2", "user-1", "team-id", "board-1", "card-1", true)
+		require.Equal(t, model.PresenceActive, changes[0].presence.State)
+		require.Equal(t, "card-1", changes[0].presence.CardID)
+
+		changes = pt.set("conn-1", "user-1", "team-id", "board-1", "", true)
+		require.Equal(t, model.PresenceIdle, changes[0].presence.State)
+	})
+
+	t.Run("moving to another board leaves the previous one", func(t *testing.T) {
+		pt := newPresenceTracker()
+
+		pt.set("conn-1", "user-1", "team-id", "board-1", "", false)
+		changes := pt.set("conn-1", "user-1", "team-id", "board-2", "", false)
+		require.Len(t, changes, 2)
+		require.Equal(t, "board-1", changes[0].presence.BoardID)
+		require.Equal(t, model.PresenceLeft, changes[0].presence.State)
+		require.Equal(t, "board-2", changes[1].presence.BoardID)
+		require.Equal(t, model.PresenceActive, changes[1].presence.State)
+
+		require.Empty(t, pt.boardPresence("board-1"))
+		require.Len(t, pt.boardPresence("board-2"), 1)
+	})
+
+	t.Run("removing a connection", func(t *testing.T) {
+		pt := newPresenceTracker()
+
+		require.Empty(t, pt.remove("conn-1"))
+
+		pt.set("conn-1", "user-1", "team-id", "board-1", "", false)
+		pt.set("conn-2", "user-1", "team-id", "board-1", "", true)
+
+		changes := pt.remove("conn-1")
+		require.Len(t, changes, 1)
+		require.Equal(t, model.PresenceIdle, changes[0].presence.State)
+
+		changes = pt.remove("conn-2")
+		require.Equal(t, model.PresenceLeft, changes[0].presence.State)
+
+		_, _, ok := pt.getBoard("conn-2")
+		require.False(t, ok)
+	})
+
+	t.Run("board presence has one entry per user", func(t *testing.T) {
+		pt := newPresenceTracker()
+
+		pt.set("conn-1", "user-1", "team-id", "board-1", "", false)
+		pt.set("conn-2", "user-1", "team-id", "board-1", "", true)
+		pt.set("conn-3", "user-2", "team-id", "board-1", "card-1", true)
+
+		presences := pt.boardPresence("board-1")
+		require.Len(t, presences, 2)
+		for _, presence := range presences {
+			if presence.UserID == "user-1" {
+				require.Equal(t, model.PresenceActive, presence.State)
+			} else {
+				require.Equal(t, model.PresenceIdle, presence.State)
+				require.Equal(t, "card-1", presence.CardID)
+			}
+		}
+	})
+
+	t.Run("relayed presence is merged with the local one", func(t *testing.T) {
+		pt := newPresenceTracker()
+
+		pt.set("conn-1", "user-1", "team-id", "board-1", "", true)
+		pt.setRelayed("node-1", "team-id", &model.BoardPresence{
+			UserID:  "user-1",
+			BoardID: "board-1",
+			CardID:  "card-1",
+			State:   model.PresenceActive,
+			Since:   1,
+		})
+
+		presences := pt.boardPresence("board-1")
+		require.Len(t, presences, 1)
+		require.Equal(t, model.PresenceActive, presences[0].State)
+		require.Equal(t, "card-1", presences[0].CardID)
+		require.Equal(t, int64(1), presences[0].Since)
+
+		pt.setRelayed("node-1", "team-id", &model.BoardPresence{
+			UserID:  "user-1",
+			BoardID: "board-1",
+			State:   model.PresenceLeft,
+		})
+
+		presences = pt.boardPresence("board-1")
+		require.Len(t, presences, 1)
+		require.Equal(t, model.PresenceIdle, presences[0].State)
+	})
+}
+
+func TestPluginAdapterPresence(t *testing.T) {
+	th := SetupTestHelper(t)
+
+	webConnID := mmModel.NewId()
+	userID := mmModel.NewId()
+	teamID := mmModel.NewId()
+	boardID := mmModel.NewId()
+
+	th.pa.OnWebSocketConnect(webConnID, userID)
+	th.SubscribeWebConnToTeam(webConnID, userID, teamID)
+
+	th.store.EXPECT().GetMembersForBoard(boardID).Return([]*model.BoardMember{{UserID: userID}}, nil).AnyTimes()
+	th.auth.EXPECT().DoesUserHaveTeamAccess(userID, teamID).Return(true).AnyTimes()
+	th.api.EXPECT().PublishPluginClusterEvent(gomock.Any(), gomock.Any()).AnyTimes()
+
+	t.Run("members can set their presence on a board", func(t *testing.T) {
+		th.api.EXPECT().PublishWebSocketEvent(websocketActionUpdateBoard, gomock.Any(), &mmModel.WebsocketBroadcast{UserId: userID})
+
+		msgData := map[string]interface{}{"teamId": teamID, "boardId": boardID, "cardId": "card-id"}
+		th.ReceiveWebSocketMessage(webConnID, userID, websocketActionSetPresence, msgData)
+
+		presences := th.pa.GetBoardPresence(boardID)
+		require.Len(t, presences, 1)
+		require.Equal(t, userID, presences[0].UserID)
+		require.Equal(t, "card-id", presences[0].CardID)
+	})
+
+	t.Run("non members cannot", func(t *testing.T) {
+		otherBoardID := mmModel.NewId()
+		th.store.EXPECT().GetMembersForBoard(otherBoardID).Return([]*model.BoardMember{}, nil)
+
+		msgData := map[string]interface{}{"teamId": teamID, "boardId": otherBoardID}
+		th.ReceiveWebSocketMessage(webConnID, userID, websocketActionSetPresence, msgData)
+
+		require.Empty(t, th.pa.GetBoardPresence(otherBoardID))
+	})
+
+	t.Run("disconnecting leaves the board", func(t *testing.T) {
+		th.pa.OnWebSocketDisconnect(webConnID, userID)
+		require.Empty(t, th.pa.GetBoardPresence(boardID))
+	})
+}
@@ -0,0 +1,225 @@
+package ws
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// defaultSendQueueSize is the number of messages a listener can
+	// have waiting to be written. It is larger than the replay buffer
+	// so a resume always fits in an empty queue.
+	defaultSendQueueSize = 1024
+
+	// writeWait is the time allowed to write a message to a websocket.
+	writeWait = 10 * time.Second
+
+	// pongWait is the time allowed to read the next pong, or any
+	// message, from a websocket before the connection is considered
+	// dead.
+	pongWait = 60 * time.Second
+
+	// pingPeriod is how often websockets are pinged. It must be less
+	// than pongWait.
+	pingPeriod = pongWait * 9 / 10
+)
+
+// SlowConsumerPolicy is what the server does with a listener whose
+// send queue is full.
+type SlowConsumerPolicy string
+
+const (
+	// SlowConsumerResync drops the messages queued for the listener
+	// and asks it to resync the teams it is subscribed to.
+	SlowConsumerResync SlowConsumerPolicy = "resync"
+	// SlowConsumerDisconnect closes the connection of the listener.
+	SlowConsumerDisconnect SlowConsumerPolicy = "disconnect"
+)
+
+// IsValid checks if the policy is a known one.
+func (p SlowConsumerPolicy) IsValid() bool {
+	return p == SlowConsumerResync || p == SlowConsumerDisconnect
+}
+
+var (
+	errSendQueueFull = errors.New("send queue full")
+	errSessionClosed = errors.New("session closed")
+)
+
+// SetSendQueue sets the number of messages that can be queued for
+// each listener, and what to do with the listeners that fall behind.
+func (ws *Server) SetSendQueue(size int, policy SlowConsumerPolicy) {
+	if size > 0 {
+		ws.sendQueueSize = size
+	}
+	if policy.IsValid() {
+		ws.slowConsumerPolicy = policy
+	}
+}
+
+// newSession creates a listener for a connection and starts the
+// goroutine that writes its messages.
+func (ws *Server) newSession(conn listenerConn, userID string) *websocketSession {
+	session := &websocketSession{
+		id:      utils.NewID(utils.IDTypeNone),
+		conn:    conn,
+		userID:  userID,
+		teams:   []string{},
+		blocks:  []string{},
+		server:  ws,
+		send:    make(chan interface{}, ws.sendQueueSize),
+		stopped: make(chan struct{}),
+	}
+	go ws.writeLoop(session)
+	return session
+}
+
+// writeLoop writes the queued messages of a listener to its
+// connection and pings websockets to detect dead connections, until
+// the session is stopped.
+func (ws *Server) writeLoop(wss *websocketSession) {
+	defer wss.stop()
+
+	client, isWebsocket := wss.conn.(*websocket.Conn)
+	var pings <-chan time.Time
+	if isWebsocket {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+		pings = ticker.C
+	}
+
+	for {
+		select {
+		case <-wss.stopped:
+			return
+		case message := <-wss.send:
+			ws.metrics.AddWebsocketQueueDepth(-1)
+			if isWebsocket {
+				_ = client.SetWriteDeadline(time.Now().Add(writeWait))
+			}
+			if err := wss.conn.WriteJSON(message); err != nil {
+				ws.logger.Error("write error", mlog.Stringer("client", wss.conn.RemoteAddr()), mlog.Err(err))
+				wss.conn.Close()
+				return
+			}
+		case <-pings:
+			if err := client.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				ws.logger.Debug("ping error", mlog.Stringer("client", wss.conn.RemoteAddr()), mlog.Err(err))
+				wss.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// keepAlive makes reads from a websocket fail if neither a message
+// nor a pong is received for pongWait, so the connections of clients
+// that went away without closing them are reaped.
+func (ws *Server) keepAlive(client *websocket.Conn) {
+	_ = client.SetReadDeadline(time.Now().Add(pongWait))
+	client.SetPongHandler(func(string) error {
+		return client.SetReadDeadline(time.Now().Add(pongWait))
+	})
+}
+
+// isDeadConnection checks if a read error comes from a websocket that
+// stopped answering pings.
+func isDeadConnection(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// enqueue adds a message to the send queue of the listener. If the
+// queue is full, the slow consumer policy of the server is applied,
+// and an error is returned if the listener should be disconnected.
+// The session must be locked.
+func (wss *websocketSession) enqueue(v interface{}) error {
+	if wss.isStopped {
+		return errSessionClosed
+	}
+
+	select {
+	case wss.send <- v:
+		wss.server.metrics.AddWebsocketQueueDepth(1)
+		return nil
+	default:
+	}
+
+	return wss.server.handleSlowConsumer(wss)
+}
+
+// drain drops the queued messages of the listener and returns how
+// many were dropped. The session must be locked.
+func (wss *websocketSession) drain() int {
+	dropped := 0
+	for {
+		select {
+		case <-wss.send:
+			dropped++
+		default:
+			wss.server.metrics.AddWebsocketQueueDepth(-dropped)
+			return dropped
+		}
+	}
+}
+
+// stop ends the write loop of the listener and drops its queued
+// messages.
+func (wss *websocketSession) stop() {
+	wss.mu.Lock()
+	defer wss.mu.Unlock()
+
+	if wss.isStopped {
+		return
+	}
+	wss.isStopped = true
+	close(wss.stopped)
+	wss.drain()
+}
+
+// handleSlowConsumer applies the slow consumer policy to a listener
+// whose send queue is full. The message that didn't fit and the queued
+// ones are dropped, and the listener is either asked to resync its
+// teams or disconnected. Listeners without team subscriptions can't
+// resync, so they are always disconnected. The session must be locked.
+func (ws *Server) handleSlowConsumer(wss *websocketSession) error {
+	dropped := wss.drain()
+	ws.metrics.IncrementWebsocketDroppedMessages(dropped + 1)
+
+	ws.mu.RLock()
+	teams := append([]string{}, wss.teams...)
+	ws.mu.RUnlock()
+
+	policy := ws.slowConsumerPolicy
+	if len(teams) == 0 || len(teams) > cap(wss.send) {
+		policy = SlowConsumerDisconnect
+	}
+	ws.metrics.IncrementWebsocketSlowConsumers(string(policy))
+
+	ws.logger.Warn("Slow websocket consumer",
+		mlog.String("userID", wss.userID),
+		mlog.Stringer("client", wss.conn.RemoteAddr()),
+		mlog.String("policy", string(policy)),
+		mlog.Int("dropped", dropped+1),
+	)
+
+	if policy == SlowConsumerDisconnect {
+		return errSendQueueFull
+	}
+
+	for _, teamID := range teams {
+		wss.send <- ResyncMsg{
+			Action: websocketActionResync,
+			TeamID: teamID,
+			Seq:    ws.getStream(teamID).last(),
+		}
+		ws.metrics.AddWebsocketQueueDepth(1)
+	}
+	return nil
+}
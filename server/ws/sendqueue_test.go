@@ -0,0 +1,151 @@
+package ws
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/auth"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/stretchr/testify/require"
+)
+
+// blockingConn is a connection whose writes wait until it's
+// unblocked, like the one of a client that can't keep up.
+type blockingConn struct {
+	mu       sync.Mutex
+	unblock  chan struct{}
+	messages []interface{}
+	closed   bool
+}
+
+func newBlockingConn() *blockingConn {
+	return &blockingConn{unblock: make(chan struct{})}
+}
+
+func (c *blockingConn) WriteJSON(v interface{}) error {
+	<-c.unblock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, v)
+	return nil
+}
+
+func (c *blockingConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *blockingConn) RemoteAddr() net.Addr {
+	return sseAddr("127.0.0.1")
+}
+
+func (c *blockingConn) getMessages() []interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]interface{}{}, c.messages...)
+}
+
+func TestSlowConsumer(t *testing.T) {
+	teamID := "team-id"
+
+	setup := func(t *testing.T, policy SlowConsumerPolicy) (*Server, *websocketSession, *blockingConn) {
+		server := NewServer(&auth.Auth{}, "token", false, mlog.CreateConsoleTestLogger(t), nil)
+		server.SetSendQueue(2, policy)
+
+		conn := newBlockingConn()
+		session := server.newSession(conn, "user-id")
+		t.Cleanup(session.stop)
+		server.addListener(session)
+		server.subscribeListenerToTeam(session, teamID)
+		return server, session, conn
+	}
+
+	t.Run("messages are queued without waiting for the client", func(t *testing.T) {
+		_, session, conn := setup(t, SlowConsumerResync)
+
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < 2; i++ {
+				require.NoError(t, session.WriteJSON(i))
+			}
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			require.Fail(t, "writes blocked on a slow client")
+		}
+
+		close(conn.unblock)
+		require.Eventually(t, func() bool {
+			return len(conn.getMessages()) == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("a client that falls behind is asked to resync", func(t *testing.T) {
+		server, session, conn := setup(t, SlowConsumerResync)
+		lastSeq := server.getStream(teamID).last()
+
+		// the write loop takes the first message and blocks, so the
+		// queue fills with the next ones
+		for i := 0; i < 10; i++ {
+			require.NoError(t, session.WriteJSON(i))
+		}
+
+		close(conn.unblock)
+		resync := ResyncMsg{
+			Action: websocketActionResync,
+			TeamID: teamID,
+			Seq:    lastSeq,
+		}
+		require.Eventually(t, func() bool {
+			for _, message := range conn.getMessages() {
+				if message == resync {
+					return true
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond)
+		require.Less(t, len(conn.getMessages()), 10)
+		conn.mu.Lock()
+		require.False(t, conn.closed)
+		conn.mu.Unlock()
+	})
+
+	t.Run("a client that falls behind is disconnected", func(t *testing.T) {
+		_, session, conn := setup(t, SlowConsumerDisconnect)
+
+		var err error
+		for i := 0; i < 10 && err == nil; i++ {
+			err = session.WriteJSON(i)
+		}
+		require.ErrorIs(t, err, errSendQueueFull)
+		close(conn.unblock)
+	})
+
+	t.Run("a client without team subscriptions is disconnected", func(t *testing.T) {
+		server, session, conn := setup(t, SlowConsumerResync)
+		server.unsubscribeListenerFromTeam(session, teamID)
+
+		var err error
+		for i := 0; i < 10 && err == nil; i++ {
+			err = session.WriteJSON(i)
+		}
+		require.ErrorIs(t, err, errSendQueueFull)
+		close(conn.unblock)
+	})
+
+	t.Run("messages are not queued once the session is stopped", func(t *testing.T) {
+		_, session, conn := setup(t, SlowConsumerResync)
+		session.stop()
+
+		require.ErrorIs(t, session.WriteJSON("message"), errSessionClosed)
+		close(conn.unblock)
+	})
+}
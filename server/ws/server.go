@@ -2,23 +2,27 @@ package ws
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/mattermost/focalboard/server/auth"
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/utils"
 
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 )
 
+// WriteJSON queues a message to be written to the connection of the
+// listener.
 func (wss *websocketSession) WriteJSON(v interface{}) error {
 	wss.mu.Lock()
 	defer wss.mu.Unlock()
-	err := wss.conn.WriteJSON(v)
-	return err
+	return wss.enqueue(v)
 }
 
 func (wss *websocketSession) isSubscribedToTeam(teamID string) bool {
@@ -53,14 +57,47 @@ type Server struct {
 	isMattermostAuth bool
 	logger           mlog.LoggerIFace
 	store            Store
+	streams          map[string]*teamStream
+	streamsMu        sync.Mutex
+	presence         *presenceTracker
+	clusterBus       ClusterBus
+	nodeID           string
+	metrics          *metrics.Metrics
+
+	sendQueueSize      int
+	slowConsumerPolicy SlowConsumerPolicy
+}
+
+// listenerConn is the connection a listener receives its messages
+// through, either a websocket or a server-sent events stream.
+type listenerConn interface {
+	WriteJSON(v interface{}) error
+	Close() error
+	RemoteAddr() net.Addr
 }
 
 type websocketSession struct {
-	conn   *websocket.Conn
+	id     string
+	conn   listenerConn
 	userID string
 	mu     sync.Mutex
 	teams  []string
 	blocks []string
+	// boards restricts the board events the listener receives to
+	// the ones of these boards, if set.
+	boards map[string]bool
+
+	server *Server
+	// send holds the messages waiting to be written by the write
+	// loop, so a slow client doesn't delay the broadcasts to others.
+	send      chan interface{}
+	stopped   chan struct{}
+	isStopped bool
+}
+
+// receivesBoard checks if the listener wants the events of a board.
+func (wss *websocketSession) receivesBoard(boardID string) bool {
+	return wss.boards == nil || wss.boards[boardID]
 }
 
 func (wss *websocketSession) isAuthenticated() bool {
@@ -70,9 +107,13 @@ func (wss *websocketSession) isAuthenticated() bool {
 // NewServer creates a new Server.
 func NewServer(auth *auth.Auth, singleUserToken string, isMattermostAuth bool, logger mlog.LoggerIFace, store Store) *Server {
 	return &Server{
-		listeners:        make(map[*websocketSession]bool),
-		listenersByTeam:  make(map[string][]*websocketSession),
-		listenersByBlock: make(map[string][]*websocketSession),
+		listeners:          make(map[*websocketSession]bool),
+		listenersByTeam:    make(map[string][]*websocketSession),
+		listenersByBlock:   make(map[string][]*websocketSession),
+		streams:            make(map[string]*teamStream),
+		presence:           newPresenceTracker(),
+		sendQueueSize:      defaultSendQueueSize,
+		slowConsumerPolicy: SlowConsumerResync,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
@@ -86,9 +127,16 @@ func NewServer(auth *auth.Auth, singleUserToken string, isMattermostAuth bool, l
 	}
 }
 
+// SetMetrics makes the server report the state of the send queues
+// of its listeners.
+func (ws *Server) SetMetrics(metrics *metrics.Metrics) {
+	ws.metrics = metrics
+}
+
 // RegisterRoutes registers routes.
 func (ws *Server) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/ws", ws.handleWebSocket)
+	r.HandleFunc("/ws/events", ws.handleServerSentEvents).Methods("GET")
 }
 
 func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
@@ -99,19 +147,15 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// create an empty session with websocket client
-	wsSession := &websocketSession{
-		conn:   client,
-		userID: "",
-		mu:     sync.Mutex{},
-		teams:  []string{},
-		blocks: []string{},
-	}
-
+	userID := ""
 	if ws.isMattermostAuth {
-		wsSession.userID = r.Header.Get("Mattermost-User-Id")
+		userID = r.Header.Get("Mattermost-User-Id")
 	}
 
+	// create an empty session with websocket client
+	wsSession := ws.newSession(client, userID)
+	ws.keepAlive(client)
+
 	ws.addListener(wsSession)
 
 	// Make sure we close the connection when the function returns
@@ -120,12 +164,20 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 		// Remove session from listeners
 		ws.removeListener(wsSession)
+		ws.broadcastPresenceChanges(ws.presence.remove(wsSession.id))
+		wsSession.stop()
 		wsSession.conn.Close()
 	}()
 
 	// Simple message handling loop
 	for {
-		_, p, err := wsSession.conn.ReadMessage()
+		_, p, err := client.ReadMessage()
+		if err != nil && isDeadConnection(err) {
+			ws.logger.Debug("Reaping dead WebSocket", mlog.Stringer("client", wsSession.conn.RemoteAddr()))
+			ws.metrics.IncrementWebsocketDeadConnections(1)
+			ws.removeListener(wsSession)
+			break
+		}
 		if err != nil {
 			ws.logger.Error("ERROR WebSocket",
 				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
@@ -134,6 +186,8 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			ws.removeListener(wsSession)
 			break
 		}
+		// any message from the client shows the connection is alive
+		_ = client.SetReadDeadline(time.Now().Add(pongWait))
 
 		var command WebsocketCommand
 
@@ -213,24 +267,38 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
 			)
 
-			// if single user mode, check that the userID is valid and
-			// assume that the user has permission if so
-			if len(ws.singleUserToken) != 0 {
-				if wsSession.userID != model.SingleUser {
-					continue
-				}
-
-				// if not in single user mode validate that the session
-				// has permissions to the team
-			} else {
-				ws.logger.Debug("Not single user mode")
-				if !ws.auth.DoesUserHaveTeamAccess(wsSession.userID, command.TeamID) {
-					ws.logger.Error("WS user doesn't have team access", mlog.String("teamID", command.TeamID), mlog.String("userID", wsSession.userID))
-					continue
-				}
+			if !ws.canSubscribeToTeam(wsSession, command.TeamID) {
+				continue
 			}
 
 			ws.subscribeListenerToTeam(wsSession, command.TeamID)
+		case websocketActionResume:
+			ws.logger.Debug(`Command: RESUME`,
+				mlog.String("teamID", command.TeamID),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			if !ws.canSubscribeToTeam(wsSession, command.TeamID) {
+				continue
+			}
+
+			ws.resumeListener(wsSession, command.TeamID, command.Seq)
+		case websocketActionSetPresence:
+			ws.logger.Debug(`Command: SET_PRESENCE`,
+				mlog.String("teamID", command.TeamID),
+				mlog.String("boardID", command.BoardID),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			ws.setListenerPresence(wsSession, command)
+		case websocketActionSetEditing:
+			ws.logger.Debug(`Command: SET_EDITING`,
+				mlog.String("teamID", command.TeamID),
+				mlog.String("boardID", command.BoardID),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			ws.broadcastEditing(wsSession, command)
 		case websocketActionUnsubscribeTeam:
 			ws.logger.Debug(`Command: UNSUBSCRIBE_TEAM`,
 				mlog.String("teamID", command.TeamID),
@@ -244,6 +312,25 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// canSubscribeToTeam checks that an authenticated session can
+// receive the updates of a team.
+func (ws *Server) canSubscribeToTeam(wsSession *websocketSession, teamID string) bool {
+	// if single user mode, check that the userID is valid and
+	// assume that the user has permission if so
+	if len(ws.singleUserToken) != 0 {
+		return wsSession.userID == model.SingleUser
+	}
+
+	// if not in single user mode validate that the session
+	// has permissions to the team
+	ws.logger.Debug("Not single user mode")
+	if !ws.auth.DoesUserHaveTeamAccess(wsSession.userID, teamID) {
+		ws.logger.Error("WS user doesn't have team access", mlog.String("teamID", teamID), mlog.String("userID", wsSession.userID))
+		return false
+	}
+	return true
+}
+
 // isCommandReadTokenValid ensures that a command contains a read
 // token and a set of block ids that said token is valid for.
 func (ws *Server) isCommandReadTokenValid(command WebsocketCommand) bool {
@@ -327,6 +414,216 @@ func (ws *Server) subscribeListenerToTeam(listener *websocketSession, teamID str
 	listener.teams = append(listener.teams, teamID)
 }
 
+// getStream returns the event stream of a team, creating it if
+// needed.
+func (ws *Server) getStream(teamID string) *teamStream {
+	ws.streamsMu.Lock()
+	defer ws.streamsMu.Unlock()
+
+	stream, ok := ws.streams[teamID]
+	if !ok {
+		stream = newTeamStream()
+		ws.streams[teamID] = stream
+	}
+	return stream
+}
+
+// resumeListener subscribes the listener to a team and sends it the
+// events it missed since a sequence number, or asks it to resync if
+// those events are not available anymore. The session is locked
+// while the events are queued so the events broadcasted meanwhile
+// are received after them; clients discard the events with a
+// sequence number they have already seen.
+func (ws *Server) resumeListener(listener *websocketSession, teamID string, seq int64) {
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+
+	ws.subscribeListenerToTeam(listener, teamID)
+
+	events, lastSeq, ok := ws.getStream(teamID).eventsSince(seq)
+	if !ok {
+		ws.logger.Debug("Resume not possible, asking for resync",
+			mlog.String("teamID", teamID),
+			mlog.Stringer("client", listener.conn.RemoteAddr()),
+		)
+		message := ResyncMsg{
+			Action: websocketActionResync,
+			TeamID: teamID,
+			Seq:    lastSeq,
+		}
+		if err := listener.enqueue(message); err != nil {
+			ws.logger.Error("resync error", mlog.Err(err))
+			listener.conn.Close()
+		}
+		return
+	}
+
+	// the events are only replayed to the current members of their
+	// boards, as they are broadcasted
+	isMember := map[string]bool{}
+	for _, event := range events {
+		if !listener.receivesBoard(event.boardID) || !ws.canReceiveEvent(listener.userID, event, isMember) {
+			continue
+		}
+		if err := listener.enqueue(event.message); err != nil {
+			ws.logger.Error("replay error", mlog.Err(err))
+			listener.conn.Close()
+			return
+		}
+	}
+}
+
+// setListenerPresence updates the board and card the listener is
+// looking at, or leaves its board if the command has no board.
+func (ws *Server) setListenerPresence(listener *websocketSession, command WebsocketCommand) {
+	if command.BoardID == "" {
+		ws.broadcastPresenceChanges(ws.presence.remove(listener.id))
+		return
+	}
+
+	if !listener.isSubscribedToTeam(command.TeamID) {
+		ws.logger.Error("WS presence for a team the listener is not subscribed to",
+			mlog.String("teamID", command.TeamID),
+			mlog.String("userID", listener.userID),
+		)
+		return
+	}
+
+	isMember, err := isBoardMember(ws.store, command.BoardID, listener.userID)
+	if err != nil {
+		ws.logger.Error("error getting members for board",
+			mlog.String("method", "setListenerPresence"),
+			mlog.String("boardID", command.BoardID),
+			mlog.Err(err),
+		)
+		return
+	}
+	if !isMember {
+		ws.logger.Error("WS presence for a board the user is not a member of",
+			mlog.String("boardID", command.BoardID),
+			mlog.String("userID", listener.userID),
+		)
+		return
+	}
+
+	changes := ws.presence.set(listener.id, listener.userID, command.TeamID, command.BoardID, command.CardID, command.Idle)
+	ws.broadcastPresenceChanges(changes)
+}
+
+// broadcastPresenceChanges sends the presence of users to the other
+// listeners of their boards.
+func (ws *Server) broadcastPresenceChanges(changes []presenceChange) {
+	for _, change := range changes {
+		ws.broadcastPresenceLocal(change.teamID, change.presence)
+		ws.publishToCluster(&clusterBroadcast{
+			Type:     clusterBroadcastPresence,
+			TeamID:   change.teamID,
+			Presence: change.presence,
+		})
+	}
+}
+
+func (ws *Server) broadcastPresenceLocal(teamID string, presence *model.BoardPresence) {
+	message := UpdatePresenceMsg{
+		Action:   websocketActionUpdatePresence,
+		TeamID:   teamID,
+		Presence: presence,
+	}
+
+	for _, listener := range ws.getListenersForTeamAndBoard(teamID, presence.BoardID) {
+		ws.logger.Trace("Broadcast presence change",
+			mlog.String("teamID", teamID),
+			mlog.String("boardID", presence.BoardID),
+			mlog.String("userID", presence.UserID),
+			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
+		)
+
+		if err := listener.WriteJSON(message); err != nil {
+			ws.logger.Error("broadcast presence error", mlog.Err(err))
+			listener.conn.Close()
+		}
+	}
+}
+
+// broadcastEditing sends to the other listeners of a board that the
+// user of the listener started or stopped editing a field of a card.
+// Only listeners that are on the board can send these signals.
+func (ws *Server) broadcastEditing(listener *websocketSession, command WebsocketCommand) {
+	teamID, boardID, ok := ws.presence.getBoard(listener.id)
+	if !ok || boardID != command.BoardID {
+		return
+	}
+
+	signal := &model.EditingSignal{
+		UserID:  listener.userID,
+		BoardID: boardID,
+		CardID:  command.CardID,
+		Field:   command.Field,
+		Editing: command.Editing,
+	}
+	ws.broadcastEditingLocal(teamID, signal, listener)
+	ws.publishToCluster(&clusterBroadcast{
+		Type:    clusterBroadcastEditing,
+		TeamID:  teamID,
+		Editing: signal,
+	})
+}
+
+func (ws *Server) broadcastEditingLocal(teamID string, signal *model.EditingSignal, sender *websocketSession) {
+	message := UpdateEditingMsg{
+		Action:  websocketActionUpdateEditing,
+		TeamID:  teamID,
+		Editing: signal,
+	}
+
+	for _, listener := range ws.getListenersForTeamAndBoard(teamID, signal.BoardID) {
+		if listener == sender {
+			continue
+		}
+
+		if err := listener.WriteJSON(message); err != nil {
+			ws.logger.Error("broadcast editing error", mlog.Err(err))
+			listener.conn.Close()
+		}
+	}
+}
+
+// GetBoardPresence returns the users currently looking at a board.
+func (ws *Server) GetBoardPresence(boardID string) []*model.BoardPresence {
+	return ws.presence.boardPresence(boardID)
+}
+
+// canReceiveEvent checks if a user would have received an event of a
+// team stream. Board memberships are cached in isMember.
+func (ws *Server) canReceiveEvent(userID string, event streamEvent, isMember map[string]bool) bool {
+	for _, id := range event.ensureUsers {
+		if id == userID {
+			return true
+		}
+	}
+
+	member, ok := isMember[event.boardID]
+	if !ok {
+		members, err := ws.store.GetMembersForBoard(event.boardID)
+		if err != nil {
+			ws.logger.Error("error getting members for board",
+				mlog.String("method", "canReceiveEvent"),
+				mlog.String("boardID", event.boardID),
+				mlog.Err(err),
+			)
+			return false
+		}
+		for _, m := range members {
+			if m.UserID == userID {
+				member = true
+				break
+			}
+		}
+		isMember[event.boardID] = member
+	}
+	return member
+}
+
 // unsubscribeListenerFromTeam safely modifies the listener and
 // the server data structures to remove the link between the listener
 // and a given team ID.
@@ -505,7 +802,7 @@ func (ws *Server) getListenersForTeamAndBoard(teamID, boardID string, ensureUser
 	listeners := []*websocketSession{}
 	for _, memberID := range memberIDs {
 		for _, listener := range ws.listenersByTeam[teamID] {
-			if listener.userID == memberID {
+			if listener.userID == memberID && listener.receivesBoard(boardID) {
 				listeners = append(listeners, listener)
 			}
 		}
@@ -525,8 +822,9 @@ func (ws *Server) BroadcastBlockDelete(teamID, blockID, boardID string) {
 	ws.BroadcastBlockChange(teamID, block)
 }
 
-// BroadcastBlockChange broadcasts update messages to clients.
-func (ws *Server) BroadcastBlockChange(teamID string, block *model.Block) {
+// broadcastBlockChangeLocal broadcasts update messages to the clients of
+// this node.
+func (ws *Server) broadcastBlockChangeLocal(teamID string, block *model.Block) {
 	blockIDsToNotify := []string{block.ID, block.ParentID}
 
 	message := UpdateBlockMsg{
@@ -534,6 +832,7 @@ func (ws *Server) BroadcastBlockChange(teamID string, block *model.Block) {
 		TeamID: teamID,
 		Block:  block,
 	}
+	ws.getStream(teamID).add(block.BoardID, &message, nil)
 
 	listeners := ws.getListenersForTeamAndBoard(teamID, block.BoardID)
 	ws.logger.Trace("listener(s) for teamID",
@@ -557,7 +856,7 @@ func (ws *Server) BroadcastBlockChange(teamID string, block *model.Block) {
 			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
 		)
 
-		err := listener.WriteJSON(message)
+		err := listener.WriteJSON(&message)
 		if err != nil {
 			ws.logger.Error("broadcast error", mlog.Err(err))
 			listener.conn.Close()
@@ -565,7 +864,79 @@ func (ws *Server) BroadcastBlockChange(teamID string, block *model.Block) {
 	}
 }
 
-func (ws *Server) BroadcastCategoryChange(category model.Category) {
+// broadcastBlocksChangeLocal broadcasts the changes of several blocks of a
+// board to the clients of this node as a single message.
+func (ws *Server) broadcastBlocksChangeLocal(teamID, boardID string, blocks []*model.Block) {
+	message := UpdateBlocksMsg{
+		Action:  websocketActionUpdateBlocks,
+		TeamID:  teamID,
+		BoardID: boardID,
+		Blocks:  blocks,
+	}
+	ws.getStream(teamID).add(boardID, &message, nil)
+
+	listeners := ws.getListenersForTeamAndBoard(teamID, boardID)
+	for _, block := range blocks {
+		listeners = append(listeners, ws.getListenersForBlock(block.ID)...)
+		listeners = append(listeners, ws.getListenersForBlock(block.ParentID)...)
+	}
+
+	sent := map[*websocketSession]bool{}
+	for _, listener := range listeners {
+		if sent[listener] {
+			continue
+		}
+		sent[listener] = true
+
+		ws.logger.Debug("Broadcast blocks change",
+			mlog.String("teamID", teamID),
+			mlog.String("boardID", boardID),
+			mlog.Int("block_count", len(blocks)),
+			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
+		)
+
+		if err := listener.WriteJSON(&message); err != nil {
+			ws.logger.Error("broadcast error", mlog.Err(err))
+			listener.conn.Close()
+		}
+	}
+}
+
+// broadcastCommentReactionChangeLocal broadcasts a reaction added to or removed
+// from a comment to the clients of its board.
+func (ws *Server) broadcastCommentReactionChangeLocal(teamID string, reaction *model.CommentReaction, deleted bool) {
+	message := UpdateCommentReactionMsg{
+		Action:   websocketActionUpdateCommentReaction,
+		TeamID:   teamID,
+		Reaction: reaction,
+		Deleted:  deleted,
+	}
+	ws.getStream(teamID).add(reaction.BoardID, &message, nil)
+
+	listeners := ws.getListenersForTeamAndBoard(teamID, reaction.BoardID)
+	listeners = append(listeners, ws.getListenersForBlock(reaction.CommentID)...)
+
+	sent := map[*websocketSession]bool{}
+	for _, listener := range listeners {
+		if sent[listener] {
+			continue
+		}
+		sent[listener] = true
+
+		ws.logger.Debug("Broadcast comment reaction change",
+			mlog.String("teamID", teamID),
+			mlog.String("commentID", reaction.CommentID),
+			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
+		)
+
+		if err := listener.WriteJSON(&message); err != nil {
+			ws.logger.Error("broadcast error", mlog.Err(err))
+			listener.conn.Close()
+		}
+	}
+}
+
+func (ws *Server) broadcastCategoryChangeLocal(category model.Category) {
 	message := UpdateCategoryMessage{
 		Action:   websocketActionUpdateCategory,
 		TeamID:   category.TeamID,
@@ -588,7 +959,7 @@ func (ws *Server) BroadcastCategoryChange(category model.Category) {
 	}
 }
 
-func (ws *Server) BroadcastCategoryReorder(teamID, userID string, categoryOrder []string) {
+func (ws *Server) broadcastCategoryReorderLocal(teamID, userID string, categoryOrder []string) {
 	message := CategoryReorderMessage{
 		Action:        websocketActionReorderCategories,
 		CategoryOrder: categoryOrder,
@@ -610,7 +981,7 @@ func (ws *Server) BroadcastCategoryReorder(teamID, userID string, categoryOrder
 	}
 }
 
-func (ws *Server) BroadcastCategoryBoardsReorder(teamID, userID, categoryID string, boardOrder []string) {
+func (ws *Server) broadcastCategoryBoardsReorderLocal(teamID, userID, categoryID string, boardOrder []string) {
 	message := CategoryBoardReorderMessage{
 		Action:     websocketActionReorderCategoryBoards,
 		CategoryID: categoryID,
@@ -634,7 +1005,7 @@ func (ws *Server) BroadcastCategoryBoardsReorder(teamID, userID, categoryID stri
 	}
 }
 
-func (ws *Server) BroadcastCategoryBoardChange(teamID, userID string, boardCategories []*model.BoardCategoryWebsocketData) {
+func (ws *Server) broadcastCategoryBoardChangeLocal(teamID, userID string, boardCategories []*model.BoardCategoryWebsocketData) {
 	message := UpdateCategoryMessage{
 		Action:          websocketActionUpdateCategoryBoard,
 		TeamID:          teamID,
@@ -657,8 +1028,36 @@ func (ws *Server) BroadcastCategoryBoardChange(teamID, userID string, boardCateg
 	}
 }
 
-// BroadcastConfigChange broadcasts update messages to clients.
-func (ws *Server) BroadcastConfigChange(clientConfig model.ClientConfig) {
+func (ws *Server) broadcastInboxChangeLocal(teamID, userID string, item *model.InboxItem, unreadCount int) {
+	message := UpdateInboxMsg{
+		Action:      websocketActionUpdateInbox,
+		TeamID:      teamID,
+		Item:        item,
+		UnreadCount: unreadCount,
+	}
+
+	for _, listener := range ws.listenersByTeam[teamID] {
+		if listener.userID != userID {
+			continue
+		}
+
+		ws.logger.Debug("Broadcast inbox change",
+			mlog.String("userID", userID),
+			mlog.String("teamID", teamID),
+			mlog.Int("unreadCount", unreadCount),
+			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
+		)
+
+		if err := listener.WriteJSON(message); err != nil {
+			ws.logger.Error("broadcast inbox change error", mlog.Err(err))
+			listener.conn.Close()
+		}
+	}
+}
+
+// broadcastConfigChangeLocal broadcasts update messages to the clients of
+// this node.
+func (ws *Server) broadcastConfigChangeLocal(clientConfig model.ClientConfig) {
 	message := UpdateClientConfig{
 		Action:       websocketActionUpdateConfig,
 		ClientConfig: clientConfig,
@@ -681,12 +1080,13 @@ func (ws *Server) BroadcastConfigChange(clientConfig model.ClientConfig) {
 	}
 }
 
-func (ws *Server) BroadcastBoardChange(teamID string, board *model.Board) {
+func (ws *Server) broadcastBoardChangeLocal(teamID string, board *model.Board) {
 	message := UpdateBoardMsg{
 		Action: websocketActionUpdateBoard,
 		TeamID: teamID,
 		Board:  board,
 	}
+	ws.getStream(teamID).add(board.ID, &message, nil)
 
 	listeners := ws.getListenersForTeamAndBoard(teamID, board.ID)
 	ws.logger.Trace("listener(s) for teamID and boardID",
@@ -702,7 +1102,7 @@ func (ws *Server) BroadcastBoardChange(teamID string, board *model.Board) {
 			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
 		)
 
-		err := listener.WriteJSON(message)
+		err := listener.WriteJSON(&message)
 		if err != nil {
 			ws.logger.Error("broadcast error", mlog.Err(err))
 			listener.conn.Close()
@@ -721,12 +1121,13 @@ func (ws *Server) BroadcastBoardDelete(teamID, boardID string) {
 	ws.BroadcastBoardChange(teamID, board)
 }
 
-func (ws *Server) BroadcastMemberChange(teamID, boardID string, member *model.BoardMember) {
+func (ws *Server) broadcastMemberChangeLocal(teamID, boardID string, member *model.BoardMember) {
 	message := UpdateMemberMsg{
 		Action: websocketActionUpdateMember,
 		TeamID: teamID,
 		Member: member,
 	}
+	ws.getStream(teamID).add(boardID, &message, nil)
 
 	listeners := ws.getListenersForTeamAndBoard(teamID, boardID)
 	ws.logger.Trace("listener(s) for teamID and boardID",
@@ -742,7 +1143,7 @@ func (ws *Server) BroadcastMemberChange(teamID, boardID string, member *model.Bo
 			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
 		)
 
-		err := listener.WriteJSON(message)
+		err := listener.WriteJSON(&message)
 		if err != nil {
 			ws.logger.Error("broadcast error", mlog.Err(err))
 			listener.conn.Close()
@@ -750,12 +1151,13 @@ func (ws *Server) BroadcastMemberChange(teamID, boardID string, member *model.Bo
 	}
 }
 
-func (ws *Server) BroadcastMemberDelete(teamID, boardID, userID string) {
+func (ws *Server) broadcastMemberDeleteLocal(teamID, boardID, userID string) {
 	message := UpdateMemberMsg{
 		Action: websocketActionDeleteMember,
 		TeamID: teamID,
 		Member: &model.BoardMember{UserID: userID, BoardID: boardID},
 	}
+	ws.getStream(teamID).add(boardID, &message, []string{userID})
 
 	// when fetching the members of the board that should receive the
 	// member deletion message, the deleted member will not be one of
@@ -774,7 +1176,7 @@ func (ws *Server) BroadcastMemberDelete(teamID, boardID, userID string) {
 			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
 		)
 
-		err := listener.WriteJSON(message)
+		err := listener.WriteJSON(&message)
 		if err != nil {
 			ws.logger.Error("broadcast error", mlog.Err(err))
 			listener.conn.Close()
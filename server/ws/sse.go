@@ -0,0 +1,198 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	authService "github.com/mattermost/focalboard/server/services/auth"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// sseHeartbeatInterval is how often a comment is sent to keep idle
+// streams open through proxies.
+const sseHeartbeatInterval = 30 * time.Second
+
+var errSSEClosed = errors.New("server-sent events stream closed")
+
+// sseAddr is the address of the client of a server-sent events stream.
+type sseAddr string
+
+func (a sseAddr) Network() string { return "tcp" }
+func (a sseAddr) String() string  { return string(a) }
+
+// sseConn is a server-sent events stream. The sequence number of the
+// team events is sent as the event ID, so clients that reconnect can
+// resume from it with the Last-Event-ID header.
+type sseConn struct {
+	mu         sync.Mutex
+	w          http.ResponseWriter
+	flusher    http.Flusher
+	remoteAddr sseAddr
+	cancel     context.CancelFunc
+	closed     bool
+}
+
+func (c *sseConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if message, ok := v.(sequencedMessage); ok && message.getSeq() != 0 {
+		fmt.Fprintf(&buf, "id: %d\n", message.getSeq())
+	}
+	fmt.Fprintf(&buf, "data: %s\n\n", data)
+
+	return c.write(buf.Bytes())
+}
+
+func (c *sseConn) writeHeartbeat() error {
+	return c.write([]byte(": heartbeat\n\n"))
+}
+
+func (c *sseConn) write(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the response can't be written once the handler returned
+	if c.closed {
+		return errSSEClosed
+	}
+	if _, err := c.w.Write(data); err != nil {
+		return err
+	}
+	c.flusher.Flush()
+	return nil
+}
+
+// Close ends the stream.
+func (c *sseConn) Close() error {
+	c.cancel()
+	return nil
+}
+
+func (c *sseConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+func (c *sseConn) markClosed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+// handleServerSentEvents streams the events of a team, the same ones
+// sent through the websocket, for clients that can't open one. The
+// events can be restricted to some boards with boardId parameters.
+func (ws *Server) handleServerSentEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	teamID := query.Get("teamId")
+	if teamID == "" {
+		http.Error(w, "missing teamId parameter", http.StatusBadRequest)
+		return
+	}
+
+	var seq int64
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("lastEventId")
+	}
+	if lastEventID != "" {
+		var err error
+		if seq, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID := ""
+	if ws.isMattermostAuth {
+		userID = r.Header.Get("Mattermost-User-Id")
+	}
+	if userID == "" {
+		token, _ := authService.ParseAuthTokenFromRequest(r)
+		userID = ws.getUserIDForToken(token)
+	}
+	if userID == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	conn := &sseConn{
+		w:          w,
+		flusher:    flusher,
+		remoteAddr: sseAddr(r.RemoteAddr),
+		cancel:     cancel,
+	}
+	session := ws.newSession(conn, userID)
+	defer session.stop()
+	if boardIDs := query["boardId"]; len(boardIDs) != 0 {
+		session.boards = make(map[string]bool, len(boardIDs))
+		for _, boardID := range boardIDs {
+			session.boards[boardID] = true
+		}
+	}
+
+	if !ws.canSubscribeToTeam(session, teamID) {
+		http.Error(w, "access denied to team", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// disables the response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ws.logger.Debug("CONNECT server-sent events",
+		mlog.String("teamID", teamID),
+		mlog.String("userID", userID),
+		mlog.Stringer("client", conn.RemoteAddr()),
+	)
+
+	ws.addListener(session)
+	defer func() {
+		ws.logger.Debug("DISCONNECT server-sent events", mlog.Stringer("client", conn.RemoteAddr()))
+		ws.removeListener(session)
+		conn.markClosed()
+	}()
+
+	if lastEventID != "" {
+		ws.resumeListener(session, teamID, seq)
+	} else {
+		ws.subscribeListenerToTeam(session, teamID)
+	}
+
+	ticker := time.NewTicker(sseHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.writeHeartbeat(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	wsMocks "github.com/mattermost/focalboard/server/ws/mocks"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// readSSEEvent reads the next event of a stream, skipping heartbeats.
+func readSSEEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	var id, data string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && data != "":
+			return id, data
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestServerSentEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := wsMocks.NewMockStore(ctrl)
+	store.EXPECT().GetMembersForBoard(gomock.Any()).Return([]*model.BoardMember{{UserID: model.SingleUser}}, nil).AnyTimes()
+
+	server := NewServer(&auth.Auth{}, "token", false, mlog.CreateConsoleTestLogger(t), store)
+	r := mux.NewRouter()
+	server.RegisterRoutes(r)
+	httpServer := httptest.NewServer(r)
+	defer httpServer.Close()
+
+	connect := func(t *testing.T, query string, header http.Header) *bufio.Reader {
+		req, err := http.NewRequest(http.MethodGet, httpServer.URL+"/ws/events?"+query, nil)
+		require.NoError(t, err)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := http.DefaultClient.Do(req) //nolint:bodyclose
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		return bufio.NewReader(resp.Body)
+	}
+
+	waitForListeners := func(t *testing.T, count int) {
+		require.Eventually(t, func() bool {
+			server.mu.RLock()
+			defer server.mu.RUnlock()
+			return len(server.listenersByTeam["team-id"]) == count
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		resp, err := http.Get(httpServer.URL + "/ws/events?teamId=team-id&access_token=invalid")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("missing team", func(t *testing.T) {
+		resp, err := http.Get(httpServer.URL + "/ws/events?access_token=token")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	var seq int64
+	t.Run("events are streamed with their sequence number", func(t *testing.T) {
+		reader := connect(t, "teamId=team-id&access_token=token", nil)
+		waitForListeners(t, 1)
+
+		server.BroadcastBlockChange("team-id", &model.Block{ID: "block-id", BoardID: "board-id"})
+
+		id, data := readSSEEvent(t, reader)
+		var message UpdateBlockMsg
+		require.NoError(t, json.Unmarshal([]byte(data), &message))
+		require.Equal(t, websocketActionUpdateBlock, message.Action)
+		require.Equal(t, strconv.FormatInt(message.Seq, 10), id)
+		seq = message.Seq
+	})
+
+	t.Run("clients resume from the last event ID", func(t *testing.T) {
+		server.BroadcastBoardChange("team-id", &model.Board{ID: "board-id"})
+
+		reader := connect(t, "teamId=team-id&access_token=token", http.Header{"Last-Event-Id": []string{strconv.FormatInt(seq, 10)}})
+
+		id, data := readSSEEvent(t, reader)
+		require.Equal(t, strconv.FormatInt(seq+1, 10), id)
+		var message UpdateBoardMsg
+		require.NoError(t, json.Unmarshal([]byte(data), &message))
+		require.Equal(t, "board-id", message.Board.ID)
+	})
+
+	t.Run("events can be restricted to some boards", func(t *testing.T) {
+		connect(t, "teamId=team-id&access_token=token&boardId=board-id", nil)
+		require.Eventually(t, func() bool {
+			server.mu.RLock()
+			defer server.mu.RUnlock()
+			listeners := server.listenersByTeam["team-id"]
+			return len(listeners) == 1 && listeners[0].boards != nil
+		}, time.Second, 10*time.Millisecond)
+
+		require.Eventually(t, func() bool {
+			server.mu.RLock()
+			defer server.mu.RUnlock()
+			return len(server.getListenersForTeamAndBoard("team-id", "board-id")) == 1 &&
+				len(server.getListenersForTeamAndBoard("team-id", "other-board-id")) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
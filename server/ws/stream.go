@@ -0,0 +1,103 @@
+package ws
+
+import (
+	"sync"
+	"time"
+)
+
+// replayBufferSize is the number of events of a team kept in memory
+// to be replayed to the clients that resume their connection.
+const replayBufferSize = 500
+
+// sequencedMessage is a team or board event that carries a sequence
+// number.
+type sequencedMessage interface {
+	setSeq(seq int64)
+	getSeq() int64
+}
+
+func (m *UpdateBlockMsg) setSeq(seq int64)           { m.Seq = seq }
+func (m *UpdateBlocksMsg) setSeq(seq int64)          { m.Seq = seq }
+func (m *UpdateCommentReactionMsg) setSeq(seq int64) { m.Seq = seq }
+func (m *UpdateBoardMsg) setSeq(seq int64)           { m.Seq = seq }
+func (m *UpdateMemberMsg) setSeq(seq int64)          { m.Seq = seq }
+
+func (m *UpdateBlockMsg) getSeq() int64           { return m.Seq }
+func (m *UpdateBlocksMsg) getSeq() int64          { return m.Seq }
+func (m *UpdateCommentReactionMsg) getSeq() int64 { return m.Seq }
+func (m *UpdateBoardMsg) getSeq() int64           { return m.Seq }
+func (m *UpdateMemberMsg) getSeq() int64          { return m.Seq }
+
+// streamEvent is an event kept in the replay buffer of a team.
+type streamEvent struct {
+	seq     int64
+	boardID string
+	// ensureUsers are users that received the event without being
+	// members of the board, like a member that has been removed.
+	ensureUsers []string
+	message     sequencedMessage
+}
+
+// teamStream holds the sequence number and the last events of a
+// team.
+type teamStream struct {
+	mu      sync.Mutex
+	lastSeq int64
+	events  []streamEvent
+}
+
+// newTeamStream creates a stream whose sequence numbers start from the
+// current time in microseconds, so the numbers a client got from a
+// previous run of the server are always behind the ones of the new
+// stream and can't be mistaken for a valid resume point.
+func newTeamStream() *teamStream {
+	return &teamStream{lastSeq: time.Now().UnixMicro()}
+}
+
+// add assigns the next sequence number to a message and keeps it in
+// the replay buffer, dropping the oldest event if the buffer is full.
+func (s *teamStream) add(boardID string, message sequencedMessage, ensureUsers []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSeq++
+	message.setSeq(s.lastSeq)
+
+	s.events = append(s.events, streamEvent{
+		seq:         s.lastSeq,
+		boardID:     boardID,
+		ensureUsers: ensureUsers,
+		message:     message,
+	})
+	if len(s.events) > replayBufferSize {
+		s.events = s.events[1:]
+	}
+}
+
+// last returns the last sequence number of the stream.
+func (s *teamStream) last() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSeq
+}
+
+// eventsSince returns the events that came after a sequence number,
+// and the last sequence number of the stream. If some of those events
+// are not in the buffer anymore, or the sequence number is unknown,
+// the client cannot resume and needs to resync.
+func (s *teamStream) eventsSince(seq int64) ([]streamEvent, int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if seq == s.lastSeq {
+		return []streamEvent{}, s.lastSeq, true
+	}
+	if seq > s.lastSeq || len(s.events) == 0 || seq < s.events[0].seq-1 {
+		return nil, s.lastSeq, false
+	}
+
+	start := len(s.events) - int(s.lastSeq-seq)
+	events := make([]streamEvent, len(s.events)-start)
+	copy(events, s.events[start:])
+	return events, s.lastSeq, true
+}
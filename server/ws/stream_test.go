@@ -0,0 +1,111 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	wsMocks "github.com/mattermost/focalboard/server/ws/mocks"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamStream(t *testing.T) {
+	t.Run("messages get consecutive sequence numbers", func(t *testing.T) {
+		stream := newTeamStream()
+		start := stream.lastSeq
+
+		first := &UpdateBlockMsg{}
+		second := &UpdateBoardMsg{}
+		stream.add("board-id", first, nil)
+		stream.add("board-id", second, nil)
+
+		require.Equal(t, start+1, first.Seq)
+		require.Equal(t, start+2, second.Seq)
+		require.Equal(t, start+2, stream.lastSeq)
+	})
+
+	t.Run("events since a sequence number", func(t *testing.T) {
+		stream := newTeamStream()
+		start := stream.lastSeq
+		for i := 0; i < 3; i++ {
+			stream.add("board-id", &UpdateBlockMsg{}, nil)
+		}
+
+		events, lastSeq, ok := stream.eventsSince(start + 1)
+		require.True(t, ok)
+		require.Equal(t, start+3, lastSeq)
+		require.Len(t, events, 2)
+		require.Equal(t, start+2, events[0].seq)
+		require.Equal(t, start+3, events[1].seq)
+
+		events, _, ok = stream.eventsSince(start)
+		require.True(t, ok)
+		require.Len(t, events, 3)
+
+		events, _, ok = stream.eventsSince(start + 3)
+		require.True(t, ok)
+		require.Empty(t, events)
+	})
+
+	t.Run("unknown sequence numbers require a resync", func(t *testing.T) {
+		stream := newTeamStream()
+		start := stream.lastSeq
+
+		_, lastSeq, ok := stream.eventsSince(start - 1)
+		require.False(t, ok)
+		require.Equal(t, start, lastSeq)
+
+		stream.add("board-id", &UpdateBlockMsg{}, nil)
+
+		_, _, ok = stream.eventsSince(start + 2)
+		require.False(t, ok)
+
+		_, _, ok = stream.eventsSince(0)
+		require.False(t, ok)
+	})
+
+	t.Run("the replay buffer is bounded", func(t *testing.T) {
+		stream := newTeamStream()
+		start := stream.lastSeq
+		for i := 0; i < replayBufferSize+10; i++ {
+			stream.add("board-id", &UpdateBlockMsg{}, nil)
+		}
+		require.Len(t, stream.events, replayBufferSize)
+
+		_, _, ok := stream.eventsSince(start + 5)
+		require.False(t, ok)
+
+		events, _, ok := stream.eventsSince(start + 10)
+		require.True(t, ok)
+		require.Len(t, events, replayBufferSize)
+	})
+}
+
+func TestCanReceiveEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := wsMocks.NewMockStore(ctrl)
+	server := NewServer(&auth.Auth{}, "", false, mlog.CreateConsoleTestLogger(t), store)
+
+	store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{{UserID: "member-id"}}, nil).Times(2)
+
+	t.Run("board members receive the events of the board", func(t *testing.T) {
+		isMember := map[string]bool{}
+		event := streamEvent{boardID: "board-id"}
+		require.True(t, server.canReceiveEvent("member-id", event, isMember))
+		// the membership is cached for the following events
+		require.True(t, server.canReceiveEvent("member-id", event, isMember))
+	})
+
+	t.Run("other users don't", func(t *testing.T) {
+		require.False(t, server.canReceiveEvent("other-id", streamEvent{boardID: "board-id"}, map[string]bool{}))
+	})
+
+	t.Run("unless they are explicitly included", func(t *testing.T) {
+		event := streamEvent{boardID: "board-id", ensureUsers: []string{"removed-id"}}
+		require.True(t, server.canReceiveEvent("removed-id", event, map[string]bool{}))
+	})
+}